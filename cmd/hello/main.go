@@ -1,132 +1,258 @@
 package main
 
 import (
-	"github.com/go-gl/gl/all-core/gl"
 	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/render"
+	"github.com/mleku/goo/pkg/text"
+	"github.com/mleku/goo/pkg/theme"
 	"github.com/mleku/goo/pkg/widget"
 	"github.com/mleku/goo/pkg/window"
 	"lol.mleku.dev/chk"
+	"lol.mleku.dev/log"
 )
 
+// fontPath is the TrueType font used for the demo labels
+const fontPath = "/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf"
+
 // WidgetApp implements the window application
 type WidgetApp struct {
+	window     *window.Window
+	font       *text.Font
 	rootWidget *widget.RootWidget
+	// crosshair is where the crosshair was drawn last frame
+	crosshair     interfaces.Point
+	showCrosshair bool
+	// light is set while the light theme is shown
+	light bool
 }
 
 // Init initializes the widget tree using the chained API with inline creation
 func (app *WidgetApp) Init() (err error) {
+	font := app.font
+	menu := widget.NewMenu(font).
+		Submenu("Theme",
+			widget.NewMenu(font).
+				Item("Light", func() { app.setTheme(true) }).
+				Item("Dark", func() { app.setTheme(false) }),
+		).
+		Separator().
+		Item("Say hello", func() { log.I.Ln("hello") })
+
+	ctrl := interfaces.ModControl
+	bar := widget.MenuBar(font).
+		Menu("&File", widget.NewMenu(font).
+			ItemShortcut("&New window", widget.Shortcut{Key: 'N', Mods: ctrl}, app.newWindow).
+			ItemShortcut("&Say hello", widget.Shortcut{Key: 'H', Mods: ctrl}, func() { log.I.Ln("hello") }).
+			Separator().
+			ItemShortcut("&Close window", widget.Shortcut{Key: 'W', Mods: ctrl}, app.window.Stop).
+			ItemShortcut("&Quit", widget.Shortcut{Key: 'Q', Mods: ctrl}, func() { app.window.App().Stop() }),
+		).
+		Menu("&View", widget.NewMenu(font).
+			ItemShortcut("&Toggle theme", widget.Shortcut{Key: 'T', Mods: ctrl}, app.toggleTheme).
+			ItemShortcut("&Inspect widgets", widget.Shortcut{Key: 'I', Mods: ctrl | interfaces.ModShift}, app.inspect),
+		)
+
 	app.rootWidget = widget.Root(
-		widget.Overlay().
-			Child(
-				widget.Column().
-					Flex(
-						widget.Row().
-							Flex(widget.Fill(1.0, 0.0, 0.0, 1.0), 1.0).
-							Flex(widget.Fill(1.0, 1.0, 0.0, 1.0), 1.0),
-						1.0,
+		widget.Column().
+			Rigid(bar).
+			Flex(
+				widget.Overlay().
+					Child(
+						widget.WithContextMenu(
+							widget.Column().
+								Flex(
+									widget.Row().
+										Flex(widget.Fill(1.0, 0.0, 0.0, 1.0), 1.0).
+										Flex(widget.Fill(1.0, 1.0, 0.0, 1.0), 1.0),
+									1.0,
+								).
+								Flex(
+									widget.Row().
+										Flex(widget.Fill(0.0, 1.0, 0.0, 1.0), 1.0).
+										Flex(widget.Fill(0.0, 0.0, 1.0, 1.0), 1.0),
+									1.0,
+								),
+							menu,
+						),
 					).
-					Flex(
-						widget.Row().
-							Flex(widget.Fill(0.0, 1.0, 0.0, 1.0), 1.0).
-							Flex(widget.Fill(0.0, 0.0, 1.0, 1.0), 1.0),
-						1.0,
-					),
-			).
-			Child(
-				widget.Center(
-					widget.NewFixedSize(64, 64,
-						widget.Fill(1.0, 1.0, 1.0, 0.75),
+					Child(
+						widget.Center(
+							widget.NewFixedSize(140, 40,
+								widget.WithTooltip(
+									widget.Button(
+										widget.Label(font, "Toggle theme").
+											Size(16).
+											Align(text.AlignCenter),
+									).
+										OnClick(app.toggleTheme),
+									font, "Switch between the light and dark themes",
+								),
+							),
+						),
+					).
+					Child(
+						widget.NewDirectionWidget(
+							widget.NewFixedSize(240, 28,
+								widget.TextInput(font).
+									Placeholder("Type here").
+									OnSubmit(func(s string) { log.I.Ln("submitted", s) }),
+							),
+							widget.GravityNorth,
+						),
 					),
-				),
+				1.0,
 			),
 	)
 
+	app.rootWidget.SetTheme(theme.Dark())
+	// F12 shows frame timing and the bounds of the widget under the cursor
+	app.rootWidget.Debug(font)
 	return
 }
 
-// Render renders the widget tree
-func (app *WidgetApp) Render(width, height int, mouseX, mouseY float64, cursorInWindow bool) (err error) {
-	// Set the clear color to black
-	gl.ClearColor(0.0, 0.0, 0.0, 1.0)
-	gl.Clear(gl.COLOR_BUFFER_BIT)
+// newWindow opens another window showing the demo, sharing the font's glyph
+// atlas with this one
+func (app *WidgetApp) newWindow() {
+	if err := open(app.window.App(), app.font); chk.E(err) {
+		return
+	}
+}
+
+// inspect opens a window showing the demo's widget tree, outlining the
+// widget pointed out in it
+func (app *WidgetApp) inspect() {
+	w, err := window.New(360, 560, "Widget Inspector")
+	if chk.E(err) {
+		return
+	}
+	root := widget.Root(widget.Inspector(app.font, app.rootWidget).Wake(app.window.Wake))
+	root.SetTheme(theme.Dark())
+	render := func(frame *window.Frame) (err error) {
+		ctx := &interfaces.Context{
+			WindowWidth:  frame.Width,
+			WindowHeight: frame.Height,
+			DrawList:     frame.DrawList,
+			Clipboard:    frame.Clipboard,
+			Clock:        frame.Clock,
+			Scale:        frame.Scale,
+			InputMethod:  frame.InputMethod,
+		}
+		box := &interfaces.Box{Size: interfaces.Size{Width: float32(frame.Width), Height: float32(frame.Height)}}
+		root.Dispatch(ctx, box, frame.Events)
+		if _, err = root.Render(ctx, box); chk.E(err) {
+			return
+		}
+		frame.Cursor = root.Cursor()
+		return
+	}
+	if err = app.window.App().Open(w, render); chk.E(err) {
+		return
+	}
+}
 
-	// Enable blending globally
-	gl.Enable(gl.BLEND)
-	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
-	gl.Enable(gl.SCISSOR_TEST)
+// toggleTheme switches between the light and dark themes
+func (app *WidgetApp) toggleTheme() {
+	app.setTheme(!app.light)
+}
 
-	// Set up the projection matrix for 2D rendering
-	// Use orthographic projection matching screen coordinates
-	gl.MatrixMode(gl.PROJECTION)
-	gl.LoadIdentity()
-	gl.Ortho(0, float64(width), 0, float64(height), -1, 1)
+// setTheme switches to the light or dark theme
+func (app *WidgetApp) setTheme(light bool) {
+	app.light = light
+	if app.light {
+		app.rootWidget.SetTheme(theme.Light())
+	} else {
+		app.rootWidget.SetTheme(theme.Dark())
+	}
+	log.I.Ln("light theme:", app.light)
+}
 
-	gl.MatrixMode(gl.MODELVIEW)
-	gl.LoadIdentity()
+// Render renders the widget tree
+func (app *WidgetApp) Render(frame *window.Frame) (err error) {
+	width, height := frame.Width, frame.Height
 
 	// Create widget context with window dimensions
 	widgetCtx := &interfaces.Context{
 		WindowWidth:    width,  // Window logical size
 		WindowHeight:   height, // Window logical size
 		PaintedRegions: make([]interfaces.Rect, 0),
+		DrawList:       frame.DrawList,
+		Clipboard:      frame.Clipboard,
+		Clock:          frame.Clock,
+		Stats:          &frame.Stats,
+		Scale:          frame.Scale,
+		InputMethod:    frame.InputMethod,
 	}
 
-	// Create a dummy box for the root widget
-	rootBox := &interfaces.Box{}
+	// The root box spans the whole window
+	rootBox := &interfaces.Box{
+		Size: interfaces.Size{Width: float32(width), Height: float32(height)},
+	}
+
+	// Deliver input received since the last frame to the widget tree
+	app.rootWidget.Dispatch(widgetCtx, rootBox, frame.Events)
+
+	// Repaint under the old crosshair when the cursor moves
+	cursor := interfaces.Point{X: float32(frame.MouseX), Y: float32(frame.MouseY)}
+	if app.showCrosshair && (cursor != app.crosshair || !frame.CursorInWindow) {
+		app.rootWidget.Invalidate(interfaces.Rect{X: app.crosshair.X - 1, Y: 0, Width: 3, Height: float32(height)})
+		app.rootWidget.Invalidate(interfaces.Rect{X: 0, Y: app.crosshair.Y - 1, Width: float32(width), Height: 3})
+	}
+	app.crosshair, app.showCrosshair = cursor, frame.CursorInWindow
 
-	// Render the widget tree
-	_, err = app.rootWidget.Render(widgetCtx, rootBox)
-	if err != nil {
+	// Lay out the widget tree if it changed and repaint damaged regions
+	if _, err = app.rootWidget.Render(widgetCtx, rootBox); chk.E(err) {
 		return
 	}
 
+	// Show the cursor shape the widget under the mouse asks for
+	frame.Cursor = app.rootWidget.Cursor()
+
 	// Draw crosshair at mouse cursor position only if cursor is in window
-	if cursorInWindow {
-		drawCrosshair(float32(mouseX), float32(height)-float32(mouseY), width, height)
+	if frame.CursorInWindow {
+		drawCrosshair(frame.DrawList, cursor.X, cursor.Y, width, height)
 	}
 
 	return
 }
 
 // drawCrosshair draws a 1-pixel wide black crosshair at the specified position
-func drawCrosshair(x, y float32, width, height int) {
-	// Disable scissor test for crosshair to draw over everything
-	gl.Disable(gl.SCISSOR_TEST)
-
-	// Set line width to 1 pixel
-	gl.LineWidth(1.0)
-
-	// Set color to black
-	gl.Color4f(0.0, 0.0, 0.0, 1.0)
+func drawCrosshair(list *render.DrawList, x, y float32, width, height int) {
+	black := [4]float32{0.0, 0.0, 0.0, 1.0}
 
 	// Draw vertical line (full height)
-	gl.Begin(gl.LINES)
-	gl.Vertex2f(x, 0)
-	gl.Vertex2f(x, float32(height))
-	gl.End()
+	list.Line(x, 0, x, float32(height), 1.0, black)
 
 	// Draw horizontal line (full width)
-	gl.Begin(gl.LINES)
-	gl.Vertex2f(0, y)
-	gl.Vertex2f(float32(width), y)
-	gl.End()
+	list.Line(0, y, float32(width), y, 1.0, black)
+}
 
-	// Re-enable scissor test
-	gl.Enable(gl.SCISSOR_TEST)
+// open shows the demo in a new window of the app
+func open(a *window.App, font *text.Font) (err error) {
+	var w *window.Window
+	if w, err = window.New(640, 480, "Fromage Widget Demo with GLFW"); chk.E(err) {
+		return
+	}
+	// Draw in step with the display rather than as fast as possible
+	w.VSync(true)
+	app := &WidgetApp{window: w, font: font}
+	if err = app.Init(); chk.E(err) {
+		return
+	}
+	return a.Open(w, app.Render)
 }
 
 func main() {
-	w, err := window.New(640, 480, "Fromage Widget Demo with GLFW")
+	font, err := text.Load(fontPath)
 	if chk.E(err) {
 		return
 	}
 
-	app := &WidgetApp{}
-	if err := app.Init(); chk.E(err) {
+	a := window.NewApp()
+	if err := open(a, font); chk.E(err) {
 		return
 	}
 
-	if err := w.Run(app.Render); chk.E(err) {
+	if err := a.Run(); chk.E(err) {
 		return
 	}
 }
@@ -0,0 +1,112 @@
+// Command markup shows a UI described in a JSON file, reloading it whenever
+// the file is saved. Run it from the repository root, or pass the path of the
+// description to show:
+//
+//	go run ./cmd/markup [cmd/markup/ui.json]
+package main
+
+import (
+	"os"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/markup"
+	"github.com/mleku/goo/pkg/text"
+	"github.com/mleku/goo/pkg/theme"
+	"github.com/mleku/goo/pkg/widget"
+	"github.com/mleku/goo/pkg/window"
+	"lol.mleku.dev/chk"
+	"lol.mleku.dev/log"
+)
+
+const (
+	// fontPath is the TrueType font the UI is drawn with
+	fontPath = "/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf"
+	// defaultPath is the description shown when none is given
+	defaultPath = "cmd/markup/ui.json"
+)
+
+// MarkupApp shows a document
+type MarkupApp struct {
+	doc  *markup.Document
+	root *widget.RootWidget
+}
+
+// greet shows a greeting in the label with the id greeting, if there is one
+func (app *MarkupApp) greet(name string) {
+	if l, ok := app.doc.UI().ByID("greeting").(*widget.LabelWidget); ok {
+		l.SetText("Hello, " + name + "!")
+	}
+}
+
+// hello greets the name in the text input with the id name, if there is one
+func (app *MarkupApp) hello() {
+	if t, ok := app.doc.UI().ByID("name").(*widget.TextInputWidget); ok {
+		app.greet(t.Text())
+	}
+}
+
+// dark switches between the dark and light themes
+func (app *MarkupApp) dark(dark bool) {
+	if dark {
+		app.root.SetTheme(theme.Dark())
+	} else {
+		app.root.SetTheme(theme.Light())
+	}
+}
+
+// Render polls the document for changes and renders the tree
+func (app *MarkupApp) Render(frame *window.Frame) (err error) {
+	app.doc.Poll(frame.Clock)
+	ctx := &interfaces.Context{
+		WindowWidth:  frame.Width,
+		WindowHeight: frame.Height,
+		DrawList:     frame.DrawList,
+		Clipboard:    frame.Clipboard,
+		Clock:        frame.Clock,
+		Scale:        frame.Scale,
+		InputMethod:  frame.InputMethod,
+	}
+	box := &interfaces.Box{
+		Size: interfaces.Size{Width: float32(frame.Width), Height: float32(frame.Height)},
+	}
+	app.root.Dispatch(ctx, box, frame.Events)
+	if _, err = app.root.Render(ctx, box); chk.E(err) {
+		return
+	}
+	frame.Cursor = app.root.Cursor()
+	return
+}
+
+func main() {
+	path := defaultPath
+	if len(os.Args) > 1 {
+		path = os.Args[1]
+	}
+	font, err := text.Load(fontPath)
+	if chk.E(err) {
+		return
+	}
+
+	app := &MarkupApp{}
+	loader := markup.NewLoader(font).
+		Callback("greet", app.greet).
+		Callback("hello", app.hello).
+		Callback("dark", app.dark)
+	if app.doc, err = markup.Open(loader, path); chk.E(err) {
+		return
+	}
+	app.doc.OnReload(func(ui *markup.UI, err error) {
+		if err == nil {
+			log.I.Ln("reloaded", path)
+		}
+	})
+	app.root = widget.Root(app.doc.Widget()).SetTheme(theme.Dark())
+
+	w, err := window.New(480, 360, "Markup")
+	if chk.E(err) {
+		return
+	}
+	if err = w.Run(app.Render); chk.E(err) {
+		return
+	}
+}
@@ -0,0 +1,67 @@
+// Package anim animates values over time. Tweens read the time from a frame
+// Clock and ask it for more frames while they run, so the window only keeps
+// redrawing while something is moving.
+package anim
+
+import (
+	"time"
+)
+
+// Clock is the frame clock passed to widgets through the context. The window
+// advances it at the start of each frame and, after the frame is drawn,
+// checks whether another frame was requested before waiting for input.
+// A nil clock reads the current time and ignores requests.
+type Clock struct {
+	now    time.Time
+	active bool
+	wake   time.Time
+}
+
+// NewClock creates a clock set to the current time
+func NewClock() *Clock {
+	return &Clock{now: time.Now()}
+}
+
+// Advance starts a new frame at the given time, clearing the requests made
+// during the previous frame
+func (c *Clock) Advance(now time.Time) {
+	c.now = now
+	c.active = false
+	c.wake = time.Time{}
+}
+
+// Now returns the time of the current frame
+func (c *Clock) Now() time.Time {
+	if c == nil {
+		return time.Now()
+	}
+	return c.now
+}
+
+// Request asks for another frame as soon as possible, used by running animations
+func (c *Clock) Request() {
+	if c != nil {
+		c.active = true
+	}
+}
+
+// WakeAt asks for a frame at a later time, such as the next caret blink.
+// The earliest requested time wins.
+func (c *Clock) WakeAt(t time.Time) {
+	if c != nil && (c.wake.IsZero() || t.Before(c.wake)) {
+		c.wake = t
+	}
+}
+
+// Active reports whether a frame was requested as soon as possible
+func (c *Clock) Active() bool {
+	return c != nil && c.active
+}
+
+// Wake returns the time a later frame was requested for, if any
+func (c *Clock) Wake() (t time.Time, ok bool) {
+	if c == nil || c.wake.IsZero() {
+		return
+	}
+	return c.wake, true
+}
@@ -0,0 +1,69 @@
+package anim
+
+import (
+	"math"
+)
+
+// Easing maps the linear progress of an animation from 0 to 1 onto the
+// progress of its value, which starts at 0 and ends at 1
+type Easing func(t float32) float32
+
+// Linear moves at a constant rate
+func Linear(t float32) float32 {
+	return t
+}
+
+// EaseInQuad starts slowly and accelerates
+func EaseInQuad(t float32) float32 {
+	return t * t
+}
+
+// EaseOutQuad starts quickly and decelerates
+func EaseOutQuad(t float32) float32 {
+	return t * (2 - t)
+}
+
+// EaseInOutQuad accelerates through the first half and decelerates through the second
+func EaseInOutQuad(t float32) float32 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return -1 + (4-2*t)*t
+}
+
+// EaseInCubic starts slowly and accelerates more sharply than EaseInQuad
+func EaseInCubic(t float32) float32 {
+	return t * t * t
+}
+
+// EaseOutCubic starts quickly and decelerates more gently than EaseOutQuad
+func EaseOutCubic(t float32) float32 {
+	t--
+	return t*t*t + 1
+}
+
+// EaseInOutCubic accelerates and decelerates along a cubic curve
+func EaseInOutCubic(t float32) float32 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	t = 2*t - 2
+	return t*t*t/2 + 1
+}
+
+// EaseOutBack overshoots the target slightly before settling on it
+func EaseOutBack(t float32) float32 {
+	const c1 = 1.70158
+	const c3 = c1 + 1
+	t--
+	return 1 + c3*t*t*t + c1*t*t
+}
+
+// EaseOutElastic springs past the target and oscillates into place
+func EaseOutElastic(t float32) float32 {
+	if t <= 0 || t >= 1 {
+		return t
+	}
+	const c4 = 2 * math.Pi / 3
+	return float32(math.Pow(2, -10*float64(t))*math.Sin((float64(t)*10-0.75)*c4)) + 1
+}
@@ -0,0 +1,108 @@
+package anim
+
+import (
+	"time"
+)
+
+// Lerp interpolates between two values, returning a at t = 0 and b at t = 1.
+// Easings may pass t slightly outside that range.
+type Lerp[T any] func(a, b T, t float32) T
+
+// Tween animates a value towards a target. The animation starts the first
+// time the value is read after To, so it can be set up outside of a frame.
+type Tween[T any] struct {
+	from, to T
+	// current is the value from the last read, where a new animation starts
+	current  T
+	lerp     Lerp[T]
+	ease     Easing
+	duration time.Duration
+	start    time.Time
+	running  bool
+	started  bool
+}
+
+// NewTween creates a tween resting at a value, interpolated with lerp
+func NewTween[T any](value T, lerp Lerp[T]) *Tween[T] {
+	return &Tween[T]{from: value, to: value, current: value, lerp: lerp, ease: Linear}
+}
+
+// NewFloat creates a tween of a number
+func NewFloat(value float32) *Tween[float32] {
+	return NewTween(value, LerpFloat)
+}
+
+// NewColor creates a tween of an RGBA color
+func NewColor(value [4]float32) *Tween[[4]float32] {
+	return NewTween(value, LerpColor)
+}
+
+// Set stops any animation and jumps to a value
+func (a *Tween[T]) Set(value T) {
+	a.from, a.to, a.current = value, value, value
+	a.running = false
+}
+
+// To animates from the current value to a target over the duration using
+// the easing, nil for Linear. A duration of zero jumps straight there.
+func (a *Tween[T]) To(target T, duration time.Duration, ease Easing) {
+	if duration <= 0 {
+		a.Set(target)
+		return
+	}
+	if ease == nil {
+		ease = Linear
+	}
+	a.from, a.to = a.current, target
+	a.duration, a.ease = duration, ease
+	a.running, a.started = true, false
+}
+
+// Value returns the value at the clock's time. While the animation runs it
+// requests another frame from the clock.
+func (a *Tween[T]) Value(clock *Clock) T {
+	if !a.running {
+		return a.to
+	}
+	now := clock.Now()
+	if !a.started {
+		a.start, a.started = now, true
+	}
+	progress := float32(now.Sub(a.start)) / float32(a.duration)
+	if progress >= 1 {
+		a.running = false
+		a.current = a.to
+		return a.to
+	}
+	a.current = a.lerp(a.from, a.to, a.ease(progress))
+	clock.Request()
+	return a.current
+}
+
+// Running reports whether the value is still moving towards its target
+func (a *Tween[T]) Running() bool {
+	return a.running
+}
+
+// From returns the value the current animation started from
+func (a *Tween[T]) From() T {
+	return a.from
+}
+
+// Target returns the value being animated towards
+func (a *Tween[T]) Target() T {
+	return a.to
+}
+
+// LerpFloat interpolates between two numbers
+func LerpFloat(a, b float32, t float32) float32 {
+	return a + (b-a)*t
+}
+
+// LerpColor interpolates between two RGBA colors component by component
+func LerpColor(a, b [4]float32, t float32) (c [4]float32) {
+	for i := range c {
+		c[i] = a[i] + (b[i]-a[i])*t
+	}
+	return
+}
@@ -0,0 +1,39 @@
+// Package browser opens web addresses and files in the programs the user
+// chose for them, such as links clicked in the interface. Like the dialogs
+// package it drives the platform's own tools: xdg-open on Linux and the BSDs,
+// open on macOS and the shell's file associations on Windows.
+package browser
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+)
+
+// ErrUnavailable is returned when the system has no tool for opening
+// addresses
+var ErrUnavailable = errors.New("browser: not available on this system")
+
+// Open opens a web address, or a file, in the program the user chose for
+// it, without waiting for that program to exit
+func Open(url string) (err error) {
+	var command []string
+	switch runtime.GOOS {
+	case "darwin":
+		command = []string{"open", url}
+	case "windows":
+		command = []string{"rundll32", "url.dll,FileProtocolHandler", url}
+	default:
+		command = []string{"xdg-open", url}
+	}
+	if _, err = exec.LookPath(command[0]); err != nil {
+		return ErrUnavailable
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	if err = cmd.Start(); err != nil {
+		return
+	}
+	// Reap the tool once it has handed the address over
+	go cmd.Wait()
+	return
+}
@@ -0,0 +1,180 @@
+package chart
+
+import (
+	"math"
+
+	"github.com/mleku/goo/pkg/text"
+	"github.com/mleku/goo/pkg/widget"
+)
+
+// barGroupGap is the proportion of each category's width left empty
+// between its group of bars and the next
+const barGroupGap = 0.3
+
+// BarSeries is a named set of values, one for each category of a bar chart,
+// drawn in Color or, when it is transparent, the next color of the palette
+type BarSeries struct {
+	Name   string
+	Color  [4]float32
+	Values []float32
+}
+
+// BarChart draws a group of bars for each category, one bar from each
+// series, rising from zero against a value axis scaled to fit them.
+// Hovering a bar shows its value.
+type BarChart struct {
+	chart
+	categories []string
+	series     []BarSeries
+}
+
+// Bar creates a bar chart of the series over the named categories, drawing
+// its text in the font. If no constraints are provided, uses default
+// flexible constraints (0, 0, 1e9, 1e9).
+func Bar(font *text.Font, categories []string, series []BarSeries, constraints ...widget.Constraints) *BarChart {
+	return &BarChart{chart: newChart(font, constraints), categories: categories, series: series}
+}
+
+// SetData replaces the categories and the series plotted over them
+func (b *BarChart) SetData(categories []string, series []BarSeries) {
+	b.categories, b.series = categories, series
+	b.hover = none
+	b.MarkNeedsPaint()
+}
+
+// Series returns the plotted series
+func (b *BarChart) Series() []BarSeries {
+	return b.series
+}
+
+// Legend shows or hides the legend naming the series and returns the chart
+// for chaining
+func (b *BarChart) Legend(legend bool) *BarChart {
+	b.legend = legend
+	b.MarkNeedsPaint()
+	return b
+}
+
+// TextSize sets the pixel size of labels and returns the chart for chaining
+func (b *BarChart) TextSize(size float32) *BarChart {
+	b.size = size
+	b.MarkNeedsPaint()
+	return b
+}
+
+// Format sets how values are written on the axis and in tooltips, replacing
+// decimals chosen to suit the axis ticks, and returns the chart for chaining
+func (b *BarChart) Format(format func(v float32) string) *BarChart {
+	b.format = format
+	b.MarkNeedsPaint()
+	return b
+}
+
+// count returns the number of categories, which is at least the length of
+// the longest series
+func (b *BarChart) count() int {
+	n := len(b.categories)
+	for _, s := range b.series {
+		n = max(n, len(s.Values))
+	}
+	return n
+}
+
+// axis returns the value axis scaled to the values and zero
+func (b *BarChart) axis() axis {
+	var lo, hi float32
+	for _, s := range b.series {
+		for _, v := range s.Values {
+			lo, hi = min(lo, v), max(hi, v)
+		}
+	}
+	return niceAxis(lo, hi)
+}
+
+// entries returns the legend entries naming the series
+func (b *BarChart) entries() []legendEntry {
+	entries := make([]legendEntry, len(b.series))
+	for i, s := range b.series {
+		entries[i] = legendEntry{name: s.Name, color: colorAt(s.Color, i)}
+	}
+	return entries
+}
+
+// plot returns the plot area within the box and the value axis drawn along it
+func (b *BarChart) plot(box *widget.Box) (plot widget.Rect, a axis) {
+	a = b.axis()
+	plot = b.plotRect(box, b.labelWidth(a), b.legendWidth(b.entries()))
+	return
+}
+
+// bar returns the rect of the bar showing the index'th value of a series
+func (b *BarChart) bar(plot widget.Rect, a axis, series, index int) widget.Rect {
+	slot := plot.Width / float32(b.count())
+	width := slot * (1 - barGroupGap) / float32(len(b.series))
+	x := plot.X + slot*float32(index) + slot*barGroupGap/2 + width*float32(series)
+	bottom := plot.Y + plot.Height
+	zero := a.at(0, bottom, plot.Y)
+	top := a.at(b.series[series].Values[index], bottom, plot.Y)
+	return widget.Rect{X: x, Y: min(zero, top), Width: width, Height: float32(math.Abs(float64(top - zero)))}
+}
+
+// Paint implements the Widget interface for BarChart
+func (b *BarChart) Paint(ctx *widget.Context, box *widget.Box) (err error) {
+	plot, a := b.plot(box)
+	if plot.Empty() {
+		return
+	}
+	list := ctx.DrawList
+	b.drawValueAxis(ctx, plot, a)
+	n := b.count()
+	if n == 0 {
+		return
+	}
+	slot := plot.Width / float32(n)
+	for i, c := range b.categories {
+		b.drawBelow(ctx, plot, plot.X+slot*(float32(i)+0.5), c)
+	}
+	for i, s := range b.series {
+		for k := range s.Values {
+			r := b.bar(plot, a, i, k)
+			color := colorAt(s.Color, i)
+			if b.hover == (item{i, k}) {
+				color = lighten(color)
+			}
+			list.Rect(r.X, r.Y, r.Width, r.Height, color)
+		}
+	}
+	b.drawLegend(ctx, box, b.legendWidth(b.entries()), b.entries())
+	if h := b.hover; h != none && h.series < len(b.series) && h.index < len(b.series[h.series].Values) {
+		s := b.series[h.series]
+		tip := b.formatValue(s.Values[h.index], a.step)
+		if h.index < len(b.categories) {
+			tip = b.categories[h.index] + ": " + tip
+		}
+		if s.Name != "" {
+			tip = s.Name + ", " + tip
+		}
+		b.drawTooltip(ctx, box, tip)
+	}
+	return
+}
+
+// HandleEvent implements the Widget interface for BarChart, tracking the bar
+// under the cursor
+func (b *BarChart) HandleEvent(ctx *widget.Context, box *widget.Box, ev widget.Event) (handled bool) {
+	b.track(box, ev, func(at widget.Point) item {
+		if b.count() == 0 {
+			return none
+		}
+		plot, a := b.plot(box)
+		for i, s := range b.series {
+			for k := range s.Values {
+				if b.bar(plot, a, i, k).Contains(at) {
+					return item{i, k}
+				}
+			}
+		}
+		return none
+	})
+	return false
+}
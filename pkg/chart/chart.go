@@ -0,0 +1,357 @@
+// Package chart draws line, bar and pie charts of data series as widgets,
+// with axes scaled to the data, gridlines, tick labels, legends and
+// tooltips showing the value under the cursor.
+package chart
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/render"
+	"github.com/mleku/goo/pkg/text"
+	"github.com/mleku/goo/pkg/theme"
+	"github.com/mleku/goo/pkg/widget"
+)
+
+const (
+	// textSize is the default pixel size of labels, legends and tooltips
+	textSize = 12
+	// gap is the space between the plot, its labels and the legend
+	gap = 6
+	// tickLength is how far tick marks reach out of the plot
+	tickLength = 4
+	// tickCount is roughly how many ticks an axis is divided into
+	tickCount = 5
+	// swatchSize is the width and height of the color swatches in legends
+	swatchSize = 10
+	// hoverRadius is how close the cursor must come to a point to hover it
+	hoverRadius = 8
+	// tooltipPadding is the space around the text of a tooltip
+	tooltipPadding = 4
+	// tooltipCursorGap is how far from the cursor a tooltip is shown
+	tooltipCursorGap = 12
+)
+
+// Palette holds the colors series are drawn with when they set none, taken
+// in turn and repeated when there are more series
+var Palette = [][4]float32{
+	{0.26, 0.52, 0.96, 1},
+	{0.96, 0.49, 0.13, 1},
+	{0.2, 0.7, 0.35, 1},
+	{0.86, 0.22, 0.27, 1},
+	{0.58, 0.4, 0.74, 1},
+	{0.55, 0.34, 0.29, 1},
+	{0.89, 0.47, 0.76, 1},
+	{0.09, 0.75, 0.81, 1},
+}
+
+// colorAt returns a color, or the palette color of the i'th series when it
+// is transparent
+func colorAt(color [4]float32, i int) [4]float32 {
+	if color[3] > 0 {
+		return color
+	}
+	return Palette[i%len(Palette)]
+}
+
+// lighten returns a color brightened to highlight the item drawn in it
+func lighten(color [4]float32) [4]float32 {
+	for i := range 3 {
+		color[i] = min(color[i]+0.15, 1)
+	}
+	return color
+}
+
+// defaultTheme is used when the context sets no theme
+var defaultTheme = theme.Dark()
+
+// themeOf returns the theme charts in the context are drawn with
+func themeOf(ctx *widget.Context) *theme.Theme {
+	if ctx.Theme != nil {
+		return ctx.Theme
+	}
+	return defaultTheme
+}
+
+// item identifies a data item, the index'th value of a series
+type item struct {
+	series, index int
+}
+
+// none is the item hovered when the cursor is over no data
+var none = item{-1, -1}
+
+// chart holds the state shared by the chart widgets: the font their text is
+// drawn in, how values are formatted and what the cursor is over
+type chart struct {
+	widget.Base
+	font        *text.Font
+	size        float32
+	legend      bool
+	format      func(v float32) string
+	constraints widget.Constraints
+	// hover is the item under the cursor, shown with a tooltip at cursor
+	hover  item
+	cursor widget.Point
+	// path is reused to build the shapes of the data
+	path render.Path
+}
+
+// newChart creates the shared state of a chart drawing text in the font
+func newChart(font *text.Font, constraints []widget.Constraints) chart {
+	c := chart{
+		font:        font,
+		size:        textSize,
+		legend:      true,
+		hover:       none,
+		constraints: widget.NewFlexConstraints(0, 0, 1e9, 1e9),
+	}
+	if len(constraints) > 0 {
+		c.constraints = constraints[0]
+	}
+	return c
+}
+
+// GetConstraints returns the chart's constraints
+func (c *chart) GetConstraints() widget.Constraints {
+	return c.constraints
+}
+
+// Measure returns the minimum size of the chart
+func (c *chart) Measure(constraints widget.Constraints) widget.Size {
+	return widget.Size{Width: c.constraints.MinWidth, Height: c.constraints.MinHeight}
+}
+
+// Layout implements the Widget interface for charts, which take all the
+// space offered and fit the plot within it
+func (c *chart) Layout(ctx *widget.Context, constraints widget.Constraints) (size widget.Size, err error) {
+	size = widget.Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	c.SetLayout(ctx, constraints, size)
+	return
+}
+
+// face returns the face labels are drawn in
+func (c *chart) face() *text.Face {
+	return c.font.Face(c.size)
+}
+
+// formatValue returns a value as text, showing as many decimals as the step
+// between the values it is shown among needs, or as many as it has without
+// a step
+func (c *chart) formatValue(v, step float32) string {
+	if c.format != nil {
+		return c.format(v)
+	}
+	if step <= 0 {
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	}
+	decimals := 0
+	if step < 1 {
+		decimals = int(math.Ceil(-math.Log10(float64(step)) - 1e-6))
+	}
+	return strconv.FormatFloat(float64(v), 'f', decimals, 32)
+}
+
+// setHover changes the item under the cursor, repainting when the item or
+// the tooltip following the cursor changes
+func (c *chart) setHover(hover item, cursor widget.Point) {
+	if hover == c.hover && (hover == none || cursor == c.cursor) {
+		return
+	}
+	c.hover, c.cursor = hover, cursor
+	c.MarkNeedsPaint()
+}
+
+// track follows the cursor over the chart, looking up the item under it
+// with find
+func (c *chart) track(box *widget.Box, ev widget.Event, find func(p widget.Point) item) {
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		hover := none
+		if box.Contains(e.Position) {
+			hover = find(e.Position)
+		}
+		c.setHover(hover, e.Position)
+	case interfaces.CursorLeaveEvent:
+		c.setHover(none, c.cursor)
+	}
+}
+
+// legendEntry is a name shown in a legend beside the color it is drawn in
+type legendEntry struct {
+	name  string
+	color [4]float32
+}
+
+// legendWidth returns the width of a legend listing the entries, 0 when it
+// is hidden
+func (c *chart) legendWidth(entries []legendEntry) float32 {
+	if !c.legend || len(entries) == 0 {
+		return 0
+	}
+	face := c.face()
+	var width float32
+	for _, e := range entries {
+		width = max(width, face.Measure(e.name))
+	}
+	return swatchSize + gap + float32(math.Ceil(float64(width)))
+}
+
+// drawLegend lists the entries down from the top of the legend at the
+// right of the box, which is width wide
+func (c *chart) drawLegend(ctx *widget.Context, box *widget.Box, width float32, entries []legendEntry) {
+	if width <= 0 {
+		return
+	}
+	face := c.face()
+	list := ctx.DrawList
+	th := themeOf(ctx)
+	x := box.Position.X + box.Size.Width - width - gap
+	y := box.Position.Y + gap
+	line := face.LineHeight()
+	for _, e := range entries {
+		list.RoundRect(x, y+(line-swatchSize)/2, swatchSize, swatchSize, 2, e.color)
+		face.Draw(list, x+swatchSize+gap, y+face.Ascent(), e.name, th.Text)
+		y += line + gap/2
+	}
+}
+
+// drawTooltip shows a line of text in a bubble beside the cursor, kept
+// within the box
+func (c *chart) drawTooltip(ctx *widget.Context, box *widget.Box, s string) {
+	face := c.face()
+	list := ctx.DrawList
+	th := themeOf(ctx)
+	w := float32(math.Ceil(float64(face.Measure(s)))) + 2*tooltipPadding
+	h := float32(math.Ceil(float64(face.LineHeight()))) + 2*tooltipPadding
+	x, y := c.cursor.X+tooltipCursorGap, c.cursor.Y+tooltipCursorGap
+	if x+w > box.Position.X+box.Size.Width {
+		x = c.cursor.X - tooltipCursorGap - w
+	}
+	if y+h > box.Position.Y+box.Size.Height {
+		y = c.cursor.Y - tooltipCursorGap - h
+	}
+	x = max(x, box.Position.X)
+	y = max(y, box.Position.Y)
+	r := th.Radius.Small
+	list.RoundRect(x, y, w, h, r, th.Border)
+	list.RoundRect(x+1, y+1, w-2, h-2, max(r-1, 0), th.Surface)
+	face.Draw(list, x+tooltipPadding, y+tooltipPadding+face.Ascent(), s, th.Text)
+}
+
+// axis is the range of values along an axis, divided into ticks at
+// multiples of step
+type axis struct {
+	lo, hi, step float32
+}
+
+// niceAxis returns an axis covering lo to hi with ticks at round numbers,
+// about tickCount of them, widened to the ticks either side
+func niceAxis(lo, hi float32) axis {
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	if hi == lo {
+		// A single value is shown against zero
+		lo, hi = min(lo, 0), max(hi, 0)
+		if hi == lo {
+			hi = 1
+		}
+	}
+	step := niceStep(float64(hi-lo) / tickCount)
+	return axis{
+		lo:   float32(math.Floor(float64(lo)/step) * step),
+		hi:   float32(math.Ceil(float64(hi)/step) * step),
+		step: float32(step),
+	}
+}
+
+// niceStep returns the 1, 2 or 5 times a power of ten nearest above a step
+func niceStep(raw float64) float64 {
+	if raw <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if raw <= m*magnitude {
+			return m * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// ticks returns the values ticks are drawn at, from lo to hi
+func (a axis) ticks() (ticks []float32) {
+	n := int(math.Round(float64((a.hi - a.lo) / a.step)))
+	for i := 0; i <= n; i++ {
+		ticks = append(ticks, a.lo+float32(i)*a.step)
+	}
+	return
+}
+
+// at returns where a value lies between from and to, the positions of the
+// axis's low and high ends
+func (a axis) at(v, from, to float32) float32 {
+	if a.hi == a.lo {
+		return from
+	}
+	return from + (v-a.lo)/(a.hi-a.lo)*(to-from)
+}
+
+// labelWidth returns the width of the widest tick label of an axis
+func (c *chart) labelWidth(a axis) (width float32) {
+	face := c.face()
+	for _, t := range a.ticks() {
+		width = max(width, face.Measure(c.formatValue(t, a.step)))
+	}
+	return float32(math.Ceil(float64(width)))
+}
+
+// plotRect returns the part of the box left for the plot once room is made
+// for the labels of the value axis on the left, a row of labels below and
+// the legend on the right
+func (c *chart) plotRect(box *widget.Box, labelWidth, legendWidth float32) widget.Rect {
+	line := c.face().LineHeight()
+	left := labelWidth + gap + tickLength
+	right := float32(gap)
+	if legendWidth > 0 {
+		right += legendWidth + gap
+	}
+	top := line / 2
+	bottom := line + gap + tickLength
+	return widget.Rect{
+		X:      box.Position.X + left,
+		Y:      box.Position.Y + top,
+		Width:  max(box.Size.Width-left-right, 0),
+		Height: max(box.Size.Height-top-bottom, 0),
+	}
+}
+
+// drawValueAxis draws the gridlines and tick labels of an axis running up
+// the left of the plot
+func (c *chart) drawValueAxis(ctx *widget.Context, plot widget.Rect, a axis) {
+	face := c.face()
+	list := ctx.DrawList
+	th := themeOf(ctx)
+	bottom := plot.Y + plot.Height
+	for _, t := range a.ticks() {
+		y := a.at(t, bottom, plot.Y)
+		list.Line(plot.X, y, plot.X+plot.Width, y, 1, th.Track)
+		list.Line(plot.X-tickLength, y, plot.X, y, 1, th.Border)
+		s := c.formatValue(t, a.step)
+		face.Draw(list, plot.X-tickLength-gap/2-face.Measure(s), y+(face.Ascent()-face.Descent())/2, s, th.TextMuted)
+	}
+	list.Line(plot.X, plot.Y, plot.X, bottom, 1, th.Border)
+	list.Line(plot.X, bottom, plot.X+plot.Width, bottom, 1, th.Border)
+}
+
+// drawBelow draws a label centered on x below the plot, with a tick mark
+func (c *chart) drawBelow(ctx *widget.Context, plot widget.Rect, x float32, s string) {
+	face := c.face()
+	list := ctx.DrawList
+	th := themeOf(ctx)
+	bottom := plot.Y + plot.Height
+	list.Line(x, bottom, x, bottom+tickLength, 1, th.Border)
+	face.Draw(list, x-face.Measure(s)/2, bottom+tickLength+gap/2+face.Ascent(), s, th.TextMuted)
+}
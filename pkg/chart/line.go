@@ -0,0 +1,186 @@
+package chart
+
+import (
+	"math"
+
+	"github.com/mleku/goo/pkg/render"
+	"github.com/mleku/goo/pkg/text"
+	"github.com/mleku/goo/pkg/widget"
+)
+
+const (
+	// lineWidth is the width of the lines joining the points of a series
+	lineWidth = 2
+	// pointRadius is the radius of the dots marking the points of a series
+	pointRadius = 3
+)
+
+// LineSeries is a named run of points joined by a line, drawn in Color or,
+// when it is transparent, the next color of the palette
+type LineSeries struct {
+	Name   string
+	Color  [4]float32
+	Points []widget.Point
+}
+
+// LineChart plots series of points against numeric axes scaled to fit them,
+// joining the points of each series with a line. Hovering a point shows its
+// coordinates.
+type LineChart struct {
+	chart
+	series []LineSeries
+	// dots marks every point rather than only the one hovered
+	dots bool
+}
+
+// Line creates a line chart of the series drawing its text in the font.
+// If no constraints are provided, uses default flexible constraints (0, 0, 1e9, 1e9).
+func Line(font *text.Font, series []LineSeries, constraints ...widget.Constraints) *LineChart {
+	return &LineChart{chart: newChart(font, constraints), series: series}
+}
+
+// SetSeries replaces the plotted series
+func (l *LineChart) SetSeries(series []LineSeries) {
+	l.series = series
+	l.hover = none
+	l.MarkNeedsPaint()
+}
+
+// Series returns the plotted series
+func (l *LineChart) Series() []LineSeries {
+	return l.series
+}
+
+// Dots marks every point with a dot and returns the chart for chaining
+func (l *LineChart) Dots(dots bool) *LineChart {
+	l.dots = dots
+	l.MarkNeedsPaint()
+	return l
+}
+
+// Legend shows or hides the legend naming the series and returns the chart
+// for chaining
+func (l *LineChart) Legend(legend bool) *LineChart {
+	l.legend = legend
+	l.MarkNeedsPaint()
+	return l
+}
+
+// TextSize sets the pixel size of labels and returns the chart for chaining
+func (l *LineChart) TextSize(size float32) *LineChart {
+	l.size = size
+	l.MarkNeedsPaint()
+	return l
+}
+
+// Format sets how values are written on the axes and in tooltips, replacing
+// decimals chosen to suit the axis ticks, and returns the chart for chaining
+func (l *LineChart) Format(format func(v float32) string) *LineChart {
+	l.format = format
+	l.MarkNeedsPaint()
+	return l
+}
+
+// axes returns the axes scaled to the points of every series
+func (l *LineChart) axes() (x, y axis) {
+	x0, y0 := float32(math.MaxFloat32), float32(math.MaxFloat32)
+	x1, y1 := -x0, -y0
+	for _, s := range l.series {
+		for _, p := range s.Points {
+			x0, y0 = min(x0, p.X), min(y0, p.Y)
+			x1, y1 = max(x1, p.X), max(y1, p.Y)
+		}
+	}
+	if x0 > x1 {
+		x0, x1, y0, y1 = 0, 1, 0, 1
+	}
+	return niceAxis(x0, x1), niceAxis(y0, y1)
+}
+
+// entries returns the legend entries naming the series
+func (l *LineChart) entries() []legendEntry {
+	entries := make([]legendEntry, len(l.series))
+	for i, s := range l.series {
+		entries[i] = legendEntry{name: s.Name, color: colorAt(s.Color, i)}
+	}
+	return entries
+}
+
+// plot returns the plot area within the box and the axes drawn along it
+func (l *LineChart) plot(box *widget.Box) (plot widget.Rect, x, y axis) {
+	x, y = l.axes()
+	plot = l.plotRect(box, l.labelWidth(y), l.legendWidth(l.entries()))
+	return
+}
+
+// position returns where a point is drawn in the plot
+func position(plot widget.Rect, x, y axis, p widget.Point) (float32, float32) {
+	return x.at(p.X, plot.X, plot.X+plot.Width), y.at(p.Y, plot.Y+plot.Height, plot.Y)
+}
+
+// Paint implements the Widget interface for LineChart
+func (l *LineChart) Paint(ctx *widget.Context, box *widget.Box) (err error) {
+	plot, x, y := l.plot(box)
+	if plot.Empty() {
+		return
+	}
+	list := ctx.DrawList
+	l.drawValueAxis(ctx, plot, y)
+	for _, t := range x.ticks() {
+		l.drawBelow(ctx, plot, x.at(t, plot.X, plot.X+plot.Width), l.formatValue(t, x.step))
+	}
+	list.PushClip(plot.X-pointRadius-1, plot.Y-pointRadius-1, plot.Width+2*pointRadius+2, plot.Height+2*pointRadius+2)
+	for i, s := range l.series {
+		color := colorAt(s.Color, i)
+		l.path.Reset()
+		for k, p := range s.Points {
+			px, py := position(plot, x, y, p)
+			if k == 0 {
+				l.path.MoveTo(px, py)
+			} else {
+				l.path.LineTo(px, py)
+			}
+		}
+		list.StrokePath(&l.path, lineWidth, render.Solid(color))
+		if l.dots {
+			for _, p := range s.Points {
+				px, py := position(plot, x, y, p)
+				list.Circle(px, py, pointRadius, color)
+			}
+		}
+	}
+	list.PopClip()
+	l.drawLegend(ctx, box, l.legendWidth(l.entries()), l.entries())
+	if h := l.hover; h != none && h.series < len(l.series) && h.index < len(l.series[h.series].Points) {
+		s := l.series[h.series]
+		p := s.Points[h.index]
+		px, py := position(plot, x, y, p)
+		list.Circle(px, py, pointRadius+2, themeOf(ctx).Text)
+		list.Circle(px, py, pointRadius+1, colorAt(s.Color, h.series))
+		tip := "(" + l.formatValue(p.X, x.step) + ", " + l.formatValue(p.Y, y.step) + ")"
+		if s.Name != "" {
+			tip = s.Name + " " + tip
+		}
+		l.drawTooltip(ctx, box, tip)
+	}
+	return
+}
+
+// HandleEvent implements the Widget interface for LineChart, tracking the
+// point under the cursor
+func (l *LineChart) HandleEvent(ctx *widget.Context, box *widget.Box, ev widget.Event) (handled bool) {
+	l.track(box, ev, func(at widget.Point) item {
+		plot, x, y := l.plot(box)
+		nearest, best := none, float32(hoverRadius*hoverRadius)
+		for i, s := range l.series {
+			for k, p := range s.Points {
+				px, py := position(plot, x, y, p)
+				if d := (px-at.X)*(px-at.X) + (py-at.Y)*(py-at.Y); d <= best {
+					nearest, best = item{i, k}, d
+				}
+			}
+		}
+		return nearest
+	})
+	return false
+}
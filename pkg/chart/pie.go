@@ -0,0 +1,199 @@
+package chart
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/mleku/goo/pkg/render"
+	"github.com/mleku/goo/pkg/text"
+	"github.com/mleku/goo/pkg/widget"
+)
+
+// pieLift is how far the hovered slice of a pie is pulled out from the middle
+const pieLift = 6
+
+// Slice is a named share of a pie chart, drawn in Color or, when it is
+// transparent, the next color of the palette. Slices with values of zero or
+// less are left out.
+type Slice struct {
+	Label string
+	Color [4]float32
+	Value float32
+}
+
+// PieChart divides a circle into slices sized by their share of the total,
+// clockwise from the top, with a legend naming them. Hovering a slice pulls
+// it out and shows its value and share.
+type PieChart struct {
+	chart
+	slices []Slice
+	// hole is the radius of the hole in the middle as a proportion of the
+	// pie's, making a donut chart
+	hole float32
+}
+
+// Pie creates a pie chart of the slices drawing its text in the font.
+// If no constraints are provided, uses default flexible constraints (0, 0, 1e9, 1e9).
+func Pie(font *text.Font, slices []Slice, constraints ...widget.Constraints) *PieChart {
+	return &PieChart{chart: newChart(font, constraints), slices: slices}
+}
+
+// SetSlices replaces the slices
+func (p *PieChart) SetSlices(slices []Slice) {
+	p.slices = slices
+	p.hover = none
+	p.MarkNeedsPaint()
+}
+
+// Slices returns the slices
+func (p *PieChart) Slices() []Slice {
+	return p.slices
+}
+
+// Hole cuts a hole in the middle of the pie, its radius a proportion of the
+// pie's from 0 for none to below 1, and returns the chart for chaining
+func (p *PieChart) Hole(hole float32) *PieChart {
+	p.hole = min(max(hole, 0), 0.95)
+	p.MarkNeedsPaint()
+	return p
+}
+
+// Legend shows or hides the legend naming the slices and returns the chart
+// for chaining
+func (p *PieChart) Legend(legend bool) *PieChart {
+	p.legend = legend
+	p.MarkNeedsPaint()
+	return p
+}
+
+// TextSize sets the pixel size of labels and returns the chart for chaining
+func (p *PieChart) TextSize(size float32) *PieChart {
+	p.size = size
+	p.MarkNeedsPaint()
+	return p
+}
+
+// Format sets how values are written in tooltips and returns the chart for
+// chaining
+func (p *PieChart) Format(format func(v float32) string) *PieChart {
+	p.format = format
+	p.MarkNeedsPaint()
+	return p
+}
+
+// total returns the sum of the slices shown
+func (p *PieChart) total() (total float32) {
+	for _, s := range p.slices {
+		total += max(s.Value, 0)
+	}
+	return
+}
+
+// entries returns the legend entries naming the slices
+func (p *PieChart) entries() []legendEntry {
+	entries := make([]legendEntry, len(p.slices))
+	for i, s := range p.slices {
+		entries[i] = legendEntry{name: s.Label, color: colorAt(s.Color, i)}
+	}
+	return entries
+}
+
+// circle returns the center and radius of the pie within the box, leaving
+// room for the legend and for the hovered slice to be pulled out
+func (p *PieChart) circle(box *widget.Box) (cx, cy, radius float32) {
+	width := box.Size.Width - gap
+	if w := p.legendWidth(p.entries()); w > 0 {
+		width -= w + gap
+	}
+	radius = max(min(width, box.Size.Height)/2-gap-pieLift, 0)
+	return box.Position.X + width/2, box.Position.Y + box.Size.Height/2, radius
+}
+
+// angles returns the angles, in radians clockwise from the positive x axis,
+// the i'th slice runs between
+func (p *PieChart) angles(i int, total float32) (start, end float32) {
+	start = -math.Pi / 2
+	for k, s := range p.slices[:i+1] {
+		sweep := 2 * math.Pi * max(s.Value, 0) / total
+		if k == i {
+			return start, start + sweep
+		}
+		start += sweep
+	}
+	return
+}
+
+// Paint implements the Widget interface for PieChart
+func (p *PieChart) Paint(ctx *widget.Context, box *widget.Box) (err error) {
+	cx, cy, radius := p.circle(box)
+	total := p.total()
+	if radius <= 0 || total <= 0 {
+		p.drawLegend(ctx, box, p.legendWidth(p.entries()), p.entries())
+		return
+	}
+	list := ctx.DrawList
+	for i, s := range p.slices {
+		if s.Value <= 0 {
+			continue
+		}
+		start, end := p.angles(i, total)
+		x, y := cx, cy
+		color := colorAt(s.Color, i)
+		if p.hover.index == i {
+			mid := float64(start+end) / 2
+			x += pieLift * float32(math.Cos(mid))
+			y += pieLift * float32(math.Sin(mid))
+			color = lighten(color)
+		}
+		p.path.Reset()
+		if p.hole > 0 {
+			inner := radius * p.hole
+			p.path.Arc(x, y, radius, radius, start, end)
+			p.path.Arc(x, y, inner, inner, end, start)
+		} else {
+			p.path.MoveTo(x, y)
+			p.path.Arc(x, y, radius, radius, start, end)
+		}
+		list.FillPath(p.path.Close(), render.Solid(color))
+	}
+	p.drawLegend(ctx, box, p.legendWidth(p.entries()), p.entries())
+	if h := p.hover; h != none && h.index < len(p.slices) {
+		s := p.slices[h.index]
+		share := strconv.FormatFloat(float64(100*s.Value/total), 'f', 1, 32)
+		tip := p.formatValue(s.Value, 0) + " (" + share + "%)"
+		if s.Label != "" {
+			tip = s.Label + ": " + tip
+		}
+		p.drawTooltip(ctx, box, tip)
+	}
+	return
+}
+
+// HandleEvent implements the Widget interface for PieChart, tracking the
+// slice under the cursor
+func (p *PieChart) HandleEvent(ctx *widget.Context, box *widget.Box, ev widget.Event) (handled bool) {
+	p.track(box, ev, func(at widget.Point) item {
+		cx, cy, radius := p.circle(box)
+		total := p.total()
+		dx, dy := at.X-cx, at.Y-cy
+		d := float32(math.Sqrt(float64(dx*dx + dy*dy)))
+		if total <= 0 || d > radius+pieLift || d < radius*p.hole {
+			return none
+		}
+		// Measure the angle clockwise from the top, where the slices start
+		angle := float32(math.Atan2(float64(dy), float64(dx)))
+		if angle < -math.Pi/2 {
+			angle += 2 * math.Pi
+		}
+		for i, s := range p.slices {
+			if s.Value <= 0 {
+				continue
+			}
+			if start, end := p.angles(i, total); angle >= start && angle < end {
+				return item{0, i}
+			}
+		}
+		return none
+	})
+	return false
+}
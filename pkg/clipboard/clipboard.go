@@ -0,0 +1,182 @@
+// Package clipboard reads and writes the clipboard programs share, holding
+// text and images. A Clipboard keeps what it last saw of a System, such as
+// the operating system's clipboard, and is observable, so commands like
+// paste can enable themselves only while there is something to paste.
+//
+// Clipboards are not safe for concurrent use; use them from the goroutine
+// that runs the window.
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+
+	"github.com/mleku/goo/pkg/state"
+	"lol.mleku.dev/chk"
+)
+
+// ErrUnsupported is returned by systems that cannot hold images
+var ErrUnsupported = errors.New("clipboard: images are not supported")
+
+// System is a clipboard holding text or an image, such as the one the
+// operating system shares between programs
+type System interface {
+	// Text returns the text held, empty when there is none
+	Text() string
+	// SetText replaces the contents with text
+	SetText(s string)
+	// Image returns the image held encoded as PNG, nil when there is none
+	Image() (data []byte, err error)
+	// SetImage replaces the contents with an image encoded as PNG
+	SetImage(data []byte) (err error)
+	// HasImage reports whether an image is held without reading it
+	HasImage() bool
+}
+
+// Clipboard reads and writes a system clipboard, notifying its subscribers
+// when what it holds changes. Changes made through the clipboard are seen
+// at once; changes made by other programs are seen by Check, which windows
+// call when they gain focus, as that is when the user has been elsewhere.
+type Clipboard struct {
+	system System
+	// text is the text last seen and image whether an image was
+	text  string
+	image bool
+	// changes counts the changes seen, notifying the subscribers
+	changes *state.State[int]
+}
+
+// New creates a clipboard over a system clipboard, reading what it holds
+func New(system System) *Clipboard {
+	c := &Clipboard{system: system, changes: state.New(0)}
+	c.text, c.image = system.Text(), system.HasImage()
+	return c
+}
+
+// Text returns the text held, empty when there is none, and implements
+// interfaces.Clipboard
+func (c *Clipboard) Text() string {
+	s := c.system.Text()
+	c.seen(s, c.image)
+	return s
+}
+
+// SetText replaces the contents with text, and implements
+// interfaces.Clipboard
+func (c *Clipboard) SetText(s string) {
+	c.system.SetText(s)
+	c.seen(s, false)
+}
+
+// Image returns the image held, nil when there is none
+func (c *Clipboard) Image() (img image.Image, err error) {
+	var data []byte
+	if data, err = c.system.Image(); chk.E(err) || data == nil {
+		c.seen(c.text, false)
+		return
+	}
+	if img, err = png.Decode(bytes.NewReader(data)); chk.E(err) {
+		return
+	}
+	c.seen(c.text, true)
+	return
+}
+
+// SetImage replaces the contents with an image
+func (c *Clipboard) SetImage(img image.Image) (err error) {
+	var b bytes.Buffer
+	if err = png.Encode(&b, img); chk.E(err) {
+		return
+	}
+	if err = c.system.SetImage(b.Bytes()); chk.E(err) {
+		return
+	}
+	c.seen("", true)
+	return
+}
+
+// HasText reports whether text was held when the clipboard last looked
+func (c *Clipboard) HasText() bool {
+	return c.text != ""
+}
+
+// HasImage reports whether an image was held when the clipboard last looked
+func (c *Clipboard) HasImage() bool {
+	return c.image
+}
+
+// Check looks at what the system clipboard holds, notifying the
+// subscribers and reporting whether it changed since the clipboard last
+// looked
+func (c *Clipboard) Check() (changed bool) {
+	return c.seen(c.system.Text(), c.system.HasImage())
+}
+
+// Subscribe implements state.Observable, calling fn after each change seen
+// to what the clipboard holds
+func (c *Clipboard) Subscribe(fn func()) (cancel func()) {
+	return c.changes.Subscribe(fn)
+}
+
+// CanPasteText returns whether text is held as a value that follows the
+// clipboard, for binding the enabled state of a paste command
+func (c *Clipboard) CanPasteText() *state.Computed[bool] {
+	return state.Derive(c.HasText, c)
+}
+
+// CanPasteImage returns whether an image is held as a value that follows
+// the clipboard
+func (c *Clipboard) CanPasteImage() *state.Computed[bool] {
+	return state.Derive(c.HasImage, c)
+}
+
+// seen records what the clipboard holds, notifying the subscribers and
+// reporting whether it changed
+func (c *Clipboard) seen(text string, image bool) (changed bool) {
+	if text == c.text && image == c.image {
+		return false
+	}
+	c.text, c.image = text, image
+	c.changes.Set(c.changes.Get() + 1)
+	return true
+}
+
+// memory is a clipboard kept in memory
+type memory struct {
+	text  string
+	image []byte
+}
+
+// Memory returns a system clipboard kept in memory, private to the program,
+// for tests and for rendering without a window
+func Memory() System {
+	return &memory{}
+}
+
+// Text returns the text held
+func (m *memory) Text() string {
+	return m.text
+}
+
+// SetText replaces the contents with text
+func (m *memory) SetText(s string) {
+	m.text, m.image = s, nil
+}
+
+// Image returns the image held
+func (m *memory) Image() (data []byte, err error) {
+	return m.image, nil
+}
+
+// SetImage replaces the contents with an image
+func (m *memory) SetImage(data []byte) (err error) {
+	m.text, m.image = "", bytes.Clone(data)
+	return
+}
+
+// HasImage reports whether an image is held
+func (m *memory) HasImage() bool {
+	return m.image != nil
+}
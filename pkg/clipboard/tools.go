@@ -0,0 +1,203 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"lol.mleku.dev/chk"
+)
+
+// tools is a system clipboard reached through the command line tools of
+// the platform
+type tools struct {
+	// readText, writeText, readImage and writeImage are the commands
+	// reading and writing each, with the data passed on standard input and
+	// output, and hasImage lists what is held, naming an image when there is
+	// one
+	readText, writeText   []string
+	readImage, writeImage []string
+	hasImage              []string
+	// hexImage is set when readImage writes the image as AppleScript data
+	hexImage bool
+	// script reads and writes images with scripts given the path of a
+	// temporary file, where the platform's tools cannot stream them
+	script func(path string, write bool) []string
+}
+
+// Tools returns the system clipboard of the platform reached through its
+// command line tools, which hold images as well as text: wl-copy and
+// wl-paste under Wayland or xclip under X11, pbcopy, pbpaste and osascript
+// on macOS and PowerShell on Windows. Images are unsupported where the
+// tools are missing. Windows opened by the window package read text
+// through GLFW and use these tools for images only.
+func Tools() System {
+	switch runtime.GOOS {
+	case "darwin":
+		return &tools{
+			readText:  []string{"pbpaste"},
+			writeText: []string{"pbcopy"},
+			readImage: []string{"osascript", "-e", "the clipboard as «class PNGf»"},
+			hasImage:  []string{"osascript", "-e", "clipboard info for «class PNGf»"},
+			hexImage:  true,
+			script: func(path string, write bool) []string {
+				return []string{"osascript", "-e", `set the clipboard to (read (POSIX file "` + path + `") as «class PNGf»)`}
+			},
+		}
+	case "windows":
+		forms := "Add-Type -AssemblyName System.Windows.Forms, System.Drawing; "
+		return &tools{
+			readText:  []string{"powershell", "-NoProfile", "-Command", "Get-Clipboard -Raw"},
+			writeText: []string{"powershell", "-NoProfile", "-Command", "$input | Set-Clipboard"},
+			hasImage:  []string{"powershell", "-NoProfile", "-STA", "-Command", forms + "[Windows.Forms.Clipboard]::ContainsImage()"},
+			script: func(path string, write bool) []string {
+				if write {
+					return []string{"powershell", "-NoProfile", "-STA", "-Command",
+						forms + "[Windows.Forms.Clipboard]::SetImage([Drawing.Image]::FromFile('" + path + "'))"}
+				}
+				return []string{"powershell", "-NoProfile", "-STA", "-Command",
+					forms + "$i = [Windows.Forms.Clipboard]::GetImage(); if ($i) { $i.Save('" + path + "', [Drawing.Imaging.ImageFormat]::Png) }"}
+			},
+		}
+	}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		if _, err := exec.LookPath("wl-paste"); err == nil {
+			return &tools{
+				readText:   []string{"wl-paste", "--no-newline"},
+				writeText:  []string{"wl-copy"},
+				readImage:  []string{"wl-paste", "--no-newline", "--type", "image/png"},
+				writeImage: []string{"wl-copy", "--type", "image/png"},
+				hasImage:   []string{"wl-paste", "--list-types"},
+			}
+		}
+	}
+	return &tools{
+		readText:   []string{"xclip", "-selection", "clipboard", "-out"},
+		writeText:  []string{"xclip", "-selection", "clipboard", "-in"},
+		readImage:  []string{"xclip", "-selection", "clipboard", "-target", "image/png", "-out"},
+		writeImage: []string{"xclip", "-selection", "clipboard", "-target", "image/png", "-in"},
+		hasImage:   []string{"xclip", "-selection", "clipboard", "-target", "TARGETS", "-out"},
+	}
+}
+
+// HasImageTools reports whether the tools Tools reads images with are
+// installed
+func HasImageTools() bool {
+	t := Tools().(*tools)
+	command := t.readImage
+	if command == nil && t.script != nil {
+		command = t.script("", false)
+	}
+	if command == nil {
+		return false
+	}
+	_, err := exec.LookPath(command[0])
+	return err == nil
+}
+
+// Text returns the text held, empty when there is none or the tool is missing
+func (t *tools) Text() string {
+	out, err := run(t.readText, nil)
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
+
+// SetText replaces the contents with text
+func (t *tools) SetText(s string) {
+	_, _ = run(t.writeText, []byte(s))
+}
+
+// Image returns the image held encoded as PNG
+func (t *tools) Image() (data []byte, err error) {
+	switch {
+	case t.readImage != nil && t.hexImage:
+		// AppleScript writes the data as «data PNGf89504E47...»
+		var out []byte
+		if out, err = run(t.readImage, nil); err != nil {
+			return nil, nil
+		}
+		s := strings.TrimSpace(string(out))
+		s = strings.TrimSuffix(strings.TrimPrefix(s, "«data PNGf"), "»")
+		if data, err = hex.DecodeString(s); chk.E(err) {
+			return
+		}
+	case t.readImage != nil:
+		if data, err = run(t.readImage, nil); err != nil || !bytes.HasPrefix(data, pngSignature) {
+			return nil, nil
+		}
+	case t.script != nil:
+		return t.viaFile(nil)
+	default:
+		return nil, ErrUnsupported
+	}
+	return
+}
+
+// SetImage replaces the contents with an image encoded as PNG
+func (t *tools) SetImage(data []byte) (err error) {
+	switch {
+	case t.writeImage != nil:
+		_, err = run(t.writeImage, data)
+	case t.script != nil:
+		_, err = t.viaFile(data)
+	default:
+		err = ErrUnsupported
+	}
+	return
+}
+
+// HasImage reports whether an image is held
+func (t *tools) HasImage() bool {
+	out, err := run(t.hasImage, nil)
+	if err != nil {
+		return false
+	}
+	s := strings.ToLower(string(out))
+	return strings.Contains(s, "image/png") || strings.Contains(s, "pngf") || strings.TrimSpace(s) == "true"
+}
+
+// viaFile writes an image through a temporary file, or reads one when data
+// is nil
+func (t *tools) viaFile(data []byte) (image []byte, err error) {
+	var f *os.File
+	if f, err = os.CreateTemp("", "clipboard-*.png"); chk.E(err) {
+		return
+	}
+	path := f.Name()
+	defer os.Remove(path)
+	if _, err = f.Write(data); chk.E(err) {
+		f.Close()
+		return
+	}
+	if err = f.Close(); chk.E(err) {
+		return
+	}
+	if _, err = run(t.script(path, data != nil), nil); err != nil || data != nil {
+		return
+	}
+	if image, err = os.ReadFile(path); chk.E(err) || len(image) == 0 {
+		return nil, err
+	}
+	return
+}
+
+// pngSignature starts every PNG file
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// run runs a command with input on its standard input, returning its
+// standard output. A missing command fails like one that found nothing.
+func run(command []string, input []byte) (out []byte, err error) {
+	if len(command) == 0 {
+		return nil, ErrUnsupported
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	if input != nil {
+		cmd.Stdin = bytes.NewReader(input)
+	}
+	return cmd.Output()
+}
@@ -0,0 +1,135 @@
+// Package dialogs shows the system's dialogs for choosing files to open,
+// where to save a file and folders. The dialogs are run by the platform's
+// own tools while the program keeps drawing, and their result is passed to
+// a callback.
+//
+// Where the system has no dialogs, such as on a Linux desktop without
+// zenity or kdialog, the callbacks receive ErrUnavailable and programs fall
+// back to a file browser of their own.
+package dialogs
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+var (
+	// ErrCancelled is passed to the callback when the user closes the
+	// dialog without choosing
+	ErrCancelled = errors.New("dialogs: cancelled")
+	// ErrUnavailable is passed to the callback when the system has no
+	// dialogs
+	ErrUnavailable = errors.New("dialogs: no system dialogs available")
+)
+
+// Filter limits the files offered to those matching its patterns, such as
+// "*.png", under a name such as "Images"
+type Filter struct {
+	Name     string
+	Patterns []string
+}
+
+// Options configure a dialog
+type Options struct {
+	// Title is shown in the dialog's title bar
+	Title string
+	// Directory is where the dialog starts, the system's choice when empty
+	Directory string
+	// Filename is the name a save dialog suggests
+	Filename string
+	// Filters limit the files offered, the first being chosen at first. All
+	// files are offered when there are none.
+	Filters []Filter
+	// Multiple lets an open dialog choose several files
+	Multiple bool
+	// Post runs the callback, given the call, on the goroutine running the
+	// window so it can update widgets. Without it the callback runs on a
+	// goroutine of its own and must hand the result to the window itself.
+	Post func(fn func())
+}
+
+// kind is the kind of dialog shown
+type kind int
+
+const (
+	openFiles kind = iota
+	saveFile
+	pickDirectory
+)
+
+// Open shows a dialog choosing files to open and passes their paths to
+// done, which receives ErrCancelled when none were chosen. It returns at
+// once, leaving the window running while the dialog is shown.
+func Open(options Options, done func(paths []string, err error)) {
+	show(openFiles, options, done)
+}
+
+// Save shows a dialog choosing where to save a file and passes its path to
+// done. Systems that ask whether to replace an existing file do.
+func Save(options Options, done func(path string, err error)) {
+	show(saveFile, options, func(paths []string, err error) {
+		done(first(paths), err)
+	})
+}
+
+// Directory shows a dialog choosing a folder and passes its path to done
+func Directory(options Options, done func(path string, err error)) {
+	show(pickDirectory, options, func(paths []string, err error) {
+		done(first(paths), err)
+	})
+}
+
+// Available reports whether the system has dialogs to show
+func Available() bool {
+	_, ok := tool()
+	return ok
+}
+
+// show runs a dialog on a goroutine of its own and passes the result to
+// done through the options' Post
+func show(k kind, options Options, done func(paths []string, err error)) {
+	go func() {
+		paths, err := run(k, options)
+		if options.Post != nil {
+			options.Post(func() { done(paths, err) })
+			return
+		}
+		done(paths, err)
+	}()
+}
+
+// run shows a dialog with the system's tool and waits for the paths chosen
+func run(k kind, options Options) (paths []string, err error) {
+	t, ok := tool()
+	if !ok {
+		return nil, ErrUnavailable
+	}
+	command := t.command(k, options)
+	out, err := exec.Command(command[0], command[1:]...).Output()
+	if err != nil {
+		// The tools exit with an error when the dialog is cancelled
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			return nil, ErrCancelled
+		}
+		return nil, err
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(string(out), "\r\n", "\n"), "\n") {
+		if line != "" {
+			paths = append(paths, line)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, ErrCancelled
+	}
+	return
+}
+
+// first returns the first of the paths, empty when there are none
+func first(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	return paths[0]
+}
@@ -0,0 +1,222 @@
+package dialogs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// native is a tool showing the system's dialogs, building the command line
+// that shows each kind. The commands print the chosen paths a line each and
+// exit with an error when cancelled.
+type native struct {
+	name    string
+	command func(k kind, options Options) []string
+}
+
+// tool returns the tool showing dialogs on this system: osascript on macOS,
+// PowerShell on Windows and zenity or kdialog elsewhere, preferring kdialog
+// on KDE desktops
+func tool() (t native, ok bool) {
+	switch runtime.GOOS {
+	case "darwin":
+		return native{"osascript", appleScript}, true
+	case "windows":
+		return native{"powershell", powerShell}, true
+	}
+	tools := []native{{"zenity", zenity}, {"kdialog", kdialog}}
+	if os.Getenv("KDE_FULL_SESSION") != "" {
+		slices.Reverse(tools)
+	}
+	for _, t = range tools {
+		if _, err := exec.LookPath(t.name); err == nil {
+			return t, true
+		}
+	}
+	return native{}, false
+}
+
+// zenity builds the command showing a dialog with the GTK zenity tool
+func zenity(k kind, o Options) []string {
+	args := []string{"zenity", "--file-selection"}
+	if o.Title != "" {
+		args = append(args, "--title="+o.Title)
+	}
+	switch k {
+	case openFiles:
+		if o.Multiple {
+			args = append(args, "--multiple", "--separator=\n")
+		}
+	case saveFile:
+		args = append(args, "--save", "--confirm-overwrite")
+	case pickDirectory:
+		args = append(args, "--directory")
+	}
+	// A directory given with a trailing separator is opened rather than chosen
+	if start := startPath(k, o); start != "" {
+		args = append(args, "--filename="+start)
+	}
+	if k != pickDirectory {
+		for _, f := range o.Filters {
+			args = append(args, "--file-filter="+f.Name+" | "+strings.Join(f.Patterns, " "))
+		}
+	}
+	return args
+}
+
+// kdialog builds the command showing a dialog with the KDE kdialog tool
+func kdialog(k kind, o Options) []string {
+	args := []string{"kdialog"}
+	if o.Title != "" {
+		args = append(args, "--title", o.Title)
+	}
+	start := startPath(k, o)
+	if start == "" {
+		start = "."
+	}
+	switch k {
+	case openFiles:
+		if o.Multiple {
+			args = append(args, "--multiple", "--separate-output")
+		}
+		args = append(args, "--getopenfilename", start)
+	case saveFile:
+		args = append(args, "--getsavefilename", start)
+	case pickDirectory:
+		return append(args, "--getexistingdirectory", start)
+	}
+	// Filters are written as "*.png *.jpg|Images", a line each
+	filters := make([]string, len(o.Filters))
+	for i, f := range o.Filters {
+		filters[i] = strings.Join(f.Patterns, " ") + "|" + f.Name
+	}
+	if len(filters) > 0 {
+		args = append(args, strings.Join(filters, "\n"))
+	}
+	return args
+}
+
+// appleScript builds the command showing a dialog with AppleScript on macOS
+func appleScript(k kind, o Options) []string {
+	var choose strings.Builder
+	switch k {
+	case openFiles:
+		choose.WriteString("choose file")
+	case saveFile:
+		choose.WriteString("choose file name")
+	case pickDirectory:
+		choose.WriteString("choose folder")
+	}
+	if o.Title != "" {
+		choose.WriteString(" with prompt " + appleString(o.Title))
+	}
+	if k == openFiles {
+		var types []string
+		for _, f := range o.Filters {
+			for _, p := range f.Patterns {
+				if ext := strings.TrimPrefix(p, "*."); ext != p && !strings.ContainsAny(ext, "*?") {
+					types = append(types, appleString(ext))
+				}
+			}
+		}
+		if len(types) > 0 {
+			choose.WriteString(" of type {" + strings.Join(types, ", ") + "}")
+		}
+	}
+	if k == saveFile && o.Filename != "" {
+		choose.WriteString(" default name " + appleString(o.Filename))
+	}
+	if o.Directory != "" {
+		choose.WriteString(" default location (POSIX file " + appleString(o.Directory) + ")")
+	}
+	if k == openFiles && o.Multiple {
+		choose.WriteString(" with multiple selections allowed")
+	}
+	// Choosing one file gives an alias rather than a list, so one is made
+	lines := []string{
+		"set chosen to " + choose.String(),
+		"if class of chosen is not list then set chosen to {chosen}",
+		`set out to ""`,
+		"repeat with f in chosen",
+		"set out to out & POSIX path of f & linefeed",
+		"end repeat",
+		"return out",
+	}
+	args := []string{"osascript"}
+	for _, line := range lines {
+		args = append(args, "-e", line)
+	}
+	return args
+}
+
+// appleString quotes a string for AppleScript
+func appleString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// powerShell builds the command showing a Windows Forms dialog through
+// PowerShell on Windows
+func powerShell(k kind, o Options) []string {
+	var script strings.Builder
+	script.WriteString("Add-Type -AssemblyName System.Windows.Forms; ")
+	switch k {
+	case openFiles, saveFile:
+		if k == openFiles {
+			script.WriteString("$d = New-Object System.Windows.Forms.OpenFileDialog; ")
+			if o.Multiple {
+				script.WriteString("$d.Multiselect = $true; ")
+			}
+		} else {
+			script.WriteString("$d = New-Object System.Windows.Forms.SaveFileDialog; ")
+			if o.Filename != "" {
+				script.WriteString("$d.FileName = " + powerShellString(o.Filename) + "; ")
+			}
+		}
+		if o.Title != "" {
+			script.WriteString("$d.Title = " + powerShellString(o.Title) + "; ")
+		}
+		if o.Directory != "" {
+			script.WriteString("$d.InitialDirectory = " + powerShellString(o.Directory) + "; ")
+		}
+		// Filters are written as "Images|*.png;*.jpg", joined by bars
+		filters := make([]string, len(o.Filters))
+		for i, f := range o.Filters {
+			filters[i] = f.Name + "|" + strings.Join(f.Patterns, ";")
+		}
+		if len(filters) > 0 {
+			script.WriteString("$d.Filter = " + powerShellString(strings.Join(filters, "|")) + "; ")
+		}
+		script.WriteString("if ($d.ShowDialog() -eq 'OK') { $d.FileNames -join \"`n\" } else { exit 1 }")
+	case pickDirectory:
+		script.WriteString("$d = New-Object System.Windows.Forms.FolderBrowserDialog; ")
+		if o.Title != "" {
+			script.WriteString("$d.Description = " + powerShellString(o.Title) + "; ")
+		}
+		if o.Directory != "" {
+			script.WriteString("$d.SelectedPath = " + powerShellString(o.Directory) + "; ")
+		}
+		script.WriteString("if ($d.ShowDialog() -eq 'OK') { $d.SelectedPath } else { exit 1 }")
+	}
+	return []string{"powershell", "-NoProfile", "-STA", "-Command", script.String()}
+}
+
+// powerShellString quotes a string for PowerShell
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// startPath returns the path a dialog starts at: the suggested file name in
+// the directory for a save dialog, otherwise the directory with a trailing
+// separator, empty when neither is set
+func startPath(k kind, o Options) string {
+	if k == saveFile && o.Filename != "" {
+		return filepath.Join(o.Directory, o.Filename)
+	}
+	if o.Directory == "" {
+		return ""
+	}
+	return strings.TrimSuffix(o.Directory, string(filepath.Separator)) + string(filepath.Separator)
+}
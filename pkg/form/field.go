@@ -0,0 +1,247 @@
+package form
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mleku/goo/pkg/text"
+	"github.com/mleku/goo/pkg/theme"
+	"github.com/mleku/goo/pkg/widget"
+)
+
+// DateLayout is the layout dates are typed in and shown with
+const DateLayout = time.DateOnly
+
+const (
+	// messageSize is the pixel size of the error messages below fields
+	messageSize = 12
+	// messageGap is the space between a field and its error message
+	messageGap = 2
+)
+
+// kind is the kind of value a field edits
+type kind int
+
+const (
+	textField kind = iota
+	numberField
+	checkboxField
+	dropdownField
+	dateField
+)
+
+// Values holds the values of a form's fields by name, typed by the kind of
+// field: a string for text and dropdown fields, a float64 for numbers, a
+// bool for checkboxes and a time.Time for dates. Numbers and dates left
+// empty are missing, as are dropdowns with nothing selected.
+type Values map[string]any
+
+// String returns the value of a text or dropdown field, empty if missing
+func (v Values) String(name string) string {
+	s, _ := v[name].(string)
+	return s
+}
+
+// Float returns the value of a number field, zero if missing
+func (v Values) Float(name string) float64 {
+	f, _ := v[name].(float64)
+	return f
+}
+
+// Bool returns the value of a checkbox
+func (v Values) Bool(name string) bool {
+	b, _ := v[name].(bool)
+	return b
+}
+
+// Time returns the value of a date field, the zero time if missing
+func (v Values) Time(name string) time.Time {
+	t, _ := v[name].(time.Time)
+	return t
+}
+
+// field is a labelled input of a form and the state of its validation
+type field struct {
+	name       string
+	kind       kind
+	validators []Validator
+	label      *widget.LabelWidget
+	input      widget.Widget
+	message    *message
+	// clean is the value the field is not dirty at, set by SetValues and by
+	// submitting
+	clean any
+	// err is why the value was rejected when last validated, nil if it was
+	// not
+	err error
+}
+
+// value returns the typed value of the field, nil for an empty number or
+// date, or why the text typed could not be read
+func (f *field) value() (v any, err error) {
+	switch in := f.input.(type) {
+	case *widget.CheckboxWidget:
+		return in.IsChecked(), nil
+	case *widget.DropdownWidget:
+		if in.Selected() < 0 {
+			return nil, nil
+		}
+		return in.SelectedOption(), nil
+	case *widget.TextInputWidget:
+		s := in.Text()
+		switch {
+		case f.kind == textField:
+			return s, nil
+		case strings.TrimSpace(s) == "":
+			return nil, nil
+		case f.kind == numberField:
+			var n float64
+			if n, err = strconv.ParseFloat(strings.TrimSpace(s), 64); err != nil {
+				return nil, errors.New("must be a number")
+			}
+			return n, nil
+		default:
+			var t time.Time
+			if t, err = time.Parse(DateLayout, strings.TrimSpace(s)); err != nil {
+				return nil, errors.New("must be a date as YYYY-MM-DD")
+			}
+			return t, nil
+		}
+	}
+	return
+}
+
+// set shows a typed value in the input, emptying it for nil
+func (f *field) set(v any) {
+	switch in := f.input.(type) {
+	case *widget.CheckboxWidget:
+		b, _ := v.(bool)
+		in.SetChecked(b)
+	case *widget.DropdownWidget:
+		s, _ := v.(string)
+		in.Select(slices.Index(in.Options(), s))
+	case *widget.TextInputWidget:
+		switch v := v.(type) {
+		case string:
+			in.SetText(v)
+		case float64:
+			in.SetText(formatNumber(v))
+		case time.Time:
+			in.SetText(v.Format(DateLayout))
+		default:
+			in.SetText("")
+		}
+	}
+}
+
+// validate reads and checks the value, showing the first error found below
+// the field, and reports whether the value was accepted
+func (f *field) validate() bool {
+	v, err := f.value()
+	for _, check := range f.validators {
+		if err != nil {
+			break
+		}
+		err = check(v)
+	}
+	f.err = err
+	f.message.set(err)
+	return err == nil
+}
+
+// dirty reports whether the value differs from the clean one. Text that
+// cannot be read is dirty.
+func (f *field) dirty() bool {
+	v, err := f.value()
+	if err != nil {
+		return true
+	}
+	if t, ok := v.(time.Time); ok {
+		c, ok := f.clean.(time.Time)
+		return !ok || !t.Equal(c)
+	}
+	return v != f.clean
+}
+
+// message shows a field's error in the theme's error color below it, and
+// takes no space while there is none
+type message struct {
+	widget.Base
+	font *text.Font
+	text string
+}
+
+// set shows the message of an error, or hides it for nil
+func (m *message) set(err error) {
+	s := ""
+	if err != nil {
+		s = err.Error()
+	}
+	if s == m.text {
+		return
+	}
+	m.text = s
+	m.MarkNeedsLayout()
+}
+
+// GetConstraints returns the height of a line while there is a message
+func (m *message) GetConstraints() widget.Constraints {
+	if m.text == "" {
+		return widget.NewFlexConstraints(0, 0, 1e9, 0)
+	}
+	face := m.font.Face(messageSize)
+	height := face.LineHeight() + messageGap
+	return widget.NewFlexConstraints(face.Measure(m.text), height, 1e9, height)
+}
+
+// Measure returns the size of the message
+func (m *message) Measure(constraints widget.Constraints) widget.Size {
+	c := m.GetConstraints()
+	return widget.Size{Width: c.MinWidth, Height: c.MinHeight}
+}
+
+// Layout implements the Widget interface for message
+func (m *message) Layout(ctx *widget.Context, constraints widget.Constraints) (size widget.Size, err error) {
+	size = widget.Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	m.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for message, drawing it at the start
+// of the line
+func (m *message) Paint(ctx *widget.Context, box *widget.Box) (err error) {
+	if m.text == "" {
+		return
+	}
+	face := m.font.Face(messageSize)
+	align := text.AlignStart
+	if ctx.RTL {
+		align = align.Mirrored()
+	}
+	list := ctx.DrawList
+	list.PushClip(box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height)
+	defer list.PopClip()
+	x := box.Position.X + align.Offset(face.Measure(m.text), box.Size.Width)
+	face.Draw(list, x, box.Position.Y+messageGap+face.Ascent(), m.text, themeOf(ctx).Error)
+	return
+}
+
+// HandleEvent implements the Widget interface for message, which takes no
+// input
+func (m *message) HandleEvent(ctx *widget.Context, box *widget.Box, ev widget.Event) (handled bool) {
+	return false
+}
+
+// defaultTheme is used when the context sets no theme
+var defaultTheme = theme.Dark()
+
+// themeOf returns the theme forms in the context are drawn with
+func themeOf(ctx *widget.Context) *theme.Theme {
+	if ctx.Theme != nil {
+		return ctx.Theme
+	}
+	return defaultTheme
+}
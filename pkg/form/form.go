@@ -0,0 +1,270 @@
+// Package form builds forms from declared fields: text, numbers,
+// checkboxes, dropdowns and dates, each checked by validators. A form lays
+// its fields out in a grid with their labels aligned in a column beside
+// them, shows why a value was rejected below its field, tracks which fields
+// were changed and hands the values over, typed, when submitted.
+//
+//	f := form.New(font).
+//		Text("name", "Name", form.Required()).
+//		Number("age", "Age", form.Range(0, 150)).
+//		Checkbox("news", "Newsletter").
+//		OnSubmit(func(v form.Values) { save(v.String("name"), v.Float("age")) })
+package form
+
+import (
+	"slices"
+
+	"github.com/mleku/goo/pkg/text"
+	"github.com/mleku/goo/pkg/widget"
+)
+
+const (
+	// labelGap separates the column of labels from the fields
+	labelGap = 12
+	// rowGap separates the rows of fields
+	rowGap = 8
+)
+
+// Form is a column of labelled fields. It is a widget.Container, so buttons
+// and other widgets can be added below the fields with Rigid and Flex, such
+// as a button calling Submit.
+//
+// Fields are validated as they are edited, and all of them when the form is
+// submitted, which only hands over the values when every field is valid.
+// Pressing enter in a text field submits the form.
+type Form struct {
+	*widget.Container
+	font     *text.Font
+	size     float32
+	fields   []*field
+	labels   []*widget.ConstrainedBoxWidget
+	indents  []*widget.ConstrainedBoxWidget
+	onSubmit func(values Values)
+	onChange func(name string, values Values)
+}
+
+// New creates a new empty form with labels drawn in the given font at 14
+// pixels
+func New(font *text.Font) *Form {
+	return &Form{Container: widget.Column(), font: font, size: 14}
+}
+
+// Text appends a field editing a line of text and returns the form for
+// chaining
+func (f *Form) Text(name, label string, validators ...Validator) *Form {
+	return f.add(name, label, textField, f.textInput(""), validators)
+}
+
+// Number appends a field editing a number and returns the form for
+// chaining. Text that is not a number is rejected.
+func (f *Form) Number(name, label string, validators ...Validator) *Form {
+	return f.add(name, label, numberField, f.textInput(""), validators)
+}
+
+// Checkbox appends a field that is checked or not and returns the form for
+// chaining
+func (f *Form) Checkbox(name, label string, validators ...Validator) *Form {
+	in := widget.Checkbox(f.font, "").Size(f.size)
+	return f.add(name, label, checkboxField, in, validators)
+}
+
+// Dropdown appends a field choosing one of the options and returns the
+// form for chaining
+func (f *Form) Dropdown(name, label string, options []string, validators ...Validator) *Form {
+	in := widget.Dropdown(f.font, options...).Size(f.size)
+	return f.add(name, label, dropdownField, in, validators)
+}
+
+// Date appends a field editing a date typed as in DateLayout and returns
+// the form for chaining
+func (f *Form) Date(name, label string, validators ...Validator) *Form {
+	return f.add(name, label, dateField, f.textInput("YYYY-MM-DD"), validators)
+}
+
+// OnSubmit sets the callback invoked with the values when the form is
+// submitted with every field valid, and returns the form for chaining
+func (f *Form) OnSubmit(fn func(values Values)) *Form {
+	f.onSubmit = fn
+	return f
+}
+
+// OnChange sets the callback invoked with the name of the field and the
+// values of the form when the user edits a field, and returns the form for
+// chaining
+func (f *Form) OnChange(fn func(name string, values Values)) *Form {
+	f.onChange = fn
+	return f
+}
+
+// Input returns the widget editing the named field, to set a placeholder
+// or the like, nil if there is no such field. Text, number and date fields
+// are edited by a *widget.TextInputWidget, checkboxes by a
+// *widget.CheckboxWidget and dropdowns by a *widget.DropdownWidget.
+func (f *Form) Input(name string) widget.Widget {
+	if fl := f.field(name); fl != nil {
+		return fl.input
+	}
+	return nil
+}
+
+// Values returns the values of the fields that can be read. Numbers and
+// dates whose text cannot be read are missing, as are empty ones.
+func (f *Form) Values() Values {
+	values := make(Values, len(f.fields))
+	for _, fl := range f.fields {
+		if v, err := fl.value(); err == nil && v != nil {
+			values[fl.name] = v
+		}
+	}
+	return values
+}
+
+// SetValues shows the values in their fields, emptying the fields missing,
+// and takes them as the state the form is not dirty in, as when editing a
+// stored record. Errors shown are cleared.
+func (f *Form) SetValues(values Values) {
+	for _, fl := range f.fields {
+		fl.set(values[fl.name])
+		fl.clean, _ = fl.value()
+		fl.err = nil
+		fl.message.set(nil)
+	}
+}
+
+// Validate checks every field, showing the errors found below them, and
+// reports whether they are all valid
+func (f *Form) Validate() (valid bool) {
+	valid = true
+	for _, fl := range f.fields {
+		if !fl.validate() {
+			valid = false
+		}
+	}
+	return
+}
+
+// Error returns why the value of the named field was rejected when last
+// validated, nil if it was not
+func (f *Form) Error(name string) error {
+	if fl := f.field(name); fl != nil {
+		return fl.err
+	}
+	return nil
+}
+
+// Submit validates the form and, when every field is valid, passes the
+// values to the submit callback and takes them as the clean state. It
+// returns the values and whether they were valid.
+func (f *Form) Submit() (values Values, ok bool) {
+	if !f.Validate() {
+		return nil, false
+	}
+	values = f.Values()
+	for _, fl := range f.fields {
+		fl.clean = values[fl.name]
+	}
+	if f.onSubmit != nil {
+		f.onSubmit(values)
+	}
+	return values, true
+}
+
+// Reset puts back the values the form was last set to or submitted with,
+// or empties it, and clears the errors shown
+func (f *Form) Reset() {
+	for _, fl := range f.fields {
+		fl.set(fl.clean)
+		fl.err = nil
+		fl.message.set(nil)
+	}
+}
+
+// Dirty reports whether any field differs from the clean state
+func (f *Form) Dirty() bool {
+	return slices.ContainsFunc(f.fields, (*field).dirty)
+}
+
+// IsDirty reports whether the named field differs from the clean state
+func (f *Form) IsDirty(name string) bool {
+	fl := f.field(name)
+	return fl != nil && fl.dirty()
+}
+
+// field returns the named field, nil if there is none
+func (f *Form) field(name string) *field {
+	for _, fl := range f.fields {
+		if fl.name == name {
+			return fl
+		}
+	}
+	return nil
+}
+
+// textInput creates the input of a text, number or date field
+func (f *Form) textInput(placeholder string) *widget.TextInputWidget {
+	return widget.TextInput(f.font).Size(f.size).Placeholder(placeholder).
+		OnSubmit(func(string) { f.Submit() })
+}
+
+// add appends a field edited by the input, validated as it changes, as a
+// row of the grid with its message below it
+func (f *Form) add(name, label string, k kind, input widget.Widget, validators []Validator) *Form {
+	fl := &field{
+		name:       name,
+		kind:       k,
+		validators: validators,
+		label:      widget.Label(f.font, label).Size(f.size),
+		input:      input,
+		message:    &message{font: f.font},
+	}
+	fl.clean, _ = fl.value()
+	changed := func() {
+		fl.validate()
+		if f.onChange != nil {
+			f.onChange(name, f.Values())
+		}
+	}
+	switch in := input.(type) {
+	case *widget.TextInputWidget:
+		in.OnChange(func(string) { changed() })
+	case *widget.CheckboxWidget:
+		in.OnChange(func(bool) { changed() })
+	case *widget.DropdownWidget:
+		in.OnSelect(func(int, string) { changed() })
+	}
+	f.fields = append(f.fields, fl)
+
+	labelBox := widget.ConstrainedBox(fl.label)
+	indent := widget.ConstrainedBox(widget.Spacer())
+	f.labels = append(f.labels, labelBox)
+	f.indents = append(f.indents, indent)
+	row := widget.Row().Rigid(labelBox)
+	if k == checkboxField {
+		row.Rigid(input).Spacer(1)
+	} else {
+		row.Flex(input, 1)
+	}
+	f.Rigid(widget.Padding(
+		widget.Column().
+			Rigid(row).
+			Rigid(widget.Row().Rigid(indent).Flex(fl.message, 1)),
+		widget.Insets{Bottom: rowGap},
+	))
+	f.align()
+	return f
+}
+
+// align sizes the column of labels to the widest of them, so the fields
+// line up beside it
+func (f *Form) align() {
+	var width float32
+	face := f.font.Face(f.size)
+	for _, fl := range f.fields {
+		width = max(width, face.Measure(fl.label.Text()))
+	}
+	width += labelGap
+	for i := range f.labels {
+		f.labels[i].Min(width, -1)
+		f.indents[i].Tight(width, 0)
+	}
+}
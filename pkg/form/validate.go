@@ -0,0 +1,98 @@
+package form
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// Validator checks the value of a field, returning the message shown below
+// it as an error when the value is not acceptable. Validators receive the
+// field's typed value: a string for text and dropdown fields, a float64 for
+// number fields, a bool for checkboxes and a time.Time for dates, or nil
+// for a number or date left empty.
+type Validator func(value any) error
+
+// Required rejects empty fields and unchecked checkboxes. The other
+// validators accept empty fields, so optional fields may be left blank.
+func Required() Validator {
+	return func(value any) error {
+		switch v := value.(type) {
+		case nil:
+			return errors.New("required")
+		case string:
+			if v == "" {
+				return errors.New("required")
+			}
+		case bool:
+			if !v {
+				return errors.New("required")
+			}
+		}
+		return nil
+	}
+}
+
+// MinLength rejects text shorter than n characters
+func MinLength(n int) Validator {
+	return func(value any) error {
+		if s, ok := value.(string); ok && s != "" && utf8.RuneCountInString(s) < n {
+			return fmt.Errorf("must be at least %d characters", n)
+		}
+		return nil
+	}
+}
+
+// MaxLength rejects text longer than n characters
+func MaxLength(n int) Validator {
+	return func(value any) error {
+		if s, ok := value.(string); ok && utf8.RuneCountInString(s) > n {
+			return fmt.Errorf("must be at most %d characters", n)
+		}
+		return nil
+	}
+}
+
+// Range rejects numbers outside lo to hi inclusive
+func Range(lo, hi float64) Validator {
+	return func(value any) error {
+		if v, ok := value.(float64); ok && (v < lo || v > hi) {
+			return fmt.Errorf("must be from %s to %s", formatNumber(lo), formatNumber(hi))
+		}
+		return nil
+	}
+}
+
+// Pattern rejects text the expression does not match with the message given
+func Pattern(expr *regexp.Regexp, message string) Validator {
+	return func(value any) error {
+		if s, ok := value.(string); ok && s != "" && !expr.MatchString(s) {
+			return errors.New(message)
+		}
+		return nil
+	}
+}
+
+// Between rejects dates before from or after to, either of which may be
+// zero to leave that end open
+func Between(from, to time.Time) Validator {
+	return func(value any) error {
+		v, ok := value.(time.Time)
+		switch {
+		case !ok:
+		case !from.IsZero() && v.Before(from):
+			return fmt.Errorf("must not be before %s", from.Format(DateLayout))
+		case !to.IsZero() && v.After(to):
+			return fmt.Errorf("must not be after %s", to.Format(DateLayout))
+		}
+		return nil
+	}
+}
+
+// formatNumber formats a number with as few decimals as needed
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
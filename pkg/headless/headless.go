@@ -0,0 +1,176 @@
+// Package headless draws widget trees into images in memory without opening
+// a window, using the software renderer. Frames are drawn at times chosen by
+// the caller, so animations come out the same on every run, which suits
+// golden image tests in CI and thumbnails of a UI generated on a server.
+package headless
+
+import (
+	"image"
+	"sync"
+	"time"
+
+	"github.com/mleku/goo/pkg/anim"
+	"github.com/mleku/goo/pkg/clipboard"
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/render"
+	"github.com/mleku/goo/pkg/widget"
+	"lol.mleku.dev/chk"
+)
+
+// Screen stands in for a window, drawing a widget tree into an image. Input
+// is delivered with Send and drawn with the next frame, as a window queues it
+// between frames. Like a window it keeps the image between frames, so only
+// the regions that changed are repainted.
+type Screen struct {
+	root          *widget.RootWidget
+	width, height int
+	// scale is the number of pixels per unit of the widgets' coordinates
+	scale     float32
+	canvas    *render.Canvas
+	list      *render.DrawList
+	clock     *anim.Clock
+	clipboard *clipboard.Clipboard
+	// inputMethod keeps the caret the focused text reports
+	inputMethod inputMethod
+	events      []interfaces.Event
+	// queued holds the calls other goroutines made for the next frame
+	mu     sync.Mutex
+	queued []func()
+}
+
+// New creates a new screen showing a widget tree at a size in the widgets'
+// coordinates
+func New(root *widget.RootWidget, width, height int) *Screen {
+	s := &Screen{
+		root:      root,
+		width:     width,
+		height:    height,
+		scale:     1,
+		canvas:    render.NewCanvas(width, height),
+		list:      render.NewDrawList(),
+		clock:     anim.NewClock(),
+		clipboard: clipboard.New(clipboard.Memory()),
+	}
+	s.Send(interfaces.ExposeEvent{})
+	return s
+}
+
+// Scale sets the number of pixels per unit of the widgets' coordinates, as
+// on a high density display, and returns the screen for chaining
+func (s *Screen) Scale(scale float32) *Screen {
+	s.scale = scale
+	s.Resize(s.width, s.height)
+	return s
+}
+
+// Resize changes the size of the screen in the widgets' coordinates. The
+// whole tree is drawn again in the next frame.
+func (s *Screen) Resize(width, height int) {
+	s.width, s.height = width, height
+	s.canvas.Resize(int(float32(width)*s.scale), int(float32(height)*s.scale))
+	s.Send(interfaces.ExposeEvent{})
+}
+
+// Send queues input for the next frame
+func (s *Screen) Send(events ...interfaces.Event) {
+	s.events = append(s.events, events...)
+}
+
+// RunOnUIThread runs a function at the start of the next frame, before the
+// input is delivered, as a window does. It is safe to call from any
+// goroutine.
+func (s *Screen) RunOnUIThread(fn func()) {
+	s.mu.Lock()
+	s.queued = append(s.queued, fn)
+	s.mu.Unlock()
+}
+
+// Clock returns the frame clock, which reports whether the tree asked for
+// another frame while animating
+func (s *Screen) Clock() *anim.Clock {
+	return s.clock
+}
+
+// Clipboard returns the clipboard the widgets use, which is kept in memory
+func (s *Screen) Clipboard() *clipboard.Clipboard {
+	return s.clipboard
+}
+
+// InputCaret returns the caret of the focused text in the widgets'
+// coordinates, where an input method would place its candidate window
+func (s *Screen) InputCaret() interfaces.Rect {
+	return s.inputMethod.caret
+}
+
+// Frame delivers the queued input and draws a frame at a time, returning the
+// screen's image. The image is drawn into by later frames, so copy it to
+// keep a frame.
+func (s *Screen) Frame(now time.Time) (img *image.RGBA, err error) {
+	s.clock.Advance(now)
+	ctx := &interfaces.Context{
+		WindowWidth:    s.width,
+		WindowHeight:   s.height,
+		PaintedRegions: make([]interfaces.Rect, 0),
+		DrawList:       s.list,
+		Clipboard:      s.clipboard,
+		Clock:          s.clock,
+		Scale:          s.scale,
+		InputMethod:    &s.inputMethod,
+	}
+	box := &interfaces.Box{
+		Size: interfaces.Size{Width: float32(s.width), Height: float32(s.height)},
+	}
+	s.mu.Lock()
+	queued := s.queued
+	s.queued = nil
+	s.mu.Unlock()
+	for _, fn := range queued {
+		fn()
+	}
+	events := s.events
+	s.events = nil
+	s.root.Dispatch(ctx, box, events)
+	if _, err = s.root.Render(ctx, box); chk.E(err) {
+		s.list.Reset()
+		return
+	}
+	s.canvas.Flush(s.list, s.width, s.height)
+	return s.canvas.Image(), nil
+}
+
+// Render draws a widget tree once at a size and returns the image
+func Render(root *widget.RootWidget, width, height int) (img *image.RGBA, err error) {
+	return New(root, width, height).Frame(time.Now())
+}
+
+// Diff counts the pixels that differ between two images by more than a
+// tolerance in any channel, for comparing a frame with a golden image.
+// Pixels inside only one of the images all count as different.
+func Diff(a, b *image.RGBA, tolerance uint8) (count int) {
+	ab, bb := a.Bounds(), b.Bounds()
+	both := ab.Intersect(bb)
+	count = ab.Dx()*ab.Dy() + bb.Dx()*bb.Dy() - 2*both.Dx()*both.Dy()
+	for y := both.Min.Y; y < both.Max.Y; y++ {
+		for x := both.Min.X; x < both.Max.X; x++ {
+			p := a.Pix[a.PixOffset(x, y):]
+			q := b.Pix[b.PixOffset(x, y):]
+			for i := range 4 {
+				if max(p[i], q[i])-min(p[i], q[i]) > tolerance {
+					count++
+					break
+				}
+			}
+		}
+	}
+	return
+}
+
+// inputMethod keeps the caret reported for the input method
+type inputMethod struct {
+	caret interfaces.Rect
+}
+
+// SetCaret records the caret
+func (m *inputMethod) SetCaret(caret interfaces.Rect) {
+	m.caret = caret
+}
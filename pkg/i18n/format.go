@@ -0,0 +1,193 @@
+package i18n
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotNumber is returned by ParseNumber for text that is not a number
+var ErrNotNumber = errors.New("i18n: not a number")
+
+// Integer formats a whole number with the locale's thousands separators
+func (l *Locale) Integer(n int) string {
+	s := strconv.Itoa(n)
+	if n < 0 {
+		return "-" + l.group(s[1:])
+	}
+	return l.group(s)
+}
+
+// Number formats a number with a fixed number of decimals, or as few as
+// needed when decimals is negative, with the locale's separators
+func (l *Locale) Number(v float64, decimals int) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	s := strconv.FormatFloat(math.Abs(v), 'f', decimals, 64)
+	whole, fraction, _ := strings.Cut(s, ".")
+	s = l.group(whole)
+	if fraction != "" {
+		s += l.Decimal + fraction
+	}
+	if v < 0 && strings.Trim(s, "0"+l.Decimal+l.Group) != "" {
+		s = "-" + s
+	}
+	return s
+}
+
+// ParseNumber reads a number written with the locale's separators, as
+// Number writes them. Thousands separators are skipped anywhere, and where
+// the locale groups with a space any kind of space is.
+func (l *Locale) ParseNumber(s string) (v float64, err error) {
+	s = strings.TrimSpace(s)
+	if l.Group != "" {
+		s = strings.ReplaceAll(s, l.Group, "")
+		if strings.TrimSpace(l.Group) == "" {
+			s = strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "").Replace(s)
+		}
+	}
+	if l.Decimal != "." {
+		s = strings.Replace(s, l.Decimal, ".", 1)
+	}
+	// The minus sign is written as a hyphen
+	s = strings.Replace(s, "\u2212", "-", 1)
+	if v, err = strconv.ParseFloat(s, 64); err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, ErrNotNumber
+	}
+	return
+}
+
+// Percentage formats a fraction as a percentage, so 0.25 is 25%
+func (l *Locale) Percentage(v float64, decimals int) string {
+	return l.Number(v*100, decimals) + l.Percent
+}
+
+// group inserts the thousands separator into a string of digits
+func (l *Locale) group(digits string) string {
+	if len(digits) <= 3 || l.Group == "" {
+		return digits
+	}
+	var b strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(l.Group)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+// Date formats the date of a time in the locale's usual form
+func (l *Locale) Date(t time.Time) string {
+	return l.Format(t, l.DateFormat)
+}
+
+// Time formats the time of day of a time in the locale's usual form
+func (l *Locale) Time(t time.Time) string {
+	return l.Format(t, l.TimeFormat)
+}
+
+// DateTime formats the date and time of day of a time
+func (l *Locale) DateTime(t time.Time) string {
+	return l.Date(t) + " " + l.Time(t)
+}
+
+// Format formats a time with a pattern in the style of Unicode CLDR date
+// patterns, where runs of these letters are replaced:
+//
+//	yyyy, yy      year in four or two digits
+//	M, MM         month number, padded to two digits for MM
+//	MMM, MMMM     short and full month name
+//	d, dd         day of the month
+//	EEE, EEEE     short and full weekday name
+//	H, HH         hour from 0 to 23
+//	h, hh         hour from 1 to 12
+//	mm, ss        minutes and seconds
+//	a             the locale's AM or PM
+//
+// Other letters are reserved; text in single quotes is copied as it is, and
+// two single quotes write one.
+func (l *Locale) Format(t time.Time, pattern string) string {
+	var b strings.Builder
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		n := 1
+		for i+n < len(runes) && runes[i+n] == c {
+			n++
+		}
+		switch c {
+		case '\'':
+			if n >= 2 {
+				b.WriteString(strings.Repeat("'", n/2))
+				i += n / 2 * 2
+				continue
+			}
+			// Quoted text runs to the next quote
+			end := i + 1
+			for end < len(runes) && runes[end] != '\'' {
+				end++
+			}
+			b.WriteString(string(runes[i+1 : end]))
+			i = end + 1
+			continue
+		case 'y':
+			if n == 2 {
+				b.WriteString(pad(t.Year()%100, 2))
+			} else {
+				b.WriteString(pad(t.Year(), n))
+			}
+		case 'M':
+			switch {
+			case n >= 4:
+				b.WriteString(l.Months[t.Month()-1])
+			case n == 3:
+				b.WriteString(l.ShortMonths[t.Month()-1])
+			default:
+				b.WriteString(pad(int(t.Month()), n))
+			}
+		case 'd':
+			b.WriteString(pad(t.Day(), n))
+		case 'E':
+			if n >= 4 {
+				b.WriteString(l.Days[t.Weekday()])
+			} else {
+				b.WriteString(l.ShortDays[t.Weekday()])
+			}
+		case 'H':
+			b.WriteString(pad(t.Hour(), n))
+		case 'h':
+			b.WriteString(pad((t.Hour()+11)%12+1, n))
+		case 'm':
+			b.WriteString(pad(t.Minute(), n))
+		case 's':
+			b.WriteString(pad(t.Second(), n))
+		case 'a':
+			if t.Hour() < 12 {
+				b.WriteString(l.AM)
+			} else {
+				b.WriteString(l.PM)
+			}
+		default:
+			b.WriteString(string(runes[i : i+n]))
+		}
+		i += n
+	}
+	return b.String()
+}
+
+// pad formats a number with leading zeros to at least width digits
+func pad(n, width int) string {
+	s := strconv.Itoa(n)
+	if len(s) < width {
+		s = strings.Repeat("0", width-len(s)) + s
+	}
+	return s
+}
@@ -0,0 +1,98 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNumber(t *testing.T) {
+	tests := []struct {
+		locale   *Locale
+		v        float64
+		decimals int
+		want     string
+	}{
+		{English(), 1234567.891, 2, "1,234,567.89"},
+		{English(), -1234.5, -1, "-1,234.5"},
+		{English(), 999, 0, "999"},
+		{English(), -0.001, 1, "0.0"},
+		{German(), 1234567.891, 2, "1.234.567,89"},
+		{French(), 1234.5, 1, "1\u202f234,5"},
+		{Russian(), 12345, 0, "12\u00a0345"},
+		{Arabic(), 1234.5, 1, "1,234.5"},
+	}
+	for _, tt := range tests {
+		if got := tt.locale.Number(tt.v, tt.decimals); got != tt.want {
+			t.Errorf("%s Number(%g, %d) = %q, want %q", tt.locale.Tag, tt.v, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestIntegerAndPercentage(t *testing.T) {
+	en, de := English(), German()
+	for _, tt := range []struct{ got, want string }{
+		{en.Integer(0), "0"},
+		{en.Integer(1000), "1,000"},
+		{en.Integer(-1000000), "-1,000,000"},
+		{de.Integer(1000), "1.000"},
+		{en.Percentage(0.25, 0), "25%"},
+		{de.Percentage(0.125, 1), "12,5\u00a0%"},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		locale *Locale
+		s      string
+		want   float64
+		err    error
+	}{
+		{English(), "1,234.5", 1234.5, nil},
+		{English(), " -12 ", -12, nil},
+		{German(), "1.234,5", 1234.5, nil},
+		{French(), "1\u202f234,5", 1234.5, nil},
+		{French(), "1 234,5", 1234.5, nil},
+		{French(), "1\u00a0234,5", 1234.5, nil},
+		{Russian(), "\u22127", -7, nil},
+		{English(), "twelve", 0, ErrNotNumber},
+		{English(), "NaN", 0, ErrNotNumber},
+	}
+	for _, tt := range tests {
+		got, err := tt.locale.ParseNumber(tt.s)
+		if err != tt.err || got != tt.want {
+			t.Errorf("%s ParseNumber(%q) = %g, %v, want %g, %v", tt.locale.Tag, tt.s, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	at := time.Date(2024, time.March, 5, 14, 7, 9, 0, time.UTC)
+	tests := []struct {
+		locale  *Locale
+		pattern string
+		want    string
+	}{
+		{English(), "yyyy-MM-dd HH:mm:ss", "2024-03-05 14:07:09"},
+		{English(), "yy M d", "24 3 5"},
+		{English(), "EEEE, MMMM d", "Tuesday, March 5"},
+		{English(), "EEE MMM", "Tue Mar"},
+		{English(), "h:mm a", "2:07 PM"},
+		{English(), "'at' HH'h' ''", "at 14h '"},
+		{German(), "EEEE, d. MMMM", "Dienstag, 5. März"},
+	}
+	for _, tt := range tests {
+		if got := tt.locale.Format(at, tt.pattern); got != tt.want {
+			t.Errorf("%s Format(%q) = %q, want %q", tt.locale.Tag, tt.pattern, got, tt.want)
+		}
+	}
+	if got := English().Date(at); got != "Mar 5, 2024" {
+		t.Errorf("Date = %q", got)
+	}
+	if got := Japanese().Date(at); got != "2024/03/05" {
+		t.Errorf("Japanese Date = %q", got)
+	}
+}
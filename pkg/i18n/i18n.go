@@ -0,0 +1,167 @@
+// Package i18n translates and formats the text a program shows. A Catalog
+// holds the messages of each language and the current Locale, which can be
+// switched while the program runs: the catalog is observable, so text bound
+// to its messages and roots following it update, and right to left locales
+// mirror the layout. Locales also format numbers and dates and choose the
+// plural form of messages that count something.
+//
+// Catalogs are not safe for concurrent use; switch the locale from the
+// goroutine that runs the window.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mleku/goo/pkg/state"
+)
+
+// Forms are the forms of a message by plural category. A message with no
+// form for a category uses its Other form.
+type Forms map[Plural]string
+
+// Catalog holds messages in several languages and the locale they are
+// looked up in. Messages missing from the current locale's language fall
+// back to the language the catalog was created with, then to the key.
+//
+// Messages may hold placeholders in braces, such as "Hello, {name}", filled
+// from the arguments of T and N, which are given as name and value pairs.
+// Numbers and times are formatted for the locale.
+type Catalog struct {
+	locale   *state.State[*Locale]
+	fallback string
+	// messages holds the forms of each message by language tag and key
+	messages map[string]map[string]Forms
+}
+
+// NewCatalog creates an empty catalog set to a locale, whose language is
+// also the fallback for messages other languages lack
+func NewCatalog(locale *Locale) *Catalog {
+	return &Catalog{
+		locale: state.NewFunc(locale, func(a, b *Locale) bool {
+			return a == b
+		}),
+		fallback: locale.Tag,
+		messages: make(map[string]map[string]Forms),
+	}
+}
+
+// Add adds messages in a language by key and returns the catalog for
+// chaining. Messages are stored by the tag as given, so "de" serves every
+// German locale while "de-AT" serves only Austrian German.
+func (c *Catalog) Add(tag string, messages map[string]string) *Catalog {
+	for key, message := range messages {
+		c.AddPlural(tag, key, Forms{Other: message})
+	}
+	return c
+}
+
+// AddPlural adds a message in a language with a form for each plural
+// category the language has, and returns the catalog for chaining
+func (c *Catalog) AddPlural(tag, key string, forms Forms) *Catalog {
+	m := c.messages[tag]
+	if m == nil {
+		m = make(map[string]Forms)
+		c.messages[tag] = m
+	}
+	m[key] = forms
+	return c
+}
+
+// Locale returns the current locale
+func (c *Catalog) Locale() *Locale {
+	return c.locale.Get()
+}
+
+// SetLocale switches the locale, notifying the subscribers
+func (c *Catalog) SetLocale(l *Locale) {
+	c.locale.Set(l)
+}
+
+// Subscribe implements state.Observable, calling fn after each switch of
+// locale
+func (c *Catalog) Subscribe(fn func()) (cancel func()) {
+	return c.locale.Subscribe(fn)
+}
+
+// T returns the message with a key in the current locale, with placeholders
+// filled from name and value pairs
+func (c *Catalog) T(key string, args ...any) string {
+	forms := c.lookup(key)
+	if forms == nil {
+		return c.fill(key, args)
+	}
+	return c.fill(forms[Other], args)
+}
+
+// N returns the form of the message with a key that suits a count, with
+// the count filling the placeholder {n} and the other placeholders filled
+// from name and value pairs
+func (c *Catalog) N(key string, n int, args ...any) string {
+	args = append([]any{"n", n}, args...)
+	forms := c.lookup(key)
+	if forms == nil {
+		return c.fill(key, args)
+	}
+	message, ok := forms[c.Locale().Plural(n)]
+	if !ok {
+		message = forms[Other]
+	}
+	return c.fill(message, args)
+}
+
+// Text returns the message with a key as a value that follows the locale,
+// for binding to the text of a widget
+func (c *Catalog) Text(key string, args ...any) *state.Computed[string] {
+	return state.Derive(func() string { return c.T(key, args...) }, c)
+}
+
+// Count returns the form of the message with a key that suits a count as a
+// value that follows both the locale and the count
+func (c *Catalog) Count(key string, n state.Value[int], args ...any) *state.Computed[string] {
+	return state.Derive(func() string { return c.N(key, n.Get(), args...) }, c, n)
+}
+
+// lookup returns the forms of a message in the current locale's language,
+// or else in the fallback language, nil when neither has it
+func (c *Catalog) lookup(key string) Forms {
+	for _, tag := range []string{c.Locale().Tag, language(c.Locale().Tag), c.fallback, language(c.fallback)} {
+		if forms, ok := c.messages[tag][key]; ok {
+			return forms
+		}
+	}
+	return nil
+}
+
+// fill replaces the placeholders of a message with the values of name and
+// value pairs, formatted for the locale. Placeholders without a value are
+// left as they are.
+func (c *Catalog) fill(message string, args []any) string {
+	if len(args) < 2 || !strings.Contains(message, "{") {
+		return message
+	}
+	pairs := make([]string, 0, len(args))
+	for i := 0; i+1 < len(args); i += 2 {
+		pairs = append(pairs, "{"+fmt.Sprint(args[i])+"}", c.format(args[i+1]))
+	}
+	return strings.NewReplacer(pairs...).Replace(message)
+}
+
+// format writes a placeholder value as the locale writes it
+func (c *Catalog) format(v any) string {
+	l := c.Locale()
+	switch v := v.(type) {
+	case int:
+		return l.Integer(v)
+	case int64:
+		return l.Integer(int(v))
+	case float32:
+		return l.Number(float64(v), -1)
+	case float64:
+		return l.Number(v, -1)
+	case time.Time:
+		return l.Date(v)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,243 @@
+package i18n
+
+import (
+	"strings"
+)
+
+// Locale describes the conventions of a language and region: which way its
+// text runs, how it writes numbers and dates and how it chooses plural forms
+type Locale struct {
+	// Tag is the BCP 47 language tag, such as "en-US" or "ar"
+	Tag string
+	// Name is the name of the language written in it, for locale pickers
+	Name string
+	// RTL is set for languages written from right to left, whose layouts are
+	// mirrored
+	RTL bool
+	// Decimal separates the fraction of a number and Group its thousands
+	Decimal, Group string
+	// Percent is written after a percentage, with any space before it
+	Percent string
+	// Plural returns the category a count selects a message form by
+	Plural func(n int) Plural
+	// Months and Days are the names of the months from January and the days
+	// of the week from Sunday, and ShortMonths and ShortDays their
+	// abbreviations
+	Months, ShortMonths [12]string
+	Days, ShortDays     [7]string
+	// AM and PM mark the halves of the day on a 12 hour clock
+	AM, PM string
+	// DateFormat and TimeFormat are the patterns of Date and Time, in the
+	// form Format takes
+	DateFormat, TimeFormat string
+}
+
+// language returns the language subtag of a tag, such as "en" for "en-US"
+func language(tag string) string {
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		return tag[:i]
+	}
+	return tag
+}
+
+// Lookup returns the built-in locale for a language tag, matching on the
+// language alone when the region is not known, or nil when there is none
+func Lookup(tag string) *Locale {
+	want := strings.ToLower(strings.ReplaceAll(tag, "_", "-"))
+	for _, l := range Locales() {
+		if strings.ToLower(l.Tag) == want {
+			return l
+		}
+	}
+	for _, l := range Locales() {
+		if language(l.Tag) == language(want) {
+			return l
+		}
+	}
+	return nil
+}
+
+// Locales returns the built-in locales
+func Locales() []*Locale {
+	return []*Locale{English(), German(), French(), Spanish(), Russian(), Arabic(), Hebrew(), Japanese()}
+}
+
+// English returns the conventions of United States English
+func English() *Locale {
+	return &Locale{
+		Tag:     "en-US",
+		Name:    "English",
+		Decimal: ".",
+		Group:   ",",
+		Percent: "%",
+		Plural:  pluralOne,
+		Months: [12]string{"January", "February", "March", "April", "May", "June",
+			"July", "August", "September", "October", "November", "December"},
+		ShortMonths: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun",
+			"Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		Days:       [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		ShortDays:  [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+		AM:         "AM",
+		PM:         "PM",
+		DateFormat: "MMM d, yyyy",
+		TimeFormat: "h:mm a",
+	}
+}
+
+// German returns the conventions of German
+func German() *Locale {
+	return &Locale{
+		Tag:     "de-DE",
+		Name:    "Deutsch",
+		Decimal: ",",
+		Group:   ".",
+		Percent: "\u00a0%",
+		Plural:  pluralOne,
+		Months: [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni",
+			"Juli", "August", "September", "Oktober", "November", "Dezember"},
+		ShortMonths: [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni",
+			"Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		Days:       [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		ShortDays:  [7]string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+		AM:         "AM",
+		PM:         "PM",
+		DateFormat: "dd.MM.yyyy",
+		TimeFormat: "HH:mm",
+	}
+}
+
+// French returns the conventions of French
+func French() *Locale {
+	return &Locale{
+		Tag:     "fr-FR",
+		Name:    "Français",
+		Decimal: ",",
+		Group:   "\u202f",
+		Percent: "\u202f%",
+		Plural:  pluralFrench,
+		Months: [12]string{"janvier", "février", "mars", "avril", "mai", "juin",
+			"juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		ShortMonths: [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin",
+			"juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		Days:       [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		ShortDays:  [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+		AM:         "AM",
+		PM:         "PM",
+		DateFormat: "d MMM yyyy",
+		TimeFormat: "HH:mm",
+	}
+}
+
+// Spanish returns the conventions of Spanish
+func Spanish() *Locale {
+	return &Locale{
+		Tag:     "es-ES",
+		Name:    "Español",
+		Decimal: ",",
+		Group:   ".",
+		Percent: "\u00a0%",
+		Plural:  pluralOne,
+		Months: [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio",
+			"julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		ShortMonths: [12]string{"ene", "feb", "mar", "abr", "may", "jun",
+			"jul", "ago", "sept", "oct", "nov", "dic"},
+		Days:       [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		ShortDays:  [7]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+		AM:         "a.\u00a0m.",
+		PM:         "p.\u00a0m.",
+		DateFormat: "d MMM yyyy",
+		TimeFormat: "H:mm",
+	}
+}
+
+// Russian returns the conventions of Russian. Month names are in the
+// genitive, as they are written in dates.
+func Russian() *Locale {
+	return &Locale{
+		Tag:     "ru-RU",
+		Name:    "Русский",
+		Decimal: ",",
+		Group:   "\u00a0",
+		Percent: "\u00a0%",
+		Plural:  pluralSlavic,
+		Months: [12]string{"января", "февраля", "марта", "апреля", "мая", "июня",
+			"июля", "августа", "сентября", "октября", "ноября", "декабря"},
+		ShortMonths: [12]string{"янв.", "февр.", "мар.", "апр.", "мая", "июн.",
+			"июл.", "авг.", "сент.", "окт.", "нояб.", "дек."},
+		Days:       [7]string{"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"},
+		ShortDays:  [7]string{"вс", "пн", "вт", "ср", "чт", "пт", "сб"},
+		AM:         "AM",
+		PM:         "PM",
+		DateFormat: "dd.MM.yyyy",
+		TimeFormat: "HH:mm",
+	}
+}
+
+// Arabic returns the conventions of Arabic, written from right to left with
+// Western digits and separators as in North Africa and on most software
+func Arabic() *Locale {
+	return &Locale{
+		Tag:     "ar",
+		Name:    "العربية",
+		RTL:     true,
+		Decimal: ".",
+		Group:   ",",
+		Percent: "%",
+		Plural:  pluralArabic,
+		Months: [12]string{"يناير", "فبراير", "مارس", "أبريل", "مايو", "يونيو",
+			"يوليو", "أغسطس", "سبتمبر", "أكتوبر", "نوفمبر", "ديسمبر"},
+		ShortMonths: [12]string{"يناير", "فبراير", "مارس", "أبريل", "مايو", "يونيو",
+			"يوليو", "أغسطس", "سبتمبر", "أكتوبر", "نوفمبر", "ديسمبر"},
+		Days:       [7]string{"الأحد", "الاثنين", "الثلاثاء", "الأربعاء", "الخميس", "الجمعة", "السبت"},
+		ShortDays:  [7]string{"الأحد", "الاثنين", "الثلاثاء", "الأربعاء", "الخميس", "الجمعة", "السبت"},
+		AM:         "ص",
+		PM:         "م",
+		DateFormat: "d MMMM yyyy",
+		TimeFormat: "h:mm a",
+	}
+}
+
+// Hebrew returns the conventions of Hebrew, written from right to left
+func Hebrew() *Locale {
+	return &Locale{
+		Tag:     "he-IL",
+		Name:    "עברית",
+		RTL:     true,
+		Decimal: ".",
+		Group:   ",",
+		Percent: "%",
+		Plural:  pluralHebrew,
+		Months: [12]string{"ינואר", "פברואר", "מרץ", "אפריל", "מאי", "יוני",
+			"יולי", "אוגוסט", "ספטמבר", "אוקטובר", "נובמבר", "דצמבר"},
+		ShortMonths: [12]string{"ינו׳", "פבר׳", "מרץ", "אפר׳", "מאי", "יוני",
+			"יולי", "אוג׳", "ספט׳", "אוק׳", "נוב׳", "דצמ׳"},
+		Days:       [7]string{"יום ראשון", "יום שני", "יום שלישי", "יום רביעי", "יום חמישי", "יום שישי", "יום שבת"},
+		ShortDays:  [7]string{"יום א׳", "יום ב׳", "יום ג׳", "יום ד׳", "יום ה׳", "יום ו׳", "שבת"},
+		AM:         "לפנה״צ",
+		PM:         "אחה״צ",
+		DateFormat: "d.M.yyyy",
+		TimeFormat: "H:mm",
+	}
+}
+
+// Japanese returns the conventions of Japanese
+func Japanese() *Locale {
+	return &Locale{
+		Tag:     "ja-JP",
+		Name:    "日本語",
+		Decimal: ".",
+		Group:   ",",
+		Percent: "%",
+		Plural:  pluralNone,
+		Months: [12]string{"1月", "2月", "3月", "4月", "5月", "6月",
+			"7月", "8月", "9月", "10月", "11月", "12月"},
+		ShortMonths: [12]string{"1月", "2月", "3月", "4月", "5月", "6月",
+			"7月", "8月", "9月", "10月", "11月", "12月"},
+		Days:       [7]string{"日曜日", "月曜日", "火曜日", "水曜日", "木曜日", "金曜日", "土曜日"},
+		ShortDays:  [7]string{"日", "月", "火", "水", "木", "金", "土"},
+		AM:         "午前",
+		PM:         "午後",
+		DateFormat: "yyyy/MM/dd",
+		TimeFormat: "H:mm",
+	}
+}
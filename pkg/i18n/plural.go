@@ -0,0 +1,108 @@
+package i18n
+
+// Plural is the grammatical category a count selects a message form by, as
+// defined by the Unicode CLDR plural rules. Languages use different subsets:
+// English only has One and Other, Arabic all six.
+type Plural int
+
+const (
+	Other Plural = iota
+	Zero
+	One
+	Two
+	Few
+	Many
+)
+
+// String returns the CLDR name of the category
+func (p Plural) String() string {
+	switch p {
+	case Zero:
+		return "zero"
+	case One:
+		return "one"
+	case Two:
+		return "two"
+	case Few:
+		return "few"
+	case Many:
+		return "many"
+	}
+	return "other"
+}
+
+// pluralOne is the rule of English, German, Spanish and most other European
+// languages: one for 1 and other for the rest
+func pluralOne(n int) Plural {
+	if n == 1 {
+		return One
+	}
+	return Other
+}
+
+// pluralFrench treats 0 and 1 alike, and millions as many
+func pluralFrench(n int) Plural {
+	switch {
+	case n == 0 || n == 1:
+		return One
+	case n != 0 && n%1000000 == 0:
+		return Many
+	}
+	return Other
+}
+
+// pluralSlavic is the rule of Russian and Ukrainian, which choose by the
+// last digits
+func pluralSlavic(n int) Plural {
+	n = abs(n)
+	switch d, dd := n%10, n%100; {
+	case d == 1 && dd != 11:
+		return One
+	case d >= 2 && d <= 4 && (dd < 12 || dd > 14):
+		return Few
+	}
+	return Many
+}
+
+// pluralArabic has a form for zero, one, two, small and large counts
+func pluralArabic(n int) Plural {
+	n = abs(n)
+	switch dd := n % 100; {
+	case n == 0:
+		return Zero
+	case n == 1:
+		return One
+	case n == 2:
+		return Two
+	case dd >= 3 && dd <= 10:
+		return Few
+	case dd >= 11 && dd <= 99:
+		return Many
+	}
+	return Other
+}
+
+// pluralHebrew has a form for one and for two
+func pluralHebrew(n int) Plural {
+	switch n {
+	case 1:
+		return One
+	case 2:
+		return Two
+	}
+	return Other
+}
+
+// pluralNone is the rule of languages that do not inflect for number, such
+// as Japanese and Chinese
+func pluralNone(int) Plural {
+	return Other
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,64 @@
+package i18n
+
+import (
+	"testing"
+)
+
+func TestPluralRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule func(n int) Plural
+		want map[int]Plural
+	}{
+		{"one", pluralOne, map[int]Plural{0: Other, 1: One, 2: Other, 11: Other, 21: Other}},
+		{"french", pluralFrench, map[int]Plural{
+			0: One, 1: One, 2: Other, 999999: Other, 1000000: Many, 3000000: Many,
+		}},
+		{"slavic", pluralSlavic, map[int]Plural{
+			1: One, 21: One, 101: One, 11: Many, 2: Few, 4: Few, 22: Few,
+			12: Many, 14: Many, 5: Many, 0: Many, 111: Many, -1: One, -3: Few,
+		}},
+		{"arabic", pluralArabic, map[int]Plural{
+			0: Zero, 1: One, 2: Two, 3: Few, 10: Few, 103: Few,
+			11: Many, 99: Many, 111: Many, 100: Other, 102: Other,
+		}},
+		{"hebrew", pluralHebrew, map[int]Plural{0: Other, 1: One, 2: Two, 3: Other, 20: Other}},
+		{"none", pluralNone, map[int]Plural{0: Other, 1: Other, 2: Other}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for n, want := range tt.want {
+				if got := tt.rule(n); got != want {
+					t.Errorf("%d: got %s, want %s", n, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCatalogN(t *testing.T) {
+	c := NewCatalog(English()).
+		AddPlural("en-US", "files", Forms{One: "{n} file", Other: "{n} files"}).
+		AddPlural("ru", "files", Forms{One: "{n} файл", Few: "{n} файла", Many: "{n} файлов"})
+	tests := []struct {
+		locale *Locale
+		n      int
+		want   string
+	}{
+		{English(), 1, "1 file"},
+		{English(), 2, "2 files"},
+		{English(), 1200, "1,200 files"},
+		{Russian(), 1, "1 файл"},
+		{Russian(), 3, "3 файла"},
+		{Russian(), 11, "11 файлов"},
+		// Japanese has no message, so the fallback language's is used, in the
+		// form Japanese selects, which has no singular
+		{Japanese(), 1, "1 files"},
+	}
+	for _, tt := range tests {
+		c.SetLocale(tt.locale)
+		if got := c.N("files", tt.n); got != tt.want {
+			t.Errorf("%s %d: got %q, want %q", tt.locale.Tag, tt.n, got, tt.want)
+		}
+	}
+}
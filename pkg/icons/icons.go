@@ -0,0 +1,131 @@
+// Package icons is a built-in set of line icons addressed by name, such as
+// "close", "search" or "chevron-down", so buttons, menus and toolbars can
+// show standard icons without shipping image files. Like the glyphs of text,
+// icons are rasterized once for each pixel size into a shared coverage atlas
+// and drawn tinted in any color.
+package icons
+
+import (
+	"math"
+	"slices"
+
+	"github.com/mleku/goo/pkg/render"
+	"github.com/mleku/goo/pkg/svg"
+	"github.com/mleku/goo/pkg/text"
+	"lol.mleku.dev/chk"
+)
+
+// header opens the document every icon's shapes are placed in
+const header = `<svg xmlns="http://www.w3.org/2000/svg" width="24" height="24" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round" stroke-linejoin="round">`
+
+// glyph is the atlas region holding an icon rasterized at one pixel size
+type glyph struct {
+	x, y, size int
+}
+
+// glyphKey identifies an icon rasterized at a pixel size
+type glyphKey struct {
+	name   string
+	pixels int
+}
+
+var (
+	// images caches the parsed documents of the icons by name
+	images = make(map[string]*svg.Image)
+	// atlas holds the rasterized icons, created when the first is drawn
+	atlas  *text.Atlas
+	glyphs = make(map[glyphKey]glyph)
+)
+
+// Names returns the names of every icon in the set, sorted
+func Names() (names []string) {
+	for name := range sources {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return
+}
+
+// Has reports whether the set has an icon of a name
+func Has(name string) bool {
+	_, ok := sources[name]
+	return ok
+}
+
+// Image returns the vector image of an icon, nil if the set has no icon of
+// the name. Its shapes are painted in the current color.
+func Image(name string) *svg.Image {
+	if img, ok := images[name]; ok {
+		return img
+	}
+	source, ok := sources[name]
+	if !ok {
+		return nil
+	}
+	img, err := svg.Parse([]byte(header + source + "</svg>"))
+	if chk.E(err) {
+		return nil
+	}
+	images[name] = img
+	return img
+}
+
+// Draw adds an icon size units square with its top left corner at (x, y) to
+// the draw list, in a color. Scale is the number of pixels per unit, which
+// the icon is rasterized for so it stays crisp on high density displays.
+// Unknown names draw nothing.
+func Draw(list *render.DrawList, name string, x, y, size, scale float32, color [4]float32) {
+	if scale <= 0 {
+		scale = 1
+	}
+	pixels := int(math.Round(float64(size * scale)))
+	if pixels <= 0 {
+		return
+	}
+	g, ok := lookup(name, pixels)
+	if !ok {
+		return
+	}
+	t := atlas.Texture()
+	aw, ah := float32(t.Width), float32(t.Height)
+	// Snap to whole pixels so the icon is not resampled
+	x = float32(math.Round(float64(x*scale))) / scale
+	y = float32(math.Round(float64(y*scale))) / scale
+	s := float32(g.size) / scale
+	list.Image(t, x, y, s, s,
+		float32(g.x)/aw, float32(g.y)/ah,
+		float32(g.x+g.size)/aw, float32(g.y+g.size)/ah,
+		color,
+	)
+}
+
+// lookup returns the atlas region of an icon at a pixel size, rasterizing
+// it into the atlas the first time it is drawn at that size
+func lookup(name string, pixels int) (g glyph, ok bool) {
+	key := glyphKey{name: name, pixels: pixels}
+	if g, ok = glyphs[key]; ok {
+		return
+	}
+	img := Image(name)
+	if img == nil {
+		return
+	}
+	if atlas == nil {
+		// Room for a few hundred icons, as growing the atlas moves the
+		// icons already drawn in the frame
+		atlas = text.NewAtlas(1024, 1024)
+	}
+	rgba := img.RasterizeMono(pixels, pixels, [4]float32{1, 1, 1, 1})
+	mask := make([]byte, pixels*pixels)
+	for i := range mask {
+		mask[i] = rgba.Pix[4*i+3]
+	}
+	var reset bool
+	if g.x, g.y, reset = atlas.Add(pixels, pixels, mask); reset {
+		// The atlas was recycled so every cached icon is stale
+		clear(glyphs)
+	}
+	g.size = pixels
+	glyphs[key] = g
+	return g, true
+}
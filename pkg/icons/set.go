@@ -0,0 +1,60 @@
+package icons
+
+// sources holds the shapes of the icons on a 24 unit grid, stroked 2 units
+// wide with round ends and joins in the current color
+var sources = map[string]string{
+	"add":           `<path d="M12 5v14M5 12h14"/>`,
+	"remove":        `<path d="M5 12h14"/>`,
+	"close":         `<path d="M6 6l12 12M18 6L6 18"/>`,
+	"check":         `<path d="M5 12.5l4.5 4.5L19 7"/>`,
+	"menu":          `<path d="M4 6h16M4 12h16M4 18h16"/>`,
+	"more-horiz":    `<path d="M5 12h.01M12 12h.01M19 12h.01"/>`,
+	"more-vert":     `<path d="M12 5v.01M12 12v.01M12 19v.01"/>`,
+	"chevron-up":    `<path d="M6 15l6-6 6 6"/>`,
+	"chevron-down":  `<path d="M6 9l6 6 6-6"/>`,
+	"chevron-left":  `<path d="M15 6l-6 6 6 6"/>`,
+	"chevron-right": `<path d="M9 6l6 6-6 6"/>`,
+	"arrow-up":      `<path d="M12 19V5M5 12l7-7 7 7"/>`,
+	"arrow-down":    `<path d="M12 5v14M19 12l-7 7-7-7"/>`,
+	"arrow-left":    `<path d="M19 12H5M12 19l-7-7 7-7"/>`,
+	"arrow-right":   `<path d="M5 12h14M12 5l7 7-7 7"/>`,
+	"search":        `<circle cx="11" cy="11" r="7"/><path d="M20 20l-4-4"/>`,
+	"zoom-in":       `<circle cx="11" cy="11" r="7"/><path d="M20 20l-4-4M11 8v6M8 11h6"/>`,
+	"zoom-out":      `<circle cx="11" cy="11" r="7"/><path d="M20 20l-4-4M8 11h6"/>`,
+	"home":          `<path d="M3 11l9-8 9 8M5 9.5V21h5v-6h4v6h5V9.5"/>`,
+	"folder":        `<path d="M3 6a1 1 0 0 1 1-1h5l2 2h9a1 1 0 0 1 1 1v10a1 1 0 0 1-1 1H4a1 1 0 0 1-1-1z"/>`,
+	"file":          `<path d="M14 3H6a1 1 0 0 0-1 1v16a1 1 0 0 0 1 1h12a1 1 0 0 0 1-1V8zM14 3v5h5"/>`,
+	"edit":          `<path d="M4 20h4L19 9l-4-4L4 16zM13 7l4 4"/>`,
+	"delete":        `<path d="M4 7h16M10 11v6M14 11v6M6 7l1 13a1 1 0 0 0 1 1h8a1 1 0 0 0 1-1l1-13M9 7V4h6v3"/>`,
+	"save":          `<path d="M5 3h11l5 5v11a2 2 0 0 1-2 2H5a2 2 0 0 1-2-2V5a2 2 0 0 1 2-2zM7 3v5h8V3M7 21v-7h10v7"/>`,
+	"copy":          `<rect x="9" y="9" width="12" height="12" rx="2"/><path d="M5 15H4a1 1 0 0 1-1-1V4a1 1 0 0 1 1-1h10a1 1 0 0 1 1 1v1"/>`,
+	"cut":           `<circle cx="6" cy="6" r="3"/><circle cx="6" cy="18" r="3"/><path d="M8.5 8.5L20 20M8.5 15.5L20 4"/>`,
+	"paste":         `<rect x="8" y="2" width="8" height="4" rx="1"/><path d="M16 4h2a2 2 0 0 1 2 2v14a2 2 0 0 1-2 2H6a2 2 0 0 1-2-2V6a2 2 0 0 1 2-2h2"/>`,
+	"undo":          `<path d="M9 14L4 9l5-5M4 9h11a5 5 0 0 1 0 10h-3"/>`,
+	"redo":          `<path d="M15 14l5-5-5-5M20 9H9a5 5 0 0 0 0 10h3"/>`,
+	"refresh":       `<path d="M20 12a8 8 0 1 1-2.34-5.66M20 4v5h-5"/>`,
+	"download":      `<path d="M12 3v12M7 10l5 5 5-5M4 21h16"/>`,
+	"upload":        `<path d="M12 15V3M7 8l5-5 5 5M4 21h16"/>`,
+	"info":          `<circle cx="12" cy="12" r="9"/><path d="M12 11v6M12 7.5v.01"/>`,
+	"help":          `<circle cx="12" cy="12" r="9"/><path d="M9.5 9.5a2.5 2.5 0 1 1 3.5 2.3c-.6.3-1 .9-1 1.5v.7M12 17v.01"/>`,
+	"warning":       `<path d="M12 3L2 20h20zM12 9v5M12 17v.01"/>`,
+	"error":         `<circle cx="12" cy="12" r="9"/><path d="M12 7v6M12 16.5v.01"/>`,
+	"play":          `<path d="M7 4v16l13-8z"/>`,
+	"pause":         `<path d="M8 5v14M16 5v14"/>`,
+	"stop":          `<rect x="5" y="5" width="14" height="14" rx="1"/>`,
+	"star":          `<path d="M12 3l2.7 5.6 6.1.9-4.4 4.3 1 6.1L12 17l-5.4 2.9 1-6.1-4.4-4.3 6.1-.9z"/>`,
+	"heart":         `<path d="M12 20s-8-4.8-8-10.5A4.5 4.5 0 0 1 12 7a4.5 4.5 0 0 1 8 2.5C20 15.2 12 20 12 20z"/>`,
+	"settings":      `<path d="M4 6h10M18 6h2M4 12h4M12 12h8M4 18h12"/><circle cx="16" cy="6" r="2"/><circle cx="10" cy="12" r="2"/><circle cx="18" cy="18" r="2"/>`,
+	"user":          `<circle cx="12" cy="8" r="4"/><path d="M4 21a8 8 0 0 1 16 0"/>`,
+	"lock":          `<rect x="5" y="11" width="14" height="10" rx="2"/><path d="M8 11V7a4 4 0 0 1 8 0v4"/>`,
+	"eye":           `<path d="M2 12s3.6-7 10-7 10 7 10 7-3.6 7-10 7S2 12 2 12z"/><circle cx="12" cy="12" r="3"/>`,
+	"calendar":      `<rect x="3" y="5" width="18" height="16" rx="2"/><path d="M3 10h18M8 3v4M16 3v4"/>`,
+	"clock":         `<circle cx="12" cy="12" r="9"/><path d="M12 7v5l3 2"/>`,
+	"mail":          `<rect x="3" y="5" width="18" height="14" rx="2"/><path d="M3 7l9 6 9-6"/>`,
+	"bell":          `<path d="M6 16v-5a6 6 0 0 1 12 0v5l2 2H4zM10 21h4"/>`,
+	"link":          `<path d="M10 14a4 4 0 0 0 5.7 0l3-3a4 4 0 0 0-5.7-5.7l-1 1M14 10a4 4 0 0 0-5.7 0l-3 3a4 4 0 0 0 5.7 5.7l1-1"/>`,
+	"external":      `<path d="M14 4h6v6M20 4l-9 9M18 14v5a1 1 0 0 1-1 1H5a1 1 0 0 1-1-1V7a1 1 0 0 1 1-1h5"/>`,
+	"filter":        `<path d="M3 5h18l-7 8v6l-4 2v-8z"/>`,
+	"list":          `<path d="M9 6h11M9 12h11M9 18h11M4 6h.01M4 12h.01M4 18h.01"/>`,
+	"grid":          `<rect x="4" y="4" width="6" height="6" rx="1"/><rect x="14" y="4" width="6" height="6" rx="1"/><rect x="4" y="14" width="6" height="6" rx="1"/><rect x="14" y="14" width="6" height="6" rx="1"/>`,
+}
@@ -0,0 +1,191 @@
+package interfaces
+
+// Role is the kind of control an accessibility node is, which screen
+// readers announce along with its name
+type Role int
+
+const (
+	// RoleGeneric is a node with no particular role, such as a custom widget
+	// that only has a name
+	RoleGeneric Role = iota
+	RoleWindow
+	RoleLabel
+	RoleButton
+	RoleCheckBox
+	RoleRadioButton
+	RoleSwitch
+	RoleTextInput
+	RoleTextArea
+	RoleSlider
+	RoleProgressBar
+	RoleComboBox
+	RoleTabList
+	RoleTree
+	RoleList
+	RoleMenu
+	RoleMenuItem
+	RoleDialog
+	RoleImage
+	RoleLink
+	RoleGroup
+)
+
+// String returns the name of the role
+func (r Role) String() string {
+	switch r {
+	case RoleWindow:
+		return "window"
+	case RoleLabel:
+		return "label"
+	case RoleButton:
+		return "button"
+	case RoleCheckBox:
+		return "checkbox"
+	case RoleRadioButton:
+		return "radio button"
+	case RoleSwitch:
+		return "switch"
+	case RoleTextInput:
+		return "text input"
+	case RoleTextArea:
+		return "text area"
+	case RoleSlider:
+		return "slider"
+	case RoleProgressBar:
+		return "progress bar"
+	case RoleComboBox:
+		return "combo box"
+	case RoleTabList:
+		return "tab list"
+	case RoleTree:
+		return "tree"
+	case RoleList:
+		return "list"
+	case RoleMenu:
+		return "menu"
+	case RoleMenuItem:
+		return "menu item"
+	case RoleDialog:
+		return "dialog"
+	case RoleImage:
+		return "image"
+	case RoleLink:
+		return "link"
+	case RoleGroup:
+		return "group"
+	}
+	return "generic"
+}
+
+// AccessState holds flags describing the state of an accessibility node
+type AccessState uint32
+
+const (
+	StateFocusable AccessState = 1 << iota
+	StateFocused
+	StateDisabled
+	// StateCheckable is set on nodes that can be checked, with StateChecked
+	// set while they are
+	StateCheckable
+	StateChecked
+	StateSelected
+	// StateExpandable is set on nodes that open and close, with
+	// StateExpanded set while they are open
+	StateExpandable
+	StateExpanded
+	StateReadOnly
+	StateMultiline
+)
+
+// Has reports whether all the flags of other are set
+func (s AccessState) Has(other AccessState) bool {
+	return s&other == other
+}
+
+// AccessAction is an action assistive technology can ask a node to perform
+type AccessAction int
+
+const (
+	// AccessFocus gives the node keyboard focus
+	AccessFocus AccessAction = iota
+	// AccessClick performs the node's default action, as a click would
+	AccessClick
+	// AccessIncrement and AccessDecrement step a value up or down
+	AccessIncrement
+	AccessDecrement
+	// AccessSetValue replaces the value with the text of the request
+	AccessSetValue
+	// AccessExpand and AccessCollapse open and close the node
+	AccessExpand
+	AccessCollapse
+)
+
+// String returns the name of the action
+func (a AccessAction) String() string {
+	switch a {
+	case AccessFocus:
+		return "focus"
+	case AccessClick:
+		return "click"
+	case AccessIncrement:
+		return "increment"
+	case AccessDecrement:
+		return "decrement"
+	case AccessSetValue:
+		return "set value"
+	case AccessExpand:
+		return "expand"
+	case AccessCollapse:
+		return "collapse"
+	}
+	return "unknown"
+}
+
+// AccessNode describes a widget to assistive technology. Widgets fill in
+// what they are; the root assigns the ID, bounds and children.
+type AccessNode struct {
+	// ID identifies the node for as long as its widget is shown
+	ID   uint64
+	Role Role
+	// Name is what the node is called, such as the text of a button, and
+	// Description any further explanation
+	Name, Description string
+	// Value is the current value as text, such as the contents of a text
+	// input or the option chosen in a combo box
+	Value string
+	// Numeric is the value of a slider or progress bar within Min to Max,
+	// which are equal when the node has no numeric value
+	Numeric, Min, Max float64
+	State             AccessState
+	// Actions are the actions the node performs besides focus, which the
+	// root offers for focusable nodes
+	Actions []AccessAction
+	// Bounds is the visible region of the node in window coordinates
+	Bounds Rect
+	// Children are the IDs of the nodes within this one in display order
+	Children []uint64
+}
+
+// AccessUpdate is a change to the accessibility tree of a window
+type AccessUpdate struct {
+	// Root is the ID of the window's node
+	Root uint64
+	// Focus is the ID of the node holding keyboard focus, 0 for none
+	Focus uint64
+	// Nodes holds the nodes added or changed since the last update, parents
+	// before their children; the first update holds every node
+	Nodes []AccessNode
+	// Removed holds the IDs of the nodes no longer shown
+	Removed []uint64
+}
+
+// AccessBridge exposes the accessibility tree of a window to the platform's
+// assistive technology interface, such as AT-SPI on Linux, UI Automation on
+// Windows or NSAccessibility on macOS. Bridges receive each change to the
+// tree, and send the actions screen readers ask for back to the window as
+// AccessActionEvents. No bridge ships with goo and windows install none, so
+// screen readers are told nothing until the program sets a bridge of its
+// own, such as one speaking AT-SPI over D-Bus or adapting AccessKit.
+type AccessBridge interface {
+	Update(update AccessUpdate)
+}
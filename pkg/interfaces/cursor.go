@@ -0,0 +1,24 @@
+package interfaces
+
+// Cursor is the shape of the mouse cursor shown over the window
+type Cursor int
+
+const (
+	// CursorDefault is the system's normal cursor
+	CursorDefault Cursor = iota
+	// CursorArrow is the normal arrow, for overriding a cursor requested
+	// further up the tree
+	CursorArrow
+	// CursorIBeam is the text cursor shown over editable text
+	CursorIBeam
+	// CursorHand is the pointing hand shown over links
+	CursorHand
+	// CursorResizeH is shown over edges dragged left and right
+	CursorResizeH
+	// CursorResizeV is shown over edges dragged up and down
+	CursorResizeV
+	// CursorCrosshair is shown for precise picking, such as in drawing tools
+	CursorCrosshair
+	// CursorNotAllowed is shown where an action is not possible
+	CursorNotAllowed
+)
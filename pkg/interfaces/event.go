@@ -0,0 +1,249 @@
+package interfaces
+
+// Event is implemented by all input events delivered to widgets
+type Event interface {
+	isEvent()
+}
+
+// MouseButton identifies a mouse button
+type MouseButton int
+
+const (
+	MouseButtonLeft MouseButton = iota
+	MouseButtonRight
+	MouseButtonMiddle
+)
+
+// Action describes the state transition of a key or button
+type Action int
+
+const (
+	ActionRelease Action = iota
+	ActionPress
+	ActionRepeat
+)
+
+// Modifier is a bit set of keyboard modifier keys held during an event
+type Modifier int
+
+const (
+	ModShift Modifier = 1 << iota
+	ModControl
+	ModAlt
+	ModSuper
+	ModCapsLock
+	ModNumLock
+)
+
+// Key is a physical keyboard key. Values match the GLFW key codes so the
+// window can convert them directly.
+type Key int
+
+const (
+	KeyUnknown   Key = -1
+	KeySpace     Key = 32
+	KeyA         Key = 65
+	KeyC         Key = 67
+	KeyV         Key = 86
+	KeyX         Key = 88
+	KeyY         Key = 89
+	KeyZ         Key = 90
+	KeyEscape    Key = 256
+	KeyEnter     Key = 257
+	KeyTab       Key = 258
+	KeyBackspace Key = 259
+	KeyInsert    Key = 260
+	KeyDelete    Key = 261
+	KeyRight     Key = 262
+	KeyLeft      Key = 263
+	KeyDown      Key = 264
+	KeyUp        Key = 265
+	KeyPageUp    Key = 266
+	KeyPageDown  Key = 267
+	KeyHome      Key = 268
+	KeyEnd       Key = 269
+	KeyF1        Key = 290
+	KeyF2        Key = 291
+	KeyF3        Key = 292
+	KeyF4        Key = 293
+	KeyF5        Key = 294
+	KeyF6        Key = 295
+	KeyF7        Key = 296
+	KeyF8        Key = 297
+	KeyF9        Key = 298
+	KeyF10       Key = 299
+	KeyF11       Key = 300
+	KeyF12       Key = 301
+)
+
+// GamepadButton identifies a button of a gamepad in the standard layout.
+// Values match the GLFW gamepad buttons so the window can convert them
+// directly.
+type GamepadButton int
+
+const (
+	GamepadA GamepadButton = iota
+	GamepadB
+	GamepadX
+	GamepadY
+	GamepadLeftBumper
+	GamepadRightBumper
+	GamepadBack
+	GamepadStart
+	GamepadGuide
+	GamepadLeftThumb
+	GamepadRightThumb
+	GamepadDpadUp
+	GamepadDpadRight
+	GamepadDpadDown
+	GamepadDpadLeft
+)
+
+// MouseMoveEvent is sent when the cursor moves within the window
+type MouseMoveEvent struct {
+	// Position in window coordinates (0,0 = top-left)
+	Position Point
+}
+
+// MouseButtonEvent is sent when a mouse button is pressed or released
+type MouseButtonEvent struct {
+	Position Point
+	Button   MouseButton
+	Action   Action
+	Mods     Modifier
+}
+
+// KeyEvent is sent when a keyboard key is pressed, repeated or released
+type KeyEvent struct {
+	Key      Key
+	Scancode int
+	Action   Action
+	Mods     Modifier
+}
+
+// CharEvent is sent for each unicode character produced by text input
+type CharEvent struct {
+	Char rune
+}
+
+// PreeditEvent is sent while an input method composes text, such as when
+// typing Chinese, Japanese or Korean, each time the composition changes. The
+// focused text widget shows it at the caret until the input method commits
+// it, which arrives as CharEvents, or cancels it with an empty Text. Windows
+// only send the compositions fed to them by Window.Preedit.
+type PreeditEvent struct {
+	// Text is the text composed so far
+	Text string
+	// Caret is the rune index of the input method's caret within Text
+	Caret int
+}
+
+// AccessActionEvent is sent when assistive technology, such as a screen
+// reader, asks a node of the accessibility tree to perform an action
+type AccessActionEvent struct {
+	// Target is the ID of the node
+	Target uint64
+	Action AccessAction
+	// Value is the new value for AccessSetValue
+	Value string
+}
+
+// ScrollEvent is sent when the mouse wheel or trackpad scrolls
+type ScrollEvent struct {
+	Position Point
+	// Offset is the scroll amount along each axis
+	Offset Point
+}
+
+// CursorEnterEvent is sent when the cursor enters the window
+type CursorEnterEvent struct{}
+
+// CursorLeaveEvent is sent when the cursor leaves the window
+type CursorLeaveEvent struct{}
+
+// FileDropEvent is sent when files are dragged from another application and
+// dropped on the window
+type FileDropEvent struct {
+	Position Point
+	// Paths are the paths of the dropped files and directories
+	Paths []string
+}
+
+// TouchPhase is the stage of a touch an event reports
+type TouchPhase int
+
+const (
+	TouchBegin TouchPhase = iota
+	TouchMove
+	TouchEnd
+	// TouchCancel ends a touch the system took over, such as for one of its
+	// own gestures, which should be abandoned rather than acted on
+	TouchCancel
+)
+
+// TouchEvent is sent when a finger touches, moves on or leaves a touch
+// screen. A touch keeps its ID from when it begins until it ends, and
+// several may be down at once. Desktop windows only send the touches fed
+// to them by Window.Touch.
+type TouchEvent struct {
+	ID       int
+	Position Point
+	Phase    TouchPhase
+}
+
+// GamepadEvent is sent when a gamepad button is pressed, repeated while
+// held or released. Pushing the left stick past half way is reported as the
+// d-pad, and only the d-pad repeats.
+type GamepadEvent struct {
+	// Gamepad identifies the gamepad among those connected
+	Gamepad int
+	Button  GamepadButton
+	Action  Action
+}
+
+// ExposeEvent is sent when the window contents were lost, such as when the
+// framebuffer is created or resized, and the whole window must be repainted
+type ExposeEvent struct{}
+
+func (MouseMoveEvent) isEvent()    {}
+func (MouseButtonEvent) isEvent()  {}
+func (KeyEvent) isEvent()          {}
+func (CharEvent) isEvent()         {}
+func (PreeditEvent) isEvent()      {}
+func (AccessActionEvent) isEvent() {}
+func (ScrollEvent) isEvent()       {}
+func (CursorEnterEvent) isEvent()  {}
+func (CursorLeaveEvent) isEvent()  {}
+func (FileDropEvent) isEvent()     {}
+func (TouchEvent) isEvent()        {}
+func (GamepadEvent) isEvent()      {}
+func (ExposeEvent) isEvent()       {}
+
+// Target returns the position an event should be hit tested against.
+// Button presses, touches beginning, scrolls and file drops are delivered
+// only to the topmost widget under the cursor; all other events are
+// broadcast through the tree so widgets can track hover, drags and releases
+// outside their box.
+func Target(ev Event) (at Point, targeted bool) {
+	switch e := ev.(type) {
+	case MouseButtonEvent:
+		if e.Action == ActionPress {
+			return e.Position, true
+		}
+	case TouchEvent:
+		if e.Phase == TouchBegin {
+			return e.Position, true
+		}
+	case ScrollEvent:
+		return e.Position, true
+	case FileDropEvent:
+		return e.Position, true
+	}
+	return Point{}, false
+}
+
+// Contains reports whether the point lies within the box
+func (b *Box) Contains(p Point) bool {
+	return p.X >= b.Position.X && p.X < b.Position.X+b.Size.Width &&
+		p.Y >= b.Position.Y && p.Y < b.Position.Y+b.Size.Height
+}
@@ -0,0 +1,217 @@
+package interfaces
+
+import (
+	"github.com/mleku/goo/pkg/render"
+)
+
+// HitRegion is an interactive region registered while painting
+type HitRegion struct {
+	// Rect is the region in window coordinates, clipped to what is visible
+	Rect Rect
+	// Target is the widget, or any other value, the region belongs to
+	Target any
+	// Layer is 0 for the window's content and counts up through the popups
+	// shown over it
+	Layer int
+	// order is the sequence in which regions were painted, later regions
+	// lying over earlier ones in the same layer
+	order int
+}
+
+// Hits is the registry of interactive regions, found by HitTest from the
+// top down. The root keeps it up to date as it paints: regions inside a
+// repainted part of the window are dropped and registered again by the
+// widgets painted there, so regions must be registered on every paint.
+// Scrolling and clipping widgets push the region their children are visible
+// in, and transforming widgets their transform, so registered rects are in
+// window coordinates. A nil registry ignores registrations.
+type Hits struct {
+	regions []HitRegion
+	// index finds the region of a target in a layer
+	index      map[hitKey]int
+	clips      []Rect
+	transforms []render.Matrix
+	layer      int
+	order      int
+}
+
+// Register adds an interactive region covering a rect for a target, over
+// the regions registered before it. Registering the same target again in
+// the same layer moves its region, keeping its place in the order.
+func (h *Hits) Register(target any, rect Rect) {
+	if h == nil {
+		return
+	}
+	if n := len(h.transforms); n > 0 {
+		b := h.transforms[n-1].Bounds([4]float32{rect.X, rect.Y, rect.Width, rect.Height})
+		rect = Rect{X: b[0], Y: b[1], Width: b[2], Height: b[3]}
+	}
+	if n := len(h.clips); n > 0 {
+		rect = rect.Intersect(h.clips[n-1])
+	}
+	key := hitKey{target: target, layer: h.layer}
+	if i, ok := h.index[key]; ok {
+		// Keep the region's place, as the regions over it may not be painted
+		// again. One clipped away is kept empty, as it contains no point.
+		h.regions[i].Rect = rect
+		return
+	}
+	if rect.Empty() {
+		return
+	}
+	h.order++
+	region := HitRegion{Rect: rect, Target: target, Layer: h.layer, order: h.order}
+	if h.index == nil {
+		h.index = make(map[hitKey]int)
+	}
+	h.index[key] = len(h.regions)
+	h.regions = append(h.regions, region)
+}
+
+// HitTest returns the target of the topmost region containing a point
+func (h *Hits) HitTest(p Point) (target any, ok bool) {
+	if h == nil {
+		return
+	}
+	var top *HitRegion
+	for i := range h.regions {
+		r := &h.regions[i]
+		if r.Rect.Contains(p) && (top == nil || r.above(top)) {
+			top = r
+		}
+	}
+	if top == nil {
+		return
+	}
+	return top.Target, true
+}
+
+// HitTestAll returns the targets of all regions containing a point, topmost first
+func (h *Hits) HitTestAll(p Point) (targets []any) {
+	if h == nil {
+		return
+	}
+	var found []*HitRegion
+	for i := range h.regions {
+		if h.regions[i].Rect.Contains(p) {
+			found = append(found, &h.regions[i])
+		}
+	}
+	// Insertion sort, as few regions overlap a point
+	for i := 1; i < len(found); i++ {
+		for j := i; j > 0 && found[j].above(found[j-1]); j-- {
+			found[j], found[j-1] = found[j-1], found[j]
+		}
+	}
+	for _, r := range found {
+		targets = append(targets, r.Target)
+	}
+	return
+}
+
+// Regions returns the registered regions
+func (h *Hits) Regions() []HitRegion {
+	if h == nil {
+		return nil
+	}
+	return h.regions
+}
+
+// PushClip restricts the regions registered until PopClip to a rect, within
+// the rect pushed before it
+func (h *Hits) PushClip(rect Rect) {
+	if h == nil {
+		return
+	}
+	if n := len(h.clips); n > 0 {
+		rect = rect.Intersect(h.clips[n-1])
+	}
+	h.clips = append(h.clips, rect)
+}
+
+// PopClip removes the rect pushed last by PushClip
+func (h *Hits) PopClip() {
+	if h == nil || len(h.clips) == 0 {
+		return
+	}
+	h.clips = h.clips[:len(h.clips)-1]
+}
+
+// PushTransform maps the rects registered until PopTransform through a
+// matrix, after the matrix pushed before it, registering their bounds
+func (h *Hits) PushTransform(m render.Matrix) {
+	if h == nil {
+		return
+	}
+	if n := len(h.transforms); n > 0 {
+		m = m.Then(h.transforms[n-1])
+	}
+	h.transforms = append(h.transforms, m)
+}
+
+// PopTransform removes the matrix pushed last by PushTransform
+func (h *Hits) PopTransform() {
+	if h == nil || len(h.transforms) == 0 {
+		return
+	}
+	h.transforms = h.transforms[:len(h.transforms)-1]
+}
+
+// SetLayer sets the layer regions are registered in from now on, 0 for the
+// window's content and counting up through the popups shown over it
+func (h *Hits) SetLayer(layer int) {
+	if h != nil {
+		h.layer = layer
+	}
+}
+
+// Repaint drops the regions lying entirely inside a part of the window that
+// is about to be repainted, as the widgets painted there register them again
+func (h *Hits) Repaint(rect Rect) {
+	if h == nil {
+		return
+	}
+	h.keep(func(r *HitRegion) bool { return !rect.Covers(r.Rect) })
+	h.clips = h.clips[:0]
+	h.transforms = h.transforms[:0]
+	h.layer = 0
+}
+
+// DropLayers drops the regions of a layer and those above it, after the
+// popups shown in them close
+func (h *Hits) DropLayers(layer int) {
+	if h == nil {
+		return
+	}
+	h.keep(func(r *HitRegion) bool { return r.Layer < layer })
+}
+
+// keep drops the regions for which fn returns false
+func (h *Hits) keep(fn func(r *HitRegion) bool) {
+	kept := h.regions[:0]
+	for i := range h.regions {
+		if r := &h.regions[i]; fn(r) && !r.Rect.Empty() {
+			kept = append(kept, *r)
+		}
+	}
+	clear(h.regions[len(kept):])
+	h.regions = kept
+	clear(h.index)
+	for i, r := range h.regions {
+		h.index[hitKey{target: r.Target, layer: r.Layer}] = i
+	}
+}
+
+// hitKey identifies the region of a target in a layer
+type hitKey struct {
+	target any
+	layer  int
+}
+
+// above reports whether a region lies over another
+func (r *HitRegion) above(o *HitRegion) bool {
+	if r.Layer != o.Layer {
+		return r.Layer > o.Layer
+	}
+	return r.order > o.order
+}
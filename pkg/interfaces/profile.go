@@ -0,0 +1,118 @@
+package interfaces
+
+import (
+	"time"
+
+	"github.com/mleku/goo/pkg/render"
+)
+
+// PaintSample is what a widget cost to paint over a frame
+type PaintSample struct {
+	// Target is the widget painted
+	Target any
+	// Rect is the box it was last painted in, in window coordinates
+	Rect Rect
+	// Paints is how many times it painted, once for each damaged region
+	// it lay in
+	Paints int
+	// Time is how long its paints took including its descendants, and Self
+	// that time without them
+	Time, Self time.Duration
+	// Commands and Vertices are the draw commands and vertices it added
+	// itself, not counting its descendants'
+	Commands, Vertices int
+}
+
+// PaintProfile records what each widget costs to paint while profiling.
+// Parents paint their children between Begin and End, so the cost of a
+// widget's descendants is told from its own. A nil profile records nothing.
+type PaintProfile struct {
+	samples []PaintSample
+	// index finds the sample of a target
+	index map[any]int
+	stack []paintFrame
+}
+
+// paintFrame is a widget being painted, with what the draw list held and
+// the time when it started, and what its children have cost so far
+type paintFrame struct {
+	sample             int
+	list               *render.DrawList
+	start              time.Time
+	commands, vertices int
+	children           time.Duration
+	childCommands      int
+	childVertices      int
+}
+
+// Begin starts timing the paint of a target in a rect, drawn into a list
+func (p *PaintProfile) Begin(target any, rect Rect, list *render.DrawList) {
+	if p == nil {
+		return
+	}
+	i, ok := p.index[target]
+	if !ok {
+		if p.index == nil {
+			p.index = make(map[any]int)
+		}
+		i = len(p.samples)
+		p.index[target] = i
+		p.samples = append(p.samples, PaintSample{Target: target})
+	}
+	p.samples[i].Rect = rect
+	p.samples[i].Paints++
+	p.stack = append(p.stack, paintFrame{
+		sample:   i,
+		list:     list,
+		start:    time.Now(),
+		commands: len(list.Commands),
+		vertices: len(list.Vertices),
+	})
+}
+
+// End stops timing the paint begun last
+func (p *PaintProfile) End() {
+	if p == nil || len(p.stack) == 0 {
+		return
+	}
+	f := p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+	elapsed := time.Since(f.start)
+	commands := len(f.list.Commands) - f.commands
+	vertices := len(f.list.Vertices) - f.vertices
+	s := &p.samples[f.sample]
+	s.Time += elapsed
+	s.Self += elapsed - f.children
+	s.Commands += commands - f.childCommands
+	s.Vertices += vertices - f.childVertices
+	if n := len(p.stack); n > 0 {
+		parent := &p.stack[n-1]
+		parent.children += elapsed
+		// Children painting into a list of their own, as cached layers
+		// do, leave the parent's list as it was
+		if parent.list == f.list {
+			parent.childCommands += commands
+			parent.childVertices += vertices
+		}
+	}
+}
+
+// Samples returns what each widget painted since the last Reset cost, in
+// the order they first painted
+func (p *PaintProfile) Samples() []PaintSample {
+	if p == nil {
+		return nil
+	}
+	return p.samples
+}
+
+// Reset forgets the samples, for the next frame
+func (p *PaintProfile) Reset() {
+	if p == nil {
+		return
+	}
+	clear(p.samples)
+	p.samples = p.samples[:0]
+	clear(p.index)
+	p.stack = p.stack[:0]
+}
@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"time"
+)
+
+// FrameStats describes how fast a window is drawing
+type FrameStats struct {
+	// FPS is the number of frames drawn in the last second
+	FPS float64
+	// FrameTime is how long the last frame took to draw, not counting the
+	// wait for the buffer swap
+	FrameTime time.Duration
+	// P50, P95 and P99 are percentiles of the recent frame times
+	P50, P95, P99 time.Duration
+	// Recent holds the recent frame times, oldest first
+	Recent []time.Duration
+	// Refresh is the time between refreshes of the display the window is
+	// on, the deadline each frame has, zero when unknown
+	Refresh time.Duration
+}
@@ -1,5 +1,11 @@
 package interfaces
 
+import (
+	"github.com/mleku/goo/pkg/anim"
+	"github.com/mleku/goo/pkg/render"
+	"github.com/mleku/goo/pkg/theme"
+)
+
 // Point represents a 2D coordinate
 type Point struct {
 	X, Y float32
@@ -44,12 +50,130 @@ type Context struct {
 	AvailableSize Size
 	// Painted regions to avoid double painting
 	PaintedRegions []Rect
+	// Clip is the damaged region being repainted. Widgets outside it are
+	// skipped and drawing is scissored to it. An empty clip means unclipped.
+	Clip Rect
+	// DrawList receives the geometry painted by widgets
+	DrawList *render.DrawList
+	// Clipboard gives access to the system clipboard, nil when unavailable
+	Clipboard Clipboard
+	// Clock is the frame clock animations read the time from and request
+	// further frames on. A nil clock uses the current time.
+	Clock *anim.Clock
+	// Theme holds the colors and metrics widgets are drawn with, nil for
+	// the default dark theme
+	Theme *theme.Theme
+	// Hits is the registry of interactive regions, which widgets register in
+	// while painting and look up the widget under a point in, nil when
+	// regions are not tracked
+	Hits *Hits
+	// Stats describes how fast the window has been drawing, nil when unknown
+	Stats *FrameStats
+	// Profile records what each widget costs to paint while the root is
+	// profiling, nil otherwise
+	Profile *PaintProfile
+	// Scale is the number of pixels per unit of the widgets' coordinates,
+	// such as 2 on a high density display, 0 when unknown meaning 1
+	Scale float32
+	// InputMethod is told where the focused text caret is so the input
+	// method can place its candidate window beside it, nil when unavailable
+	InputMethod InputMethod
+	// RTL mirrors the layout for right to left languages: rows place their
+	// children from the right and east and west gravity swap
+	RTL bool
+	// LayoutEpoch counts the times the root discarded every cached layout at
+	// once, such as when the locale switched. Layouts cached in an earlier
+	// epoch are stale.
+	LayoutEpoch uint64
+}
+
+// Clipboard reads and writes the system clipboard
+type Clipboard interface {
+	// Text returns the clipboard contents as text
+	Text() string
+	// SetText replaces the clipboard contents
+	SetText(s string)
+}
+
+// InputMethod is the system's text input method, which composes text such as
+// Chinese, Japanese and Korean from several keys
+type InputMethod interface {
+	// SetCaret places the input method's candidate window beside the caret
+	// of the focused text, given in window coordinates
+	SetCaret(caret Rect)
 }
 
-// Widget defines the interface that all widgets must implement
+// Widget defines the interface that all widgets must implement.
+// Rendering happens in two passes: Layout computes sizes and positions children
+// relative to the widget, then Paint draws the tree at the computed boxes.
+// Layout results are cached until a widget calls MarkNeedsLayout.
 type Widget interface {
-	// Render draws the widget within the given box and returns the actual size used
-	Render(ctx *Context, box *Box) (usedSize Size, err error)
+	// Layout computes the size the widget occupies within the given constraints
+	Layout(ctx *Context, constraints Constraints) (size Size, err error)
+	// Paint draws the widget within the box computed by its parent's layout
+	Paint(ctx *Context, box *Box) (err error)
 	// GetConstraints returns the size constraints for this widget
 	GetConstraints() Constraints
+	// Measure returns the size the widget would take to fit its content
+	// within the maximum of the constraints, without laying it out, so a
+	// container can size children to their content before dividing its
+	// space. A widget that cannot fit may measure larger than the maximum.
+	Measure(constraints Constraints) Size
+	// HandleEvent delivers an input event to the widget laid out in the given
+	// box and reports whether it was consumed
+	HandleEvent(ctx *Context, box *Box, ev Event) (handled bool)
+	// SetParent links the widget to the container that lays it out
+	SetParent(parent Widget)
+	// MarkNeedsLayout discards the cached layout of the widget and its ancestors
+	MarkNeedsLayout()
+	// MarkNeedsPaint schedules the region the widget last painted to be redrawn
+	MarkNeedsPaint()
+}
+
+// Rect returns the region covered by the box
+func (b *Box) Rect() Rect {
+	return Rect{X: b.Position.X, Y: b.Position.Y, Width: b.Size.Width, Height: b.Size.Height}
+}
+
+// Empty reports whether the rect covers no area
+func (r Rect) Empty() bool {
+	return r.Width <= 0 || r.Height <= 0
+}
+
+// Intersect returns the overlap of two rects, which is empty if they do not overlap
+func (r Rect) Intersect(o Rect) Rect {
+	x0, y0 := max(r.X, o.X), max(r.Y, o.Y)
+	x1, y1 := min(r.X+r.Width, o.X+o.Width), min(r.Y+r.Height, o.Y+o.Height)
+	if x1 <= x0 || y1 <= y0 {
+		return Rect{}
+	}
+	return Rect{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}
+}
+
+// Union returns the smallest rect containing both rects
+func (r Rect) Union(o Rect) Rect {
+	if r.Empty() {
+		return o
+	}
+	if o.Empty() {
+		return r
+	}
+	x0, y0 := min(r.X, o.X), min(r.Y, o.Y)
+	x1, y1 := max(r.X+r.Width, o.X+o.Width), max(r.Y+r.Height, o.Y+o.Height)
+	return Rect{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}
+}
+
+// Overlaps reports whether two rects share any area
+func (r Rect) Overlaps(o Rect) bool {
+	return !r.Intersect(o).Empty()
+}
+
+// Contains reports whether the point lies within the rect
+func (r Rect) Contains(p Point) bool {
+	return p.X >= r.X && p.X < r.X+r.Width && p.Y >= r.Y && p.Y < r.Y+r.Height
+}
+
+// Covers reports whether the other rect lies entirely within the rect
+func (r Rect) Covers(o Rect) bool {
+	return o.X >= r.X && o.X+o.Width <= r.X+r.Width && o.Y >= r.Y && o.Y+o.Height <= r.Y+r.Height
 }
@@ -0,0 +1,21 @@
+package interfaces
+
+// WindowArea is the part of a window's frame a press lands in, for windows
+// without the system's title bar and borders that draw their own
+type WindowArea int
+
+const (
+	// AreaClient is the window's content, where presses go to the widgets
+	AreaClient WindowArea = iota
+	// AreaCaption moves the window while dragged, as a title bar does
+	AreaCaption
+	// The edges and corners resize the window while dragged
+	AreaTop
+	AreaBottom
+	AreaLeft
+	AreaRight
+	AreaTopLeft
+	AreaTopRight
+	AreaBottomLeft
+	AreaBottomRight
+)
@@ -0,0 +1,110 @@
+package markup
+
+import (
+	"os"
+	"time"
+
+	"github.com/mleku/goo/pkg/anim"
+	"github.com/mleku/goo/pkg/state"
+	"github.com/mleku/goo/pkg/widget"
+	"lol.mleku.dev/chk"
+)
+
+// pollInterval is how often a document checks whether its file changed
+const pollInterval = 500 * time.Millisecond
+
+// Document is a description loaded from a file, showing the tree it builds
+// in a widget that is rebuilt when the file is reloaded. Polled each frame,
+// it reloads the file after it changes, so a UI can be edited while it runs.
+// A file that fails to build leaves the last tree showing.
+type Document struct {
+	loader *Loader
+	path   string
+	ui     *UI
+	// version counts the reloads, rebuilding the view when it changes
+	version *state.State[int]
+	view    *widget.ObserveWidget
+	// modified and size identify the version of the file loaded
+	modified time.Time
+	size     int64
+	// next is when the file is checked again
+	next     time.Time
+	onReload func(ui *UI, err error)
+}
+
+// Open loads a description from a file with a loader
+func Open(loader *Loader, path string) (d *Document, err error) {
+	d = &Document{loader: loader, path: path, version: state.New(0)}
+	if err = d.Reload(); err != nil {
+		return nil, err
+	}
+	d.view = widget.Observe(func() widget.Widget { return d.ui.Root }, d.version)
+	return
+}
+
+// OnReload sets a function called after each reload with the new tree, or
+// with the error that left the old tree showing, to find widgets by id again
+// or report the error, and returns the document for chaining
+func (d *Document) OnReload(fn func(ui *UI, err error)) *Document {
+	d.onReload = fn
+	return d
+}
+
+// UI returns the tree last built from the file
+func (d *Document) UI() *UI {
+	return d.ui
+}
+
+// Widget returns the widget showing the tree, which replaces its child with
+// each reload, losing any focus or scroll position inside it
+func (d *Document) Widget() widget.Widget {
+	return d.view
+}
+
+// Reload loads the file again and shows the tree it builds
+func (d *Document) Reload() (err error) {
+	var info os.FileInfo
+	if info, err = os.Stat(d.path); chk.E(err) {
+		return
+	}
+	// Remember the version read even if it fails to build, to wait for the next edit
+	d.modified, d.size = info.ModTime(), info.Size()
+	var ui *UI
+	if ui, err = d.loader.Load(d.path); err != nil {
+		if d.onReload != nil {
+			d.onReload(nil, err)
+		}
+		return
+	}
+	d.ui = ui
+	d.version.Update(func(v int) int { return v + 1 })
+	if d.onReload != nil {
+		d.onReload(ui, nil)
+	}
+	return
+}
+
+// Poll reloads the file if it changed since it was loaded, checking at
+// intervals and asking the clock to wake the window for the next check. Call
+// it each frame from the window's render function, before the tree is laid out.
+func (d *Document) Poll(clock *anim.Clock) {
+	now := clock.Now()
+	if now.Before(d.next) {
+		clock.WakeAt(d.next)
+		return
+	}
+	d.next = now.Add(pollInterval)
+	clock.WakeAt(d.next)
+	info, err := os.Stat(d.path)
+	if err != nil {
+		// The file may be between being removed and written again by an editor
+		return
+	}
+	if info.ModTime().Equal(d.modified) && info.Size() == d.size {
+		return
+	}
+	if err = d.Reload(); err != nil {
+		// The old tree stays showing until the file is fixed
+		chk.E(err)
+	}
+}
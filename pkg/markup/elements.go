@@ -0,0 +1,302 @@
+package markup
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mleku/goo/pkg/icons"
+	"github.com/mleku/goo/pkg/text"
+	"github.com/mleku/goo/pkg/widget"
+)
+
+// builtins are the elements every loader starts with
+var builtins = map[string]Element{
+	"column":    container(widget.Column),
+	"row":       container(widget.Row),
+	"overlay":   overlay,
+	"center":    center,
+	"scroll":    scroll,
+	"split":     split,
+	"fill":      fill,
+	"spacer":    spacer,
+	"label":     label,
+	"icon":      icon,
+	"button":    button,
+	"textinput": textInput,
+	"checkbox":  checkbox,
+	"slider":    slider,
+	"progress":  progress,
+}
+
+// container returns the element of a row or column. Children with a flex
+// attribute share the space left after those without in proportion to it.
+// The children are placed along the container by "justify", "start",
+// "center", "end", "between" or "around", and across it by "align",
+// "stretch", "start", "center" or "end".
+func container(create func(constraints ...widget.Constraints) *widget.Container) Element {
+	return func(n *Node) (w widget.Widget, err error) {
+		var children []widget.Widget
+		if children, err = n.Build(); err != nil {
+			return
+		}
+		c := create().Justify(justify(n, "justify")).CrossAlign(crossAlign(n, "align"))
+		for i, child := range children {
+			if weight := n.Children[i].Float("flex", 0); weight > 0 {
+				c.Flex(child, weight)
+			} else {
+				c.Rigid(child)
+			}
+		}
+		return c, nil
+	}
+}
+
+// overlay stacks its children, the first at the bottom
+func overlay(n *Node) (w widget.Widget, err error) {
+	var children []widget.Widget
+	if children, err = n.Build(); err != nil {
+		return
+	}
+	o := widget.Overlay()
+	for _, child := range children {
+		o.Child(child)
+	}
+	return o, nil
+}
+
+// center centers its child
+func center(n *Node) (w widget.Widget, err error) {
+	var child widget.Widget
+	if child, err = n.BuildChild(); err != nil {
+		return
+	}
+	return widget.Center(child), nil
+}
+
+// scroll scrolls its child
+func scroll(n *Node) (w widget.Widget, err error) {
+	var child widget.Widget
+	if child, err = n.BuildChild(); err != nil {
+		return
+	}
+	return widget.Scroll(child), nil
+}
+
+// split shows its two children side by side, or one above the other when
+// vertical, with a divider the user drags
+func split(n *Node) (w widget.Widget, err error) {
+	if len(n.Children) != 2 {
+		return nil, n.errorf("%w: %d, want 2", errChildren, len(n.Children))
+	}
+	var children []widget.Widget
+	if children, err = n.Build(); err != nil {
+		return
+	}
+	s := widget.Split(children[0], children[1]).Ratio(n.Float("ratio", 0.5))
+	if n.Bool("vertical", false) {
+		s.Vertical()
+	}
+	return s, nil
+}
+
+// fill fills its box with a color
+func fill(n *Node) (w widget.Widget, err error) {
+	c := n.Color("color", [4]float32{0, 0, 0, 0})
+	return widget.Fill(c[0], c[1], c[2], c[3]), nil
+}
+
+// spacer is empty space, usually given a flex attribute to push the
+// children after it to the end of a row or column
+func spacer(n *Node) (w widget.Widget, err error) {
+	return widget.Spacer(), nil
+}
+
+// label shows text, aligned "start", "center" or "end"
+func label(n *Node) (w widget.Widget, err error) {
+	l := widget.Label(n.loader.font, n.String("text", "")).
+		Size(n.Float("size", 14)).
+		Align(alignment(n, "align"))
+	if n.Has("color") {
+		c := n.Color("color", [4]float32{})
+		l.Color(c[0], c[1], c[2], c[3])
+	}
+	return l, nil
+}
+
+// icon shows an icon of the built-in set by name, size units square
+func icon(n *Node) (w widget.Widget, err error) {
+	name := n.String("name", "")
+	if !icons.Has(name) {
+		return nil, n.errorf("%w %q", errUnknownIcon, name)
+	}
+	i := widget.IconNamed(name, n.Float("size", 16))
+	if n.Has("color") {
+		c := n.Color("color", [4]float32{})
+		i.Color(c[0], c[1], c[2], c[3])
+	}
+	return i, nil
+}
+
+// button is a button showing its child, or text centered, calling onClick
+func button(n *Node) (w widget.Widget, err error) {
+	var content widget.Widget
+	if len(n.Children) > 0 {
+		if content, err = n.BuildChild(); err != nil {
+			return
+		}
+	} else {
+		content = widget.Label(n.loader.font, n.String("text", "")).
+			Size(n.Float("size", 14)).
+			Align(text.AlignCenter)
+	}
+	b := widget.Button(content).Disabled(n.Bool("disabled", false))
+	if fn := Callback[func()](n, "onClick"); fn != nil {
+		b.OnClick(fn)
+	}
+	return b, nil
+}
+
+// textInput is a line of editable text, calling onChange as it is edited and
+// onSubmit when enter is pressed
+func textInput(n *Node) (w widget.Widget, err error) {
+	t := widget.TextInput(n.loader.font).
+		Size(n.Float("size", 14)).
+		Placeholder(n.String("placeholder", ""))
+	if n.Has("text") {
+		t.SetText(n.String("text", ""))
+	}
+	if fn := Callback[func(string)](n, "onChange"); fn != nil {
+		t.OnChange(fn)
+	}
+	if fn := Callback[func(string)](n, "onSubmit"); fn != nil {
+		t.OnSubmit(fn)
+	}
+	return t, nil
+}
+
+// checkbox is a labeled checkbox, calling onChange when it is toggled
+func checkbox(n *Node) (w widget.Widget, err error) {
+	c := widget.Checkbox(n.loader.font, n.String("text", "")).
+		Checked(n.Bool("checked", false)).
+		Disabled(n.Bool("disabled", false))
+	if fn := Callback[func(bool)](n, "onChange"); fn != nil {
+		c.OnChange(fn)
+	}
+	return c, nil
+}
+
+// slider picks a value from min to max, calling onChange as it is dragged
+func slider(n *Node) (w widget.Widget, err error) {
+	s := widget.Slider(n.Float("min", 0), n.Float("max", 1)).
+		Vertical(n.Bool("vertical", false))
+	if n.Has("step") {
+		s.Step(n.Float("step", 0))
+	}
+	if n.Has("value") {
+		s.SetValue(n.Float("value", 0))
+	}
+	if fn := Callback[func(float32)](n, "onChange"); fn != nil {
+		s.OnChange(fn)
+	}
+	return s, nil
+}
+
+// progress shows progress from 0 to 1, or activity of unknown length when
+// indeterminate
+func progress(n *Node) (w widget.Widget, err error) {
+	p := widget.ProgressBar().Indeterminate(n.Bool("indeterminate", false))
+	p.SetValue(n.Float("value", 0))
+	return p, nil
+}
+
+// alignment reads a text alignment attribute
+func alignment(n *Node, key string) text.Alignment {
+	switch s := n.String(key, "start"); s {
+	case "start":
+		return text.AlignStart
+	case "center":
+		return text.AlignCenter
+	case "end":
+		return text.AlignEnd
+	default:
+		n.fail(fmt.Errorf("%w %q: %q is not start, center or end", errAttribute, key, s))
+		return text.AlignStart
+	}
+}
+
+// parseColor reads a color from a "#rgb", "#rrggbb" or "#rrggbbaa" string or
+// an array of three or four components from 0 to 1
+func parseColor(v any) (color [4]float32, ok bool) {
+	color[3] = 1
+	switch v := v.(type) {
+	case string:
+		hex, found := strings.CutPrefix(v, "#")
+		if !found {
+			return
+		}
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 && len(hex) != 8 {
+			return
+		}
+		for i := 0; i < len(hex); i += 2 {
+			b, err := strconv.ParseUint(hex[i:i+2], 16, 8)
+			if err != nil {
+				return
+			}
+			color[i/2] = float32(b) / 255
+		}
+		return color, true
+	case []any:
+		if len(v) != 3 && len(v) != 4 {
+			return
+		}
+		for i, e := range v {
+			f, isNumber := e.(float64)
+			if !isNumber {
+				return
+			}
+			color[i] = float32(f)
+		}
+		return color, true
+	}
+	return
+}
+
+// justify reads how the children of a row or column are placed along it
+func justify(n *Node, key string) widget.Justify {
+	switch s := n.String(key, "start"); s {
+	case "start":
+		return widget.JustifyStart
+	case "center":
+		return widget.JustifyCenter
+	case "end":
+		return widget.JustifyEnd
+	case "between":
+		return widget.JustifySpaceBetween
+	case "around":
+		return widget.JustifySpaceAround
+	default:
+		n.fail(fmt.Errorf("%w %q: %q is not start, center, end, between or around", errAttribute, key, s))
+		return widget.JustifyStart
+	}
+}
+
+// crossAlign reads how the children of a row or column are placed across it
+func crossAlign(n *Node, key string) widget.CrossAlign {
+	switch s := n.String(key, "stretch"); s {
+	case "stretch":
+		return widget.CrossStretch
+	case "start":
+		return widget.CrossStart
+	case "center":
+		return widget.CrossCenter
+	case "end":
+		return widget.CrossEnd
+	default:
+		n.fail(fmt.Errorf("%w %q: %q is not stretch, start, center or end", errAttribute, key, s))
+		return widget.CrossStretch
+	}
+}
@@ -0,0 +1,23 @@
+package markup
+
+import (
+	"errors"
+)
+
+var (
+	// errUnknownElement is returned for a node whose type has no element registered
+	errUnknownElement = errors.New("unknown element")
+	// errUnknownAttribute is returned for an attribute no element read, usually a typo
+	errUnknownAttribute = errors.New("unknown attribute")
+	// errAttribute is returned for an attribute of the wrong kind of value
+	errAttribute = errors.New("invalid attribute")
+	// errChildren is returned for a node with the wrong number of children
+	errChildren = errors.New("wrong number of children")
+	// errCallback is returned for a callback that is not registered or has
+	// the wrong signature
+	errCallback = errors.New("invalid callback")
+	// errDuplicateID is returned when two nodes have the same id
+	errDuplicateID = errors.New("duplicate id")
+	// errUnknownIcon is returned for an icon name not in the built-in set
+	errUnknownIcon = errors.New("unknown icon")
+)
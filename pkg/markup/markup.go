@@ -0,0 +1,395 @@
+// Package markup builds widget trees from a declarative description in JSON,
+// so a UI can be laid out and restyled without recompiling. Each node is an
+// object naming its element with "type", holding its children in "children"
+// or its only child in "child", and setting the element's attributes with
+// its other fields:
+//
+//	{
+//		"type": "column",
+//		"children": [
+//			{"type": "label", "text": "Name", "size": 16},
+//			{"type": "textinput", "id": "name", "placeholder": "Type here", "onSubmit": "greet"},
+//			{"type": "button", "text": "Greet", "onClick": "greet", "flex": 1}
+//		]
+//	}
+//
+// Attributes naming callbacks are bound to Go functions registered with the
+// loader under those names, so the description decides where the behavior is
+// wired and the program supplies it. Any node may also set "id" to find its
+// widget from Go, "width" and "height" together to fix its size, "padding" and
+// "tooltip", and "flex" to take a share of the space left in a row or column.
+// Elements are looked up by type in the loader, which has the built-in
+// elements registered and takes more.
+//
+// A Document loads a description from a file and watches it, rebuilding the
+// tree it shows when the file changes, so edits appear in the running UI.
+package markup
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/mleku/goo/pkg/text"
+	"github.com/mleku/goo/pkg/widget"
+	"lol.mleku.dev/chk"
+)
+
+// Element builds the widget for a node of its type, reading its attributes
+// and building its children through the node
+type Element func(n *Node) (widget.Widget, error)
+
+// Loader builds widget trees from descriptions, with the elements and
+// callbacks the descriptions can name
+type Loader struct {
+	font      *text.Font
+	elements  map[string]Element
+	callbacks map[string]any
+}
+
+// NewLoader creates a loader with the built-in elements, drawing text in a font
+func NewLoader(font *text.Font) *Loader {
+	l := &Loader{
+		font:      font,
+		elements:  make(map[string]Element),
+		callbacks: make(map[string]any),
+	}
+	for name, e := range builtins {
+		l.elements[name] = e
+	}
+	return l
+}
+
+// Font returns the font the loader's elements draw text in
+func (l *Loader) Font() *text.Font {
+	return l.font
+}
+
+// Element registers an element under a type name, replacing any element of
+// that name, and returns the loader for chaining
+func (l *Loader) Element(name string, e Element) *Loader {
+	l.elements[name] = e
+	return l
+}
+
+// Callback registers a function under a name for attributes to bind to, and
+// returns the loader for chaining. The function must have the signature the
+// attribute expects, such as func() for a button's onClick or func(string)
+// for a text input's onSubmit.
+func (l *Loader) Callback(name string, fn any) *Loader {
+	l.callbacks[name] = fn
+	return l
+}
+
+// Load builds the tree described in a file
+func (l *Loader) Load(path string) (ui *UI, err error) {
+	var data []byte
+	if data, err = os.ReadFile(path); chk.E(err) {
+		return
+	}
+	if ui, err = l.Parse(data); err != nil {
+		err = fmt.Errorf("%s: %w", path, err)
+	}
+	return
+}
+
+// Parse builds the tree described in JSON
+func (l *Loader) Parse(data []byte) (ui *UI, err error) {
+	root := &Node{}
+	if err = json.Unmarshal(data, root); err != nil {
+		return
+	}
+	ui = &UI{ids: make(map[string]widget.Widget)}
+	if ui.Root, err = l.build(ui, root, root.Type); err != nil {
+		return nil, err
+	}
+	if err = root.unused(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// build builds the widget for a node, wrapped as its common attributes ask
+func (l *Loader) build(ui *UI, n *Node, path string) (w widget.Widget, err error) {
+	n.loader, n.ui, n.path = l, ui, path
+	element, ok := l.elements[n.Type]
+	if !ok {
+		return nil, n.errorf("%w %q", errUnknownElement, n.Type)
+	}
+	if w, err = element(n); err != nil {
+		return
+	}
+	if len(n.Children) > 0 && !n.built {
+		return nil, n.errorf("%w: %d, want none", errChildren, len(n.Children))
+	}
+	if id := n.String("id", ""); id != "" {
+		if _, ok := ui.ids[id]; ok {
+			return nil, n.errorf("%w %q", errDuplicateID, id)
+		}
+		ui.ids[id] = w
+	}
+	if n.Has("padding") {
+		w = widget.Padding(w, n.Insets("padding"))
+	}
+	if n.Has("width") != n.Has("height") {
+		n.fail(fmt.Errorf("%w: width and height fix the size together", errAttribute))
+	} else if n.Has("width") {
+		w = widget.NewFixedSize(n.Float("width", 0), n.Float("height", 0), w)
+	}
+	if tip := n.String("tooltip", ""); tip != "" {
+		w = widget.WithTooltip(w, l.font, tip)
+	}
+	// The parent reads flex, which is common to every node
+	n.used["flex"] = true
+	return w, n.err
+}
+
+// UI is a widget tree built from a description
+type UI struct {
+	// Root is the widget of the top node
+	Root widget.Widget
+	ids  map[string]widget.Widget
+}
+
+// ByID returns the widget the element built for the node with an id, inside
+// any wrappers its common attributes added, nil if there is none
+func (u *UI) ByID(id string) widget.Widget {
+	return u.ids[id]
+}
+
+// Node is a node of a description being built
+type Node struct {
+	// Type names the element that builds the node
+	Type string
+	// Children are the node's child nodes
+	Children []*Node
+	attrs    map[string]json.RawMessage
+	// used marks the attributes read, so the rest can be reported as unknown
+	used   map[string]bool
+	loader *Loader
+	ui     *UI
+	// path locates the node in the description for errors
+	path string
+	// err is the first attribute error, returned after the element built
+	err error
+	// built is set once the children were built
+	built bool
+}
+
+// UnmarshalJSON implements json.Unmarshaler for Node
+func (n *Node) UnmarshalJSON(data []byte) (err error) {
+	if err = json.Unmarshal(data, &n.attrs); err != nil {
+		return
+	}
+	n.used = make(map[string]bool)
+	if err = n.take("type", &n.Type); err != nil {
+		return
+	}
+	if n.Type == "" {
+		return fmt.Errorf("%w: node without a type", errAttribute)
+	}
+	var child *Node
+	if err = n.take("child", &child); err != nil {
+		return
+	}
+	if err = n.take("children", &n.Children); err != nil {
+		return
+	}
+	if child != nil {
+		n.Children = append([]*Node{child}, n.Children...)
+	}
+	return
+}
+
+// take decodes and removes a structural field
+func (n *Node) take(key string, v any) (err error) {
+	raw, ok := n.attrs[key]
+	if !ok {
+		return
+	}
+	delete(n.attrs, key)
+	if err = json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("%w %q: %w", errAttribute, key, err)
+	}
+	return
+}
+
+// Loader returns the loader building the node
+func (n *Node) Loader() *Loader {
+	return n.loader
+}
+
+// Has reports whether the node sets an attribute
+func (n *Node) Has(key string) bool {
+	_, ok := n.attrs[key]
+	return ok
+}
+
+// decode reads an attribute into v, reporting whether it was set. An
+// attribute of the wrong kind is recorded as the node's error.
+func (n *Node) decode(key string, v any) bool {
+	raw, ok := n.attrs[key]
+	if !ok {
+		return false
+	}
+	n.used[key] = true
+	if err := json.Unmarshal(raw, v); err != nil {
+		n.fail(fmt.Errorf("%w %q: %w", errAttribute, key, err))
+		return false
+	}
+	return true
+}
+
+// String returns a text attribute, or def when it is not set
+func (n *Node) String(key, def string) string {
+	if s := def; n.decode(key, &s) {
+		return s
+	}
+	return def
+}
+
+// Float returns a number attribute, or def when it is not set
+func (n *Node) Float(key string, def float32) float32 {
+	if f := def; n.decode(key, &f) {
+		return f
+	}
+	return def
+}
+
+// Bool returns a true or false attribute, or def when it is not set
+func (n *Node) Bool(key string, def bool) bool {
+	if b := def; n.decode(key, &b) {
+		return b
+	}
+	return def
+}
+
+// Color returns a color attribute, written as "#rgb", "#rrggbb" or
+// "#rrggbbaa", or as an array of red, green, blue and optionally alpha from
+// 0 to 1, or def when it is not set
+func (n *Node) Color(key string, def [4]float32) [4]float32 {
+	var v any
+	if !n.decode(key, &v) {
+		return def
+	}
+	color, ok := parseColor(v)
+	if !ok {
+		n.fail(fmt.Errorf("%w %q: not a color", errAttribute, key))
+		return def
+	}
+	return color
+}
+
+// Insets returns an insets attribute, written as one distance for every
+// edge, two for the vertical and horizontal edges, or four for the top,
+// right, bottom and left edges
+func (n *Node) Insets(key string) (insets widget.Insets) {
+	var v any
+	if !n.decode(key, &v) {
+		return
+	}
+	var d []float32
+	switch v := v.(type) {
+	case float64:
+		d = []float32{float32(v)}
+	case []any:
+		for _, e := range v {
+			f, ok := e.(float64)
+			if !ok {
+				d = nil
+				break
+			}
+			d = append(d, float32(f))
+		}
+	}
+	switch len(d) {
+	case 1:
+		insets = widget.UniformInsets(d[0])
+	case 2:
+		insets = widget.SymmetricInsets(d[0], d[1])
+	case 4:
+		insets = widget.Insets{Top: d[0], Right: d[1], Bottom: d[2], Left: d[3]}
+	default:
+		n.fail(fmt.Errorf("%w %q: not insets", errAttribute, key))
+	}
+	return
+}
+
+// Callback returns the function registered under the name an attribute
+// holds, nil when the attribute is not set. A name that is not registered,
+// or a function without the signature T, is recorded as the node's error.
+func Callback[T any](n *Node, key string) (fn T) {
+	var name string
+	if !n.decode(key, &name) {
+		return
+	}
+	registered, ok := n.loader.callbacks[name]
+	if !ok {
+		n.fail(fmt.Errorf("%w %q for %q: not registered", errCallback, name, key))
+		return
+	}
+	if fn, ok = registered.(T); !ok {
+		n.fail(fmt.Errorf("%w %q for %q: %T is not %T", errCallback, name, key, registered, fn))
+	}
+	return
+}
+
+// Build builds the widgets of the node's children
+func (n *Node) Build() (children []widget.Widget, err error) {
+	n.built = true
+	for i, c := range n.Children {
+		var w widget.Widget
+		if w, err = n.loader.build(n.ui, c, fmt.Sprintf("%s > %s[%d]", n.path, c.Type, i)); err != nil {
+			return
+		}
+		children = append(children, w)
+	}
+	return
+}
+
+// BuildChild builds the widget of the node's only child
+func (n *Node) BuildChild() (child widget.Widget, err error) {
+	if len(n.Children) != 1 {
+		return nil, n.errorf("%w: %d, want 1", errChildren, len(n.Children))
+	}
+	var children []widget.Widget
+	if children, err = n.Build(); err != nil {
+		return
+	}
+	return children[0], nil
+}
+
+// fail records the first attribute error of the node
+func (n *Node) fail(err error) {
+	if n.err == nil {
+		n.err = n.errorf("%w", err)
+	}
+}
+
+// errorf returns an error located at the node
+func (n *Node) errorf(format string, args ...any) error {
+	return fmt.Errorf("%s: "+format, append([]any{n.path}, args...)...)
+}
+
+// unused returns an error for the first attribute of the node or its
+// descendants that nothing read
+func (n *Node) unused() (err error) {
+	var keys []string
+	for key := range n.attrs {
+		if !n.used[key] {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) > 0 {
+		slices.Sort(keys)
+		return n.errorf("%w %s", errUnknownAttribute, strings.Join(keys, ", "))
+	}
+	for _, c := range n.Children {
+		if err = c.unused(); err != nil {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,162 @@
+// Package notify shows the system's desktop notifications and puts an icon
+// in the system tray, with a menu, so programs can keep running in the
+// background after hiding their windows. Like the dialogs package it drives
+// the platform's own tools: notify-send and yad on Linux desktops, osascript
+// on macOS and PowerShell on Windows.
+//
+// Where the system lacks a tool, such as a Linux desktop without yad or the
+// tray on macOS, the functions return ErrUnavailable and programs carry on
+// without them.
+package notify
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnavailable is returned when the system has no tool for notifications
+// or the tray
+var ErrUnavailable = errors.New("notify: not available on this system")
+
+// Urgency is how urgently a notification asks for attention
+type Urgency int
+
+const (
+	Normal Urgency = iota
+	Low
+	Critical
+)
+
+// level returns the notify-send name of an urgency, normal for values that
+// are not one of the constants
+func (u Urgency) level() string {
+	switch u {
+	case Low:
+		return "low"
+	case Critical:
+		return "critical"
+	}
+	return "normal"
+}
+
+// Notification is a message shown by the system outside the program's
+// windows
+type Notification struct {
+	// Title is the first line, in bold on most systems
+	Title string
+	// Body is the text below the title
+	Body string
+	// Icon is the path of an image shown beside the text, or the name of an
+	// icon of the desktop's theme on Linux, the program's on other systems
+	Icon string
+	// App names the program sending the notification where the system
+	// shows it
+	App string
+	// Urgency critical notifications stay until dismissed on most Linux
+	// desktops
+	Urgency Urgency
+	// Timeout is how long the notification is shown, the system's choice
+	// when zero
+	Timeout time.Duration
+}
+
+// Send shows a notification, returning once it has been handed to the
+// system's tool without waiting for the notification to close
+func Send(n Notification) (err error) {
+	command := notifyCommand(n)
+	if command == nil {
+		return ErrUnavailable
+	}
+	if _, err = exec.LookPath(command[0]); err != nil {
+		return ErrUnavailable
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	if err = cmd.Start(); err != nil {
+		return
+	}
+	go cmd.Wait()
+	return
+}
+
+// Available reports whether the system can show notifications
+func Available() bool {
+	command := notifyCommand(Notification{})
+	if command == nil {
+		return false
+	}
+	_, err := exec.LookPath(command[0])
+	return err == nil
+}
+
+// notifyCommand builds the command showing a notification on this system,
+// nil when there is none
+func notifyCommand(n Notification) []string {
+	switch runtime.GOOS {
+	case "darwin":
+		script := "display notification " + appleString(n.Body) + " with title " + appleString(n.Title)
+		if n.App != "" {
+			script += " subtitle " + appleString(n.App)
+		}
+		return []string{"osascript", "-e", script}
+	case "windows":
+		// A balloon from a tray icon of its own, which lives as long as the
+		// balloon is shown
+		timeout := n.Timeout
+		if timeout <= 0 {
+			timeout = 5 * time.Second
+		}
+		kind := "Info"
+		if n.Urgency == Critical {
+			kind = "Warning"
+		}
+		icon := "[Drawing.SystemIcons]::Information"
+		if n.Icon != "" {
+			icon = "[Drawing.Icon]::ExtractAssociatedIcon(" + powerShellString(n.Icon) + ")"
+		}
+		ms := strconv.FormatInt(timeout.Milliseconds(), 10)
+		script := "Add-Type -AssemblyName System.Windows.Forms, System.Drawing; " +
+			"$n = New-Object Windows.Forms.NotifyIcon; " +
+			"$n.Icon = " + icon + "; " +
+			"$n.Text = " + powerShellString(truncate(n.App, 63)) + "; " +
+			"$n.Visible = $true; " +
+			"$n.ShowBalloonTip(" + ms + ", " + powerShellString(n.Title) + ", " + powerShellString(n.Body) + ", '" + kind + "'); " +
+			"Start-Sleep -Milliseconds " + ms + "; $n.Dispose()"
+		return []string{"powershell", "-NoProfile", "-Command", script}
+	case "linux", "freebsd", "openbsd", "netbsd", "dragonfly":
+		args := []string{"notify-send", "--urgency=" + n.Urgency.level()}
+		if n.Timeout > 0 {
+			args = append(args, "--expire-time="+strconv.FormatInt(n.Timeout.Milliseconds(), 10))
+		}
+		if n.Icon != "" {
+			args = append(args, "--icon="+n.Icon)
+		}
+		if n.App != "" {
+			args = append(args, "--app-name="+n.App)
+		}
+		// A body starting with a dash would be read as an option
+		return append(args, "--", n.Title, n.Body)
+	}
+	return nil
+}
+
+// appleString quotes a string for AppleScript
+func appleString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// powerShellString quotes a string for PowerShell
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// truncate shortens a string to at most n runes
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
@@ -0,0 +1,29 @@
+package notify
+
+import (
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestUrgencyLevel(t *testing.T) {
+	tests := []struct {
+		urgency Urgency
+		want    string
+	}{
+		{Normal, "normal"},
+		{Low, "low"},
+		{Critical, "critical"},
+		{-1, "normal"},
+		{Critical + 1, "normal"},
+	}
+	for _, tt := range tests {
+		if got := tt.urgency.level(); got != tt.want {
+			t.Errorf("urgency %d: got %q, want %q", tt.urgency, got, tt.want)
+		}
+		command := notifyCommand(Notification{Title: "title", Urgency: tt.urgency})
+		if runtime.GOOS == "linux" && !slices.Contains(command, "--urgency="+tt.want) {
+			t.Errorf("urgency %d: command %q does not ask for %s", tt.urgency, command, tt.want)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package notify
+
+import (
+	"bufio"
+	"io"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"lol.mleku.dev/chk"
+)
+
+// MenuItem is an entry of the tray icon's menu. An item without a label
+// separates the items around it.
+type MenuItem struct {
+	Label    string
+	Disabled bool
+	// Action is called when the item is chosen
+	Action func()
+}
+
+// TrayOptions configure a tray icon
+type TrayOptions struct {
+	// Icon is the path of the image shown in the tray, a PNG file or an ICO
+	// file on Windows, or the name of an icon of the desktop's theme on Linux
+	Icon string
+	// Tooltip is shown while the pointer rests on the icon
+	Tooltip string
+	// Menu is shown when the icon is right clicked
+	Menu []MenuItem
+	// OnClick is called when the icon is clicked, typically showing the
+	// program's window again
+	OnClick func()
+	// Post runs the callbacks, given the call, on the goroutine running the
+	// window so they can show windows and update widgets. Without it the
+	// callbacks run on a goroutine of their own.
+	Post func(fn func())
+}
+
+// Tray is an icon in the system tray. It is shown by a helper process,
+// yad on Linux desktops and PowerShell on Windows, which reports clicks on
+// its standard output; macOS has no tool showing one.
+type Tray struct {
+	// mu guards the options, read by the goroutine reading the helper
+	mu      sync.Mutex
+	options TrayOptions
+	cmd     *exec.Cmd
+	// input sends commands to a helper that listens for changes, nil for
+	// helpers started again to change
+	input io.WriteCloser
+}
+
+// NewTray shows an icon in the system tray, returning ErrUnavailable where
+// the system has no tool to show one
+func NewTray(options TrayOptions) (t *Tray, err error) {
+	t = &Tray{options: options}
+	if err = t.start(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// SetIcon changes the image shown in the tray
+func (t *Tray) SetIcon(icon string) {
+	t.mu.Lock()
+	t.options.Icon = icon
+	t.mu.Unlock()
+	t.update("icon:" + icon)
+}
+
+// SetTooltip changes the text shown while the pointer rests on the icon
+func (t *Tray) SetTooltip(tooltip string) {
+	t.mu.Lock()
+	t.options.Tooltip = tooltip
+	t.mu.Unlock()
+	t.update("tooltip:" + oneLine(tooltip))
+}
+
+// SetMenu replaces the items of the icon's menu
+func (t *Tray) SetMenu(items ...MenuItem) {
+	t.mu.Lock()
+	t.options.Menu = items
+	t.mu.Unlock()
+	t.update("menu:" + yadMenu(items))
+}
+
+// Close removes the icon from the tray
+func (t *Tray) Close() {
+	t.stop()
+}
+
+// start starts the helper showing the icon and the goroutine reading what
+// the user clicks
+func (t *Tray) start() (err error) {
+	command := t.command()
+	if command == nil {
+		return ErrUnavailable
+	}
+	if _, err = exec.LookPath(command[0]); err != nil {
+		return ErrUnavailable
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	var out io.ReadCloser
+	if out, err = cmd.StdoutPipe(); chk.E(err) {
+		return
+	}
+	var input io.WriteCloser
+	if runtime.GOOS != "windows" {
+		if input, err = cmd.StdinPipe(); chk.E(err) {
+			return
+		}
+	}
+	if err = cmd.Start(); chk.E(err) {
+		return
+	}
+	t.cmd, t.input = cmd, input
+	go t.read(out)
+	go cmd.Wait()
+	return
+}
+
+// stop ends the helper showing the icon
+func (t *Tray) stop() {
+	if t.cmd == nil {
+		return
+	}
+	if t.input != nil {
+		// yad removes its icon when told to quit
+		_, _ = io.WriteString(t.input, "quit\n")
+		t.input.Close()
+	} else {
+		_ = t.cmd.Process.Kill()
+	}
+	t.cmd, t.input = nil, nil
+}
+
+// update sends a change to a helper that listens for them, or starts the
+// helper again with the changed options
+func (t *Tray) update(line string) {
+	if t.cmd == nil {
+		return
+	}
+	if t.input != nil {
+		_, _ = io.WriteString(t.input, line+"\n")
+		return
+	}
+	t.stop()
+	chk.E(t.start())
+}
+
+// read passes the clicks the helper reports to the callbacks until it exits
+func (t *Tray) read(out io.Reader) {
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		t.mu.Lock()
+		var fn func()
+		switch {
+		case line == "click":
+			fn = t.options.OnClick
+		case strings.HasPrefix(line, "menu "):
+			if i, err := strconv.Atoi(strings.TrimPrefix(line, "menu ")); err == nil && i >= 0 && i < len(t.options.Menu) {
+				fn = t.options.Menu[i].Action
+			}
+		}
+		post := t.options.Post
+		t.mu.Unlock()
+		switch {
+		case fn == nil:
+		case post != nil:
+			post(fn)
+		default:
+			fn()
+		}
+	}
+}
+
+// command builds the command running the helper for this system, nil when
+// there is none
+func (t *Tray) command() []string {
+	o := t.options
+	switch runtime.GOOS {
+	case "darwin":
+		return nil
+	case "windows":
+		return powerShellTray(o)
+	}
+	// yad runs the commands of the icon and its items with its own output,
+	// which is read for the clicks
+	return []string{"yad", "--notification", "--listen",
+		"--image=" + o.Icon,
+		"--text=" + oneLine(o.Tooltip),
+		"--command=echo click",
+		"--menu=" + yadMenu(o.Menu),
+	}
+}
+
+// yadMenu writes the items of a menu as yad reads them: each label and its
+// command separated by an exclamation mark, the items by bars, an empty
+// label making a separator
+func yadMenu(items []MenuItem) string {
+	clean := strings.NewReplacer("|", "/", "!", ".", "\n", " ")
+	entries := make([]string, len(items))
+	for i, item := range items {
+		if item.Label == "" {
+			continue
+		}
+		entries[i] = clean.Replace(item.Label) + "!"
+		if !item.Disabled {
+			entries[i] += "echo menu " + strconv.Itoa(i)
+		}
+	}
+	return strings.Join(entries, "|")
+}
+
+// powerShellTray builds the command showing the icon with Windows Forms
+// through PowerShell, which runs until killed. Changes start it again.
+func powerShellTray(o TrayOptions) []string {
+	var script strings.Builder
+	script.WriteString("Add-Type -AssemblyName System.Windows.Forms, System.Drawing; ")
+	script.WriteString("function Say($s) { [Console]::Out.WriteLine($s); [Console]::Out.Flush() }; ")
+	script.WriteString("$n = New-Object Windows.Forms.NotifyIcon; ")
+	switch {
+	case strings.HasSuffix(strings.ToLower(o.Icon), ".ico"):
+		script.WriteString("$n.Icon = New-Object Drawing.Icon(" + powerShellString(o.Icon) + "); ")
+	case o.Icon != "":
+		script.WriteString("$n.Icon = [Drawing.Icon]::FromHandle(([Drawing.Bitmap]::FromFile(" + powerShellString(o.Icon) + ")).GetHicon()); ")
+	default:
+		script.WriteString("$n.Icon = [Drawing.SystemIcons]::Application; ")
+	}
+	// Tooltips longer than 63 characters are refused
+	script.WriteString("$n.Text = " + powerShellString(truncate(oneLine(o.Tooltip), 63)) + "; ")
+	script.WriteString("$m = New-Object Windows.Forms.ContextMenuStrip; ")
+	for i, item := range o.Menu {
+		if item.Label == "" {
+			script.WriteString("[void]$m.Items.Add((New-Object Windows.Forms.ToolStripSeparator)); ")
+			continue
+		}
+		script.WriteString("$i = $m.Items.Add(" + powerShellString(item.Label) + "); ")
+		if item.Disabled {
+			script.WriteString("$i.Enabled = $false; ")
+		}
+		script.WriteString("$i.add_Click({ Say 'menu " + strconv.Itoa(i) + "' }); ")
+	}
+	script.WriteString("$n.ContextMenuStrip = $m; ")
+	script.WriteString("$n.add_MouseClick({ if ($_.Button -eq 'Left') { Say 'click' } }); ")
+	script.WriteString("$n.Visible = $true; [Windows.Forms.Application]::Run()")
+	return []string{"powershell", "-NoProfile", "-STA", "-Command", script.String()}
+}
+
+// oneLine replaces the line breaks of a string with spaces, as the helpers
+// read their commands a line each
+func oneLine(s string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+}
@@ -0,0 +1,121 @@
+package render
+
+import (
+	"image"
+	"math"
+)
+
+// blurReach is how many standard deviations away a blur samples
+const blurReach = 3
+
+// Blur draws a rect filled with what was drawn below it blurred by a
+// gaussian with a standard deviation of radius, as frosted glass. The
+// pixels sampled reach blurReach times the radius beyond the rect, past
+// the clip rect, which like rounded clips confines only what is drawn.
+func (d *DrawList) Blur(x, y, width, height, radius float32) {
+	if width <= 0 || height <= 0 || radius <= 0 || d.clippedOut(x, y, width, height) {
+		return
+	}
+	reach := radius * blurReach
+	backdrop := [4]float32{x - reach, y - reach, width + 2*reach, height + 2*reach}
+	m := d.Transform()
+	if !m.IsIdentity() {
+		backdrop = m.Bounds(backdrop)
+		// Blur by the radius as drawn, for transforms that scale
+		radius *= float32(math.Sqrt(math.Abs(float64(m.A*m.D - m.B*m.C))))
+	}
+	clip, clipped := d.ClipRect()
+	mk := d.currentMask()
+	d.Commands = append(d.Commands, Command{
+		Clip:       clip,
+		Clipped:    clipped,
+		Mask:       mk.rect,
+		MaskRadius: mk.radius,
+		Masked:     mk.set,
+		Blur:       radius,
+		Backdrop:   backdrop,
+		First:      len(d.Vertices),
+		Count:      6,
+	})
+	color := d.fade([4]float32{1, 1, 1, 1})
+	x1, y1 := x+width, y+height
+	d.Vertices = append(d.Vertices,
+		vertex(x, y, 0, 0, color),
+		vertex(x1, y, 1, 0, color),
+		vertex(x1, y1, 1, 1, color),
+		vertex(x, y, 0, 0, color),
+		vertex(x1, y1, 1, 1, color),
+		vertex(x, y1, 0, 1, color),
+	)
+}
+
+// blur blurs the pixels of the canvas below a blur command into a texture,
+// returning it with the pixel its top left corner came from, or nil when
+// there are none
+func (c *Canvas) blur(cmd *Command, scaleX, scaleY float32) (t *Texture, at image.Point) {
+	b := cmd.Backdrop
+	r := image.Rect(
+		int(math.Floor(float64(b[0]*scaleX))), int(math.Floor(float64(b[1]*scaleY))),
+		int(math.Ceil(float64((b[0]+b[2])*scaleX))), int(math.Ceil(float64((b[1]+b[3])*scaleY))),
+	).Intersect(c.img.Bounds())
+	if r.Empty() {
+		return
+	}
+	w, h := r.Dx(), r.Dy()
+	pix := make([]float32, w*h*4)
+	for y := range h {
+		row := c.img.Pix[c.img.PixOffset(r.Min.X, r.Min.Y+y):]
+		for i := range w * 4 {
+			pix[y*w*4+i] = float32(row[i]) / 255
+		}
+	}
+	sigma := float64(cmd.Blur * scaleX)
+	reach := int(math.Ceil(sigma * blurReach))
+	weights := make([]float32, reach+1)
+	for i := range weights {
+		weights[i] = float32(math.Exp(-0.5 * float64(i*i) / (sigma * sigma)))
+	}
+	// Blur across and then down, clamping at the edges as textures do
+	pix = gaussian(pix, w, h, 4, 4*w, weights)
+	pix = gaussian(pix, h, w, 4*w, 4, weights)
+	t = NewTexture(w, h, FormatRGBA, make([]byte, w*h*4))
+	t.Premultiplied = true
+	for i, v := range pix {
+		t.Pixels[i] = channel(v)
+	}
+	return t, r.Min
+}
+
+// gaussian blurs count lines of pixels of length n along one direction,
+// stride apart along it and lines apart between lines, returning the
+// blurred pixels
+func gaussian(pix []float32, n, count, stride, lines int, weights []float32) []float32 {
+	out := make([]float32, len(pix))
+	for line := range count {
+		base := line * lines
+		for i := range n {
+			var sum [4]float32
+			var total float32
+			for k := -(len(weights) - 1); k < len(weights); k++ {
+				w := weights[abs32(k)]
+				j := base + min(max(i+k, 0), n-1)*stride
+				for ch := range sum {
+					sum[ch] += pix[j+ch] * w
+				}
+				total += w
+			}
+			for ch := range sum {
+				out[base+i*stride+ch] = sum[ch] / total
+			}
+		}
+	}
+	return out
+}
+
+// abs32 returns the magnitude of an int
+func abs32(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,141 @@
+//go:build !js
+
+package render
+
+import (
+	"math"
+
+	"github.com/go-gl/gl/all-core/gl"
+	"lol.mleku.dev/chk"
+)
+
+// blurVertexShader covers the target with one triangle made from the
+// vertex index, needing no vertex buffer
+const blurVertexShader = `#version 330 core
+out vec2 uv;
+void main() {
+	vec2 p = vec2((gl_VertexID << 1) & 2, gl_VertexID & 2);
+	uv = p;
+	gl_Position = vec4(p*2.0 - 1.0, 0.0, 1.0);
+}
+`
+
+// blurFragmentShader is one direction of a separable gaussian blur
+const blurFragmentShader = `#version 330 core
+in vec2 uv;
+uniform sampler2D tex;
+// step is one texel along the direction blurred and sigma the standard
+// deviation in texels
+uniform vec2 step;
+uniform float sigma;
+out vec4 outColor;
+void main() {
+	int radius = int(ceil(sigma*3.0));
+	vec4 sum = texture(tex, uv);
+	float total = 1.0;
+	for (int i = 1; i <= radius; i++) {
+		float w = exp(-0.5*float(i*i)/(sigma*sigma));
+		sum += (texture(tex, uv + step*float(i)) + texture(tex, uv - step*float(i)))*w;
+		total += 2.0*w;
+	}
+	outColor = sum/total;
+}
+`
+
+// blur copies the pixels of the bound framebuffer below a blur command and
+// blurs them into the first scratch texture, returning the rect of
+// framebuffer pixels (x, y, width, height) they came from, counting rows
+// from the bottom, or false when there are none
+func (r *Renderer) blur(cmd *Command, scaleX, scaleY, height float32, flipped bool) (rect [4]float32, ok bool) {
+	if r.blurFailed {
+		return
+	}
+	if r.blurProgram == 0 {
+		var err error
+		if r.blurProgram, err = linkProgram(blurVertexShader, blurFragmentShader); chk.E(err) {
+			r.blurFailed = true
+			return
+		}
+		gl.GenVertexArrays(1, &r.blurVAO)
+		r.blurStep = gl.GetUniformLocation(r.blurProgram, gl.Str("step\x00"))
+		r.blurSigma = gl.GetUniformLocation(r.blurProgram, gl.Str("sigma\x00"))
+		gl.UseProgram(r.blurProgram)
+		gl.Uniform1i(gl.GetUniformLocation(r.blurProgram, gl.Str("tex\x00")), 0)
+		r.scratch = [2]*Texture{{}, {}}
+	}
+	var vp [4]int32
+	gl.GetIntegerv(gl.VIEWPORT, &vp[0])
+	b := cmd.Backdrop
+	y := height - b[1] - b[3]
+	if flipped {
+		y = b[1]
+	}
+	x0 := max(int32(math.Floor(float64(b[0]*scaleX))), 0)
+	y0 := max(int32(math.Floor(float64(y*scaleY))), 0)
+	x1 := min(int32(math.Ceil(float64((b[0]+b[2])*scaleX))), vp[2])
+	y1 := min(int32(math.Ceil(float64((y+b[3])*scaleY))), vp[3])
+	w, h := x1-x0, y1-y0
+	if w <= 0 || h <= 0 {
+		return
+	}
+	var previous int32
+	gl.GetIntegerv(gl.FRAMEBUFFER_BINDING, &previous)
+	for _, t := range r.scratch {
+		t.Width, t.Height = int(w), int(h)
+		r.target(t)
+	}
+	gl.Disable(gl.SCISSOR_TEST)
+	gl.BindTexture(gl.TEXTURE_2D, r.scratch[0].id)
+	gl.CopyTexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, x0, y0, w, h)
+
+	gl.Disable(gl.BLEND)
+	gl.UseProgram(r.blurProgram)
+	gl.BindVertexArray(r.blurVAO)
+	gl.Viewport(0, 0, w, h)
+	gl.Uniform1f(r.blurSigma, cmd.Blur*scaleX)
+	// Blur across into the second texture and down back into the first
+	gl.BindFramebuffer(gl.FRAMEBUFFER, r.scratch[1].fbo)
+	gl.Uniform2f(r.blurStep, 1/float32(w), 0)
+	gl.DrawArrays(gl.TRIANGLES, 0, 3)
+	gl.BindTexture(gl.TEXTURE_2D, r.scratch[1].id)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, r.scratch[0].fbo)
+	gl.Uniform2f(r.blurStep, 0, 1/float32(h))
+	gl.DrawArrays(gl.TRIANGLES, 0, 3)
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, uint32(previous))
+	gl.Viewport(vp[0], vp[1], vp[2], vp[3])
+	gl.Enable(gl.BLEND)
+	gl.UseProgram(r.program)
+	gl.BindVertexArray(r.vao)
+	return [4]float32{float32(x0), float32(y0), float32(w), float32(h)}, true
+}
+
+// target makes a texture one draw lists can be rendered into, allocating
+// its GPU storage at its size and the framebuffer rendering into it
+func (r *Renderer) target(t *Texture) {
+	if t.id == 0 {
+		gl.GenTextures(1, &t.id)
+		gl.BindTexture(gl.TEXTURE_2D, t.id)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+		t.glFilter = gl.LINEAR
+	} else {
+		gl.BindTexture(gl.TEXTURE_2D, t.id)
+	}
+	if t.glWidth != t.Width || t.glHeight != t.Height {
+		gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, int32(t.Width), int32(t.Height), 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+		t.glWidth, t.glHeight = t.Width, t.Height
+	}
+	// The pixels rendered are not in Pixels, which must not replace them
+	t.uploaded = t.version
+	if t.fbo == 0 {
+		var previous int32
+		gl.GetIntegerv(gl.FRAMEBUFFER_BINDING, &previous)
+		gl.GenFramebuffers(1, &t.fbo)
+		gl.BindFramebuffer(gl.FRAMEBUFFER, t.fbo)
+		gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, t.id, 0)
+		gl.BindFramebuffer(gl.FRAMEBUFFER, uint32(previous))
+	}
+}
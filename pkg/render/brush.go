@@ -0,0 +1,147 @@
+package render
+
+import (
+	"math"
+	"slices"
+)
+
+// Stop is a color at an offset along a gradient, from 0 at its start to 1 at its end
+type Stop struct {
+	Offset float32
+	Color  [4]float32
+}
+
+// brushKind distinguishes the ways a brush colors shapes
+type brushKind int
+
+const (
+	brushSolid brushKind = iota
+	brushLinear
+	brushRadial
+)
+
+// Brush colors the shapes it fills with a solid color or a linear or radial
+// gradient. Gradient positions are relative to the bounds of each shape,
+// (0, 0) at its top left and (1, 1) at its bottom right, so one brush fits
+// shapes of any size. The zero Brush is unset and paints nothing.
+type Brush struct {
+	kind  brushKind
+	set   bool
+	color [4]float32
+	stops []Stop
+	// from and to are the ends of a linear gradient, or the center and the
+	// radii of a radial one
+	from, to [2]float32
+}
+
+// Solid returns a brush painting a single color
+func Solid(color [4]float32) Brush {
+	return Brush{kind: brushSolid, set: true, color: color}
+}
+
+// LinearGradient returns a brush blending through the stops along the line
+// from (x0, y0) to (x1, y1). Beyond the ends of the line the brush paints
+// the color of the nearest stop.
+func LinearGradient(x0, y0, x1, y1 float32, stops ...Stop) Brush {
+	return Brush{
+		kind:  brushLinear,
+		set:   true,
+		stops: sortStops(stops),
+		from:  [2]float32{x0, y0},
+		to:    [2]float32{x1, y1},
+	}
+}
+
+// RadialGradient returns a brush blending through the stops outwards from
+// the center (cx, cy) to the radius. Like positions, the radius is relative
+// to the bounds of the shape, so the gradient is stretched into an ellipse in
+// shapes that are not square.
+func RadialGradient(cx, cy, radius float32, stops ...Stop) Brush {
+	return Brush{
+		kind:  brushRadial,
+		set:   true,
+		stops: sortStops(stops),
+		from:  [2]float32{cx, cy},
+		to:    [2]float32{radius, radius},
+	}
+}
+
+// IsSet reports whether the brush paints anything, false for the zero Brush
+func (b Brush) IsSet() bool {
+	return b.set
+}
+
+// IsSolid reports whether the brush paints a single color
+func (b Brush) IsSolid() bool {
+	return b.kind == brushSolid || len(b.stops) < 2
+}
+
+// Color returns the color of a solid brush, or of the first stop of a gradient
+func (b Brush) Color() [4]float32 {
+	if b.kind == brushSolid || len(b.stops) == 0 {
+		return b.color
+	}
+	return b.stops[0].Color
+}
+
+// At returns the color the brush paints at a position relative to the
+// bounds of a shape
+func (b Brush) At(x, y float32) [4]float32 {
+	switch {
+	case b.IsSolid():
+		return b.Color()
+	case b.kind == brushLinear:
+		dx, dy := b.to[0]-b.from[0], b.to[1]-b.from[1]
+		length := dx*dx + dy*dy
+		if length == 0 {
+			return b.Color()
+		}
+		// Project the position onto the gradient's line
+		return b.sample(((x-b.from[0])*dx + (y-b.from[1])*dy) / length)
+	default:
+		if b.to[0] == 0 || b.to[1] == 0 {
+			return b.stops[len(b.stops)-1].Color
+		}
+		dx, dy := (x-b.from[0])/b.to[0], (y-b.from[1])/b.to[1]
+		return b.sample(float32(math.Sqrt(float64(dx*dx + dy*dy))))
+	}
+}
+
+// sample returns the color at an offset along the gradient
+func (b Brush) sample(t float32) [4]float32 {
+	stops := b.stops
+	if t <= stops[0].Offset {
+		return stops[0].Color
+	}
+	for i := 1; i < len(stops); i++ {
+		s0, s1 := stops[i-1], stops[i]
+		if t > s1.Offset {
+			continue
+		}
+		f := float32(0)
+		if span := s1.Offset - s0.Offset; span > 0 {
+			f = (t - s0.Offset) / span
+		}
+		var c [4]float32
+		for k := range c {
+			c[k] = s0.Color[k] + (s1.Color[k]-s0.Color[k])*f
+		}
+		return c
+	}
+	return stops[len(stops)-1].Color
+}
+
+// sortStops returns a copy of the stops in order of offset
+func sortStops(stops []Stop) []Stop {
+	stops = slices.Clone(stops)
+	slices.SortStableFunc(stops, func(a, b Stop) int {
+		switch {
+		case a.Offset < b.Offset:
+			return -1
+		case a.Offset > b.Offset:
+			return 1
+		}
+		return 0
+	})
+	return stops
+}
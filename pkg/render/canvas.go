@@ -0,0 +1,289 @@
+package render
+
+import (
+	"image"
+	"math"
+)
+
+// Canvas is a software renderer that draws draw lists into an image in
+// memory, following the GL renderer closely enough that the same frame
+// looks the same. It needs no GL context, so widget trees can be drawn
+// without a window for tests, screenshots and thumbnails. Like the window's
+// offscreen canvas it keeps its contents between frames.
+type Canvas struct {
+	img *image.RGBA
+	// transparent is set for the canvases layers are rendered in, which
+	// start out transparent and keep the alpha drawn into them
+	transparent bool
+	// backdrop holds the blurred pixels the blur being drawn samples, copied
+	// from those at backdropAt
+	backdrop   *Texture
+	backdropAt image.Point
+}
+
+// NewCanvas creates a canvas of a size in pixels, filled with opaque black
+func NewCanvas(width, height int) *Canvas {
+	c := &Canvas{}
+	c.Resize(width, height)
+	return c
+}
+
+// Resize changes the size of the canvas in pixels. The previous contents are lost.
+func (c *Canvas) Resize(width, height int) {
+	c.img = image.NewRGBA(image.Rect(0, 0, max(width, 0), max(height, 0)))
+	for i := 3; i < len(c.img.Pix); i += 4 {
+		c.img.Pix[i] = 255
+	}
+}
+
+// Image returns the canvas contents, which are opaque. The image is drawn
+// into by later frames, so copy it to keep a frame.
+func (c *Canvas) Image() *image.RGBA {
+	return c.img
+}
+
+// Flush draws the list into the canvas and resets it. Width and height are
+// the logical size the list coordinates refer to, which is scaled to the
+// canvas size as on a high density display.
+func (c *Canvas) Flush(list *DrawList, width, height int) {
+	defer list.Reset()
+	b := c.img.Bounds()
+	if len(list.Commands) == 0 || width <= 0 || height <= 0 || b.Empty() {
+		return
+	}
+	c.draw(list, float32(width), float32(height))
+}
+
+// draw draws a list whose coordinates span width by height into the canvas,
+// after rendering the layers it samples
+func (c *Canvas) draw(list *DrawList, width, height float32) {
+	b := c.img.Bounds()
+	for i := range list.Commands {
+		if cmd := &list.Commands[i]; cmd.Layer != nil {
+			layer(cmd)
+		}
+	}
+	scaleX := float32(b.Dx()) / width
+	scaleY := float32(b.Dy()) / height
+	for i := range list.Commands {
+		cmd := &list.Commands[i]
+		if cmd.Layer != nil || cmd.GL != nil {
+			continue
+		}
+		// Scissor in pixels the way the GL renderer does, which counts rows
+		// from the bottom
+		scissor := b
+		if cmd.Clipped {
+			x := int(cmd.Clip[0] * scaleX)
+			y := int((height - cmd.Clip[1] - cmd.Clip[3]) * scaleY)
+			w, h := int(cmd.Clip[2]*scaleX), int(cmd.Clip[3]*scaleY)
+			scissor = image.Rect(x, b.Dy()-y-h, x+w, b.Dy()-y).Intersect(b)
+		}
+		if cmd.Clear {
+			c.clear(scissor, cmd.ClearColor)
+			continue
+		}
+		if cmd.Blur > 0 {
+			if c.backdrop, c.backdropAt = c.blur(cmd, scaleX, scaleY); c.backdrop == nil {
+				continue
+			}
+		}
+		for v := cmd.First; v+2 < cmd.First+cmd.Count; v += 3 {
+			c.triangle(cmd, list.Vertices[v:v+3], scissor, scaleX, scaleY)
+		}
+	}
+}
+
+// layer renders a command's layer into the pixels of its texture
+func layer(cmd *Command) {
+	defer cmd.Layer.Reset()
+	t := cmd.Texture
+	if t.Width <= 0 || t.Height <= 0 || cmd.LayerSize[0] <= 0 || cmd.LayerSize[1] <= 0 {
+		return
+	}
+	c := &Canvas{img: image.NewRGBA(image.Rect(0, 0, t.Width, t.Height)), transparent: true}
+	c.draw(cmd.Layer, cmd.LayerSize[0], cmd.LayerSize[1])
+	t.Format, t.Pixels = FormatRGBA, c.img.Pix
+	t.Invalidate()
+}
+
+// clear fills a rect of pixels with a color without blending
+func (c *Canvas) clear(r image.Rectangle, color [4]float32) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			c.set(x, y, color[0], color[1], color[2], color[3])
+		}
+	}
+}
+
+// triangle rasterizes a triangle, blending the pixels whose centers it covers
+func (c *Canvas) triangle(cmd *Command, v []Vertex, scissor image.Rectangle, scaleX, scaleY float32) {
+	a, b, d := &v[0], &v[1], &v[2]
+	ax, ay := a.X*scaleX, a.Y*scaleY
+	bx, by := b.X*scaleX, b.Y*scaleY
+	dx, dy := d.X*scaleX, d.Y*scaleY
+	area := edge(ax, ay, bx, by, dx, dy)
+	if area == 0 {
+		return
+	}
+	if area < 0 {
+		// Wind every triangle the same way so the fill rule is consistent
+		b, d = d, b
+		bx, by, dx, dy = dx, dy, bx, by
+		area = -area
+	}
+	r := image.Rect(
+		int(math.Floor(float64(min(ax, bx, dx)))), int(math.Floor(float64(min(ay, by, dy)))),
+		int(math.Ceil(float64(max(ax, bx, dx)))), int(math.Ceil(float64(max(ay, by, dy)))),
+	).Intersect(scissor)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		py := float32(y) + 0.5
+		for x := r.Min.X; x < r.Max.X; x++ {
+			px := float32(x) + 0.5
+			w0 := edge(bx, by, dx, dy, px, py)
+			w1 := edge(dx, dy, ax, ay, px, py)
+			w2 := edge(ax, ay, bx, by, px, py)
+			if !inside(w0, dx-bx, dy-by) || !inside(w1, ax-dx, ay-dy) || !inside(w2, bx-ax, by-ay) {
+				continue
+			}
+			w0, w1, w2 = w0/area, w1/area, w2/area
+			color := [4]float32{
+				a.R*w0 + b.R*w1 + d.R*w2,
+				a.G*w0 + b.G*w1 + d.G*w2,
+				a.B*w0 + b.B*w1 + d.B*w2,
+				a.A*w0 + b.A*w1 + d.A*w2,
+			}
+			// tint is the vertex alpha, which premultiplied colors are faded by
+			tint, premultiplied := color[3], false
+			if cmd.Blur > 0 {
+				// Blurs sample the pixels where they were copied from
+				s := texel(c.backdrop, x-c.backdropAt.X, y-c.backdropAt.Y)
+				for i := range color {
+					color[i] *= s[i]
+				}
+				premultiplied = true
+			} else if t := cmd.Texture; t != nil {
+				s := sample(t, a.U*w0+b.U*w1+d.U*w2, a.V*w0+b.V*w1+d.V*w2)
+				for i := range color {
+					color[i] *= s[i]
+				}
+				premultiplied = t.Premultiplied
+			}
+			cover := float32(1)
+			if cmd.Masked {
+				cover = coverage(cmd, px/scaleX, py/scaleY)
+			}
+			if premultiplied {
+				for i := range 3 {
+					color[i] *= tint * cover
+				}
+			}
+			color[3] *= cover
+			c.blend(x, y, color, premultiplied)
+		}
+	}
+}
+
+// edge returns twice the signed area of the triangle (x0, y0), (x1, y1),
+// (x, y), positive when the point is to the left of the edge
+func edge(x0, y0, x1, y1, x, y float32) float32 {
+	return (x1-x0)*(y-y0) - (y1-y0)*(x-x0)
+}
+
+// inside reports whether a pixel center with edge function w is inside an
+// edge running along (dx, dy). Centers exactly on the edge belong to only one
+// of the two triangles sharing it, so they are not blended twice.
+func inside(w, dx, dy float32) bool {
+	return w > 0 || w == 0 && (dy > 0 || dy == 0 && dx < 0)
+}
+
+// coverage returns how much of a pixel at a window position lies inside the
+// command's rounded mask, as the GL renderer's fragment shader computes it
+func coverage(cmd *Command, x, y float32) float32 {
+	m, radius := cmd.Mask, cmd.MaskRadius
+	if m[2] <= 0 {
+		return 1
+	}
+	hx, hy := m[2]/2, m[3]/2
+	px, py := abs(x-m[0]-hx), abs(y-m[1]-hy)
+	qx, qy := px-(hx-radius[0]), py-(hy-radius[1])
+	if qx <= 0 || qy <= 0 || radius[0] <= 0 || radius[1] <= 0 {
+		// Beside the corners the distance is to the nearest straight edge
+		return clamp01(0.5 - max(px-hx, py-hy))
+	}
+	kx, ky := qx/radius[0], qy/radius[1]
+	k := float32(math.Hypot(float64(kx), float64(ky)))
+	g := float32(math.Hypot(float64(kx/radius[0]), float64(ky/radius[1])))
+	return clamp01(0.5 - (k-1)/g*k)
+}
+
+// sample reads a texture at a texture coordinate, clamped to its edges and
+// filtered as the texture asks. Coverage textures read as white.
+func sample(t *Texture, u, v float32) [4]float32 {
+	if t.Width <= 0 || t.Height <= 0 || len(t.Pixels) < t.Width*t.Height*t.BytesPerPixel() {
+		return [4]float32{}
+	}
+	x, y := u*float32(t.Width)-0.5, v*float32(t.Height)-0.5
+	if t.Filter == FilterNearest {
+		return texel(t, int(math.Floor(float64(x+0.5))), int(math.Floor(float64(y+0.5))))
+	}
+	x0, y0 := float32(math.Floor(float64(x))), float32(math.Floor(float64(y)))
+	fx, fy := x-x0, y-y0
+	i, j := int(x0), int(y0)
+	p00, p10 := texel(t, i, j), texel(t, i+1, j)
+	p01, p11 := texel(t, i, j+1), texel(t, i+1, j+1)
+	var s [4]float32
+	for k := range s {
+		top := p00[k] + (p10[k]-p00[k])*fx
+		bottom := p01[k] + (p11[k]-p01[k])*fx
+		s[k] = top + (bottom-top)*fy
+	}
+	return s
+}
+
+// texel returns the color of a texture pixel, clamping the coordinates to the texture
+func texel(t *Texture, x, y int) [4]float32 {
+	x = min(max(x, 0), t.Width-1)
+	y = min(max(y, 0), t.Height-1)
+	if t.Format == FormatAlpha {
+		return [4]float32{1, 1, 1, float32(t.Pixels[y*t.Width+x]) / 255}
+	}
+	p := t.Pixels[(y*t.Width+x)*4:]
+	return [4]float32{float32(p[0]) / 255, float32(p[1]) / 255, float32(p[2]) / 255, float32(p[3]) / 255}
+}
+
+// blend draws a color over a pixel with its alpha, accumulating the alpha
+// as the GL renderer does. Premultiplied colors are already faded by it.
+func (c *Canvas) blend(x, y int, color [4]float32, premultiplied bool) {
+	a := clamp01(color[3])
+	fade := a
+	if premultiplied {
+		fade = 1
+	}
+	p := c.img.Pix[c.img.PixOffset(x, y):]
+	c.set(x, y,
+		clamp01(color[0])*fade+float32(p[0])/255*(1-a),
+		clamp01(color[1])*fade+float32(p[1])/255*(1-a),
+		clamp01(color[2])*fade+float32(p[2])/255*(1-a),
+		a+float32(p[3])/255*(1-a),
+	)
+}
+
+// set stores a color in a pixel, opaque unless the canvas is transparent
+func (c *Canvas) set(x, y int, r, g, b, a float32) {
+	if !c.transparent {
+		a = 1
+	}
+	p := c.img.Pix[c.img.PixOffset(x, y):]
+	p[0], p[1], p[2], p[3] = channel(r), channel(g), channel(b), channel(a)
+}
+
+// channel converts a color channel to a byte, rounding to nearest as GL does
+func channel(v float32) uint8 {
+	return uint8(clamp01(v)*255 + 0.5)
+}
+
+// clamp01 limits a value to the range 0 to 1
+func clamp01(v float32) float32 {
+	return min(max(v, 0), 1)
+}
@@ -0,0 +1,312 @@
+package render
+
+// Vertex is a single vertex of a draw list, in window coordinates (0,0 = top-left)
+type Vertex struct {
+	X, Y       float32
+	U, V       float32
+	R, G, B, A float32
+}
+
+// Command draws a run of triangles sharing one texture, clip rect and mask,
+// clears the clip rect when Clear is set, or renders a layer into its
+// texture when Layer is set
+type Command struct {
+	// Texture sampled by the vertices, nil for solid colors
+	Texture *Texture
+	// Clip is the scissor rect (x, y, width, height), applied when Clipped is set
+	Clip    [4]float32
+	Clipped bool
+	// Mask confines drawing to a rect (x, y, width, height) with corners
+	// rounded to the horizontal and vertical radii in MaskRadius, applied
+	// when Masked is set. Radii of half the rect's size make it an ellipse.
+	Mask       [4]float32
+	MaskRadius [2]float32
+	Masked     bool
+	// Clear fills the clip rect with ClearColor, replacing what was there
+	Clear      bool
+	ClearColor [4]float32
+	// Layer is a draw list rendered into Texture, cleared to transparent,
+	// before the commands after it sample the texture. Its coordinates span
+	// LayerSize, scaled to the texture's size in pixels.
+	Layer     *DrawList
+	LayerSize [2]float32
+	// Shader, when set, shades the triangles given the Uniforms in place of
+	// the texture's color
+	Shader   *Shader
+	Uniforms []Uniform
+	// Blur, when above 0, fills the triangles with the pixels drawn below
+	// them blurred by a gaussian of that standard deviation, sampling those
+	// in the rect Backdrop (x, y, width, height)
+	Blur     float32
+	Backdrop [4]float32
+	// GL is called to render into Texture with OpenGL, through a
+	// framebuffer with a depth buffer, before the commands after it sample
+	// the texture
+	GL func(width, height int)
+	// First and Count select the command's vertices in the list
+	First, Count int
+}
+
+// DrawList records the geometry painted during a frame so it can be
+// submitted to the GPU in a few batched draw calls
+type DrawList struct {
+	Vertices []Vertex
+	Commands []Command
+	// clips is the stack of active clip rects, each already intersected
+	// with the one below it
+	clips [][4]float32
+	// masks holds the rounded mask active with each clip rect
+	masks []mask
+	// alphas is the stack of opacities, each already multiplied by the one
+	// below it
+	alphas []float32
+	// transforms is the stack of transforms applied to the vertices added
+	// under each
+	transforms []transform
+	// path and normals hold the outline of the shape being drawn, reused
+	// between shapes
+	path, normals [][2]float32
+	// corners and triangles hold the indices into path used to cut a
+	// concave shape into triangles
+	corners, triangles []int
+}
+
+// NewDrawList creates an empty draw list
+func NewDrawList() *DrawList {
+	return &DrawList{}
+}
+
+// Reset empties the list so it can be reused for the next frame
+func (d *DrawList) Reset() {
+	d.Vertices = d.Vertices[:0]
+	d.Commands = d.Commands[:0]
+	d.clips = d.clips[:0]
+	d.masks = d.masks[:0]
+	d.alphas = d.alphas[:0]
+	d.transforms = d.transforms[:0]
+}
+
+// PushAlpha multiplies the opacity of everything drawn until the matching
+// PopAlpha by alpha
+func (d *DrawList) PushAlpha(alpha float32) {
+	if n := len(d.alphas); n > 0 {
+		alpha *= d.alphas[n-1]
+	}
+	d.alphas = append(d.alphas, alpha)
+}
+
+// PopAlpha restores the opacity that was active before the last PushAlpha
+func (d *DrawList) PopAlpha() {
+	if n := len(d.alphas); n > 0 {
+		d.alphas = d.alphas[:n-1]
+	}
+}
+
+// PushClip restricts drawing to a rect intersected with the current clip
+func (d *DrawList) PushClip(x, y, width, height float32) {
+	clip := [4]float32{x, y, width, height}
+	if m := d.Transform(); !m.IsIdentity() {
+		clip = m.Bounds(clip)
+	}
+	if n := len(d.clips); n > 0 {
+		clip = intersect(d.clips[n-1], clip)
+	}
+	d.clips = append(d.clips, clip)
+	// Rounded masks stay in force inside rect clips
+	var m mask
+	if n := len(d.masks); n > 0 {
+		m = d.masks[n-1]
+	}
+	d.masks = append(d.masks, m)
+}
+
+// PushRoundClip restricts drawing to a rect with corners rounded to radius,
+// intersected with the current clip rect, with anti-aliased edges. Only the
+// innermost rounded clip is applied; drawing inside nested rounded clips is
+// kept within the bounding rects of the outer ones but not their corners.
+func (d *DrawList) PushRoundClip(x, y, width, height, radius float32) {
+	radius = min(radius, width/2, height/2)
+	d.PushClip(x, y, width, height)
+	if radius > 0 {
+		d.masks[len(d.masks)-1] = d.mask(x, y, width, height, radius, radius)
+	}
+}
+
+// PushEllipseClip restricts drawing to the ellipse touching the edges of a
+// rect, intersected with the current clip rect, like PushRoundClip
+func (d *DrawList) PushEllipseClip(x, y, width, height float32) {
+	d.PushClip(x, y, width, height)
+	if width > 0 && height > 0 {
+		d.masks[len(d.masks)-1] = d.mask(x, y, width, height, width/2, height/2)
+	}
+}
+
+// PopClip restores the clip that was active before the last PushClip,
+// PushRoundClip or PushEllipseClip
+func (d *DrawList) PopClip() {
+	if n := len(d.clips); n > 0 {
+		d.clips = d.clips[:n-1]
+		d.masks = d.masks[:n-1]
+	}
+}
+
+// ClipRect returns the current clip rect and whether one is active
+func (d *DrawList) ClipRect() (clip [4]float32, clipped bool) {
+	if n := len(d.clips); n > 0 {
+		return d.clips[n-1], true
+	}
+	return
+}
+
+// mask is a rounded rect confining drawing, in force when set
+type mask struct {
+	rect   [4]float32
+	radius [2]float32
+	set    bool
+}
+
+// mask returns a mask of a rect with corners of radii rx and ry, in window
+// coordinates. Under a transform the mask covers the transformed bounding
+// rect, with its corners scaled to match.
+func (d *DrawList) mask(x, y, width, height, rx, ry float32) mask {
+	rect := [4]float32{x, y, width, height}
+	if m := d.Transform(); !m.IsIdentity() {
+		rect = m.Bounds(rect)
+		rx *= rect[2] / width
+		ry *= rect[3] / height
+	}
+	return mask{rect: rect, radius: [2]float32{rx, ry}, set: true}
+}
+
+// currentMask returns the rounded mask in force
+func (d *DrawList) currentMask() mask {
+	if n := len(d.masks); n > 0 {
+		return d.masks[n-1]
+	}
+	return mask{}
+}
+
+// Clear fills the current clip rect, or the whole target when unclipped,
+// with a color without blending
+func (d *DrawList) Clear(color [4]float32) {
+	clip, clipped := d.ClipRect()
+	d.Commands = append(d.Commands, Command{
+		Clip:       clip,
+		Clipped:    clipped,
+		Clear:      true,
+		ClearColor: color,
+		First:      len(d.Vertices),
+	})
+}
+
+// RenderTexture renders a draw list into a texture, replacing what it held,
+// before anything drawn after it samples the texture. The list's
+// coordinates span width by height, scaled to the texture's size in pixels
+// as on a high density display, and it is reset once rendered. The texture
+// is marked premultiplied, as the colors of its translucent pixels are
+// multiplied by their alpha.
+func (d *DrawList) RenderTexture(t *Texture, list *DrawList, width, height float32) {
+	t.Premultiplied = true
+	d.Commands = append(d.Commands, Command{
+		Texture:   t,
+		Layer:     list,
+		LayerSize: [2]float32{width, height},
+		First:     len(d.Vertices),
+	})
+}
+
+// Rect adds a solid colored rectangle
+func (d *DrawList) Rect(x, y, width, height float32, color [4]float32) {
+	d.Image(nil, x, y, width, height, 0, 0, 1, 1, color)
+}
+
+// Image adds a rectangle textured with the (u0, v0)-(u1, v1) region of the
+// texture, tinted by color. A nil texture draws a solid color.
+func (d *DrawList) Image(texture *Texture, x, y, width, height, u0, v0, u1, v1 float32, color [4]float32) {
+	if width <= 0 || height <= 0 || d.clippedOut(x, y, width, height) {
+		return
+	}
+	d.command(texture, 6)
+	color = d.fade(color)
+	x1, y1 := x+width, y+height
+	d.Vertices = append(d.Vertices,
+		vertex(x, y, u0, v0, color),
+		vertex(x1, y, u1, v0, color),
+		vertex(x1, y1, u1, v1, color),
+		vertex(x, y, u0, v0, color),
+		vertex(x1, y1, u1, v1, color),
+		vertex(x, y1, u0, v1, color),
+	)
+}
+
+// Triangle adds a solid colored triangle
+func (d *DrawList) Triangle(x0, y0, x1, y1, x2, y2 float32, color [4]float32) {
+	d.command(nil, 3)
+	color = d.fade(color)
+	d.Vertices = append(d.Vertices,
+		vertex(x0, y0, 0, 0, color),
+		vertex(x1, y1, 0, 0, color),
+		vertex(x2, y2, 0, 0, color),
+	)
+}
+
+// command extends the last command with count vertices when it shares the
+// texture, clip and mask, otherwise it starts a new one
+func (d *DrawList) command(texture *Texture, count int) {
+	clip, clipped := d.ClipRect()
+	m := d.currentMask()
+	if n := len(d.Commands); n > 0 {
+		last := &d.Commands[n-1]
+		if !last.Clear && last.Layer == nil && last.GL == nil && last.Shader == nil && last.Blur == 0 && last.Texture == texture && last.Clipped == clipped && last.Clip == clip &&
+			last.Masked == m.set && last.Mask == m.rect && last.MaskRadius == m.radius {
+			last.Count += count
+			return
+		}
+	}
+	d.Commands = append(d.Commands, Command{
+		Texture:    texture,
+		Clip:       clip,
+		Clipped:    clipped,
+		Mask:       m.rect,
+		MaskRadius: m.radius,
+		Masked:     m.set,
+		First:      len(d.Vertices),
+		Count:      count,
+	})
+}
+
+// clippedOut reports whether a rect lies entirely outside the current clip
+func (d *DrawList) clippedOut(x, y, width, height float32) bool {
+	clip, clipped := d.ClipRect()
+	if !clipped {
+		return false
+	}
+	rect := [4]float32{x, y, width, height}
+	if m := d.Transform(); !m.IsIdentity() {
+		rect = m.Bounds(rect)
+	}
+	r := intersect(clip, rect)
+	return r[2] <= 0 || r[3] <= 0
+}
+
+// fade applies the current opacity to a color
+func (d *DrawList) fade(color [4]float32) [4]float32 {
+	if n := len(d.alphas); n > 0 {
+		color[3] *= d.alphas[n-1]
+	}
+	return color
+}
+
+func vertex(x, y, u, v float32, c [4]float32) Vertex {
+	return Vertex{X: x, Y: y, U: u, V: v, R: c[0], G: c[1], B: c[2], A: c[3]}
+}
+
+// intersect returns the overlap of two (x, y, width, height) rects
+func intersect(a, b [4]float32) [4]float32 {
+	x0, y0 := max(a[0], b[0]), max(a[1], b[1])
+	x1, y1 := min(a[0]+a[2], b[0]+b[2]), min(a[1]+a[3], b[1]+b[3])
+	if x1 <= x0 || y1 <= y0 {
+		return [4]float32{x0, y0, 0, 0}
+	}
+	return [4]float32{x0, y0, x1 - x0, y1 - y0}
+}
@@ -0,0 +1,14 @@
+package render
+
+import (
+	"errors"
+)
+
+var (
+	// errShaderCompile is returned when a shader fails to compile
+	errShaderCompile = errors.New("shader compilation failed")
+	// errProgramLink is returned when the shader program fails to link
+	errProgramLink = errors.New("shader program link failed")
+	// errNinePatch is returned for a nine-patch image without stretch marks
+	errNinePatch = errors.New("nine-patch image has no stretch marks")
+)
@@ -0,0 +1,24 @@
+package render
+
+// RenderGL calls fn to render into a texture with OpenGL before anything
+// drawn after it samples the texture, for 3D scenes and games shown among
+// widgets. fn is called on the thread drawing the frame, with the window's
+// GL context current, a framebuffer of the texture's size in pixels bound
+// with a depth and stencil buffer, the viewport covering it, and color,
+// depth and stencil cleared to transparent, 1 and 0. Its rows count from
+// the bottom as GL's do, so the texture is drawn with its v coordinates
+// flipped. It may change any GL state, which is restored after, but must
+// not delete the framebuffer bound. The texture is marked premultiplied, as
+// colors blended into it are. The software canvas cannot run GL and leaves
+// the texture empty.
+func (d *DrawList) RenderGL(t *Texture, fn func(width, height int)) {
+	if fn == nil || t.Width <= 0 || t.Height <= 0 {
+		return
+	}
+	t.Premultiplied = true
+	d.Commands = append(d.Commands, Command{
+		Texture: t,
+		GL:      fn,
+		First:   len(d.Vertices),
+	})
+}
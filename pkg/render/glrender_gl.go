@@ -0,0 +1,54 @@
+//go:build !js
+
+package render
+
+import (
+	"github.com/go-gl/gl/all-core/gl"
+)
+
+// renderGL calls a command's GL function with the framebuffer of its
+// texture bound, then restores the state the renderer relies on
+func (r *Renderer) renderGL(cmd *Command) {
+	t := cmd.Texture
+	resized := t.glWidth != t.Width || t.glHeight != t.Height
+	r.target(t)
+	if t.depth == 0 {
+		gl.GenRenderbuffers(1, &t.depth)
+		resized = true
+	}
+	var previous int32
+	var vp [4]int32
+	gl.GetIntegerv(gl.FRAMEBUFFER_BINDING, &previous)
+	gl.GetIntegerv(gl.VIEWPORT, &vp[0])
+	gl.BindFramebuffer(gl.FRAMEBUFFER, t.fbo)
+	if resized {
+		gl.BindRenderbuffer(gl.RENDERBUFFER, t.depth)
+		gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH24_STENCIL8, int32(t.Width), int32(t.Height))
+		gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_STENCIL_ATTACHMENT, gl.RENDERBUFFER, t.depth)
+		gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
+	}
+	gl.Viewport(0, 0, int32(t.Width), int32(t.Height))
+	gl.Disable(gl.SCISSOR_TEST)
+	gl.ClearColor(0, 0, 0, 0)
+	gl.ClearDepth(1)
+	gl.ClearStencil(0)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT | gl.STENCIL_BUFFER_BIT)
+
+	cmd.GL(t.Width, t.Height)
+
+	// Put back what user code commonly changes and the renderer assumes
+	gl.BindFramebuffer(gl.FRAMEBUFFER, uint32(previous))
+	gl.Viewport(vp[0], vp[1], vp[2], vp[3])
+	gl.Disable(gl.DEPTH_TEST)
+	gl.Disable(gl.STENCIL_TEST)
+	gl.Disable(gl.CULL_FACE)
+	gl.Disable(gl.SCISSOR_TEST)
+	gl.Enable(gl.BLEND)
+	gl.BlendEquation(gl.FUNC_ADD)
+	gl.ColorMask(true, true, true, true)
+	gl.DepthMask(true)
+	gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindVertexArray(0)
+	gl.UseProgram(r.program)
+}
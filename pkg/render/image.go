@@ -0,0 +1,60 @@
+package render
+
+import (
+	"image"
+	"image/draw"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"sync"
+
+	"lol.mleku.dev/chk"
+)
+
+// fileTextures caches textures loaded by LoadTexture by file path
+var fileTextures = struct {
+	sync.Mutex
+	textures map[string]*Texture
+}{textures: make(map[string]*Texture)}
+
+// TextureFromImage converts an image to an RGBA texture
+func TextureFromImage(img image.Image) *Texture {
+	b := img.Bounds()
+	if n, ok := img.(*image.NRGBA); ok && n.Rect.Min == (image.Point{}) && n.Stride == 4*b.Dx() {
+		// Already in the texture layout
+		return NewTexture(b.Dx(), b.Dy(), FormatRGBA, n.Pix)
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return NewTexture(b.Dx(), b.Dy(), FormatRGBA, dst.Pix)
+}
+
+// LoadTexture decodes a PNG or JPEG file into a texture. Textures are cached
+// by path, so loading the same file again returns the same texture.
+func LoadTexture(path string) (t *Texture, err error) {
+	fileTextures.Lock()
+	defer fileTextures.Unlock()
+	if t = fileTextures.textures[path]; t != nil {
+		return
+	}
+	var img image.Image
+	if img, err = decodeFile(path); chk.E(err) {
+		return
+	}
+	t = TextureFromImage(img)
+	fileTextures.textures[path] = t
+	return
+}
+
+// decodeFile decodes a PNG or JPEG file
+func decodeFile(path string) (img image.Image, err error) {
+	var f *os.File
+	if f, err = os.Open(path); chk.E(err) {
+		return
+	}
+	defer f.Close()
+	if img, _, err = image.Decode(f); chk.E(err) {
+		return
+	}
+	return
+}
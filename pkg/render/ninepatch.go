@@ -0,0 +1,142 @@
+package render
+
+import (
+	"image"
+	"image/draw"
+	"sync"
+
+	"lol.mleku.dev/chk"
+)
+
+// fileNinePatches caches nine-patches loaded by LoadNinePatch by file path
+var fileNinePatches = struct {
+	sync.Mutex
+	patches map[string]*NinePatch
+}{patches: make(map[string]*NinePatch)}
+
+// NinePatch is an image cut into a three by three grid so it can be drawn at
+// any size without distorting its corners, for skinning buttons and panels.
+// The corners are drawn at their own size, the edges stretch along their
+// length and the middle stretches both ways.
+type NinePatch struct {
+	Texture *Texture
+	// Left, Top, Right and Bottom are the sizes in texture pixels of the
+	// borders around the middle
+	Left, Top, Right, Bottom float32
+	// Padding is the inset (left, top, right, bottom) of the area content
+	// is laid out in, which may differ from the borders
+	Padding [4]float32
+}
+
+// NewNinePatch creates a nine-patch from a texture and the sizes of its
+// borders, which are also the padding around content
+func NewNinePatch(texture *Texture, left, top, right, bottom float32) *NinePatch {
+	return &NinePatch{
+		Texture: texture,
+		Left:    left,
+		Top:     top,
+		Right:   right,
+		Bottom:  bottom,
+		Padding: [4]float32{left, top, right, bottom},
+	}
+}
+
+// NinePatchFromImage creates a nine-patch from an image marked up the way of
+// Android's .9.png files: a one pixel frame around the image whose opaque
+// black pixels along the top and left mark the parts that stretch, and along
+// the right and bottom the area of the content. Without right and bottom
+// marks the content area is the stretched middle.
+func NinePatchFromImage(img image.Image) (p *NinePatch, err error) {
+	b := img.Bounds()
+	if b.Dx() < 3 || b.Dy() < 3 {
+		return nil, errNinePatch
+	}
+	marked := func(x, y int) bool {
+		r, g, bl, a := img.At(x, y).RGBA()
+		return a == 0xffff && r == 0 && g == 0 && bl == 0
+	}
+	// span returns the first and one past the last marked pixel along a side
+	// of the frame, counted from the inside of the frame
+	span := func(n int, at func(i int) (x, y int)) (start, end int, ok bool) {
+		start = -1
+		for i := 1; i < n-1; i++ {
+			if marked(at(i)) {
+				if start < 0 {
+					start = i - 1
+				}
+				end = i
+			}
+		}
+		return start, end, start >= 0
+	}
+	w, h := b.Dx(), b.Dy()
+	left, right, ok := span(w, func(i int) (int, int) { return b.Min.X + i, b.Min.Y })
+	if !ok {
+		return nil, errNinePatch
+	}
+	top, bottom, ok := span(h, func(i int) (int, int) { return b.Min.X, b.Min.Y + i })
+	if !ok {
+		return nil, errNinePatch
+	}
+	inner := image.Rect(b.Min.X+1, b.Min.Y+1, b.Max.X-1, b.Max.Y-1)
+	sub := image.NewNRGBA(image.Rect(0, 0, inner.Dx(), inner.Dy()))
+	draw.Draw(sub, sub.Bounds(), img, inner.Min, draw.Src)
+	iw, ih := float32(inner.Dx()), float32(inner.Dy())
+	p = NewNinePatch(TextureFromImage(sub), float32(left), float32(top), iw-float32(right), ih-float32(bottom))
+	if start, end, ok := span(w, func(i int) (int, int) { return b.Min.X + i, b.Max.Y - 1 }); ok {
+		p.Padding[0], p.Padding[2] = float32(start), iw-float32(end)
+	}
+	if start, end, ok := span(h, func(i int) (int, int) { return b.Max.X - 1, b.Min.Y + i }); ok {
+		p.Padding[1], p.Padding[3] = float32(start), ih-float32(end)
+	}
+	return
+}
+
+// LoadNinePatch decodes a PNG file marked up like Android's .9.png files
+// into a nine-patch. Nine-patches are cached by path, so loading the same
+// file again returns the same nine-patch.
+func LoadNinePatch(path string) (p *NinePatch, err error) {
+	fileNinePatches.Lock()
+	defer fileNinePatches.Unlock()
+	if p = fileNinePatches.patches[path]; p != nil {
+		return
+	}
+	var img image.Image
+	if img, err = decodeFile(path); chk.E(err) {
+		return
+	}
+	if p, err = NinePatchFromImage(img); chk.E(err) {
+		return
+	}
+	fileNinePatches.patches[path] = p
+	return
+}
+
+// NinePatch adds a nine-patch stretched over a rect, its pixels multiplied
+// by the tint color. Rects smaller than the borders shrink the borders in
+// proportion.
+func (d *DrawList) NinePatch(p *NinePatch, x, y, width, height float32, tint [4]float32) {
+	t := p.Texture
+	if t == nil || t.Width == 0 || t.Height == 0 || width <= 0 || height <= 0 {
+		return
+	}
+	tw, th := float32(t.Width), float32(t.Height)
+	// Borders in the rect, squeezed when they do not fit
+	l, r, tp, b := p.Left, p.Right, p.Top, p.Bottom
+	if s := width / (l + r); s < 1 {
+		l, r = l*s, r*s
+	}
+	if s := height / (tp + b); s < 1 {
+		tp, b = tp*s, b*s
+	}
+	xs := [4]float32{x, x + l, x + width - r, x + width}
+	ys := [4]float32{y, y + tp, y + height - b, y + height}
+	us := [4]float32{0, p.Left / tw, 1 - p.Right/tw, 1}
+	vs := [4]float32{0, p.Top / th, 1 - p.Bottom/th, 1}
+	for row := range 3 {
+		for col := range 3 {
+			d.Image(t, xs[col], ys[row], xs[col+1]-xs[col], ys[row+1]-ys[row],
+				us[col], vs[row], us[col+1], vs[row+1], tint)
+		}
+	}
+}
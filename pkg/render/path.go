@@ -0,0 +1,349 @@
+package render
+
+import (
+	"math"
+)
+
+// flatness is the furthest, in pixels, the segments curves are drawn with
+// stray from the true curve
+const flatness = 0.25
+
+// pathVerb is a step of a path's outline
+type pathVerb int
+
+const (
+	verbMove pathVerb = iota
+	verbLine
+	verbQuad
+	verbCubic
+	verbClose
+)
+
+// Path is an outline of straight lines and Bézier curves, made of one or
+// more subpaths each started by MoveTo. Drawing starts a subpath at the
+// origin when none was started. Paths are flattened to line segments when
+// drawn, finely enough for the scale they are drawn at, and keep the
+// segments until the path changes or is drawn at another scale.
+type Path struct {
+	verbs  []pathVerb
+	points [][2]float32
+	// start is the first point of the current subpath, where Close returns to
+	start [2]float32
+	open  bool
+	// flat holds the subpaths flattened at tolerance
+	flat      []flatPath
+	tolerance float32
+}
+
+// flatPath is a subpath flattened to points
+type flatPath struct {
+	points [][2]float32
+	closed bool
+}
+
+// NewPath creates an empty path
+func NewPath() *Path {
+	return &Path{}
+}
+
+// Reset empties the path so it can be built again, keeping its storage
+func (p *Path) Reset() *Path {
+	p.verbs = p.verbs[:0]
+	p.points = p.points[:0]
+	p.open = false
+	p.changed()
+	return p
+}
+
+// MoveTo starts a new subpath at a point and returns the path for chaining
+func (p *Path) MoveTo(x, y float32) *Path {
+	p.verbs = append(p.verbs, verbMove)
+	p.points = append(p.points, [2]float32{x, y})
+	p.start, p.open = [2]float32{x, y}, true
+	p.changed()
+	return p
+}
+
+// LineTo adds a straight line to a point and returns the path for chaining
+func (p *Path) LineTo(x, y float32) *Path {
+	p.ensureOpen()
+	p.verbs = append(p.verbs, verbLine)
+	p.points = append(p.points, [2]float32{x, y})
+	p.changed()
+	return p
+}
+
+// QuadTo adds a quadratic Bézier curve bending towards a control point on
+// its way to a point, and returns the path for chaining
+func (p *Path) QuadTo(cx, cy, x, y float32) *Path {
+	p.ensureOpen()
+	p.verbs = append(p.verbs, verbQuad)
+	p.points = append(p.points, [2]float32{cx, cy}, [2]float32{x, y})
+	p.changed()
+	return p
+}
+
+// CubicTo adds a cubic Bézier curve leaving towards the first control point
+// and arriving from the second at a point, and returns the path for chaining
+func (p *Path) CubicTo(c1x, c1y, c2x, c2y, x, y float32) *Path {
+	p.ensureOpen()
+	p.verbs = append(p.verbs, verbCubic)
+	p.points = append(p.points, [2]float32{c1x, c1y}, [2]float32{c2x, c2y}, [2]float32{x, y})
+	p.changed()
+	return p
+}
+
+// Arc adds an arc of the ellipse with the given center and radii from the
+// start angle to the end angle, in radians clockwise from the positive x
+// axis, and returns the path for chaining. A line joins the current point
+// to the start of the arc, which starts the subpath when there is none.
+func (p *Path) Arc(cx, cy, rx, ry, start, end float32) *Path {
+	point := func(angle float64) (float32, float32) {
+		return cx + rx*float32(math.Cos(angle)), cy + ry*float32(math.Sin(angle))
+	}
+	x, y := point(float64(start))
+	if p.open {
+		p.LineTo(x, y)
+	} else {
+		p.MoveTo(x, y)
+	}
+	sweep := float64(end - start)
+	// Each cubic spans at most a quarter turn, where it stays within a
+	// fraction of a pixel of the ellipse
+	n := max(int(math.Ceil(math.Abs(sweep)/(math.Pi/2))), 1)
+	step := sweep / float64(n)
+	k := float32(4.0 / 3.0 * math.Tan(step/4))
+	a := float64(start)
+	for range n {
+		b := a + step
+		cosA, sinA := float32(math.Cos(a)), float32(math.Sin(a))
+		cosB, sinB := float32(math.Cos(b)), float32(math.Sin(b))
+		x1, y1 := point(a)
+		x2, y2 := point(b)
+		p.CubicTo(
+			x1-k*rx*sinA, y1+k*ry*cosA,
+			x2+k*rx*sinB, y2-k*ry*cosB,
+			x2, y2,
+		)
+		a = b
+	}
+	return p
+}
+
+// Close joins the current subpath back to its start and returns the path
+// for chaining. The next line or curve starts a new subpath there.
+func (p *Path) Close() *Path {
+	if !p.open {
+		return p
+	}
+	p.verbs = append(p.verbs, verbClose)
+	p.open = false
+	p.changed()
+	return p
+}
+
+// Rect adds a closed rectangle and returns the path for chaining
+func (p *Path) Rect(x, y, width, height float32) *Path {
+	return p.MoveTo(x, y).LineTo(x+width, y).LineTo(x+width, y+height).LineTo(x, y+height).Close()
+}
+
+// RoundRect adds a closed rectangle with corners rounded to radius and
+// returns the path for chaining
+func (p *Path) RoundRect(x, y, width, height, radius float32) *Path {
+	r := min(radius, width/2, height/2)
+	if r <= 0 {
+		return p.Rect(x, y, width, height)
+	}
+	p.MoveTo(x+r, y)
+	p.Arc(x+width-r, y+r, r, r, -math.Pi/2, 0)
+	p.Arc(x+width-r, y+height-r, r, r, 0, math.Pi/2)
+	p.Arc(x+r, y+height-r, r, r, math.Pi/2, math.Pi)
+	p.Arc(x+r, y+r, r, r, math.Pi, 3*math.Pi/2)
+	return p.Close()
+}
+
+// Ellipse adds a closed ellipse with the given center and radii and returns
+// the path for chaining
+func (p *Path) Ellipse(cx, cy, rx, ry float32) *Path {
+	p.Close()
+	p.MoveTo(cx+rx, cy)
+	p.Arc(cx, cy, rx, ry, 0, 2*math.Pi)
+	return p.Close()
+}
+
+// Circle adds a closed circle and returns the path for chaining
+func (p *Path) Circle(cx, cy, radius float32) *Path {
+	return p.Ellipse(cx, cy, radius, radius)
+}
+
+// ensureOpen starts a subpath for a line or curve, at the start of the last
+// subpath after Close and at the origin on an empty path
+func (p *Path) ensureOpen() {
+	if !p.open {
+		p.MoveTo(p.start[0], p.start[1])
+	}
+}
+
+// changed forgets the flattened subpaths
+func (p *Path) changed() {
+	p.tolerance = 0
+}
+
+// flatten returns the subpaths as points no further than tolerance from the
+// curves they follow, reusing those from the last call when the path has
+// not changed since
+func (p *Path) flatten(tolerance float32) []flatPath {
+	if p.tolerance == tolerance && tolerance > 0 {
+		return p.flat
+	}
+	for i := range p.flat {
+		p.flat[i].points = p.flat[i].points[:0]
+	}
+	p.flat = p.flat[:0]
+	var current *flatPath
+	var last [2]float32
+	add := func(q [2]float32) {
+		current.points = append(current.points, q)
+		last = q
+	}
+	i := 0
+	for _, verb := range p.verbs {
+		switch verb {
+		case verbMove:
+			if cap(p.flat) > len(p.flat) {
+				p.flat = p.flat[:len(p.flat)+1]
+				p.flat[len(p.flat)-1].closed = false
+			} else {
+				p.flat = append(p.flat, flatPath{})
+			}
+			current = &p.flat[len(p.flat)-1]
+			add(p.points[i])
+			i++
+		case verbLine:
+			add(p.points[i])
+			i++
+		case verbQuad:
+			c, e := p.points[i], p.points[i+1]
+			i += 2
+			// The segments needed for the curve's deviation from its chord
+			dd := length(last[0]-2*c[0]+e[0], last[1]-2*c[1]+e[1])
+			n := segments(dd / (8 * tolerance))
+			s := last
+			for k := 1; k <= n; k++ {
+				t := float32(k) / float32(n)
+				u := 1 - t
+				add([2]float32{
+					u*u*s[0] + 2*u*t*c[0] + t*t*e[0],
+					u*u*s[1] + 2*u*t*c[1] + t*t*e[1],
+				})
+			}
+		case verbCubic:
+			c1, c2, e := p.points[i], p.points[i+1], p.points[i+2]
+			i += 3
+			dd := max(
+				length(last[0]-2*c1[0]+c2[0], last[1]-2*c1[1]+c2[1]),
+				length(c1[0]-2*c2[0]+e[0], c1[1]-2*c2[1]+e[1]),
+			)
+			n := segments(3 * dd / (4 * tolerance))
+			s := last
+			for k := 1; k <= n; k++ {
+				t := float32(k) / float32(n)
+				u := 1 - t
+				add([2]float32{
+					u*u*u*s[0] + 3*u*u*t*c1[0] + 3*u*t*t*c2[0] + t*t*t*e[0],
+					u*u*u*s[1] + 3*u*u*t*c1[1] + 3*u*t*t*c2[1] + t*t*t*e[1],
+				})
+			}
+		case verbClose:
+			current.closed = true
+		}
+	}
+	p.tolerance = tolerance
+	return p.flat
+}
+
+// segments returns how many segments flatten a curve whose deviation from
+// its chord, relative to the tolerance, is squared
+func segments(squared float32) int {
+	return min(max(int(math.Ceil(math.Sqrt(float64(squared)))), 1), 256)
+}
+
+// length returns the length of a vector
+func length(x, y float32) float32 {
+	return float32(math.Sqrt(float64(x*x + y*y)))
+}
+
+// FillPath fills each subpath of a path with the brush, closing those left
+// open. Subpaths are filled one over another, so one inside another covers
+// it rather than cutting a hole, and each should not cross itself.
+// Gradients span the bounds of the whole path.
+func (d *DrawList) FillPath(p *Path, brush Brush) {
+	if !brush.IsSet() {
+		return
+	}
+	flat := p.flatten(d.tolerance())
+	x, y, width, height, ok := pathBounds(flat)
+	if !ok || d.clippedOut(x-feather, y-feather, width+2*feather, height+2*feather) {
+		return
+	}
+	s := d.shader(brush, x, y, width, height)
+	for _, f := range flat {
+		d.setPath(f.points)
+		if n := len(d.path); n > 1 && abs(d.path[0][0]-d.path[n-1][0]) < 1e-3 && abs(d.path[0][1]-d.path[n-1][1]) < 1e-3 {
+			d.path = d.path[:n-1]
+		}
+		d.fillPath(s, !brush.IsSolid())
+	}
+}
+
+// StrokePath draws lines width wide along the middle of each subpath of a
+// path, painted with the brush as if it filled the path's bounds
+func (d *DrawList) StrokePath(p *Path, width float32, brush Brush) {
+	if !brush.IsSet() || width <= 0 {
+		return
+	}
+	flat := p.flatten(d.tolerance())
+	x, y, w, h, ok := pathBounds(flat)
+	if !ok || d.clippedOut(x-width-feather, y-width-feather, w+2*(width+feather), h+2*(width+feather)) {
+		return
+	}
+	s := d.shader(brush, x, y, w, h)
+	for _, f := range flat {
+		d.setPath(f.points)
+		d.strokePath(f.closed, width, s)
+	}
+}
+
+// setPath sets the path to points, dropping repeated ones
+func (d *DrawList) setPath(points [][2]float32) {
+	d.path = d.path[:0]
+	for _, q := range points {
+		d.addPoint(q[0], q[1])
+	}
+}
+
+// tolerance returns how far flattened curves may stray in the current
+// drawing coordinates, finer under transforms that enlarge them
+func (d *DrawList) tolerance() float32 {
+	m := d.Transform()
+	scale := float32(math.Sqrt(math.Abs(float64(m.A*m.D - m.B*m.C))))
+	if scale <= 0 {
+		return flatness
+	}
+	return flatness / scale
+}
+
+// pathBounds returns the bounding rect of flattened subpaths, and whether
+// they hold any points
+func pathBounds(flat []flatPath) (x, y, width, height float32, ok bool) {
+	x0, y0 := float32(math.MaxFloat32), float32(math.MaxFloat32)
+	x1, y1 := -x0, -y0
+	for _, f := range flat {
+		for _, q := range f.points {
+			x0, y0 = min(x0, q[0]), min(y0, q[1])
+			x1, y1 = max(x1, q[0]), max(y1, q[1])
+			ok = true
+		}
+	}
+	return x0, y0, x1 - x0, y1 - y0, ok
+}
@@ -0,0 +1,466 @@
+//go:build !js
+
+package render
+
+import (
+	"strings"
+	"unsafe"
+
+	"github.com/go-gl/gl/all-core/gl"
+	"lol.mleku.dev/chk"
+	"lol.mleku.dev/log"
+)
+
+const vertexShader = `#version 330 core
+layout(location = 0) in vec2 position;
+layout(location = 1) in vec2 uv;
+layout(location = 2) in vec4 color;
+uniform vec2 viewport;
+// flip is -1 when rendering into a texture, whose rows count from the top
+// rather than the bottom
+uniform float flip;
+out vec2 fragPosition;
+out vec2 fragUV;
+out vec4 fragColor;
+void main() {
+	// Map window coordinates (0,0 = top-left) to clip space
+	gl_Position = vec4(position.x*2.0/viewport.x - 1.0, (1.0 - position.y*2.0/viewport.y)*flip, 0.0, 1.0);
+	fragPosition = position;
+	fragUV = uv;
+	fragColor = color;
+}
+`
+
+const fragmentShader = `#version 330 core
+in vec2 fragPosition;
+in vec2 fragUV;
+in vec4 fragColor;
+uniform sampler2D tex;
+// mask is a rect (x, y, width, height) with corners of radii maskRadius that
+// drawing is confined to, disabled when its width is 0
+uniform vec4 mask;
+uniform vec2 maskRadius;
+// premultiplied is set for textures whose colors are multiplied by alpha
+uniform bool premultiplied;
+// backdrop is the rect of framebuffer pixels (x, y, width, height) a
+// blurred copy of which is sampled where it was copied from in place of
+// fragUV, disabled when its width is 0
+uniform vec4 backdrop;
+out vec4 outColor;
+
+// coverage returns how much of the pixel lies inside the mask
+float coverage() {
+	if (mask.z <= 0.0) {
+		return 1.0;
+	}
+	vec2 halfSize = mask.zw * 0.5;
+	vec2 p = abs(fragPosition - mask.xy - halfSize);
+	vec2 q = p - (halfSize - maskRadius);
+	if (q.x <= 0.0 || q.y <= 0.0 || maskRadius.x <= 0.0 || maskRadius.y <= 0.0) {
+		// Beside the corners the distance is to the nearest straight edge
+		return clamp(0.5 - max(p.x - halfSize.x, p.y - halfSize.y), 0.0, 1.0);
+	}
+	// Approximate the distance to the elliptical corner by its implicit
+	// function divided by the length of its gradient
+	vec2 k = q / maskRadius;
+	float d = (length(k) - 1.0) / length(k / maskRadius) * length(k);
+	return clamp(0.5 - d, 0.0, 1.0);
+}
+
+void main() {
+	vec2 uv = fragUV;
+	if (backdrop.z > 0.0) {
+		uv = (gl_FragCoord.xy - backdrop.xy) / backdrop.zw;
+	}
+	outColor = fragColor * texture(tex, uv);
+	float c = coverage();
+	if (premultiplied) {
+		outColor.rgb *= fragColor.a * c;
+	}
+	outColor.a *= c;
+}
+`
+
+// vertexSize is the size in bytes of one Vertex
+const vertexSize = int32(unsafe.Sizeof(Vertex{}))
+
+// Renderer submits draw lists to OpenGL 3.3 core using a single shader
+// program and a streamed vertex buffer
+type Renderer struct {
+	program  uint32
+	viewport int32
+	flip     int32
+	// premultiplied locates the uniform set for premultiplied textures
+	premultiplied int32
+	// mask and maskRadius locate the uniforms of the rounded clip mask
+	mask       int32
+	maskRadius int32
+	// backdrop locates the uniform of the pixels blurs sample
+	backdrop int32
+	vao      uint32
+	vbo      uint32
+	// white is bound for commands without a texture
+	white *Texture
+	// shaders are the effect shaders compiled, deleted with the renderer
+	shaders []*Shader
+	// blurProgram blurs the pixels below blurs along blurStep by blurSigma,
+	// drawing one triangle from the empty blurVAO, back and forth between
+	// the scratch textures. blurFailed is set when it does not compile.
+	blurProgram uint32
+	blurFailed  bool
+	blurVAO     uint32
+	blurStep    int32
+	blurSigma   int32
+	scratch     [2]*Texture
+}
+
+// NewRenderer compiles the shaders and creates the vertex buffers. It must be
+// called with a current GL context.
+func NewRenderer() (r *Renderer, err error) {
+	r = &Renderer{
+		white: NewTexture(1, 1, FormatRGBA, []byte{255, 255, 255, 255}),
+	}
+	if r.program, err = linkProgram(vertexShader, fragmentShader); chk.E(err) {
+		return nil, err
+	}
+	r.viewport = gl.GetUniformLocation(r.program, gl.Str("viewport\x00"))
+	r.flip = gl.GetUniformLocation(r.program, gl.Str("flip\x00"))
+	r.premultiplied = gl.GetUniformLocation(r.program, gl.Str("premultiplied\x00"))
+	r.mask = gl.GetUniformLocation(r.program, gl.Str("mask\x00"))
+	r.maskRadius = gl.GetUniformLocation(r.program, gl.Str("maskRadius\x00"))
+	r.backdrop = gl.GetUniformLocation(r.program, gl.Str("backdrop\x00"))
+	gl.UseProgram(r.program)
+	gl.Uniform1i(gl.GetUniformLocation(r.program, gl.Str("tex\x00")), 0)
+
+	gl.GenVertexArrays(1, &r.vao)
+	gl.BindVertexArray(r.vao)
+	gl.GenBuffers(1, &r.vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.vbo)
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointerWithOffset(0, 2, gl.FLOAT, false, vertexSize, unsafe.Offsetof(Vertex{}.X))
+	gl.EnableVertexAttribArray(1)
+	gl.VertexAttribPointerWithOffset(1, 2, gl.FLOAT, false, vertexSize, unsafe.Offsetof(Vertex{}.U))
+	gl.EnableVertexAttribArray(2)
+	gl.VertexAttribPointerWithOffset(2, 4, gl.FLOAT, false, vertexSize, unsafe.Offsetof(Vertex{}.R))
+	gl.BindVertexArray(0)
+	return
+}
+
+// Flush draws the list into the current framebuffer and resets it. Width and
+// height are the logical window size the list coordinates refer to.
+func (r *Renderer) Flush(list *DrawList, width, height int) {
+	defer list.Reset()
+	r.draw(list, float32(width), float32(height), false)
+	r.releaseDisposed()
+}
+
+// draw draws a list into the bound framebuffer, whose viewport spans the
+// logical size width by height, after rendering the layers it samples.
+// Flipped framebuffers are textures, whose rows count from the top.
+func (r *Renderer) draw(list *DrawList, width, height float32, flipped bool) {
+	if len(list.Commands) == 0 || width <= 0 || height <= 0 {
+		return
+	}
+	for i := range list.Commands {
+		switch cmd := &list.Commands[i]; {
+		case cmd.Layer != nil:
+			r.layer(cmd)
+		case cmd.GL != nil:
+			r.renderGL(cmd)
+		}
+	}
+
+	// Scissor rects are in framebuffer pixels, which differ from window
+	// coordinates on high density displays
+	var vp [4]int32
+	gl.GetIntegerv(gl.VIEWPORT, &vp[0])
+	scaleX := float32(vp[2]) / width
+	scaleY := float32(vp[3]) / height
+
+	gl.UseProgram(r.program)
+	gl.Uniform2f(r.viewport, width, height)
+	flip := float32(1)
+	if flipped {
+		flip = -1
+	}
+	gl.Uniform1f(r.flip, flip)
+	gl.BindVertexArray(r.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.vbo)
+	if len(list.Vertices) > 0 {
+		gl.BufferData(gl.ARRAY_BUFFER, len(list.Vertices)*int(vertexSize), gl.Ptr(list.Vertices), gl.STREAM_DRAW)
+	}
+	gl.Enable(gl.BLEND)
+	blend(false)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.Uniform4f(r.mask, 0, 0, 0, 0)
+	gl.Uniform1i(r.premultiplied, 0)
+	gl.Uniform4f(r.backdrop, 0, 0, 0, 0)
+	var masked, premultiplied bool
+
+	for i := range list.Commands {
+		cmd := &list.Commands[i]
+		if cmd.Layer != nil || cmd.GL != nil {
+			continue
+		}
+		var backdrop [4]float32
+		if cmd.Blur > 0 {
+			var ok bool
+			if backdrop, ok = r.blur(cmd, scaleX, scaleY, height, flipped); !ok {
+				continue
+			}
+		}
+		if cmd.Clipped {
+			y := height - cmd.Clip[1] - cmd.Clip[3]
+			if flipped {
+				y = cmd.Clip[1]
+			}
+			gl.Enable(gl.SCISSOR_TEST)
+			gl.Scissor(
+				int32(cmd.Clip[0]*scaleX),
+				int32(y*scaleY),
+				int32(cmd.Clip[2]*scaleX),
+				int32(cmd.Clip[3]*scaleY),
+			)
+		} else {
+			gl.Disable(gl.SCISSOR_TEST)
+		}
+		if cmd.Clear {
+			gl.ClearColor(cmd.ClearColor[0], cmd.ClearColor[1], cmd.ClearColor[2], cmd.ClearColor[3])
+			gl.Clear(gl.COLOR_BUFFER_BIT)
+			continue
+		}
+		if cmd.Count == 0 {
+			continue
+		}
+		if cmd.Masked {
+			gl.Uniform4f(r.mask, cmd.Mask[0], cmd.Mask[1], cmd.Mask[2], cmd.Mask[3])
+			gl.Uniform2f(r.maskRadius, cmd.MaskRadius[0], cmd.MaskRadius[1])
+			masked = true
+		} else if masked {
+			gl.Uniform4f(r.mask, 0, 0, 0, 0)
+			masked = false
+		}
+		if cmd.Blur > 0 {
+			// Framebuffer colors are premultiplied by alpha
+			gl.Uniform4f(r.backdrop, backdrop[0], backdrop[1], backdrop[2], backdrop[3])
+			gl.Uniform1i(r.premultiplied, 1)
+			blend(true)
+			gl.BindTexture(gl.TEXTURE_2D, r.scratch[0].id)
+			gl.DrawArrays(gl.TRIANGLES, int32(cmd.First), int32(cmd.Count))
+			gl.Uniform4f(r.backdrop, 0, 0, 0, 0)
+			if !premultiplied {
+				gl.Uniform1i(r.premultiplied, 0)
+				blend(false)
+			}
+			continue
+		}
+		texture := cmd.Texture
+		if texture == nil {
+			texture = r.white
+		}
+		if cmd.Shader != nil && r.useShader(cmd, width, height, flip) {
+			// Effects return premultiplied colors
+			blend(true)
+			r.bind(texture)
+			gl.DrawArrays(gl.TRIANGLES, int32(cmd.First), int32(cmd.Count))
+			gl.UseProgram(r.program)
+			blend(premultiplied)
+			continue
+		}
+		if texture.Premultiplied != premultiplied {
+			premultiplied = texture.Premultiplied
+			var on int32
+			if premultiplied {
+				on = 1
+			}
+			gl.Uniform1i(r.premultiplied, on)
+			blend(premultiplied)
+		}
+		r.bind(texture)
+		gl.DrawArrays(gl.TRIANGLES, int32(cmd.First), int32(cmd.Count))
+	}
+
+	gl.Disable(gl.SCISSOR_TEST)
+	gl.BindVertexArray(0)
+}
+
+// blend sets how colors are drawn over the framebuffer, for colors
+// premultiplied by alpha or not. Alpha accumulates coverage so transparent
+// windows composite correctly.
+func blend(premultiplied bool) {
+	if premultiplied {
+		gl.BlendFuncSeparate(gl.ONE, gl.ONE_MINUS_SRC_ALPHA, gl.ONE, gl.ONE_MINUS_SRC_ALPHA)
+		return
+	}
+	gl.BlendFuncSeparate(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA, gl.ONE, gl.ONE_MINUS_SRC_ALPHA)
+}
+
+// layer renders a command's layer into its texture through a framebuffer
+// of the texture's own, restoring the framebuffer and viewport after
+func (r *Renderer) layer(cmd *Command) {
+	defer cmd.Layer.Reset()
+	t := cmd.Texture
+	if t.Width <= 0 || t.Height <= 0 {
+		return
+	}
+	r.target(t)
+	var previous int32
+	var vp [4]int32
+	gl.GetIntegerv(gl.FRAMEBUFFER_BINDING, &previous)
+	gl.GetIntegerv(gl.VIEWPORT, &vp[0])
+	gl.BindFramebuffer(gl.FRAMEBUFFER, t.fbo)
+	gl.Viewport(0, 0, int32(t.Width), int32(t.Height))
+	gl.Disable(gl.SCISSOR_TEST)
+	gl.ClearColor(0, 0, 0, 0)
+	gl.Clear(gl.COLOR_BUFFER_BIT)
+	r.draw(cmd.Layer, cmd.LayerSize[0], cmd.LayerSize[1], true)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, uint32(previous))
+	gl.Viewport(vp[0], vp[1], vp[2], vp[3])
+}
+
+// Release frees the GPU copy of a texture. The texture is uploaded again if
+// it is drawn later.
+func (r *Renderer) Release(t *Texture) {
+	if t.fbo != 0 {
+		gl.DeleteFramebuffers(1, &t.fbo)
+		t.fbo = 0
+	}
+	if t.depth != 0 {
+		gl.DeleteRenderbuffers(1, &t.depth)
+		t.depth = 0
+	}
+	if t.id != 0 {
+		gl.DeleteTextures(1, &t.id)
+		t.id = 0
+		t.uploaded = 0
+		t.glWidth, t.glHeight, t.glFilter = 0, 0, 0
+	}
+}
+
+// releaseDisposed frees the textures passed to Dispose since the last frame
+func (r *Renderer) releaseDisposed() {
+	disposed.Lock()
+	textures := disposed.textures
+	disposed.textures = nil
+	disposed.Unlock()
+	for _, t := range textures {
+		r.Release(t)
+	}
+}
+
+// Delete frees the renderer's GL objects and the textures disposed since
+// the last frame
+func (r *Renderer) Delete() {
+	r.releaseDisposed()
+	r.Release(r.white)
+	for _, s := range r.shaders {
+		gl.DeleteProgram(s.program)
+		s.program = 0
+	}
+	r.shaders = nil
+	if r.blurProgram != 0 {
+		for _, t := range r.scratch {
+			r.Release(t)
+		}
+		gl.DeleteVertexArrays(1, &r.blurVAO)
+		gl.DeleteProgram(r.blurProgram)
+	}
+	gl.DeleteBuffers(1, &r.vbo)
+	gl.DeleteVertexArrays(1, &r.vao)
+	gl.DeleteProgram(r.program)
+}
+
+// bind binds a texture, uploading its pixels if they changed since last time
+func (r *Renderer) bind(t *Texture) {
+	if t.id == 0 {
+		gl.GenTextures(1, &t.id)
+		gl.BindTexture(gl.TEXTURE_2D, t.id)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+		if t.Format == FormatAlpha {
+			// Sample single channel coverage as white with alpha
+			swizzle := [4]int32{gl.ONE, gl.ONE, gl.ONE, gl.RED}
+			gl.TexParameteriv(gl.TEXTURE_2D, gl.TEXTURE_SWIZZLE_RGBA, &swizzle[0])
+		}
+	} else {
+		gl.BindTexture(gl.TEXTURE_2D, t.id)
+	}
+	filter := int32(gl.LINEAR)
+	if t.Filter == FilterNearest {
+		filter = gl.NEAREST
+	}
+	if filter != t.glFilter {
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, filter)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, filter)
+		t.glFilter = filter
+	}
+	if t.uploaded == t.version || len(t.Pixels) < t.Width*t.Height*t.BytesPerPixel() {
+		return
+	}
+
+	internal, format := int32(gl.RGBA8), uint32(gl.RGBA)
+	if t.Format == FormatAlpha {
+		internal, format = gl.R8, gl.RED
+	}
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	if t.glWidth == t.Width && t.glHeight == t.Height {
+		gl.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, int32(t.Width), int32(t.Height), format, gl.UNSIGNED_BYTE, gl.Ptr(t.Pixels))
+	} else {
+		gl.TexImage2D(gl.TEXTURE_2D, 0, internal, int32(t.Width), int32(t.Height), 0, format, gl.UNSIGNED_BYTE, gl.Ptr(t.Pixels))
+		t.glWidth, t.glHeight = t.Width, t.Height
+	}
+	t.uploaded = t.version
+}
+
+// linkProgram compiles and links a vertex and fragment shader pair
+func linkProgram(vertexSource, fragmentSource string) (program uint32, err error) {
+	var vertex, fragment uint32
+	if vertex, err = compileShader(vertexSource, gl.VERTEX_SHADER); chk.E(err) {
+		return
+	}
+	defer gl.DeleteShader(vertex)
+	if fragment, err = compileShader(fragmentSource, gl.FRAGMENT_SHADER); chk.E(err) {
+		return
+	}
+	defer gl.DeleteShader(fragment)
+
+	program = gl.CreateProgram()
+	gl.AttachShader(program, vertex)
+	gl.AttachShader(program, fragment)
+	gl.LinkProgram(program)
+
+	var status int32
+	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
+	if status == gl.FALSE {
+		var length int32
+		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &length)
+		infoLog := strings.Repeat("\x00", int(length+1))
+		gl.GetProgramInfoLog(program, length, nil, gl.Str(infoLog))
+		log.E.Ln("program link:", infoLog)
+		gl.DeleteProgram(program)
+		return 0, errProgramLink
+	}
+	return
+}
+
+// compileShader compiles a single shader stage
+func compileShader(source string, shaderType uint32) (shader uint32, err error) {
+	shader = gl.CreateShader(shaderType)
+	sources, free := gl.Strs(source + "\x00")
+	gl.ShaderSource(shader, 1, sources, nil)
+	free()
+	gl.CompileShader(shader)
+
+	var status int32
+	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
+	if status == gl.FALSE {
+		var length int32
+		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &length)
+		infoLog := strings.Repeat("\x00", int(length+1))
+		gl.GetShaderInfoLog(shader, length, nil, gl.Str(infoLog))
+		log.E.Ln("shader compile:", infoLog)
+		gl.DeleteShader(shader)
+		return 0, errShaderCompile
+	}
+	return
+}
@@ -0,0 +1,67 @@
+package render
+
+// Shader is a GLSL fragment shader drawn over a texture with
+// DrawList.Effect. Its source defines the function
+//
+//	vec4 effect(vec2 uv)
+//
+// returning the color at uv, the texture coordinate from 0 to 1 across the
+// drawn rect, usually computed from texture(tex, uv). Colors are
+// premultiplied by alpha, both those of textures draw lists are rendered
+// into and those returned. The source declares the uniforms it is given
+// with the command along with any functions it needs, and is compiled the
+// first time it is drawn. The software canvas cannot run shaders and draws
+// the texture unaffected, as does the GL renderer when the source does not
+// compile.
+type Shader struct {
+	source string
+	// GL state owned by the renderer
+	program   uint32
+	failed    bool
+	locations map[string]int32
+}
+
+// NewShader creates a shader from the source of its effect function
+func NewShader(source string) *Shader {
+	return &Shader{source: source}
+}
+
+// Source returns the source of the shader's effect function
+func (s *Shader) Source() string {
+	return s.source
+}
+
+// Uniform is a value of one to four floats passed to a shader by name, as a
+// float or a vec2, vec3 or vec4
+type Uniform struct {
+	Name  string
+	Value []float32
+}
+
+// Effect draws a rect textured with a texture through a shader given the
+// uniforms. Effects are not confined by rounded clips.
+func (d *DrawList) Effect(shader *Shader, uniforms []Uniform, texture *Texture, x, y, width, height float32) {
+	if width <= 0 || height <= 0 || d.clippedOut(x, y, width, height) {
+		return
+	}
+	clip, clipped := d.ClipRect()
+	d.Commands = append(d.Commands, Command{
+		Texture:  texture,
+		Shader:   shader,
+		Uniforms: uniforms,
+		Clip:     clip,
+		Clipped:  clipped,
+		First:    len(d.Vertices),
+		Count:    6,
+	})
+	color := d.fade([4]float32{1, 1, 1, 1})
+	x1, y1 := x+width, y+height
+	d.Vertices = append(d.Vertices,
+		vertex(x, y, 0, 0, color),
+		vertex(x1, y, 1, 0, color),
+		vertex(x1, y1, 1, 1, color),
+		vertex(x, y, 0, 0, color),
+		vertex(x1, y1, 1, 1, color),
+		vertex(x, y1, 0, 1, color),
+	)
+}
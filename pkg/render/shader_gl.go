@@ -0,0 +1,73 @@
+//go:build !js
+
+package render
+
+import (
+	"github.com/go-gl/gl/all-core/gl"
+	"lol.mleku.dev/chk"
+)
+
+// effectPrelude declares what every effect shader is given: the texture it
+// runs over and the coordinates being shaded
+const effectPrelude = `#version 330 core
+in vec2 fragPosition;
+in vec2 fragUV;
+in vec4 fragColor;
+uniform sampler2D tex;
+out vec4 outColor;
+`
+
+// effectMain runs the effect, fading its color by the opacity drawn with
+const effectMain = `
+void main() {
+	outColor = effect(fragUV) * fragColor.a;
+}
+`
+
+// useShader switches to the program of a command's shader, compiling it
+// the first time, and sets its uniforms. It reports false when the shader
+// does not compile, leaving the renderer's own program in use.
+func (r *Renderer) useShader(cmd *Command, width, height, flip float32) bool {
+	s := cmd.Shader
+	if s.failed {
+		return false
+	}
+	if s.program == 0 {
+		var err error
+		if s.program, err = linkProgram(vertexShader, effectPrelude+s.source+effectMain); chk.E(err) {
+			s.failed = true
+			return false
+		}
+		s.locations = make(map[string]int32)
+		r.shaders = append(r.shaders, s)
+	}
+	gl.UseProgram(s.program)
+	gl.Uniform2f(s.location("viewport"), width, height)
+	gl.Uniform1f(s.location("flip"), flip)
+	gl.Uniform1i(s.location("tex"), 0)
+	for _, u := range cmd.Uniforms {
+		l := s.location(u.Name)
+		switch v := u.Value; len(v) {
+		case 1:
+			gl.Uniform1f(l, v[0])
+		case 2:
+			gl.Uniform2f(l, v[0], v[1])
+		case 3:
+			gl.Uniform3f(l, v[0], v[1], v[2])
+		case 4:
+			gl.Uniform4f(l, v[0], v[1], v[2], v[3])
+		}
+	}
+	return true
+}
+
+// location returns the location of a uniform of the shader's program, -1
+// for those it does not use
+func (s *Shader) location(name string) int32 {
+	l, ok := s.locations[name]
+	if !ok {
+		l = gl.GetUniformLocation(s.program, gl.Str(name+"\x00"))
+		s.locations[name] = l
+	}
+	return l
+}
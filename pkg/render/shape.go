@@ -0,0 +1,576 @@
+package render
+
+import (
+	"math"
+)
+
+// feather is the width in pixels over which the edges of shapes fade out,
+// smoothing them without multisampling
+const feather = 1.0
+
+// gradientStep is the longest edge, in pixels, between the points at which
+// gradients are evaluated across a shape
+const gradientStep = 16
+
+// gradientRings is the number of rings between the middle and the edge of a
+// gradient filled shape at which gradients are evaluated
+const gradientRings = 8
+
+// shade returns the color of a shape at a point
+type shade func(x, y float32) [4]float32
+
+// RoundRect adds a solid colored rectangle with corners rounded to radius.
+// Without rounding the rectangle is drawn with sharp edges like Rect.
+func (d *DrawList) RoundRect(x, y, width, height, radius float32, color [4]float32) {
+	d.FillRoundRect(x, y, width, height, radius, Solid(color))
+}
+
+// FillRoundRect adds a rectangle with corners rounded to radius, painted
+// with the brush. Without rounding the rectangle has sharp edges like Rect.
+func (d *DrawList) FillRoundRect(x, y, width, height, radius float32, brush Brush) {
+	if !brush.IsSet() {
+		return
+	}
+	radius = min(radius, width/2, height/2)
+	if radius <= 0 {
+		d.fillRect(x, y, width, height, brush, d.shader(brush, x, y, width, height))
+		return
+	}
+	if width <= 0 || height <= 0 || d.clippedOut(x-feather, y-feather, width+2*feather, height+2*feather) {
+		return
+	}
+	d.roundRectPath(x, y, width, height, radius)
+	d.fillPath(d.shader(brush, x, y, width, height), !brush.IsSolid())
+}
+
+// RoundRectStroke adds the outline of a rectangle with corners rounded to
+// radius, lineWidth wide inside the rectangle's edges. Without rounding the
+// outline is drawn with sharp edges like Rect.
+func (d *DrawList) RoundRectStroke(x, y, width, height, radius, lineWidth float32, color [4]float32) {
+	d.StrokeRoundRect(x, y, width, height, radius, lineWidth, Solid(color))
+}
+
+// StrokeRoundRect adds the outline of a rectangle with corners rounded to
+// radius, lineWidth wide inside the rectangle's edges and painted with the
+// brush as if it filled the whole rectangle. Without rounding the outline
+// has sharp edges like Rect.
+func (d *DrawList) StrokeRoundRect(x, y, width, height, radius, lineWidth float32, brush Brush) {
+	if !brush.IsSet() || width <= 0 || height <= 0 || lineWidth <= 0 || d.clippedOut(x-feather, y-feather, width+2*feather, height+2*feather) {
+		return
+	}
+	s := d.shader(brush, x, y, width, height)
+	lineWidth = min(lineWidth, width/2, height/2)
+	radius = min(radius, width/2, height/2)
+	if radius <= 0 {
+		d.fillRect(x, y, width, lineWidth, brush, s)
+		d.fillRect(x, y+height-lineWidth, width, lineWidth, brush, s)
+		d.fillRect(x, y+lineWidth, lineWidth, height-2*lineWidth, brush, s)
+		d.fillRect(x+width-lineWidth, y+lineWidth, lineWidth, height-2*lineWidth, brush, s)
+		return
+	}
+	// Stroke along the middle of the line
+	half := lineWidth / 2
+	d.roundRectPath(x+half, y+half, width-lineWidth, height-lineWidth, max(radius-half, 0))
+	d.strokePath(true, lineWidth, s)
+}
+
+// Circle adds a solid colored circle
+func (d *DrawList) Circle(cx, cy, radius float32, color [4]float32) {
+	d.Ellipse(cx, cy, radius, radius, color)
+}
+
+// CircleStroke adds the outline of a circle, lineWidth wide inside its edge
+func (d *DrawList) CircleStroke(cx, cy, radius, lineWidth float32, color [4]float32) {
+	d.EllipseStroke(cx, cy, radius, radius, lineWidth, color)
+}
+
+// Ellipse adds a solid colored ellipse with the given horizontal and vertical radii
+func (d *DrawList) Ellipse(cx, cy, rx, ry float32, color [4]float32) {
+	d.FillEllipse(cx, cy, rx, ry, Solid(color))
+}
+
+// FillEllipse adds an ellipse with the given horizontal and vertical radii,
+// painted with the brush
+func (d *DrawList) FillEllipse(cx, cy, rx, ry float32, brush Brush) {
+	if !brush.IsSet() || rx <= 0 || ry <= 0 || d.clippedOut(cx-rx-feather, cy-ry-feather, 2*(rx+feather), 2*(ry+feather)) {
+		return
+	}
+	d.ellipsePath(cx, cy, rx, ry)
+	d.fillPath(d.shader(brush, cx-rx, cy-ry, 2*rx, 2*ry), !brush.IsSolid())
+}
+
+// EllipseStroke adds the outline of an ellipse, lineWidth wide inside its edge
+func (d *DrawList) EllipseStroke(cx, cy, rx, ry, lineWidth float32, color [4]float32) {
+	d.StrokeEllipse(cx, cy, rx, ry, lineWidth, Solid(color))
+}
+
+// StrokeEllipse adds the outline of an ellipse, lineWidth wide inside its
+// edge and painted with the brush as if it filled the whole ellipse
+func (d *DrawList) StrokeEllipse(cx, cy, rx, ry, lineWidth float32, brush Brush) {
+	if !brush.IsSet() || rx <= 0 || ry <= 0 || lineWidth <= 0 || d.clippedOut(cx-rx-feather, cy-ry-feather, 2*(rx+feather), 2*(ry+feather)) {
+		return
+	}
+	half := min(lineWidth, rx, ry) / 2
+	d.ellipsePath(cx, cy, rx-half, ry-half)
+	d.strokePath(true, 2*half, d.shader(brush, cx-rx, cy-ry, 2*rx, 2*ry))
+}
+
+// Polygon adds a solid colored polygon through the points in order, which
+// should not cross itself
+func (d *DrawList) Polygon(points [][2]float32, color [4]float32) {
+	d.path = append(d.path[:0], points...)
+	d.fillPath(d.shader(Solid(color), 0, 0, 0, 0), false)
+}
+
+// Polyline adds lines of the given width joining the points in order, and
+// joining the last back to the first when closed
+func (d *DrawList) Polyline(points [][2]float32, closed bool, width float32, color [4]float32) {
+	d.path = append(d.path[:0], points...)
+	d.strokePath(closed, width, d.shader(Solid(color), 0, 0, 0, 0))
+}
+
+// Line adds a straight line of the given width between two points
+func (d *DrawList) Line(x0, y0, x1, y1, width float32, color [4]float32) {
+	d.path = append(d.path[:0], [2]float32{x0, y0}, [2]float32{x1, y1})
+	d.strokePath(false, width, d.shader(Solid(color), 0, 0, 0, 0))
+}
+
+// shadowRings is the number of rings across the blurred edge of a shadow
+const shadowRings = 8
+
+// shadowCorner is the number of segments in each corner of a shadow ring
+const shadowCorner = 8
+
+// Shadow adds the soft shadow of a rectangle with corners rounded to radius,
+// as if blurred by a Gaussian with a standard deviation of half the blur. The
+// shadow fades out over blur either side of the rectangle's edges, so it
+// reaches blur beyond them.
+func (d *DrawList) Shadow(x, y, width, height, radius, blur float32, color [4]float32) {
+	if blur <= 0 {
+		d.RoundRect(x, y, width, height, radius, color)
+		return
+	}
+	if width <= 0 || height <= 0 || d.clippedOut(x-blur, y-blur, width+2*blur, height+2*blur) {
+		return
+	}
+	color = d.fade(color)
+	radius = max(min(radius, width/2, height/2), 0)
+	// The blurred edge reaches inside the rectangle as far as outside it,
+	// but no further than its middle
+	inset := min(blur, width/2, height/2)
+	sigma := float64(blur) / 2
+	// ring returns the rectangle offset outwards from the edges by a distance
+	// with its opacity there, the fraction of the Gaussian beyond the edge
+	ring := func(by float32) (points [][2]float32, alpha float32) {
+		start := len(d.path)
+		d.shadowPath(x-by, y-by, width+2*by, height+2*by, max(radius+by, 0))
+		alpha = color[3] * float32(math.Erfc(float64(by)/(sigma*math.Sqrt2))/2)
+		return d.path[start:], alpha
+	}
+	d.path = d.path[:0]
+	inner, alpha := ring(-inset)
+	n := len(inner)
+	d.command(nil, 3*(n-2)+6*n*shadowRings)
+	at := func(p [2]float32, alpha float32) Vertex {
+		c := color
+		c[3] = alpha
+		return vertex(p[0], p[1], 0, 0, c)
+	}
+	for i := 1; i < n-1; i++ {
+		d.Vertices = append(d.Vertices, at(inner[0], alpha), at(inner[i], alpha), at(inner[i+1], alpha))
+	}
+	for r := 1; r <= shadowRings; r++ {
+		outer, outerAlpha := ring(-inset + (inset+blur)*float32(r)/shadowRings)
+		if r == shadowRings {
+			outerAlpha = 0
+		}
+		for i := range n {
+			j := (i + 1) % n
+			a, b := at(inner[i], alpha), at(inner[j], alpha)
+			c, e := at(outer[j], outerAlpha), at(outer[i], outerAlpha)
+			d.Vertices = append(d.Vertices, a, b, c, a, c, e)
+		}
+		inner, alpha = outer, outerAlpha
+	}
+}
+
+// shadowPath appends the outline of a rounded rectangle to the path with the
+// same number of points whatever the radius, so the rings of a shadow can be
+// joined point to point
+func (d *DrawList) shadowPath(x, y, width, height, radius float32) {
+	corners := [4][2]float32{
+		{x + radius, y + radius},
+		{x + width - radius, y + radius},
+		{x + width - radius, y + height - radius},
+		{x + radius, y + height - radius},
+	}
+	for c, center := range corners {
+		start := math.Pi + float64(c)*math.Pi/2
+		for i := 0; i <= shadowCorner; i++ {
+			angle := start + float64(i)/shadowCorner*math.Pi/2
+			d.path = append(d.path, [2]float32{center[0] + radius*float32(math.Cos(angle)), center[1] + radius*float32(math.Sin(angle))})
+		}
+	}
+}
+
+// shader returns the colors the brush paints over a shape with the given
+// bounds, faded by the current opacity
+func (d *DrawList) shader(brush Brush, x, y, width, height float32) shade {
+	if brush.IsSolid() {
+		color := d.fade(brush.Color())
+		return func(float32, float32) [4]float32 { return color }
+	}
+	sx, sy := float32(0), float32(0)
+	if width > 0 {
+		sx = 1 / width
+	}
+	if height > 0 {
+		sy = 1 / height
+	}
+	return func(px, py float32) [4]float32 {
+		return d.fade(brush.At((px-x)*sx, (py-y)*sy))
+	}
+}
+
+// fillRect adds a rectangle with sharp edges, shaded across it
+func (d *DrawList) fillRect(x, y, width, height float32, brush Brush, s shade) {
+	if brush.IsSolid() {
+		d.Rect(x, y, width, height, brush.Color())
+		return
+	}
+	if width <= 0 || height <= 0 || d.clippedOut(x, y, width, height) {
+		return
+	}
+	d.path = append(d.path[:0], [2]float32{x, y}, [2]float32{x + width, y}, [2]float32{x + width, y + height}, [2]float32{x, y + height})
+	d.densify()
+	d.fillMesh(s)
+}
+
+// roundRectPath sets the path to the outline of a rounded rectangle,
+// clockwise from the left end of the top left corner
+func (d *DrawList) roundRectPath(x, y, width, height, radius float32) {
+	d.path = d.path[:0]
+	segments := min(max(int(radius), 2), 12)
+	if radius <= 0 {
+		segments = 0
+	}
+	corners := [4][2]float32{
+		{x + radius, y + radius},
+		{x + width - radius, y + radius},
+		{x + width - radius, y + height - radius},
+		{x + radius, y + height - radius},
+	}
+	for c, center := range corners {
+		start := math.Pi + float64(c)*math.Pi/2
+		for i := 0; i <= segments; i++ {
+			angle := start
+			if segments > 0 {
+				angle += float64(i) / float64(segments) * math.Pi / 2
+			}
+			d.addPoint(center[0]+radius*float32(math.Cos(angle)), center[1]+radius*float32(math.Sin(angle)))
+		}
+	}
+}
+
+// ellipsePath sets the path to the outline of an ellipse, clockwise from its
+// left end
+func (d *DrawList) ellipsePath(cx, cy, rx, ry float32) {
+	d.path = d.path[:0]
+	segments := min(max(int(2*max(rx, ry)), 16), 96)
+	for i := range segments {
+		angle := math.Pi + float64(i)/float64(segments)*2*math.Pi
+		d.addPoint(cx+rx*float32(math.Cos(angle)), cy+ry*float32(math.Sin(angle)))
+	}
+}
+
+// addPoint appends a point to the path unless it repeats the last one
+func (d *DrawList) addPoint(x, y float32) {
+	if n := len(d.path); n > 0 {
+		last := d.path[n-1]
+		if abs(last[0]-x) < 1e-3 && abs(last[1]-y) < 1e-3 {
+			return
+		}
+	}
+	d.path = append(d.path, [2]float32{x, y})
+}
+
+// computeNormals sets the normals to the outward directions at each point of
+// the path, scaled at corners so offsetting by them keeps edges parallel
+func (d *DrawList) computeNormals(closed bool) {
+	p := d.path
+	n := len(p)
+	d.normals = d.normals[:0]
+	edge := func(i int) (nx, ny float32) {
+		a, b := p[i], p[(i+1)%n]
+		dx, dy := b[0]-a[0], b[1]-a[1]
+		if l := float32(math.Sqrt(float64(dx*dx + dy*dy))); l > 0 {
+			dx, dy = dx/l, dy/l
+		}
+		// Clockwise on screen, the outside is to the left of each edge
+		return dy, -dx
+	}
+	for i := range n {
+		var ax, ay, bx, by float32
+		switch {
+		case !closed && i == 0:
+			ax, ay = edge(0)
+			bx, by = ax, ay
+		case !closed && i == n-1:
+			ax, ay = edge(n - 2)
+			bx, by = ax, ay
+		default:
+			ax, ay = edge((i + n - 1) % n)
+			bx, by = edge(i)
+		}
+		mx, my := (ax+bx)/2, (ay+by)/2
+		// Lengthen the averaged normal to reach the offset edges, limiting
+		// the spike at very sharp corners
+		if d2 := mx*mx + my*my; d2 > 1e-6 {
+			scale := min(1/d2, 100)
+			mx, my = mx*scale, my*scale
+		}
+		d.normals = append(d.normals, [2]float32{mx, my})
+	}
+}
+
+// densify adds points along the edges of the closed path so none is longer
+// than the gradient step
+func (d *DrawList) densify() {
+	p := d.path
+	n := len(p)
+	out := d.normals[:0]
+	for i := range n {
+		a, b := p[i], p[(i+1)%n]
+		dx, dy := b[0]-a[0], b[1]-a[1]
+		steps := max(int(math.Ceil(math.Sqrt(float64(dx*dx+dy*dy))/gradientStep)), 1)
+		for k := range steps {
+			t := float32(k) / float32(steps)
+			out = append(out, [2]float32{a[0] + dx*t, a[1] + dy*t})
+		}
+	}
+	// The normals are recomputed from the path before use, so their storage
+	// is free to swap with the path's
+	d.path, d.normals = out, p
+}
+
+// fillPath fills the path, fading its edge out over the feather. Gradients
+// are evaluated at points spread across convex shapes rather than only
+// around their edge.
+func (d *DrawList) fillPath(s shade, gradient bool) {
+	if len(d.path) < 3 {
+		return
+	}
+	if gradient {
+		d.densify()
+	}
+	p := d.path
+	n := len(p)
+	d.computeNormals(true)
+	// The normals point inwards when the path runs anticlockwise
+	var area float32
+	for i := range n {
+		j := (i + 1) % n
+		area += p[i][0]*p[j][1] - p[j][0]*p[i][1]
+	}
+	side := float32(1)
+	if area < 0 {
+		side = -1
+	}
+	convex := d.convex(side)
+	offset := func(i int, by float32) Vertex {
+		by *= side
+		x, y := p[i][0]+d.normals[i][0]*by, p[i][1]+d.normals[i][1]*by
+		return vertex(x, y, 0, 0, s(x, y))
+	}
+	// Pull the edge in by half the feather, the fringe fading out beyond it
+	for i := range n {
+		v := offset(i, -feather/2)
+		p[i] = [2]float32{v.X, v.Y}
+	}
+	switch {
+	case !convex:
+		d.fillConcave(s, side)
+	case gradient:
+		d.fillMesh(s)
+	default:
+		d.command(nil, 3*(n-2))
+		inner0 := offset(0, 0)
+		for i := 1; i < n-1; i++ {
+			d.Vertices = append(d.Vertices, inner0, offset(i, 0), offset(i+1, 0))
+		}
+	}
+	d.command(nil, 6*n)
+	for i := range n {
+		j := (i + 1) % n
+		ii, ij := offset(i, 0), offset(j, 0)
+		oi, oj := offset(i, feather), offset(j, feather)
+		oi.A, oj.A = 0, 0
+		d.Vertices = append(d.Vertices, ii, ij, oj, ii, oj, oi)
+	}
+}
+
+// convex reports whether the path turns the same way, given by side, at
+// every corner
+func (d *DrawList) convex(side float32) bool {
+	p := d.path
+	n := len(p)
+	for i := range n {
+		if turn(p[(i+n-1)%n], p[i], p[(i+1)%n])*side < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// fillConcave fills a path that turns both ways by cutting it into triangles,
+// each time cutting off a corner turning the way the path runs whose
+// triangle holds no other corner. A path crossing itself may run out of such
+// corners, and what is left is filled as if it were convex.
+func (d *DrawList) fillConcave(s shade, side float32) {
+	p := d.path
+	corners := d.corners[:0]
+	for i := range p {
+		corners = append(corners, i)
+	}
+	tris := d.triangles[:0]
+	for len(corners) > 3 {
+		n := len(corners)
+		cut := -1
+		for k := range n {
+			a, b, c := corners[(k+n-1)%n], corners[k], corners[(k+1)%n]
+			if turn(p[a], p[b], p[c])*side <= 0 {
+				continue
+			}
+			ear := true
+			for _, j := range corners {
+				if j != a && j != b && j != c && inTriangle(p[j], p[a], p[b], p[c]) {
+					ear = false
+					break
+				}
+			}
+			if ear {
+				tris = append(tris, a, b, c)
+				cut = k
+				break
+			}
+		}
+		if cut < 0 {
+			break
+		}
+		corners = append(corners[:cut], corners[cut+1:]...)
+	}
+	for i := 1; i < len(corners)-1; i++ {
+		tris = append(tris, corners[0], corners[i], corners[i+1])
+	}
+	d.corners, d.triangles = corners, tris
+	d.command(nil, len(tris))
+	for _, i := range tris {
+		d.Vertices = append(d.Vertices, vertex(p[i][0], p[i][1], 0, 0, s(p[i][0], p[i][1])))
+	}
+}
+
+// turn returns which way the path turns at b coming from a and going on to
+// c, its sign matching the path's area when it turns the way the path runs
+func turn(a, b, c [2]float32) float32 {
+	return (b[0]-a[0])*(c[1]-b[1]) - (b[1]-a[1])*(c[0]-b[0])
+}
+
+// inTriangle reports whether a point lies strictly inside a triangle
+func inTriangle(q, a, b, c [2]float32) bool {
+	d1, d2, d3 := turn(a, b, q), turn(b, c, q), turn(c, a, q)
+	return (d1 > 0 && d2 > 0 && d3 > 0) || (d1 < 0 && d2 < 0 && d3 < 0)
+}
+
+// fillMesh fills the convex path with rings of triangles from its middle out
+// to its edge, shading each corner so gradients blend smoothly across it
+func (d *DrawList) fillMesh(s shade) {
+	p := d.path
+	n := len(p)
+	if n < 3 {
+		return
+	}
+	var cx, cy float32
+	for _, q := range p {
+		cx += q[0]
+		cy += q[1]
+	}
+	cx, cy = cx/float32(n), cy/float32(n)
+	at := func(i int, t float32) Vertex {
+		x, y := cx+(p[i][0]-cx)*t, cy+(p[i][1]-cy)*t
+		return vertex(x, y, 0, 0, s(x, y))
+	}
+	d.command(nil, 3*n+6*n*(gradientRings-1))
+	center := vertex(cx, cy, 0, 0, s(cx, cy))
+	for r := range gradientRings {
+		t0, t1 := float32(r)/gradientRings, float32(r+1)/gradientRings
+		for i := range n {
+			j := (i + 1) % n
+			if r == 0 {
+				d.Vertices = append(d.Vertices, center, at(i, t1), at(j, t1))
+				continue
+			}
+			a, b, c, e := at(i, t0), at(j, t0), at(j, t1), at(i, t1)
+			d.Vertices = append(d.Vertices, a, b, c, a, c, e)
+		}
+	}
+}
+
+// strokePath draws lines of the given width along the path, fading both
+// edges out over the feather. Lines thinner than the feather are drawn
+// fainter instead.
+func (d *DrawList) strokePath(closed bool, width float32, s shade) {
+	p := d.path
+	n := len(p)
+	if n < 2 || width <= 0 {
+		return
+	}
+	d.computeNormals(closed)
+	opacity := float32(1)
+	if width < feather {
+		opacity = width / feather
+	}
+	core := max(width-feather, 0) / 2
+	// Rings of vertices across the line, from outside to inside, with the
+	// opacity of each
+	rings := []struct {
+		by    float32
+		alpha float32
+	}{
+		{core + feather, 0},
+		{core, opacity},
+		{-core, opacity},
+		{-core - feather, 0},
+	}
+	if core == 0 {
+		// Thin lines have a single ring along the middle at full opacity
+		rings = append(rings[:1], rings[2:]...)
+	}
+	offset := func(i int, ring int) Vertex {
+		by := rings[ring].by
+		x, y := p[i][0]+d.normals[i][0]*by, p[i][1]+d.normals[i][1]*by
+		v := vertex(x, y, 0, 0, s(x, y))
+		v.A *= rings[ring].alpha
+		return v
+	}
+	segments := n - 1
+	if closed {
+		segments = n
+	}
+	d.command(nil, 6*segments*(len(rings)-1))
+	for seg := range segments {
+		i, j := seg, (seg+1)%n
+		for r := 0; r < len(rings)-1; r++ {
+			a, b := offset(i, r), offset(j, r)
+			c, e := offset(j, r+1), offset(i, r+1)
+			d.Vertices = append(d.Vertices, a, b, c, a, c, e)
+		}
+	}
+}
+
+// abs returns the absolute value of x
+func abs(x float32) float32 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,130 @@
+package render
+
+import (
+	"image"
+	"image/draw"
+	"sync"
+)
+
+// TextureSource is a texture fed frames by a producer on another
+// goroutine, such as a camera, a video decoder or a remote desktop stream.
+// Frames pushed are copied into a back buffer, which Update swaps with the
+// texture's pixels on the UI goroutine, so the producer never writes pixels
+// being drawn and frames pushed faster than they are shown replace each
+// other. Frames are RGBA without premultiplied alpha, as image.NRGBA holds.
+type TextureSource struct {
+	texture *Texture
+	mu      sync.Mutex
+	// back holds the latest frame pushed, width by height, waiting for
+	// Update when pending
+	back          []byte
+	width, height int
+	pending       bool
+	// frames counts the frames pushed and shown the frames swapped in
+	frames, shown int
+	wake          func()
+}
+
+// NewTextureSource creates a source whose texture is empty until the
+// first frame is pushed
+func NewTextureSource() *TextureSource {
+	return &TextureSource{texture: NewTexture(0, 0, FormatRGBA, nil)}
+}
+
+// Wake sets a function called on the producer's goroutine after each frame
+// is pushed, such as the Wake of the window showing the source, and returns
+// the source for chaining
+func (s *TextureSource) Wake(fn func()) *TextureSource {
+	s.mu.Lock()
+	s.wake = fn
+	s.mu.Unlock()
+	return s
+}
+
+// Push copies a frame of width by height RGBA pixels into the back buffer,
+// replacing any frame not yet shown. It is safe to call from any goroutine.
+func (s *TextureSource) Push(width, height int, pixels []byte) {
+	n := width * height * 4
+	if width <= 0 || height <= 0 || len(pixels) < n {
+		return
+	}
+	s.mu.Lock()
+	if cap(s.back) < n {
+		s.back = make([]byte, n)
+	}
+	s.back = s.back[:n]
+	copy(s.back, pixels)
+	s.push(width, height)
+}
+
+// PushImage copies an image into the back buffer as Push does, converting
+// it when it is not an image.NRGBA
+func (s *TextureSource) PushImage(img image.Image) {
+	b := img.Bounds()
+	if n, ok := img.(*image.NRGBA); ok && n.Stride == 4*b.Dx() {
+		s.Push(b.Dx(), b.Dy(), n.Pix[n.PixOffset(b.Min.X, b.Min.Y):])
+		return
+	}
+	width, height := b.Dx(), b.Dy()
+	if width <= 0 || height <= 0 {
+		return
+	}
+	s.mu.Lock()
+	if cap(s.back) < width*height*4 {
+		s.back = make([]byte, width*height*4)
+	}
+	dst := &image.NRGBA{Pix: s.back[:width*height*4], Stride: 4 * width, Rect: image.Rect(0, 0, width, height)}
+	draw.Draw(dst, dst.Rect, img, b.Min, draw.Src)
+	s.back = dst.Pix
+	s.push(width, height)
+}
+
+// push marks the back buffer, already filled, as the latest frame and
+// unlocks the source before waking its window
+func (s *TextureSource) push(width, height int) {
+	s.width, s.height = width, height
+	s.pending = true
+	s.frames++
+	wake := s.wake
+	s.mu.Unlock()
+	if wake != nil {
+		wake()
+	}
+}
+
+// Pending reports whether a frame was pushed that Update has not swapped in
+func (s *TextureSource) Pending() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pending
+}
+
+// Update swaps the latest frame pushed into the texture, reporting whether
+// there was one. It must be called on the UI goroutine, between frames.
+func (s *TextureSource) Update() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.pending {
+		return false
+	}
+	t := s.texture
+	t.Pixels, s.back = s.back, t.Pixels
+	t.Width, t.Height = s.width, s.height
+	t.Invalidate()
+	s.pending = false
+	s.shown++
+	return true
+}
+
+// Texture returns the texture frames are swapped into
+func (s *TextureSource) Texture() *Texture {
+	return s.texture
+}
+
+// Frames returns the number of frames pushed and the number shown, which
+// falls behind when frames are pushed faster than they are drawn
+func (s *TextureSource) Frames() (pushed, shown int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.frames, s.shown
+}
@@ -0,0 +1,96 @@
+package render
+
+import (
+	"sync"
+)
+
+// disposed holds textures whose GPU copies are waiting to be freed by a renderer
+var disposed = struct {
+	sync.Mutex
+	textures []*Texture
+}{}
+
+// Format describes the pixel layout of a texture
+type Format int
+
+const (
+	// FormatAlpha has one byte of coverage per pixel, drawn as the tint color
+	FormatAlpha Format = iota
+	// FormatRGBA has four bytes per pixel of non-premultiplied color
+	FormatRGBA
+)
+
+// Filter selects how a texture is sampled when scaled
+type Filter int
+
+const (
+	// FilterLinear interpolates between neighbouring pixels
+	FilterLinear Filter = iota
+	// FilterNearest samples the closest pixel, keeping edges sharp
+	FilterNearest
+)
+
+// Texture is CPU side image data that renderers upload to the GPU the first
+// time it is drawn and again after each call to Invalidate. Changes to the
+// filter take effect the next time it is drawn. Textures draw lists are
+// rendered into with DrawList.RenderTexture need no pixels; the GL renderer
+// keeps what it renders on the GPU.
+type Texture struct {
+	Width, Height int
+	Format        Format
+	Filter        Filter
+	// Premultiplied is set for textures whose colors are multiplied by
+	// their alpha, such as those draw lists are rendered into
+	Premultiplied bool
+	Pixels        []byte
+	// version counts changes to Pixels so renderers know when to re-upload
+	version int
+	// GL state owned by the renderer
+	id       uint32
+	uploaded int
+	glWidth  int
+	glHeight int
+	glFilter int32
+	// fbo renders into the texture when draw lists are rendered into it,
+	// and depth is the depth and stencil buffer of those rendered with GL
+	fbo   uint32
+	depth uint32
+}
+
+// NewTexture creates a texture from pixel data in the given format
+func NewTexture(width, height int, format Format, pixels []byte) *Texture {
+	return &Texture{
+		Width:   width,
+		Height:  height,
+		Format:  format,
+		Pixels:  pixels,
+		version: 1,
+	}
+}
+
+// Invalidate marks the pixels as changed so they are uploaded again. Call it
+// after modifying Pixels or changing the size.
+func (t *Texture) Invalidate() {
+	t.version++
+}
+
+// Version returns a counter that changes whenever the pixels are invalidated
+func (t *Texture) Version() int {
+	return t.version
+}
+
+// Dispose frees the GPU copy of the texture at the end of the next frame.
+// The texture may still be drawn again afterwards, which uploads it anew.
+func (t *Texture) Dispose() {
+	disposed.Lock()
+	disposed.textures = append(disposed.textures, t)
+	disposed.Unlock()
+}
+
+// BytesPerPixel returns the size of one pixel in the texture's format
+func (t *Texture) BytesPerPixel() int {
+	if t.Format == FormatAlpha {
+		return 1
+	}
+	return 4
+}
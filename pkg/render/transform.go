@@ -0,0 +1,141 @@
+package render
+
+import (
+	"math"
+)
+
+// Matrix is a 2D affine transform mapping a point (x, y) to
+// (A*x + C*y + E, B*x + D*y + F). The zero Matrix collapses everything to
+// the origin; start from Identity.
+type Matrix struct {
+	A, B, C, D, E, F float32
+}
+
+// Identity returns the transform that leaves points where they are
+func Identity() Matrix {
+	return Matrix{A: 1, D: 1}
+}
+
+// Translate returns a transform moving points by (x, y)
+func Translate(x, y float32) Matrix {
+	return Matrix{A: 1, D: 1, E: x, F: y}
+}
+
+// Scale returns a transform scaling points from the origin by sx
+// horizontally and sy vertically
+func Scale(sx, sy float32) Matrix {
+	return Matrix{A: sx, D: sy}
+}
+
+// Rotate returns a transform rotating points about the origin by an angle in
+// radians, clockwise on screen where y points down
+func Rotate(angle float32) Matrix {
+	sin, cos := math.Sincos(float64(angle))
+	return Matrix{A: float32(cos), B: float32(sin), C: float32(-sin), D: float32(cos)}
+}
+
+// Then returns the transform applying m followed by n
+func (m Matrix) Then(n Matrix) Matrix {
+	return Matrix{
+		A: n.A*m.A + n.C*m.B,
+		B: n.B*m.A + n.D*m.B,
+		C: n.A*m.C + n.C*m.D,
+		D: n.B*m.C + n.D*m.D,
+		E: n.A*m.E + n.C*m.F + n.E,
+		F: n.B*m.E + n.D*m.F + n.F,
+	}
+}
+
+// Apply returns the point (x, y) transformed
+func (m Matrix) Apply(x, y float32) (float32, float32) {
+	return m.A*x + m.C*y + m.E, m.B*x + m.D*y + m.F
+}
+
+// Invert returns the transform undoing m, and false if m collapses points
+// onto a line and cannot be undone
+func (m Matrix) Invert() (inv Matrix, ok bool) {
+	det := m.A*m.D - m.B*m.C
+	if det == 0 {
+		return Matrix{}, false
+	}
+	inv = Matrix{
+		A: m.D / det,
+		B: -m.B / det,
+		C: -m.C / det,
+		D: m.A / det,
+	}
+	inv.E = -(inv.A*m.E + inv.C*m.F)
+	inv.F = -(inv.B*m.E + inv.D*m.F)
+	return inv, true
+}
+
+// IsIdentity reports whether the transform leaves points where they are
+func (m Matrix) IsIdentity() bool {
+	return m == Identity()
+}
+
+// Bounds returns the bounding rect (x, y, width, height) of a rect
+// transformed by m
+func (m Matrix) Bounds(rect [4]float32) [4]float32 {
+	x0, y0 := float32(math.MaxFloat32), float32(math.MaxFloat32)
+	x1, y1 := -x0, -y0
+	for _, c := range [4][2]float32{
+		{rect[0], rect[1]},
+		{rect[0] + rect[2], rect[1]},
+		{rect[0] + rect[2], rect[1] + rect[3]},
+		{rect[0], rect[1] + rect[3]},
+	} {
+		x, y := m.Apply(c[0], c[1])
+		x0, y0 = min(x0, x), min(y0, y)
+		x1, y1 = max(x1, x), max(y1, y)
+	}
+	return [4]float32{x0, y0, x1 - x0, y1 - y0}
+}
+
+// transform is an entry of the transform stack
+type transform struct {
+	// matrix is the transform pushed, and full the product of it with those
+	// below it, mapping to window coordinates
+	matrix, full Matrix
+	// first is the index of the first vertex added under the transform
+	first int
+}
+
+// PushTransform transforms everything drawn until the matching PopTransform
+// by m, inside any transforms already pushed. Clip rects pushed under a
+// transform clip to their transformed bounding rect, so they are exact for
+// translations and scales but not for rotations.
+func (d *DrawList) PushTransform(m Matrix) {
+	full := m
+	if n := len(d.transforms); n > 0 {
+		full = m.Then(d.transforms[n-1].full)
+	}
+	d.transforms = append(d.transforms, transform{matrix: m, full: full, first: len(d.Vertices)})
+}
+
+// PopTransform applies the last pushed transform to what was drawn since
+// PushTransform and restores the transform that was active before it
+func (d *DrawList) PopTransform() {
+	n := len(d.transforms)
+	if n == 0 {
+		return
+	}
+	t := d.transforms[n-1]
+	d.transforms = d.transforms[:n-1]
+	if t.matrix.IsIdentity() {
+		return
+	}
+	for i := t.first; i < len(d.Vertices); i++ {
+		v := &d.Vertices[i]
+		v.X, v.Y = t.matrix.Apply(v.X, v.Y)
+	}
+}
+
+// Transform returns the transform from the current drawing coordinates to
+// window coordinates
+func (d *DrawList) Transform() Matrix {
+	if n := len(d.transforms); n > 0 {
+		return d.transforms[n-1].full
+	}
+	return Identity()
+}
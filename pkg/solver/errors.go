@@ -0,0 +1,33 @@
+package solver
+
+import (
+	"errors"
+)
+
+var (
+	// errDuplicateConstraint is returned when a constraint is added twice
+	errDuplicateConstraint = errors.New("duplicate constraint")
+	// errUnknownConstraint is returned when removing a constraint that was not added
+	errUnknownConstraint = errors.New("unknown constraint")
+	// errUnsatisfiable is returned for a required constraint that conflicts
+	// with those already added
+	errUnsatisfiable = errors.New("unsatisfiable constraint")
+	// errDuplicateEdit is returned when a variable is made editable twice
+	errDuplicateEdit = errors.New("duplicate edit variable")
+	// errUnknownEdit is returned for a variable that is not editable
+	errUnknownEdit = errors.New("unknown edit variable")
+	// errRequiredEdit is returned for an edit variable of required strength,
+	// whose suggestions could not give way
+	errRequiredEdit = errors.New("edit variable cannot be required")
+	// errUnbounded is returned when the objective can decrease without limit,
+	// which the solver's own objective never should
+	errUnbounded = errors.New("unbounded objective")
+	// errInternal is returned when the tableau is in a state it should never reach
+	errInternal = errors.New("internal solver error")
+)
+
+// IsUnsatisfiable reports whether an error is from adding a required
+// constraint that conflicts with the others
+func IsUnsatisfiable(err error) bool {
+	return errors.Is(err, errUnsatisfiable)
+}
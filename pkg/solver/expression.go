@@ -0,0 +1,176 @@
+package solver
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Variable is an unknown the solver finds a value for
+type Variable struct {
+	// Name describes the variable in errors and debugging output
+	Name  string
+	value float64
+}
+
+// NewVariable creates a new variable with a name
+func NewVariable(name string) *Variable {
+	return &Variable{Name: name}
+}
+
+// Value returns the value the solver found the last time variables were updated
+func (v *Variable) Value() float64 {
+	return v.value
+}
+
+// Term is a variable multiplied by a coefficient
+type Term struct {
+	Variable    *Variable
+	Coefficient float64
+}
+
+// Expression is a sum of terms and a constant
+type Expression struct {
+	Terms    []Term
+	Constant float64
+}
+
+// Var returns the expression of a variable alone
+func Var(v *Variable) Expression {
+	return Expression{Terms: []Term{{Variable: v, Coefficient: 1}}}
+}
+
+// Const returns the expression of a constant alone
+func Const(c float64) Expression {
+	return Expression{Constant: c}
+}
+
+// Plus returns the sum of two expressions
+func (e Expression) Plus(o Expression) Expression {
+	terms := make([]Term, 0, len(e.Terms)+len(o.Terms))
+	terms = append(append(terms, e.Terms...), o.Terms...)
+	return Expression{Terms: terms, Constant: e.Constant + o.Constant}
+}
+
+// Minus returns the difference of two expressions
+func (e Expression) Minus(o Expression) Expression {
+	return e.Plus(o.Times(-1))
+}
+
+// Add returns the expression with a constant added
+func (e Expression) Add(c float64) Expression {
+	e.Constant += c
+	return e
+}
+
+// Times returns the expression multiplied by a constant
+func (e Expression) Times(k float64) Expression {
+	terms := make([]Term, len(e.Terms))
+	for i, t := range e.Terms {
+		terms[i] = Term{Variable: t.Variable, Coefficient: t.Coefficient * k}
+	}
+	return Expression{Terms: terms, Constant: e.Constant * k}
+}
+
+// Relation is how the two sides of a constraint compare
+type Relation int
+
+const (
+	// LessOrEqual constrains the left side to at most the right
+	LessOrEqual Relation = iota
+	// GreaterOrEqual constrains the left side to at least the right
+	GreaterOrEqual
+	// Equal constrains the sides to be equal
+	Equal
+)
+
+// Strengths order the constraints that cannot all be satisfied. A required
+// constraint must hold, and the solver satisfies stronger constraints before
+// weaker ones, however many of the weaker there are.
+const (
+	Required float64 = 1000*1000*1000 + 1000*1000 + 1000
+	Strong   float64 = 1000 * 1000
+	Medium   float64 = 1000
+	Weak     float64 = 1
+)
+
+// Constraint is a linear relation between expressions with a strength
+type Constraint struct {
+	// expression is compared to zero
+	expression Expression
+	relation   Relation
+	strength   float64
+}
+
+// NewConstraint creates a constraint relating two expressions with a
+// strength, which is clipped to Required
+func NewConstraint(lhs Expression, relation Relation, rhs Expression, strength float64) *Constraint {
+	return &Constraint{
+		expression: reduce(lhs.Minus(rhs)),
+		relation:   relation,
+		strength:   min(max(strength, 0), Required),
+	}
+}
+
+// Strength returns the strength of the constraint
+func (c *Constraint) Strength() float64 {
+	return c.strength
+}
+
+// String formats the constraint with its sides moved to the left, such as
+// "2*width - height >= 0 (strong)"
+func (c *Constraint) String() string {
+	var b strings.Builder
+	for i, t := range c.expression.Terms {
+		k := t.Coefficient
+		switch {
+		case i > 0 && k < 0:
+			b.WriteString(" - ")
+			k = -k
+		case i > 0:
+			b.WriteString(" + ")
+		}
+		if k != 1 {
+			b.WriteString(strconv.FormatFloat(k, 'g', -1, 64) + "*")
+		}
+		b.WriteString(t.Variable.Name)
+	}
+	if k := c.expression.Constant; k != 0 || len(c.expression.Terms) == 0 {
+		if len(c.expression.Terms) > 0 {
+			if k < 0 {
+				b.WriteString(" - ")
+				k = -k
+			} else {
+				b.WriteString(" + ")
+			}
+		}
+		b.WriteString(strconv.FormatFloat(k, 'g', -1, 64))
+	}
+	b.WriteString([...]string{" <= 0", " >= 0", " == 0"}[c.relation])
+	switch c.strength {
+	case Required:
+	case Strong:
+		b.WriteString(" (strong)")
+	case Medium:
+		b.WriteString(" (medium)")
+	case Weak:
+		b.WriteString(" (weak)")
+	default:
+		b.WriteString(" (" + strconv.FormatFloat(c.strength, 'g', -1, 64) + ")")
+	}
+	return b.String()
+}
+
+// reduce combines the terms of an expression with the same variable
+func reduce(e Expression) Expression {
+	index := make(map[*Variable]int, len(e.Terms))
+	var terms []Term
+	for _, t := range e.Terms {
+		if i, ok := index[t.Variable]; ok {
+			terms[i].Coefficient += t.Coefficient
+			continue
+		}
+		index[t.Variable] = len(terms)
+		terms = append(terms, t)
+	}
+	return Expression{Terms: terms, Constant: e.Constant}
+}
@@ -0,0 +1,149 @@
+package solver
+
+import (
+	"slices"
+)
+
+// epsilon is how close to zero a coefficient is treated as zero
+const epsilon = 1.0e-8
+
+// nearZero reports whether a value is zero within rounding error
+func nearZero(v float64) bool {
+	return v < epsilon && v > -epsilon
+}
+
+// symbolKind is the role of a symbol in the tableau
+type symbolKind int
+
+const (
+	invalidSymbol symbolKind = iota
+	// externalSymbol stands for one of the user's variables
+	externalSymbol
+	// slackSymbol turns an inequality into an equation
+	slackSymbol
+	// errorSymbol measures how far a constraint that is not required is
+	// from being satisfied
+	errorSymbol
+	// dummySymbol marks a required equation, never entering the basis
+	dummySymbol
+)
+
+// symbol is a column of the tableau. Symbols are ordered by id, so the
+// solver makes the same choices on every run.
+type symbol struct {
+	id   int
+	kind symbolKind
+}
+
+// valid reports whether the symbol was created by the solver
+func (s symbol) valid() bool {
+	return s.kind != invalidSymbol
+}
+
+// pivotable reports whether a symbol may enter the basis in place of another
+func (s symbol) pivotable() bool {
+	return s.kind == slackSymbol || s.kind == errorSymbol
+}
+
+// row is a row of the tableau, a basic symbol's value as a constant plus a
+// linear combination of parametric symbols
+type row struct {
+	constant float64
+	cells    map[symbol]float64
+}
+
+// newRow creates a row holding a constant
+func newRow(constant float64) *row {
+	return &row{constant: constant, cells: make(map[symbol]float64)}
+}
+
+// copy returns a copy of the row
+func (r *row) copy() *row {
+	c := newRow(r.constant)
+	for s, v := range r.cells {
+		c.cells[s] = v
+	}
+	return c
+}
+
+// symbols returns the row's symbols in order
+func (r *row) symbols() []symbol {
+	return sortedSymbols(r.cells)
+}
+
+// add adds a value to the constant and returns the new constant
+func (r *row) add(value float64) float64 {
+	r.constant += value
+	return r.constant
+}
+
+// insertSymbol adds a multiple of a symbol, removing it if it cancels out
+func (r *row) insertSymbol(s symbol, coefficient float64) {
+	if c := r.cells[s] + coefficient; nearZero(c) {
+		delete(r.cells, s)
+	} else {
+		r.cells[s] = c
+	}
+}
+
+// insertRow adds a multiple of another row
+func (r *row) insertRow(o *row, coefficient float64) {
+	r.constant += o.constant * coefficient
+	for s, c := range o.cells {
+		r.insertSymbol(s, c*coefficient)
+	}
+}
+
+// remove removes a symbol
+func (r *row) remove(s symbol) {
+	delete(r.cells, s)
+}
+
+// reverseSign negates the constant and every coefficient
+func (r *row) reverseSign() {
+	r.constant = -r.constant
+	for s, c := range r.cells {
+		r.cells[s] = -c
+	}
+}
+
+// solveFor rearranges the row, which equals zero, to give the value of one
+// of its symbols, which is removed
+func (r *row) solveFor(s symbol) {
+	k := -1 / r.cells[s]
+	delete(r.cells, s)
+	r.constant *= k
+	for o, c := range r.cells {
+		r.cells[o] = c * k
+	}
+}
+
+// solveForPair rearranges the row giving the value of lhs to give the value
+// of rhs instead
+func (r *row) solveForPair(lhs, rhs symbol) {
+	r.insertSymbol(lhs, -1)
+	r.solveFor(rhs)
+}
+
+// coefficient returns the coefficient of a symbol, zero if absent
+func (r *row) coefficient(s symbol) float64 {
+	return r.cells[s]
+}
+
+// substitute replaces a symbol with the row giving its value
+func (r *row) substitute(s symbol, o *row) {
+	if c, ok := r.cells[s]; ok {
+		delete(r.cells, s)
+		r.insertRow(o, c)
+	}
+}
+
+// sortedSymbols returns the keys of a map of symbols in order
+func sortedSymbols[V any](m map[symbol]V) []symbol {
+	keys := make([]symbol, 0, len(m))
+	for s := range m {
+		keys = append(keys, s)
+	}
+	slices.SortFunc(keys, func(a, b symbol) int { return a.id - b.id })
+	return keys
+}
@@ -0,0 +1,479 @@
+// Package solver finds values for variables related by linear equations and
+// inequalities, using the incremental Cassowary simplex algorithm that
+// constraint based layout is built on. Constraints have strengths: required
+// constraints always hold, and where the rest conflict the stronger win.
+// Edit variables take suggested values, such as the size of a window as it
+// is resized, and the solution is updated from the last one rather than
+// solved again from scratch.
+package solver
+
+import (
+	"fmt"
+	"math"
+)
+
+// tag records the symbols added to the tableau for a constraint
+type tag struct {
+	marker, other symbol
+}
+
+// edit is the state of an edit variable
+type edit struct {
+	tag        tag
+	constraint *Constraint
+	constant   float64
+}
+
+// Solver solves a set of constraints. A solver is not safe for concurrent use.
+type Solver struct {
+	constraints map[*Constraint]tag
+	rows        map[symbol]*row
+	vars        map[*Variable]symbol
+	edits       map[*Variable]*edit
+	// infeasible are the basic symbols whose rows went negative after an
+	// edit, repaired by the dual simplex method
+	infeasible []symbol
+	objective  *row
+	// artificial is the objective used while adding a constraint that has
+	// no symbol to solve for
+	artificial *row
+	lastID     int
+}
+
+// New creates a solver with no constraints
+func New() *Solver {
+	return &Solver{
+		constraints: make(map[*Constraint]tag),
+		rows:        make(map[symbol]*row),
+		vars:        make(map[*Variable]symbol),
+		edits:       make(map[*Variable]*edit),
+		objective:   newRow(0),
+	}
+}
+
+// AddConstraint adds a constraint. A required constraint that conflicts with
+// the required constraints already added is not added, and the error
+// reports it, which IsUnsatisfiable recognises.
+func (s *Solver) AddConstraint(c *Constraint) (err error) {
+	if _, ok := s.constraints[c]; ok {
+		return errDuplicateConstraint
+	}
+	var t tag
+	r := s.createRow(c, &t)
+	subject := chooseSubject(r, t)
+	// A row of only dummy symbols is a required equation between constants,
+	// which holds if the constant is zero
+	if !subject.valid() && allDummies(r) {
+		if !nearZero(r.constant) {
+			s.removeEffects(c, t)
+			return fmt.Errorf("%w: %s", errUnsatisfiable, c)
+		}
+		subject = t.marker
+	}
+	if !subject.valid() {
+		var satisfied bool
+		if satisfied, err = s.addWithArtificialVariable(r); err != nil {
+			return
+		}
+		if !satisfied {
+			// The row stays in the tableau through its slack symbol; take it
+			// out as a constraint being removed would be
+			s.constraints[c] = t
+			if err = s.RemoveConstraint(c); err != nil {
+				return
+			}
+			return fmt.Errorf("%w: %s", errUnsatisfiable, c)
+		}
+	} else {
+		r.solveFor(subject)
+		s.substitute(subject, r)
+		s.rows[subject] = r
+	}
+	s.constraints[c] = t
+	return s.optimize(s.objective)
+}
+
+// RemoveConstraint removes a constraint that was added
+func (s *Solver) RemoveConstraint(c *Constraint) (err error) {
+	t, ok := s.constraints[c]
+	if !ok {
+		return errUnknownConstraint
+	}
+	delete(s.constraints, c)
+	s.removeEffects(c, t)
+	if _, ok = s.rows[t.marker]; ok {
+		delete(s.rows, t.marker)
+	} else {
+		leaving, r := s.markerLeavingRow(t.marker)
+		if r == nil {
+			return errInternal
+		}
+		delete(s.rows, leaving)
+		r.solveForPair(leaving, t.marker)
+		s.substitute(t.marker, r)
+	}
+	return s.optimize(s.objective)
+}
+
+// HasConstraint reports whether a constraint was added
+func (s *Solver) HasConstraint(c *Constraint) bool {
+	_, ok := s.constraints[c]
+	return ok
+}
+
+// AddEditVariable makes a variable take the values suggested for it with a
+// strength below Required, starting at zero
+func (s *Solver) AddEditVariable(v *Variable, strength float64) (err error) {
+	if _, ok := s.edits[v]; ok {
+		return errDuplicateEdit
+	}
+	if strength = min(max(strength, 0), Required); strength == Required {
+		return errRequiredEdit
+	}
+	c := NewConstraint(Var(v), Equal, Const(0), strength)
+	if err = s.AddConstraint(c); err != nil {
+		return
+	}
+	s.edits[v] = &edit{tag: s.constraints[c], constraint: c}
+	return
+}
+
+// RemoveEditVariable stops a variable from taking suggested values
+func (s *Solver) RemoveEditVariable(v *Variable) (err error) {
+	e, ok := s.edits[v]
+	if !ok {
+		return errUnknownEdit
+	}
+	if err = s.RemoveConstraint(e.constraint); err != nil {
+		return
+	}
+	delete(s.edits, v)
+	return
+}
+
+// HasEditVariable reports whether a variable takes suggested values
+func (s *Solver) HasEditVariable(v *Variable) bool {
+	_, ok := s.edits[v]
+	return ok
+}
+
+// SuggestValue suggests a value for an edit variable, which it takes as far
+// as stronger constraints allow
+func (s *Solver) SuggestValue(v *Variable, value float64) (err error) {
+	e, ok := s.edits[v]
+	if !ok {
+		return errUnknownEdit
+	}
+	delta := value - e.constant
+	e.constant = value
+	// The edit's error symbols are basic or parametric; update whichever rows
+	// they appear in and repair those that became infeasible
+	if r, ok := s.rows[e.tag.marker]; ok {
+		if r.add(-delta) < 0 {
+			s.infeasible = append(s.infeasible, e.tag.marker)
+		}
+		return s.dualOptimize()
+	}
+	if r, ok := s.rows[e.tag.other]; ok {
+		if r.add(delta) < 0 {
+			s.infeasible = append(s.infeasible, e.tag.other)
+		}
+		return s.dualOptimize()
+	}
+	for _, basic := range sortedSymbols(s.rows) {
+		r := s.rows[basic]
+		if c := r.coefficient(e.tag.marker); c != 0 && r.add(delta*c) < 0 && basic.kind != externalSymbol {
+			s.infeasible = append(s.infeasible, basic)
+		}
+	}
+	return s.dualOptimize()
+}
+
+// UpdateVariables stores the solution in the variables, read with Value
+func (s *Solver) UpdateVariables() {
+	for v, sym := range s.vars {
+		if r, ok := s.rows[sym]; ok {
+			v.value = r.constant
+		} else {
+			v.value = 0
+		}
+	}
+}
+
+// newSymbol creates a symbol of a kind
+func (s *Solver) newSymbol(kind symbolKind) symbol {
+	s.lastID++
+	return symbol{id: s.lastID, kind: kind}
+}
+
+// varSymbol returns the symbol of a variable, creating it the first time
+func (s *Solver) varSymbol(v *Variable) symbol {
+	sym, ok := s.vars[v]
+	if !ok {
+		sym = s.newSymbol(externalSymbol)
+		s.vars[v] = sym
+	}
+	return sym
+}
+
+// createRow creates the row of a constraint with the current basic variables
+// substituted, adding the slack and error symbols it needs and their cost
+// to the objective
+func (s *Solver) createRow(c *Constraint, t *tag) *row {
+	r := newRow(c.expression.Constant)
+	for _, term := range c.expression.Terms {
+		if nearZero(term.Coefficient) {
+			continue
+		}
+		sym := s.varSymbol(term.Variable)
+		if basic, ok := s.rows[sym]; ok {
+			r.insertRow(basic, term.Coefficient)
+		} else {
+			r.insertSymbol(sym, term.Coefficient)
+		}
+	}
+	switch c.relation {
+	case LessOrEqual, GreaterOrEqual:
+		coefficient := 1.0
+		if c.relation == GreaterOrEqual {
+			coefficient = -1
+		}
+		t.marker = s.newSymbol(slackSymbol)
+		r.insertSymbol(t.marker, coefficient)
+		if c.strength < Required {
+			t.other = s.newSymbol(errorSymbol)
+			r.insertSymbol(t.other, -coefficient)
+			s.objective.insertSymbol(t.other, c.strength)
+		}
+	case Equal:
+		if c.strength < Required {
+			t.marker = s.newSymbol(errorSymbol)
+			t.other = s.newSymbol(errorSymbol)
+			r.insertSymbol(t.marker, -1)
+			r.insertSymbol(t.other, 1)
+			s.objective.insertSymbol(t.marker, c.strength)
+			s.objective.insertSymbol(t.other, c.strength)
+		} else {
+			t.marker = s.newSymbol(dummySymbol)
+			r.insertSymbol(t.marker, 1)
+		}
+	}
+	if r.constant < 0 {
+		r.reverseSign()
+	}
+	return r
+}
+
+// chooseSubject returns the symbol to solve a new row for: an external
+// symbol if there is one, otherwise a new slack or error symbol with a
+// negative coefficient, otherwise none
+func chooseSubject(r *row, t tag) symbol {
+	for _, sym := range r.symbols() {
+		if sym.kind == externalSymbol {
+			return sym
+		}
+	}
+	if t.marker.pivotable() && r.coefficient(t.marker) < 0 {
+		return t.marker
+	}
+	if t.other.pivotable() && r.coefficient(t.other) < 0 {
+		return t.other
+	}
+	return symbol{}
+}
+
+// allDummies reports whether a row has only dummy symbols
+func allDummies(r *row) bool {
+	for sym := range r.cells {
+		if sym.kind != dummySymbol {
+			return false
+		}
+	}
+	return true
+}
+
+// addWithArtificialVariable adds a row with no subject to solve for by
+// minimising an artificial variable standing for it, reporting whether the
+// row could be satisfied
+func (s *Solver) addWithArtificialVariable(r *row) (satisfied bool, err error) {
+	art := s.newSymbol(slackSymbol)
+	s.rows[art] = r.copy()
+	s.artificial = r.copy()
+	if err = s.optimize(s.artificial); err != nil {
+		return
+	}
+	satisfied = nearZero(s.artificial.constant)
+	s.artificial = nil
+	if basic, ok := s.rows[art]; ok {
+		delete(s.rows, art)
+		if len(basic.cells) == 0 {
+			return
+		}
+		entering := anyPivotableSymbol(basic)
+		if !entering.valid() {
+			return false, nil
+		}
+		basic.solveForPair(art, entering)
+		s.substitute(entering, basic)
+		s.rows[entering] = basic
+	}
+	for _, basic := range s.rows {
+		basic.remove(art)
+	}
+	s.objective.remove(art)
+	return
+}
+
+// anyPivotableSymbol returns the first slack or error symbol of a row
+func anyPivotableSymbol(r *row) symbol {
+	for _, sym := range r.symbols() {
+		if sym.pivotable() {
+			return sym
+		}
+	}
+	return symbol{}
+}
+
+// substitute replaces a symbol with the row giving its value in every row
+// and the objectives, noting rows that became infeasible
+func (s *Solver) substitute(sym symbol, r *row) {
+	for _, basic := range sortedSymbols(s.rows) {
+		o := s.rows[basic]
+		o.substitute(sym, r)
+		if basic.kind != externalSymbol && o.constant < 0 {
+			s.infeasible = append(s.infeasible, basic)
+		}
+	}
+	s.objective.substitute(sym, r)
+	if s.artificial != nil {
+		s.artificial.substitute(sym, r)
+	}
+}
+
+// optimize minimises an objective with the primal simplex method
+func (s *Solver) optimize(objective *row) error {
+	for {
+		entering := enteringSymbol(objective)
+		if !entering.valid() {
+			return nil
+		}
+		leaving, r := s.leavingRow(entering)
+		if r == nil {
+			return errUnbounded
+		}
+		delete(s.rows, leaving)
+		r.solveForPair(leaving, entering)
+		s.substitute(entering, r)
+		s.rows[entering] = r
+	}
+}
+
+// dualOptimize makes the infeasible rows feasible again with the dual
+// simplex method, keeping the objective optimal
+func (s *Solver) dualOptimize() error {
+	for len(s.infeasible) > 0 {
+		leaving := s.infeasible[len(s.infeasible)-1]
+		s.infeasible = s.infeasible[:len(s.infeasible)-1]
+		r, ok := s.rows[leaving]
+		if !ok || nearZero(r.constant) || r.constant >= 0 {
+			continue
+		}
+		entering := s.dualEnteringSymbol(r)
+		if !entering.valid() {
+			return errInternal
+		}
+		delete(s.rows, leaving)
+		r.solveForPair(leaving, entering)
+		s.substitute(entering, r)
+		s.rows[entering] = r
+	}
+	return nil
+}
+
+// enteringSymbol returns the first symbol that decreases the objective as
+// it increases, none when the objective is minimal
+func enteringSymbol(objective *row) symbol {
+	for _, sym := range objective.symbols() {
+		if sym.kind != dummySymbol && objective.cells[sym] < 0 {
+			return sym
+		}
+	}
+	return symbol{}
+}
+
+// dualEnteringSymbol returns the symbol to enter the basis in place of an
+// infeasible row's, keeping the objective optimal
+func (s *Solver) dualEnteringSymbol(r *row) (entering symbol) {
+	ratio := math.MaxFloat64
+	for _, sym := range r.symbols() {
+		if c := r.cells[sym]; c > 0 && sym.kind != dummySymbol {
+			if q := s.objective.coefficient(sym) / c; q < ratio {
+				ratio, entering = q, sym
+			}
+		}
+	}
+	return
+}
+
+// leavingRow returns the basic symbol and row that limit how far the
+// entering symbol can increase, nil if nothing does
+func (s *Solver) leavingRow(entering symbol) (leaving symbol, r *row) {
+	ratio := math.MaxFloat64
+	for _, basic := range sortedSymbols(s.rows) {
+		if basic.kind == externalSymbol {
+			continue
+		}
+		o := s.rows[basic]
+		if c := o.coefficient(entering); c < 0 {
+			if q := -o.constant / c; q < ratio {
+				ratio, leaving, r = q, basic, o
+			}
+		}
+	}
+	return
+}
+
+// markerLeavingRow returns the row to pivot out for a constraint's marker
+// symbol being removed: the restricted row that limits it most, otherwise
+// an external row holding it
+func (s *Solver) markerLeavingRow(marker symbol) (leaving symbol, r *row) {
+	first, second := math.MaxFloat64, math.MaxFloat64
+	var firstSym, secondSym, thirdSym symbol
+	for _, basic := range sortedSymbols(s.rows) {
+		o := s.rows[basic]
+		c := o.coefficient(marker)
+		switch {
+		case c == 0:
+		case basic.kind == externalSymbol:
+			thirdSym = basic
+		case c < 0:
+			if q := -o.constant / c; q < first {
+				first, firstSym = q, basic
+			}
+		default:
+			if q := o.constant / c; q < second {
+				second, secondSym = q, basic
+			}
+		}
+	}
+	for _, sym := range []symbol{firstSym, secondSym, thirdSym} {
+		if sym.valid() {
+			return sym, s.rows[sym]
+		}
+	}
+	return
+}
+
+// removeEffects takes a constraint's error symbols out of the objective
+func (s *Solver) removeEffects(c *Constraint, t tag) {
+	for _, sym := range []symbol{t.marker, t.other} {
+		if sym.kind != errorSymbol {
+			continue
+		}
+		if r, ok := s.rows[sym]; ok {
+			s.objective.insertRow(r, -c.strength)
+		} else {
+			s.objective.insertSymbol(sym, -c.strength)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package solver
+
+import (
+	"math"
+	"testing"
+)
+
+// near reports whether two values are equal to within rounding
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		name string
+		// build adds the constraints relating x and y
+		build func(x, y *Variable) []*Constraint
+		x, y  float64
+	}{
+		{
+			name: "equal to constants",
+			build: func(x, y *Variable) []*Constraint {
+				return []*Constraint{
+					NewConstraint(Var(x), Equal, Const(10), Required),
+					NewConstraint(Var(y), Equal, Var(x).Times(2).Add(5), Required),
+				}
+			},
+			x: 10, y: 25,
+		},
+		{
+			name: "inequality bounds a weak preference",
+			build: func(x, y *Variable) []*Constraint {
+				return []*Constraint{
+					NewConstraint(Var(x), GreaterOrEqual, Const(50), Required),
+					NewConstraint(Var(x), Equal, Const(20), Weak),
+					NewConstraint(Var(y), LessOrEqual, Const(30), Required),
+					NewConstraint(Var(y), Equal, Const(80), Weak),
+				}
+			},
+			x: 50, y: 30,
+		},
+		{
+			name: "stronger constraint wins",
+			build: func(x, y *Variable) []*Constraint {
+				return []*Constraint{
+					NewConstraint(Var(x), Equal, Const(100), Strong),
+					NewConstraint(Var(x), Equal, Const(0), Medium),
+					NewConstraint(Var(y), Equal, Const(1), Weak),
+					NewConstraint(Var(y), Equal, Const(2), Medium),
+				}
+			},
+			x: 100, y: 2,
+		},
+		{
+			name: "sum splits evenly",
+			build: func(x, y *Variable) []*Constraint {
+				return []*Constraint{
+					NewConstraint(Var(x).Plus(Var(y)), Equal, Const(300), Required),
+					NewConstraint(Var(x), Equal, Var(y), Required),
+				}
+			},
+			x: 150, y: 150,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New()
+			x, y := NewVariable("x"), NewVariable("y")
+			for _, c := range tt.build(x, y) {
+				if err := s.AddConstraint(c); err != nil {
+					t.Fatalf("AddConstraint(%s): %v", c, err)
+				}
+			}
+			s.UpdateVariables()
+			if !near(x.Value(), tt.x) || !near(y.Value(), tt.y) {
+				t.Errorf("x, y = %g, %g, want %g, %g", x.Value(), y.Value(), tt.x, tt.y)
+			}
+		})
+	}
+}
+
+func TestUnsatisfiable(t *testing.T) {
+	s := New()
+	x := NewVariable("x")
+	if err := s.AddConstraint(NewConstraint(Var(x), Equal, Const(1), Required)); err != nil {
+		t.Fatal(err)
+	}
+	conflict := NewConstraint(Var(x), Equal, Const(2), Required)
+	if err := s.AddConstraint(conflict); !IsUnsatisfiable(err) {
+		t.Errorf("conflicting required constraint: got %v, want unsatisfiable", err)
+	}
+	if s.HasConstraint(conflict) {
+		t.Error("conflicting constraint was added")
+	}
+}
+
+func TestConstraintErrors(t *testing.T) {
+	s := New()
+	x := NewVariable("x")
+	c := NewConstraint(Var(x), Equal, Const(1), Strong)
+	if err := s.AddConstraint(c); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddConstraint(c); err != errDuplicateConstraint {
+		t.Errorf("adding twice: got %v, want %v", err, errDuplicateConstraint)
+	}
+	if err := s.RemoveConstraint(c); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RemoveConstraint(c); err != errUnknownConstraint {
+		t.Errorf("removing twice: got %v, want %v", err, errUnknownConstraint)
+	}
+	if err := s.AddEditVariable(x, Required); err != errRequiredEdit {
+		t.Errorf("required edit: got %v, want %v", err, errRequiredEdit)
+	}
+	if err := s.SuggestValue(x, 1); err != errUnknownEdit {
+		t.Errorf("suggesting without an edit: got %v, want %v", err, errUnknownEdit)
+	}
+}
+
+func TestSuggestValue(t *testing.T) {
+	s := New()
+	width, left, right := NewVariable("width"), NewVariable("left"), NewVariable("right")
+	for _, c := range []*Constraint{
+		NewConstraint(Var(left), Equal, Const(0), Required),
+		NewConstraint(Var(right), Equal, Var(left).Plus(Var(width)), Required),
+		NewConstraint(Var(width), GreaterOrEqual, Const(100), Required),
+	} {
+		if err := s.AddConstraint(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.AddEditVariable(width, Strong); err != nil {
+		t.Fatal(err)
+	}
+	// Suggestions below the required minimum give way to it
+	for _, tt := range []struct{ suggest, right float64 }{
+		{400, 400},
+		{250, 250},
+		{50, 100},
+		{640, 640},
+	} {
+		if err := s.SuggestValue(width, tt.suggest); err != nil {
+			t.Fatal(err)
+		}
+		s.UpdateVariables()
+		if !near(right.Value(), tt.right) {
+			t.Errorf("suggest %g: right = %g, want %g", tt.suggest, right.Value(), tt.right)
+		}
+	}
+	if err := s.RemoveEditVariable(width); err != nil {
+		t.Fatal(err)
+	}
+	if s.HasEditVariable(width) {
+		t.Error("edit variable still present after removal")
+	}
+}
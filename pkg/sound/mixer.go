@@ -0,0 +1,217 @@
+package sound
+
+import (
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+
+	"lol.mleku.dev/chk"
+)
+
+const (
+	// chunk is the number of frames mixed and written at a time
+	chunk = Rate / 100
+	// lead is how far ahead of the sound heard the mixer writes, which
+	// bounds the delay before a sound starts
+	lead = 40 * time.Millisecond
+	// idle is how long the output stays open after the last sound ends
+	idle = 2 * time.Second
+	// maxVoices is the number of samples playing at once, beyond which the
+	// oldest stop
+	maxVoices = 16
+)
+
+// Mixer mixes the samples playing into one stream written to an output. The
+// output is opened as the first sound plays and closed once nothing has
+// played for a while. Mixers are safe to use from any goroutine.
+type Mixer struct {
+	open func() (io.WriteCloser, error)
+	mu   sync.Mutex
+	// voices are the samples playing, and running is set while a goroutine
+	// writes them to the output
+	voices  []*voice
+	running bool
+	volume  float32
+	muted   bool
+	// failed is set once the output could not be opened, after which
+	// sounds are dropped
+	failed bool
+}
+
+// voice is a sample playing, at a frame position stepping through it at
+// the ratio of its rate to the mixer's
+type voice struct {
+	sample *Sample
+	at     float64
+	step   float64
+	volume float32
+}
+
+// NewMixer creates a mixer writing to the output open returns, which takes
+// interleaved stereo frames of signed 16 bit little endian samples at Rate
+func NewMixer(open func() (io.WriteCloser, error)) *Mixer {
+	return &Mixer{open: open, volume: 1}
+}
+
+// Play plays a sample at full volume, returning at once
+func (m *Mixer) Play(s *Sample) {
+	m.PlayVolume(s, 1)
+}
+
+// PlayVolume plays a sample at a volume from 0 to 1, returning at once
+func (m *Mixer) PlayVolume(s *Sample, volume float32) {
+	if s == nil || s.Rate <= 0 || s.Channels <= 0 || len(s.Data) == 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.muted || m.failed {
+		return
+	}
+	if len(m.voices) >= maxVoices {
+		m.voices = m.voices[1:]
+	}
+	m.voices = append(m.voices, &voice{
+		sample: s,
+		step:   float64(s.Rate) / Rate,
+		volume: volume,
+	})
+	if !m.running {
+		m.running = true
+		go m.run()
+	}
+}
+
+// SetVolume sets the volume from 0 to 1 every sound is played at
+func (m *Mixer) SetVolume(volume float32) {
+	m.mu.Lock()
+	m.volume = min(max(volume, 0), 1)
+	m.mu.Unlock()
+}
+
+// Volume returns the volume every sound is played at
+func (m *Mixer) Volume() float32 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.volume
+}
+
+// SetMuted stops the sounds playing and drops those played while muted, for
+// a preference turning sounds off
+func (m *Mixer) SetMuted(muted bool) {
+	m.mu.Lock()
+	m.muted = muted
+	if muted {
+		m.voices = nil
+	}
+	m.mu.Unlock()
+}
+
+// Muted reports whether sounds are dropped
+func (m *Mixer) Muted() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.muted
+}
+
+// Stop stops the sounds playing
+func (m *Mixer) Stop() {
+	m.mu.Lock()
+	m.voices = nil
+	m.mu.Unlock()
+}
+
+// run opens the output and writes the mixed sounds to it in real time,
+// closing it once nothing has played for a while
+func (m *Mixer) run() {
+	out, err := m.open()
+	if err != nil {
+		// Systems without a player play no sounds, which is no error
+		if err != ErrUnavailable {
+			chk.E(err)
+		}
+		m.mu.Lock()
+		m.failed, m.running, m.voices = true, false, nil
+		m.mu.Unlock()
+		return
+	}
+	mix := make([]float32, chunk*2)
+	pcm := make([]byte, chunk*4)
+	start := time.Now()
+	var written, quiet time.Duration
+	for {
+		m.mu.Lock()
+		playing := m.mix(mix)
+		if !playing && quiet >= idle {
+			m.running = false
+			m.mu.Unlock()
+			break
+		}
+		m.mu.Unlock()
+		if playing {
+			quiet = 0
+		} else {
+			quiet += time.Second * chunk / Rate
+		}
+		for i, v := range mix {
+			binary.LittleEndian.PutUint16(pcm[i*2:], uint16(int16(min(max(v, -1), 1)*32767)))
+		}
+		if _, err = out.Write(pcm); chk.E(err) {
+			m.mu.Lock()
+			m.failed, m.running, m.voices = true, false, nil
+			m.mu.Unlock()
+			break
+		}
+		// Keep only a little ahead of what is heard, so new sounds start soon
+		written += time.Second * chunk / Rate
+		if ahead := written - time.Since(start); ahead > lead {
+			time.Sleep(ahead - lead)
+		}
+	}
+	chk.E(out.Close())
+}
+
+// mix fills a buffer of stereo frames with the voices playing, dropping
+// those that ended, and reports whether any played. It is called with the
+// mixer locked.
+func (m *Mixer) mix(buf []float32) (playing bool) {
+	clear(buf)
+	playing = len(m.voices) > 0
+	left := m.voices[:0]
+	for _, v := range m.voices {
+		s := v.sample
+		frames := len(s.Data) / s.Channels
+		gain := v.volume * m.volume
+		for i := 0; i < len(buf); i += 2 {
+			// Resample by interpolating between neighbouring frames
+			j := int(v.at)
+			if j >= frames {
+				break
+			}
+			f := float32(v.at - float64(j))
+			l, r := s.frame(j)
+			if j+1 < frames {
+				nl, nr := s.frame(j + 1)
+				l, r = l+(nl-l)*f, r+(nr-r)*f
+			}
+			buf[i] += l * gain
+			buf[i+1] += r * gain
+			v.at += v.step
+		}
+		if int(v.at) < frames {
+			left = append(left, v)
+		}
+	}
+	m.voices = left
+	return
+}
+
+// frame returns the left and right samples of a frame, the same for mono
+func (s *Sample) frame(i int) (l, r float32) {
+	l = s.Data[i*s.Channels]
+	if s.Channels > 1 {
+		return l, s.Data[i*s.Channels+1]
+	}
+	return l, l
+}
@@ -0,0 +1,65 @@
+package sound
+
+import (
+	"errors"
+	"io"
+	"os/exec"
+	"strconv"
+)
+
+// ErrUnavailable is returned when the system has no player for the mixed
+// sound
+var ErrUnavailable = errors.New("sound: no player available on this system")
+
+// players are the commands tried in turn to play raw stereo PCM at Rate
+// read from their standard input
+var players = [][]string{
+	{"pw-cat", "--playback", "--format=s16", "--rate=" + strconv.Itoa(Rate), "--channels=2", "-"},
+	{"paplay", "--raw", "--format=s16le", "--rate=" + strconv.Itoa(Rate), "--channels=2"},
+	{"aplay", "-q", "-t", "raw", "-f", "S16_LE", "-r", strconv.Itoa(Rate), "-c", "2"},
+	{"play", "-q", "-t", "raw", "-e", "signed", "-b", "16", "-r", strconv.Itoa(Rate), "-c", "2", "-"},
+}
+
+// OpenPlayer starts the first player the system has, returning its input,
+// which stops it when closed. It returns ErrUnavailable when there is none.
+func OpenPlayer() (w io.WriteCloser, err error) {
+	for _, p := range players {
+		if _, err = exec.LookPath(p[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(p[0], p[1:]...)
+		var in io.WriteCloser
+		if in, err = cmd.StdinPipe(); err != nil {
+			return
+		}
+		if err = cmd.Start(); err != nil {
+			return
+		}
+		return &player{WriteCloser: in, cmd: cmd}, nil
+	}
+	return nil, ErrUnavailable
+}
+
+// Available reports whether the system has a player for OpenPlayer
+func Available() bool {
+	for _, p := range players {
+		if _, err := exec.LookPath(p[0]); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// player is the input of a player command, waiting for it to exit once
+// the input is closed
+type player struct {
+	io.WriteCloser
+	cmd *exec.Cmd
+}
+
+// Close closes the player's input, letting it finish the sound it holds
+func (p *player) Close() (err error) {
+	err = p.WriteCloser.Close()
+	go p.cmd.Wait()
+	return
+}
@@ -0,0 +1,121 @@
+// Package sound plays short samples, such as the clicks and alerts themes
+// attach to interactions, without an audio engine. Samples playing at once
+// are mixed in Go into one stream of 16 bit stereo PCM, handed to the
+// platform's own player: pw-cat, paplay or aplay on Linux desktops, or SoX's
+// play where it is installed. Programs wanting another output, such as an
+// audio library of their own, pass it to NewMixer.
+//
+// Where the system lacks a player, sounds are silently dropped and programs
+// carry on without them.
+package sound
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Rate is the number of frames per second mixers play, each a pair of
+// samples for the left and right channels
+const Rate = 44100
+
+// Sample is a short sound held in memory as samples from -1 to 1,
+// interleaved when it has two channels
+type Sample struct {
+	// Rate is the number of frames per second the sound was recorded at
+	Rate int
+	// Channels is 1 for mono and 2 for stereo
+	Channels int
+	Data     []float32
+}
+
+// Duration returns how long the sample plays
+func (s *Sample) Duration() time.Duration {
+	if s == nil || s.Rate <= 0 || s.Channels <= 0 {
+		return 0
+	}
+	frames := len(s.Data) / s.Channels
+	return time.Duration(frames) * time.Second / time.Duration(s.Rate)
+}
+
+// Tone returns a sine wave of a frequency in hertz, lasting the duration at
+// a volume from 0 to 1, fading in and out over a few milliseconds so it
+// starts and stops without clicking
+func Tone(frequency float64, duration time.Duration, volume float32) *Sample {
+	n := int(duration.Seconds() * Rate)
+	s := &Sample{Rate: Rate, Channels: 1, Data: make([]float32, n)}
+	fade := min(Rate/200, n/2)
+	for i := range s.Data {
+		v := volume * float32(math.Sin(2*math.Pi*frequency*float64(i)/Rate))
+		if i < fade {
+			v *= float32(i) / float32(fade)
+		} else if j := n - 1 - i; j < fade {
+			v *= float32(j) / float32(fade)
+		}
+		s.Data[i] = v
+	}
+	return s
+}
+
+// Decay returns the sample faded out exponentially, to a thousandth of its
+// volume by its end, for percussive sounds such as clicks
+func (s *Sample) Decay() *Sample {
+	d := &Sample{Rate: s.Rate, Channels: s.Channels, Data: make([]float32, len(s.Data))}
+	frames := len(s.Data) / max(s.Channels, 1)
+	for i, v := range s.Data {
+		t := float64(i/max(s.Channels, 1)) / float64(max(frames, 1))
+		d.Data[i] = v * float32(math.Exp(-6.9*t))
+	}
+	return d
+}
+
+// Join returns the samples played one after the other, which must share a
+// rate and channels
+func Join(samples ...*Sample) *Sample {
+	j := &Sample{Rate: Rate, Channels: 1}
+	if len(samples) > 0 {
+		j.Rate, j.Channels = samples[0].Rate, samples[0].Channels
+	}
+	for _, s := range samples {
+		j.Data = append(j.Data, s.Data...)
+	}
+	return j
+}
+
+// Click returns a short high tick, for buttons and switches
+func Click() *Sample {
+	return Tone(2200, 25*time.Millisecond, 0.3).Decay()
+}
+
+// Hover returns a faint tick, quieter and shorter than Click, for the
+// pointer moving onto controls
+func Hover() *Sample {
+	return Tone(3000, 12*time.Millisecond, 0.08).Decay()
+}
+
+// Notice returns a soft rising pair of tones, for notices
+func Notice() *Sample {
+	return Join(Tone(660, 70*time.Millisecond, 0.25), Tone(880, 110*time.Millisecond, 0.25).Decay())
+}
+
+// Error returns a low falling pair of tones, for failures
+func Error() *Sample {
+	return Join(Tone(330, 90*time.Millisecond, 0.35), Tone(220, 160*time.Millisecond, 0.35).Decay())
+}
+
+// shared is the mixer Play uses, created with the first sound played
+var shared = sync.OnceValue(func() *Mixer { return NewMixer(OpenPlayer) })
+
+// Default returns the mixer Play uses, playing through the system's player
+func Default() *Mixer {
+	return shared()
+}
+
+// Play plays a sample through the default mixer, returning at once. Nil
+// samples are not played, so optional sounds need no checks.
+func Play(s *Sample) {
+	if s == nil {
+		return
+	}
+	Default().Play(s)
+}
@@ -0,0 +1,103 @@
+package sound
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+
+	"lol.mleku.dev/chk"
+)
+
+// ErrFormat is returned for files that are not WAV files of PCM or float
+// samples
+var ErrFormat = errors.New("sound: not a supported WAV file")
+
+// LoadWAV reads a WAV file into a sample
+func LoadWAV(path string) (s *Sample, err error) {
+	var data []byte
+	if data, err = os.ReadFile(path); chk.E(err) {
+		return
+	}
+	return DecodeWAV(bytes.NewReader(data))
+}
+
+// DecodeWAV reads a WAV file of 8, 16, 24 or 32 bit integer or 32 bit float
+// samples into a sample. Channels beyond the first two are dropped.
+func DecodeWAV(r io.Reader) (s *Sample, err error) {
+	var header [12]byte
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		return nil, ErrFormat
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return nil, ErrFormat
+	}
+	var format, channels, bits uint16
+	var rate uint32
+	for {
+		var chunk [8]byte
+		if _, err = io.ReadFull(r, chunk[:]); err != nil {
+			return nil, ErrFormat
+		}
+		size := binary.LittleEndian.Uint32(chunk[4:])
+		body := make([]byte, size+size%2)
+		if _, err = io.ReadFull(r, body); err != nil && !(string(chunk[:4]) == "data" && err == io.ErrUnexpectedEOF) {
+			return nil, ErrFormat
+		}
+		switch string(chunk[:4]) {
+		case "fmt ":
+			if size < 16 {
+				return nil, ErrFormat
+			}
+			format = binary.LittleEndian.Uint16(body[0:])
+			channels = binary.LittleEndian.Uint16(body[2:])
+			rate = binary.LittleEndian.Uint32(body[4:])
+			bits = binary.LittleEndian.Uint16(body[14:])
+			if format == 0xfffe && size >= 26 {
+				// The extensible format names the real one in its subformat
+				format = binary.LittleEndian.Uint16(body[24:])
+			}
+		case "data":
+			if channels == 0 || rate == 0 {
+				return nil, ErrFormat
+			}
+			return decodeSamples(body[:size], format, int(channels), int(rate), int(bits))
+		}
+	}
+}
+
+// decodeSamples converts the bytes of a WAV file's data chunk to a sample
+func decodeSamples(data []byte, format uint16, channels, rate, bits int) (s *Sample, err error) {
+	size := bits / 8
+	if size == 0 || format == 3 && bits != 32 || format != 1 && format != 3 {
+		return nil, ErrFormat
+	}
+	kept := min(channels, 2)
+	frames := len(data) / (size * channels)
+	s = &Sample{Rate: rate, Channels: kept, Data: make([]float32, 0, frames*kept)}
+	for f := range frames {
+		for c := range kept {
+			p := data[(f*channels+c)*size:]
+			var v float32
+			switch {
+			case format == 3:
+				v = math.Float32frombits(binary.LittleEndian.Uint32(p))
+			case size == 1:
+				// Eight bit samples alone are unsigned
+				v = (float32(p[0]) - 128) / 128
+			case size == 2:
+				v = float32(int16(binary.LittleEndian.Uint16(p))) / 32768
+			case size == 3:
+				v = float32(int32(uint32(p[0])<<8|uint32(p[1])<<16|uint32(p[2])<<24)) / (1 << 31)
+			case size == 4:
+				v = float32(int32(binary.LittleEndian.Uint32(p))) / (1 << 31)
+			default:
+				return nil, ErrFormat
+			}
+			s.Data = append(s.Data, v)
+		}
+	}
+	return
+}
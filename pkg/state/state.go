@@ -0,0 +1,132 @@
+// Package state holds observable values for widgets to show. A State is a
+// value that notifies its subscribers when it is set, and a Computed value
+// is derived from other observables, recomputed when they change. Widgets
+// subscribe to the values they show and update themselves when they change,
+// so the program changes its state rather than its widgets.
+//
+// Values are not safe for concurrent use. Set them from the goroutine that
+// runs the window, as subscribers mark widgets dirty as soon as they are
+// notified.
+package state
+
+// Observable is implemented by values that notify subscribers when they change
+type Observable interface {
+	// Subscribe calls fn after each change until the returned cancel
+	// function is called
+	Subscribe(fn func()) (cancel func())
+}
+
+// Value is an observable value that can be read
+type Value[T any] interface {
+	Observable
+	Get() T
+}
+
+// State is a value that notifies its subscribers when it is set to a
+// different value
+type State[T any] struct {
+	value T
+	// equal reports whether setting a value changes nothing, nil to notify
+	// on every set
+	equal func(a, b T) bool
+	subs  subscribers
+}
+
+// New creates a state holding a value, notifying subscribers only when it is
+// set to a value that is not equal
+func New[T comparable](value T) *State[T] {
+	return &State[T]{value: value, equal: func(a, b T) bool { return a == b }}
+}
+
+// NewFunc creates a state holding a value of a type that cannot be compared
+// with ==, using equal to skip sets that change nothing. A nil equal
+// notifies subscribers on every set.
+func NewFunc[T any](value T, equal func(a, b T) bool) *State[T] {
+	return &State[T]{value: value, equal: equal}
+}
+
+// Get returns the value
+func (s *State[T]) Get() T {
+	return s.value
+}
+
+// Set changes the value and notifies the subscribers if it changed
+func (s *State[T]) Set(value T) {
+	if s.equal != nil && s.equal(s.value, value) {
+		return
+	}
+	s.value = value
+	s.subs.notify()
+}
+
+// Update sets the value to the result of fn applied to the current value
+func (s *State[T]) Update(fn func(value T) T) {
+	s.Set(fn(s.value))
+}
+
+// Subscribe implements Observable for State
+func (s *State[T]) Subscribe(fn func()) (cancel func()) {
+	return s.subs.add(fn)
+}
+
+// Computed is a value derived from other observables. It is recomputed the
+// first time it is read after one of them changes, and notifies its own
+// subscribers as soon as one of them does.
+type Computed[T any] struct {
+	compute func() T
+	value   T
+	dirty   bool
+	subs    subscribers
+	cancels []func()
+}
+
+// Derive creates a value computed by fn from the dependencies, which fn reads
+func Derive[T any](fn func() T, deps ...Observable) *Computed[T] {
+	c := &Computed[T]{compute: fn, dirty: true}
+	for _, dep := range deps {
+		c.cancels = append(c.cancels, dep.Subscribe(c.invalidate))
+	}
+	return c
+}
+
+// Map creates a value computed by fn from a single source
+func Map[A, B any](source Value[A], fn func(A) B) *Computed[B] {
+	return Derive(func() B { return fn(source.Get()) }, source)
+}
+
+// Get returns the value, recomputing it if a dependency changed since it was
+// last read
+func (c *Computed[T]) Get() T {
+	if c.dirty {
+		c.value = c.compute()
+		c.dirty = false
+	}
+	return c.value
+}
+
+// Subscribe implements Observable for Computed
+func (c *Computed[T]) Subscribe(fn func()) (cancel func()) {
+	return c.subs.add(fn)
+}
+
+// Close stops following the dependencies, leaving the value as last computed
+func (c *Computed[T]) Close() {
+	for _, cancel := range c.cancels {
+		cancel()
+	}
+	c.cancels = nil
+}
+
+// invalidate marks the value stale after a dependency changed
+func (c *Computed[T]) invalidate() {
+	c.dirty = true
+	c.subs.notify()
+}
+
+// Watch calls fn with the value now and again after each change until the
+// returned cancel function is called, for keeping a widget in step with a
+// value, such as setting a label's text
+func Watch[T any](value Value[T], fn func(T)) (cancel func()) {
+	fn(value.Get())
+	return value.Subscribe(func() { fn(value.Get()) })
+}
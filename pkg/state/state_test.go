@@ -0,0 +1,116 @@
+package state
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStateSet(t *testing.T) {
+	tests := []struct {
+		name string
+		s    *State[[]int]
+		sets [][]int
+		// notified is how many times the subscriber should be called
+		notified int
+	}{
+		{
+			name:     "equal sets are skipped",
+			s:        NewFunc([]int{1}, slices.Equal[[]int]),
+			sets:     [][]int{{1}, {1, 2}, {1, 2}, {3}},
+			notified: 2,
+		},
+		{
+			name:     "nil equal notifies every set",
+			s:        NewFunc[[]int](nil, nil),
+			sets:     [][]int{nil, nil, {1}},
+			notified: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var notified int
+			tt.s.Subscribe(func() { notified++ })
+			for _, v := range tt.sets {
+				tt.s.Set(v)
+			}
+			if notified != tt.notified {
+				t.Errorf("notified %d times, want %d", notified, tt.notified)
+			}
+			if got := tt.s.Get(); !slices.Equal(got, tt.sets[len(tt.sets)-1]) {
+				t.Errorf("got %v, want the last value set", got)
+			}
+		})
+	}
+}
+
+func TestComparable(t *testing.T) {
+	s := New(1)
+	var seen []int
+	cancel := Watch[int](s, func(v int) { seen = append(seen, v) })
+	s.Set(1)
+	s.Set(2)
+	s.Update(func(v int) int { return v * 10 })
+	cancel()
+	s.Set(3)
+	if want := []int{1, 2, 20}; !slices.Equal(seen, want) {
+		t.Errorf("watched %v, want %v", seen, want)
+	}
+}
+
+func TestComputed(t *testing.T) {
+	a, b := New(2), New(3)
+	var computed int
+	sum := Derive(func() int {
+		computed++
+		return a.Get() + b.Get()
+	}, a, b)
+	doubled := Map[int](sum, func(v int) int { return v * 2 })
+	var notified int
+	doubled.Subscribe(func() { notified++ })
+
+	if got := doubled.Get(); got != 10 {
+		t.Errorf("got %d, want 10", got)
+	}
+	// Reads between changes use the last computed value
+	sum.Get()
+	if computed != 1 {
+		t.Errorf("computed %d times, want 1", computed)
+	}
+	a.Set(5)
+	b.Set(5)
+	if notified != 2 {
+		t.Errorf("notified %d times, want 2", notified)
+	}
+	if computed != 1 {
+		t.Error("recomputed before being read")
+	}
+	if got := doubled.Get(); got != 20 {
+		t.Errorf("got %d, want 20", got)
+	}
+	sum.Close()
+	a.Set(100)
+	if got := sum.Get(); got != 10 {
+		t.Errorf("closed value changed to %d", got)
+	}
+}
+
+func TestSubscribeDuringNotify(t *testing.T) {
+	s := New(0)
+	var calls []string
+	var cancelB func()
+	s.Subscribe(func() {
+		calls = append(calls, "a")
+		cancelB()
+		s.Subscribe(func() { calls = append(calls, "c") })
+	})
+	cancelB = s.Subscribe(func() { calls = append(calls, "b") })
+	s.Set(1)
+	if want := []string{"a"}; !slices.Equal(calls, want) {
+		t.Errorf("first notify called %v, want %v", calls, want)
+	}
+	calls = nil
+	s.Set(2)
+	if want := []string{"a", "c"}; !slices.Equal(calls, want) {
+		t.Errorf("second notify called %v, want %v", calls, want)
+	}
+}
@@ -0,0 +1,39 @@
+package state
+
+// subscription is a callback registered with an observable
+type subscription struct {
+	fn func()
+}
+
+// subscribers is the list of callbacks of an observable
+type subscribers struct {
+	list []*subscription
+}
+
+// add registers a callback, returning a function that removes it
+func (s *subscribers) add(fn func()) (cancel func()) {
+	sub := &subscription{fn: fn}
+	s.list = append(s.list, sub)
+	return func() {
+		sub.fn = nil
+		for i, o := range s.list {
+			if o == sub {
+				// Copy rather than shift in place, as notify may be ranging
+				// over the old list
+				s.list = append(s.list[:i:i], s.list[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// notify calls the callbacks in the order they were added. Callbacks may
+// subscribe or cancel; those added during notification are not called and
+// those cancelled are not called once cancelled.
+func (s *subscribers) notify() {
+	for _, sub := range s.list {
+		if sub.fn != nil {
+			sub.fn()
+		}
+	}
+}
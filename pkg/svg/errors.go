@@ -0,0 +1,12 @@
+package svg
+
+import (
+	"errors"
+)
+
+var (
+	// errNotSVG is returned when a document has no svg element
+	errNotSVG = errors.New("not an svg document")
+	// errNoSize is returned when a document has neither a size nor a view box
+	errNoSize = errors.New("svg document has no size")
+)
@@ -0,0 +1,475 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mleku/goo/pkg/render"
+	"lol.mleku.dev/chk"
+)
+
+// paint is how a shape is filled or stroked: not at all unless set, with
+// the current color, or with a color. The opacity applies on top of the
+// color's own.
+type paint struct {
+	set     bool
+	current bool
+	color   [4]float32
+	opacity float32
+}
+
+// shape is an outline drawn filled, stroked or both
+type shape struct {
+	path []segment
+	// transform maps the outline to the document's coordinates
+	transform   render.Matrix
+	fill        paint
+	stroke      paint
+	fillRule    fillRule
+	strokeWidth float32
+	cap         lineCap
+}
+
+// style holds the presentation attributes in force at an element
+type style struct {
+	fill, stroke  paint
+	fillRule      fillRule
+	strokeWidth   float32
+	cap           lineCap
+	fillOpacity   float32
+	strokeOpacity float32
+	// opacity is the product of the opacities of the element and the groups
+	// around it, applied to each shape rather than to the group as a whole
+	opacity float32
+	// color replaces the current color when set
+	color    [4]float32
+	colorSet bool
+	// transform maps the element's coordinates to the document's
+	transform render.Matrix
+}
+
+// skipped are the elements that are not drawn where they appear, or not
+// supported, whose contents are left out
+var skipped = map[string]bool{
+	"defs":           true,
+	"symbol":         true,
+	"clipPath":       true,
+	"mask":           true,
+	"pattern":        true,
+	"marker":         true,
+	"linearGradient": true,
+	"radialGradient": true,
+	"filter":         true,
+	"style":          true,
+	"script":         true,
+	"text":           true,
+	"title":          true,
+	"desc":           true,
+	"metadata":       true,
+	"foreignObject":  true,
+}
+
+// Parse reads an SVG document
+func Parse(data []byte) (img *Image, err error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = false
+	img = &Image{}
+	styles := []style{{
+		fill:          paint{set: true, color: [4]float32{0, 0, 0, 1}},
+		strokeWidth:   1,
+		fillOpacity:   1,
+		strokeOpacity: 1,
+		opacity:       1,
+		transform:     render.Identity(),
+	}}
+	// skip counts the depth inside an element whose contents are left out
+	skip := 0
+	root := false
+	for {
+		var tok xml.Token
+		if tok, err = d.Token(); err != nil {
+			if err == io.EOF {
+				err = nil
+				break
+			}
+			chk.E(err)
+			return
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := t.Name.Local
+			if skip > 0 || skipped[name] {
+				skip++
+				continue
+			}
+			attrs := attributes(t.Attr)
+			st := styles[len(styles)-1].inherit(attrs)
+			styles = append(styles, st)
+			if name == "svg" && !root {
+				root = true
+				img.size(attrs)
+				continue
+			}
+			if path := shapePath(name, attrs); len(path) > 0 {
+				img.shapes = append(img.shapes, st.shape(path))
+			}
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if len(styles) > 1 {
+				styles = styles[:len(styles)-1]
+			}
+		}
+	}
+	if !root {
+		err = errNotSVG
+		return
+	}
+	if img.viewBox[2] <= 0 || img.viewBox[3] <= 0 {
+		err = errNoSize
+		return
+	}
+	return
+}
+
+// size sets the size and view box of the image from the root element. The
+// view box defaults to the size and the size to the view box.
+func (img *Image) size(attrs map[string]string) {
+	vb := numberList(attrs["viewBox"])
+	if len(vb) == 4 {
+		img.viewBox = [4]float32{vb[0], vb[1], vb[2], vb[3]}
+	}
+	img.Width, img.Height = parseLength(attrs["width"], img.viewBox[2]), parseLength(attrs["height"], img.viewBox[3])
+	if len(vb) != 4 {
+		img.viewBox = [4]float32{0, 0, img.Width, img.Height}
+	}
+}
+
+// attributes returns an element's attributes by name, with the properties
+// in its style attribute overriding them
+func attributes(attrs []xml.Attr) map[string]string {
+	m := make(map[string]string, len(attrs))
+	for _, a := range attrs {
+		m[a.Name.Local] = strings.TrimSpace(a.Value)
+	}
+	if s, ok := m["style"]; ok {
+		for _, decl := range strings.Split(s, ";") {
+			if name, value, ok := strings.Cut(decl, ":"); ok {
+				m[strings.TrimSpace(name)] = strings.TrimSpace(value)
+			}
+		}
+	}
+	return m
+}
+
+// inherit returns the style of an element with the attributes, inside an
+// element with this style
+func (s style) inherit(attrs map[string]string) style {
+	if v, ok := attrs["color"]; ok {
+		if c, ok := parseColor(v); ok {
+			s.color, s.colorSet = c, true
+		}
+	}
+	if v, ok := attrs["fill"]; ok {
+		s.fill = s.paint(v, s.fill)
+	}
+	if v, ok := attrs["stroke"]; ok {
+		s.stroke = s.paint(v, s.stroke)
+	}
+	switch attrs["fill-rule"] {
+	case "nonzero":
+		s.fillRule = nonZero
+	case "evenodd":
+		s.fillRule = evenOdd
+	}
+	switch attrs["stroke-linecap"] {
+	case "butt":
+		s.cap = capButt
+	case "round":
+		s.cap = capRound
+	case "square":
+		s.cap = capSquare
+	}
+	if v, ok := attrs["stroke-width"]; ok {
+		s.strokeWidth = parseLength(v, s.strokeWidth)
+	}
+	if v, ok := attrs["fill-opacity"]; ok {
+		s.fillOpacity = opacity(v)
+	}
+	if v, ok := attrs["stroke-opacity"]; ok {
+		s.strokeOpacity = opacity(v)
+	}
+	if v, ok := attrs["opacity"]; ok {
+		s.opacity *= opacity(v)
+	}
+	if v, ok := attrs["transform"]; ok {
+		s.transform = parseTransform(v).Then(s.transform)
+	}
+	return s
+}
+
+// paint returns the paint a fill or stroke attribute sets, or the inherited
+// paint when the value is not understood
+func (s style) paint(v string, inherited paint) paint {
+	switch v {
+	case "none", "transparent":
+		return paint{}
+	case "inherit":
+		return inherited
+	case "currentColor":
+		if s.colorSet {
+			return paint{set: true, color: s.color}
+		}
+		return paint{set: true, current: true, color: [4]float32{0, 0, 0, 1}}
+	}
+	if strings.HasPrefix(v, "url(") {
+		// Paint servers are not supported, so use the fallback color after
+		// the reference if there is one
+		if _, fallback, ok := strings.Cut(v, ")"); ok && strings.TrimSpace(fallback) != "" {
+			return s.paint(strings.TrimSpace(fallback), inherited)
+		}
+		return paint{}
+	}
+	if c, ok := parseColor(v); ok {
+		return paint{set: true, color: c}
+	}
+	return inherited
+}
+
+// shape returns a shape with the style drawing an outline
+func (s style) shape(path []segment) shape {
+	sh := shape{
+		path:        path,
+		transform:   s.transform,
+		fill:        s.fill,
+		stroke:      s.stroke,
+		fillRule:    s.fillRule,
+		strokeWidth: s.strokeWidth,
+		cap:         s.cap,
+	}
+	sh.fill.opacity = s.opacity * s.fillOpacity
+	sh.stroke.opacity = s.opacity * s.strokeOpacity
+	return sh
+}
+
+// shapePath returns the outline of a shape element, empty for other
+// elements and shapes with no area or length
+func shapePath(name string, attrs map[string]string) []segment {
+	num := func(key string) float32 {
+		return parseLength(attrs[key], 0)
+	}
+	var o outline
+	switch name {
+	case "path":
+		return parsePath(attrs["d"])
+	case "rect":
+		w, h := num("width"), num("height")
+		if w <= 0 || h <= 0 {
+			return nil
+		}
+		rx, rxSet := attrs["rx"]
+		ry, rySet := attrs["ry"]
+		switch {
+		case rxSet && !rySet:
+			ry = rx
+		case rySet && !rxSet:
+			rx = ry
+		}
+		o.rect(num("x"), num("y"), w, h, parseLength(rx, 0), parseLength(ry, 0))
+	case "circle":
+		r := num("r")
+		if r <= 0 {
+			return nil
+		}
+		o.ellipse(num("cx"), num("cy"), r, r)
+	case "ellipse":
+		rx, ry := num("rx"), num("ry")
+		if rx <= 0 || ry <= 0 {
+			return nil
+		}
+		o.ellipse(num("cx"), num("cy"), rx, ry)
+	case "line":
+		o.moveTo(num("x1"), num("y1"))
+		o.lineTo(num("x2"), num("y2"))
+	case "polyline", "polygon":
+		points := numberList(attrs["points"])
+		for i := 0; i+1 < len(points); i += 2 {
+			if i == 0 {
+				o.moveTo(points[i], points[i+1])
+			} else {
+				o.lineTo(points[i], points[i+1])
+			}
+		}
+		if name == "polygon" && len(o.segments) > 0 {
+			o.close()
+		}
+	}
+	return o.segments
+}
+
+// parseLength returns the number at the start of a length such as "12" or
+// "12px", or a default when there is none. Units are ignored, and so
+// percentages are not supported.
+func parseLength(v string, def float32) float32 {
+	s := scanner{s: v}
+	if n, ok := s.number(); ok {
+		return n
+	}
+	return def
+}
+
+// opacity returns an opacity from 0 to 1, given as a number or a percentage
+func opacity(v string) float32 {
+	s := scanner{s: v}
+	n, ok := s.number()
+	if !ok {
+		return 1
+	}
+	if strings.HasSuffix(v, "%") {
+		n /= 100
+	}
+	return min(max(n, 0), 1)
+}
+
+// numberList returns the numbers in a list separated by spaces or commas
+func numberList(v string) (numbers []float32) {
+	s := scanner{s: v}
+	for {
+		n, ok := s.number()
+		if !ok {
+			return
+		}
+		numbers = append(numbers, n)
+	}
+}
+
+// parseTransform returns the transform a transform attribute lists,
+// applying the last in the list first
+func parseTransform(v string) render.Matrix {
+	m := render.Identity()
+	for {
+		open := strings.IndexByte(v, '(')
+		end := strings.IndexByte(v, ')')
+		if open < 0 || end < open {
+			return m
+		}
+		name := strings.Trim(v[:open], " \t\r\n,")
+		args := numberList(v[open+1 : end])
+		v = v[end+1:]
+		arg := func(i int, def float32) float32 {
+			if i < len(args) {
+				return args[i]
+			}
+			return def
+		}
+		var t render.Matrix
+		switch name {
+		case "matrix":
+			if len(args) != 6 {
+				continue
+			}
+			t = render.Matrix{A: args[0], B: args[1], C: args[2], D: args[3], E: args[4], F: args[5]}
+		case "translate":
+			t = render.Translate(arg(0, 0), arg(1, 0))
+		case "scale":
+			t = render.Scale(arg(0, 1), arg(1, arg(0, 1)))
+		case "rotate":
+			cx, cy := arg(1, 0), arg(2, 0)
+			t = render.Translate(-cx, -cy).Then(render.Rotate(arg(0, 0) * math.Pi / 180)).Then(render.Translate(cx, cy))
+		case "skewX":
+			t = render.Matrix{A: 1, C: float32(math.Tan(float64(arg(0, 0)) * math.Pi / 180)), D: 1}
+		case "skewY":
+			t = render.Matrix{A: 1, B: float32(math.Tan(float64(arg(0, 0)) * math.Pi / 180)), D: 1}
+		default:
+			continue
+		}
+		m = t.Then(m)
+	}
+}
+
+// namedColors are the color keywords understood, the basic CSS colors
+var namedColors = map[string][4]float32{
+	"black":   {0, 0, 0, 1},
+	"silver":  {0.75, 0.75, 0.75, 1},
+	"gray":    {0.5, 0.5, 0.5, 1},
+	"grey":    {0.5, 0.5, 0.5, 1},
+	"white":   {1, 1, 1, 1},
+	"maroon":  {0.5, 0, 0, 1},
+	"red":     {1, 0, 0, 1},
+	"purple":  {0.5, 0, 0.5, 1},
+	"fuchsia": {1, 0, 1, 1},
+	"magenta": {1, 0, 1, 1},
+	"green":   {0, 0.5, 0, 1},
+	"lime":    {0, 1, 0, 1},
+	"olive":   {0.5, 0.5, 0, 1},
+	"yellow":  {1, 1, 0, 1},
+	"navy":    {0, 0, 0.5, 1},
+	"blue":    {0, 0, 1, 1},
+	"teal":    {0, 0.5, 0.5, 1},
+	"aqua":    {0, 1, 1, 1},
+	"cyan":    {0, 1, 1, 1},
+	"orange":  {1, 0.65, 0, 1},
+}
+
+// parseColor reads a color written as a keyword, #rgb, #rgba, #rrggbb,
+// #rrggbbaa, rgb() or rgba()
+func parseColor(v string) (c [4]float32, ok bool) {
+	v = strings.TrimSpace(v)
+	if c, ok = namedColors[strings.ToLower(v)]; ok {
+		return
+	}
+	if hex, found := strings.CutPrefix(v, "#"); found {
+		if len(hex) == 3 || len(hex) == 4 {
+			var long strings.Builder
+			for _, r := range hex {
+				long.WriteRune(r)
+				long.WriteRune(r)
+			}
+			hex = long.String()
+		}
+		if len(hex) != 6 && len(hex) != 8 {
+			return
+		}
+		n, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return
+		}
+		if len(hex) == 6 {
+			n = n<<8 | 0xff
+		}
+		for i := range 4 {
+			c[i] = float32(n>>(24-8*i)&0xff) / 255
+		}
+		return c, true
+	}
+	lower := strings.ToLower(v)
+	if !strings.HasPrefix(lower, "rgb") {
+		return
+	}
+	open, end := strings.IndexByte(v, '('), strings.IndexByte(v, ')')
+	if open < 0 || end < open {
+		return
+	}
+	parts := strings.FieldsFunc(v[open+1:end], func(r rune) bool { return r == ',' || r == ' ' || r == '/' })
+	if len(parts) < 3 {
+		return
+	}
+	c[3] = 1
+	for i, p := range parts[:min(len(parts), 4)] {
+		n := parseLength(p, 0)
+		switch {
+		case strings.HasSuffix(p, "%"):
+			n /= 100
+		case i < 3:
+			n /= 255
+		}
+		c[i] = min(max(n, 0), 1)
+	}
+	return c, true
+}
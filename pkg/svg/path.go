@@ -0,0 +1,479 @@
+package svg
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/mleku/goo/pkg/render"
+)
+
+// flatness is the furthest, in pixels, the segments curves are rasterized
+// with stray from the true curve
+const flatness = 0.2
+
+// verb is a step of an outline
+type verb int
+
+const (
+	verbMove verb = iota
+	verbLine
+	verbCubic
+	verbClose
+)
+
+// segment is a step of an outline to the last of its points, through the
+// control points before it for cubic Bézier curves
+type segment struct {
+	verb   verb
+	points [3][2]float32
+}
+
+// outline builds the segments of a shape, tracking the current point
+type outline struct {
+	segments []segment
+	current  [2]float32
+	start    [2]float32
+}
+
+func (o *outline) moveTo(x, y float32) {
+	o.segments = append(o.segments, segment{verb: verbMove, points: [3][2]float32{{x, y}}})
+	o.current, o.start = [2]float32{x, y}, [2]float32{x, y}
+}
+
+func (o *outline) lineTo(x, y float32) {
+	o.segments = append(o.segments, segment{verb: verbLine, points: [3][2]float32{{x, y}}})
+	o.current = [2]float32{x, y}
+}
+
+func (o *outline) cubicTo(c1x, c1y, c2x, c2y, x, y float32) {
+	o.segments = append(o.segments, segment{verb: verbCubic, points: [3][2]float32{{c1x, c1y}, {c2x, c2y}, {x, y}}})
+	o.current = [2]float32{x, y}
+}
+
+// quadTo adds a quadratic Bézier curve as the cubic that traces it
+func (o *outline) quadTo(cx, cy, x, y float32) {
+	p := o.current
+	o.cubicTo(
+		p[0]+2.0/3*(cx-p[0]), p[1]+2.0/3*(cy-p[1]),
+		x+2.0/3*(cx-x), y+2.0/3*(cy-y),
+		x, y,
+	)
+}
+
+func (o *outline) close() {
+	o.segments = append(o.segments, segment{verb: verbClose})
+	o.current = o.start
+}
+
+// arcTo adds an elliptical arc to a point the way SVG paths describe it:
+// with radii rx and ry, the ellipse's x axis turned by rotation degrees,
+// taking the long way round when large is set and running clockwise on
+// screen when sweep is set. Radii too small to reach the point are scaled
+// up until they do.
+func (o *outline) arcTo(rx, ry, rotation float32, large, sweep bool, x, y float32) {
+	p := o.current
+	if p == [2]float32{x, y} {
+		return
+	}
+	if rx == 0 || ry == 0 {
+		o.lineTo(x, y)
+		return
+	}
+	x1, y1, x2, y2 := float64(p[0]), float64(p[1]), float64(x), float64(y)
+	rxf, ryf := math.Abs(float64(rx)), math.Abs(float64(ry))
+	sin, cos := math.Sincos(float64(rotation) * math.Pi / 180)
+	// The start point in the ellipse's axes, relative to the chord's middle
+	dx, dy := (x1-x2)/2, (y1-y2)/2
+	x1p := cos*dx + sin*dy
+	y1p := -sin*dx + cos*dy
+	if l := x1p*x1p/(rxf*rxf) + y1p*y1p/(ryf*ryf); l > 1 {
+		rxf, ryf = rxf*math.Sqrt(l), ryf*math.Sqrt(l)
+	}
+	num := rxf*rxf*ryf*ryf - rxf*rxf*y1p*y1p - ryf*ryf*x1p*x1p
+	den := rxf*rxf*y1p*y1p + ryf*ryf*x1p*x1p
+	coef := math.Sqrt(max(num/den, 0))
+	if large == sweep {
+		coef = -coef
+	}
+	cxp, cyp := coef*rxf*y1p/ryf, -coef*ryf*x1p/rxf
+	cx := cos*cxp - sin*cyp + (x1+x2)/2
+	cy := sin*cxp + cos*cyp + (y1+y2)/2
+	ux, uy := (x1p-cxp)/rxf, (y1p-cyp)/ryf
+	vx, vy := (-x1p-cxp)/rxf, (-y1p-cyp)/ryf
+	start := math.Atan2(uy, ux)
+	delta := math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	} else if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	}
+	// Each cubic spans at most a quarter turn, where it stays within a
+	// fraction of a pixel of the ellipse
+	n := max(int(math.Ceil(math.Abs(delta)/(math.Pi/2)-1e-9)), 1)
+	step := delta / float64(n)
+	k := 4.0 / 3.0 * math.Tan(step/4)
+	point := func(t float64) (px, py, tx, ty float64) {
+		st, ct := math.Sincos(t)
+		px = cx + rxf*ct*cos - ryf*st*sin
+		py = cy + rxf*ct*sin + ryf*st*cos
+		tx = -rxf*st*cos - ryf*ct*sin
+		ty = -rxf*st*sin + ryf*ct*cos
+		return
+	}
+	a := start
+	for i := range n {
+		b := a + step
+		ax, ay, atx, aty := point(a)
+		bx, by, btx, bty := point(b)
+		if i == n-1 {
+			// End exactly on the point asked for
+			bx, by = x2, y2
+		}
+		o.cubicTo(
+			float32(ax+k*atx), float32(ay+k*aty),
+			float32(bx-k*btx), float32(by-k*bty),
+			float32(bx), float32(by),
+		)
+		a = b
+	}
+}
+
+// ellipse adds a closed ellipse clockwise from its right end
+func (o *outline) ellipse(cx, cy, rx, ry float32) {
+	o.moveTo(cx+rx, cy)
+	o.arcTo(rx, ry, 0, false, true, cx-rx, cy)
+	o.arcTo(rx, ry, 0, false, true, cx+rx, cy)
+	o.close()
+}
+
+// rect adds a closed rectangle with corners rounded to radii rx and ry
+func (o *outline) rect(x, y, width, height, rx, ry float32) {
+	rx, ry = min(rx, width/2), min(ry, height/2)
+	if rx <= 0 || ry <= 0 {
+		o.moveTo(x, y)
+		o.lineTo(x+width, y)
+		o.lineTo(x+width, y+height)
+		o.lineTo(x, y+height)
+		o.close()
+		return
+	}
+	o.moveTo(x+rx, y)
+	o.lineTo(x+width-rx, y)
+	o.arcTo(rx, ry, 0, false, true, x+width, y+ry)
+	o.lineTo(x+width, y+height-ry)
+	o.arcTo(rx, ry, 0, false, true, x+width-rx, y+height)
+	o.lineTo(x+rx, y+height)
+	o.arcTo(rx, ry, 0, false, true, x, y+height-ry)
+	o.lineTo(x, y+ry)
+	o.arcTo(rx, ry, 0, false, true, x+rx, y)
+	o.close()
+}
+
+// parsePath reads SVG path data into an outline. Like browsers, it keeps
+// what it read up to the first error and drops the rest.
+func parsePath(d string) []segment {
+	var o outline
+	s := scanner{s: d}
+	var command byte
+	// last is the last control point of the previous curve, reflected to
+	// make the first of a smooth curve
+	var last [2]float32
+	var previous byte
+	for {
+		s.skipSpace()
+		if s.done() {
+			break
+		}
+		if c := s.s[s.i]; isCommand(c) {
+			command = c
+			s.i++
+		} else if command == 0 {
+			break
+		}
+		relative := command >= 'a'
+		var rel [2]float32
+		if relative {
+			rel = o.current
+		}
+		upper := command &^ 0x20
+		ok := true
+		switch upper {
+		case 'M':
+			var x, y float32
+			if x, y, ok = s.pair(); ok {
+				o.moveTo(rel[0]+x, rel[1]+y)
+				// Further pairs after a move are lines
+				if relative {
+					command = 'l'
+				} else {
+					command = 'L'
+				}
+			}
+		case 'L':
+			var x, y float32
+			if x, y, ok = s.pair(); ok {
+				o.lineTo(rel[0]+x, rel[1]+y)
+			}
+		case 'H':
+			var x float32
+			if x, ok = s.number(); ok {
+				o.lineTo(rel[0]+x, o.current[1])
+			}
+		case 'V':
+			var y float32
+			if y, ok = s.number(); ok {
+				o.lineTo(o.current[0], rel[1]+y)
+			}
+		case 'C':
+			var v [6]float32
+			if ok = s.numbers(v[:]); ok {
+				o.cubicTo(rel[0]+v[0], rel[1]+v[1], rel[0]+v[2], rel[1]+v[3], rel[0]+v[4], rel[1]+v[5])
+				last = [2]float32{rel[0] + v[2], rel[1] + v[3]}
+			}
+		case 'S':
+			var v [4]float32
+			if ok = s.numbers(v[:]); ok {
+				c1 := o.current
+				if previous == 'C' || previous == 'S' {
+					c1 = [2]float32{2*c1[0] - last[0], 2*c1[1] - last[1]}
+				}
+				o.cubicTo(c1[0], c1[1], rel[0]+v[0], rel[1]+v[1], rel[0]+v[2], rel[1]+v[3])
+				last = [2]float32{rel[0] + v[0], rel[1] + v[1]}
+			}
+		case 'Q':
+			var v [4]float32
+			if ok = s.numbers(v[:]); ok {
+				o.quadTo(rel[0]+v[0], rel[1]+v[1], rel[0]+v[2], rel[1]+v[3])
+				last = [2]float32{rel[0] + v[0], rel[1] + v[1]}
+			}
+		case 'T':
+			var x, y float32
+			if x, y, ok = s.pair(); ok {
+				c := o.current
+				if previous == 'Q' || previous == 'T' {
+					c = [2]float32{2*c[0] - last[0], 2*c[1] - last[1]}
+				}
+				o.quadTo(c[0], c[1], rel[0]+x, rel[1]+y)
+				last = c
+			}
+		case 'A':
+			var v [3]float32
+			var large, sweep bool
+			var x, y float32
+			if ok = s.numbers(v[:]); ok {
+				if large, ok = s.flag(); ok {
+					if sweep, ok = s.flag(); ok {
+						if x, y, ok = s.pair(); ok {
+							o.arcTo(v[0], v[1], v[2], large, sweep, rel[0]+x, rel[1]+y)
+						}
+					}
+				}
+			}
+		case 'Z':
+			o.close()
+			// A close takes no numbers, so another command must follow
+			previous = 'Z'
+			command = 0
+			continue
+		default:
+			ok = false
+		}
+		if !ok {
+			break
+		}
+		previous = upper
+	}
+	return o.segments
+}
+
+// isCommand reports whether a byte is a path command letter
+func isCommand(c byte) bool {
+	switch c &^ 0x20 {
+	case 'M', 'L', 'H', 'V', 'C', 'S', 'Q', 'T', 'A', 'Z':
+		return true
+	}
+	return false
+}
+
+// scanner reads the numbers of path data and attribute lists, which may be
+// separated by spaces or a comma, or run together where a sign or second
+// decimal point starts the next
+type scanner struct {
+	s string
+	i int
+}
+
+func (s *scanner) done() bool {
+	return s.i >= len(s.s)
+}
+
+// skipSpace skips white space
+func (s *scanner) skipSpace() {
+	for !s.done() && isSpace(s.s[s.i]) {
+		s.i++
+	}
+}
+
+// skipSeparator skips white space with at most one comma in it
+func (s *scanner) skipSeparator() {
+	s.skipSpace()
+	if !s.done() && s.s[s.i] == ',' {
+		s.i++
+		s.skipSpace()
+	}
+}
+
+// number reads the next number
+func (s *scanner) number() (v float32, ok bool) {
+	s.skipSeparator()
+	start := s.i
+	if !s.done() && (s.s[s.i] == '+' || s.s[s.i] == '-') {
+		s.i++
+	}
+	digits := false
+	for !s.done() && isDigit(s.s[s.i]) {
+		s.i++
+		digits = true
+	}
+	if !s.done() && s.s[s.i] == '.' {
+		s.i++
+		for !s.done() && isDigit(s.s[s.i]) {
+			s.i++
+			digits = true
+		}
+	}
+	if !digits {
+		s.i = start
+		return
+	}
+	if !s.done() && (s.s[s.i] == 'e' || s.s[s.i] == 'E') {
+		j := s.i + 1
+		if j < len(s.s) && (s.s[j] == '+' || s.s[j] == '-') {
+			j++
+		}
+		if j < len(s.s) && isDigit(s.s[j]) {
+			for j < len(s.s) && isDigit(s.s[j]) {
+				j++
+			}
+			s.i = j
+		}
+	}
+	f, err := strconv.ParseFloat(s.s[start:s.i], 32)
+	if err != nil {
+		s.i = start
+		return
+	}
+	return float32(f), true
+}
+
+// numbers reads as many numbers as fit in v
+func (s *scanner) numbers(v []float32) (ok bool) {
+	for i := range v {
+		if v[i], ok = s.number(); !ok {
+			return
+		}
+	}
+	return true
+}
+
+// pair reads the next two numbers
+func (s *scanner) pair() (x, y float32, ok bool) {
+	if x, ok = s.number(); !ok {
+		return
+	}
+	y, ok = s.number()
+	return
+}
+
+// flag reads an arc flag, a single 0 or 1 that need not be separated from
+// what follows
+func (s *scanner) flag() (set, ok bool) {
+	s.skipSeparator()
+	if s.done() || (s.s[s.i] != '0' && s.s[s.i] != '1') {
+		return
+	}
+	set = s.s[s.i] == '1'
+	s.i++
+	return set, true
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// polyline is a run of points an outline is flattened to
+type polyline struct {
+	points [][2]float32
+	closed bool
+}
+
+// flatten returns the subpaths of an outline transformed by m and cut into
+// straight segments short enough to follow curves within the flatness
+func flatten(segments []segment, m render.Matrix) (lines []polyline) {
+	var last [2]float32
+	apply := func(q [2]float32) [2]float32 {
+		x, y := m.Apply(q[0], q[1])
+		return [2]float32{x, y}
+	}
+	begin := func(q [2]float32) {
+		// A move with nothing drawn from it draws nothing
+		if n := len(lines); n > 0 && len(lines[n-1].points) == 1 && !lines[n-1].closed {
+			lines = lines[:n-1]
+		}
+		lines = append(lines, polyline{points: [][2]float32{q}})
+		last = q
+	}
+	add := func(q [2]float32) {
+		l := &lines[len(lines)-1]
+		l.points = append(l.points, q)
+		last = q
+	}
+	for _, seg := range segments {
+		if seg.verb == verbLine || seg.verb == verbCubic {
+			// Lines after a close start a new subpath where the last began
+			if n := len(lines); n == 0 || lines[n-1].closed {
+				begin(last)
+			}
+		}
+		switch seg.verb {
+		case verbMove:
+			begin(apply(seg.points[0]))
+		case verbLine:
+			add(apply(seg.points[0]))
+		case verbCubic:
+			s := last
+			c1, c2, e := apply(seg.points[0]), apply(seg.points[1]), apply(seg.points[2])
+			dd := max(
+				length(s[0]-2*c1[0]+c2[0], s[1]-2*c1[1]+c2[1]),
+				length(c1[0]-2*c2[0]+e[0], c1[1]-2*c2[1]+e[1]),
+			)
+			n := min(max(int(math.Ceil(math.Sqrt(float64(3*dd/(4*flatness))))), 1), 256)
+			for k := 1; k <= n; k++ {
+				t := float32(k) / float32(n)
+				u := 1 - t
+				add([2]float32{
+					u*u*u*s[0] + 3*u*u*t*c1[0] + 3*u*t*t*c2[0] + t*t*t*e[0],
+					u*u*u*s[1] + 3*u*u*t*c1[1] + 3*u*t*t*c2[1] + t*t*t*e[1],
+				})
+			}
+		case verbClose:
+			if n := len(lines); n > 0 && !lines[n-1].closed {
+				lines[n-1].closed = true
+				last = lines[n-1].points[0]
+			}
+		}
+	}
+	if n := len(lines); n > 0 && len(lines[n-1].points) == 1 && !lines[n-1].closed {
+		lines = lines[:n-1]
+	}
+	return
+}
+
+// length returns the length of a vector
+func length(x, y float32) float32 {
+	return float32(math.Sqrt(float64(x*x + y*y)))
+}
@@ -0,0 +1,225 @@
+package svg
+
+import (
+	"math"
+)
+
+// fillRule decides which parts of a shape whose outline crosses itself or
+// nests are inside it
+type fillRule int
+
+const (
+	// nonZero fills where the outline winds around a point in one direction
+	// more often than the other
+	nonZero fillRule = iota
+	// evenOdd fills where a ray from a point crosses the outline an odd
+	// number of times, cutting holes where outlines nest
+	evenOdd
+)
+
+// lineCap is the shape drawn at the ends of open strokes
+type lineCap int
+
+const (
+	capButt lineCap = iota
+	capRound
+	capSquare
+)
+
+// rasterizer accumulates the signed area coverage of edges and resolves it
+// into anti-aliased coverage under a fill rule
+type rasterizer struct {
+	width, height int
+	acc           []float32
+}
+
+// newRasterizer creates a rasterizer for an image of the given size
+func newRasterizer(width, height int) *rasterizer {
+	return &rasterizer{
+		width:  width,
+		height: height,
+		// Coverage can spill past the last pixel of the last row
+		acc: make([]float32, width*height+width+2),
+	}
+}
+
+// reset clears the coverage for the next shape
+func (r *rasterizer) reset() {
+	clear(r.acc)
+}
+
+// line adds a straight edge from (x0, y0) to (x1, y1) in pixels
+func (r *rasterizer) line(x0, y0, x1, y1 float32) {
+	if y0 == y1 {
+		return
+	}
+	dir := float32(1)
+	if y0 > y1 {
+		dir = -1
+		x0, y0, x1, y1 = x1, y1, x0, y0
+	}
+	dxdy := (x1 - x0) / (y1 - y0)
+	x := x0
+	if y0 < 0 {
+		x -= y0 * dxdy
+	}
+	maxX := float32(r.width) - 1
+	yEnd := min(int(math.Ceil(float64(y1))), r.height)
+	for y := int(max(y0, 0)); y < yEnd; y++ {
+		row := y * r.width
+		dy := min(float32(y+1), y1) - max(float32(y), y0)
+		xNext := x + dxdy*dy
+		d := dy * dir
+		a, b := min(max(x, 0), maxX), min(max(xNext, 0), maxX)
+		if a > b {
+			a, b = b, a
+		}
+		aFloor := float32(math.Floor(float64(a)))
+		ai := int(aFloor)
+		bCeil := float32(math.Ceil(float64(b)))
+		bi := int(bCeil)
+		if bi <= ai+1 {
+			// The edge stays within a single pixel column
+			mid := 0.5*(a+b) - aFloor
+			r.acc[row+ai] += d - d*mid
+			r.acc[row+ai+1] += d * mid
+		} else {
+			s := 1 / (b - a)
+			af := a - aFloor
+			a0 := 0.5 * s * (1 - af) * (1 - af)
+			bf := b - bCeil + 1
+			am := 0.5 * s * bf * bf
+			r.acc[row+ai] += d * a0
+			if bi == ai+2 {
+				r.acc[row+ai+1] += d * (1 - a0 - am)
+			} else {
+				a1 := s * (1.5 - af)
+				r.acc[row+ai+1] += d * (a1 - a0)
+				for xi := ai + 2; xi < bi-1; xi++ {
+					r.acc[row+xi] += d * s
+				}
+				a2 := a1 + float32(bi-ai-3)*s
+				r.acc[row+bi-1] += d * (1 - a2 - am)
+			}
+			r.acc[row+bi] += d * am
+		}
+		x = xNext
+	}
+}
+
+// polygon adds the edges of a closed outline
+func (r *rasterizer) polygon(points [][2]float32) {
+	n := len(points)
+	for i := range n {
+		a, b := points[i], points[(i+1)%n]
+		r.line(a[0], a[1], b[0], b[1])
+	}
+}
+
+// wound adds the edges of a closed outline running clockwise on screen,
+// reversing it if needed, so overlapping pieces of a stroke add up rather
+// than cancel out
+func (r *rasterizer) wound(points [][2]float32) {
+	var area float32
+	n := len(points)
+	for i := range n {
+		a, b := points[i], points[(i+1)%n]
+		area += a[0]*b[1] - b[0]*a[1]
+	}
+	if area >= 0 {
+		r.polygon(points)
+		return
+	}
+	for i := range n {
+		a, b := points[n-1-i], points[(2*n-2-i)%n]
+		r.line(a[0], a[1], b[0], b[1])
+	}
+}
+
+// stroke adds the outline of a line half wide either side of a polyline,
+// as a quad along each segment and a circle at each join, which rounds the
+// joins, with the cap at the ends of open polylines
+func (r *rasterizer) stroke(p polyline, half float32, cap lineCap) {
+	points := p.points
+	n := len(points)
+	if n == 0 || half <= 0 {
+		return
+	}
+	if n == 1 {
+		if cap == capRound {
+			r.circle(points[0], half)
+		} else if cap == capSquare {
+			c := points[0]
+			r.wound([][2]float32{{c[0] - half, c[1] - half}, {c[0] + half, c[1] - half}, {c[0] + half, c[1] + half}, {c[0] - half, c[1] + half}})
+		}
+		return
+	}
+	segments := n - 1
+	if p.closed {
+		segments = n
+	}
+	for i := range segments {
+		a, b := points[i], points[(i+1)%n]
+		dx, dy := b[0]-a[0], b[1]-a[1]
+		l := float32(math.Sqrt(float64(dx*dx + dy*dy)))
+		if l == 0 {
+			continue
+		}
+		dx, dy = dx/l*half, dy/l*half
+		if !p.closed && cap == capSquare {
+			if i == 0 {
+				a = [2]float32{a[0] - dx, a[1] - dy}
+			}
+			if i == segments-1 {
+				b = [2]float32{b[0] + dx, b[1] + dy}
+			}
+		}
+		r.wound([][2]float32{{a[0] - dy, a[1] + dx}, {b[0] - dy, b[1] + dx}, {b[0] + dy, b[1] - dx}, {a[0] + dy, a[1] - dx}})
+	}
+	for i, c := range points {
+		end := i == 0 || i == n-1
+		if !end || p.closed || cap == capRound {
+			r.circle(c, half)
+		}
+	}
+}
+
+// circle adds a circle of a radius about a point
+func (r *rasterizer) circle(c [2]float32, radius float32) {
+	// Enough sides that they stray less than a tenth of a pixel
+	sides := min(max(int(math.Ceil(math.Pi/math.Sqrt(0.2/float64(radius)))), 8), 64)
+	points := make([][2]float32, sides)
+	for i := range points {
+		a := 2 * math.Pi * float64(i) / float64(sides)
+		points[i] = [2]float32{c[0] + radius*float32(math.Cos(a)), c[1] + radius*float32(math.Sin(a))}
+	}
+	r.polygon(points)
+}
+
+// blend paints the covered pixels with a color, over what is already there
+func (r *rasterizer) blend(pix []float32, color [4]float32, rule fillRule) {
+	if color[3] <= 0 {
+		return
+	}
+	var sum float32
+	for i := range r.width * r.height {
+		sum += r.acc[i]
+		v := float32(math.Abs(float64(sum)))
+		if rule == evenOdd {
+			v -= 2 * float32(math.Floor(float64(v/2)))
+			if v > 1 {
+				v = 2 - v
+			}
+		}
+		a := min(v, 1) * color[3]
+		if a <= 0 {
+			continue
+		}
+		p := pix[4*i : 4*i+4]
+		keep := 1 - a
+		p[0] = color[0]*a + p[0]*keep
+		p[1] = color[1]*a + p[1]*keep
+		p[2] = color[2]*a + p[2]*keep
+		p[3] = a + p[3]*keep
+	}
+}
@@ -0,0 +1,142 @@
+// Package svg reads SVG documents such as icons and rasterizes them at any
+// size, so they stay crisp at every display density. It draws paths and the
+// basic shapes filled and stroked with solid colors, in groups with
+// transforms and opacity. Gradients, patterns, text, clipping, masks,
+// filters and style sheets are not supported, and what uses them is left
+// out.
+package svg
+
+import (
+	"image"
+	"math"
+	"os"
+	"sync"
+
+	"github.com/mleku/goo/pkg/render"
+	"lol.mleku.dev/chk"
+)
+
+// fileImages caches documents loaded by Load by file path
+var fileImages = struct {
+	sync.Mutex
+	images map[string]*Image
+}{images: make(map[string]*Image)}
+
+// Image is a parsed SVG document, ready to be rasterized
+type Image struct {
+	// Width and Height are the size the document asks to be drawn at
+	Width, Height float32
+	// viewBox is the rect (x, y, width, height) of the document's
+	// coordinates mapped onto the image
+	viewBox [4]float32
+	shapes  []shape
+}
+
+// Load parses an SVG file. Documents are cached by path, so loading the same
+// file again returns the same image.
+func Load(path string) (img *Image, err error) {
+	fileImages.Lock()
+	defer fileImages.Unlock()
+	if img = fileImages.images[path]; img != nil {
+		return
+	}
+	var data []byte
+	if data, err = os.ReadFile(path); chk.E(err) {
+		return
+	}
+	if img, err = Parse(data); chk.E(err) {
+		return
+	}
+	fileImages.images[path] = img
+	return
+}
+
+// Rasterize draws the image scaled to fit a size in pixels, keeping its
+// aspect ratio and centered. Shapes painted with currentColor are drawn in
+// the current color unless the document sets its own.
+func (img *Image) Rasterize(width, height int, current [4]float32) *image.NRGBA {
+	return img.rasterize(width, height, func(p paint) [4]float32 {
+		if p.current {
+			return current
+		}
+		return p.color
+	})
+}
+
+// RasterizeMono draws the image like Rasterize with every shape in one
+// color, keeping only the opacity of their own colors, for icons recolored
+// to match a theme
+func (img *Image) RasterizeMono(width, height int, color [4]float32) *image.NRGBA {
+	return img.rasterize(width, height, func(p paint) [4]float32 {
+		c := color
+		if !p.current {
+			c[3] *= p.color[3]
+		}
+		return c
+	})
+}
+
+// rasterize draws the shapes over each other in the colors resolve gives
+// their paints, faded by the paints' opacity
+func (img *Image) rasterize(width, height int, resolve func(p paint) [4]float32) *image.NRGBA {
+	width, height = max(width, 0), max(height, 0)
+	out := image.NewNRGBA(image.Rect(0, 0, width, height))
+	if width == 0 || height == 0 {
+		return out
+	}
+	view := img.view(float32(width), float32(height))
+	colorOf := func(p paint) [4]float32 {
+		c := resolve(p)
+		c[3] *= p.opacity
+		return c
+	}
+	r := newRasterizer(width, height)
+	// Premultiplied colors of the pixels, blended in floating point
+	pix := make([]float32, 4*width*height)
+	for i := range img.shapes {
+		s := &img.shapes[i]
+		m := s.transform.Then(view)
+		if s.fill.set {
+			r.reset()
+			for _, p := range flatten(s.path, m) {
+				r.polygon(p.points)
+			}
+			r.blend(pix, colorOf(s.fill), s.fillRule)
+		}
+		if s.stroke.set && s.strokeWidth > 0 {
+			scale := float32(math.Sqrt(math.Abs(float64(m.A*m.D - m.B*m.C))))
+			r.reset()
+			for _, p := range flatten(s.path, m) {
+				r.stroke(p, s.strokeWidth*scale/2, s.cap)
+			}
+			r.blend(pix, colorOf(s.stroke), nonZero)
+		}
+	}
+	for i := 0; i < len(pix); i += 4 {
+		a := pix[i+3]
+		if a <= 0 {
+			continue
+		}
+		out.Pix[i] = uint8(min(pix[i]/a, 1)*255 + 0.5)
+		out.Pix[i+1] = uint8(min(pix[i+1]/a, 1)*255 + 0.5)
+		out.Pix[i+2] = uint8(min(pix[i+2]/a, 1)*255 + 0.5)
+		out.Pix[i+3] = uint8(min(a, 1)*255 + 0.5)
+	}
+	return out
+}
+
+// view returns the transform from the document's coordinates to pixels of
+// an image of a size, fitting the view box in the middle of it
+func (img *Image) view(width, height float32) render.Matrix {
+	vb := img.viewBox
+	if vb[2] <= 0 || vb[3] <= 0 {
+		return render.Identity()
+	}
+	s := min(width/vb[2], height/vb[3])
+	return render.Matrix{
+		A: s,
+		D: s,
+		E: (width-vb[2]*s)/2 - vb[0]*s,
+		F: (height-vb[3]*s)/2 - vb[1]*s,
+	}
+}
@@ -0,0 +1,174 @@
+package svg
+
+import (
+	"image/color"
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name          string
+		doc           string
+		width, height float32
+		viewBox       [4]float32
+		shapes        int
+		err           error
+	}{
+		{
+			name:  "size and view box",
+			doc:   `<svg width="48" height="24" viewBox="0 0 24 12"><rect width="24" height="12"/></svg>`,
+			width: 48, height: 24, viewBox: [4]float32{0, 0, 24, 12}, shapes: 1,
+		},
+		{
+			name:  "view box sets the size",
+			doc:   `<svg viewBox="2 4 16 8"><circle cx="8" cy="8" r="4"/><path d="M0 0L4 4"/></svg>`,
+			width: 16, height: 8, viewBox: [4]float32{2, 4, 16, 8}, shapes: 2,
+		},
+		{
+			name:  "unsupported elements are left out",
+			doc:   `<svg width="10" height="10"><defs><rect width="1" height="1"/></defs><text>hi</text><line x2="10" y2="10"/></svg>`,
+			width: 10, height: 10, viewBox: [4]float32{0, 0, 10, 10}, shapes: 1,
+		},
+		{name: "not svg", doc: `<html><body/></html>`, err: errNotSVG},
+		{name: "no size", doc: `<svg><rect width="1" height="1"/></svg>`, err: errNoSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := Parse([]byte(tt.doc))
+			if err != tt.err {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if img.Width != tt.width || img.Height != tt.height || img.viewBox != tt.viewBox {
+				t.Errorf("got %gx%g view box %v, want %gx%g view box %v",
+					img.Width, img.Height, img.viewBox, tt.width, tt.height, tt.viewBox)
+			}
+			if len(img.shapes) != tt.shapes {
+				t.Errorf("got %d shapes, want %d", len(img.shapes), tt.shapes)
+			}
+		})
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		v    string
+		want [4]float32
+		ok   bool
+	}{
+		{"red", [4]float32{1, 0, 0, 1}, true},
+		{" Blue ", [4]float32{0, 0, 1, 1}, true},
+		{"#fff", [4]float32{1, 1, 1, 1}, true},
+		{"#ff000080", [4]float32{1, 0, 0, 128.0 / 255}, true},
+		{"#00ff00", [4]float32{0, 1, 0, 1}, true},
+		{"rgb(255, 0, 0)", [4]float32{1, 0, 0, 1}, true},
+		{"rgba(0 0 255 / 50%)", [4]float32{0, 0, 1, 0.5}, true},
+		{"#12", [4]float32{}, false},
+		{"#zzzzzz", [4]float32{}, false},
+		{"chartreuse", [4]float32{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseColor(tt.v)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("%q: got %v %v, want %v %v", tt.v, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		d     string
+		verbs []verb
+		// last is the end point of the last segment
+		last [2]float32
+	}{
+		{"M1 2 L3 4", []verb{verbMove, verbLine}, [2]float32{3, 4}},
+		{"m1,2 l3,4 z", []verb{verbMove, verbLine, verbClose}, [2]float32{1, 2}},
+		{"M0 0H10V5h-2", []verb{verbMove, verbLine, verbLine, verbLine}, [2]float32{8, 5}},
+		{"M0 0 10 10 20 0", []verb{verbMove, verbLine, verbLine}, [2]float32{20, 0}},
+		{"M0 0C1 1 2 1 3 0S5 -1 6 0", []verb{verbMove, verbCubic, verbCubic}, [2]float32{6, 0}},
+		{"M0 0Q5 5 10 0", []verb{verbMove, verbCubic}, [2]float32{10, 0}},
+		{"M1.5e1-2.5", []verb{verbMove}, [2]float32{15, -2.5}},
+	}
+	for _, tt := range tests {
+		segments := parsePath(tt.d)
+		var verbs []verb
+		for _, s := range segments {
+			verbs = append(verbs, s.verb)
+		}
+		if !slices.Equal(verbs, tt.verbs) {
+			t.Errorf("%q: got verbs %v, want %v", tt.d, verbs, tt.verbs)
+			continue
+		}
+		s := segments[len(segments)-1]
+		last := s.points[0]
+		if s.verb == verbCubic {
+			last = s.points[2]
+		}
+		if s.verb != verbClose && last != tt.last {
+			t.Errorf("%q: ends at %v, want %v", tt.d, last, tt.last)
+		}
+	}
+}
+
+func TestParseTransform(t *testing.T) {
+	tests := []struct {
+		v    string
+		x, y float32
+	}{
+		{"translate(10 5)", 11, 6},
+		{"scale(2)", 2, 2},
+		{"scale(2, 3)", 2, 3},
+		{"translate(10) scale(2)", 12, 2},
+		{"rotate(90)", -1, 1},
+		{"rotate(180 1 1)", 1, 1},
+		{"matrix(1 0 0 1 4 4)", 5, 5},
+		{"bogus(1)", 1, 1},
+	}
+	for _, tt := range tests {
+		x, y := parseTransform(tt.v).Apply(1, 1)
+		if abs32(x-tt.x) > 1e-4 || abs32(y-tt.y) > 1e-4 {
+			t.Errorf("%q maps (1, 1) to (%g, %g), want (%g, %g)", tt.v, x, y, tt.x, tt.y)
+		}
+	}
+}
+
+func abs32(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func TestRasterize(t *testing.T) {
+	img, err := Parse([]byte(`<svg viewBox="0 0 20 10">
+		<rect width="10" height="10" fill="currentColor"/>
+		<rect x="10" width="10" height="10" fill="#00f" opacity="0.5"/>
+	</svg>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The view box is fitted in the middle, leaving a band above and below
+	out := img.Rasterize(40, 40, [4]float32{1, 0, 0, 1})
+	tests := []struct {
+		x, y int
+		want color.NRGBA
+	}{
+		{10, 20, color.NRGBA{255, 0, 0, 255}},
+		{30, 20, color.NRGBA{0, 0, 255, 128}},
+		{10, 5, color.NRGBA{}},
+		{30, 35, color.NRGBA{}},
+	}
+	for _, tt := range tests {
+		if got := out.NRGBAAt(tt.x, tt.y); got != tt.want {
+			t.Errorf("pixel %d,%d: got %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+	mono := img.RasterizeMono(40, 40, [4]float32{0, 1, 0, 1})
+	if got := mono.NRGBAAt(30, 20); got != (color.NRGBA{0, 255, 0, 128}) {
+		t.Errorf("mono pixel: got %v", got)
+	}
+}
@@ -0,0 +1,259 @@
+package syntax
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	// inComment is the state inside a block comment
+	inComment State = iota + 1
+	// inString is the state inside a string that spans lines
+	inString
+)
+
+// Language is a Lexer for the many languages shaped like C: identifiers,
+// keywords, numbers, quoted strings, line and block comments and operators.
+type Language struct {
+	// Keywords and Types are the reserved words and built-in type names
+	Keywords, Types []string
+	// LineComment starts a comment running to the end of the line
+	LineComment string
+	// BlockStart and BlockEnd enclose comments that may span lines
+	BlockStart, BlockEnd string
+	// Quotes are the runes that start and end strings on one line, which
+	// a backslash escapes within them
+	Quotes string
+	// Multiline is a quote of strings that may span lines without escapes,
+	// 0 for none
+	Multiline rune
+
+	once                  sync.Once
+	words                 map[string]Kind
+	line, start, blockEnd []rune
+}
+
+// Go lexes Go source
+var Go = &Language{
+	Keywords: []string{
+		"break", "case", "chan", "const", "continue", "default", "defer", "else",
+		"fallthrough", "for", "func", "go", "goto", "if", "import", "interface",
+		"map", "package", "range", "return", "select", "struct", "switch", "type",
+		"var", "true", "false", "nil", "iota",
+	},
+	Types: []string{
+		"any", "bool", "byte", "comparable", "complex64", "complex128", "error",
+		"float32", "float64", "int", "int8", "int16", "int32", "int64", "rune",
+		"string", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+	},
+	LineComment: "//",
+	BlockStart:  "/*",
+	BlockEnd:    "*/",
+	Quotes:      `"'`,
+	Multiline:   '`',
+}
+
+// C lexes C source
+var C = &Language{
+	Keywords: []string{
+		"auto", "break", "case", "const", "continue", "default", "do", "else",
+		"enum", "extern", "for", "goto", "if", "inline", "register", "restrict",
+		"return", "sizeof", "static", "struct", "switch", "typedef", "union",
+		"volatile", "while", "NULL", "true", "false",
+	},
+	Types: []string{
+		"bool", "char", "double", "float", "int", "long", "short", "signed",
+		"unsigned", "void", "size_t", "int8_t", "int16_t", "int32_t", "int64_t",
+		"uint8_t", "uint16_t", "uint32_t", "uint64_t",
+	},
+	LineComment: "//",
+	BlockStart:  "/*",
+	BlockEnd:    "*/",
+	Quotes:      `"'`,
+}
+
+// JavaScript lexes JavaScript source
+var JavaScript = &Language{
+	Keywords: []string{
+		"async", "await", "break", "case", "catch", "class", "const", "continue",
+		"debugger", "default", "delete", "do", "else", "export", "extends",
+		"finally", "for", "function", "if", "import", "in", "instanceof", "let",
+		"new", "of", "return", "static", "super", "switch", "this", "throw",
+		"try", "typeof", "var", "void", "while", "yield", "true", "false",
+		"null", "undefined",
+	},
+	Types: []string{
+		"Array", "Boolean", "Date", "Error", "Map", "Number", "Object",
+		"Promise", "RegExp", "Set", "String", "Symbol",
+	},
+	LineComment: "//",
+	BlockStart:  "/*",
+	BlockEnd:    "*/",
+	Quotes:      `"'`,
+	Multiline:   '`',
+}
+
+// Python lexes Python source
+var Python = &Language{
+	Keywords: []string{
+		"and", "as", "assert", "async", "await", "break", "class", "continue",
+		"def", "del", "elif", "else", "except", "finally", "for", "from",
+		"global", "if", "import", "in", "is", "lambda", "nonlocal", "not", "or",
+		"pass", "raise", "return", "try", "while", "with", "yield", "True",
+		"False", "None",
+	},
+	Types: []string{
+		"bool", "bytes", "complex", "dict", "float", "frozenset", "int", "list",
+		"object", "set", "str", "tuple",
+	},
+	LineComment: "#",
+	Quotes:      `"'`,
+}
+
+// JSON lexes JSON documents
+var JSON = &Language{
+	Keywords: []string{"true", "false", "null"},
+	Quotes:   `"`,
+}
+
+// compile builds the lookup tables on first use
+func (l *Language) compile() {
+	l.once.Do(func() {
+		l.words = make(map[string]Kind, len(l.Keywords)+len(l.Types))
+		for _, w := range l.Types {
+			l.words[w] = Type
+		}
+		for _, w := range l.Keywords {
+			l.words[w] = Keyword
+		}
+		l.line = []rune(l.LineComment)
+		l.start = []rune(l.BlockStart)
+		l.blockEnd = []rune(l.BlockEnd)
+	})
+}
+
+// Lex implements Lexer
+func (l *Language) Lex(line []rune, state State, tokens []Token) ([]Token, State) {
+	l.compile()
+	i, n := 0, len(line)
+	switch state {
+	case inComment:
+		end, ok := find(line, 0, l.blockEnd)
+		tokens = append(tokens, Token{Kind: Comment, Start: 0, End: end})
+		if !ok {
+			return tokens, inComment
+		}
+		i = end
+	case inString:
+		end, ok := find(line, 0, []rune{l.Multiline})
+		tokens = append(tokens, Token{Kind: String, Start: 0, End: end})
+		if !ok {
+			return tokens, inString
+		}
+		i = end
+	}
+	for i < n {
+		c := line[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case len(l.line) > 0 && hasPrefix(line, i, l.line):
+			return append(tokens, Token{Kind: Comment, Start: i, End: n}), 0
+		case len(l.start) > 0 && hasPrefix(line, i, l.start):
+			end, ok := find(line, i+len(l.start), l.blockEnd)
+			tokens = append(tokens, Token{Kind: Comment, Start: i, End: end})
+			if !ok {
+				return tokens, inComment
+			}
+			i = end
+		case l.Multiline != 0 && c == l.Multiline:
+			end, ok := find(line, i+1, []rune{c})
+			tokens = append(tokens, Token{Kind: String, Start: i, End: end})
+			if !ok {
+				return tokens, inString
+			}
+			i = end
+		case strings.ContainsRune(l.Quotes, c):
+			end := i + 1
+			for end < n && line[end] != c {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, n)
+			tokens = append(tokens, Token{Kind: String, Start: i, End: end})
+			i = end
+		case unicode.IsDigit(c) || c == '.' && i+1 < n && unicode.IsDigit(line[i+1]):
+			end := i + 1
+			for end < n && (isWord(line[end]) || line[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, Token{Kind: Number, Start: i, End: end})
+			i = end
+		case isWord(c):
+			end := i + 1
+			for end < n && isWord(line[end]) {
+				end++
+			}
+			if kind, ok := l.words[string(line[i:end])]; ok {
+				tokens = append(tokens, Token{Kind: kind, Start: i, End: end})
+			} else if next := skipSpace(line, end); next < n && line[next] == '(' {
+				tokens = append(tokens, Token{Kind: Function, Start: i, End: end})
+			}
+			i = end
+		case unicode.IsPunct(c) || unicode.IsSymbol(c):
+			end := i + 1
+			// Operators stop where comments and strings start
+			for end < n && (unicode.IsPunct(line[end]) || unicode.IsSymbol(line[end])) &&
+				!strings.ContainsRune(l.Quotes, line[end]) && line[end] != l.Multiline &&
+				!(len(l.line) > 0 && hasPrefix(line, end, l.line)) &&
+				!(len(l.start) > 0 && hasPrefix(line, end, l.start)) {
+				end++
+			}
+			tokens = append(tokens, Token{Kind: Operator, Start: i, End: end})
+			i = end
+		default:
+			i++
+		}
+	}
+	return tokens, 0
+}
+
+// isWord reports whether a rune may be part of an identifier
+func isWord(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// hasPrefix reports whether the line holds prefix at i
+func hasPrefix(line []rune, i int, prefix []rune) bool {
+	if i+len(prefix) > len(line) {
+		return false
+	}
+	for j, r := range prefix {
+		if line[i+j] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// find returns the index just past the first occurrence of end in the line
+// from i, or the length of the line and false when there is none
+func find(line []rune, i int, end []rune) (after int, ok bool) {
+	for ; i < len(line); i++ {
+		if hasPrefix(line, i, end) {
+			return i + len(end), true
+		}
+	}
+	return len(line), false
+}
+
+// skipSpace returns the index of the first rune from i that is not a space
+func skipSpace(line []rune, i int) int {
+	for i < len(line) && unicode.IsSpace(line[i]) {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,116 @@
+package syntax
+
+import (
+	"slices"
+	"testing"
+)
+
+// kindNames name the kinds of tokens in test failures
+var kindNames = map[Kind]string{
+	Plain:    "plain",
+	Keyword:  "keyword",
+	Type:     "type",
+	Function: "function",
+	String:   "string",
+	Number:   "number",
+	Comment:  "comment",
+	Operator: "operator",
+}
+
+// spans returns the tokens of a line as the kind and text of each
+func spans(line []rune, tokens []Token) (s []string) {
+	for _, t := range tokens {
+		s = append(s, kindNames[t.Kind]+" "+string(line[t.Start:t.End]))
+	}
+	return
+}
+
+func TestLex(t *testing.T) {
+	tests := []struct {
+		name   string
+		lang   *Language
+		line   string
+		state  State
+		tokens []string
+		end    State
+	}{
+		{
+			name: "go declaration",
+			lang: Go, line: "func main() int {",
+			tokens: []string{"keyword func", "function main", "operator ()", "type int", "operator {"},
+		},
+		{
+			name: "numbers and strings",
+			lang: Go, line: `x := 0x1F + 1.5 + "a\"b" + 'c'`,
+			tokens: []string{"operator :=", "number 0x1F", "operator +", "number 1.5", "operator +",
+				`string "a\"b"`, "operator +", "string 'c'"},
+		},
+		{
+			name: "line comment",
+			lang: Go, line: "return // done",
+			tokens: []string{"keyword return", "comment // done"},
+		},
+		{
+			name: "operator stops at a comment",
+			lang: C, line: "a+/* b */",
+			tokens: []string{"operator +", "comment /* b */"},
+		},
+		{
+			name: "block comment runs on",
+			lang: Go, line: "x /* start",
+			tokens: []string{"comment /* start"}, end: inComment,
+		},
+		{
+			name: "block comment ends",
+			lang: Go, line: "end */ nil", state: inComment,
+			tokens: []string{"comment end */", "keyword nil"},
+		},
+		{
+			name: "block comment continues",
+			lang: Go, line: "still inside", state: inComment,
+			tokens: []string{"comment still inside"}, end: inComment,
+		},
+		{
+			name: "raw string runs on",
+			lang: Go, line: "s := `one",
+			tokens: []string{"operator :=", "string `one"}, end: inString,
+		},
+		{
+			name: "raw string ends",
+			lang: Go, line: "two` + s", state: inString,
+			tokens: []string{"string two`", "operator +"},
+		},
+		{
+			name: "unterminated string ends with the line",
+			lang: Python, line: `print("oops`,
+			tokens: []string{"function print", "operator (", `string "oops`},
+		},
+		{
+			name: "json",
+			lang: JSON, line: `{"a": [true, null, -2]}`,
+			tokens: []string{"operator {", `string "a"`, "operator :", "operator [", "keyword true",
+				"operator ,", "keyword null", "operator ,", "operator -", "number 2", "operator ]}"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := []rune(tt.line)
+			tokens, end := tt.lang.Lex(line, tt.state, nil)
+			if got := spans(line, tokens); !slices.Equal(got, tt.tokens) {
+				t.Errorf("got %q, want %q", got, tt.tokens)
+			}
+			if end != tt.end {
+				t.Errorf("ends in state %d, want %d", end, tt.end)
+			}
+		})
+	}
+}
+
+func TestLexAppends(t *testing.T) {
+	line := []rune("if")
+	before := []Token{{Kind: Comment, Start: 0, End: 1}}
+	tokens, _ := Go.Lex(line, 0, before)
+	if len(tokens) != 2 || tokens[0] != before[0] || tokens[1] != (Token{Kind: Keyword, Start: 0, End: 2}) {
+		t.Errorf("got %v, want the line's tokens appended", tokens)
+	}
+}
@@ -0,0 +1,40 @@
+// Package syntax splits source code into tokens for syntax coloring. Lexers
+// work one line at a time, carrying a small state from the end of each line
+// to the next, so editors only lex the lines they show and relex from the
+// first line an edit touches.
+package syntax
+
+// Kind is the kind of a token, which editors color by
+type Kind int
+
+const (
+	// Plain is text with no special meaning, such as names
+	Plain Kind = iota
+	Keyword
+	// Type is the name of a built-in type
+	Type
+	// Function is a name followed by an opening parenthesis
+	Function
+	String
+	Number
+	Comment
+	// Operator is a run of punctuation and symbols, including brackets
+	Operator
+)
+
+// Token is a colored range of a line, in runes
+type Token struct {
+	Kind       Kind
+	Start, End int
+}
+
+// State is what a lexer carries from the end of one line to the start of
+// the next, such as being inside a block comment. Files start in state 0.
+type State int
+
+// Lexer splits lines of source code into tokens. Lex appends the tokens of
+// a line lexed from a state to tokens and returns them with the state at the
+// end of the line. Runs of plain text between tokens need not be returned.
+type Lexer interface {
+	Lex(line []rune, state State, tokens []Token) ([]Token, State)
+}
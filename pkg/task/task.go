@@ -0,0 +1,198 @@
+// Package task runs work in the background while the window keeps drawing,
+// reporting its progress and result through observable state. Widgets bind
+// to the state, such as a progress bar to a task's progress and a spinner to
+// whether it is running, so they follow the task without polling it.
+//
+// The state of a task only changes on the goroutine running the window:
+// progress reported by the job and its result are handed to the window
+// through its RunOnUIThread, and reports made faster than the window draws
+// are merged so the latest is shown.
+package task
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/mleku/goo/pkg/state"
+)
+
+// UI runs functions on the goroutine running the window, as window.Window
+// and headless.Screen do
+type UI interface {
+	RunOnUIThread(fn func())
+}
+
+// Status is where a task has got to
+type Status int
+
+const (
+	Running Status = iota
+	Done
+	Failed
+	Cancelled
+)
+
+// Reporter is passed to a job to report its progress. Its methods are safe
+// to call from the job's goroutine.
+type Reporter interface {
+	// Progress reports the fraction of the work done, from 0 to 1, or a
+	// negative fraction while the amount of work is not known
+	Progress(fraction float32)
+	// Message reports what the job is doing, such as the file it is reading
+	Message(message string)
+}
+
+// Job is the work a task does, returning its result. It stops early
+// returning the context's error when the context is cancelled.
+type Job[T any] func(ctx context.Context, report Reporter) (result T, err error)
+
+// Task is a job running in the background
+type Task[T any] struct {
+	// Progress is the fraction of the work done from 0 to 1, negative while
+	// the amount is not known, for binding to a progress bar
+	Progress *state.State[float32]
+	// Message is what the job last said it was doing
+	Message *state.State[string]
+	// Status is where the task has got to, and Active whether it is still
+	// running, for binding to a spinner or a cancel button
+	Status *state.State[Status]
+	Active *state.State[bool]
+
+	ui     UI
+	cancel context.CancelFunc
+	done   chan struct{}
+	onDone func(result T, err error)
+
+	// mu guards the job's result and the reports waiting to be shown, and
+	// posted is set while a call showing them is queued on the window
+	mu       sync.Mutex
+	result   T
+	err      error
+	progress float32
+	message  string
+	posted   bool
+}
+
+// Start runs a job on a goroutine of its own, reporting its progress and
+// result through the window. Cancelling the context, or the task, cancels
+// the job. Call it from the goroutine running the window.
+func Start[T any](ctx context.Context, ui UI, job Job[T]) *Task[T] {
+	ctx, cancel := context.WithCancel(ctx)
+	t := &Task[T]{
+		Progress: state.New[float32](-1),
+		Message:  state.New(""),
+		Status:   state.New(Running),
+		Active:   state.New(true),
+		ui:       ui,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		progress: -1,
+	}
+	go func() {
+		result, err := job(ctx, reporter[T]{t})
+		cancel()
+		// The result is kept and Done closed here, so waiting on the task
+		// ends even when the window closed before the job did and drops
+		// the call showing it
+		t.mu.Lock()
+		t.result, t.err = result, err
+		t.mu.Unlock()
+		close(t.done)
+		ui.RunOnUIThread(t.finish)
+	}()
+	return t
+}
+
+// OnDone sets the callback invoked on the goroutine running the window with
+// the job's result when it ends, and returns the task for chaining. A task
+// already ended calls it straight away.
+func (t *Task[T]) OnDone(fn func(result T, err error)) *Task[T] {
+	t.onDone = fn
+	if fn != nil && t.Status.Get() != Running {
+		fn(t.Result())
+	}
+	return t
+}
+
+// Cancel cancels the job's context. The task ends as Cancelled once the job
+// returns.
+func (t *Task[T]) Cancel() {
+	t.cancel()
+}
+
+// Done returns a channel closed when the job has returned, for waiting on
+// it from other goroutines. The task's state shows it ended from the
+// window's next frame.
+func (t *Task[T]) Done() <-chan struct{} {
+	return t.done
+}
+
+// Result returns the job's result and error once Done is closed
+func (t *Task[T]) Result() (result T, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.result, t.err
+}
+
+// finish shows the job's result on the goroutine running the window
+func (t *Task[T]) finish() {
+	t.show()
+	result, err := t.Result()
+	switch {
+	case errors.Is(err, context.Canceled):
+		t.Status.Set(Cancelled)
+	case err != nil:
+		t.Status.Set(Failed)
+	default:
+		t.Progress.Set(1)
+		t.Status.Set(Done)
+	}
+	t.Active.Set(false)
+	if t.onDone != nil {
+		t.onDone(result, err)
+	}
+}
+
+// report records a report from the job, queueing a call to show it on the
+// window unless one is already waiting
+func (t *Task[T]) report(fn func()) {
+	t.mu.Lock()
+	fn()
+	post := !t.posted
+	t.posted = true
+	t.mu.Unlock()
+	if post {
+		t.ui.RunOnUIThread(t.show)
+	}
+}
+
+// show sets the state to the latest reports
+func (t *Task[T]) show() {
+	t.mu.Lock()
+	progress, message := t.progress, t.message
+	t.posted = false
+	t.mu.Unlock()
+	t.Progress.Set(progress)
+	t.Message.Set(message)
+}
+
+// reporter passes a job's reports to its task
+type reporter[T any] struct {
+	t *Task[T]
+}
+
+// Progress implements Reporter
+func (r reporter[T]) Progress(fraction float32) {
+	r.t.report(func() {
+		if fraction >= 0 {
+			fraction = min(fraction, 1)
+		}
+		r.t.progress = fraction
+	})
+}
+
+// Message implements Reporter
+func (r reporter[T]) Message(message string) {
+	r.t.report(func() { r.t.message = message })
+}
@@ -0,0 +1,169 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// queue is a UI that holds the calls made to it until they are run
+type queue struct {
+	mu     sync.Mutex
+	queued sync.Cond
+	calls  []func()
+}
+
+// newQueue creates a queue holding no calls
+func newQueue() *queue {
+	q := &queue{}
+	q.queued.L = &q.mu
+	return q
+}
+
+// RunOnUIThread implements UI
+func (q *queue) RunOnUIThread(fn func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.calls = append(q.calls, fn)
+	q.queued.Broadcast()
+}
+
+// waiting returns how many calls are queued
+func (q *queue) waiting() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.calls)
+}
+
+// run runs the calls queued so far, as a window does each frame
+func (q *queue) run() {
+	q.mu.Lock()
+	calls := q.calls
+	q.calls = nil
+	q.mu.Unlock()
+	for _, fn := range calls {
+		fn()
+	}
+}
+
+// runUntil runs the calls as they are queued until ended reports true
+func (q *queue) runUntil(ended func() bool) {
+	for !ended() {
+		q.mu.Lock()
+		for len(q.calls) == 0 {
+			q.queued.Wait()
+		}
+		q.mu.Unlock()
+		q.run()
+	}
+}
+
+var errJob = errors.New("job failed")
+
+func TestEnd(t *testing.T) {
+	tests := []struct {
+		name   string
+		job    Job[int]
+		cancel bool
+		status Status
+		result int
+		err    error
+	}{
+		{
+			name: "done",
+			job: func(context.Context, Reporter) (int, error) {
+				return 42, nil
+			},
+			status: Done, result: 42,
+		},
+		{
+			name: "failed",
+			job: func(context.Context, Reporter) (int, error) {
+				return 0, errJob
+			},
+			status: Failed, err: errJob,
+		},
+		{
+			name: "cancelled",
+			job: func(ctx context.Context, _ Reporter) (int, error) {
+				<-ctx.Done()
+				return 0, ctx.Err()
+			},
+			cancel: true,
+			status: Cancelled, err: context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ui := newQueue()
+			task := Start(context.Background(), ui, tt.job)
+			var ended int
+			task.OnDone(func(result int, err error) {
+				ended++
+				if result != tt.result || err != tt.err {
+					t.Errorf("OnDone got %d, %v, want %d, %v", result, err, tt.result, tt.err)
+				}
+			})
+			if tt.cancel {
+				task.Cancel()
+			}
+			<-task.Done()
+			// The result is kept as Done closes, before the window shows it
+			if result, err := task.Result(); result != tt.result || err != tt.err {
+				t.Errorf("Result got %d, %v, want %d, %v", result, err, tt.result, tt.err)
+			}
+			if task.Status.Get() != Running || !task.Active.Get() {
+				t.Error("state changed off the window's goroutine")
+			}
+			ui.runUntil(func() bool { return !task.Active.Get() })
+			if got := task.Status.Get(); got != tt.status {
+				t.Errorf("status %d, want %d", got, tt.status)
+			}
+			if task.Active.Get() {
+				t.Error("still active")
+			}
+			if ended != 1 {
+				t.Errorf("OnDone called %d times, want 1", ended)
+			}
+			// A callback set after the end is called straight away
+			task.OnDone(func(int, error) { ended++ })
+			if ended != 2 {
+				t.Error("OnDone after the end was not called")
+			}
+		})
+	}
+}
+
+func TestReportsMerge(t *testing.T) {
+	ui := newQueue()
+	reported, proceed := make(chan struct{}), make(chan struct{})
+	task := Start(context.Background(), ui, func(_ context.Context, report Reporter) (string, error) {
+		report.Progress(0.25)
+		report.Message("reading")
+		report.Progress(2)
+		close(reported)
+		<-proceed
+		report.Progress(-1)
+		return "ok", nil
+	})
+	<-reported
+	if n := ui.waiting(); n != 1 {
+		t.Fatalf("%d calls queued for three reports, want 1", n)
+	}
+	if task.Progress.Get() != -1 || task.Message.Get() != "" {
+		t.Error("reports shown before the window ran")
+	}
+	ui.run()
+	if got := task.Progress.Get(); got != 1 {
+		t.Errorf("progress %g, want the latest report clipped to 1", got)
+	}
+	if got := task.Message.Get(); got != "reading" {
+		t.Errorf("message %q, want %q", got, "reading")
+	}
+	close(proceed)
+	ui.runUntil(func() bool { return !task.Active.Get() })
+	if task.Status.Get() != Done || task.Progress.Get() != 1 {
+		t.Errorf("status %d progress %g, want done at 1", task.Status.Get(), task.Progress.Get())
+	}
+}
@@ -0,0 +1,67 @@
+package text
+
+import (
+	"github.com/mleku/goo/pkg/render"
+)
+
+// maxAtlasSize is the largest texture dimension the atlas grows to
+const maxAtlasSize = 4096
+
+// Atlas packs glyph bitmaps into a single channel texture using shelf packing
+type Atlas struct {
+	texture *render.Texture
+	// Current shelf position and height
+	x, y, rowHeight int
+}
+
+// NewAtlas creates an empty atlas of the given size, for packing bitmaps
+// other than a face's glyphs, such as icons
+func NewAtlas(width, height int) *Atlas {
+	return &Atlas{
+		texture: render.NewTexture(width, height, render.FormatAlpha, make([]byte, width*height)),
+	}
+}
+
+// Texture returns the texture holding the glyph bitmaps
+func (a *Atlas) Texture() *render.Texture {
+	return a.texture
+}
+
+// Add copies a coverage bitmap w by h pixels into the atlas and returns its
+// position. When the atlas is full it doubles in height up to maxAtlasSize,
+// after which it is cleared and reset reports that previously returned
+// positions are no longer valid.
+func (a *Atlas) Add(w, h int, mask []byte) (x, y int, reset bool) {
+	t := a.texture
+	// Leave a one pixel gutter so linear filtering does not bleed
+	if a.x+w+1 > t.Width {
+		a.x = 0
+		a.y += a.rowHeight
+		a.rowHeight = 0
+	}
+	for a.y+h+1 > t.Height {
+		if t.Height*2 > maxAtlasSize {
+			clear(t.Pixels)
+			a.x, a.y, a.rowHeight = 0, 0, 0
+			reset = true
+			break
+		}
+		grown := make([]byte, t.Width*t.Height*2)
+		copy(grown, t.Pixels)
+		t.Pixels = grown
+		t.Height *= 2
+	}
+	if w+1 > t.Width || h+1 > t.Height {
+		return
+	}
+	x, y = a.x, a.y
+	for row := 0; row < h; row++ {
+		copy(t.Pixels[(y+row)*t.Width+x:], mask[row*w:(row+1)*w])
+	}
+	a.x += w + 1
+	if h+1 > a.rowHeight {
+		a.rowHeight = h + 1
+	}
+	t.Invalidate()
+	return
+}
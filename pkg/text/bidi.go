@@ -0,0 +1,298 @@
+package text
+
+import (
+	"unicode"
+)
+
+// bidiClass is the bidirectional type of a rune from the Unicode
+// Bidirectional Algorithm (UAX #9)
+type bidiClass uint8
+
+const (
+	bidiL   bidiClass = iota // left to right letters
+	bidiR                    // right to left letters, such as Hebrew
+	bidiAL                   // Arabic letters
+	bidiEN                   // European digits
+	bidiES                   // plus and minus
+	bidiET                   // currency, percent and degree signs
+	bidiAN                   // Arabic digits
+	bidiCS                   // number separators
+	bidiNSM                  // combining marks
+	bidiBN                   // format characters with no direction
+	bidiB                    // paragraph separators
+	bidiS                    // tabs
+	bidiWS                   // spaces
+	bidiON                   // other punctuation and symbols
+)
+
+// classify returns the bidirectional type of a rune. Explicit embedding,
+// override and isolate controls are not supported and count as format
+// characters.
+func classify(r rune) bidiClass {
+	switch {
+	case r >= '0' && r <= '9', r >= 0x06F0 && r <= 0x06F9, r >= 0xFF10 && r <= 0xFF19:
+		return bidiEN
+	case r >= 0x0660 && r <= 0x0669, r == 0x066B || r == 0x066C:
+		return bidiAN
+	case r == '+' || r == '-' || r == 0x2212:
+		return bidiES
+	case r == '#' || r == '$' || r == '%' || r == 0xB0 || r == 0x2030 || unicode.Is(unicode.Sc, r):
+		return bidiET
+	case r == ',' || r == '.' || r == '/' || r == ':' || r == 0xA0 || r == 0x060C:
+		return bidiCS
+	case r == '\n' || r == '\r' || r == 0x1C || r == 0x1D || r == 0x1E || r == 0x85 || r == 0x2029:
+		return bidiB
+	case r == '\t' || r == 0x0B || r == 0x1F:
+		return bidiS
+	case r == 0x200E:
+		return bidiL
+	case r == 0x200F:
+		return bidiR
+	case r == 0x061C:
+		return bidiAL
+	case r == 0x200B || r == 0x200C || r == 0x200D || r >= 0x202A && r <= 0x202E ||
+		r >= 0x2060 && r <= 0x206F || r == 0xFEFF || unicode.Is(unicode.Cc, r):
+		return bidiBN
+	case unicode.In(r, unicode.Mn, unicode.Me):
+		return bidiNSM
+	case unicode.IsSpace(r):
+		return bidiWS
+	case r >= 0x0590 && r <= 0x05FF, r >= 0x07C0 && r <= 0x085F, r >= 0xFB1D && r <= 0xFB4F,
+		r >= 0x10800 && r <= 0x10FFF, r >= 0x1E800 && r <= 0x1EDFF:
+		return bidiR
+	case r >= 0x0600 && r <= 0x07BF, r >= 0x0860 && r <= 0x08FF, r >= 0xFB50 && r <= 0xFDFF,
+		r >= 0xFE70 && r <= 0xFEFF, r >= 0x1EE00 && r <= 0x1EEFF:
+		return bidiAL
+	case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+		return bidiL
+	}
+	return bidiON
+}
+
+// rtlStrong reports whether a resolved type counts as right to left for
+// neutrals, where numbers act as right to left text
+func rtlStrong(c bidiClass) bool {
+	return c == bidiR || c == bidiAL || c == bidiEN || c == bidiAN
+}
+
+// bidiLevels resolves the embedding level of each rune of a line, even for
+// left to right and odd for right to left. The base level is that of the
+// first strong letter, left to right if there is none. The explicit
+// embedding controls are not supported, so levels never exceed 2.
+func bidiLevels(runes []rune) (levels []uint8, rtl bool) {
+	n := len(runes)
+	types := make([]bidiClass, n)
+	for i, r := range runes {
+		types[i] = classify(r)
+	}
+	// P2, P3: the first strong letter sets the paragraph direction
+	for _, t := range types {
+		if t == bidiL {
+			break
+		}
+		if t == bidiR || t == bidiAL {
+			rtl = true
+			break
+		}
+	}
+	base := uint8(0)
+	sos := bidiL
+	if rtl {
+		base, sos = 1, bidiR
+	}
+
+	// W1: marks take the type of what they follow
+	prev := sos
+	for i, t := range types {
+		switch t {
+		case bidiNSM:
+			types[i] = prev
+		case bidiBN:
+		default:
+			prev = t
+		}
+	}
+	// W2, W3: digits after Arabic letters are Arabic digits and Arabic
+	// letters are right to left
+	strongest := sos
+	for i, t := range types {
+		switch t {
+		case bidiL, bidiR:
+			strongest = t
+		case bidiAL:
+			strongest = t
+			types[i] = bidiR
+		case bidiEN:
+			if strongest == bidiAL {
+				types[i] = bidiAN
+			}
+		}
+	}
+	// W4: a single separator between two numbers of a kind joins them
+	for i := 1; i+1 < n; i++ {
+		a, b := types[i-1], types[i+1]
+		switch {
+		case types[i] == bidiES && a == bidiEN && b == bidiEN:
+			types[i] = bidiEN
+		case types[i] == bidiCS && a == b && (a == bidiEN || a == bidiAN):
+			types[i] = a
+		}
+	}
+	// W5: terminators next to European digits become digits
+	for i := 0; i < n; i++ {
+		if types[i] != bidiET {
+			continue
+		}
+		j := i
+		for j < n && types[j] == bidiET {
+			j++
+		}
+		if i > 0 && types[i-1] == bidiEN || j < n && types[j] == bidiEN {
+			for k := i; k < j; k++ {
+				types[k] = bidiEN
+			}
+		}
+		i = j
+	}
+	// W6, W7: other separators are neutral, and European digits in left to
+	// right text are left to right
+	strongest = sos
+	for i, t := range types {
+		switch t {
+		case bidiES, bidiET, bidiCS:
+			types[i] = bidiON
+		case bidiL, bidiR:
+			strongest = t
+		case bidiEN:
+			if strongest == bidiL {
+				types[i] = bidiL
+			}
+		}
+	}
+	// N1, N2: neutrals between text of one direction take it, and others
+	// take the base direction
+	for i := 0; i < n; i++ {
+		if !neutral(types[i]) {
+			continue
+		}
+		j := i
+		for j < n && neutral(types[j]) {
+			j++
+		}
+		before, after := sos, sos
+		if i > 0 {
+			before = types[i-1]
+		}
+		if j < n {
+			after = types[j]
+		}
+		resolved := sos
+		if rtlStrong(before) == rtlStrong(after) {
+			resolved = bidiL
+			if rtlStrong(before) {
+				resolved = bidiR
+			}
+		}
+		for k := i; k < j; k++ {
+			types[k] = resolved
+		}
+		i = j
+	}
+	// I1, I2: implicit levels
+	levels = make([]uint8, n)
+	for i, t := range types {
+		level := base
+		switch {
+		case base == 0 && t == bidiR:
+			level++
+		case base == 0 && (t == bidiAN || t == bidiEN):
+			level += 2
+		case base == 1 && (t == bidiL || t == bidiEN || t == bidiAN):
+			level++
+		}
+		levels[i] = level
+	}
+	// L1: separators and trailing white space return to the base level
+	trailing := true
+	for i := n - 1; i >= 0; i-- {
+		switch c := classify(runes[i]); {
+		case c == bidiS || c == bidiB:
+			levels[i] = base
+			trailing = true
+		case trailing && (c == bidiWS || c == bidiBN):
+			levels[i] = base
+		default:
+			trailing = false
+		}
+	}
+	return levels, rtl
+}
+
+// neutral reports whether a resolved type takes its direction from its
+// surroundings
+func neutral(c bidiClass) bool {
+	return c == bidiON || c == bidiWS || c == bidiS || c == bidiB || c == bidiBN
+}
+
+// visualOrder returns the indices of items at the given levels in the order
+// they are displayed from left to right, reversing each run at or above
+// every odd level from the highest down (L2)
+func visualOrder(levels []uint8) []int {
+	order := make([]int, len(levels))
+	var highest, lowestOdd uint8 = 0, 255
+	for i, l := range levels {
+		order[i] = i
+		highest = max(highest, l)
+		if l%2 == 1 {
+			lowestOdd = min(lowestOdd, l)
+		}
+	}
+	for level := highest; level >= lowestOdd && level > 0; level-- {
+		for i := 0; i < len(order); i++ {
+			if levels[order[i]] < level {
+				continue
+			}
+			j := i
+			for j < len(order) && levels[order[j]] >= level {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				order[a], order[b] = order[b], order[a]
+			}
+			i = j
+		}
+	}
+	return order
+}
+
+// mirror returns the mirrored form of brackets and other paired punctuation,
+// drawn in right to left text (L4)
+func mirror(r rune) rune {
+	switch r {
+	case '(':
+		return ')'
+	case ')':
+		return '('
+	case '[':
+		return ']'
+	case ']':
+		return '['
+	case '{':
+		return '}'
+	case '}':
+		return '{'
+	case '<':
+		return '>'
+	case '>':
+		return '<'
+	case 0xAB:
+		return 0xBB
+	case 0xBB:
+		return 0xAB
+	case 0x2039:
+		return 0x203A
+	case 0x203A:
+		return 0x2039
+	}
+	return r
+}
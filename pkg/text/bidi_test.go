@@ -0,0 +1,80 @@
+package text
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBidiLevels(t *testing.T) {
+	tests := []struct {
+		name   string
+		s      string
+		levels []uint8
+		rtl    bool
+	}{
+		{"latin", "abc", []uint8{0, 0, 0}, false},
+		{"no strong letters", "1+2", []uint8{0, 0, 0}, false},
+		{"hebrew", "אבג", []uint8{1, 1, 1}, true},
+		{"hebrew in latin", "ab אב c", []uint8{0, 0, 0, 1, 1, 0, 0}, false},
+		{"latin in hebrew", "א bc ב", []uint8{1, 1, 2, 2, 1, 1}, true},
+		{"digits in hebrew", "אב 12", []uint8{1, 1, 1, 2, 2}, true},
+		{"arabic", "سلام", []uint8{1, 1, 1, 1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			levels, rtl := bidiLevels([]rune(tt.s))
+			if !slices.Equal(levels, tt.levels) || rtl != tt.rtl {
+				t.Errorf("got %v rtl %v, want %v rtl %v", levels, rtl, tt.levels, tt.rtl)
+			}
+		})
+	}
+}
+
+func TestVisualOrder(t *testing.T) {
+	tests := []struct {
+		levels []uint8
+		want   []int
+	}{
+		{[]uint8{0, 0, 0}, []int{0, 1, 2}},
+		{[]uint8{1, 1, 1}, []int{2, 1, 0}},
+		{[]uint8{0, 0, 1, 1, 1}, []int{0, 1, 4, 3, 2}},
+		{[]uint8{1, 1, 1, 2, 2}, []int{3, 4, 2, 1, 0}},
+		{[]uint8{1, 2, 2, 1, 1}, []int{4, 3, 1, 2, 0}},
+		{nil, []int{}},
+	}
+	for _, tt := range tests {
+		if got := visualOrder(tt.levels); !slices.Equal(got, tt.want) {
+			t.Errorf("%v: got %v, want %v", tt.levels, got, tt.want)
+		}
+	}
+}
+
+func TestMirror(t *testing.T) {
+	for r, want := range map[rune]rune{'(': ')', ']': '[', '<': '>', '«': '»', 'a': 'a'} {
+		if got := mirror(r); got != want {
+			t.Errorf("%q: got %q, want %q", r, got, want)
+		}
+	}
+}
+
+func TestJoiningForms(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want []string
+	}{
+		{"dual joining", "بيت", []string{"init", "medi", "fina"}},
+		{"right joining", "دار", []string{"isol", "isol", "isol"}},
+		{"alef breaks the word", "باب", []string{"init", "fina", "isol"}},
+		{"marks are transparent", "بَب", []string{"init", "", "fina"}},
+		{"tatweel joins", "بـ", []string{"init", ""}},
+		{"latin does not join", "ab", []string{"", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := joiningForms([]rune(tt.s)); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
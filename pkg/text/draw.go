@@ -0,0 +1,73 @@
+package text
+
+import (
+	"math"
+
+	"github.com/mleku/goo/pkg/render"
+)
+
+// Alignment specifies the horizontal placement of text within a box
+type Alignment int
+
+const (
+	AlignStart Alignment = iota
+	AlignCenter
+	AlignEnd
+)
+
+// Offset returns the x offset of text of the given width within the available width
+func (a Alignment) Offset(textWidth, availableWidth float32) float32 {
+	switch a {
+	case AlignCenter:
+		return (availableWidth - textWidth) / 2
+	case AlignEnd:
+		return availableWidth - textWidth
+	default:
+		return 0
+	}
+}
+
+// Mirrored returns the alignment in a layout mirrored for a right to left
+// language, where text starts at the right
+func (a Alignment) Mirrored() Alignment {
+	switch a {
+	case AlignStart:
+		return AlignEnd
+	case AlignEnd:
+		return AlignStart
+	}
+	return a
+}
+
+// Draw adds a string to the draw list with the pen starting at x on the
+// baseline y, both in window coordinates (0,0 = top-left). Text that needs
+// shaping, such as Arabic or Hebrew, is drawn from its Layout.
+func (f *Face) Draw(list *render.DrawList, x, y float32, s string, color [4]float32) {
+	if !f.simple(s) {
+		f.Layout(s).Draw(list, x, y, color)
+		return
+	}
+	// Snap the pen to whole pixels so glyphs are not resampled
+	x, y = float32(math.Round(float64(x))), float32(math.Round(float64(y)))
+	for _, r := range s {
+		g := f.Glyph(r)
+		f.drawGlyph(list, g, x, y, color)
+		x += g.Advance
+	}
+}
+
+// drawGlyph adds a glyph to the draw list with its origin at x on the
+// baseline y
+func (f *Face) drawGlyph(list *render.DrawList, g *Glyph, x, y float32, color [4]float32) {
+	if g.Width == 0 {
+		return
+	}
+	t := f.atlas.texture
+	aw, ah := float32(t.Width), float32(t.Height)
+	list.Image(t,
+		x+g.BearingX, y-g.BearingY, float32(g.Width), float32(g.Height),
+		float32(g.X)/aw, float32(g.Y)/ah,
+		float32(g.X+g.Width)/aw, float32(g.Y+g.Height)/ah,
+		color,
+	)
+}
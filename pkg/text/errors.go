@@ -0,0 +1,17 @@
+package text
+
+import (
+	"errors"
+)
+
+var (
+	// errInvalidFont is returned when font data is truncated or malformed
+	errInvalidFont = errors.New("invalid font data")
+	// errMissingTable is returned when a required sfnt table is absent
+	errMissingTable = errors.New("missing required font table")
+	// errCFFUnsupported is returned for OpenType fonts with CFF outlines,
+	// which the rasterizer cannot read, only TrueType (glyf) outlines
+	errCFFUnsupported = errors.New("CFF outlines are not supported")
+	// errNoCmap is returned when the font has no usable unicode character map
+	errNoCmap = errors.New("no supported unicode cmap")
+)
@@ -0,0 +1,139 @@
+package text
+
+import (
+	"math"
+)
+
+// Glyph is a rasterized glyph stored in a face's atlas
+type Glyph struct {
+	// Atlas region holding the glyph bitmap
+	X, Y, Width, Height int
+	// Offset from the pen position on the baseline to the bitmap top-left
+	BearingX, BearingY float32
+	// Horizontal distance to advance the pen after drawing
+	Advance float32
+}
+
+// Face is a font rendered at a fixed pixel size
+type Face struct {
+	font   *Font
+	size   float32
+	scale  float32
+	glyphs map[rune]*Glyph
+	// indexed holds the glyphs by glyph index, for shaped text whose
+	// glyphs need not belong to any rune
+	indexed map[int]*Glyph
+	layouts map[string]*Layout
+	atlas   *Atlas
+}
+
+// newFace creates a face for the font at the given pixel size
+func newFace(font *Font, size float32) *Face {
+	return &Face{
+		font:    font,
+		size:    size,
+		scale:   size / font.unitsPerEm,
+		glyphs:  make(map[rune]*Glyph),
+		indexed: make(map[int]*Glyph),
+		layouts: make(map[string]*Layout),
+		atlas:   NewAtlas(512, 512),
+	}
+}
+
+// Size returns the pixel size of the face
+func (f *Face) Size() float32 {
+	return f.size
+}
+
+// Ascent returns the distance from the baseline to the top of the line
+func (f *Face) Ascent() float32 {
+	return float32(math.Ceil(float64(f.font.ascender * f.scale)))
+}
+
+// Descent returns the distance from the baseline to the bottom of the line
+func (f *Face) Descent() float32 {
+	return float32(math.Ceil(float64(-f.font.descender * f.scale)))
+}
+
+// LineHeight returns the distance between consecutive baselines
+func (f *Face) LineHeight() float32 {
+	return f.Ascent() + f.Descent() + float32(math.Ceil(float64(f.font.lineGap*f.scale)))
+}
+
+// Atlas returns the texture atlas holding the face's glyphs
+func (f *Face) Atlas() *Atlas {
+	return f.atlas
+}
+
+// Glyph returns the rasterized glyph for a rune, rendering it into the atlas
+// the first time it is requested
+func (f *Face) Glyph(r rune) *Glyph {
+	if g, ok := f.glyphs[r]; ok {
+		return g
+	}
+	g := f.glyphAt(f.font.GlyphIndex(r))
+	f.glyphs[r] = g
+	return g
+}
+
+// glyphAt returns the rasterized glyph with a glyph index, rendering it into
+// the atlas the first time it is requested
+func (f *Face) glyphAt(index int) *Glyph {
+	if g, ok := f.indexed[index]; ok {
+		return g
+	}
+	g := &Glyph{Advance: f.font.advance(index) * f.scale}
+	contours, bb := f.font.outline(index)
+	if len(contours) > 0 {
+		// Pixel bounds padded by one so anti-aliasing is not clipped
+		left := float32(math.Floor(float64(bb.xMin*f.scale))) - 1
+		top := float32(math.Ceil(float64(bb.yMax*f.scale))) + 1
+		right := float32(math.Ceil(float64(bb.xMax*f.scale))) + 1
+		bottom := float32(math.Floor(float64(bb.yMin*f.scale))) - 1
+		w, h := int(right-left), int(top-bottom)
+		if w > 0 && h > 0 {
+			ras := newRasterizer(w, h)
+			ras.contours(contours, f.scale, -left, top)
+			var reset bool
+			if g.X, g.Y, reset = f.atlas.Add(w, h, ras.mask()); reset {
+				// The atlas was recycled so every cached glyph is stale
+				clear(f.glyphs)
+				clear(f.indexed)
+			}
+			g.Width, g.Height = w, h
+			g.BearingX, g.BearingY = left, top
+		}
+	}
+	f.indexed[index] = g
+	return g
+}
+
+// Measure returns the advance width of a string, after shaping when it
+// needs it
+func (f *Face) Measure(s string) (width float32) {
+	if !f.simple(s) {
+		return f.Layout(s).Width
+	}
+	for _, r := range s {
+		width += f.Glyph(r).Advance
+	}
+	return
+}
+
+// Index returns the rune index of the caret position in s closest to x,
+// measured from the start of the string
+func (f *Face) Index(s string, x float32) (index int) {
+	if !f.simple(s) {
+		return f.Layout(s).Index(x)
+	}
+	var pen float32
+	for _, r := range s {
+		advance := f.Glyph(r).Advance
+		if x < pen+advance/2 {
+			return
+		}
+		pen += advance
+		index++
+	}
+	return
+}
@@ -0,0 +1,264 @@
+package text
+
+import (
+	"encoding/binary"
+	"os"
+
+	"lol.mleku.dev/chk"
+)
+
+// Font is a parsed TrueType or OpenType (TrueType outline) font
+type Font struct {
+	unitsPerEm  float32
+	numGlyphs   int
+	longLoca    bool
+	numHMetrics int
+	ascender    float32
+	descender   float32
+	lineGap     float32
+	cmapFormat  uint16
+	cmapOffset  int
+	cmap        []byte
+	loca        []byte
+	glyf        []byte
+	hmtx        []byte
+	// gsub holds the glyph substitutions, nil when the font has none
+	gsub  *gsub
+	faces map[float32]*Face
+}
+
+// Load reads and parses a font file from disk. Only fonts with TrueType
+// (glyf) outlines load; see Parse.
+func Load(path string) (f *Font, err error) {
+	var data []byte
+	if data, err = os.ReadFile(path); chk.E(err) {
+		return
+	}
+	return Parse(data)
+}
+
+// Parse parses TTF, OTF (with TrueType outlines) or the first font of a TTC.
+// Glyphs are only read from TrueType (glyf) outlines: OpenType fonts with
+// PostScript (CFF) outlines, such as most .otf files from type foundries,
+// are not supported and return an error saying so, as does a collection
+// whose first font has them.
+func Parse(data []byte) (f *Font, err error) {
+	if len(data) < 12 {
+		return nil, errInvalidFont
+	}
+	base := 0
+	switch string(data[:4]) {
+	case "ttcf":
+		// Font collection, use the first font
+		if len(data) < 16 {
+			return nil, errInvalidFont
+		}
+		base = int(u32(data, 12))
+	case "OTTO":
+		return nil, errCFFUnsupported
+	}
+	if base+12 > len(data) {
+		return nil, errInvalidFont
+	}
+	if string(data[base:base+4]) == "OTTO" {
+		return nil, errCFFUnsupported
+	}
+
+	// Read the table directory
+	tables := make(map[string][]byte)
+	numTables := int(u16(data, base+4))
+	for i := 0; i < numTables; i++ {
+		rec := base + 12 + 16*i
+		if rec+16 > len(data) {
+			return nil, errInvalidFont
+		}
+		offset, length := int(u32(data, rec+8)), int(u32(data, rec+12))
+		if offset+length > len(data) {
+			return nil, errInvalidFont
+		}
+		tables[string(data[rec:rec+4])] = data[offset : offset+length]
+	}
+	for _, tag := range []string{"head", "hhea", "hmtx", "maxp", "cmap", "loca", "glyf"} {
+		if _, ok := tables[tag]; !ok {
+			if tag == "glyf" {
+				if _, cff := tables["CFF "]; cff {
+					return nil, errCFFUnsupported
+				}
+			}
+			return nil, errMissingTable
+		}
+	}
+
+	head, hhea, maxp := tables["head"], tables["hhea"], tables["maxp"]
+	if len(head) < 54 || len(hhea) < 36 || len(maxp) < 6 {
+		return nil, errInvalidFont
+	}
+	f = &Font{
+		unitsPerEm:  float32(u16(head, 18)),
+		longLoca:    int16(u16(head, 50)) != 0,
+		ascender:    float32(int16(u16(hhea, 4))),
+		descender:   float32(int16(u16(hhea, 6))),
+		lineGap:     float32(int16(u16(hhea, 8))),
+		numHMetrics: int(u16(hhea, 34)),
+		numGlyphs:   int(u16(maxp, 4)),
+		loca:        tables["loca"],
+		glyf:        tables["glyf"],
+		hmtx:        tables["hmtx"],
+		gsub:        newGSUB(tables["GSUB"]),
+		faces:       make(map[float32]*Face),
+	}
+	if f.unitsPerEm == 0 || f.numHMetrics == 0 || len(f.hmtx) < 4*f.numHMetrics {
+		return nil, errInvalidFont
+	}
+	if err = f.parseCmap(tables["cmap"]); chk.E(err) {
+		return nil, err
+	}
+	return
+}
+
+// parseCmap selects the best unicode subtable of the character map
+func (f *Font) parseCmap(cmap []byte) (err error) {
+	if len(cmap) < 4 {
+		return errInvalidFont
+	}
+	numTables := int(u16(cmap, 2))
+	best := -1
+	for i := 0; i < numTables; i++ {
+		rec := 4 + 8*i
+		if rec+8 > len(cmap) {
+			return errInvalidFont
+		}
+		platform, encoding := u16(cmap, rec), u16(cmap, rec+2)
+		offset := int(u32(cmap, rec+4))
+		if offset+4 > len(cmap) {
+			continue
+		}
+		format := u16(cmap, offset)
+		unicode := platform == 0 || (platform == 3 && (encoding == 1 || encoding == 10))
+		if !unicode || (format != 4 && format != 12) {
+			continue
+		}
+		// Prefer the full unicode range subtable when there is one
+		if best < 0 || format == 12 {
+			best = offset
+			f.cmapFormat = format
+		}
+	}
+	if best < 0 {
+		return errNoCmap
+	}
+	f.cmapOffset = best
+	f.cmap = cmap
+	return
+}
+
+// GlyphIndex returns the glyph for a rune, or 0 (the missing glyph) if the
+// font does not map it
+func (f *Font) GlyphIndex(r rune) (g int) {
+	cmap, o := f.cmap, f.cmapOffset
+	c := uint32(r)
+	switch f.cmapFormat {
+	case 4:
+		if c > 0xFFFF || o+14 > len(cmap) {
+			return 0
+		}
+		segCount := int(u16(cmap, o+6)) / 2
+		ends := o + 14
+		starts := ends + 2*segCount + 2
+		deltas := starts + 2*segCount
+		ranges := deltas + 2*segCount
+		if ranges+2*segCount > len(cmap) {
+			return 0
+		}
+		for i := 0; i < segCount; i++ {
+			if uint32(u16(cmap, ends+2*i)) < c {
+				continue
+			}
+			start := uint32(u16(cmap, starts+2*i))
+			if start > c {
+				return 0
+			}
+			delta := u16(cmap, deltas+2*i)
+			rangeOffset := int(u16(cmap, ranges+2*i))
+			if rangeOffset == 0 {
+				return int(uint16(c) + delta)
+			}
+			addr := ranges + 2*i + rangeOffset + 2*int(c-start)
+			if addr+2 > len(cmap) {
+				return 0
+			}
+			if g := u16(cmap, addr); g != 0 {
+				return int(g + delta)
+			}
+			return 0
+		}
+	case 12:
+		if o+16 > len(cmap) {
+			return 0
+		}
+		n := int(u32(cmap, o+12))
+		lo, hi := 0, n
+		for lo < hi {
+			mid := (lo + hi) / 2
+			rec := o + 16 + 12*mid
+			if rec+12 > len(cmap) {
+				return 0
+			}
+			start, end := u32(cmap, rec), u32(cmap, rec+4)
+			switch {
+			case c < start:
+				hi = mid
+			case c > end:
+				lo = mid + 1
+			default:
+				return int(u32(cmap, rec+8) + c - start)
+			}
+		}
+	}
+	return 0
+}
+
+// advance returns the horizontal advance of a glyph in font units
+func (f *Font) advance(g int) float32 {
+	if g >= f.numHMetrics {
+		g = f.numHMetrics - 1
+	}
+	return float32(u16(f.hmtx, 4*g))
+}
+
+// glyphData returns the raw glyf entry for a glyph, nil for empty glyphs
+func (f *Font) glyphData(g int) []byte {
+	if g < 0 || g >= f.numGlyphs {
+		return nil
+	}
+	var start, end int
+	if f.longLoca {
+		if 4*g+8 > len(f.loca) {
+			return nil
+		}
+		start, end = int(u32(f.loca, 4*g)), int(u32(f.loca, 4*g+4))
+	} else {
+		if 2*g+4 > len(f.loca) {
+			return nil
+		}
+		start, end = 2*int(u16(f.loca, 2*g)), 2*int(u16(f.loca, 2*g+2))
+	}
+	if start >= end || end > len(f.glyf) {
+		return nil
+	}
+	return f.glyf[start:end]
+}
+
+// Face returns the font rendered at the given pixel size. Faces are cached
+// so repeated calls with the same size share one glyph atlas.
+func (f *Font) Face(size float32) *Face {
+	if face, ok := f.faces[size]; ok {
+		return face
+	}
+	face := newFace(f, size)
+	f.faces[size] = face
+	return face
+}
+
+func u16(b []byte, i int) uint16 { return binary.BigEndian.Uint16(b[i:]) }
+func u32(b []byte, i int) uint32 { return binary.BigEndian.Uint32(b[i:]) }
@@ -0,0 +1,90 @@
+package text
+
+import (
+	"os"
+	"testing"
+)
+
+// testFont is a TrueType font found on most Linux systems
+const testFont = "/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf"
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"empty", nil, errInvalidFont},
+		{"cff outlines", table("OTTO").u16(0, 0, 0, 0), errCFFUnsupported},
+		{"cff collection", table("ttcf").u16(1, 0, 0, 1, 0, 16).tag("OTTO").u16(0, 0, 0, 0), errCFFUnsupported},
+		{"no tables", table{0, 1, 0, 0}.u16(0, 0, 0, 0), errMissingTable},
+		{"truncated directory", table{0, 1, 0, 0}.u16(1, 0, 0, 0), errInvalidFont},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.data); err != tt.err {
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+// loadTestFont loads the test font, skipping the test when it is missing
+func loadTestFont(t *testing.T) *Font {
+	t.Helper()
+	if _, err := os.Stat(testFont); err != nil {
+		t.Skip("test font not installed:", testFont)
+	}
+	f, err := Load(testFont)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestLayout(t *testing.T) {
+	face := loadTestFont(t).Face(16)
+	tests := []struct {
+		s   string
+		rtl bool
+	}{
+		{"hello", false},
+		{"שלום", true},
+		{"سلام", true},
+		{"ab אב", false},
+	}
+	for _, tt := range tests {
+		l := face.Layout(tt.s)
+		if l.RTL != tt.rtl {
+			t.Errorf("%q: RTL = %v, want %v", tt.s, l.RTL, tt.rtl)
+		}
+		if l.Width <= 0 {
+			t.Errorf("%q: width %g", tt.s, l.Width)
+		}
+		// The caret before the first rune is at the start of the line, on the
+		// right for right to left text
+		start := float32(0)
+		if tt.rtl {
+			start = l.Width
+		}
+		if got := l.CaretX(0); abs(got-start) > 0.5 {
+			t.Errorf("%q: caret 0 at %g, want %g", tt.s, got, start)
+		}
+		if got := l.Index(start); got != 0 {
+			t.Errorf("%q: index at %g = %d, want 0", tt.s, start, got)
+		}
+	}
+	if face.Measure("hello") != face.Layout("hello").Width {
+		t.Error("Measure differs from the layout width")
+	}
+}
+
+func TestGlyphIndex(t *testing.T) {
+	f := loadTestFont(t)
+	if f.GlyphIndex('A') == 0 || f.GlyphIndex('ب') == 0 {
+		t.Error("mapped runes have no glyph")
+	}
+	if g := f.GlyphIndex(0x10FFFD); g != 0 {
+		t.Errorf("private use rune has glyph %d", g)
+	}
+}
@@ -0,0 +1,235 @@
+package text
+
+// point is an outline point in font units
+type point struct {
+	x, y float32
+	on   bool
+}
+
+// bounds is a glyph bounding box in font units
+type bounds struct {
+	xMin, yMin, xMax, yMax float32
+}
+
+// maxCompositeDepth limits recursion through nested composite glyphs
+const maxCompositeDepth = 8
+
+// Composite glyph component flags
+const (
+	argsAreWords    = 0x0001
+	argsAreXY       = 0x0002
+	haveScale       = 0x0008
+	moreComponents  = 0x0020
+	haveXYScale     = 0x0040
+	haveTwoByTwo    = 0x0080
+	simpleOnCurve   = 0x01
+	simpleXShort    = 0x02
+	simpleYShort    = 0x04
+	simpleRepeat    = 0x08
+	simpleXSameOrPo = 0x10
+	simpleYSameOrPo = 0x20
+)
+
+// outline returns the contours and bounding box of a glyph in font units
+func (f *Font) outline(g int) (contours [][]point, bb bounds) {
+	data := f.glyphData(g)
+	if len(data) < 10 {
+		return
+	}
+	bb = bounds{
+		xMin: float32(int16(u16(data, 2))),
+		yMin: float32(int16(u16(data, 4))),
+		xMax: float32(int16(u16(data, 6))),
+		yMax: float32(int16(u16(data, 8))),
+	}
+	contours = f.appendContours(nil, g, [6]float32{1, 0, 0, 1, 0, 0}, 0)
+	return
+}
+
+// appendContours appends the contours of glyph g transformed by the affine
+// matrix m (xx, xy, yx, yy, dx, dy)
+func (f *Font) appendContours(dst [][]point, g int, m [6]float32, depth int) [][]point {
+	data := f.glyphData(g)
+	if len(data) < 10 || depth > maxCompositeDepth {
+		return dst
+	}
+	numContours := int(int16(u16(data, 0)))
+	if numContours >= 0 {
+		return appendSimple(dst, data, numContours, m)
+	}
+
+	// Composite glyph built from transformed component glyphs
+	p := 10
+	for {
+		if p+4 > len(data) {
+			return dst
+		}
+		flags := u16(data, p)
+		component := int(u16(data, p+2))
+		p += 4
+		var dx, dy float32
+		if flags&argsAreWords != 0 {
+			if p+4 > len(data) {
+				return dst
+			}
+			dx, dy = float32(int16(u16(data, p))), float32(int16(u16(data, p+2)))
+			p += 4
+		} else {
+			if p+2 > len(data) {
+				return dst
+			}
+			dx, dy = float32(int8(data[p])), float32(int8(data[p+1]))
+			p += 2
+		}
+		if flags&argsAreXY == 0 {
+			// Point matching placement is rare and not supported
+			dx, dy = 0, 0
+		}
+		c := [4]float32{1, 0, 0, 1}
+		switch {
+		case flags&haveScale != 0:
+			if p+2 > len(data) {
+				return dst
+			}
+			c[0] = f2dot14(u16(data, p))
+			c[3] = c[0]
+			p += 2
+		case flags&haveXYScale != 0:
+			if p+4 > len(data) {
+				return dst
+			}
+			c[0], c[3] = f2dot14(u16(data, p)), f2dot14(u16(data, p+2))
+			p += 4
+		case flags&haveTwoByTwo != 0:
+			if p+8 > len(data) {
+				return dst
+			}
+			c = [4]float32{f2dot14(u16(data, p)), f2dot14(u16(data, p+2)),
+				f2dot14(u16(data, p+4)), f2dot14(u16(data, p+6))}
+			p += 8
+		}
+		// Combine the component transform with the parent transform
+		cm := [6]float32{
+			m[0]*c[0] + m[2]*c[1],
+			m[1]*c[0] + m[3]*c[1],
+			m[0]*c[2] + m[2]*c[3],
+			m[1]*c[2] + m[3]*c[3],
+			m[0]*dx + m[2]*dy + m[4],
+			m[1]*dx + m[3]*dy + m[5],
+		}
+		dst = f.appendContours(dst, component, cm, depth+1)
+		if flags&moreComponents == 0 {
+			return dst
+		}
+	}
+}
+
+// appendSimple decodes a simple glyph description
+func appendSimple(dst [][]point, data []byte, numContours int, m [6]float32) [][]point {
+	p := 10
+	if p+2*numContours+2 > len(data) {
+		return dst
+	}
+	ends := make([]int, numContours)
+	for i := range ends {
+		ends[i] = int(u16(data, p+2*i))
+	}
+	p += 2 * numContours
+	if numContours == 0 {
+		return dst
+	}
+	numPoints := ends[numContours-1] + 1
+	p += 2 + int(u16(data, p))
+
+	// Flags, with run length repeats
+	flags := make([]byte, 0, numPoints)
+	for len(flags) < numPoints {
+		if p >= len(data) {
+			return dst
+		}
+		flag := data[p]
+		p++
+		flags = append(flags, flag)
+		if flag&simpleRepeat != 0 {
+			if p >= len(data) {
+				return dst
+			}
+			for n := data[p]; n > 0 && len(flags) < numPoints; n-- {
+				flags = append(flags, flag)
+			}
+			p++
+		}
+	}
+
+	// Coordinates are delta encoded, x values first then y values
+	pts := make([]point, numPoints)
+	var v int
+	for i, flag := range flags {
+		switch {
+		case flag&simpleXShort != 0:
+			if p >= len(data) {
+				return dst
+			}
+			if flag&simpleXSameOrPo != 0 {
+				v += int(data[p])
+			} else {
+				v -= int(data[p])
+			}
+			p++
+		case flag&simpleXSameOrPo == 0:
+			if p+2 > len(data) {
+				return dst
+			}
+			v += int(int16(u16(data, p)))
+			p += 2
+		}
+		pts[i].x = float32(v)
+		pts[i].on = flag&simpleOnCurve != 0
+	}
+	v = 0
+	for i, flag := range flags {
+		switch {
+		case flag&simpleYShort != 0:
+			if p >= len(data) {
+				return dst
+			}
+			if flag&simpleYSameOrPo != 0 {
+				v += int(data[p])
+			} else {
+				v -= int(data[p])
+			}
+			p++
+		case flag&simpleYSameOrPo == 0:
+			if p+2 > len(data) {
+				return dst
+			}
+			v += int(int16(u16(data, p)))
+			p += 2
+		}
+		pts[i].y = float32(v)
+	}
+
+	// Apply the transform and split into contours
+	start := 0
+	for _, end := range ends {
+		if end < start || end >= numPoints {
+			return dst
+		}
+		contour := make([]point, 0, end-start+1)
+		for _, pt := range pts[start : end+1] {
+			contour = append(contour, point{
+				x:  m[0]*pt.x + m[2]*pt.y + m[4],
+				y:  m[1]*pt.x + m[3]*pt.y + m[5],
+				on: pt.on,
+			})
+		}
+		dst = append(dst, contour)
+		start = end + 1
+	}
+	return dst
+}
+
+// f2dot14 converts a 2.14 fixed point number to float
+func f2dot14(v uint16) float32 {
+	return float32(int16(v)) / 16384
+}
@@ -0,0 +1,316 @@
+package text
+
+import (
+	"slices"
+)
+
+// gsub reads the lookups of a font's glyph substitution table. Only the
+// single and ligature substitutions are applied, which are what joining
+// scripts and ligatures need; the contextual ones are skipped.
+type gsub struct {
+	data []byte
+	// lookups caches the lookup indices of features by script and tag
+	lookups map[[2]string][]int
+	// starts caches whether glyphs begin a ligature
+	starts map[int]bool
+}
+
+// gsub lookup types
+const (
+	lookupSingle    = 1
+	lookupLigature  = 4
+	lookupExtension = 7
+)
+
+// lookupIgnoreMarks is the lookup flag skipping combining marks
+const lookupIgnoreMarks = 0x8
+
+// newGSUB wraps a GSUB table, nil when the font has none
+func newGSUB(data []byte) *gsub {
+	if len(data) < 10 {
+		return nil
+	}
+	return &gsub{
+		data:    data,
+		lookups: make(map[[2]string][]int),
+		starts:  make(map[int]bool),
+	}
+}
+
+// in reports whether n bytes from offset lie inside the table
+func (t *gsub) in(offset, n int) bool {
+	return offset >= 0 && offset+n <= len(t.data)
+}
+
+// featureLookups returns the indices of the lookups of a feature for a
+// script, in the order they apply. Scripts the font does not list fall back
+// to its default script, then to Latin.
+func (t *gsub) featureLookups(script, tag string) []int {
+	key := [2]string{script, tag}
+	if lookups, ok := t.lookups[key]; ok {
+		return lookups
+	}
+	var lookups []int
+	d := t.data
+	scripts := int(u16(d, 4))
+	features := int(u16(d, 6))
+	langSys := -1
+	for _, want := range []string{script, "DFLT", "latn"} {
+		if langSys = t.defaultLangSys(scripts, want); langSys >= 0 {
+			break
+		}
+	}
+	if langSys >= 0 && t.in(langSys, 6) && t.in(features, 2) {
+		count := int(u16(d, langSys+4))
+		featureCount := int(u16(d, features))
+		for i := 0; i < count && t.in(langSys+6+2*i, 2); i++ {
+			index := int(u16(d, langSys+6+2*i))
+			rec := features + 2 + 6*index
+			if index >= featureCount || !t.in(rec, 6) || string(d[rec:rec+4]) != tag {
+				continue
+			}
+			feature := features + int(u16(d, rec+4))
+			if !t.in(feature, 4) {
+				continue
+			}
+			n := int(u16(d, feature+2))
+			for j := 0; j < n && t.in(feature+4+2*j, 2); j++ {
+				lookups = append(lookups, int(u16(d, feature+4+2*j)))
+			}
+		}
+	}
+	slices.Sort(lookups)
+	lookups = slices.Compact(lookups)
+	t.lookups[key] = lookups
+	return lookups
+}
+
+// defaultLangSys returns the offset of the default language system of a
+// script, -1 when the script is not listed
+func (t *gsub) defaultLangSys(scripts int, tag string) int {
+	d := t.data
+	if !t.in(scripts, 2) {
+		return -1
+	}
+	count := int(u16(d, scripts))
+	for i := 0; i < count; i++ {
+		rec := scripts + 2 + 6*i
+		if !t.in(rec, 6) {
+			return -1
+		}
+		if string(d[rec:rec+4]) != tag {
+			continue
+		}
+		script := scripts + int(u16(d, rec+4))
+		if !t.in(script, 2) || u16(d, script) == 0 {
+			return -1
+		}
+		return script + int(u16(d, script))
+	}
+	return -1
+}
+
+// subtables calls fn with the type and offset of each subtable of a lookup,
+// looking through extension subtables
+func (t *gsub) subtables(lookup int, fn func(kind, offset int)) (flag uint16) {
+	d := t.data
+	list := int(u16(d, 8))
+	if !t.in(list, 2) || lookup >= int(u16(d, list)) || !t.in(list+2+2*lookup, 2) {
+		return
+	}
+	table := list + int(u16(d, list+2+2*lookup))
+	if !t.in(table, 6) {
+		return
+	}
+	kind, flag, count := int(u16(d, table)), u16(d, table+2), int(u16(d, table+4))
+	for i := 0; i < count && t.in(table+6+2*i, 2); i++ {
+		offset := table + int(u16(d, table+6+2*i))
+		if kind == lookupExtension {
+			if !t.in(offset, 8) {
+				continue
+			}
+			fn(int(u16(d, offset+2)), offset+int(u32(d, offset+4)))
+			continue
+		}
+		fn(kind, offset)
+	}
+	return
+}
+
+// coverage returns the index of a glyph in a coverage table, -1 when it is
+// not covered
+func (t *gsub) coverage(offset, glyph int) int {
+	d := t.data
+	if !t.in(offset, 4) {
+		return -1
+	}
+	n := int(u16(d, offset+2))
+	switch u16(d, offset) {
+	case 1:
+		lo, hi := 0, n
+		for lo < hi {
+			mid := (lo + hi) / 2
+			if !t.in(offset+4+2*mid, 2) {
+				return -1
+			}
+			switch g := int(u16(d, offset+4+2*mid)); {
+			case glyph < g:
+				hi = mid
+			case glyph > g:
+				lo = mid + 1
+			default:
+				return mid
+			}
+		}
+	case 2:
+		lo, hi := 0, n
+		for lo < hi {
+			mid := (lo + hi) / 2
+			rec := offset + 4 + 6*mid
+			if !t.in(rec, 6) {
+				return -1
+			}
+			start, end := int(u16(d, rec)), int(u16(d, rec+2))
+			switch {
+			case glyph < start:
+				hi = mid
+			case glyph > end:
+				lo = mid + 1
+			default:
+				return int(u16(d, rec+4)) + glyph - start
+			}
+		}
+	}
+	return -1
+}
+
+// apply applies a lookup to the glyphs for which apply is set, or to all of
+// them when it is nil, and returns the glyphs
+func (t *gsub) apply(lookup int, glyphs []shaped, apply func(g *shaped) bool) []shaped {
+	type subtable struct{ kind, offset int }
+	var subs []subtable
+	flag := t.subtables(lookup, func(kind, offset int) {
+		subs = append(subs, subtable{kind, offset})
+	})
+	for i := 0; i < len(glyphs); i++ {
+		if apply != nil && !apply(&glyphs[i]) || glyphs[i].mark && flag&lookupIgnoreMarks != 0 {
+			continue
+		}
+		for _, s := range subs {
+			var done bool
+			switch s.kind {
+			case lookupSingle:
+				done = t.single(s.offset, &glyphs[i])
+			case lookupLigature:
+				glyphs, done = t.ligature(s.offset, glyphs, i, flag)
+			}
+			if done {
+				break
+			}
+		}
+	}
+	return glyphs
+}
+
+// single applies a single substitution subtable to a glyph, reporting
+// whether it replaced it
+func (t *gsub) single(offset int, g *shaped) bool {
+	d := t.data
+	if !t.in(offset, 6) {
+		return false
+	}
+	index := t.coverage(offset+int(u16(d, offset+2)), g.glyph)
+	if index < 0 {
+		return false
+	}
+	switch u16(d, offset) {
+	case 1:
+		g.glyph = int(uint16(g.glyph + int(int16(u16(d, offset+4)))))
+		return true
+	case 2:
+		if index >= int(u16(d, offset+4)) || !t.in(offset+6+2*index, 2) {
+			return false
+		}
+		g.glyph = int(u16(d, offset+6+2*index))
+		return true
+	}
+	return false
+}
+
+// ligature applies a ligature substitution subtable at glyph i, joining it
+// and the glyphs that follow into one when they spell a ligature. Marks
+// skipped over by the lookup are kept after the ligature.
+func (t *gsub) ligature(offset int, glyphs []shaped, i int, flag uint16) ([]shaped, bool) {
+	d := t.data
+	if !t.in(offset, 6) {
+		return glyphs, false
+	}
+	index := t.coverage(offset+int(u16(d, offset+2)), glyphs[i].glyph)
+	if index < 0 || index >= int(u16(d, offset+4)) || !t.in(offset+6+2*index, 2) {
+		return glyphs, false
+	}
+	set := offset + int(u16(d, offset+6+2*index))
+	if !t.in(set, 2) {
+		return glyphs, false
+	}
+	for k := 0; k < int(u16(d, set)) && t.in(set+2+2*k, 2); k++ {
+		lig := set + int(u16(d, set+2+2*k))
+		if !t.in(lig, 4) {
+			continue
+		}
+		count := int(u16(d, lig+2))
+		if !t.in(lig+4, 2*(count-1)) {
+			continue
+		}
+		// Find the glyphs of the components, skipping marks when asked
+		matched := []int{i}
+		for j, c := i+1, 1; c < count; j++ {
+			if j >= len(glyphs) {
+				break
+			}
+			if glyphs[j].mark && flag&lookupIgnoreMarks != 0 {
+				continue
+			}
+			if glyphs[j].glyph != int(u16(d, lig+4+2*(c-1))) {
+				break
+			}
+			matched = append(matched, j)
+			c++
+		}
+		if len(matched) != count {
+			continue
+		}
+		first := &glyphs[i]
+		first.glyph = int(u16(d, lig))
+		for _, j := range matched[1:] {
+			first.runes += glyphs[j].runes
+		}
+		// Remove the joined components from last to first
+		for m := len(matched) - 1; m > 0; m-- {
+			glyphs = slices.Delete(glyphs, matched[m], matched[m]+1)
+		}
+		return glyphs, true
+	}
+	return glyphs, false
+}
+
+// startsLigature reports whether a glyph is the first of any Latin
+// ligature, for skipping the shaping of text that cannot ligate
+func (t *gsub) startsLigature(glyph int) bool {
+	if starts, ok := t.starts[glyph]; ok {
+		return starts
+	}
+	var starts bool
+	for _, tag := range []string{"rlig", "liga"} {
+		for _, lookup := range t.featureLookups("latn", tag) {
+			t.subtables(lookup, func(kind, offset int) {
+				if kind == lookupLigature && t.in(offset, 4) &&
+					t.coverage(offset+int(u16(t.data, offset+2)), glyph) >= 0 {
+					starts = true
+				}
+			})
+		}
+	}
+	t.starts[glyph] = starts
+	return starts
+}
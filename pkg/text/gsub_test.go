@@ -0,0 +1,168 @@
+package text
+
+import (
+	"slices"
+	"testing"
+)
+
+// table builds big endian font table data
+type table []byte
+
+// u16 appends 16 bit values
+func (t table) u16(v ...int) table {
+	for _, x := range v {
+		t = append(t, byte(x>>8), byte(x))
+	}
+	return t
+}
+
+// tag appends a four letter tag
+func (t table) tag(s string) table {
+	return append(t, s...)
+}
+
+// Glyphs of the test GSUB table
+const (
+	glyphBeh     = 10
+	glyphBehInit = 15
+	glyphF       = 20
+	glyphI       = 21
+	glyphFI      = 30
+	glyphMark    = 40
+)
+
+// testGSUB builds a GSUB table whose Arabic init feature turns glyphBeh into
+// glyphBehInit with a single substitution, and whose Latin liga feature
+// joins glyphF and glyphI into glyphFI, skipping marks
+func testGSUB() *gsub {
+	langSys := func(feature int) table {
+		return table{}.u16(0, 0xFFFF, 1, feature)
+	}
+	script := func(feature int) table {
+		return append(table{}.u16(4, 0), langSys(feature)...)
+	}
+	arab, latn := script(0), script(1)
+	scripts := table{}.u16(2).tag("arab").u16(14).tag("latn").u16(14 + len(arab))
+	scripts = append(append(scripts, arab...), latn...)
+
+	features := table{}.u16(2).tag("init").u16(14).tag("liga").u16(20)
+	features = features.u16(0, 1, 0).u16(0, 1, 1)
+
+	single := table{}.u16(lookupSingle, 0, 1, 8).
+		u16(1, 6, glyphBehInit-glyphBeh).
+		u16(1, 1, glyphBeh)
+	ligature := table{}.u16(lookupLigature, lookupIgnoreMarks, 1, 8).
+		u16(1, 8, 1, 14).
+		u16(1, 1, glyphF).
+		u16(1, 4).
+		u16(glyphFI, 2, glyphI)
+	lookups := append(table{}.u16(2, 6, 6+len(single)), single...)
+	lookups = append(lookups, ligature...)
+
+	data := table{}.u16(1, 0, 10, 10+len(scripts), 10+len(scripts)+len(features))
+	data = append(append(append(data, scripts...), features...), lookups...)
+	return newGSUB(data)
+}
+
+func TestFeatureLookups(t *testing.T) {
+	g := testGSUB()
+	tests := []struct {
+		script, tag string
+		want        []int
+	}{
+		{"arab", "init", []int{0}},
+		{"latn", "liga", []int{1}},
+		// Scripts the font does not list fall back to Latin
+		{"hebr", "liga", []int{1}},
+		{"latn", "init", nil},
+		{"arab", "fina", nil},
+	}
+	for _, tt := range tests {
+		if got := g.featureLookups(tt.script, tt.tag); !slices.Equal(got, tt.want) {
+			t.Errorf("%s %s: got %v, want %v", tt.script, tt.tag, got, tt.want)
+		}
+	}
+}
+
+// glyphsOf returns shaped glyphs, one per rune, marking glyphMark as a mark
+func glyphsOf(ids ...int) []shaped {
+	glyphs := make([]shaped, len(ids))
+	for i, id := range ids {
+		glyphs[i] = shaped{glyph: id, cluster: i, runes: 1, mark: id == glyphMark}
+	}
+	return glyphs
+}
+
+func TestApply(t *testing.T) {
+	g := testGSUB()
+	tests := []struct {
+		name   string
+		lookup int
+		in     []int
+		// want are the glyphs after the lookup and runes how many runes each
+		// stands for
+		want, runes []int
+	}{
+		{"single", 0, []int{glyphBeh, glyphI, glyphBeh}, []int{glyphBehInit, glyphI, glyphBehInit}, []int{1, 1, 1}},
+		{"ligature", 1, []int{glyphI, glyphF, glyphI}, []int{glyphI, glyphFI}, []int{1, 2}},
+		{"ligature over a mark", 1, []int{glyphF, glyphMark, glyphI}, []int{glyphFI, glyphMark}, []int{2, 1}},
+		{"no ligature", 1, []int{glyphF, glyphF}, []int{glyphF, glyphF}, []int{1, 1}},
+		{"unknown lookup", 5, []int{glyphF, glyphI}, []int{glyphF, glyphI}, []int{1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := g.apply(tt.lookup, glyphsOf(tt.in...), nil)
+			var got, runes []int
+			for _, s := range out {
+				got = append(got, s.glyph)
+				runes = append(runes, s.runes)
+			}
+			if !slices.Equal(got, tt.want) || !slices.Equal(runes, tt.runes) {
+				t.Errorf("got glyphs %v runes %v, want %v %v", got, runes, tt.want, tt.runes)
+			}
+		})
+	}
+}
+
+func TestApplySelected(t *testing.T) {
+	g := testGSUB()
+	glyphs := glyphsOf(glyphBeh, glyphBeh)
+	glyphs[1].form = "init"
+	glyphs = g.apply(0, glyphs, func(s *shaped) bool { return s.form == "init" })
+	if glyphs[0].glyph != glyphBeh || glyphs[1].glyph != glyphBehInit {
+		t.Errorf("got %d %d, want only the selected glyph substituted", glyphs[0].glyph, glyphs[1].glyph)
+	}
+}
+
+func TestStartsLigature(t *testing.T) {
+	g := testGSUB()
+	for glyph, want := range map[int]bool{glyphF: true, glyphI: false, glyphBeh: false} {
+		if got := g.startsLigature(glyph); got != want {
+			t.Errorf("glyph %d: got %v, want %v", glyph, got, want)
+		}
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	g := &gsub{data: table{}.
+		u16(1, 3, 4, 9, 12).
+		u16(2, 2, 20, 24, 0, 30, 30, 5)}
+	tests := []struct {
+		offset, glyph, want int
+	}{
+		{0, 4, 0},
+		{0, 12, 2},
+		{0, 5, -1},
+		{10, 20, 0},
+		{10, 23, 3},
+		{10, 30, 5},
+		{10, 25, -1},
+		// Offsets outside the table cover nothing
+		{100, 4, -1},
+	}
+	for _, tt := range tests {
+		if got := g.coverage(tt.offset, tt.glyph); got != tt.want {
+			t.Errorf("coverage at %d of %d: got %d, want %d", tt.offset, tt.glyph, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package text
+
+import (
+	"math"
+)
+
+// rasterizer accumulates signed area coverage of line segments and resolves
+// it into an anti-aliased alpha mask using the non-zero winding rule
+type rasterizer struct {
+	width, height int
+	acc           []float32
+}
+
+// newRasterizer creates a rasterizer for a mask of the given size
+func newRasterizer(width, height int) *rasterizer {
+	return &rasterizer{
+		width:  width,
+		height: height,
+		// One extra cell per row so coverage can spill past the right edge
+		acc: make([]float32, width*height+width+2),
+	}
+}
+
+// line adds a straight edge from (x0, y0) to (x1, y1) in mask pixels
+func (r *rasterizer) line(x0, y0, x1, y1 float32) {
+	if y0 == y1 {
+		return
+	}
+	dir := float32(1)
+	if y0 > y1 {
+		dir = -1
+		x0, y0, x1, y1 = x1, y1, x0, y0
+	}
+	dxdy := (x1 - x0) / (y1 - y0)
+	x := x0
+	if y0 < 0 {
+		x -= y0 * dxdy
+	}
+	maxX := float32(r.width) - 1
+	yEnd := int(math.Ceil(float64(y1)))
+	if yEnd > r.height {
+		yEnd = r.height
+	}
+	for y := int(max(y0, 0)); y < yEnd; y++ {
+		row := y * r.width
+		dy := min(float32(y+1), y1) - max(float32(y), y0)
+		xNext := x + dxdy*dy
+		d := dy * dir
+		a, b := clamp(x, 0, maxX), clamp(xNext, 0, maxX)
+		if a > b {
+			a, b = b, a
+		}
+		aFloor := float32(math.Floor(float64(a)))
+		ai := int(aFloor)
+		bCeil := float32(math.Ceil(float64(b)))
+		bi := int(bCeil)
+		if bi <= ai+1 {
+			// The edge stays within a single pixel column
+			mid := 0.5*(a+b) - aFloor
+			r.acc[row+ai] += d - d*mid
+			r.acc[row+ai+1] += d * mid
+		} else {
+			s := 1 / (b - a)
+			af := a - aFloor
+			a0 := 0.5 * s * (1 - af) * (1 - af)
+			bf := b - bCeil + 1
+			am := 0.5 * s * bf * bf
+			r.acc[row+ai] += d * a0
+			if bi == ai+2 {
+				r.acc[row+ai+1] += d * (1 - a0 - am)
+			} else {
+				a1 := s * (1.5 - af)
+				r.acc[row+ai+1] += d * (a1 - a0)
+				for xi := ai + 2; xi < bi-1; xi++ {
+					r.acc[row+xi] += d * s
+				}
+				a2 := a1 + float32(bi-ai-3)*s
+				r.acc[row+bi-1] += d * (1 - a2 - am)
+			}
+			r.acc[row+bi] += d * am
+		}
+		x = xNext
+	}
+}
+
+// quad adds a quadratic bezier edge by flattening it into line segments
+func (r *rasterizer) quad(x0, y0, cx, cy, x1, y1 float32) {
+	devX, devY := x0-2*cx+x1, y0-2*cy+y1
+	devSq := devX*devX + devY*devY
+	if devSq < 0.333 {
+		r.line(x0, y0, x1, y1)
+		return
+	}
+	const tolerance = 3
+	n := 1 + int(math.Sqrt(math.Sqrt(float64(tolerance*devSq))))
+	px, py := x0, y0
+	step := 1 / float32(n)
+	t := step
+	for i := 0; i < n-1; i++ {
+		mt := 1 - t
+		nx := mt*mt*x0 + 2*mt*t*cx + t*t*x1
+		ny := mt*mt*y0 + 2*mt*t*cy + t*t*y1
+		r.line(px, py, nx, ny)
+		px, py = nx, ny
+		t += step
+	}
+	r.line(px, py, x1, y1)
+}
+
+// mask resolves the accumulated coverage into 8 bit alpha values
+func (r *rasterizer) mask() []byte {
+	out := make([]byte, r.width*r.height)
+	var sum float32
+	for i := range out {
+		sum += r.acc[i]
+		v := sum
+		if v < 0 {
+			v = -v
+		}
+		if v > 1 {
+			v = 1
+		}
+		out[i] = uint8(v*255 + 0.5)
+	}
+	return out
+}
+
+// contours adds the glyph outline, mapping font units into mask pixels with
+// the given scale and offsets. The y axis is flipped so row 0 is the top.
+func (r *rasterizer) contours(contours [][]point, scale, dx, dy float32) {
+	tx := func(p point) (float32, float32) {
+		return p.x*scale + dx, dy - p.y*scale
+	}
+	for _, c := range contours {
+		n := len(c)
+		if n == 0 {
+			continue
+		}
+		// Find an on-curve start point, synthesising one between two
+		// off-curve points when the contour has none at either end
+		var start point
+		rest := c
+		switch {
+		case c[0].on:
+			start, rest = c[0], c[1:]
+		case c[n-1].on:
+			start, rest = c[n-1], c[:n-1]
+		default:
+			start = point{x: (c[0].x + c[n-1].x) / 2, y: (c[0].y + c[n-1].y) / 2, on: true}
+		}
+		sx, sy := tx(start)
+		px, py := sx, sy
+		var ctrl *point
+		for i := range rest {
+			p := rest[i]
+			x, y := tx(p)
+			if p.on {
+				if ctrl != nil {
+					cx, cy := tx(*ctrl)
+					r.quad(px, py, cx, cy, x, y)
+					ctrl = nil
+				} else {
+					r.line(px, py, x, y)
+				}
+				px, py = x, y
+				continue
+			}
+			if ctrl != nil {
+				// Two consecutive off-curve points imply an on-curve midpoint
+				cx, cy := tx(*ctrl)
+				mx, my := (cx+x)/2, (cy+y)/2
+				r.quad(px, py, cx, cy, mx, my)
+				px, py = mx, my
+			}
+			ctrl = &rest[i]
+		}
+		if ctrl != nil {
+			cx, cy := tx(*ctrl)
+			r.quad(px, py, cx, cy, sx, sy)
+		} else if px != sx || py != sy {
+			r.line(px, py, sx, sy)
+		}
+	}
+}
+
+func clamp(v, lo, hi float32) float32 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,385 @@
+package text
+
+import (
+	"math"
+	"slices"
+	"unicode"
+
+	"github.com/mleku/goo/pkg/render"
+)
+
+// maxLayouts is how many shaped strings a face keeps before starting over
+const maxLayouts = 512
+
+// shaped is a glyph being shaped, standing for the runes of a cluster
+type shaped struct {
+	glyph int
+	// cluster is the index of the first rune the glyph stands for and runes
+	// how many it stands for, more than one for a ligature
+	cluster, runes int
+	mark           bool
+	// script is the OpenType tag of the rune's script and form the joining
+	// form feature that applies to it, empty for none
+	script, form string
+	// x is where the glyph is drawn from the start of the line and advance
+	// how far it moves the pen
+	x, advance float32
+}
+
+// Layout is a line of text shaped into glyphs and put in display order.
+// Right to left runs such as Arabic and Hebrew are reversed, Arabic letters
+// take their joined forms, ligatures are formed and combining marks are
+// placed over the letters they follow. Positions in the text are rune
+// indices, as in the text editing widgets; a caret between two runes is
+// drawn at the edge of the rune after it in the direction of that rune.
+type Layout struct {
+	// Width is the advance width of the line
+	Width float32
+	// RTL is set when the line reads from right to left, as when its first
+	// letter is Arabic or Hebrew
+	RTL    bool
+	face   *Face
+	glyphs []shaped
+	// boxes holds the left and right edges of each rune, and carets the x of
+	// the caret before each rune and at the end
+	boxes  [][2]float32
+	carets []float32
+}
+
+// Layout shapes a line of text for display. Layouts are cached, so drawing
+// and measuring the same string again is cheap.
+func (f *Face) Layout(s string) *Layout {
+	if l, ok := f.layouts[s]; ok {
+		return l
+	}
+	l := f.shape([]rune(s))
+	if len(f.layouts) >= maxLayouts {
+		clear(f.layouts)
+	}
+	f.layouts[s] = l
+	return l
+}
+
+// simple reports whether a string needs no shaping: every rune draws as
+// its own glyph from left to right
+func (f *Face) simple(s string) bool {
+	for _, r := range s {
+		if r >= 0x0300 || f.font.gsub != nil && f.font.gsub.startsLigature(f.font.GlyphIndex(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// shape lays out runes: the bidirectional levels are resolved, the glyphs
+// substituted in logical order and then put in display order
+func (f *Face) shape(runes []rune) *Layout {
+	levels, rtl := bidiLevels(runes)
+	glyphs := make([]shaped, len(runes))
+	for i, r := range runes {
+		if levels[i]%2 == 1 {
+			r = mirror(r)
+		}
+		glyphs[i] = shaped{
+			glyph:   f.font.GlyphIndex(r),
+			cluster: i,
+			runes:   1,
+			mark:    unicode.In(r, unicode.Mn, unicode.Me),
+			script:  scriptOf(r),
+		}
+	}
+	if t := f.font.gsub; t != nil {
+		for i, form := range joiningForms(runes) {
+			glyphs[i].form = form
+		}
+		for _, form := range []string{"isol", "fina", "medi", "init"} {
+			for _, lookup := range t.featureLookups("arab", form) {
+				glyphs = t.apply(lookup, glyphs, func(g *shaped) bool { return g.form == form })
+			}
+		}
+		for _, script := range []string{"arab", "hebr", "latn"} {
+			for _, tag := range []string{"rlig", "liga"} {
+				for _, lookup := range t.featureLookups(script, tag) {
+					glyphs = t.apply(lookup, glyphs, func(g *shaped) bool { return g.script == script })
+				}
+			}
+		}
+	}
+
+	// Bases are laid out in display order, then marks centered over the
+	// bases they follow
+	l := &Layout{RTL: rtl, face: f, glyphs: glyphs}
+	glyphLevels := make([]uint8, len(glyphs))
+	for i, g := range glyphs {
+		glyphLevels[i] = levels[g.cluster]
+	}
+	for _, i := range visualOrder(glyphLevels) {
+		g := &glyphs[i]
+		if g.mark {
+			continue
+		}
+		g.x = l.Width
+		g.advance = f.font.advance(g.glyph) * f.scale
+		l.Width += g.advance
+	}
+	for i := range glyphs {
+		if !glyphs[i].mark {
+			continue
+		}
+		base := i - 1
+		for base >= 0 && glyphs[base].mark {
+			base--
+		}
+		if base < 0 {
+			continue
+		}
+		b, m := f.glyphAt(glyphs[base].glyph), f.glyphAt(glyphs[i].glyph)
+		glyphs[i].x = glyphs[base].x + b.BearingX + float32(b.Width)/2 - m.BearingX - float32(m.Width)/2
+	}
+	l.place(runes, levels)
+	return l
+}
+
+// place finds the edges of each rune and the caret positions. The runes of a
+// ligature share its width, and marks sit at the trailing edge of the rune
+// before them.
+func (l *Layout) place(runes []rune, levels []uint8) {
+	n := len(runes)
+	l.boxes = make([][2]float32, n)
+	covered := make([]bool, n)
+	for _, g := range l.glyphs {
+		if g.mark {
+			continue
+		}
+		for k := 0; k < g.runes && g.cluster+k < n; k++ {
+			left := g.x + g.advance*float32(k)/float32(g.runes)
+			right := g.x + g.advance*float32(k+1)/float32(g.runes)
+			if levels[g.cluster]%2 == 1 {
+				left, right = g.x+g.advance-(right-g.x), g.x+g.advance-(left-g.x)
+			}
+			l.boxes[g.cluster+k] = [2]float32{left, right}
+			covered[g.cluster+k] = true
+		}
+	}
+	for i := range runes {
+		if covered[i] {
+			continue
+		}
+		var edge float32
+		if i > 0 {
+			edge = l.trailing(i-1, levels)
+		}
+		l.boxes[i] = [2]float32{edge, edge}
+	}
+	l.carets = make([]float32, n+1)
+	for i := range runes {
+		l.carets[i] = l.boxes[i][0]
+		if levels[i]%2 == 1 {
+			l.carets[i] = l.boxes[i][1]
+		}
+	}
+	switch {
+	case n > 0:
+		l.carets[n] = l.trailing(n-1, levels)
+	case l.RTL:
+		l.carets[n] = l.Width
+	}
+}
+
+// trailing returns the edge of a rune the text after it continues from
+func (l *Layout) trailing(i int, levels []uint8) float32 {
+	if levels[i]%2 == 1 {
+		return l.boxes[i][0]
+	}
+	return l.boxes[i][1]
+}
+
+// Draw adds the line to the draw list with its left edge at x on the
+// baseline y
+func (l *Layout) Draw(list *render.DrawList, x, y float32, color [4]float32) {
+	f := l.face
+	x, y = float32(math.Round(float64(x))), float32(math.Round(float64(y)))
+	for _, g := range l.glyphs {
+		f.drawGlyph(list, f.glyphAt(g.glyph), x+float32(math.Round(float64(g.x))), y, color)
+	}
+}
+
+// CaretX returns the x of the caret before a rune from the left edge of the
+// line, or after the last rune at the end of the text
+func (l *Layout) CaretX(index int) float32 {
+	return l.carets[min(max(index, 0), len(l.carets)-1)]
+}
+
+// Index returns the caret position closest to an x from the left edge of the
+// line
+func (l *Layout) Index(x float32) (index int) {
+	best := float32(math.Inf(1))
+	for i, c := range l.carets {
+		if d := abs(c - x); d < best {
+			index, best = i, d
+		}
+	}
+	return
+}
+
+// Selection returns the spans from left to right covered by the runes from
+// start to end, which in mixed direction text may be apart
+func (l *Layout) Selection(start, end int) (spans [][2]float32) {
+	start, end = max(start, 0), min(end, len(l.boxes))
+	if start >= end {
+		return nil
+	}
+	boxes := slices.Clone(l.boxes[start:end])
+	slices.SortFunc(boxes, func(a, b [2]float32) int {
+		switch {
+		case a[0] < b[0]:
+			return -1
+		case a[0] > b[0]:
+			return 1
+		}
+		return 0
+	})
+	for _, b := range boxes {
+		if n := len(spans); n > 0 && b[0] <= spans[n-1][1]+0.5 {
+			spans[n-1][1] = max(spans[n-1][1], b[1])
+			continue
+		}
+		spans = append(spans, b)
+	}
+	return
+}
+
+// Left returns the caret position next to the left of one, which in right
+// to left text is later in the text, or the position itself at the left end
+func (l *Layout) Left(index int) int {
+	return l.step(index, -1)
+}
+
+// Right returns the caret position next to the right of one, or the
+// position itself at the right end
+func (l *Layout) Right(index int) int {
+	return l.step(index, 1)
+}
+
+// step returns the caret position nearest to one in a direction on screen,
+// preferring the one nearest in the text among those at the same place
+func (l *Layout) step(index, dir int) int {
+	index = min(max(index, 0), len(l.carets)-1)
+	x := l.carets[index]
+	found := index
+	var bestX float32
+	for i, c := range l.carets {
+		d := (c - x) * float32(dir)
+		if d < 0.5 {
+			continue
+		}
+		if found == index || d < (bestX-x)*float32(dir)-0.5 ||
+			abs(c-bestX) < 0.5 && absInt(i-index) < absInt(found-index) {
+			found, bestX = i, c
+		}
+	}
+	return found
+}
+
+// abs returns the absolute value of a float
+func abs(v float32) float32 {
+	return float32(math.Abs(float64(v)))
+}
+
+// absInt returns the absolute value of an int
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// scriptOf returns the OpenType tag of the script whose features shape a
+// rune
+func scriptOf(r rune) string {
+	switch {
+	case classify(r) == bidiAL || unicode.Is(unicode.Arabic, r):
+		return "arab"
+	case unicode.Is(unicode.Hebrew, r):
+		return "hebr"
+	}
+	return "latn"
+}
+
+// joining is how an Arabic letter connects to its neighbours
+type joining uint8
+
+const (
+	// joinNone letters do not connect
+	joinNone joining = iota
+	// joinRight letters connect only to the letter before them
+	joinRight
+	// joinDual letters connect on both sides
+	joinDual
+	// joinCausing characters such as the tatweel connect both neighbours
+	joinCausing
+	// joinTransparent marks are skipped over
+	joinTransparent
+)
+
+// joiningType returns how a rune joins in Arabic text
+func joiningType(r rune) joining {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me):
+		return joinTransparent
+	case r == 0x0640 || r == 0x200D:
+		return joinCausing
+	case r == 0x0622, r == 0x0623, r == 0x0624, r == 0x0625, r == 0x0627, r == 0x0629,
+		r >= 0x062F && r <= 0x0632, r == 0x0648, r >= 0x0671 && r <= 0x0673,
+		r >= 0x0675 && r <= 0x0677, r >= 0x0688 && r <= 0x0699, r == 0x06C0,
+		r >= 0x06C3 && r <= 0x06CB, r == 0x06CD, r == 0x06CF, r == 0x06D2, r == 0x06D3,
+		r == 0x06D5, r == 0x06EE, r == 0x06EF:
+		return joinRight
+	case r == 0x0620, r == 0x0626, r == 0x0628, r >= 0x062A && r <= 0x062E,
+		r >= 0x0633 && r <= 0x063F, r >= 0x0641 && r <= 0x0647, r == 0x0649, r == 0x064A,
+		r == 0x066E, r == 0x066F, r >= 0x0678 && r <= 0x0687, r >= 0x069A && r <= 0x06BF,
+		r == 0x06C1, r == 0x06C2, r == 0x06CC, r == 0x06CE, r == 0x06D0, r == 0x06D1,
+		r >= 0x06FA && r <= 0x06FC, r == 0x06FF:
+		return joinDual
+	}
+	return joinNone
+}
+
+// joiningForms returns the form feature each rune of Arabic text takes from
+// how it joins its neighbours, empty for runes that do not join
+func joiningForms(runes []rune) []string {
+	forms := make([]string, len(runes))
+	types := make([]joining, len(runes))
+	for i, r := range runes {
+		types[i] = joiningType(r)
+	}
+	// neighbour returns the type of the nearest rune that is not a mark
+	neighbour := func(i, step int) joining {
+		for i += step; i >= 0 && i < len(runes); i += step {
+			if types[i] != joinTransparent {
+				return types[i]
+			}
+		}
+		return joinNone
+	}
+	for i, t := range types {
+		if t != joinRight && t != joinDual {
+			continue
+		}
+		before := neighbour(i, -1)
+		after := neighbour(i, 1)
+		joinsBefore := before == joinDual || before == joinCausing
+		joinsAfter := t == joinDual && (after == joinDual || after == joinRight || after == joinCausing)
+		switch {
+		case joinsBefore && joinsAfter:
+			forms[i] = "medi"
+		case joinsBefore:
+			forms[i] = "fina"
+		case joinsAfter:
+			forms[i] = "init"
+		default:
+			forms[i] = "isol"
+		}
+	}
+	return forms
+}
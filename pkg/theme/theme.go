@@ -0,0 +1,184 @@
+// Package theme defines the colors and metrics the built-in widgets are
+// drawn with, and light and dark presets.
+package theme
+
+import (
+	"github.com/mleku/goo/pkg/render"
+	"github.com/mleku/goo/pkg/sound"
+)
+
+// Color is a non-premultiplied RGBA color with components from 0 to 1
+type Color = [4]float32
+
+// Radii holds the corner radii used for rounded shapes, from small controls
+// such as text inputs up to large surfaces such as popups
+type Radii struct {
+	Small, Medium, Large float32
+}
+
+// SyntaxColors color the kinds of tokens in code editors
+type SyntaxColors struct {
+	Keyword, Type, Function, String, Number, Comment, Operator Color
+}
+
+// Skins holds nine-patch images drawn as the backgrounds of the built-in
+// widgets in place of their filled shapes, for artist made looks. Nil skins
+// leave the widgets drawn in the theme's colors.
+type Skins struct {
+	// Button is the background of buttons, with variants for the hovered,
+	// pressed and disabled states that fall back to it when nil
+	Button, ButtonHover, ButtonPressed, ButtonDisabled *render.NinePatch
+	// Panel is the background of raised surfaces such as dialogs, menus and
+	// the lists of dropdowns
+	Panel *render.NinePatch
+	// Tooltip is the background of tooltips
+	Tooltip *render.NinePatch
+}
+
+// Sounds are played as the built-in widgets are used, through the sound
+// package's default mixer. Nil sounds are not played, and the presets have
+// none, so themes are silent unless sounds are attached.
+type Sounds struct {
+	// Click plays as buttons, checkboxes and radio buttons are clicked
+	Click *sound.Sample
+	// Hover plays as the pointer moves onto a button
+	Hover *sound.Sample
+	// Notice plays as a toast is shown, and Error as one reporting a
+	// failure is
+	Notice, Error *sound.Sample
+}
+
+// Theme is the palette and metrics shared by the widgets in a tree. Widgets
+// read it from the context when painting, so changing the theme of the root
+// restyles every widget that has not overridden a color.
+type Theme struct {
+	// Name identifies the theme, for remembering which the user chose
+	Name string
+	// Background fills the window behind all widgets
+	Background Color
+	// BackgroundBrush paints the window instead of Background when set, for
+	// gradient backgrounds. Positions in the brush are relative to the window.
+	BackgroundBrush render.Brush
+	// Surface is the fill of controls such as buttons, with variants for
+	// the hovered and pressed states
+	Surface, SurfaceHover, SurfacePressed Color
+	// Field is the background of text entry widgets
+	Field Color
+	// Primary is the accent used for focus rings, checked controls and
+	// active scrollbar thumbs
+	Primary Color
+	// Text is the main text color and TextMuted the color of hints and
+	// secondary text
+	Text, TextMuted Color
+	// Border outlines controls
+	Border Color
+	// Link colors links, with variants for links already followed and the
+	// link being clicked
+	Link, LinkVisited, LinkActive Color
+	// Error colors messages about invalid input, and Warning and Success
+	// mark notices of trouble and of work done
+	Error, Warning, Success Color
+	// Selection highlights selected text
+	Selection Color
+	// Disabled is the fill of disabled controls
+	Disabled Color
+	// Track and Thumb color scrollbars and gutters
+	Track, Thumb Color
+	// Backdrop dims the window behind modal dialogs
+	Backdrop Color
+	// Shadow is the color of the shadows cast by raised surfaces
+	Shadow Color
+	// Radius holds the corner radii
+	Radius Radii
+	// Spacing is the base unit of the spacing scale
+	Spacing float32
+	// Syntax holds the colors of highlighted source code
+	Syntax SyntaxColors
+	// Skins replace the drawn backgrounds of widgets with images
+	Skins Skins
+	// Sounds are played as widgets are used
+	Sounds Sounds
+}
+
+// Space returns a step of the spacing scale, a multiple of the base unit
+func (t *Theme) Space(steps float32) float32 {
+	return t.Spacing * steps
+}
+
+// Dark returns a new theme with light text on dark surfaces
+func Dark() *Theme {
+	return &Theme{
+		Name:           "dark",
+		Background:     Color{0.0, 0.0, 0.0, 1.0},
+		Surface:        Color{0.25, 0.25, 0.3, 1.0},
+		SurfaceHover:   Color{0.35, 0.35, 0.42, 1.0},
+		SurfacePressed: Color{0.15, 0.15, 0.2, 1.0},
+		Field:          Color{0.1, 0.1, 0.12, 1.0},
+		Primary:        Color{0.4, 0.6, 1.0, 1.0},
+		Text:           Color{1.0, 1.0, 1.0, 1.0},
+		TextMuted:      Color{0.5, 0.5, 0.55, 1.0},
+		Border:         Color{0.5, 0.5, 0.58, 1.0},
+		Link:           Color{0.45, 0.65, 1.0, 1.0},
+		LinkVisited:    Color{0.7, 0.55, 0.95, 1.0},
+		LinkActive:     Color{0.95, 0.45, 0.45, 1.0},
+		Error:          Color{1.0, 0.42, 0.42, 1.0},
+		Warning:        Color{1.0, 0.75, 0.3, 1.0},
+		Success:        Color{0.4, 0.8, 0.5, 1.0},
+		Selection:      Color{0.25, 0.4, 0.7, 1.0},
+		Disabled:       Color{0.2, 0.2, 0.2, 0.5},
+		Track:          Color{0.15, 0.15, 0.18, 1.0},
+		Thumb:          Color{0.45, 0.45, 0.5, 1.0},
+		Backdrop:       Color{0.0, 0.0, 0.0, 0.6},
+		Shadow:         Color{0.0, 0.0, 0.0, 0.6},
+		Radius:         Radii{Small: 2, Medium: 4, Large: 8},
+		Spacing:        4,
+		Syntax: SyntaxColors{
+			Keyword:  Color{0.8, 0.47, 0.87, 1.0},
+			Type:     Color{0.35, 0.78, 0.85, 1.0},
+			Function: Color{0.38, 0.65, 0.98, 1.0},
+			String:   Color{0.6, 0.8, 0.45, 1.0},
+			Number:   Color{0.95, 0.65, 0.4, 1.0},
+			Comment:  Color{0.45, 0.5, 0.55, 1.0},
+			Operator: Color{0.7, 0.75, 0.82, 1.0},
+		},
+	}
+}
+
+// Light returns a new theme with dark text on light surfaces
+func Light() *Theme {
+	return &Theme{
+		Name:           "light",
+		Background:     Color{0.95, 0.95, 0.96, 1.0},
+		Surface:        Color{0.86, 0.86, 0.89, 1.0},
+		SurfaceHover:   Color{0.8, 0.8, 0.85, 1.0},
+		SurfacePressed: Color{0.72, 0.72, 0.78, 1.0},
+		Field:          Color{1.0, 1.0, 1.0, 1.0},
+		Primary:        Color{0.2, 0.45, 0.9, 1.0},
+		Text:           Color{0.1, 0.1, 0.12, 1.0},
+		TextMuted:      Color{0.45, 0.45, 0.5, 1.0},
+		Border:         Color{0.68, 0.68, 0.74, 1.0},
+		Link:           Color{0.1, 0.35, 0.85, 1.0},
+		LinkVisited:    Color{0.45, 0.2, 0.7, 1.0},
+		LinkActive:     Color{0.8, 0.1, 0.1, 1.0},
+		Error:          Color{0.78, 0.12, 0.12, 1.0},
+		Warning:        Color{0.8, 0.5, 0.0, 1.0},
+		Success:        Color{0.1, 0.55, 0.25, 1.0},
+		Selection:      Color{0.7, 0.8, 1.0, 1.0},
+		Disabled:       Color{0.85, 0.85, 0.85, 0.6},
+		Track:          Color{0.9, 0.9, 0.92, 1.0},
+		Thumb:          Color{0.65, 0.65, 0.7, 1.0},
+		Backdrop:       Color{0.0, 0.0, 0.0, 0.35},
+		Shadow:         Color{0.0, 0.0, 0.0, 0.3},
+		Radius:         Radii{Small: 2, Medium: 4, Large: 8},
+		Spacing:        4,
+		Syntax: SyntaxColors{
+			Keyword:  Color{0.6, 0.15, 0.65, 1.0},
+			Type:     Color{0.05, 0.5, 0.55, 1.0},
+			Function: Color{0.15, 0.35, 0.8, 1.0},
+			String:   Color{0.3, 0.55, 0.15, 1.0},
+			Number:   Color{0.75, 0.4, 0.05, 1.0},
+			Comment:  Color{0.5, 0.52, 0.55, 1.0},
+			Operator: Color{0.3, 0.3, 0.35, 1.0},
+		},
+	}
+}
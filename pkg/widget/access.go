@@ -0,0 +1,411 @@
+package widget
+
+import (
+	"slices"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"lol.mleku.dev/chk"
+)
+
+// Re-export the accessibility types for widgets describing themselves
+type (
+	AccessNode   = interfaces.AccessNode
+	AccessAction = interfaces.AccessAction
+	AccessBridge = interfaces.AccessBridge
+)
+
+// Accessible is implemented by widgets that describe themselves to
+// assistive technology such as screen readers. Accessibility returns the
+// role, name, value, state and actions of the widget; the root fills in the
+// ID, bounds and children. Widgets that are not accessible, such as layout
+// containers, are left out of the tree and their accessible descendants
+// belong to their nearest accessible ancestor.
+type Accessible interface {
+	Accessibility() AccessNode
+}
+
+// AccessActor is implemented by accessible widgets that perform actions for
+// assistive technology, reporting whether they did. Focus is handled by the
+// root for widgets that can take focus.
+type AccessActor interface {
+	AccessAction(ctx *Context, action AccessAction, value string) bool
+}
+
+// accessTree follows the accessibility tree of a root for a bridge
+type accessTree struct {
+	bridge AccessBridge
+	// name is the name of the window node
+	name string
+	// ids and widgets map the widgets shown to their node IDs and back
+	ids     map[Widget]uint64
+	widgets map[uint64]Widget
+	nextID  uint64
+	// nodes holds the nodes as last sent to the bridge, and focus the ID of
+	// the focused node
+	nodes map[uint64]AccessNode
+	focus uint64
+	// sent is set once the whole tree has been sent
+	sent bool
+}
+
+// rootAccessID is the ID of the window node
+const rootAccessID = 1
+
+// Accessibility exposes the tree to assistive technology through a bridge to
+// the platform's accessibility interface, and returns the root for chaining.
+// The bridge receives the whole tree after the next frame and then each
+// change to it; actions it asks for are sent back to the root as
+// AccessActionEvents. A nil bridge stops updating. The tree is only built
+// while a bridge is set, and the program supplies the bridge, as none ships
+// with goo.
+func (r *RootWidget) Accessibility(bridge AccessBridge) *RootWidget {
+	if bridge == nil {
+		r.access = nil
+		return r
+	}
+	name := ""
+	if r.access != nil {
+		name = r.access.name
+	}
+	r.access = &accessTree{
+		bridge:  bridge,
+		name:    name,
+		ids:     make(map[Widget]uint64),
+		widgets: make(map[uint64]Widget),
+		nextID:  rootAccessID + 1,
+		nodes:   make(map[uint64]AccessNode),
+	}
+	r.InvalidateAll()
+	return r
+}
+
+// AccessibleName sets the name screen readers announce for the window, such
+// as its title, and returns the root for chaining
+func (r *RootWidget) AccessibleName(name string) *RootWidget {
+	if r.access == nil {
+		r.access = &accessTree{}
+	}
+	r.access.name = name
+	return r
+}
+
+// AccessTree returns the nodes of the accessibility tree from the last
+// frame by ID, and the ID of the window node, for inspecting what assistive
+// technology is told. It is empty until a bridge is set.
+func (r *RootWidget) AccessTree() (root uint64, nodes map[uint64]AccessNode) {
+	if r.access == nil || r.access.bridge == nil {
+		return 0, nil
+	}
+	return rootAccessID, r.access.nodes
+}
+
+// syncAccess builds the accessibility tree from the widgets painted and
+// sends the bridge what changed since the last frame
+func (r *RootWidget) syncAccess(canvas Rect) {
+	a := r.access
+	if a == nil || a.bridge == nil {
+		return
+	}
+	nodes, order := r.accessNodes(canvas)
+	update := interfaces.AccessUpdate{Root: rootAccessID, Focus: r.accessID(r.focused, nodes)}
+	for _, id := range order {
+		node := nodes[id]
+		if old, ok := a.nodes[id]; !a.sent || !ok || !accessEqual(old, node) {
+			update.Nodes = append(update.Nodes, node)
+		}
+	}
+	for id := range a.nodes {
+		if _, ok := nodes[id]; !ok {
+			update.Removed = append(update.Removed, id)
+			delete(a.ids, a.widgets[id])
+			delete(a.widgets, id)
+		}
+	}
+	slices.Sort(update.Removed)
+	changed := !a.sent || update.Focus != a.focus || len(update.Nodes) > 0 || len(update.Removed) > 0
+	a.nodes, a.focus, a.sent = nodes, update.Focus, true
+	if changed {
+		a.bridge.Update(update)
+	}
+}
+
+// accessNodes builds the nodes of the widgets registered in the hit regions
+// of the last frame, returning them by ID and the IDs with each parent
+// before its children
+func (r *RootWidget) accessNodes(canvas Rect) (nodes map[uint64]AccessNode, order []uint64) {
+	a := r.access
+	nodes = map[uint64]AccessNode{rootAccessID: {
+		ID:     rootAccessID,
+		Role:   interfaces.RoleWindow,
+		Name:   a.name,
+		Bounds: canvas,
+	}}
+	order = []uint64{rootAccessID}
+	type shown struct {
+		widget Widget
+		id     uint64
+	}
+	var list []shown
+	for _, region := range r.hits.Regions() {
+		w, ok := region.Target.(Widget)
+		if !ok {
+			continue
+		}
+		info, ok := w.(Accessible)
+		if !ok || claimed(w) {
+			continue
+		}
+		id := a.id(w)
+		if _, dup := nodes[id]; dup {
+			continue
+		}
+		node := info.Accessibility()
+		node.ID, node.Bounds, node.Children = id, region.Rect, nil
+		if n, ok := w.(interface{ navigable() bool }); ok && n.navigable() {
+			node.State |= interfaces.StateFocusable
+		}
+		if w == r.focused {
+			node.State |= interfaces.StateFocused
+		}
+		nodes[id] = node
+		list = append(list, shown{w, id})
+	}
+	// Children are linked once every node exists, as regions repainted
+	// later come after those of their descendants
+	for _, s := range list {
+		parent := nodes[r.accessID(parentOf(s.widget), nodes)]
+		parent.Children = append(parent.Children, s.id)
+		nodes[parent.ID] = parent
+	}
+	var visit func(id uint64)
+	visit = func(id uint64) {
+		for _, child := range nodes[id].Children {
+			order = append(order, child)
+			visit(child)
+		}
+	}
+	visit(rootAccessID)
+	return
+}
+
+// accessID returns the ID of the node of a widget or its nearest ancestor
+// with a node, the window node when there is none
+func (r *RootWidget) accessID(w Widget, nodes map[uint64]AccessNode) uint64 {
+	for ; w != nil; w = parentOf(w) {
+		if id, ok := r.access.ids[w]; ok {
+			if _, shown := nodes[id]; shown {
+				return id
+			}
+		}
+	}
+	return rootAccessID
+}
+
+// id returns the node ID of a widget, assigning one the first time it is shown
+func (a *accessTree) id(w Widget) uint64 {
+	if id, ok := a.ids[w]; ok {
+		return id
+	}
+	id := a.nextID
+	a.nextID++
+	a.ids[w] = id
+	a.widgets[id] = w
+	return id
+}
+
+// accessEvent performs an action assistive technology asked a node for
+func (r *RootWidget) accessEvent(ctx *Context, e interfaces.AccessActionEvent) bool {
+	if r.access == nil {
+		return false
+	}
+	w, ok := r.access.widgets[e.Target]
+	if !ok {
+		return false
+	}
+	if n, ok := w.(interface{ navigable() bool }); ok && n.navigable() && e.Action == interfaces.AccessFocus {
+		r.SetFocus(w)
+		return true
+	}
+	actor, ok := w.(AccessActor)
+	if !ok {
+		return false
+	}
+	box := ctx.ParentBox
+	if t, ok := w.(paintTracker); ok {
+		box = t.lastPaintBox()
+	}
+	return actor.AccessAction(childContext(ctx, box), e.Action, e.Value)
+}
+
+// claimed reports whether a widget is described by the semantics wrapped
+// around it rather than by itself, or names its parent, as the label of a
+// button does
+func claimed(w Widget) bool {
+	switch p := parentOf(w).(type) {
+	case *SemanticsWidget:
+		return p.child == w
+	case interface{ accessLabel() Widget }:
+		return p.accessLabel() == w
+	}
+	return false
+}
+
+// parentOf returns the parent of a widget, nil for the root or a widget that
+// does not track its parent
+func parentOf(w Widget) Widget {
+	if p, ok := w.(interface{ Parent() Widget }); ok {
+		return p.Parent()
+	}
+	return nil
+}
+
+// accessEqual reports whether two nodes describe the same thing
+func accessEqual(a, b AccessNode) bool {
+	return a.ID == b.ID && a.Role == b.Role && a.Name == b.Name && a.Description == b.Description &&
+		a.Value == b.Value && a.Numeric == b.Numeric && a.Min == b.Min && a.Max == b.Max &&
+		a.State == b.State && a.Bounds == b.Bounds &&
+		slices.Equal(a.Actions, b.Actions) && slices.Equal(a.Children, b.Children)
+}
+
+// accessName returns the text of a widget used as the name of another, such
+// as the label of a button, empty when it has none
+func accessName(w Widget) string {
+	switch w := w.(type) {
+	case *LabelWidget:
+		return w.Text()
+	case *RichTextWidget:
+		return w.Text()
+	case Accessible:
+		return w.Accessibility().Name
+	}
+	return ""
+}
+
+// disabledState returns StateDisabled when disabled is set
+func disabledState(disabled bool) interfaces.AccessState {
+	if disabled {
+		return interfaces.StateDisabled
+	}
+	return 0
+}
+
+// SemanticsWidget describes its child to assistive technology, giving a
+// name to a widget that shows none, such as a button with an icon, or
+// making a custom widget accessible. When the child is itself accessible
+// the two are one node, with what the semantics set replacing what the
+// child reports, and the child's actions still available.
+type SemanticsWidget struct {
+	Base
+	child       Widget
+	role        interfaces.Role
+	roleSet     bool
+	name        string
+	description string
+}
+
+// Semantics creates a widget describing its child to assistive technology
+func Semantics(child Widget) *SemanticsWidget {
+	s := &SemanticsWidget{child: child}
+	adopt(s, child)
+	return s
+}
+
+// Role sets the role and returns the widget for chaining
+func (s *SemanticsWidget) Role(role interfaces.Role) *SemanticsWidget {
+	s.role, s.roleSet = role, true
+	return s
+}
+
+// Name sets the name screen readers announce and returns the widget for
+// chaining
+func (s *SemanticsWidget) Name(name string) *SemanticsWidget {
+	s.name = name
+	return s
+}
+
+// Description sets further explanation read after the name, such as what a
+// control does, and returns the widget for chaining
+func (s *SemanticsWidget) Description(description string) *SemanticsWidget {
+	s.description = description
+	return s
+}
+
+// Accessibility implements Accessible, merging the child's description
+func (s *SemanticsWidget) Accessibility() (node AccessNode) {
+	if a, ok := s.child.(Accessible); ok {
+		node = a.Accessibility()
+	}
+	if s.roleSet {
+		node.Role = s.role
+	}
+	if s.name != "" {
+		node.Name = s.name
+	}
+	if s.description != "" {
+		node.Description = s.description
+	}
+	if n, ok := s.child.(interface{ navigable() bool }); ok && n.navigable() {
+		node.State |= interfaces.StateFocusable
+	}
+	if hasFocus(s.child) {
+		node.State |= interfaces.StateFocused
+	}
+	return
+}
+
+// AccessAction implements AccessActor, passing actions to the child
+func (s *SemanticsWidget) AccessAction(ctx *Context, action AccessAction, value string) bool {
+	if action == interfaces.AccessFocus {
+		if n, ok := s.child.(interface{ navigable() bool }); ok && n.navigable() {
+			requestFocus(s.child)
+			return true
+		}
+		return false
+	}
+	if a, ok := s.child.(AccessActor); ok {
+		return a.AccessAction(ctx, action, value)
+	}
+	return false
+}
+
+// GetConstraints returns the child's constraints
+func (s *SemanticsWidget) GetConstraints() Constraints {
+	if s.child == nil {
+		return NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return s.child.GetConstraints()
+}
+
+// Measure returns the size the child measures
+func (s *SemanticsWidget) Measure(constraints Constraints) Size {
+	return measure(s.child, constraints)
+}
+
+// Layout implements the Widget interface for SemanticsWidget; the child is
+// laid out in the widget's box
+func (s *SemanticsWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !s.NeedsLayout(ctx, constraints) {
+		return s.CachedSize(), nil
+	}
+	if size, err = layoutInset(ctx, s.child, Insets{}, constraints); chk.E(err) {
+		return
+	}
+	s.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for SemanticsWidget
+func (s *SemanticsWidget) Paint(ctx *Context, box *Box) (err error) {
+	if s.child == nil {
+		return
+	}
+	return paintChild(ctx, s.child, box)
+}
+
+// HandleEvent implements the Widget interface for SemanticsWidget
+func (s *SemanticsWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if s.child == nil {
+		return false
+	}
+	return routeEvent(ctx, s.child, box, ev)
+}
@@ -0,0 +1,176 @@
+package widget
+
+import (
+	"time"
+
+	"github.com/mleku/goo/pkg/anim"
+	"lol.mleku.dev/chk"
+)
+
+// AnimatedWidget wraps a child whose position, size and opacity can be
+// animated. The child is laid out at the top left of the widget's box,
+// filling it until a size is set, and moved by an offset from there.
+type AnimatedWidget struct {
+	Base
+	child       Widget
+	constraints Constraints
+	offset      *anim.Tween[Point]
+	size        *anim.Tween[Size]
+	opacity     *anim.Tween[float32]
+	// sized is set once the child's size is controlled by the size tween
+	sized bool
+	// childSize is the child's size from the last layout
+	childSize Size
+}
+
+// Animated creates a new animated wrapper around the child.
+// If no constraints are provided, uses default flexible constraints (0, 0, 1e9, 1e9).
+func Animated(child Widget, constraints ...Constraints) *AnimatedWidget {
+	var c Constraints
+	if len(constraints) > 0 {
+		c = constraints[0]
+	} else {
+		c = NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	a := &AnimatedWidget{
+		child:       child,
+		constraints: c,
+		offset:      anim.NewTween(Point{}, lerpPoint),
+		size:        anim.NewTween(Size{}, lerpSize),
+		opacity:     anim.NewFloat(1),
+	}
+	adopt(a, child)
+	return a
+}
+
+// MoveTo slides the child to an offset from its laid out position over the
+// duration and returns the widget for chaining. A zero duration moves it at once.
+func (a *AnimatedWidget) MoveTo(x, y float32, duration time.Duration, ease anim.Easing) *AnimatedWidget {
+	// Repaint where the child was as well as where it is going
+	a.invalidate(a.travel(&a.paintBox))
+	a.offset.To(Point{X: x, Y: y}, duration, ease)
+	a.invalidate(a.travel(&a.paintBox))
+	return a
+}
+
+// ResizeTo changes the child's size over the duration and returns the
+// widget for chaining. A zero duration resizes it at once.
+func (a *AnimatedWidget) ResizeTo(width, height float32, duration time.Duration, ease anim.Easing) *AnimatedWidget {
+	if !a.sized {
+		// Start from the size the child was filling
+		a.size.Set(a.childSize)
+		a.sized = true
+	}
+	a.size.To(Size{Width: width, Height: height}, duration, ease)
+	a.MarkNeedsLayout()
+	return a
+}
+
+// FadeTo changes the child's opacity over the duration and returns the
+// widget for chaining. A zero duration changes it at once.
+func (a *AnimatedWidget) FadeTo(alpha float32, duration time.Duration, ease anim.Easing) *AnimatedWidget {
+	a.opacity.To(alpha, duration, ease)
+	a.MarkNeedsPaint()
+	return a
+}
+
+// Animating reports whether any property is still changing
+func (a *AnimatedWidget) Animating() bool {
+	return a.offset.Running() || a.size.Running() || a.opacity.Running()
+}
+
+// GetConstraints returns the animated widget's constraints
+func (a *AnimatedWidget) GetConstraints() Constraints {
+	return a.constraints
+}
+
+// Measure returns the minimum size of the animated widget
+func (a *AnimatedWidget) Measure(constraints Constraints) Size {
+	return minSize(a.GetConstraints())
+}
+
+// Layout implements the Widget interface for AnimatedWidget
+func (a *AnimatedWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !a.NeedsLayout(ctx, constraints) {
+		return a.CachedSize(), nil
+	}
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	a.childSize = size
+	if a.sized {
+		a.childSize = a.size.Value(ctx.Clock)
+	}
+	if a.child != nil {
+		if _, err = a.child.Layout(ctx, NewRigidConstraints(a.childSize.Width, a.childSize.Height)); chk.E(err) {
+			return
+		}
+	}
+	a.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for AnimatedWidget
+func (a *AnimatedWidget) Paint(ctx *Context, box *Box) (err error) {
+	alpha := a.opacity.Value(ctx.Clock)
+	offset := a.offset.Value(ctx.Clock)
+
+	// Schedule the next frame of running animations. The tree is already
+	// laid out, so relayout requested now happens on the next frame.
+	if a.size.Running() {
+		a.MarkNeedsLayout()
+	}
+	if a.offset.Running() {
+		a.invalidate(a.travel(box))
+	}
+	if a.opacity.Running() {
+		a.invalidate(a.childBox(box, offset).Rect())
+	}
+
+	if a.child == nil || alpha <= 0 {
+		return
+	}
+	list := ctx.DrawList
+	list.PushAlpha(alpha)
+	err = paintChild(ctx, a.child, a.childBox(box, offset))
+	list.PopAlpha()
+	return
+}
+
+// HandleEvent implements the Widget interface for AnimatedWidget.
+// Fully transparent children do not receive input.
+func (a *AnimatedWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if a.child == nil || a.opacity.Target() <= 0 {
+		return false
+	}
+	return routeEvent(ctx, a.child, a.childBox(box, a.offset.Value(ctx.Clock)), ev)
+}
+
+// childBox returns the child's absolute box at an offset
+func (a *AnimatedWidget) childBox(box *Box, offset Point) *Box {
+	var c Constraints
+	if a.child != nil {
+		c = a.child.GetConstraints()
+	}
+	return NewBox(
+		box.Position.X+offset.X,
+		box.Position.Y+offset.Y,
+		a.childSize.Width,
+		a.childSize.Height,
+		c,
+	)
+}
+
+// travel returns the region the child covers over the whole of the current
+// move, so every frame repaints both where it was and where it is
+func (a *AnimatedWidget) travel(box *Box) Rect {
+	return a.childBox(box, a.offset.From()).Rect().Union(a.childBox(box, a.offset.Target()).Rect())
+}
+
+// lerpPoint interpolates between two points
+func lerpPoint(a, b Point, t float32) Point {
+	return Point{X: anim.LerpFloat(a.X, b.X, t), Y: anim.LerpFloat(a.Y, b.Y, t)}
+}
+
+// lerpSize interpolates between two sizes
+func lerpSize(a, b Size, t float32) Size {
+	return Size{Width: anim.LerpFloat(a.Width, b.Width, t), Height: anim.LerpFloat(a.Height, b.Height, t)}
+}
@@ -0,0 +1,129 @@
+package widget
+
+import (
+	"lol.mleku.dev/chk"
+)
+
+// AspectRatioWidget sizes its child to the largest box of a width to height
+// ratio that fits in the space it is given, within the child's maximum
+// size, and places it in that space by a gravity, centred unless set. Image
+// thumbnails and video surfaces keep their proportions however their
+// container is shaped.
+type AspectRatioWidget struct {
+	Base
+	child   Widget
+	ratio   float32
+	gravity Gravity
+	// childBox is the child's box from the last layout, relative to the widget
+	childBox Box
+}
+
+// AspectRatio creates a new aspect ratio widget keeping the child at ratio,
+// its width divided by its height. A ratio that is not positive is square.
+func AspectRatio(child Widget, ratio float32) *AspectRatioWidget {
+	a := &AspectRatioWidget{child: child, ratio: ratio, gravity: GravityCenter}
+	adopt(a, child)
+	return a
+}
+
+// Ratio sets the width to height ratio of the child and returns the widget
+// for chaining
+func (a *AspectRatioWidget) Ratio(ratio float32) *AspectRatioWidget {
+	a.ratio = ratio
+	a.MarkNeedsLayout()
+	return a
+}
+
+// Gravity sets where the child is placed in the space it does not fill and
+// returns the widget for chaining
+func (a *AspectRatioWidget) Gravity(gravity Gravity) *AspectRatioWidget {
+	a.gravity = gravity
+	a.MarkNeedsLayout()
+	return a
+}
+
+// aspect returns the ratio, square when it is not positive
+func (a *AspectRatioWidget) aspect() float32 {
+	if a.ratio <= 0 {
+		return 1
+	}
+	return a.ratio
+}
+
+// GetConstraints returns the child's constraints with the minimum enlarged
+// to the ratio, and no maximum since the widget takes the space around the
+// child
+func (a *AspectRatioWidget) GetConstraints() Constraints {
+	c := NewFlexConstraints(0, 0, 1e9, 1e9)
+	if a.child == nil {
+		return c
+	}
+	cc := a.child.GetConstraints()
+	r := a.aspect()
+	c.MinWidth = max(cc.MinWidth, cc.MinHeight*r)
+	c.MinHeight = max(cc.MinHeight, cc.MinWidth/r)
+	return c
+}
+
+// Measure returns the smallest size of the ratio holding the size the child
+// measures
+func (a *AspectRatioWidget) Measure(constraints Constraints) Size {
+	m := measure(a.child, constraints)
+	r := a.aspect()
+	width := max(m.Width, m.Height*r)
+	return Size{Width: width, Height: width / r}
+}
+
+// Layout implements the Widget interface for AspectRatioWidget. The widget
+// takes all the space offered, or the child's size along an unbounded axis.
+func (a *AspectRatioWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !a.NeedsLayout(ctx, constraints) {
+		return a.CachedSize(), nil
+	}
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	if a.child == nil {
+		a.SetLayout(ctx, constraints, size)
+		return
+	}
+	cc := a.child.GetConstraints()
+	r := a.aspect()
+	// The widest box of the ratio within the space and the child's maximum
+	width := min(constraints.MaxWidth, constraints.MaxHeight*r, cc.MaxWidth, cc.MaxHeight*r)
+	if width >= 1e9 {
+		// Unbounded on both axes, so the child keeps its minimum
+		width = max(cc.MinWidth, cc.MinHeight*r)
+	}
+	child := Size{Width: width, Height: width / r}
+	if size.Width >= 1e9 {
+		size.Width = child.Width
+	}
+	if size.Height >= 1e9 {
+		size.Height = child.Height
+	}
+	if _, err = a.child.Layout(ctx, NewRigidConstraints(child.Width, child.Height)); chk.E(err) {
+		return
+	}
+	a.childBox = Box{
+		Position:    anchorIn(a.gravity, size, child, Point{}),
+		Size:        child,
+		Constraints: cc,
+	}
+	a.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for AspectRatioWidget
+func (a *AspectRatioWidget) Paint(ctx *Context, box *Box) (err error) {
+	if a.child == nil {
+		return
+	}
+	return paintChild(ctx, a.child, childBox(box, &a.childBox))
+}
+
+// HandleEvent implements the Widget interface for AspectRatioWidget
+func (a *AspectRatioWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if a.child == nil {
+		return false
+	}
+	return routeEvent(ctx, a.child, childBox(box, &a.childBox), ev)
+}
@@ -0,0 +1,542 @@
+package widget
+
+import (
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/text"
+	"lol.mleku.dev/chk"
+)
+
+// autocompleteDebounce is how long typing pauses before suggestions are
+// queried, unless set otherwise
+const autocompleteDebounce = 150 * time.Millisecond
+
+// AutocompleteWidget is a text input that suggests completions of the text
+// typed in a list below it. Suggestions come from a callback, queried once
+// typing pauses so a slow or remote source is not asked on every key, and
+// the part of each suggestion matching the text typed is highlighted.
+//
+// While the list is showing the up and down keys move through it, enter
+// takes the highlighted suggestion and escape hides the list. Clicking a
+// suggestion takes it too.
+type AutocompleteWidget struct {
+	Base
+	input      *TextInputWidget
+	suggest    func(query string) []string
+	fetch      func(query string, done func(suggestions []string))
+	debounce   time.Duration
+	minChars   int
+	maxVisible int
+	onSelect   func(s string)
+	// suggestions are the suggestions for query, the text they were asked for
+	suggestions []string
+	query       string
+	// generation counts the queries, so the answers to ones the text has
+	// since changed from are dropped
+	generation int
+	// pending is set when the text changed and the query is scheduled at
+	// the next paint, and timer is the scheduled query
+	pending bool
+	timer   *timer
+	// focused is whether the input had focus when last painted, to tell
+	// when it loses it
+	focused bool
+	list    *suggestionList
+	popup   *Popup
+}
+
+// Autocomplete creates a new empty autocomplete input drawn in the given
+// font at 14 pixels, showing up to 8 suggestions before the list scrolls
+func Autocomplete(font *text.Font) *AutocompleteWidget {
+	a := &AutocompleteWidget{
+		input:      TextInput(font),
+		debounce:   autocompleteDebounce,
+		minChars:   1,
+		maxVisible: 8,
+	}
+	a.list = &suggestionList{owner: a, hot: -1}
+	a.popup = NewPopup(a.list).OnClose(a.closed)
+	a.input.OnChange(a.typed)
+	a.input.keys = a.key
+	adopt(a, a.input)
+	return a
+}
+
+// Size sets the pixel size of the text and returns the input for chaining
+func (a *AutocompleteWidget) Size(size float32) *AutocompleteWidget {
+	a.input.Size(size)
+	a.MarkNeedsLayout()
+	return a
+}
+
+// Placeholder sets the hint shown while nothing is typed and the input is
+// unfocused, and returns the input for chaining
+func (a *AutocompleteWidget) Placeholder(s string) *AutocompleteWidget {
+	a.input.Placeholder(s)
+	return a
+}
+
+// Suggest sets the callback returning the suggestions for the text typed
+// and returns the input for chaining
+func (a *AutocompleteWidget) Suggest(fn func(query string) []string) *AutocompleteWidget {
+	a.suggest, a.fetch = fn, nil
+	return a
+}
+
+// SuggestAsync sets the callback looking up the suggestions for the text
+// typed, such as from a server, and returns the input for chaining. The
+// callback returns at once and calls done with the suggestions when it has
+// them; done must be called on the UI thread, so a lookup running in
+// another goroutine passes it through the window's RunOnUIThread. Answers
+// arriving after the text changed again are dropped.
+func (a *AutocompleteWidget) SuggestAsync(fn func(query string, done func(suggestions []string))) *AutocompleteWidget {
+	a.suggest, a.fetch = nil, fn
+	return a
+}
+
+// Debounce sets how long typing pauses before suggestions are queried and
+// returns the input for chaining. It defaults to 150 milliseconds.
+func (a *AutocompleteWidget) Debounce(d time.Duration) *AutocompleteWidget {
+	a.debounce = max(d, 0)
+	return a
+}
+
+// MinChars sets how many characters are typed before suggestions are
+// queried and returns the input for chaining. It defaults to one.
+func (a *AutocompleteWidget) MinChars(n int) *AutocompleteWidget {
+	a.minChars = max(n, 1)
+	return a
+}
+
+// MaxVisible sets how many suggestions the list shows before it scrolls
+// and returns the input for chaining
+func (a *AutocompleteWidget) MaxVisible(n int) *AutocompleteWidget {
+	a.maxVisible = max(n, 1)
+	return a
+}
+
+// OnSelect sets the callback invoked with the suggestion the user takes
+// and returns the input for chaining
+func (a *AutocompleteWidget) OnSelect(fn func(s string)) *AutocompleteWidget {
+	a.onSelect = fn
+	return a
+}
+
+// OnSubmit sets the callback invoked with the text when enter is pressed
+// with no suggestion highlighted, and returns the input for chaining
+func (a *AutocompleteWidget) OnSubmit(fn func(s string)) *AutocompleteWidget {
+	a.input.OnSubmit(fn)
+	return a
+}
+
+// SetText replaces the text without querying suggestions, hiding any shown
+func (a *AutocompleteWidget) SetText(s string) {
+	a.forget()
+	a.input.SetText(s)
+}
+
+// Text returns the current text
+func (a *AutocompleteWidget) Text() string {
+	return a.input.Text()
+}
+
+// Suggestions returns the suggestions shown for the text, nil when the
+// list is hidden
+func (a *AutocompleteWidget) Suggestions() []string {
+	if !a.list.open {
+		return nil
+	}
+	return a.suggestions
+}
+
+// GetConstraints returns the constraints of the text input
+func (a *AutocompleteWidget) GetConstraints() Constraints {
+	return a.input.GetConstraints()
+}
+
+// Measure returns the minimum size of the text input
+func (a *AutocompleteWidget) Measure(constraints Constraints) Size {
+	return a.input.Measure(constraints)
+}
+
+// Layout implements the Widget interface for AutocompleteWidget; the list
+// closes when the input is resized
+func (a *AutocompleteWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !a.NeedsLayout(ctx, constraints) {
+		return a.CachedSize(), nil
+	}
+	a.close()
+	if size, err = a.input.Layout(ctx, constraints); chk.E(err) {
+		return
+	}
+	a.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for AutocompleteWidget
+func (a *AutocompleteWidget) Paint(ctx *Context, box *Box) (err error) {
+	if focused := hasFocus(a.input); focused != a.focused {
+		a.focused = focused
+		if !focused {
+			a.forget()
+		}
+	}
+	if a.pending {
+		// The query waits for typing to pause from the frame the text
+		// changed in
+		a.pending = false
+		if r := rootOf(a); r != nil {
+			a.timer = r.schedule(frameTime(ctx).Add(a.debounce), a.ask)
+		}
+	}
+	return paintChild(ctx, a.input, box)
+}
+
+// CursorAt implements CursorProvider, showing the text cursor over the input
+func (a *AutocompleteWidget) CursorAt(p Point) Cursor {
+	return interfaces.CursorIBeam
+}
+
+// Accessibility implements Accessible, describing the input as a combo box
+// whose list expands with suggestions
+func (a *AutocompleteWidget) Accessibility() AccessNode {
+	node := AccessNode{
+		Role:    interfaces.RoleComboBox,
+		Name:    a.input.placeholder,
+		Value:   a.input.Text(),
+		State:   interfaces.StateExpandable,
+		Actions: []AccessAction{interfaces.AccessCollapse, interfaces.AccessSetValue},
+	}
+	if a.list.open {
+		node.State |= interfaces.StateExpanded
+	}
+	return node
+}
+
+// AccessAction implements AccessActor, hiding the list or replacing the text
+func (a *AutocompleteWidget) AccessAction(ctx *Context, action AccessAction, value string) bool {
+	switch action {
+	case interfaces.AccessCollapse:
+		a.close()
+	case interfaces.AccessSetValue:
+		a.SetText(value)
+	default:
+		return false
+	}
+	return true
+}
+
+// HandleEvent implements the Widget interface for AutocompleteWidget
+func (a *AutocompleteWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	return routeEvent(ctx, a.input, box, ev)
+}
+
+// key moves through the list and takes or dismisses suggestions for the
+// keys pressed in the input
+func (a *AutocompleteWidget) key(e interfaces.KeyEvent) bool {
+	l := a.list
+	if !l.open {
+		if e.Key == interfaces.KeyDown && len(a.suggestions) > 0 {
+			a.open()
+			l.highlight(0)
+			return true
+		}
+		return false
+	}
+	last := len(a.suggestions) - 1
+	page := l.rows - 1
+	switch e.Key {
+	case interfaces.KeyUp:
+		l.highlight(max(l.hot-1, 0))
+	case interfaces.KeyDown:
+		l.highlight(min(l.hot+1, last))
+	case interfaces.KeyPageUp:
+		l.highlight(max(l.hot-page, 0))
+	case interfaces.KeyPageDown:
+		l.highlight(min(l.hot+page, last))
+	case interfaces.KeyEnter:
+		if l.hot < 0 {
+			a.close()
+			return false
+		}
+		a.pick(l.hot)
+	case interfaces.KeyEscape:
+		a.close()
+	case interfaces.KeyTab:
+		// Hide the list and let focus move on
+		a.close()
+		return false
+	default:
+		return false
+	}
+	return true
+}
+
+// typed schedules a query for the text the user typed, once typing pauses
+func (a *AutocompleteWidget) typed(s string) {
+	a.forget()
+	if utf8.RuneCountInString(s) < a.minChars || (a.suggest == nil && a.fetch == nil) {
+		return
+	}
+	a.pending = true
+	a.MarkNeedsPaint()
+}
+
+// ask queries the suggestions for the text
+func (a *AutocompleteWidget) ask() {
+	a.timer = nil
+	generation, query := a.generation, a.input.Text()
+	if a.suggest != nil {
+		a.answer(generation, query, a.suggest(query))
+		return
+	}
+	if a.fetch != nil {
+		a.fetch(query, func(suggestions []string) { a.answer(generation, query, suggestions) })
+	}
+}
+
+// answer shows the suggestions for a query, unless the text changed or
+// the input lost focus since it was asked
+func (a *AutocompleteWidget) answer(generation int, query string, suggestions []string) {
+	if generation != a.generation || !hasFocus(a.input) {
+		return
+	}
+	a.suggestions, a.query = suggestions, query
+	if len(suggestions) == 0 {
+		a.close()
+		return
+	}
+	a.open()
+}
+
+// forget drops the suggestions and any query scheduled or in flight,
+// hiding the list
+func (a *AutocompleteWidget) forget() {
+	a.generation++
+	a.pending = false
+	if r := rootOf(a); r != nil {
+		r.cancel(a.timer)
+	}
+	a.timer = nil
+	a.suggestions = nil
+	a.close()
+}
+
+// open shows the suggestions below the input, or above it when there is
+// more room there, with none highlighted. A list already showing is sized
+// again for the suggestions.
+func (a *AutocompleteWidget) open() {
+	r := rootOf(a)
+	if r == nil || len(a.suggestions) == 0 {
+		return
+	}
+	a.close()
+	box := a.paintBox
+	rowHeight := a.list.rowHeight()
+	rows := min(len(a.suggestions), a.maxVisible)
+	top := box.Position.Y
+	below := r.CachedSize().Height - top - box.Size.Height
+	above := below < float32(rows)*rowHeight+2 && top > below
+	room := below
+	placement := PlaceBelow
+	if above {
+		room, placement = top, PlaceAbove
+	}
+	l := a.list
+	// Shorten the list to whole rows that fit the window
+	l.rows = max(min(rows, int((room-2)/rowHeight)), 1)
+	l.open, l.hot, l.first = true, -1, 0
+	r.ShowPopup(a.popup.Anchor(a, placement).Size(box.Size.Width, float32(l.rows)*rowHeight+2))
+}
+
+// close hides the list
+func (a *AutocompleteWidget) close() {
+	a.popup.Close()
+}
+
+// closed updates the input after its list closed
+func (a *AutocompleteWidget) closed() {
+	a.list.open = false
+}
+
+// pick puts a suggestion in the input on behalf of the user, invoking the
+// select callback
+func (a *AutocompleteWidget) pick(index int) {
+	s := a.suggestions[index]
+	a.SetText(s)
+	requestFocus(a.input)
+	if a.onSelect != nil {
+		a.onSelect(s)
+	}
+}
+
+// suggestionList is the popup listing an autocomplete's suggestions. Rows
+// highlight under the cursor and releasing the mouse over one takes it.
+type suggestionList struct {
+	Base
+	owner *AutocompleteWidget
+	open  bool
+	// rows is how many rows fit the list, hot is the highlighted row, -1
+	// for none, and first the row shown at the top
+	rows, hot, first int
+}
+
+// highlight highlights a row, scrolling to keep it visible
+func (l *suggestionList) highlight(index int) {
+	l.hot = index
+	if index < l.first {
+		l.first = index
+	}
+	if index >= l.first+l.rows {
+		l.first = index - l.rows + 1
+	}
+	l.MarkNeedsPaint()
+}
+
+// scroll moves the rows shown by a number of rows, clamped to the suggestions
+func (l *suggestionList) scroll(rows int) {
+	last := max(len(l.owner.suggestions)-l.rows, 0)
+	first := min(max(l.first+rows, 0), last)
+	if first != l.first {
+		l.first = first
+		l.MarkNeedsPaint()
+	}
+}
+
+// rowHeight returns the height of each row, that of the input
+func (l *suggestionList) rowHeight() float32 {
+	in := l.owner.input
+	return float32(math.Ceil(float64(in.font.Face(in.size).LineHeight()))) + 2*dropdownPadding
+}
+
+// GetConstraints returns the list's constraints; the input places the list itself
+func (l *suggestionList) GetConstraints() Constraints {
+	return NewFlexConstraints(0, 0, 1e9, 1e9)
+}
+
+// Measure returns the minimum size of the list
+func (l *suggestionList) Measure(constraints Constraints) Size {
+	return minSize(l.GetConstraints())
+}
+
+// Layout implements the Widget interface for suggestionList; lists take all the space offered
+func (l *suggestionList) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	l.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for suggestionList, drawing the
+// part of each suggestion that matches the query in the primary color
+func (l *suggestionList) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	a := l.owner
+	fillSkinned(ctx, box, th.Skins.Panel, th.Radius.Small, th.Border, th.Surface)
+
+	list := ctx.DrawList
+	list.PushClip(box.Position.X+1, box.Position.Y+1, box.Size.Width-2, box.Size.Height-2)
+	defer list.PopClip()
+
+	face := a.input.font.Face(a.input.size)
+	rowHeight := l.rowHeight()
+	width := box.Size.Width - 2
+	overflow := len(a.suggestions) > l.rows
+	if overflow {
+		width -= dropdownScrollbar
+	}
+	for i := l.first; i < min(l.first+l.rows, len(a.suggestions)); i++ {
+		y := box.Position.Y + 1 + float32(i-l.first)*rowHeight
+		if i == l.hot {
+			list.Rect(box.Position.X+1, y, width, rowHeight, th.Selection)
+		}
+		s := a.suggestions[i]
+		x, baseline := box.Position.X+dropdownPadding, y+dropdownPadding+face.Ascent()
+		start, end, ok := matchSpan(s, a.query)
+		if !ok {
+			face.Draw(list, x, baseline, s, th.Text)
+			continue
+		}
+		face.Draw(list, x, baseline, s[:start], th.Text)
+		x += face.Measure(s[:start])
+		face.Draw(list, x, baseline, s[start:end], th.Primary)
+		x += face.Measure(s[start:end])
+		face.Draw(list, x, baseline, s[end:], th.Text)
+	}
+
+	if overflow {
+		track := box.Size.Height - 2
+		length, position := thumbSpan(track, float32(l.rows), float32(len(a.suggestions)), float32(l.first))
+		list.Rect(box.Position.X+box.Size.Width-1-dropdownScrollbar, box.Position.Y+1+position, dropdownScrollbar, length, th.Thumb)
+	}
+	return
+}
+
+// HandleEvent implements the Widget interface for suggestionList
+func (l *suggestionList) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		if i, ok := l.rowAt(box, e.Position); ok && i != l.hot {
+			l.hot = i
+			l.MarkNeedsPaint()
+		}
+		return box.Contains(e.Position)
+	case interfaces.ScrollEvent:
+		// Scrolls are only delivered when the cursor is inside the box
+		l.scroll(-int(math.Round(float64(e.Offset.Y))))
+		if i, ok := l.rowAt(box, e.Position); ok {
+			l.hot = i
+		}
+		return true
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft {
+			return false
+		}
+		if e.Action == interfaces.ActionPress {
+			return true
+		}
+		// Releases are broadcast, so only pick when released over a row
+		i, ok := l.rowAt(box, e.Position)
+		if !ok {
+			return false
+		}
+		l.owner.pick(i)
+		return true
+	}
+	return false
+}
+
+// rowAt returns the suggestion under a point, reporting whether there is one
+func (l *suggestionList) rowAt(box *Box, p Point) (index int, ok bool) {
+	if !box.Contains(p) {
+		return 0, false
+	}
+	index = l.first + int((p.Y-box.Position.Y-1)/l.rowHeight())
+	if index < 0 || index >= len(l.owner.suggestions) {
+		return 0, false
+	}
+	return index, true
+}
+
+// matchSpan returns the byte range of the first part of s equal to the
+// query ignoring case, reporting whether there is one
+func matchSpan(s, query string) (start, end int, ok bool) {
+	if query == "" {
+		return 0, 0, false
+	}
+	n := utf8.RuneCountInString(query)
+	for start = range s {
+		end = start
+		for k := 0; k < n && end < len(s); k++ {
+			_, size := utf8.DecodeRuneInString(s[end:])
+			end += size
+		}
+		if strings.EqualFold(s[start:end], query) {
+			return start, end, true
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,101 @@
+package widget
+
+import "lol.mleku.dev/chk"
+
+// backdropBlurReach is how many times its radius beyond its box a blur
+// samples the pixels behind it
+const backdropBlurReach = 3
+
+// BackdropBlurWidget blurs what was drawn behind its box and draws its child
+// over the blur, for frosted glass headers, sidebars and dialogs. The child
+// usually fills the box with a translucent color to tint the glass. The
+// blur samples pixels up to three times its radius beyond the box, so
+// anything changing there repaints the widget.
+type BackdropBlurWidget struct {
+	Base
+	child        Widget
+	radius       float32
+	cornerRadius float32
+}
+
+// BackdropBlur creates a new backdrop blur drawing the child over what is
+// behind it blurred by a gaussian with a standard deviation of radius
+func BackdropBlur(child Widget, radius float32) *BackdropBlurWidget {
+	b := &BackdropBlurWidget{child: child, radius: radius}
+	adopt(b, child)
+	return b
+}
+
+// Radius changes the standard deviation of the blur and returns the widget
+// for chaining
+func (b *BackdropBlurWidget) Radius(radius float32) *BackdropBlurWidget {
+	b.radius = radius
+	b.MarkNeedsPaint()
+	return b
+}
+
+// CornerRadius rounds the corners of the blurred area and returns the
+// widget for chaining
+func (b *BackdropBlurWidget) CornerRadius(radius float32) *BackdropBlurWidget {
+	b.cornerRadius = radius
+	b.MarkNeedsPaint()
+	return b
+}
+
+// GetConstraints returns the child's constraints
+func (b *BackdropBlurWidget) GetConstraints() Constraints {
+	if b.child == nil {
+		return NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return b.child.GetConstraints()
+}
+
+// Measure returns the size the child measures
+func (b *BackdropBlurWidget) Measure(constraints Constraints) Size {
+	return measure(b.child, constraints)
+}
+
+// Layout implements the Widget interface for BackdropBlurWidget; the child
+// is laid out in the widget's box
+func (b *BackdropBlurWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !b.NeedsLayout(ctx, constraints) {
+		return b.CachedSize(), nil
+	}
+	if size, err = layoutInset(ctx, b.child, Insets{}, constraints); chk.E(err) {
+		return
+	}
+	b.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for BackdropBlurWidget
+func (b *BackdropBlurWidget) Paint(ctx *Context, box *Box) (err error) {
+	list := ctx.DrawList
+	x, y, w, h := box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height
+	if b.radius > 0 {
+		if b.cornerRadius > 0 {
+			list.PushRoundClip(x, y, w, h, b.cornerRadius)
+			list.Blur(x, y, w, h, b.radius)
+			list.PopClip()
+		} else {
+			list.Blur(x, y, w, h, b.radius)
+		}
+	}
+	if b.child == nil {
+		return
+	}
+	return paintChild(ctx, b.child, box)
+}
+
+// HandleEvent implements the Widget interface for BackdropBlurWidget
+func (b *BackdropBlurWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if b.child == nil {
+		return false
+	}
+	return routeEvent(ctx, b.child, box, ev)
+}
+
+// backdropReach implements backdropSampler
+func (b *BackdropBlurWidget) backdropReach() float32 {
+	return max(b.radius, 0) * backdropBlurReach
+}
@@ -0,0 +1,254 @@
+package widget
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/mleku/goo/pkg/anim"
+	"github.com/mleku/goo/pkg/render"
+	"github.com/mleku/goo/pkg/text"
+	"lol.mleku.dev/chk"
+)
+
+const (
+	// badgePadding is the space beside the count of a badge
+	badgePadding = 4
+	// badgeDot is the diameter of a badge shown as a dot
+	badgeDot = 8
+	// badgeRing is the width of the ring in the background color that
+	// separates a badge from the child below it
+	badgeRing = 2
+	// badgeShow is how long a badge takes to pop up, and badgeHide how long
+	// it takes to shrink away
+	badgeShow = 250 * time.Millisecond
+	badgeHide = 150 * time.Millisecond
+)
+
+// BadgeCorner is the corner of its child a badge is centered on
+type BadgeCorner int
+
+const (
+	// BadgeTopRight shows the badge on the top right corner of the child
+	BadgeTopRight BadgeCorner = iota
+	// BadgeTopLeft shows the badge on the top left corner of the child
+	BadgeTopLeft
+	// BadgeBottomRight shows the badge on the bottom right corner of the child
+	BadgeBottomRight
+	// BadgeBottomLeft shows the badge on the bottom left corner of the child
+	BadgeBottomLeft
+)
+
+// BadgeWidget draws a small badge over a corner of its child, such as the
+// count of unread messages on a tab or an icon button, or a dot marking
+// something new. Counts above the most shown read as that number and a
+// plus, as in "99+". The badge pops up when it is set and shrinks away
+// when cleared.
+type BadgeWidget struct {
+	Base
+	child  Widget
+	font   *text.Font
+	size   float32
+	corner BadgeCorner
+	offset Point
+	max    int
+	color  colorOverride
+	count  int
+	dot    bool
+	// label is the text of the badge, kept while it shrinks away
+	label string
+	// pop runs from 0 hidden to 1 shown, and shown is its value as last
+	// painted
+	pop   *anim.Tween[float32]
+	shown float32
+}
+
+// Badge wraps the child to draw a badge over its top right corner, with
+// counts in the given font at 11 pixels. The badge is hidden until a count
+// or the dot is set. The wrapper takes the child's constraints.
+func Badge(child Widget, font *text.Font) *BadgeWidget {
+	b := &BadgeWidget{child: child, font: font, size: 11, max: 99, pop: anim.NewFloat(0)}
+	adopt(b, child)
+	return b
+}
+
+// Size sets the pixel size of the count and returns the badge for chaining
+func (b *BadgeWidget) Size(size float32) *BadgeWidget {
+	b.invalidate(b.paintBounds(&b.paintBox))
+	b.size = size
+	b.MarkNeedsPaint()
+	return b
+}
+
+// Corner sets the corner of the child the badge is centered on and returns
+// the badge for chaining
+func (b *BadgeWidget) Corner(corner BadgeCorner) *BadgeWidget {
+	b.invalidate(b.paintBounds(&b.paintBox))
+	b.corner = corner
+	b.MarkNeedsPaint()
+	return b
+}
+
+// Offset moves the badge from the corner, positive values towards the
+// bottom right, and returns the badge for chaining
+func (b *BadgeWidget) Offset(x, y float32) *BadgeWidget {
+	b.invalidate(b.paintBounds(&b.paintBox))
+	b.offset = Point{X: x, Y: y}
+	b.MarkNeedsPaint()
+	return b
+}
+
+// Max sets the largest count shown in full, larger ones reading as it and a
+// plus, and returns the badge for chaining. It defaults to 99.
+func (b *BadgeWidget) Max(n int) *BadgeWidget {
+	b.max = max(n, 1)
+	b.SetCount(b.count)
+	return b
+}
+
+// Color sets the color of the badge, the theme's error color unless set,
+// and returns the badge for chaining
+func (b *BadgeWidget) Color(color [4]float32) *BadgeWidget {
+	b.color = override(color)
+	b.MarkNeedsPaint()
+	return b
+}
+
+// SetCount shows the count on the badge, hiding it for zero or less
+func (b *BadgeWidget) SetCount(n int) {
+	b.count, b.dot = max(n, 0), false
+	if b.count > 0 {
+		b.label = strconv.Itoa(min(b.count, b.max))
+		if b.count > b.max {
+			b.label += "+"
+		}
+	}
+	b.update()
+}
+
+// SetDot shows the badge as a dot without a count, or hides it
+func (b *BadgeWidget) SetDot(dot bool) {
+	b.count, b.dot = 0, dot
+	if dot {
+		b.label = ""
+	}
+	b.update()
+}
+
+// Count returns the count shown on the badge, zero if none
+func (b *BadgeWidget) Count() int {
+	return b.count
+}
+
+// IsShown reports whether the badge is shown, or popping up
+func (b *BadgeWidget) IsShown() bool {
+	return b.count > 0 || b.dot
+}
+
+// update pops the badge up or shrinks it away when it is set or cleared,
+// and repaints it
+func (b *BadgeWidget) update() {
+	switch shown := b.IsShown(); {
+	case shown && b.pop.Target() < 1:
+		b.pop.To(1, badgeShow, anim.EaseOutBack)
+	case !shown && b.pop.Target() > 0:
+		b.pop.To(0, badgeHide, anim.EaseInCubic)
+	}
+	b.invalidate(b.paintBounds(&b.paintBox))
+}
+
+// GetConstraints returns the child's constraints; the badge takes no space
+func (b *BadgeWidget) GetConstraints() Constraints {
+	if b.child == nil {
+		return NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return b.child.GetConstraints()
+}
+
+// Measure returns the size the child measures
+func (b *BadgeWidget) Measure(constraints Constraints) Size {
+	return measure(b.child, constraints)
+}
+
+// Layout implements the Widget interface for BadgeWidget; the child is laid
+// out in the widget's box
+func (b *BadgeWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !b.NeedsLayout(ctx, constraints) {
+		return b.CachedSize(), nil
+	}
+	if size, err = layoutInset(ctx, b.child, Insets{}, constraints); chk.E(err) {
+		return
+	}
+	b.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for BadgeWidget, drawing the badge
+// over the child scaled by how far it has popped up
+func (b *BadgeWidget) Paint(ctx *Context, box *Box) (err error) {
+	if shown := b.pop.Value(ctx.Clock); shown != b.shown || b.pop.Running() {
+		b.shown = shown
+		b.invalidate(b.paintBounds(box))
+	}
+	if b.child != nil {
+		if err = paintChild(ctx, b.child, box); chk.E(err) {
+			return
+		}
+	}
+	if b.shown <= 0 {
+		return
+	}
+	th := themeOf(ctx)
+	r := b.badgeRect(box)
+	cx, cy := r.X+r.Width/2, r.Y+r.Height/2
+	list := ctx.DrawList
+	list.PushTransform(render.Translate(-cx, -cy).Then(render.Scale(b.shown, b.shown)).Then(render.Translate(cx, cy)))
+	defer list.PopTransform()
+	radius := r.Height / 2
+	list.RoundRect(r.X-badgeRing, r.Y-badgeRing, r.Width+2*badgeRing, r.Height+2*badgeRing, radius+badgeRing, th.Background)
+	list.RoundRect(r.X, r.Y, r.Width, r.Height, radius, b.color.or(th.Error))
+	if b.label != "" {
+		face := b.font.Face(b.size)
+		x := cx - face.Measure(b.label)/2
+		face.Draw(list, x, cy-face.LineHeight()/2+face.Ascent(), b.label, [4]float32{1, 1, 1, 1})
+	}
+	return
+}
+
+// badgeRect returns the badge at its full size, centered on the corner of
+// the child's box
+func (b *BadgeWidget) badgeRect(box *Box) Rect {
+	width, height := float32(badgeDot), float32(badgeDot)
+	if b.label != "" {
+		face := b.font.Face(b.size)
+		height = float32(math.Ceil(float64(face.LineHeight()))) + 2
+		width = max(height, float32(math.Ceil(float64(face.Measure(b.label))))+2*badgePadding)
+	}
+	x, y := box.Position.X, box.Position.Y
+	if b.corner == BadgeTopRight || b.corner == BadgeBottomRight {
+		x += box.Size.Width
+	}
+	if b.corner == BadgeBottomRight || b.corner == BadgeBottomLeft {
+		y += box.Size.Height
+	}
+	x, y = float32(math.Round(float64(x+b.offset.X-width/2))), float32(math.Round(float64(y+b.offset.Y-height/2)))
+	return Rect{X: x, Y: y, Width: width, Height: height}
+}
+
+// paintBounds implements overflowing, returning the box along with the
+// badge, which overshoots its size as it pops up
+func (b *BadgeWidget) paintBounds(box *Box) Rect {
+	r := b.badgeRect(box)
+	grow := badgeRing + r.Height/4
+	r = Rect{X: r.X - grow, Y: r.Y - grow, Width: r.Width + 2*grow, Height: r.Height + 2*grow}
+	return box.Rect().Union(r)
+}
+
+// HandleEvent implements the Widget interface for BadgeWidget, passing
+// events to the child
+func (b *BadgeWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if b.child == nil {
+		return false
+	}
+	return routeEvent(ctx, b.child, box, ev)
+}
@@ -0,0 +1,148 @@
+package widget
+
+// Base holds the cached layout result and parent link shared by widgets.
+// Embed it in a widget to implement SetParent, MarkNeedsLayout and
+// MarkNeedsPaint, and check NeedsLayout at the start of Layout to skip work
+// when nothing has changed.
+type Base struct {
+	parent Widget
+	valid  bool
+	// epoch is the layout epoch the cached layout was computed in
+	epoch       uint64
+	constraints Constraints
+	size        Size
+	// paintBox is the absolute box the widget was last painted in
+	paintBox Box
+}
+
+// SetParent links the widget to the container that lays it out so layout
+// invalidation can propagate up the tree
+func (b *Base) SetParent(parent Widget) {
+	b.parent = parent
+}
+
+// Parent returns the container that lays out the widget, nil for the root
+func (b *Base) Parent() Widget {
+	return b.parent
+}
+
+// MarkNeedsLayout discards the cached layout of the widget and its ancestors
+func (b *Base) MarkNeedsLayout() {
+	if !b.valid {
+		// Ancestors are already invalid
+		return
+	}
+	b.valid = false
+	if b.parent != nil {
+		b.parent.MarkNeedsLayout()
+	}
+}
+
+// MarkNeedsPaint schedules the region the widget was last painted in to be
+// repainted by the root on the next frame
+func (b *Base) MarkNeedsPaint() {
+	b.invalidate(b.paintBox.Rect())
+}
+
+// invalidate schedules a region of the canvas to be repainted by the root,
+// for widgets that draw outside the box they were last painted in
+func (b *Base) invalidate(rect Rect) {
+	p := b.parent
+	for p != nil {
+		if sink, ok := p.(damageSink); ok {
+			sink.addDamage(rect)
+			return
+		}
+		parented, ok := p.(interface{ Parent() Widget })
+		if !ok {
+			return
+		}
+		p = parented.Parent()
+	}
+}
+
+// setPaintBox records the box the widget is painted in for damage tracking
+func (b *Base) setPaintBox(box *Box) {
+	b.paintBox = *box
+}
+
+// lastPaintBox returns the absolute box the widget was last painted in
+func (b *Base) lastPaintBox() *Box {
+	return &b.paintBox
+}
+
+// NeedsLayout reports whether the cached size is stale for the given
+// constraints, or was computed before the root last discarded every layout
+func (b *Base) NeedsLayout(ctx *Context, constraints Constraints) bool {
+	return !b.valid || b.constraints != constraints || b.epoch != ctx.LayoutEpoch
+}
+
+// CachedSize returns the size computed by the last layout
+func (b *Base) CachedSize() Size {
+	return b.size
+}
+
+// SetLayout caches the size computed for the given constraints
+func (b *Base) SetLayout(ctx *Context, constraints Constraints, size Size) {
+	b.valid = true
+	b.epoch = ctx.LayoutEpoch
+	b.constraints = constraints
+	b.size = size
+}
+
+// damageSink collects regions that need repainting, implemented by the root
+type damageSink interface {
+	addDamage(rect Rect)
+}
+
+// overflowing is implemented by widgets that paint outside their box,
+// returning the region they paint in when laid out in a box
+type overflowing interface {
+	paintBounds(box *Box) Rect
+}
+
+// paintTracker is implemented by widgets embedding Base
+type paintTracker interface {
+	setPaintBox(box *Box)
+	lastPaintBox() *Box
+}
+
+// adopt links a child to its new parent and invalidates the parent's layout
+func adopt(parent, child Widget) {
+	if child != nil {
+		child.SetParent(parent)
+	}
+	parent.MarkNeedsLayout()
+}
+
+// minSize returns the minimum size of constraints, which a widget whose
+// size does not depend on its content measures
+func minSize(c Constraints) Size {
+	return Size{Width: c.MinWidth, Height: c.MinHeight}
+}
+
+// measure returns the size a child measures within constraints, or nothing
+// without a child
+func measure(child Widget, constraints Constraints) Size {
+	if child == nil {
+		return Size{}
+	}
+	return child.Measure(constraints)
+}
+
+// atLeast returns a size enlarged to the minimum of constraints
+func atLeast(s Size, c Constraints) Size {
+	return Size{Width: max(s.Width, c.MinWidth), Height: max(s.Height, c.MinHeight)}
+}
+
+// childBox returns the absolute box of a child from its box relative to the parent
+func childBox(parent *Box, rel *Box) *Box {
+	return &Box{
+		Position: Point{
+			X: parent.Position.X + rel.Position.X,
+			Y: parent.Position.Y + rel.Position.Y,
+		},
+		Size:        rel.Size,
+		Constraints: rel.Constraints,
+	}
+}
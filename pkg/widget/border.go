@@ -0,0 +1,117 @@
+package widget
+
+import (
+	"github.com/mleku/goo/pkg/render"
+	"lol.mleku.dev/chk"
+)
+
+// BorderWidget draws a line around its edges, optionally with rounded
+// corners, and lays its child out inside the line
+type BorderWidget struct {
+	Base
+	child Widget
+	width float32
+	color [4]float32
+	// brush paints the line instead of the color when set
+	brush  render.Brush
+	radius float32
+}
+
+// Border creates a new border widget drawing a line of the given width and
+// color around the child, with corners rounded to radius
+func Border(child Widget, width float32, color [4]float32, radius float32) *BorderWidget {
+	b := &BorderWidget{
+		child:  child,
+		width:  width,
+		color:  color,
+		radius: radius,
+	}
+	adopt(b, child)
+	return b
+}
+
+// Width sets the width of the line and returns the border for chaining
+func (b *BorderWidget) Width(width float32) *BorderWidget {
+	b.width = width
+	b.MarkNeedsLayout()
+	return b
+}
+
+// Color sets the color of the line and returns the border for chaining
+func (b *BorderWidget) Color(red, green, blue, alpha float32) *BorderWidget {
+	b.color = [4]float32{red, green, blue, alpha}
+	b.brush = render.Brush{}
+	b.MarkNeedsPaint()
+	return b
+}
+
+// Brush paints the line with a brush instead of its color, with positions
+// relative to the border's box, and returns the border for chaining
+func (b *BorderWidget) Brush(brush render.Brush) *BorderWidget {
+	b.brush = brush
+	b.MarkNeedsPaint()
+	return b
+}
+
+// Radius sets the radius of the corners and returns the border for chaining
+func (b *BorderWidget) Radius(radius float32) *BorderWidget {
+	b.radius = radius
+	b.MarkNeedsPaint()
+	return b
+}
+
+// GetConstraints returns the child's constraints enlarged by the line
+func (b *BorderWidget) GetConstraints() Constraints {
+	if b.child == nil {
+		return b.insets().grow(NewFlexConstraints(0, 0, 1e9, 1e9))
+	}
+	return b.insets().grow(b.child.GetConstraints())
+}
+
+// Measure returns the size the child measures inside the border, enlarged
+// by it
+func (b *BorderWidget) Measure(constraints Constraints) Size {
+	return b.insets().around(measure(b.child, b.insets().shrink(constraints)))
+}
+
+// Layout implements the Widget interface for BorderWidget; the child is laid
+// out inside the line
+func (b *BorderWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !b.NeedsLayout(ctx, constraints) {
+		return b.CachedSize(), nil
+	}
+	if size, err = layoutInset(ctx, b.child, b.insets(), constraints); chk.E(err) {
+		return
+	}
+	b.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for BorderWidget. The child is
+// painted first so the line stays visible over it.
+func (b *BorderWidget) Paint(ctx *Context, box *Box) (err error) {
+	if b.child != nil {
+		if err = paintChild(ctx, b.child, b.insets().box(box, b.child.GetConstraints())); chk.E(err) {
+			return
+		}
+	}
+	brush := b.brush
+	if !brush.IsSet() {
+		brush = render.Solid(b.color)
+	}
+	ctx.DrawList.StrokeRoundRect(box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height, b.radius, b.width, brush)
+	return
+}
+
+// HandleEvent implements the Widget interface for BorderWidget
+func (b *BorderWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if b.child == nil {
+		return false
+	}
+	return routeEvent(ctx, b.child, b.insets().box(box, b.child.GetConstraints()), ev)
+}
+
+// insets returns the space the line takes from each edge
+func (b *BorderWidget) insets() Insets {
+	return UniformInsets(b.width)
+}
@@ -0,0 +1,276 @@
+package widget
+
+import (
+	"cmp"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/sound"
+	"github.com/mleku/goo/pkg/state"
+	"lol.mleku.dev/chk"
+)
+
+// ButtonWidget is a clickable rectangle that renders a label widget inside it
+type ButtonWidget struct {
+	Base
+	label       Widget
+	constraints Constraints
+	padding     float32
+	onClick     func()
+	disabled    bool
+	hovered     bool
+	pressed     bool
+	// unbind stops following the value bound to the enabled state
+	unbind func()
+
+	// State colors follow the theme surfaces unless set with Colors
+	normalColor   colorOverride
+	hoverColor    colorOverride
+	pressedColor  colorOverride
+	disabledColor colorOverride
+}
+
+// Button creates a new button that renders the given label widget inside a
+// padded rectangle. The label may be nil for a plain colored button.
+// If no constraints are provided, uses default flexible constraints (0, 0, 1e9, 1e9).
+func Button(label Widget, constraints ...Constraints) *ButtonWidget {
+	var c Constraints
+	if len(constraints) > 0 {
+		c = constraints[0]
+	} else {
+		c = NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	b := &ButtonWidget{
+		label:       label,
+		constraints: c,
+		padding:     4,
+	}
+	adopt(b, label)
+	return b
+}
+
+// OnClick sets the callback invoked when the button is clicked and returns the button for chaining
+func (b *ButtonWidget) OnClick(fn func()) *ButtonWidget {
+	b.onClick = fn
+	return b
+}
+
+// Disabled sets whether the button ignores input and returns the button for chaining
+func (b *ButtonWidget) Disabled(disabled bool) *ButtonWidget {
+	b.disabled = disabled
+	if disabled {
+		b.pressed = false
+	}
+	b.MarkNeedsPaint()
+	return b
+}
+
+// BindEnabled enables the button while an observable value is true,
+// following its changes, and returns the button for chaining. Bind a paste
+// button to Clipboard.CanPasteText to enable it only while there is text to
+// paste. Binding again replaces the value followed and nil stops following.
+func (b *ButtonWidget) BindEnabled(value state.Value[bool]) *ButtonWidget {
+	if b.unbind != nil {
+		b.unbind()
+		b.unbind = nil
+	}
+	if value != nil {
+		b.unbind = state.Watch(value, func(enabled bool) { b.Disabled(!enabled) })
+	}
+	return b
+}
+
+// Padding sets the space between the button edge and its label and returns the button for chaining
+func (b *ButtonWidget) Padding(padding float32) *ButtonWidget {
+	b.padding = padding
+	b.MarkNeedsLayout()
+	return b
+}
+
+// Colors sets the background colors for each button state, replacing the
+// theme's, and returns the button for chaining
+func (b *ButtonWidget) Colors(normal, hover, pressed, disabled [4]float32) *ButtonWidget {
+	b.normalColor = override(normal)
+	b.hoverColor = override(hover)
+	b.pressedColor = override(pressed)
+	b.disabledColor = override(disabled)
+	b.MarkNeedsPaint()
+	return b
+}
+
+// IsDisabled reports whether the button is disabled
+func (b *ButtonWidget) IsDisabled() bool {
+	return b.disabled
+}
+
+// IsHovered reports whether the cursor is over the button
+func (b *ButtonWidget) IsHovered() bool {
+	return b.hovered
+}
+
+// IsPressed reports whether the button is currently held down
+func (b *ButtonWidget) IsPressed() bool {
+	return b.pressed
+}
+
+// GetConstraints returns the button's constraints
+func (b *ButtonWidget) GetConstraints() Constraints {
+	return b.constraints
+}
+
+// Measure returns the size the label measures inside the padding, at least
+// the button's minimum
+func (b *ButtonWidget) Measure(constraints Constraints) Size {
+	padding := UniformInsets(b.padding)
+	return atLeast(padding.around(measure(b.label, padding.shrink(constraints))), b.constraints)
+}
+
+// Layout implements the Widget interface for ButtonWidget.
+// The button fills the space offered and lays out its label inside the padding.
+func (b *ButtonWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !b.NeedsLayout(ctx, constraints) {
+		return b.CachedSize(), nil
+	}
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	if b.label != nil {
+		inner := b.labelBox(&Box{Size: size})
+		if _, err = b.label.Layout(ctx, NewRigidConstraints(inner.Size.Width, inner.Size.Height)); chk.E(err) {
+			return
+		}
+	}
+	b.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for ButtonWidget
+func (b *ButtonWidget) Paint(ctx *Context, box *Box) (err error) {
+	// Pick the background for the current state
+	th := themeOf(ctx)
+	color := b.normalColor.or(th.Surface)
+	overridden := b.normalColor.set
+	skin := th.Skins.Button
+	switch {
+	case b.disabled:
+		color, overridden = b.disabledColor.or(th.Disabled), b.disabledColor.set
+		skin = cmp.Or(th.Skins.ButtonDisabled, skin)
+	case b.pressed:
+		color, overridden = b.pressedColor.or(th.SurfacePressed), b.pressedColor.set
+		skin = cmp.Or(th.Skins.ButtonPressed, skin)
+	case b.hovered:
+		color, overridden = b.hoverColor.or(th.SurfaceHover), b.hoverColor.set
+		skin = cmp.Or(th.Skins.ButtonHover, skin)
+	}
+
+	// Colors set on the button win over the theme's skins, and disabled
+	// buttons have no border
+	switch {
+	case skin != nil && !overridden:
+		ctx.DrawList.NinePatch(skin, box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height, [4]float32{1, 1, 1, 1})
+	case b.disabled:
+		ctx.DrawList.RoundRect(box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height, th.Radius.Medium, color)
+	default:
+		fillBordered(ctx, box, th.Radius.Medium, th.Border, color)
+	}
+
+	if b.label == nil {
+		return
+	}
+	return paintChild(ctx, b.label, b.labelBox(box))
+}
+
+// labelBox returns the label's box inside the button padding
+func (b *ButtonWidget) labelBox(box *Box) *Box {
+	labelBox := NewBox(
+		box.Position.X+b.padding,
+		box.Position.Y+b.padding,
+		box.Size.Width-2*b.padding,
+		box.Size.Height-2*b.padding,
+		b.label.GetConstraints(),
+	)
+	if labelBox.Size.Width < 0 {
+		labelBox.Size.Width = 0
+	}
+	if labelBox.Size.Height < 0 {
+		labelBox.Size.Height = 0
+	}
+	return labelBox
+}
+
+// navigable lets gamepad navigation stop at the button unless it is disabled
+func (b *ButtonWidget) navigable() bool {
+	return !b.disabled
+}
+
+// Accessibility implements Accessible, naming the button by the text of its label
+func (b *ButtonWidget) Accessibility() AccessNode {
+	return AccessNode{
+		Role:    interfaces.RoleButton,
+		Name:    accessName(b.label),
+		State:   disabledState(b.disabled),
+		Actions: []AccessAction{interfaces.AccessClick},
+	}
+}
+
+// AccessAction implements AccessActor, clicking the button
+func (b *ButtonWidget) AccessAction(ctx *Context, action AccessAction, value string) bool {
+	if action != interfaces.AccessClick || b.disabled {
+		return false
+	}
+	if b.onClick != nil {
+		b.onClick()
+	}
+	return true
+}
+
+// accessLabel returns the label, which names the button rather than being
+// a node of its own
+func (b *ButtonWidget) accessLabel() Widget {
+	return b.label
+}
+
+// HandleEvent implements the Widget interface for ButtonWidget
+func (b *ButtonWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		hovered := box.Contains(e.Position)
+		if hovered && !b.hovered && !b.disabled {
+			sound.Play(themeOf(ctx).Sounds.Hover)
+		}
+		b.setHovered(hovered)
+	case interfaces.CursorLeaveEvent:
+		b.setHovered(false)
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft || b.disabled {
+			return false
+		}
+		switch e.Action {
+		case interfaces.ActionPress:
+			// Presses are only delivered when the cursor is inside the box
+			b.pressed = true
+			b.MarkNeedsPaint()
+			return true
+		case interfaces.ActionRelease:
+			// Releases are broadcast, so only click when released over the button
+			if !b.pressed {
+				return false
+			}
+			b.pressed = false
+			b.MarkNeedsPaint()
+			if box.Contains(e.Position) {
+				sound.Play(themeOf(ctx).Sounds.Click)
+				if b.onClick != nil {
+					b.onClick()
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// setHovered updates the hover state, repainting when it changes
+func (b *ButtonWidget) setHovered(hovered bool) {
+	if hovered != b.hovered {
+		b.hovered = hovered
+		b.MarkNeedsPaint()
+	}
+}
@@ -0,0 +1,106 @@
+package widget_test
+
+import (
+	"testing"
+
+	"github.com/mleku/goo/pkg/headless"
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/widget"
+)
+
+func TestButtonClick(t *testing.T) {
+	font := loadFont(t)
+	tests := []struct {
+		name string
+		// press and release are where the button is pressed and released,
+		// relative to its middle
+		press, release interfaces.Point
+		disabled       bool
+		clicks         int
+	}{
+		{name: "click", clicks: 1},
+		{name: "released outside", release: interfaces.Point{X: 0, Y: 100}},
+		{name: "disabled", disabled: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var clicks int
+			b := widget.Button(nil).Disabled(tt.disabled).OnClick(func() { clicks++ })
+			h := newHarness(t, widget.Column().
+				Rigid(widget.NewFixedSize(120, 32, b)).
+				Rigid(widget.Label(font, "below")), 200, 160)
+			at := h.find(b)
+			h.press(interfaces.Point{X: at.X + tt.press.X, Y: at.Y + tt.press.Y})
+			if b.IsPressed() == tt.disabled {
+				t.Errorf("pressed = %v", b.IsPressed())
+			}
+			h.release(interfaces.Point{X: at.X + tt.release.X, Y: at.Y + tt.release.Y})
+			if b.IsPressed() {
+				t.Error("still pressed after release")
+			}
+			if clicks != tt.clicks {
+				t.Errorf("clicked %d times, want %d", clicks, tt.clicks)
+			}
+		})
+	}
+}
+
+func TestButtonStates(t *testing.T) {
+	b := widget.Button(nil).Colors(
+		[4]float32{0, 0, 1, 1},
+		[4]float32{0, 1, 0, 1},
+		[4]float32{1, 0, 0, 1},
+		[4]float32{0.5, 0.5, 0.5, 1},
+	)
+	h := newHarness(t, widget.Column().Rigid(widget.NewFixedSize(100, 40, b)), 120, 80)
+	at := h.find(b)
+	pixel := func() [4]uint8 {
+		o := h.img.PixOffset(int(at.X), int(at.Y))
+		return [4]uint8(h.img.Pix[o : o+4])
+	}
+	tests := []struct {
+		name  string
+		input func()
+		want  [4]uint8
+	}{
+		{"normal", func() {}, [4]uint8{0, 0, 255, 255}},
+		{"hover", func() { h.move(at) }, [4]uint8{0, 255, 0, 255}},
+		{"pressed", func() { h.press(at) }, [4]uint8{255, 0, 0, 255}},
+		{"released", func() { h.release(at) }, [4]uint8{0, 255, 0, 255}},
+		{"left", func() { h.move(interfaces.Point{X: 110, Y: 70}) }, [4]uint8{0, 0, 255, 255}},
+		{"disabled", func() { b.Disabled(true); h.frame() }, [4]uint8{128, 128, 128, 255}},
+	}
+	for _, tt := range tests {
+		tt.input()
+		if got := pixel(); got != tt.want {
+			t.Errorf("%s: button drawn %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestButtonRepaintsOnlyItself(t *testing.T) {
+	font := loadFont(t)
+	b := widget.Button(widget.Label(font, "OK"))
+	h := newHarness(t, widget.Column().
+		Rigid(widget.NewFixedSize(80, 30, b)).
+		Rigid(widget.Label(font, "unchanged")), 200, 100)
+	before := h.img
+	h.root.Profile(true)
+	h.frame()
+	after := h.move(h.find(b))
+	if headless.Diff(before, after, 0) == 0 {
+		t.Error("hovering did not change the button")
+	}
+	var repainted bool
+	for _, wp := range h.root.ProfileReport().Widgets {
+		if wp.Widget == b {
+			repainted = true
+		}
+		if wp.Rect.Y >= 30 {
+			t.Errorf("%s below the button repainted on hover", wp.Type)
+		}
+	}
+	if !repainted {
+		t.Error("the button was not repainted on hover")
+	}
+}
@@ -0,0 +1,220 @@
+package widget
+
+import (
+	"math"
+	"slices"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/render"
+	"github.com/mleku/goo/pkg/theme"
+	"lol.mleku.dev/chk"
+)
+
+// CacheLayerWidget renders its child into a texture and draws the texture
+// in its place until something in the child changes, for content that is
+// costly to paint but rarely changes, such as documents and charts. The
+// child is drawn clipped to the box and rendered again when it repaints or
+// is laid out again. Moving the layer, such as scrolling it, draws the same
+// texture at the new position.
+type CacheLayerWidget struct {
+	Base
+	child Widget
+	layer childLayer
+}
+
+// childLayer renders a widget's child into a texture relative to the box,
+// again only once the child changed or is drawn at another pixel density
+// or theme
+type childLayer struct {
+	texture *render.Texture
+	list    *render.DrawList
+	// dirty is set when the texture no longer shows the child
+	dirty bool
+	// at is where the box was last drawn, and scale and theme the pixel
+	// density and theme the child was rendered in
+	at    Point
+	scale float32
+	theme *theme.Theme
+	// hits are the regions the child registered when it was rendered,
+	// relative to the box, registered again whenever the texture is drawn
+	hits []interfaces.HitRegion
+	// painted are the widgets painted into the texture, whose paint boxes
+	// follow the box when it moves
+	painted []paintTracker
+}
+
+// layered is implemented by widgets drawing their child through a layer,
+// returning the layer
+type layered interface {
+	cache() *childLayer
+}
+
+// CacheLayer creates a new layer caching the rendering of the child
+func CacheLayer(child Widget) *CacheLayerWidget {
+	c := &CacheLayerWidget{child: child, layer: newChildLayer()}
+	adopt(c, child)
+	return c
+}
+
+// newChildLayer creates a layer that renders the child when first drawn
+func newChildLayer() childLayer {
+	return childLayer{
+		texture: render.NewTexture(0, 0, render.FormatRGBA, nil),
+		list:    render.NewDrawList(),
+		dirty:   true,
+	}
+}
+
+// Refresh renders the child again the next time the layer is drawn, for
+// children that change without repainting themselves
+func (c *CacheLayerWidget) Refresh() {
+	c.layer.dirty = true
+	c.MarkNeedsPaint()
+}
+
+// GetConstraints returns the child's constraints
+func (c *CacheLayerWidget) GetConstraints() Constraints {
+	if c.child == nil {
+		return NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return c.child.GetConstraints()
+}
+
+// Measure returns the size the child measures
+func (c *CacheLayerWidget) Measure(constraints Constraints) Size {
+	return measure(c.child, constraints)
+}
+
+// Layout implements the Widget interface for CacheLayerWidget; the child is
+// laid out in the widget's box
+func (c *CacheLayerWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !c.NeedsLayout(ctx, constraints) {
+		return c.CachedSize(), nil
+	}
+	c.layer.dirty = true
+	if size, err = layoutInset(ctx, c.child, Insets{}, constraints); chk.E(err) {
+		return
+	}
+	c.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for CacheLayerWidget
+func (c *CacheLayerWidget) Paint(ctx *Context, box *Box) (err error) {
+	t, width, height, err := c.layer.draw(ctx, box, c.child)
+	if t == nil || chk.E(err) {
+		return
+	}
+	ctx.DrawList.Image(t, box.Position.X, box.Position.Y, width, height, 0, 0, 1, 1, [4]float32{1, 1, 1, 1})
+	return
+}
+
+// draw renders the child into the texture unless it still shows the child
+// in the box, and registers the regions the child registered. It returns
+// the texture and its size in the widgets' coordinates, or no texture when
+// there is nothing to draw.
+func (l *childLayer) draw(ctx *Context, box *Box, child Widget) (t *render.Texture, width, height float32, err error) {
+	if child == nil || box.Size.Width <= 0 || box.Size.Height <= 0 {
+		return
+	}
+	scale := ctx.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+	th := themeOf(ctx)
+	if l.dirty || scale != l.scale || th != l.theme {
+		if err = l.render(ctx, box, child, scale); chk.E(err) {
+			return
+		}
+		l.theme = th
+	} else if box.Position != l.at {
+		l.move(box.Position.X-l.at.X, box.Position.Y-l.at.Y)
+	}
+	for _, h := range l.hits {
+		r := h.Rect
+		r.X, r.Y = r.X+box.Position.X, r.Y+box.Position.Y
+		ctx.Hits.Register(h.Target, r)
+	}
+	t = l.texture
+	return t, float32(t.Width) / scale, float32(t.Height) / scale, nil
+}
+
+// render paints the whole child into the texture at a pixel density,
+// keeping the regions it registers
+func (l *childLayer) render(ctx *Context, box *Box, child Widget, scale float32) (err error) {
+	// Anything that invalidates the child while it paints, such as an
+	// animation, renders it again next frame
+	l.dirty = false
+	l.at, l.scale = box.Position, scale
+	t := l.texture
+	t.Width = int(math.Ceil(float64(box.Size.Width * scale)))
+	t.Height = int(math.Ceil(float64(box.Size.Height * scale)))
+	hits := &interfaces.Hits{}
+	layer := *ctx
+	layer.DrawList, layer.Hits = l.list, hits
+	layer.Clip = box.Rect()
+	l.list.PushTransform(render.Translate(-box.Position.X, -box.Position.Y))
+	l.list.PushClip(box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height)
+	err = paintChild(&layer, child, box)
+	l.list.PopClip()
+	l.list.PopTransform()
+	if err != nil {
+		l.list.Reset()
+		return
+	}
+	l.hits, l.painted = l.hits[:0], l.painted[:0]
+	for _, h := range hits.Regions() {
+		h.Rect.X, h.Rect.Y = h.Rect.X-box.Position.X, h.Rect.Y-box.Position.Y
+		l.hits = append(l.hits, h)
+		// Every widget painted registers a region, some of them several
+		if t, ok := h.Target.(paintTracker); ok && !slices.Contains(l.painted, t) {
+			l.painted = append(l.painted, t)
+		}
+	}
+	ctx.DrawList.RenderTexture(t, l.list, float32(t.Width)/scale, float32(t.Height)/scale)
+	return
+}
+
+// move follows the box by an offset without rendering the child again,
+// moving the boxes the child's widgets were painted in along with it so
+// they repaint and take input where they are drawn
+func (l *childLayer) move(dx, dy float32) {
+	l.at.X, l.at.Y = l.at.X+dx, l.at.Y+dy
+	for _, t := range l.painted {
+		box := *t.lastPaintBox()
+		box.Position.X, box.Position.Y = box.Position.X+dx, box.Position.Y+dy
+		t.setPaintBox(&box)
+		// A layer inside was drawn into this texture where it now is
+		if n, ok := t.(layered); ok {
+			inner := n.cache()
+			inner.at.X, inner.at.Y = inner.at.X+dx, inner.at.Y+dy
+		}
+	}
+}
+
+// cache implements layered
+func (c *CacheLayerWidget) cache() *childLayer {
+	return &c.layer
+}
+
+// HandleEvent implements the Widget interface for CacheLayerWidget
+func (c *CacheLayerWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if c.child == nil {
+		return false
+	}
+	return routeEvent(ctx, c.child, box, ev)
+}
+
+// addDamage implements damageSink, rendering the child again before
+// passing the region on
+func (c *CacheLayerWidget) addDamage(rect Rect) {
+	c.layer.dirty = true
+	c.invalidate(rect)
+}
+
+// dispose frees the GPU copy of the texture, rendering the child again when
+// it is next drawn
+func (l *childLayer) dispose() {
+	l.texture.Dispose()
+	l.dirty = true
+}
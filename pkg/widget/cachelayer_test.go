@@ -0,0 +1,57 @@
+package widget_test
+
+import (
+	"testing"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/widget"
+)
+
+// repainted reports whether a widget was painted in the last frame
+func repainted(h *harness, w widget.Widget) bool {
+	for _, wp := range h.root.ProfileReport().Widgets {
+		if wp.Widget == w {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCacheLayerScrolls(t *testing.T) {
+	font := loadFont(t)
+	b := widget.Button(widget.Label(font, "cached"))
+	content := widget.Column().
+		Rigid(widget.Spacer(widget.NewRigidConstraints(160, 100))).
+		Rigid(widget.NewFixedSize(160, 30, b)).
+		Rigid(widget.Spacer(widget.NewRigidConstraints(160, 300)))
+	s := widget.Scroll(widget.CacheLayer(widget.NewFixedSize(160, 430, content))).Kinetic(false)
+	h := newHarness(t, s, 200, 200)
+	h.root.Profile(true)
+	at := h.find(b)
+
+	// Scrolling draws the texture further up without painting the button
+	h.send(interfaces.ScrollEvent{Position: at, Offset: interfaces.Point{Y: -1}})
+	moved := s.Offset().Y
+	if moved <= 0 {
+		t.Fatal("the view did not scroll")
+	}
+	if repainted(h, b) {
+		t.Error("scrolling painted the cached button again")
+	}
+	now := h.find(b)
+	if now.Y != at.Y-moved {
+		t.Errorf("the button is hit at %g after scrolling by %g from %g, want %g", now.Y, moved, at.Y, at.Y-moved)
+	}
+
+	// The button repaints where it was moved to when hovered
+	h.move(now)
+	if !repainted(h, b) {
+		t.Error("hovering the moved button did not paint it again")
+	}
+	var clicked bool
+	b.OnClick(func() { clicked = true })
+	h.click(now)
+	if !clicked {
+		t.Error("the moved button did not take the click")
+	}
+}
@@ -0,0 +1,271 @@
+package widget
+
+import (
+	"time"
+
+	"github.com/mleku/goo/pkg/render"
+)
+
+// CanvasWidget hands a painter to user code each time it is drawn, for
+// custom visualizations drawn with paths, lines, curves, fills, strokes,
+// transforms and clipping instead of raw GL. Drawing is confined to the
+// canvas's box and measured from its top left corner.
+type CanvasWidget struct {
+	Base
+	paint       func(p *Painter)
+	constraints Constraints
+	// animate repaints the canvas on every frame
+	animate bool
+	painter Painter
+}
+
+// Canvas creates a new canvas drawn by the paint function, which is called
+// whenever the canvas is repainted. If no constraints are provided, uses
+// default flexible constraints (0, 0, 1e9, 1e9).
+func Canvas(paint func(p *Painter), constraints ...Constraints) *CanvasWidget {
+	var c Constraints
+	if len(constraints) > 0 {
+		c = constraints[0]
+	} else {
+		c = NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return &CanvasWidget{paint: paint, constraints: c}
+}
+
+// SetPaint replaces the function drawing the canvas
+func (c *CanvasWidget) SetPaint(paint func(p *Painter)) {
+	c.paint = paint
+	c.MarkNeedsPaint()
+}
+
+// Animate repaints the canvas on every frame while set, for drawings that
+// change with time, and returns the canvas for chaining. Otherwise call
+// MarkNeedsPaint when what is drawn changes.
+func (c *CanvasWidget) Animate(animate bool) *CanvasWidget {
+	c.animate = animate
+	c.MarkNeedsPaint()
+	return c
+}
+
+// GetConstraints returns the canvas's constraints
+func (c *CanvasWidget) GetConstraints() Constraints {
+	return c.constraints
+}
+
+// Measure returns the minimum size of the canvas
+func (c *CanvasWidget) Measure(constraints Constraints) Size {
+	return minSize(c.GetConstraints())
+}
+
+// Layout implements the Widget interface for CanvasWidget; canvases take all
+// the space offered
+func (c *CanvasWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	c.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for CanvasWidget
+func (c *CanvasWidget) Paint(ctx *Context, box *Box) (err error) {
+	if c.animate {
+		ctx.Clock.Request()
+		c.MarkNeedsPaint()
+	}
+	if c.paint == nil || box.Size.Width <= 0 || box.Size.Height <= 0 {
+		return
+	}
+	list := ctx.DrawList
+	list.PushClip(box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height)
+	list.PushTransform(render.Translate(box.Position.X, box.Position.Y))
+	p := &c.painter
+	p.begin(list, box.Size, frameTime(ctx))
+	c.paint(p)
+	p.end()
+	list.PopTransform()
+	list.PopClip()
+	return
+}
+
+// HandleEvent implements the Widget interface for CanvasWidget; canvases
+// ignore input
+func (c *CanvasWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	return false
+}
+
+// Painter draws into a canvas with its origin at the canvas's top left
+// corner. Transforms, clips and opacity set on it apply to what is drawn
+// after them, until the Restore matching the Save before them or the end of
+// the paint function.
+type Painter struct {
+	list *render.DrawList
+	size Size
+	now  time.Time
+	// current counts the transforms, clips and opacities pushed since the
+	// last Save, and saved those counted before each Save still in force
+	current painterState
+	saved   []painterState
+	// scratch is reused to draw the shapes built from paths
+	scratch render.Path
+}
+
+// painterState counts what a painter has pushed onto its draw list
+type painterState struct {
+	transforms, clips, alphas int
+}
+
+// begin starts painting a canvas of a size into the list
+func (p *Painter) begin(list *render.DrawList, size Size, now time.Time) {
+	p.list, p.size, p.now = list, size, now
+	p.current = painterState{}
+	p.saved = p.saved[:0]
+}
+
+// end pops everything the paint function left pushed
+func (p *Painter) end() {
+	for len(p.saved) > 0 {
+		p.Restore()
+	}
+	p.pop(p.current)
+	p.current = painterState{}
+}
+
+// Size returns the size of the canvas
+func (p *Painter) Size() Size {
+	return p.size
+}
+
+// Now returns the time of the frame being drawn, for animating drawings
+func (p *Painter) Now() time.Time {
+	return p.now
+}
+
+// DrawList returns the draw list the painter adds to, under the painter's
+// transforms and clips, for drawing with its primitives directly
+func (p *Painter) DrawList() *render.DrawList {
+	return p.list
+}
+
+// Save remembers the current transform, clip and opacity so the matching
+// Restore can return to them
+func (p *Painter) Save() {
+	p.saved = append(p.saved, p.current)
+	p.current = painterState{}
+}
+
+// Restore returns to the transform, clip and opacity in force at the last
+// Save, and does nothing without one
+func (p *Painter) Restore() {
+	n := len(p.saved)
+	if n == 0 {
+		return
+	}
+	p.pop(p.current)
+	p.current = p.saved[n-1]
+	p.saved = p.saved[:n-1]
+}
+
+// pop removes the transforms, clips and opacities counted in a state from
+// the draw list
+func (p *Painter) pop(s painterState) {
+	for range s.transforms {
+		p.list.PopTransform()
+	}
+	for range s.clips {
+		p.list.PopClip()
+	}
+	for range s.alphas {
+		p.list.PopAlpha()
+	}
+}
+
+// Transform transforms what is drawn after it by m, inside the transforms
+// already in force
+func (p *Painter) Transform(m render.Matrix) {
+	p.list.PushTransform(m)
+	p.current.transforms++
+}
+
+// Translate moves what is drawn after it by (x, y)
+func (p *Painter) Translate(x, y float32) {
+	p.Transform(render.Translate(x, y))
+}
+
+// Scale scales what is drawn after it from the origin by sx horizontally
+// and sy vertically
+func (p *Painter) Scale(sx, sy float32) {
+	p.Transform(render.Scale(sx, sy))
+}
+
+// Rotate turns what is drawn after it about the origin by an angle in
+// radians, clockwise on screen
+func (p *Painter) Rotate(angle float32) {
+	p.Transform(render.Rotate(angle))
+}
+
+// Clip confines what is drawn after it to a rect, inside any clip already
+// in force. Under a rotation the clip covers the rect's bounding rect.
+func (p *Painter) Clip(x, y, width, height float32) {
+	p.list.PushClip(x, y, width, height)
+	p.current.clips++
+}
+
+// ClipRound confines what is drawn after it to a rect with corners rounded
+// to radius, like Clip
+func (p *Painter) ClipRound(x, y, width, height, radius float32) {
+	p.list.PushRoundClip(x, y, width, height, radius)
+	p.current.clips++
+}
+
+// ClipEllipse confines what is drawn after it to the ellipse touching the
+// edges of a rect, like Clip
+func (p *Painter) ClipEllipse(x, y, width, height float32) {
+	p.list.PushEllipseClip(x, y, width, height)
+	p.current.clips++
+}
+
+// Alpha multiplies the opacity of what is drawn after it by alpha
+func (p *Painter) Alpha(alpha float32) {
+	p.list.PushAlpha(alpha)
+	p.current.alphas++
+}
+
+// Fill fills each subpath of a path with the brush
+func (p *Painter) Fill(path *render.Path, brush render.Brush) {
+	p.list.FillPath(path, brush)
+}
+
+// Stroke draws lines width wide along the middle of each subpath of a path,
+// painted with the brush
+func (p *Painter) Stroke(path *render.Path, width float32, brush render.Brush) {
+	p.list.StrokePath(path, width, brush)
+}
+
+// Line draws a straight line width wide between two points
+func (p *Painter) Line(x0, y0, x1, y1, width float32, brush render.Brush) {
+	p.Stroke(p.scratch.Reset().MoveTo(x0, y0).LineTo(x1, y1), width, brush)
+}
+
+// FillRect fills a rect with the brush
+func (p *Painter) FillRect(x, y, width, height float32, brush render.Brush) {
+	p.list.FillRoundRect(x, y, width, height, 0, brush)
+}
+
+// StrokeRect draws the outline of a rect, width wide along its edges
+func (p *Painter) StrokeRect(x, y, width, height, lineWidth float32, brush render.Brush) {
+	p.Stroke(p.scratch.Reset().Rect(x, y, width, height), lineWidth, brush)
+}
+
+// FillCircle fills a circle with the brush
+func (p *Painter) FillCircle(cx, cy, radius float32, brush render.Brush) {
+	p.list.FillEllipse(cx, cy, radius, radius, brush)
+}
+
+// StrokeCircle draws the outline of a circle, width wide along its edge
+func (p *Painter) StrokeCircle(cx, cy, radius, width float32, brush render.Brush) {
+	p.Stroke(p.scratch.Reset().Circle(cx, cy, radius), width, brush)
+}
+
+// Image draws a texture stretched over a rect, tinted by color
+func (p *Painter) Image(texture *render.Texture, x, y, width, height float32, tint [4]float32) {
+	p.list.Image(texture, x, y, width, height, 0, 0, 1, 1, tint)
+}
@@ -0,0 +1,364 @@
+package widget
+
+import (
+	"math"
+
+	"github.com/mleku/goo/pkg/icons"
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/text"
+	"lol.mleku.dev/chk"
+)
+
+const (
+	// cardPadding is the default space between a card's edge and its child
+	cardPadding = 12
+	// cardHeaderPadding is the space around the title of a card
+	cardHeaderPadding = 8
+	// cardElevation is how far cards are raised above the window by default
+	cardElevation = 1
+)
+
+// CardWidget is a themed surface grouping its child, such as the fields of
+// a form or a tile of a dashboard, with rounded corners, padding around the
+// child and a shadow when raised. A card can have a title bar, and a
+// collapsible card hides its child when the title bar is clicked, or when
+// enter or space is pressed while the card has focus.
+type CardWidget struct {
+	Base
+	child    Widget
+	font     *text.Font
+	size     float32
+	title    string
+	padding  float32
+	level    float32
+	radius   float32
+	rounded  bool
+	hovered  bool
+	onToggle func(collapsed bool)
+	// collapsible lets the title bar hide the child, which is hidden while
+	// collapsed is set
+	collapsible bool
+	collapsed   bool
+}
+
+// Card creates a new card around the child, raised one level above the
+// window with its corners rounded to the theme's large radius
+func Card(child Widget) *CardWidget {
+	c := &CardWidget{child: child, size: 14, padding: cardPadding, level: cardElevation}
+	adopt(c, child)
+	return c
+}
+
+// Panel creates a new flat card around the child, outlined rather than
+// raised, for grouping within a surface
+func Panel(child Widget) *CardWidget {
+	return Card(child).Elevation(0)
+}
+
+// Title gives the card a title bar showing the title in the given font and
+// returns the card for chaining. An empty title removes the bar.
+func (c *CardWidget) Title(font *text.Font, title string) *CardWidget {
+	c.font, c.title = font, title
+	c.MarkNeedsLayout()
+	return c
+}
+
+// SetTitle replaces the title shown in the title bar
+func (c *CardWidget) SetTitle(title string) {
+	if title == c.title {
+		return
+	}
+	c.title = title
+	c.MarkNeedsLayout()
+}
+
+// Size sets the pixel size of the title and returns the card for chaining
+func (c *CardWidget) Size(size float32) *CardWidget {
+	c.size = size
+	c.MarkNeedsLayout()
+	return c
+}
+
+// Padding sets the space between the edge and the child and returns the
+// card for chaining
+func (c *CardWidget) Padding(padding float32) *CardWidget {
+	c.padding = padding
+	c.MarkNeedsLayout()
+	return c
+}
+
+// Radius rounds the corners, replacing the theme's radius, and returns the
+// card for chaining
+func (c *CardWidget) Radius(radius float32) *CardWidget {
+	c.radius, c.rounded = radius, true
+	c.MarkNeedsPaint()
+	return c
+}
+
+// Elevation sets how many levels the card is raised above the window, zero
+// for a flat card, and returns the card for chaining
+func (c *CardWidget) Elevation(level float32) *CardWidget {
+	c.MarkNeedsPaint()
+	c.level = max(level, 0)
+	c.MarkNeedsPaint()
+	return c
+}
+
+// Collapsible sets whether clicking the title bar hides and shows the child
+// and returns the card for chaining. Cards without a title cannot collapse.
+func (c *CardWidget) Collapsible(collapsible bool) *CardWidget {
+	c.collapsible = collapsible
+	if !collapsible {
+		c.SetCollapsed(false)
+	}
+	c.MarkNeedsPaint()
+	return c
+}
+
+// OnToggle sets the callback invoked with the new state when the user
+// collapses or expands the card, and returns the card for chaining
+func (c *CardWidget) OnToggle(fn func(collapsed bool)) *CardWidget {
+	c.onToggle = fn
+	return c
+}
+
+// SetCollapsed hides or shows the child
+func (c *CardWidget) SetCollapsed(collapsed bool) {
+	if collapsed == c.collapsed {
+		return
+	}
+	c.collapsed = collapsed
+	c.MarkNeedsLayout()
+}
+
+// IsCollapsed reports whether the child is hidden
+func (c *CardWidget) IsCollapsed() bool {
+	return c.collapsed
+}
+
+// MarkNeedsPaint schedules the card to be repainted along with its shadow
+func (c *CardWidget) MarkNeedsPaint() {
+	c.invalidate(c.paintBounds(&c.paintBox))
+}
+
+// GetConstraints returns the child's constraints enlarged by the padding
+// and the title bar, or the title bar alone while collapsed
+func (c *CardWidget) GetConstraints() Constraints {
+	header := c.headerHeight()
+	if c.hidden() {
+		return NewFlexConstraints(header, header, 1e9, header)
+	}
+	inner := NewFlexConstraints(0, 0, 1e9, 1e9)
+	if c.child != nil {
+		inner = c.child.GetConstraints()
+	}
+	return c.insets().grow(inner)
+}
+
+// Measure returns the size the child measures inside the padding and below
+// the title bar
+func (c *CardWidget) Measure(constraints Constraints) Size {
+	if c.hidden() {
+		face := c.font.Face(c.size)
+		return Size{Width: face.Measure(c.title) + 2*cardHeaderPadding + c.headerHeight(), Height: c.headerHeight()}
+	}
+	in := c.insets()
+	size := in.around(measure(c.child, in.shrink(constraints)))
+	if c.title != "" {
+		size.Width = max(size.Width, c.font.Face(c.size).Measure(c.title)+2*cardHeaderPadding)
+	}
+	return size
+}
+
+// Layout implements the Widget interface for CardWidget; the child is laid
+// out inside the padding below the title bar
+func (c *CardWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !c.NeedsLayout(ctx, constraints) {
+		return c.CachedSize(), nil
+	}
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	if !c.hidden() {
+		if size, err = layoutInset(ctx, c.child, c.insets(), constraints); chk.E(err) {
+			return
+		}
+	}
+	c.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for CardWidget
+func (c *CardWidget) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	radius := th.Radius.Large
+	if c.rounded {
+		radius = c.radius
+	}
+	if c.level > 0 {
+		paintElevated(ctx, box, c.level, radius)
+	}
+	fillSkinned(ctx, box, th.Skins.Panel, radius, th.Border, th.Surface)
+	if c.title != "" {
+		c.paintHeader(ctx, box, radius)
+	}
+	if c.hidden() || c.child == nil {
+		return
+	}
+	return paintChild(ctx, c.child, c.insets().box(box, c.child.GetConstraints()))
+}
+
+// paintHeader draws the title bar, with an arrow showing whether a
+// collapsible card is expanded
+func (c *CardWidget) paintHeader(ctx *Context, box *Box, radius float32) {
+	th := themeOf(ctx)
+	list := ctx.DrawList
+	face := c.font.Face(c.size)
+	header := c.headerHeight()
+	x, y, w := box.Position.X, box.Position.Y, box.Size.Width
+	if c.collapsible && c.hovered {
+		list.RoundRect(x+1, y+1, w-2, header-2, max(radius-1, 0), th.SurfaceHover)
+	}
+	if hasFocus(c) {
+		list.RoundRectStroke(x+1, y+1, w-2, header-2, max(radius-1, 0), 1, th.Primary)
+	}
+	if !c.hidden() {
+		list.Rect(x+1, y+header-1, w-2, 1, th.Border)
+	}
+	list.PushClip(x, y, w, header)
+	defer list.PopClip()
+	tx := x + cardHeaderPadding
+	arrow := float32(math.Round(float64(face.LineHeight())))
+	ax := x + w - cardHeaderPadding - arrow
+	if ctx.RTL {
+		tx = x + w - cardHeaderPadding - face.Measure(c.title)
+		ax = x + cardHeaderPadding
+	}
+	baseline := y + (header-face.LineHeight())/2 + face.Ascent()
+	face.Draw(list, tx, baseline, c.title, th.Text)
+	if c.collapsible {
+		name := "chevron-down"
+		if c.collapsed {
+			name = "chevron-right"
+			if ctx.RTL {
+				name = "chevron-left"
+			}
+		}
+		icons.Draw(list, name, ax, y+(header-arrow)/2, arrow, ctx.Scale, th.TextMuted)
+	}
+}
+
+// paintBounds implements overflowing, returning the box along with the
+// card's shadow
+func (c *CardWidget) paintBounds(box *Box) Rect {
+	dy, blur := elevation(c.level)
+	return shadowBounds(box, 0, dy, blur)
+}
+
+// CursorAt implements CursorProvider, showing the hand over the title bar
+// of a collapsible card
+func (c *CardWidget) CursorAt(p Point) Cursor {
+	if c.onHeader(&c.paintBox, p) {
+		return interfaces.CursorHand
+	}
+	return interfaces.CursorDefault
+}
+
+// navigable lets gamepad navigation reach a collapsible card
+func (c *CardWidget) navigable() bool {
+	return c.collapsible && c.title != ""
+}
+
+// Accessibility implements Accessible, naming the group by its title
+func (c *CardWidget) Accessibility() AccessNode {
+	node := AccessNode{Role: interfaces.RoleGroup, Name: c.title}
+	if c.navigable() {
+		node.State = interfaces.StateExpandable
+		if !c.collapsed {
+			node.State |= interfaces.StateExpanded
+		}
+		node.Actions = []AccessAction{interfaces.AccessClick}
+	}
+	return node
+}
+
+// AccessAction implements AccessActor, collapsing or expanding the card
+func (c *CardWidget) AccessAction(ctx *Context, action AccessAction, value string) bool {
+	if action != interfaces.AccessClick || !c.navigable() {
+		return false
+	}
+	c.toggle()
+	return true
+}
+
+// HandleEvent implements the Widget interface for CardWidget
+func (c *CardWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		c.setHovered(c.onHeader(box, e.Position))
+	case interfaces.CursorLeaveEvent:
+		c.setHovered(false)
+	case interfaces.MouseButtonEvent:
+		if e.Button == interfaces.MouseButtonLeft && e.Action == interfaces.ActionPress && c.onHeader(box, e.Position) {
+			requestFocus(c)
+			c.toggle()
+			return true
+		}
+	case interfaces.KeyEvent:
+		if hasFocus(c) && (e.Key == interfaces.KeyEnter || e.Key == interfaces.KeySpace) {
+			if e.Action == interfaces.ActionPress {
+				c.toggle()
+			}
+			return true
+		}
+	case interfaces.CharEvent:
+		// Swallow the space typed along with the key press
+		if hasFocus(c) && e.Char == ' ' {
+			return true
+		}
+	}
+	if c.hidden() || c.child == nil {
+		return false
+	}
+	return routeEvent(ctx, c.child, c.insets().box(box, c.child.GetConstraints()), ev)
+}
+
+// toggle collapses or expands the card for the user
+func (c *CardWidget) toggle() {
+	c.SetCollapsed(!c.collapsed)
+	if c.onToggle != nil {
+		c.onToggle(c.collapsed)
+	}
+}
+
+// onHeader reports whether a point lies on the title bar of a collapsible card
+func (c *CardWidget) onHeader(box *Box, p Point) bool {
+	return c.navigable() && box.Contains(p) && p.Y < box.Position.Y+c.headerHeight()
+}
+
+// setHovered updates whether the cursor is over the title bar, repainting
+// when it changes
+func (c *CardWidget) setHovered(hovered bool) {
+	if hovered != c.hovered {
+		c.hovered = hovered
+		c.MarkNeedsPaint()
+	}
+}
+
+// hidden reports whether only the title bar is shown
+func (c *CardWidget) hidden() bool {
+	return c.collapsed && c.title != ""
+}
+
+// headerHeight returns the height of the title bar, zero without a title
+func (c *CardWidget) headerHeight() float32 {
+	if c.title == "" {
+		return 0
+	}
+	return float32(math.Ceil(float64(c.font.Face(c.size).LineHeight()))) + 2*cardHeaderPadding
+}
+
+// insets returns the space around the child, below the title bar
+func (c *CardWidget) insets() Insets {
+	in := UniformInsets(c.padding)
+	in.Top += c.headerHeight()
+	return in
+}
@@ -0,0 +1,179 @@
+package widget
+
+import (
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/sound"
+	"github.com/mleku/goo/pkg/text"
+)
+
+// CheckboxWidget is a box that toggles a tick when clicked or when space is
+// pressed while it has focus, followed by a text label
+type CheckboxWidget struct {
+	Base
+	toggle  toggle
+	checked bool
+	// value is where the state is stored, the checked field unless bound
+	value    *bool
+	onChange func(checked bool)
+}
+
+// Checkbox creates a new unchecked checkbox with a label drawn in the given
+// font. The label defaults to 14 pixel text and may be empty.
+func Checkbox(font *text.Font, label string) *CheckboxWidget {
+	c := &CheckboxWidget{
+		toggle: toggle{font: font, size: 14, label: label},
+	}
+	c.value = &c.checked
+	return c
+}
+
+// Size sets the pixel size of the label text, which also scales the box, and
+// returns the checkbox for chaining
+func (c *CheckboxWidget) Size(size float32) *CheckboxWidget {
+	c.toggle.size = size
+	c.MarkNeedsLayout()
+	return c
+}
+
+// Checked sets the initial state and returns the checkbox for chaining
+func (c *CheckboxWidget) Checked(checked bool) *CheckboxWidget {
+	*c.value = checked
+	c.MarkNeedsPaint()
+	return c
+}
+
+// Bind stores the state in value instead of the checkbox and returns the
+// checkbox for chaining. Toggling writes through to value, and changes made
+// to value elsewhere show the next time the checkbox is repainted.
+func (c *CheckboxWidget) Bind(value *bool) *CheckboxWidget {
+	c.value = value
+	c.MarkNeedsPaint()
+	return c
+}
+
+// OnChange sets the callback invoked with the new state when the user
+// toggles the checkbox and returns the checkbox for chaining
+func (c *CheckboxWidget) OnChange(fn func(checked bool)) *CheckboxWidget {
+	c.onChange = fn
+	return c
+}
+
+// Disabled sets whether the checkbox ignores input and returns the checkbox for chaining
+func (c *CheckboxWidget) Disabled(disabled bool) *CheckboxWidget {
+	c.toggle.disabled = disabled
+	if disabled {
+		c.toggle.pressed = false
+	}
+	c.MarkNeedsPaint()
+	return c
+}
+
+// SetChecked changes the state without invoking the change callback
+func (c *CheckboxWidget) SetChecked(checked bool) {
+	if checked == *c.value {
+		return
+	}
+	*c.value = checked
+	c.MarkNeedsPaint()
+}
+
+// IsChecked reports whether the checkbox is ticked
+func (c *CheckboxWidget) IsChecked() bool {
+	return *c.value
+}
+
+// GetConstraints returns a minimum size that fits the box and label
+func (c *CheckboxWidget) GetConstraints() Constraints {
+	return c.toggle.constraints()
+}
+
+// Measure returns the minimum size of the checkbox
+func (c *CheckboxWidget) Measure(constraints Constraints) Size {
+	return minSize(c.GetConstraints())
+}
+
+// Layout implements the Widget interface for CheckboxWidget; checkboxes take
+// all the space offered and draw at the start of it
+func (c *CheckboxWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	c.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for CheckboxWidget
+func (c *CheckboxWidget) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	checked := *c.value
+	b := c.toggle.paint(ctx, box, hasFocus(c))
+
+	border, fill, tick := th.Border, th.Field, th.Background
+	switch {
+	case c.toggle.disabled:
+		border, fill, tick = th.Disabled, th.Disabled, th.TextMuted
+	case checked:
+		border, fill = th.Primary, th.Primary
+	case c.toggle.pressed:
+		fill = th.SurfacePressed
+	case c.toggle.hovered:
+		fill = th.SurfaceHover
+	}
+	fillBordered(ctx, b, th.Radius.Small, border, fill)
+	if !checked {
+		return
+	}
+
+	// Draw the tick as two strokes meeting near the bottom left
+	x, y, s := b.Position.X, b.Position.Y, b.Size.Width
+	width := max(s/8, 1.5)
+	list := ctx.DrawList
+	list.Line(x+0.22*s, y+0.52*s, x+0.42*s, y+0.72*s, width, tick)
+	list.Line(x+0.4*s, y+0.72*s, x+0.78*s, y+0.3*s, width, tick)
+	return
+}
+
+// navigable lets gamepad navigation reach an enabled checkbox
+func (c *CheckboxWidget) navigable() bool {
+	return !c.toggle.disabled
+}
+
+// Accessibility implements Accessible
+func (c *CheckboxWidget) Accessibility() AccessNode {
+	node := AccessNode{
+		Role:    interfaces.RoleCheckBox,
+		Name:    c.toggle.label,
+		State:   interfaces.StateCheckable | disabledState(c.toggle.disabled),
+		Actions: []AccessAction{interfaces.AccessClick},
+	}
+	if *c.value {
+		node.State |= interfaces.StateChecked
+	}
+	return node
+}
+
+// AccessAction implements AccessActor, toggling the checkbox on a click
+func (c *CheckboxWidget) AccessAction(ctx *Context, action AccessAction, value string) bool {
+	if action != interfaces.AccessClick || c.toggle.disabled {
+		return false
+	}
+	c.toggled()
+	return true
+}
+
+// toggled flips the state on behalf of the user, invoking the change callback
+func (c *CheckboxWidget) toggled() {
+	*c.value = !*c.value
+	c.MarkNeedsPaint()
+	if c.onChange != nil {
+		c.onChange(*c.value)
+	}
+}
+
+// HandleEvent implements the Widget interface for CheckboxWidget
+func (c *CheckboxWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	handled, activated := c.toggle.handle(c, box, ev)
+	if activated {
+		sound.Play(themeOf(ctx).Sounds.Click)
+		c.toggled()
+	}
+	return
+}
@@ -0,0 +1,483 @@
+package widget
+
+import (
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/mleku/goo/pkg/icons"
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/text"
+	"lol.mleku.dev/chk"
+)
+
+const (
+	// chipPadding is the space between the ends of a chip and its contents,
+	// and chipGap the space between its icon, label and close button
+	chipPadding = 10
+	chipGap     = 6
+	// chipInputPadding is the space between the edge of a chip input and
+	// its chips, and chipInputSpacing the space between the chips
+	chipInputPadding = 4
+	chipInputSpacing = 4
+	// chipInputTyping is the least width of the text typed into a chip input
+	chipInputTyping = 80
+)
+
+// ChipWidget is a small rounded label, such as a tag, a filter or a
+// recipient, with an optional icon before the label and an optional button
+// after it that removes the chip. While it has focus enter or space clicks
+// it, and backspace or delete closes it.
+type ChipWidget struct {
+	Base
+	font     *text.Font
+	size     float32
+	label    string
+	iconName string
+	hovered  bool
+	// hotClose is set while the cursor is over the close button, and
+	// pressed while the mouse is held on the chip, or on the button when
+	// pressedClose is set
+	hotClose, pressed, pressedClose bool
+	onClick                         func()
+	onClose                         func()
+}
+
+// Chip creates a new chip showing the label in the given font at 13 pixels
+func Chip(font *text.Font, label string) *ChipWidget {
+	return &ChipWidget{font: font, size: 13, label: label}
+}
+
+// Size sets the pixel size of the label and returns the chip for chaining
+func (c *ChipWidget) Size(size float32) *ChipWidget {
+	c.size = size
+	c.MarkNeedsLayout()
+	return c
+}
+
+// Icon shows the built-in icon named before the label, none when empty, and
+// returns the chip for chaining
+func (c *ChipWidget) Icon(name string) *ChipWidget {
+	c.iconName = name
+	c.MarkNeedsLayout()
+	return c
+}
+
+// OnClick sets the callback invoked when the chip is clicked and returns
+// the chip for chaining
+func (c *ChipWidget) OnClick(fn func()) *ChipWidget {
+	c.onClick = fn
+	return c
+}
+
+// OnClose shows a close button after the label that invokes the callback,
+// which removes the chip from where it is shown, and returns the chip for
+// chaining. A nil callback hides the button.
+func (c *ChipWidget) OnClose(fn func()) *ChipWidget {
+	c.onClose = fn
+	c.MarkNeedsLayout()
+	return c
+}
+
+// SetText replaces the label
+func (c *ChipWidget) SetText(label string) {
+	if label == c.label {
+		return
+	}
+	c.label = label
+	c.MarkNeedsLayout()
+}
+
+// Text returns the label
+func (c *ChipWidget) Text() string {
+	return c.label
+}
+
+// GetConstraints returns the size that fits the icon, label and button
+func (c *ChipWidget) GetConstraints() Constraints {
+	s := c.natural()
+	return NewFlexConstraints(s.Width, s.Height, 1e9, s.Height)
+}
+
+// Measure returns the size that fits the icon, label and button
+func (c *ChipWidget) Measure(constraints Constraints) Size {
+	return c.natural()
+}
+
+// natural returns the size that fits the icon, label and button
+func (c *ChipWidget) natural() Size {
+	face := c.font.Face(c.size)
+	icon := c.iconSize()
+	width := face.Measure(c.label) + 2*chipPadding
+	if c.iconName != "" {
+		width += icon + chipGap
+	}
+	if c.onClose != nil {
+		width += icon + chipGap
+	}
+	return Size{Width: float32(math.Ceil(float64(width))), Height: c.height()}
+}
+
+// Layout implements the Widget interface for ChipWidget
+func (c *ChipWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	c.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for ChipWidget
+func (c *ChipWidget) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	list := ctx.DrawList
+	face := c.font.Face(c.size)
+	x, y, w, h := box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height
+	radius := h / 2
+	fill := th.Surface
+	switch {
+	case c.pressed && !c.pressedClose:
+		fill = th.SurfacePressed
+	case c.hovered && !c.hotClose:
+		fill = th.SurfaceHover
+	}
+	fillBordered(ctx, box, radius, th.Border, fill)
+	if hasFocus(c) {
+		list.RoundRectStroke(x, y, w, h, radius, 1, th.Primary)
+	}
+	list.PushClip(x, y, w, h)
+	defer list.PopClip()
+
+	icon := c.iconSize()
+	iy := y + (h-icon)/2
+	// Contents are placed from the start, mirrored in a right to left layout
+	at := func(offset, width float32) float32 {
+		if ctx.RTL {
+			return x + w - offset - width
+		}
+		return x + offset
+	}
+	offset := float32(chipPadding)
+	if c.iconName != "" {
+		icons.Draw(list, c.iconName, at(offset, icon), iy, icon, ctx.Scale, th.TextMuted)
+		offset += icon + chipGap
+	}
+	width := face.Measure(c.label)
+	face.Draw(list, at(offset, width), y+(h-face.LineHeight())/2+face.Ascent(), c.label, th.Text)
+	if c.onClose != nil {
+		close := c.closeRect(ctx.RTL, box)
+		if c.hotClose {
+			color := th.SurfaceHover
+			if c.pressed && c.pressedClose {
+				color = th.SurfacePressed
+			}
+			list.RoundRect(close.X-2, close.Y-2, close.Width+4, close.Height+4, (close.Width+4)/2, color)
+		}
+		icons.Draw(list, "close", close.X, close.Y, close.Width, ctx.Scale, th.TextMuted)
+	}
+	return
+}
+
+// CursorAt implements CursorProvider, showing the hand over clickable parts
+func (c *ChipWidget) CursorAt(p Point) Cursor {
+	if c.onClick != nil || c.hotClose {
+		return interfaces.CursorHand
+	}
+	return interfaces.CursorDefault
+}
+
+// navigable lets gamepad navigation reach chips that can be clicked or closed
+func (c *ChipWidget) navigable() bool {
+	return c.onClick != nil || c.onClose != nil
+}
+
+// Accessibility implements Accessible, naming the chip by its label
+func (c *ChipWidget) Accessibility() AccessNode {
+	node := AccessNode{Role: interfaces.RoleButton, Name: c.label}
+	if c.onClick != nil {
+		node.Actions = []AccessAction{interfaces.AccessClick}
+	}
+	return node
+}
+
+// AccessAction implements AccessActor, clicking the chip
+func (c *ChipWidget) AccessAction(ctx *Context, action AccessAction, value string) bool {
+	if action != interfaces.AccessClick || c.onClick == nil {
+		return false
+	}
+	c.onClick()
+	return true
+}
+
+// HandleEvent implements the Widget interface for ChipWidget
+func (c *ChipWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		c.setHovered(box.Contains(e.Position), c.onClose != nil && c.closeRect(ctx.RTL, box).Contains(e.Position))
+	case interfaces.CursorLeaveEvent:
+		c.setHovered(false, false)
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft {
+			return false
+		}
+		switch e.Action {
+		case interfaces.ActionPress:
+			// Presses are only delivered when the cursor is inside the box
+			onClose := c.onClose != nil && c.closeRect(ctx.RTL, box).Contains(e.Position)
+			if !onClose && c.onClick == nil {
+				return false
+			}
+			if c.navigable() {
+				requestFocus(c)
+			}
+			c.pressed, c.pressedClose = true, onClose
+			c.MarkNeedsPaint()
+			return true
+		case interfaces.ActionRelease:
+			// Releases are broadcast, so only act when released where pressed
+			if !c.pressed {
+				return false
+			}
+			onClose := c.pressedClose
+			c.pressed, c.pressedClose = false, false
+			c.MarkNeedsPaint()
+			switch {
+			case onClose && c.onClose != nil && c.closeRect(ctx.RTL, box).Contains(e.Position):
+				c.onClose()
+			case !onClose && c.onClick != nil && box.Contains(e.Position):
+				c.onClick()
+			}
+			return true
+		}
+	case interfaces.KeyEvent:
+		if !hasFocus(c) || e.Action == interfaces.ActionRelease {
+			return false
+		}
+		switch {
+		case (e.Key == interfaces.KeyEnter || e.Key == interfaces.KeySpace) && c.onClick != nil:
+			if e.Action == interfaces.ActionPress {
+				c.onClick()
+			}
+			return true
+		case (e.Key == interfaces.KeyBackspace || e.Key == interfaces.KeyDelete) && c.onClose != nil:
+			if e.Action == interfaces.ActionPress {
+				c.onClose()
+			}
+			return true
+		}
+	case interfaces.CharEvent:
+		// Swallow the space typed along with the key press
+		return hasFocus(c) && e.Char == ' ' && c.onClick != nil
+	}
+	return false
+}
+
+// closeRect returns the close button in window coordinates
+func (c *ChipWidget) closeRect(rtl bool, box *Box) Rect {
+	icon := c.iconSize()
+	x := box.Position.X + box.Size.Width - chipPadding - icon
+	if rtl {
+		x = box.Position.X + chipPadding
+	}
+	return Rect{X: x, Y: box.Position.Y + (box.Size.Height-icon)/2, Width: icon, Height: icon}
+}
+
+// setHovered updates whether the cursor is over the chip and its close
+// button, repainting when either changes
+func (c *ChipWidget) setHovered(hovered, hotClose bool) {
+	if hovered != c.hovered || hotClose != c.hotClose {
+		c.hovered, c.hotClose = hovered, hotClose
+		c.MarkNeedsPaint()
+	}
+}
+
+// iconSize returns the side of the icon and the close button
+func (c *ChipWidget) iconSize() float32 {
+	return float32(math.Round(float64(c.font.Face(c.size).LineHeight())))
+}
+
+// height returns the height of a chip, a line of text with space above and
+// below it
+func (c *ChipWidget) height() float32 {
+	return float32(math.Ceil(float64(c.font.Face(c.size).LineHeight()))) + 8
+}
+
+// ChipInputWidget is a field of chips, such as the tags of a post or the
+// recipients of a message, followed by a text input. Typing text and
+// pressing enter adds it as a chip, and backspace in the empty input
+// removes the last chip. Each chip has a close button removing it. Chips
+// wrap onto more lines when the field is too narrow for them.
+type ChipInputWidget struct {
+	Base
+	font     *text.Font
+	size     float32
+	tags     []string
+	chips    []*ChipWidget
+	wrap     *WrapWidget
+	input    *TextInputWidget
+	typing   *ConstrainedBoxWidget
+	onChange func(tags []string)
+}
+
+// ChipInput creates a new empty chip input drawn in the given font at 14
+// pixels, with chips at 13
+func ChipInput(font *text.Font) *ChipInputWidget {
+	c := &ChipInputWidget{font: font, size: 14, input: TextInput(font)}
+	c.input.frameless = true
+	c.input.keys = c.key
+	c.typing = ConstrainedBox(c.input).Min(chipInputTyping, -1)
+	c.wrap = Wrap().Spacing(chipInputSpacing, chipInputSpacing).CrossAlign(WrapCenter).Child(c.typing)
+	adopt(c, c.wrap)
+	return c
+}
+
+// Size sets the pixel size of the text typed and returns the input for
+// chaining. Chips are drawn a pixel smaller.
+func (c *ChipInputWidget) Size(size float32) *ChipInputWidget {
+	c.size = size
+	c.input.Size(size)
+	for _, chip := range c.chips {
+		chip.Size(size - 1)
+	}
+	c.MarkNeedsLayout()
+	return c
+}
+
+// Placeholder sets the hint shown while nothing is typed and the input is
+// unfocused, and returns the input for chaining
+func (c *ChipInputWidget) Placeholder(s string) *ChipInputWidget {
+	c.input.Placeholder(s)
+	return c
+}
+
+// OnChange sets the callback invoked with the tags when the user adds or
+// removes one, and returns the input for chaining
+func (c *ChipInputWidget) OnChange(fn func(tags []string)) *ChipInputWidget {
+	c.onChange = fn
+	return c
+}
+
+// SetTags replaces the chips with ones for the tags
+func (c *ChipInputWidget) SetTags(tags ...string) {
+	for _, chip := range c.chips {
+		c.wrap.Remove(chip)
+	}
+	c.tags, c.chips = nil, nil
+	for _, tag := range tags {
+		c.add(tag)
+	}
+}
+
+// Tags returns the tags of the chips in order
+func (c *ChipInputWidget) Tags() []string {
+	return slices.Clone(c.tags)
+}
+
+// Text returns the text typed and not yet added as a chip
+func (c *ChipInputWidget) Text() string {
+	return c.input.Text()
+}
+
+// add appends a chip for a tag, ignoring empty and repeated tags, and
+// reports whether it did
+func (c *ChipInputWidget) add(tag string) bool {
+	tag = strings.TrimSpace(tag)
+	if tag == "" || slices.Contains(c.tags, tag) {
+		return false
+	}
+	chip := Chip(c.font, tag).Size(c.size - 1)
+	chip.OnClose(func() { c.remove(chip) })
+	c.wrap.Insert(len(c.chips), chip)
+	c.tags = append(c.tags, tag)
+	c.chips = append(c.chips, chip)
+	return true
+}
+
+// remove takes a chip out for the user, giving focus back to the input
+func (c *ChipInputWidget) remove(chip *ChipWidget) {
+	i := slices.Index(c.chips, chip)
+	if i < 0 {
+		return
+	}
+	c.wrap.Remove(chip)
+	c.tags = slices.Delete(c.tags, i, i+1)
+	c.chips = slices.Delete(c.chips, i, i+1)
+	requestFocus(c.input)
+	c.changed()
+}
+
+// key adds the text typed as a chip on enter, and removes the last chip on
+// backspace in the empty input
+func (c *ChipInputWidget) key(e interfaces.KeyEvent) bool {
+	switch {
+	case e.Key == interfaces.KeyEnter:
+		if c.add(c.input.Text()) {
+			c.input.SetText("")
+			c.changed()
+		}
+		return true
+	case e.Key == interfaces.KeyBackspace && c.input.Text() == "" && len(c.chips) > 0:
+		c.remove(c.chips[len(c.chips)-1])
+		return true
+	}
+	return false
+}
+
+// changed notifies the change callback of the tags
+func (c *ChipInputWidget) changed() {
+	if c.onChange != nil {
+		c.onChange(c.Tags())
+	}
+}
+
+// GetConstraints returns the constraints of the chips inside the padding
+func (c *ChipInputWidget) GetConstraints() Constraints {
+	return UniformInsets(chipInputPadding).grow(c.wrap.GetConstraints())
+}
+
+// Measure returns the size of the lines of chips inside the padding
+func (c *ChipInputWidget) Measure(constraints Constraints) Size {
+	in := UniformInsets(chipInputPadding)
+	return in.around(c.wrap.Measure(in.shrink(constraints)))
+}
+
+// Layout implements the Widget interface for ChipInputWidget
+func (c *ChipInputWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !c.NeedsLayout(ctx, constraints) {
+		return c.CachedSize(), nil
+	}
+	if size, err = layoutInset(ctx, c.wrap, UniformInsets(chipInputPadding), constraints); chk.E(err) {
+		return
+	}
+	c.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for ChipInputWidget, drawing the
+// field around the chips
+func (c *ChipInputWidget) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	border := th.Border
+	if hasFocus(c.input) {
+		border = th.Primary
+	}
+	fillBordered(ctx, box, th.Radius.Small, border, th.Field)
+	return paintChild(ctx, c.wrap, UniformInsets(chipInputPadding).box(box, c.wrap.GetConstraints()))
+}
+
+// CursorAt implements CursorProvider, showing the text cursor over the field
+func (c *ChipInputWidget) CursorAt(p Point) Cursor {
+	return interfaces.CursorIBeam
+}
+
+// HandleEvent implements the Widget interface for ChipInputWidget; presses
+// on the field around the chips give focus to the input
+func (c *ChipInputWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if routeEvent(ctx, c.wrap, UniformInsets(chipInputPadding).box(box, c.wrap.GetConstraints()), ev) {
+		return true
+	}
+	if e, ok := ev.(interfaces.MouseButtonEvent); ok && e.Action == interfaces.ActionPress && e.Button == interfaces.MouseButtonLeft {
+		// Presses are only delivered when the cursor is inside the box
+		requestFocus(c.input)
+		return true
+	}
+	return false
+}
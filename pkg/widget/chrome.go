@@ -0,0 +1,160 @@
+package widget
+
+import (
+	"github.com/mleku/goo/pkg/interfaces"
+	"lol.mleku.dev/chk"
+)
+
+// WindowDrag returns the part of the window's frame a press has landed in
+// since it was last called, AreaClient for none. Render functions pass it
+// to the window in Frame.WindowDrag each frame, which then moves or resizes
+// itself while the button is held, for windows without the system's title
+// bar and borders that draw their own with Caption and ResizeBorder.
+func (r *RootWidget) WindowDrag() WindowArea {
+	area := r.windowDrag
+	r.windowDrag = interfaces.AreaClient
+	return area
+}
+
+// WindowAreaWidget makes its child part of the window's frame: a title bar
+// moving the window when dragged, or edges resizing it
+type WindowAreaWidget struct {
+	Base
+	child Widget
+	// caption moves the window when a press is left by the child, and border
+	// is the width of the edges resizing it, zero for none
+	caption bool
+	border  float32
+}
+
+// Caption creates a widget moving the window when its child is dragged,
+// for the title bar of an undecorated window. Presses the widgets inside
+// take, such as on a close button, do not move it.
+func Caption(child Widget) *WindowAreaWidget {
+	a := &WindowAreaWidget{child: child, caption: true}
+	adopt(a, child)
+	return a
+}
+
+// ResizeBorder creates a widget resizing the window when the edges of its
+// child are dragged, within a width of the outside, for wrapping the whole
+// content of an undecorated window. The edges take presses before the
+// widgets under them.
+func ResizeBorder(child Widget, width float32) *WindowAreaWidget {
+	a := &WindowAreaWidget{child: child, border: width}
+	adopt(a, child)
+	return a
+}
+
+// area returns the edge or corner of the border a point lies in,
+// AreaClient when it is inside them
+func (a *WindowAreaWidget) area(box *Box, p Point) WindowArea {
+	if a.border <= 0 || !box.Contains(p) {
+		return interfaces.AreaClient
+	}
+	// Corners are as long as a few widths of the border so they are easy to
+	// grab
+	corner := 4 * a.border
+	x, y := p.X-box.Position.X, p.Y-box.Position.Y
+	w, h := box.Size.Width, box.Size.Height
+	top, bottom := y < a.border, y >= h-a.border
+	left, right := x < a.border, x >= w-a.border
+	nearTop, nearBottom := y < corner, y >= h-corner
+	nearLeft, nearRight := x < corner, x >= w-corner
+	switch {
+	case (top || left) && nearTop && nearLeft:
+		return interfaces.AreaTopLeft
+	case (top || right) && nearTop && nearRight:
+		return interfaces.AreaTopRight
+	case (bottom || left) && nearBottom && nearLeft:
+		return interfaces.AreaBottomLeft
+	case (bottom || right) && nearBottom && nearRight:
+		return interfaces.AreaBottomRight
+	case top:
+		return interfaces.AreaTop
+	case bottom:
+		return interfaces.AreaBottom
+	case left:
+		return interfaces.AreaLeft
+	case right:
+		return interfaces.AreaRight
+	}
+	return interfaces.AreaClient
+}
+
+// CursorAt implements CursorProvider for WindowAreaWidget, showing the
+// resize cursors over the edges
+func (a *WindowAreaWidget) CursorAt(p Point) Cursor {
+	box := a.paintBox
+	switch a.area(&box, p) {
+	case interfaces.AreaLeft, interfaces.AreaRight:
+		return interfaces.CursorResizeH
+	case interfaces.AreaTop, interfaces.AreaBottom:
+		return interfaces.CursorResizeV
+	case interfaces.AreaClient:
+		return interfaces.CursorDefault
+	}
+	// There are no diagonal cursors, so corners show a crosshair
+	return interfaces.CursorCrosshair
+}
+
+// GetConstraints returns the child's constraints
+func (a *WindowAreaWidget) GetConstraints() Constraints {
+	if a.child == nil {
+		return NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return a.child.GetConstraints()
+}
+
+// Measure returns the size the child measures
+func (a *WindowAreaWidget) Measure(constraints Constraints) Size {
+	return measure(a.child, constraints)
+}
+
+// Layout implements the Widget interface for WindowAreaWidget; the child is
+// laid out in the widget's box
+func (a *WindowAreaWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !a.NeedsLayout(ctx, constraints) {
+		return a.CachedSize(), nil
+	}
+	if size, err = layoutInset(ctx, a.child, Insets{}, constraints); chk.E(err) {
+		return
+	}
+	a.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for WindowAreaWidget
+func (a *WindowAreaWidget) Paint(ctx *Context, box *Box) (err error) {
+	if a.child == nil {
+		return
+	}
+	return paintChild(ctx, a.child, box)
+}
+
+// HandleEvent implements the Widget interface for WindowAreaWidget
+func (a *WindowAreaWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	e, press := ev.(interfaces.MouseButtonEvent)
+	press = press && e.Button == interfaces.MouseButtonLeft && e.Action == interfaces.ActionPress
+	if press {
+		if area := a.area(box, e.Position); area != interfaces.AreaClient {
+			a.begin(area)
+			return true
+		}
+	}
+	if a.child != nil && routeEvent(ctx, a.child, box, ev) {
+		return true
+	}
+	if press && a.caption && box.Contains(e.Position) {
+		a.begin(interfaces.AreaCaption)
+		return true
+	}
+	return false
+}
+
+// begin tells the window to move or resize itself while the button is held
+func (a *WindowAreaWidget) begin(area WindowArea) {
+	if r := rootOf(a); r != nil {
+		r.windowDrag = area
+	}
+}
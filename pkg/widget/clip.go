@@ -0,0 +1,127 @@
+package widget
+
+import (
+	"github.com/mleku/goo/pkg/interfaces"
+	"lol.mleku.dev/chk"
+)
+
+// ClipWidget confines the painting of its child to its box, to the box with
+// rounded corners, or to the ellipse inside the box, with smooth edges. It
+// suits round avatars, cards whose content must not spill past their
+// corners, and effects that spread beyond a rounded outline. Pointer input
+// outside the shape does not reach the child.
+type ClipWidget struct {
+	Base
+	child   Widget
+	radius  float32
+	ellipse bool
+}
+
+// Clip creates a new clip confining the child to the clip's box
+func Clip(child Widget) *ClipWidget {
+	c := &ClipWidget{child: child}
+	adopt(c, child)
+	return c
+}
+
+// Radius rounds the corners of the clip to radius and returns the clip for chaining
+func (c *ClipWidget) Radius(radius float32) *ClipWidget {
+	c.radius = radius
+	c.MarkNeedsPaint()
+	return c
+}
+
+// Ellipse makes the clip the ellipse touching the edges of its box, a circle
+// in a square box, and returns the clip for chaining
+func (c *ClipWidget) Ellipse() *ClipWidget {
+	c.ellipse = true
+	c.MarkNeedsPaint()
+	return c
+}
+
+// GetConstraints returns the child's constraints
+func (c *ClipWidget) GetConstraints() Constraints {
+	if c.child == nil {
+		return NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return c.child.GetConstraints()
+}
+
+// Measure returns the size the child measures
+func (c *ClipWidget) Measure(constraints Constraints) Size {
+	return measure(c.child, constraints)
+}
+
+// Layout implements the Widget interface for ClipWidget; the child is laid
+// out in the clip's box
+func (c *ClipWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !c.NeedsLayout(ctx, constraints) {
+		return c.CachedSize(), nil
+	}
+	if size, err = layoutInset(ctx, c.child, Insets{}, constraints); chk.E(err) {
+		return
+	}
+	c.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for ClipWidget
+func (c *ClipWidget) Paint(ctx *Context, box *Box) (err error) {
+	if c.child == nil {
+		return
+	}
+	// Restrict descendants to the box, both for drawing and for skipping
+	// those outside the region being repainted
+	clip := box.Rect()
+	if !ctx.Clip.Empty() {
+		clip = clip.Intersect(ctx.Clip)
+	}
+	if clip.Empty() {
+		return
+	}
+	clipped := *ctx
+	clipped.Clip = clip
+	list := ctx.DrawList
+	x, y, w, h := box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height
+	if c.ellipse {
+		list.PushEllipseClip(x, y, w, h)
+	} else {
+		list.PushRoundClip(x, y, w, h, c.radius)
+	}
+	ctx.Hits.PushClip(box.Rect())
+	err = paintChild(&clipped, c.child, box)
+	ctx.Hits.PopClip()
+	list.PopClip()
+	return
+}
+
+// HandleEvent implements the Widget interface for ClipWidget
+func (c *ClipWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if c.child == nil {
+		return false
+	}
+	if at, targeted := interfaces.Target(ev); targeted && !c.contains(box, at) {
+		return false
+	}
+	return routeEvent(ctx, c.child, box, ev)
+}
+
+// contains reports whether a point lies inside the clip's shape
+func (c *ClipWidget) contains(box *Box, p Point) bool {
+	if !box.Contains(p) {
+		return false
+	}
+	w, h := box.Size.Width, box.Size.Height
+	rx, ry := min(c.radius, w/2, h/2), min(c.radius, w/2, h/2)
+	if c.ellipse {
+		rx, ry = w/2, h/2
+	}
+	if rx <= 0 || ry <= 0 {
+		return true
+	}
+	// Distance from the center of the nearest corner's arc, zero along the
+	// straight edges, relative to the corner's radii
+	dx := max(p.X-(box.Position.X+w-rx), box.Position.X+rx-p.X, 0) / rx
+	dy := max(p.Y-(box.Position.Y+h-ry), box.Position.Y+ry-p.Y, 0) / ry
+	return dx*dx+dy*dy <= 1
+}
@@ -0,0 +1,196 @@
+package widget
+
+import (
+	"slices"
+	"strings"
+)
+
+// codePos is a position in lines of text, a line index and a rune column
+type codePos struct {
+	line, col int
+}
+
+// before reports whether the position comes before another
+func (p codePos) before(q codePos) bool {
+	return p.line < q.line || p.line == q.line && p.col < q.col
+}
+
+// endOf returns the position after text inserted at a position
+func endOf(at codePos, s string) codePos {
+	n := strings.Count(s, "\n")
+	if n == 0 {
+		return codePos{line: at.line, col: at.col + len([]rune(s))}
+	}
+	return codePos{line: at.line + n, col: len([]rune(s[strings.LastIndexByte(s, '\n')+1:]))}
+}
+
+// gapLines holds lines of text in a gap buffer: a slice with a run of
+// unused slots where the last edit was. Edits move only the lines between
+// the gap and the edit, so typing in a file of any length is cheap and
+// every line is reached in constant time.
+type gapLines struct {
+	buf [][]rune
+	// buf[gap:gapEnd] are the unused slots
+	gap, gapEnd int
+}
+
+// reset replaces the lines
+func (g *gapLines) reset(lines [][]rune) {
+	g.buf = lines
+	g.gap, g.gapEnd = len(lines), len(lines)
+}
+
+// len returns the number of lines
+func (g *gapLines) len() int {
+	return len(g.buf) - (g.gapEnd - g.gap)
+}
+
+// index returns the slot holding a line
+func (g *gapLines) index(i int) int {
+	if i < g.gap {
+		return i
+	}
+	return i + g.gapEnd - g.gap
+}
+
+// line returns the runes of a line, which the caller must not modify
+func (g *gapLines) line(i int) []rune {
+	return g.buf[g.index(i)]
+}
+
+// set replaces a line
+func (g *gapLines) set(i int, line []rune) {
+	g.buf[g.index(i)] = line
+}
+
+// moveGap moves the gap to start before line i
+func (g *gapLines) moveGap(i int) {
+	switch {
+	case i < g.gap:
+		n := g.gap - i
+		copy(g.buf[g.gapEnd-n:g.gapEnd], g.buf[i:g.gap])
+		g.gap, g.gapEnd = i, g.gapEnd-n
+	case i > g.gap:
+		n := i - g.gap
+		copy(g.buf[g.gap:g.gap+n], g.buf[g.gapEnd:g.gapEnd+n])
+		g.gap, g.gapEnd = i, g.gapEnd+n
+	}
+	// Let the lines that moved out of the gap be collected
+	clear(g.buf[g.gap:g.gapEnd])
+}
+
+// insert inserts lines before line i
+func (g *gapLines) insert(i int, lines ...[]rune) {
+	if n := len(lines); g.gapEnd-g.gap < n {
+		// Double the buffer so the gap grows with the text
+		grown := make([][]rune, max(2*len(g.buf), len(g.buf)+n, 64))
+		copy(grown, g.buf[:g.gap])
+		after := len(g.buf) - g.gapEnd
+		copy(grown[len(grown)-after:], g.buf[g.gapEnd:])
+		g.buf, g.gapEnd = grown, len(grown)-after
+	}
+	g.moveGap(i)
+	copy(g.buf[g.gap:], lines)
+	g.gap += len(lines)
+}
+
+// remove removes the lines from i to j, j exclusive
+func (g *gapLines) remove(i, j int) {
+	if j <= i {
+		return
+	}
+	g.moveGap(i)
+	clear(g.buf[g.gapEnd : g.gapEnd+j-i])
+	g.gapEnd += j - i
+}
+
+// text returns the runes from one position to another joined by newlines
+func (g *gapLines) text(from, to codePos) string {
+	var b strings.Builder
+	for i := from.line; i <= to.line; i++ {
+		line := g.line(i)
+		start, end := 0, len(line)
+		if i == from.line {
+			start = from.col
+		}
+		if i == to.line {
+			end = to.col
+		}
+		if i > from.line {
+			b.WriteByte('\n')
+		}
+		b.WriteString(string(line[start:end]))
+	}
+	return b.String()
+}
+
+// replace replaces the text from one position to another with s, returning
+// the position after the inserted text
+func (g *gapLines) replace(from, to codePos, s string) codePos {
+	head := g.line(from.line)[:from.col]
+	tail := g.line(to.line)[to.col:]
+	pieces := strings.Split(s, "\n")
+	lines := make([][]rune, len(pieces))
+	for i, p := range pieces {
+		lines[i] = []rune(p)
+	}
+	end := codePos{line: from.line + len(lines) - 1, col: len(lines[len(lines)-1])}
+	if len(lines) == 1 {
+		end.col += len(head)
+	}
+	// The first and last new lines take the text around the range
+	lines[0] = append(slices.Clone(head), lines[0]...)
+	last := len(lines) - 1
+	lines[last] = append(lines[last], tail...)
+	g.remove(from.line+1, to.line+1)
+	g.set(from.line, lines[0])
+	g.insert(from.line+1, lines[1:]...)
+	return end
+}
+
+// codeEdit is an edit of a code editor recorded for undo: text removed and
+// inserted at a position, and the selection before and after it
+type codeEdit struct {
+	at                      codePos
+	removed, inserted       string
+	caret, anchor           codePos
+	caretAfter, anchorAfter codePos
+}
+
+// codeHistory records edits for undo and redo. Unlike editHistory it keeps
+// the changed text rather than copies of the whole buffer, so it suits long
+// files. Consecutive typed characters undo as one step.
+type codeHistory struct {
+	undo, redo []codeEdit
+	merging    bool
+}
+
+// push records an edit and discards the redo edits
+func (h *codeHistory) push(e codeEdit, merge bool) {
+	h.redo = h.redo[:0]
+	if n := len(h.undo); merge && h.merging && n > 0 {
+		last := &h.undo[n-1]
+		if e.removed == "" && last.removed == "" && e.at == endOf(last.at, last.inserted) {
+			last.inserted += e.inserted
+			last.caretAfter, last.anchorAfter = e.caretAfter, e.anchorAfter
+			return
+		}
+	}
+	h.undo = append(h.undo, e)
+	if len(h.undo) > maxUndo {
+		h.undo = slices.Delete(h.undo, 0, 1)
+	}
+	h.merging = merge
+}
+
+// breakMerge ends the current run of merged edits, such as when the caret moves
+func (h *codeHistory) breakMerge() {
+	h.merging = false
+}
+
+// clear forgets all recorded edits
+func (h *codeHistory) clear() {
+	h.undo = h.undo[:0]
+	h.redo = h.redo[:0]
+	h.merging = false
+}
@@ -0,0 +1,1147 @@
+package widget
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/render"
+	"github.com/mleku/goo/pkg/syntax"
+	"github.com/mleku/goo/pkg/text"
+	"github.com/mleku/goo/pkg/theme"
+)
+
+// bracketScan is how many lines bracket matching searches from the caret
+const bracketScan = 2000
+
+// CodeEditorWidget is a multi-line editor for source code. Lines do not
+// wrap and tabs align to tab stops. A lexer colors the text, matching
+// brackets are outlined, Enter keeps the indent of the line and a gutter
+// shows line numbers and colored markers. Lines are kept in a gap buffer and
+// only the lines in view are lexed and drawn, so long files stay responsive.
+type CodeEditorWidget struct {
+	Base
+	font        *text.Font
+	size        float32
+	padding     float32
+	tabWidth    int
+	indent      string
+	lineNumbers bool
+	lexer       syntax.Lexer
+	lines       gapLines
+	// caret and anchor are the ends of the selection, equal when nothing is
+	// selected
+	caret, anchor codePos
+	history       codeHistory
+	// states holds the lexer state at the start of each line up to the
+	// first line changed since it was lexed
+	states []syntax.State
+	tokens []syntax.Token
+	// markers color lines in the gutter
+	markers       map[int][4]float32
+	onChange      func()
+	onGutterClick func(line int)
+	// gutter is the width of the line number and marker column
+	gutter float32
+	// scroll is how far the text is shifted up and scrollX how far left;
+	// wide is the width of the longest line last drawn
+	scroll  float32
+	scrollX float32
+	wide    float32
+	// goalX is the column the caret returns to when moving between lines
+	goalX     float32
+	goalValid bool
+	// dragging is set while the mouse selects text
+	dragging bool
+	// blink tracks the caret blink cycle while focused
+	blink caretBlink
+	// compose is the text an input method is composing at the caret
+	compose composition
+
+	// Colors follow the theme unless set with Colors
+	backgroundColor colorOverride
+	textColor       colorOverride
+	selectionColor  colorOverride
+}
+
+// CodeEditor creates a new empty code editor using the given font, which is
+// best monospaced. The editor defaults to 14 pixel text, tab indents four
+// spaces wide and line numbers on, and shows plain text until a lexer is
+// set.
+func CodeEditor(font *text.Font) *CodeEditorWidget {
+	c := &CodeEditorWidget{
+		font:        font,
+		size:        14,
+		padding:     4,
+		tabWidth:    4,
+		indent:      "\t",
+		lineNumbers: true,
+	}
+	c.lines.reset([][]rune{nil})
+	return c
+}
+
+// Size sets the pixel size of the text and returns the editor for chaining
+func (c *CodeEditorWidget) Size(size float32) *CodeEditorWidget {
+	c.size = size
+	c.MarkNeedsLayout()
+	return c
+}
+
+// Lexer sets the lexer coloring the text, nil for plain text, and returns
+// the editor for chaining
+func (c *CodeEditorWidget) Lexer(lexer syntax.Lexer) *CodeEditorWidget {
+	c.lexer = lexer
+	c.states = c.states[:0]
+	c.MarkNeedsPaint()
+	return c
+}
+
+// TabWidth sets the number of spaces between tab stops and returns the
+// editor for chaining
+func (c *CodeEditorWidget) TabWidth(spaces int) *CodeEditorWidget {
+	c.tabWidth = max(spaces, 1)
+	c.MarkNeedsPaint()
+	return c
+}
+
+// Indent sets the text inserted by Tab and added after opening brackets,
+// such as "\t" or four spaces, and returns the editor for chaining
+func (c *CodeEditorWidget) Indent(indent string) *CodeEditorWidget {
+	c.indent = indent
+	return c
+}
+
+// LineNumbers sets whether line numbers are shown in the gutter and returns
+// the editor for chaining
+func (c *CodeEditorWidget) LineNumbers(show bool) *CodeEditorWidget {
+	c.lineNumbers = show
+	c.MarkNeedsPaint()
+	return c
+}
+
+// OnChange sets the callback invoked after each edit and returns the editor
+// for chaining. It is not given the text, which for long files is costly to
+// join; call Text when it is needed.
+func (c *CodeEditorWidget) OnChange(fn func()) *CodeEditorWidget {
+	c.onChange = fn
+	return c
+}
+
+// OnGutterClick sets the callback invoked with the 0-based line clicked in
+// the gutter, such as to toggle a breakpoint marker, and returns the editor
+// for chaining
+func (c *CodeEditorWidget) OnGutterClick(fn func(line int)) *CodeEditorWidget {
+	c.onGutterClick = fn
+	return c
+}
+
+// Colors sets the background, text and selection colors, replacing the
+// theme's, and returns the editor for chaining. Tokens keep the theme's
+// syntax colors.
+func (c *CodeEditorWidget) Colors(background, text, selection [4]float32) *CodeEditorWidget {
+	c.backgroundColor = override(background)
+	c.textColor = override(text)
+	c.selectionColor = override(selection)
+	c.MarkNeedsPaint()
+	return c
+}
+
+// SetText replaces the text, moves the caret to the start and clears the
+// undo history and markers
+func (c *CodeEditorWidget) SetText(s string) {
+	pieces := strings.Split(strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(s), "\n")
+	lines := make([][]rune, len(pieces))
+	for i, p := range pieces {
+		lines[i] = []rune(p)
+	}
+	c.lines.reset(lines)
+	c.caret, c.anchor = codePos{}, codePos{}
+	c.history.clear()
+	c.states = c.states[:0]
+	clear(c.markers)
+	c.scroll, c.scrollX = 0, 0
+	c.goalValid = false
+	c.MarkNeedsPaint()
+}
+
+// Text returns the current text
+func (c *CodeEditorWidget) Text() string {
+	return c.lines.text(codePos{}, c.end())
+}
+
+// LineCount returns the number of lines
+func (c *CodeEditorWidget) LineCount() int {
+	return c.lines.len()
+}
+
+// Line returns the text of a 0-based line
+func (c *CodeEditorWidget) Line(i int) string {
+	return string(c.lines.line(i))
+}
+
+// Caret returns the 0-based line and rune column of the caret
+func (c *CodeEditorWidget) Caret() (line, col int) {
+	return c.caret.line, c.caret.col
+}
+
+// SetCaret moves the caret to a 0-based line and rune column, clearing the
+// selection and scrolling it into view
+func (c *CodeEditorWidget) SetCaret(line, col int) {
+	c.moveTo(codePos{line: line, col: col}, false)
+	c.goalValid = false
+	c.history.breakMerge()
+	c.scrollToCaret()
+	c.MarkNeedsPaint()
+}
+
+// SetMarker shows a colored dot in the gutter beside a 0-based line, such as
+// for breakpoints or errors. Markers move with their lines as text is
+// inserted and removed above them.
+func (c *CodeEditorWidget) SetMarker(line int, color [4]float32) {
+	if c.markers == nil {
+		c.markers = make(map[int][4]float32)
+	}
+	c.markers[line] = color
+	c.MarkNeedsPaint()
+}
+
+// ClearMarker removes the marker of a line
+func (c *CodeEditorWidget) ClearMarker(line int) {
+	delete(c.markers, line)
+	c.MarkNeedsPaint()
+}
+
+// ClearMarkers removes all markers
+func (c *CodeEditorWidget) ClearMarkers() {
+	clear(c.markers)
+	c.MarkNeedsPaint()
+}
+
+// Undo reverts the last edit, reporting whether there was one
+func (c *CodeEditorWidget) Undo() bool {
+	h := &c.history
+	n := len(h.undo)
+	if n == 0 {
+		return false
+	}
+	e := h.undo[n-1]
+	h.undo = h.undo[:n-1]
+	h.redo = append(h.redo, e)
+	h.merging = false
+	c.apply(e.at, endOf(e.at, e.inserted), e.removed)
+	c.caret, c.anchor = e.caret, e.anchor
+	c.edited(time.Now(), true)
+	return true
+}
+
+// Redo reapplies the last undone edit, reporting whether there was one
+func (c *CodeEditorWidget) Redo() bool {
+	h := &c.history
+	n := len(h.redo)
+	if n == 0 {
+		return false
+	}
+	e := h.redo[n-1]
+	h.redo = h.redo[:n-1]
+	h.undo = append(h.undo, e)
+	h.merging = false
+	c.apply(e.at, endOf(e.at, e.removed), e.inserted)
+	c.caret, c.anchor = e.caretAfter, e.anchorAfter
+	c.edited(time.Now(), true)
+	return true
+}
+
+// GetConstraints returns a minimum height that fits one line of text inside the padding
+func (c *CodeEditorWidget) GetConstraints() Constraints {
+	return NewFlexConstraints(0, c.font.Face(c.size).LineHeight()+2*c.padding, 1e9, 1e9)
+}
+
+// Measure returns the minimum size of the code editor
+func (c *CodeEditorWidget) Measure(constraints Constraints) Size {
+	return minSize(c.GetConstraints())
+}
+
+// Layout implements the Widget interface for CodeEditorWidget; editors take
+// all the space offered
+func (c *CodeEditorWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	c.SetLayout(ctx, constraints, size)
+	c.scrollToCaret()
+	return
+}
+
+// Paint implements the Widget interface for CodeEditorWidget
+func (c *CodeEditorWidget) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	focused := hasFocus(c)
+	border := th.Border
+	if focused {
+		border = th.Primary
+	}
+	fillBordered(ctx, box, th.Radius.Small, border, c.backgroundColor.or(th.Field))
+	inner := NewBox(box.Position.X+1, box.Position.Y+1, box.Size.Width-2, box.Size.Height-2, box.Constraints)
+	textColor := c.textColor.or(th.Text)
+
+	face := c.font.Face(c.size)
+	lineHeight := face.LineHeight()
+	c.updateGutter(face)
+	list := ctx.DrawList
+	list.PushClip(inner.Position.X, inner.Position.Y, inner.Size.Width, inner.Size.Height)
+	defer list.PopClip()
+
+	// Only the lines inside the view are lexed and drawn
+	top := box.Position.Y + c.padding - c.scroll
+	first := max(int(c.scroll/lineHeight), 0)
+	last := min(int((c.scroll+box.Size.Height)/lineHeight)+1, c.lines.len())
+
+	if c.gutter > 0 {
+		list.Rect(inner.Position.X, inner.Position.Y, c.gutter-1, inner.Size.Height, th.Track)
+		for i := first; i < last; i++ {
+			y := top + float32(i)*lineHeight
+			if color, ok := c.markers[i]; ok {
+				radius := lineHeight * 0.22
+				list.Circle(box.Position.X+c.padding+radius, y+lineHeight/2, radius, color)
+			}
+			if !c.lineNumbers {
+				continue
+			}
+			number := strconv.Itoa(i + 1)
+			numberColor := th.TextMuted
+			if i == c.caret.line {
+				numberColor = textColor
+			}
+			x := box.Position.X + c.gutter - c.padding - face.Measure(number)
+			face.Draw(list, x, y+face.Ascent(), number, numberColor)
+		}
+	}
+
+	// Text scrolls horizontally beneath the gutter
+	textLeft := box.Position.X + c.gutter
+	list.PushClip(textLeft, inner.Position.Y, box.Position.X+box.Size.Width-1-textLeft, inner.Size.Height)
+	defer list.PopClip()
+	x := textLeft + c.padding - c.scrollX
+
+	start, end := c.selection()
+	if start == end {
+		// Shade the caret line when nothing is selected
+		shade := th.Selection
+		shade[3] *= 0.35
+		list.Rect(textLeft, top+float32(c.caret.line)*lineHeight, box.Size.Width, lineHeight, shade)
+	}
+	if !focused {
+		// A composition ends with focus
+		c.compose.reset()
+	}
+	var composeX float32
+	var state syntax.State
+	if c.lexer != nil {
+		state = c.stateAt(first)
+	}
+	c.wide = 0
+	for i := first; i < last; i++ {
+		line := c.lines.line(i)
+		y := top + float32(i)*lineHeight
+		if focused && start != end && start.line <= i && end.line >= i {
+			x0, x1 := float32(0), c.columnX(face, line, len(line))
+			if i == start.line {
+				x0 = c.columnX(face, line, start.col)
+			}
+			if i == end.line {
+				x1 = c.columnX(face, line, end.col)
+			} else {
+				// Show the selected line break as a space
+				x1 += face.Glyph(' ').Advance
+			}
+			list.Rect(x+x0, y, x1-x0, lineHeight, c.selectionColor.or(th.Selection))
+		}
+		baseline := y + face.Ascent()
+		c.tokens = c.tokens[:0]
+		if c.lexer != nil {
+			c.tokens, state = c.lexer.Lex(line, state, c.tokens)
+			if i+1 == len(c.states) {
+				c.states = append(c.states, state)
+			}
+		}
+		if i == c.caret.line && c.compose.active() {
+			// Composed text is shown at the caret, pushing the text after it
+			// on
+			pen := c.drawColored(list, face, th, line, 0, c.caret.col, 0, x, baseline, textColor)
+			var width float32
+			width, composeX = c.compose.draw(list, face, x+pen, baseline, textColor)
+			c.wide = max(c.wide, c.drawColored(list, face, th, line, c.caret.col, len(line), pen+width, x, baseline, textColor))
+			continue
+		}
+		c.wide = max(c.wide, c.drawColored(list, face, th, line, 0, len(line), 0, x, baseline, textColor))
+	}
+
+	if open, close, ok := c.matchBrackets(); ok {
+		for _, p := range []codePos{open, close} {
+			line := c.lines.line(p.line)
+			x0 := c.columnX(face, line, p.col)
+			width := face.Glyph(line[p.col]).Advance
+			list.RoundRectStroke(x+x0-1, top+float32(p.line)*lineHeight, width+2, lineHeight, th.Radius.Small, 1, th.TextMuted)
+		}
+	}
+
+	if focused {
+		caret := x + c.columnX(face, c.lines.line(c.caret.line), c.caret.col) + composeX
+		caret = float32(math.Round(float64(caret)))
+		y := top + float32(c.caret.line)*lineHeight
+		reportCaret(ctx, caret, y, lineHeight)
+		if c.blink.visible() {
+			list.Rect(caret, y, 1, lineHeight, textColor)
+		}
+	}
+
+	// A thin thumb shows the position within long text
+	view := box.Size.Height - 2*c.padding
+	if content := float32(c.lines.len()) * lineHeight; content > view {
+		length, position := thumbSpan(view, view, content, c.scroll)
+		list.Rect(box.Position.X+box.Size.Width-5, box.Position.Y+c.padding+position, 3, length, th.Thumb)
+	}
+	return
+}
+
+// syntaxColor returns the theme color of a kind of token
+func syntaxColor(th *theme.Theme, kind syntax.Kind, plain [4]float32) [4]float32 {
+	switch kind {
+	case syntax.Keyword:
+		return th.Syntax.Keyword
+	case syntax.Type:
+		return th.Syntax.Type
+	case syntax.Function:
+		return th.Syntax.Function
+	case syntax.String:
+		return th.Syntax.String
+	case syntax.Number:
+		return th.Syntax.Number
+	case syntax.Comment:
+		return th.Syntax.Comment
+	case syntax.Operator:
+		return th.Syntax.Operator
+	}
+	return plain
+}
+
+// CursorAt implements CursorProvider, showing the text cursor over the text
+// and the normal arrow over the gutter
+func (c *CodeEditorWidget) CursorAt(p Point) Cursor {
+	if p.X < c.paintBox.Position.X+c.gutter {
+		return interfaces.CursorArrow
+	}
+	return interfaces.CursorIBeam
+}
+
+// Accessibility implements Accessible
+func (c *CodeEditorWidget) Accessibility() AccessNode {
+	return AccessNode{Role: interfaces.RoleTextArea, Value: c.Text(), State: interfaces.StateMultiline}
+}
+
+// navigable lets gamepad navigation reach the code editor
+func (c *CodeEditorWidget) navigable() bool {
+	return true
+}
+
+// HandleEvent implements the Widget interface for CodeEditorWidget
+func (c *CodeEditorWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft {
+			return false
+		}
+		switch e.Action {
+		case interfaces.ActionPress:
+			// Presses are only delivered when the cursor is inside the box
+			requestFocus(c)
+			p := c.positionAt(box, e.Position)
+			if e.Position.X < box.Position.X+c.gutter && c.onGutterClick != nil {
+				c.onGutterClick(p.line)
+				return true
+			}
+			c.moveTo(p, e.Mods&interfaces.ModShift != 0)
+			c.dragging = true
+			c.moved(ctx)
+			return true
+		case interfaces.ActionRelease:
+			if !c.dragging {
+				return false
+			}
+			c.dragging = false
+			return true
+		}
+	case interfaces.MouseMoveEvent:
+		if !c.dragging {
+			return false
+		}
+		if p := c.positionAt(box, e.Position); p != c.caret {
+			c.moveTo(p, true)
+			c.moved(ctx)
+		}
+		return true
+	case interfaces.ScrollEvent:
+		lineHeight := c.font.Face(c.size).LineHeight()
+		before, beforeX := c.scroll, c.scrollX
+		c.scroll -= e.Offset.Y * 3 * lineHeight
+		c.scrollX -= e.Offset.X * 3 * lineHeight
+		c.clampScroll()
+		c.scrollX = min(max(c.scrollX, 0), max(c.wide-c.textWidth()+1, 0))
+		if c.scroll == before && c.scrollX == beforeX {
+			return false
+		}
+		c.MarkNeedsPaint()
+		return true
+	case interfaces.KeyEvent:
+		if !hasFocus(c) || e.Action == interfaces.ActionRelease {
+			return false
+		}
+		return c.key(ctx, e)
+	case interfaces.CharEvent:
+		if !hasFocus(c) {
+			return false
+		}
+		c.compose.reset()
+		c.typed(ctx, e.Char)
+		return true
+	case interfaces.PreeditEvent:
+		if !hasFocus(c) {
+			return false
+		}
+		c.compose.update(e)
+		c.edited(frameTime(ctx), false)
+		return true
+	}
+	return false
+}
+
+// typed inserts a typed rune. A closing bracket typed on a line holding only
+// its indent takes off one level of indent.
+func (c *CodeEditorWidget) typed(ctx *Context, r rune) {
+	from, to := c.selection()
+	if from == to && strings.ContainsRune(")]}", r) {
+		line := c.lines.line(from.line)
+		if strings.TrimSpace(string(line)) == "" && from.col == len(line) {
+			from.col = 0
+			c.edit(ctx, from, to, c.unindent(string(line))+string(r), "", false)
+			return
+		}
+	}
+	c.edit(ctx, from, to, string(r), "", true)
+}
+
+// key applies an editing or movement key
+func (c *CodeEditorWidget) key(ctx *Context, e interfaces.KeyEvent) (handled bool) {
+	extend := e.Mods&interfaces.ModShift != 0
+	word := e.Mods&(interfaces.ModControl|interfaces.ModAlt) != 0
+	shortcut := e.Mods&(interfaces.ModControl|interfaces.ModSuper) != 0
+	from, to := c.selection()
+	switch e.Key {
+	case interfaces.KeyLeft:
+		switch {
+		case from != to && !extend:
+			c.moveTo(from, false)
+		case word:
+			c.moveTo(c.wordLeft(c.caret), extend)
+		default:
+			c.moveTo(c.before(c.caret), extend)
+		}
+	case interfaces.KeyRight:
+		switch {
+		case from != to && !extend:
+			c.moveTo(to, false)
+		case word:
+			c.moveTo(c.wordRight(c.caret), extend)
+		default:
+			c.moveTo(c.after(c.caret), extend)
+		}
+	case interfaces.KeyUp:
+		c.moveLines(ctx, -1, extend)
+		return true
+	case interfaces.KeyDown:
+		c.moveLines(ctx, 1, extend)
+		return true
+	case interfaces.KeyPageUp:
+		c.moveLines(ctx, -c.pageLines(), extend)
+		return true
+	case interfaces.KeyPageDown:
+		c.moveLines(ctx, c.pageLines(), extend)
+		return true
+	case interfaces.KeyHome:
+		if shortcut {
+			c.moveTo(codePos{}, extend)
+			break
+		}
+		// Home goes to the first non-space, then to the start of the line
+		col := leadingSpace(c.lines.line(c.caret.line))
+		if c.caret.col == col {
+			col = 0
+		}
+		c.moveTo(codePos{line: c.caret.line, col: col}, extend)
+	case interfaces.KeyEnd:
+		if shortcut {
+			c.moveTo(c.end(), extend)
+		} else {
+			c.moveTo(codePos{line: c.caret.line, col: len(c.lines.line(c.caret.line))}, extend)
+		}
+	case interfaces.KeyBackspace:
+		if from == to {
+			if word {
+				from = c.wordLeft(c.caret)
+			} else {
+				from = c.before(c.caret)
+			}
+		}
+		c.edit(ctx, from, to, "", "", false)
+		return true
+	case interfaces.KeyDelete:
+		if from == to {
+			if word {
+				to = c.wordRight(c.caret)
+			} else {
+				to = c.after(c.caret)
+			}
+		}
+		c.edit(ctx, from, to, "", "", false)
+		return true
+	case interfaces.KeyEnter:
+		c.newline(ctx, from, to)
+		return true
+	case interfaces.KeyTab:
+		if extend {
+			c.indentLines(ctx, false)
+		} else if from.line != to.line {
+			c.indentLines(ctx, true)
+		} else {
+			c.edit(ctx, from, to, c.indent, "", true)
+		}
+		return true
+	case interfaces.KeyA:
+		if !shortcut {
+			return false
+		}
+		c.anchor, c.caret = codePos{}, c.end()
+	case interfaces.KeyC:
+		if !shortcut {
+			return false
+		}
+		if from != to && ctx.Clipboard != nil {
+			ctx.Clipboard.SetText(c.lines.text(from, to))
+		}
+		return true
+	case interfaces.KeyX:
+		if !shortcut {
+			return false
+		}
+		if from != to && ctx.Clipboard != nil {
+			ctx.Clipboard.SetText(c.lines.text(from, to))
+			c.edit(ctx, from, to, "", "", false)
+		}
+		return true
+	case interfaces.KeyV:
+		if !shortcut {
+			return false
+		}
+		if ctx.Clipboard != nil {
+			paste := strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(ctx.Clipboard.Text())
+			c.edit(ctx, from, to, paste, "", false)
+		}
+		return true
+	case interfaces.KeyZ:
+		if !shortcut {
+			return false
+		}
+		if extend {
+			c.Redo()
+		} else {
+			c.Undo()
+		}
+		return true
+	case interfaces.KeyY:
+		if !shortcut {
+			return false
+		}
+		c.Redo()
+		return true
+	default:
+		return false
+	}
+	c.moved(ctx)
+	return true
+}
+
+// newline breaks the line at the caret, starting the new line with the
+// indent of the old one and one level more after an opening bracket. Between
+// a pair of brackets the closing one moves down to a line of its own.
+func (c *CodeEditorWidget) newline(ctx *Context, from, to codePos) {
+	line := c.lines.line(from.line)
+	indent := string(line[:min(leadingSpace(line), from.col)])
+	head := strings.TrimRightFunc(string(line[:from.col]), unicode.IsSpace)
+	if head == "" || !strings.ContainsRune("([{", rune(head[len(head)-1])) {
+		c.edit(ctx, from, to, "\n"+indent, "", false)
+		return
+	}
+	tail := ""
+	rest := c.lines.line(to.line)[to.col:]
+	if len(rest) > 0 && rest[0] == closingBracket(rune(head[len(head)-1])) {
+		tail = "\n" + indent
+	}
+	c.edit(ctx, from, to, "\n"+indent+c.indent, tail, false)
+}
+
+// indentLines adds or takes off one level of indent on each line the
+// selection or caret touches, keeping the lines selected
+func (c *CodeEditorWidget) indentLines(ctx *Context, add bool) {
+	from, to := c.selection()
+	if to.line > from.line && to.col == 0 {
+		// A selection ending at the start of a line leaves that line alone
+		to.line--
+	}
+	lines := make([]string, 0, to.line-from.line+1)
+	for i := from.line; i <= to.line; i++ {
+		line := string(c.lines.line(i))
+		switch {
+		case !add:
+			n := len(line) - len(strings.TrimLeft(line, " \t"))
+			line = c.unindent(line[:n]) + line[n:]
+		case line != "":
+			line = c.indent + line
+		}
+		lines = append(lines, line)
+	}
+	start, end := codePos{line: from.line}, codePos{line: to.line, col: len(c.lines.line(to.line))}
+	s := strings.Join(lines, "\n")
+	if s == c.lines.text(start, end) {
+		return
+	}
+	c.edit(ctx, start, end, s, "", false)
+	c.anchor = start
+	c.history.undo[len(c.history.undo)-1].anchorAfter = start
+}
+
+// unindent takes one level of indent off leading white space: a tab, or
+// spaces up to the tab width
+func (c *CodeEditorWidget) unindent(space string) string {
+	if strings.HasSuffix(space, "\t") {
+		return space[:len(space)-1]
+	}
+	trimmed := strings.TrimRight(space, " ")
+	return space[:max(len(trimmed), len(space)-c.tabWidth)]
+}
+
+// edit replaces the text between two positions with s followed by tail,
+// placing the caret between them, and records the edit for undo. Merged
+// edits undo together with the ones before them.
+func (c *CodeEditorWidget) edit(ctx *Context, from, to codePos, s, tail string, merge bool) {
+	if from == to && s == "" && tail == "" {
+		return
+	}
+	e := codeEdit{
+		at:       from,
+		removed:  c.lines.text(from, to),
+		inserted: s + tail,
+		caret:    c.caret,
+		anchor:   c.anchor,
+	}
+	c.apply(from, to, e.inserted)
+	c.caret = endOf(from, s)
+	c.anchor = c.caret
+	e.caretAfter, e.anchorAfter = c.caret, c.anchor
+	c.history.push(e, merge)
+	c.edited(frameTime(ctx), true)
+}
+
+// apply replaces the text between two positions, invalidating the lexer
+// states after the first changed line and moving the markers below it
+func (c *CodeEditorWidget) apply(from, to codePos, s string) {
+	c.lines.replace(from, to, s)
+	c.states = c.states[:min(len(c.states), from.line+1)]
+	delta := strings.Count(s, "\n") - (to.line - from.line)
+	if len(c.markers) == 0 || delta == 0 && from.line == to.line {
+		return
+	}
+	markers := make(map[int][4]float32, len(c.markers))
+	for line, color := range c.markers {
+		switch {
+		case line <= from.line:
+			markers[line] = color
+		case line > to.line:
+			markers[line+delta] = color
+		}
+	}
+	c.markers = markers
+}
+
+// moved updates the view after the caret or selection moved without an edit
+func (c *CodeEditorWidget) moved(ctx *Context) {
+	c.history.breakMerge()
+	c.goalValid = false
+	c.edited(frameTime(ctx), false)
+}
+
+// edited restarts the caret blink, scrolls the caret into view and repaints,
+// notifying the change callback when the text changed
+func (c *CodeEditorWidget) edited(now time.Time, changed bool) {
+	if changed {
+		c.goalValid = false
+	}
+	c.blink.restart(now)
+	c.scrollToCaret()
+	c.MarkNeedsPaint()
+	if changed && c.onChange != nil {
+		c.onChange()
+	}
+}
+
+// tick blinks the caret while the editor has focus
+func (c *CodeEditorWidget) tick(ctx *Context) {
+	now := frameTime(ctx)
+	if c.blink.update(now) {
+		c.MarkNeedsPaint()
+	}
+	ctx.Clock.WakeAt(c.blink.next(now))
+}
+
+// moveLines moves the caret up or down by a number of lines, keeping it near
+// the column it started from
+func (c *CodeEditorWidget) moveLines(ctx *Context, delta int, extend bool) {
+	face := c.font.Face(c.size)
+	if !c.goalValid {
+		c.goalX = c.columnX(face, c.lines.line(c.caret.line), c.caret.col)
+		c.goalValid = true
+	}
+	switch j := c.caret.line + delta; {
+	case j < 0:
+		c.moveTo(codePos{}, extend)
+	case j >= c.lines.len():
+		c.moveTo(c.end(), extend)
+	default:
+		c.moveTo(codePos{line: j, col: c.columnAt(face, c.lines.line(j), c.goalX)}, extend)
+	}
+	// Keep the goal column for the next vertical move
+	c.history.breakMerge()
+	c.edited(frameTime(ctx), false)
+}
+
+// pageLines returns the number of lines that fit in the view
+func (c *CodeEditorWidget) pageLines() int {
+	view := c.CachedSize().Height - 2*c.padding
+	return max(int(view/c.font.Face(c.size).LineHeight()), 1)
+}
+
+// selection returns the selected range in order
+func (c *CodeEditorWidget) selection() (from, to codePos) {
+	if c.anchor.before(c.caret) {
+		return c.anchor, c.caret
+	}
+	return c.caret, c.anchor
+}
+
+// end returns the position at the end of the text
+func (c *CodeEditorWidget) end() codePos {
+	last := c.lines.len() - 1
+	return codePos{line: last, col: len(c.lines.line(last))}
+}
+
+// moveTo places the caret within the text, extending the selection from the
+// anchor when extend is set and collapsing it otherwise
+func (c *CodeEditorWidget) moveTo(p codePos, extend bool) {
+	p.line = min(max(p.line, 0), c.lines.len()-1)
+	p.col = min(max(p.col, 0), len(c.lines.line(p.line)))
+	c.caret = p
+	if !extend {
+		c.anchor = p
+	}
+}
+
+// before returns the position one rune back, at the end of the previous line
+// from the start of a line
+func (c *CodeEditorWidget) before(p codePos) codePos {
+	switch {
+	case p.col > 0:
+		p.col--
+	case p.line > 0:
+		p.line--
+		p.col = len(c.lines.line(p.line))
+	}
+	return p
+}
+
+// after returns the position one rune on, at the start of the next line from
+// the end of a line
+func (c *CodeEditorWidget) after(p codePos) codePos {
+	switch {
+	case p.col < len(c.lines.line(p.line)):
+		p.col++
+	case p.line < c.lines.len()-1:
+		p.line++
+		p.col = 0
+	}
+	return p
+}
+
+// wordLeft returns the start of the word before a position, skipping any
+// spaces and punctuation in between, or the end of the previous line from
+// the start of a line
+func (c *CodeEditorWidget) wordLeft(p codePos) codePos {
+	if p.col == 0 {
+		return c.before(p)
+	}
+	line := c.lines.line(p.line)
+	for p.col > 0 && !isWordRune(line[p.col-1]) {
+		p.col--
+	}
+	for p.col > 0 && isWordRune(line[p.col-1]) {
+		p.col--
+	}
+	return p
+}
+
+// wordRight returns the end of the word after a position, skipping any
+// spaces and punctuation in between, or the start of the next line from the
+// end of a line
+func (c *CodeEditorWidget) wordRight(p codePos) codePos {
+	line := c.lines.line(p.line)
+	if p.col == len(line) {
+		return c.after(p)
+	}
+	for p.col < len(line) && !isWordRune(line[p.col]) {
+		p.col++
+	}
+	for p.col < len(line) && isWordRune(line[p.col]) {
+		p.col++
+	}
+	return p
+}
+
+// stateAt returns the lexer state at the start of a line, lexing the lines
+// before it that changed since they were last lexed
+func (c *CodeEditorWidget) stateAt(i int) syntax.State {
+	if len(c.states) == 0 {
+		c.states = append(c.states, 0)
+	}
+	for n := len(c.states); n <= i; n++ {
+		var state syntax.State
+		c.tokens, state = c.lexer.Lex(c.lines.line(n-1), c.states[n-1], c.tokens[:0])
+		c.states = append(c.states, state)
+	}
+	return c.states[i]
+}
+
+// matchBrackets returns the bracket beside the caret and the one matching
+// it, searching up to bracketScan lines away
+func (c *CodeEditorWidget) matchBrackets() (open, close codePos, ok bool) {
+	line := c.lines.line(c.caret.line)
+	p := c.caret
+	switch {
+	case p.col < len(line) && strings.ContainsRune("()[]{}", line[p.col]):
+	case p.col > 0 && strings.ContainsRune("()[]{}", line[p.col-1]):
+		p.col--
+	default:
+		return
+	}
+	r := line[p.col]
+	step, other := 1, closingBracket(r)
+	if other == 0 {
+		step, other = -1, openingBracket(r)
+	}
+	depth := 0
+	for q, n := p, 0; n < bracketScan; {
+		runes := c.lines.line(q.line)
+		for q.col >= 0 && q.col < len(runes) {
+			switch runes[q.col] {
+			case r:
+				depth++
+			case other:
+				if depth--; depth == 0 {
+					if step < 0 {
+						return q, p, true
+					}
+					return p, q, true
+				}
+			}
+			q.col += step
+		}
+		if q.line += step; q.line < 0 || q.line >= c.lines.len() {
+			return
+		}
+		if q.col = 0; step < 0 {
+			q.col = len(c.lines.line(q.line)) - 1
+		}
+		n++
+	}
+	return
+}
+
+// leadingSpace returns the number of spaces and tabs starting a line
+func leadingSpace(line []rune) (n int) {
+	for n < len(line) && (line[n] == ' ' || line[n] == '\t') {
+		n++
+	}
+	return
+}
+
+// closingBracket returns the bracket closing an opening one, or 0
+func closingBracket(r rune) rune {
+	switch r {
+	case '(':
+		return ')'
+	case '[':
+		return ']'
+	case '{':
+		return '}'
+	}
+	return 0
+}
+
+// openingBracket returns the bracket opening a closing one, or 0
+func openingBracket(r rune) rune {
+	switch r {
+	case ')':
+		return '('
+	case ']':
+		return '['
+	case '}':
+		return '{'
+	}
+	return 0
+}
+
+// tabStop returns the distance between tab stops
+func (c *CodeEditorWidget) tabStop(face *text.Face) float32 {
+	return float32(c.tabWidth) * face.Glyph(' ').Advance
+}
+
+// advance returns the x after a rune drawn at x, moving tabs to the next
+// tab stop
+func (c *CodeEditorWidget) advance(face *text.Face, r rune, x float32) float32 {
+	if r == '\t' {
+		stop := c.tabStop(face)
+		return float32(math.Floor(float64(x/stop))+1) * stop
+	}
+	return x + face.Glyph(r).Advance
+}
+
+// columnX returns the x of a column of a line from the start of the text
+func (c *CodeEditorWidget) columnX(face *text.Face, line []rune, col int) (x float32) {
+	for _, r := range line[:col] {
+		x = c.advance(face, r, x)
+	}
+	return
+}
+
+// columnAt returns the column of a line closest to an x from the start of
+// the text
+func (c *CodeEditorWidget) columnAt(face *text.Face, line []rune, x float32) int {
+	var pen float32
+	for i, r := range line {
+		next := c.advance(face, r, pen)
+		if x < (pen+next)/2 {
+			return i
+		}
+		pen = next
+	}
+	return len(line)
+}
+
+// drawColored draws the runes of a line from one column to another at pen,
+// in the colors of the line's tokens, and returns the pen after them
+func (c *CodeEditorWidget) drawColored(list *render.DrawList, face *text.Face, th *theme.Theme, line []rune, from, to int, pen, origin, baseline float32, plain [4]float32) float32 {
+	col := from
+	for _, tok := range c.tokens {
+		start, end := max(tok.Start, col), min(tok.End, to)
+		if start >= end {
+			continue
+		}
+		pen = c.drawRun(list, face, line[col:start], pen, origin, baseline, plain)
+		pen = c.drawRun(list, face, line[start:end], pen, origin, baseline, syntaxColor(th, tok.Kind, plain))
+		col = end
+	}
+	return c.drawRun(list, face, line[col:to], pen, origin, baseline, plain)
+}
+
+// drawRun draws runes at pen, measured from the start of the text at origin,
+// expanding tabs, and returns the pen after them
+func (c *CodeEditorWidget) drawRun(list *render.DrawList, face *text.Face, run []rune, pen, origin, baseline float32, color [4]float32) float32 {
+	for len(run) > 0 {
+		i := slices.Index(run, '\t')
+		if i < 0 {
+			i = len(run)
+		}
+		if i > 0 {
+			s := string(run[:i])
+			face.Draw(list, origin+pen, baseline, s, color)
+			pen += face.Measure(s)
+		}
+		if i < len(run) {
+			pen = c.advance(face, '\t', pen)
+			i++
+		}
+		run = run[i:]
+	}
+	return pen
+}
+
+// positionAt returns the text position closest to a point in window
+// coordinates
+func (c *CodeEditorWidget) positionAt(box *Box, p Point) codePos {
+	face := c.font.Face(c.size)
+	i := int(math.Floor(float64((p.Y - box.Position.Y - c.padding + c.scroll) / face.LineHeight())))
+	i = min(max(i, 0), c.lines.len()-1)
+	x := p.X - box.Position.X - c.gutter - c.padding + c.scrollX
+	return codePos{line: i, col: c.columnAt(face, c.lines.line(i), x)}
+}
+
+// scrollToCaret scrolls the view so the caret line and column are visible
+func (c *CodeEditorWidget) scrollToCaret() {
+	face := c.font.Face(c.size)
+	c.updateGutter(face)
+	lineHeight := face.LineHeight()
+	y := float32(c.caret.line) * lineHeight
+	view := c.CachedSize().Height - 2*c.padding
+	if y+lineHeight > c.scroll+view {
+		c.scroll = y + lineHeight - view
+	}
+	if y < c.scroll {
+		c.scroll = y
+	}
+	c.clampScroll()
+
+	visible := c.textWidth() - 1
+	caret := c.columnX(face, c.lines.line(c.caret.line), c.caret.col)
+	if caret-c.scrollX > visible {
+		c.scrollX = caret - visible
+	}
+	if caret < c.scrollX {
+		c.scrollX = caret
+	}
+	c.scrollX = max(c.scrollX, 0)
+}
+
+// clampScroll keeps the vertical scroll within the text
+func (c *CodeEditorWidget) clampScroll() {
+	lineHeight := c.font.Face(c.size).LineHeight()
+	view := c.CachedSize().Height - 2*c.padding
+	content := float32(c.lines.len()) * lineHeight
+	c.scroll = min(max(c.scroll, 0), max(content-view, 0))
+}
+
+// textWidth returns the width available for text beside the gutter
+func (c *CodeEditorWidget) textWidth() float32 {
+	return c.CachedSize().Width - c.gutter - 2*c.padding
+}
+
+// updateGutter sizes the gutter to the widest line number and the markers
+func (c *CodeEditorWidget) updateGutter(face *text.Face) {
+	c.gutter = 0
+	if c.lineNumbers {
+		c.gutter = face.Measure(strconv.Itoa(c.lines.len())) + 2*c.padding
+	}
+	if c.lineNumbers || len(c.markers) > 0 {
+		// Markers sit in their own column left of the numbers
+		c.gutter += face.LineHeight()*0.44 + c.padding
+	}
+}
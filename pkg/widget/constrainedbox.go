@@ -0,0 +1,140 @@
+package widget
+
+import (
+	"lol.mleku.dev/chk"
+)
+
+// ConstrainedBoxWidget replaces the minimum and maximum size its child
+// reports, tightening them to cap a widget that would take all the space it
+// is given or to hold one at a size, or loosening them to let a widget
+// shrink below its natural size. The child is laid out at the size offered
+// clamped to the new limits, at the top left of the widget.
+type ConstrainedBoxWidget struct {
+	Base
+	child Widget
+	// limits holds the sizes set, negative where the child's own apply
+	limits Constraints
+	// size is the child's size from the last layout
+	size Size
+}
+
+// ConstrainedBox creates a new constrained box reporting the child's own
+// constraints until limits are set with Min and Max
+func ConstrainedBox(child Widget) *ConstrainedBoxWidget {
+	c := &ConstrainedBoxWidget{
+		child:  child,
+		limits: NewFlexConstraints(-1, -1, -1, -1),
+	}
+	adopt(c, child)
+	return c
+}
+
+// Min sets the minimum width and height, negative to keep the child's, and
+// returns the box for chaining
+func (c *ConstrainedBoxWidget) Min(width, height float32) *ConstrainedBoxWidget {
+	c.limits.MinWidth, c.limits.MinHeight = width, height
+	c.MarkNeedsLayout()
+	return c
+}
+
+// Max sets the maximum width and height, negative to keep the child's, and
+// returns the box for chaining
+func (c *ConstrainedBoxWidget) Max(width, height float32) *ConstrainedBoxWidget {
+	c.limits.MaxWidth, c.limits.MaxHeight = width, height
+	c.MarkNeedsLayout()
+	return c
+}
+
+// Tight holds the child at a size and returns the box for chaining
+func (c *ConstrainedBoxWidget) Tight(width, height float32) *ConstrainedBoxWidget {
+	return c.Min(width, height).Max(width, height)
+}
+
+// GetConstraints returns the limits set, and the child's constraints where
+// they are not. A limit of the child's that conflicts with one set gives way
+// to it.
+func (c *ConstrainedBoxWidget) GetConstraints() (cc Constraints) {
+	cc = NewFlexConstraints(0, 0, 1e9, 1e9)
+	if c.child != nil {
+		cc = c.child.GetConstraints()
+	}
+	cc.MinWidth, cc.MaxWidth = limit(cc.MinWidth, cc.MaxWidth, c.limits.MinWidth, c.limits.MaxWidth)
+	cc.MinHeight, cc.MaxHeight = limit(cc.MinHeight, cc.MaxHeight, c.limits.MinHeight, c.limits.MaxHeight)
+	return
+}
+
+// Measure returns the size the child measures within the limits
+func (c *ConstrainedBoxWidget) Measure(constraints Constraints) Size {
+	cc := c.GetConstraints()
+	constraints.MaxWidth = min(constraints.MaxWidth, cc.MaxWidth)
+	constraints.MaxHeight = min(constraints.MaxHeight, cc.MaxHeight)
+	m := measure(c.child, constraints)
+	return Size{
+		Width:  min(max(m.Width, cc.MinWidth), cc.MaxWidth),
+		Height: min(max(m.Height, cc.MinHeight), cc.MaxHeight),
+	}
+}
+
+// limit replaces a minimum and maximum with those set, which are negative
+// when unset, keeping the minimum at most the maximum
+func limit(lo, hi, setLo, setHi float32) (float32, float32) {
+	if setLo >= 0 {
+		lo = setLo
+	}
+	if setHi >= 0 {
+		hi = setHi
+	}
+	if lo > hi {
+		if setLo >= 0 {
+			hi = lo
+		} else {
+			lo = hi
+		}
+	}
+	return lo, hi
+}
+
+// Layout implements the Widget interface for ConstrainedBoxWidget
+func (c *ConstrainedBoxWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !c.NeedsLayout(ctx, constraints) {
+		return c.CachedSize(), nil
+	}
+	cc := c.GetConstraints()
+	size = Size{
+		Width:  min(max(constraints.MaxWidth, cc.MinWidth), cc.MaxWidth),
+		Height: min(max(constraints.MaxHeight, cc.MinHeight), cc.MaxHeight),
+	}
+	c.size = size
+	if c.child != nil {
+		if _, err = c.child.Layout(ctx, NewRigidConstraints(size.Width, size.Height)); chk.E(err) {
+			return
+		}
+	}
+	c.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for ConstrainedBoxWidget
+func (c *ConstrainedBoxWidget) Paint(ctx *Context, box *Box) (err error) {
+	if c.child == nil {
+		return
+	}
+	return paintChild(ctx, c.child, c.childBox(box))
+}
+
+// HandleEvent implements the Widget interface for ConstrainedBoxWidget
+func (c *ConstrainedBoxWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if c.child == nil {
+		return false
+	}
+	return routeEvent(ctx, c.child, c.childBox(box), ev)
+}
+
+// childBox returns the child's box at the widget's position
+func (c *ConstrainedBoxWidget) childBox(box *Box) *Box {
+	return &Box{
+		Position:    box.Position,
+		Size:        c.size,
+		Constraints: c.child.GetConstraints(),
+	}
+}
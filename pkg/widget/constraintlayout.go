@@ -0,0 +1,377 @@
+package widget
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/solver"
+	"lol.mleku.dev/chk"
+)
+
+// Edge is a line of a box that anchors attach to: its sides or its centre
+// lines
+type Edge int
+
+const (
+	EdgeLeft Edge = iota
+	EdgeRight
+	EdgeCenterX
+	EdgeTop
+	EdgeBottom
+	EdgeCenterY
+)
+
+// horizontal reports whether the edge places a box across, along the x axis
+func (e Edge) horizontal() bool {
+	return e <= EdgeCenterX
+}
+
+// String returns the name of the edge
+func (e Edge) String() string {
+	return [...]string{"left", "right", "centerX", "top", "bottom", "centerY"}[e]
+}
+
+// positionStrength is how strongly an unattached child stays at the top
+// left, weaker than its preference for the size it measures so attaching one
+// side moves it rather than stretching it
+const positionStrength = solver.Weak / 2
+
+// Anchor is a line another edge can be attached to: an edge of a child or of
+// the layout, or a guideline across the layout
+type Anchor struct {
+	// child is the child whose edge this is, nil for the layout's own edges
+	// and guidelines
+	child *ConstrainedChild
+	edge  Edge
+	// guide is set for a guideline at a fraction of the layout's width or
+	// height plus an offset
+	guide            bool
+	fraction, offset float32
+}
+
+// anchorRule relates an edge of a child to an anchor
+type anchorRule struct {
+	edge     Edge
+	relation solver.Relation
+	to       Anchor
+	margin   float32
+}
+
+// ConstrainedChild is a child of a constraint layout with the rules placing it
+type ConstrainedChild struct {
+	layout                   *ConstraintLayoutWidget
+	widget                   Widget
+	left, top, right, bottom *solver.Variable
+	rules                    []anchorRule
+	// width and height fix the child's size when set
+	width, height float32
+	// ratio fixes the child's width to its height when set
+	ratio float32
+}
+
+// ConstraintLayoutWidget places its children by attaching their edges to
+// the edges of other children, of the layout and of guidelines, solving
+// the rules together so forms can line up their labels and dashboards can
+// relate panels that no nesting of rows and columns would. Each child keeps
+// the size it measures unless its rules stretch it, and sits at the top left
+// unless they move it. Rules that conflict are reported when the layout is
+// laid out and left out.
+type ConstraintLayoutWidget struct {
+	Base
+	children    []*ConstrainedChild
+	constraints Constraints
+	// boxes holds the child boxes from the last layout, relative to the layout
+	boxes []Box
+}
+
+// ConstraintLayout creates a new constraint layout with no children.
+// If no constraints are provided, uses default flexible constraints (0, 0, 1e9, 1e9).
+func ConstraintLayout(constraints ...Constraints) *ConstraintLayoutWidget {
+	var c Constraints
+	if len(constraints) > 0 {
+		c = constraints[0]
+	} else {
+		c = NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return &ConstraintLayoutWidget{constraints: c}
+}
+
+// Add adds a child, painted over those added before it, and returns it to
+// attach its edges
+func (l *ConstraintLayoutWidget) Add(child Widget) *ConstrainedChild {
+	n := len(l.children)
+	c := &ConstrainedChild{
+		layout: l,
+		widget: child,
+		left:   solver.NewVariable(fmt.Sprintf("child%d.left", n)),
+		top:    solver.NewVariable(fmt.Sprintf("child%d.top", n)),
+		right:  solver.NewVariable(fmt.Sprintf("child%d.right", n)),
+		bottom: solver.NewVariable(fmt.Sprintf("child%d.bottom", n)),
+	}
+	l.children = append(l.children, c)
+	adopt(l, child)
+	return c
+}
+
+// Anchor returns an edge of the layout
+func (l *ConstraintLayoutWidget) Anchor(edge Edge) Anchor {
+	return Anchor{edge: edge}
+}
+
+// GuideX returns a vertical guideline at a fraction of the layout's width
+// plus an offset, for left, right and centerX edges to attach to
+func (l *ConstraintLayoutWidget) GuideX(fraction, offset float32) Anchor {
+	return Anchor{edge: EdgeCenterX, guide: true, fraction: fraction, offset: offset}
+}
+
+// GuideY returns a horizontal guideline at a fraction of the layout's
+// height plus an offset, for top, bottom and centerY edges to attach to
+func (l *ConstraintLayoutWidget) GuideY(fraction, offset float32) Anchor {
+	return Anchor{edge: EdgeCenterY, guide: true, fraction: fraction, offset: offset}
+}
+
+// Widget returns the child's widget
+func (c *ConstrainedChild) Widget() Widget {
+	return c.widget
+}
+
+// Anchor returns an edge of the child for other children to attach to
+func (c *ConstrainedChild) Anchor(edge Edge) Anchor {
+	return Anchor{child: c, edge: edge}
+}
+
+// Attach puts an edge of the child on an anchor across the same axis, a
+// margin inside it: right of it for left edges, left of it for right edges,
+// below it for top edges, above it for bottom edges, and right of or below
+// it for centre lines. It returns the child for chaining.
+func (c *ConstrainedChild) Attach(edge Edge, to Anchor, margin float32) *ConstrainedChild {
+	return c.rule(edge, solver.Equal, to, margin)
+}
+
+// AtLeast keeps an edge of the child at least a margin inside an anchor, on
+// the side Attach would put it, and otherwise as close to it as the rules
+// allow, so a child kept inside several anchors clears them all, such as
+// inputs beside the widest of a column of labels. It returns the child for
+// chaining.
+func (c *ConstrainedChild) AtLeast(edge Edge, to Anchor, margin float32) *ConstrainedChild {
+	relation := solver.GreaterOrEqual
+	if edge == EdgeRight || edge == EdgeBottom {
+		relation = solver.LessOrEqual
+	}
+	return c.rule(edge, relation, to, margin)
+}
+
+// Width fixes the child's width, zero for its minimum width, and returns the
+// child for chaining
+func (c *ConstrainedChild) Width(width float32) *ConstrainedChild {
+	c.width = width
+	c.layout.MarkNeedsLayout()
+	return c
+}
+
+// Height fixes the child's height, zero for its minimum height, and returns
+// the child for chaining
+func (c *ConstrainedChild) Height(height float32) *ConstrainedChild {
+	c.height = height
+	c.layout.MarkNeedsLayout()
+	return c
+}
+
+// Ratio keeps the child's width a multiple of its height, zero for none, and
+// returns the child for chaining
+func (c *ConstrainedChild) Ratio(ratio float32) *ConstrainedChild {
+	c.ratio = ratio
+	c.layout.MarkNeedsLayout()
+	return c
+}
+
+// rule adds a rule relating an edge to an anchor
+func (c *ConstrainedChild) rule(edge Edge, relation solver.Relation, to Anchor, margin float32) *ConstrainedChild {
+	c.rules = append(c.rules, anchorRule{edge: edge, relation: relation, to: to, margin: margin})
+	c.layout.MarkNeedsLayout()
+	return c
+}
+
+// edge returns the expression of one of the child's edges
+func (c *ConstrainedChild) edge(edge Edge) solver.Expression {
+	switch edge {
+	case EdgeLeft:
+		return solver.Var(c.left)
+	case EdgeRight:
+		return solver.Var(c.right)
+	case EdgeCenterX:
+		return solver.Var(c.left).Plus(solver.Var(c.right)).Times(0.5)
+	case EdgeTop:
+		return solver.Var(c.top)
+	case EdgeBottom:
+		return solver.Var(c.bottom)
+	default:
+		return solver.Var(c.top).Plus(solver.Var(c.bottom)).Times(0.5)
+	}
+}
+
+// GetConstraints returns the layout's constraints
+func (l *ConstraintLayoutWidget) GetConstraints() Constraints {
+	return l.constraints
+}
+
+// Measure returns the minimum size of the layout, whose children fit
+// whatever space it is given
+func (l *ConstraintLayoutWidget) Measure(constraints Constraints) Size {
+	return minSize(l.GetConstraints())
+}
+
+// Layout implements the Widget interface for ConstraintLayoutWidget. The
+// layout fills the space it is given, or when that is unbounded, encloses
+// its children.
+func (l *ConstraintLayoutWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !l.NeedsLayout(ctx, constraints) {
+		return l.CachedSize(), nil
+	}
+	width, height := solver.NewVariable("width"), solver.NewVariable("height")
+	s := solver.New()
+	add := func(c *solver.Constraint) {
+		// A conflicting rule is left out rather than failing the frame
+		if err := s.AddConstraint(c); err != nil {
+			chk.E(err)
+		}
+	}
+	for _, side := range []struct {
+		v        *solver.Variable
+		min, max float32
+	}{{width, constraints.MinWidth, constraints.MaxWidth}, {height, constraints.MinHeight, constraints.MaxHeight}} {
+		add(solver.NewConstraint(solver.Var(side.v), solver.GreaterOrEqual, solver.Const(float64(side.min)), solver.Required))
+		if side.max < 1e9 {
+			add(solver.NewConstraint(solver.Var(side.v), solver.LessOrEqual, solver.Const(float64(side.max)), solver.Required))
+			add(solver.NewConstraint(solver.Var(side.v), solver.Equal, solver.Const(float64(side.max)), solver.Medium))
+		} else {
+			add(solver.NewConstraint(solver.Var(side.v), solver.Equal, solver.Const(0), solver.Weak))
+		}
+	}
+	anchor := func(a Anchor) solver.Expression {
+		switch {
+		case a.child != nil:
+			return a.child.edge(a.edge)
+		case a.guide && a.edge.horizontal():
+			return solver.Var(width).Times(float64(a.fraction)).Add(float64(a.offset))
+		case a.guide:
+			return solver.Var(height).Times(float64(a.fraction)).Add(float64(a.offset))
+		}
+		switch a.edge {
+		case EdgeLeft, EdgeTop:
+			return solver.Const(0)
+		case EdgeRight:
+			return solver.Var(width)
+		case EdgeCenterX:
+			return solver.Var(width).Times(0.5)
+		case EdgeBottom:
+			return solver.Var(height)
+		default:
+			return solver.Var(height).Times(0.5)
+		}
+	}
+	for _, c := range l.children {
+		cc := c.widget.GetConstraints()
+		// Children prefer the size they measure
+		m := c.widget.Measure(constraints)
+		w := solver.Var(c.right).Minus(solver.Var(c.left))
+		h := solver.Var(c.bottom).Minus(solver.Var(c.top))
+		for _, side := range []struct {
+			size       solver.Expression
+			fixed      float32
+			start, end *solver.Variable
+			extent     *solver.Variable
+			min, max   float32
+			natural    float32
+		}{
+			{w, c.width, c.left, c.right, width, cc.MinWidth, cc.MaxWidth, m.Width},
+			{h, c.height, c.top, c.bottom, height, cc.MinHeight, cc.MaxHeight, m.Height},
+		} {
+			add(solver.NewConstraint(side.size, solver.GreaterOrEqual, solver.Const(0), solver.Required))
+			if side.fixed > 0 {
+				add(solver.NewConstraint(side.size, solver.Equal, solver.Const(float64(side.fixed)), solver.Required))
+			}
+			add(solver.NewConstraint(side.size, solver.GreaterOrEqual, solver.Const(float64(side.min)), solver.Strong))
+			if side.max < 1e9 {
+				add(solver.NewConstraint(side.size, solver.LessOrEqual, solver.Const(float64(side.max)), solver.Strong))
+			}
+			add(solver.NewConstraint(side.size, solver.Equal, solver.Const(float64(max(side.natural, side.min))), solver.Weak))
+			// Children stay inside the layout, which grows to enclose them
+			// when its size is unbounded
+			add(solver.NewConstraint(solver.Var(side.start), solver.GreaterOrEqual, solver.Const(0), solver.Strong))
+			add(solver.NewConstraint(solver.Var(side.end), solver.LessOrEqual, solver.Var(side.extent), solver.Strong))
+			add(solver.NewConstraint(solver.Var(side.start), solver.Equal, solver.Const(0), positionStrength))
+		}
+		if c.ratio > 0 {
+			add(solver.NewConstraint(w, solver.Equal, h.Times(float64(c.ratio)), solver.Strong))
+		}
+		for _, r := range c.rules {
+			if r.edge.horizontal() != r.to.edge.horizontal() {
+				chk.E(fmt.Errorf("%w: %s edge attached across a %s edge", errAnchorAxis, r.edge, r.to.edge))
+				continue
+			}
+			margin := float64(r.margin)
+			if r.edge == EdgeRight || r.edge == EdgeBottom {
+				margin = -margin
+			}
+			edge, to := c.edge(r.edge), anchor(r.to).Add(margin)
+			add(solver.NewConstraint(edge, r.relation, to, solver.Required))
+			if r.relation != solver.Equal {
+				add(solver.NewConstraint(edge, solver.Equal, to, solver.Medium))
+			}
+		}
+	}
+	s.UpdateVariables()
+
+	l.boxes = l.boxes[:0]
+	for _, c := range l.children {
+		// Round each edge, so children sharing an edge meet without a gap
+		x0, x1 := math.Round(c.left.Value()), math.Round(c.right.Value())
+		y0, y1 := math.Round(c.top.Value()), math.Round(c.bottom.Value())
+		box := Box{
+			Position:    Point{X: float32(x0), Y: float32(y0)},
+			Size:        Size{Width: float32(x1 - x0), Height: float32(y1 - y0)},
+			Constraints: c.widget.GetConstraints(),
+		}
+		if _, err = c.widget.Layout(ctx, NewRigidConstraints(box.Size.Width, box.Size.Height)); chk.E(err) {
+			return
+		}
+		l.boxes = append(l.boxes, box)
+	}
+	size = Size{Width: float32(math.Round(width.Value())), Height: float32(math.Round(height.Value()))}
+	l.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for ConstraintLayoutWidget.
+// Children are painted in the order they were added.
+func (l *ConstraintLayoutWidget) Paint(ctx *Context, box *Box) (err error) {
+	for i := range l.boxes {
+		if i >= len(l.children) {
+			break
+		}
+		if err = paintChild(ctx, l.children[i].widget, childBox(box, &l.boxes[i])); chk.E(err) {
+			return
+		}
+	}
+	return
+}
+
+// HandleEvent implements the Widget interface for ConstraintLayoutWidget.
+// Later children paint over earlier ones so they receive events first.
+func (l *ConstraintLayoutWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	_, targeted := interfaces.Target(ev)
+	for i := len(l.boxes) - 1; i >= 0; i-- {
+		if i >= len(l.children) {
+			continue
+		}
+		if routeEvent(ctx, l.children[i].widget, childBox(box, &l.boxes[i]), ev) {
+			handled = true
+			if targeted {
+				return
+			}
+		}
+	}
+	return
+}
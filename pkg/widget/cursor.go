@@ -0,0 +1,121 @@
+package widget
+
+import (
+	"github.com/mleku/goo/pkg/interfaces"
+	"lol.mleku.dev/chk"
+)
+
+// CursorProvider is implemented by widgets that change the mouse cursor
+// while it is over them. The topmost widget under the cursor is asked
+// first, then its ancestors until one returns a shape.
+type CursorProvider interface {
+	// CursorAt returns the cursor to show at a point over the widget,
+	// CursorDefault to leave it to the widgets containing it
+	CursorAt(p Point) Cursor
+}
+
+// Cursor returns the shape of the mouse cursor for the window to show, that
+// of the widgets under it. A drag over a place that does not accept its
+// payload shows that it cannot be dropped there. It is the default cursor
+// while the mouse is outside the window.
+func (r *RootWidget) Cursor() Cursor {
+	if !r.pointerIn {
+		return interfaces.CursorDefault
+	}
+	if d := r.drag; d != nil && d.target != nil && !d.accepted {
+		return interfaces.CursorNotAllowed
+	}
+	var w Widget = r.HitTest(r.pointer)
+	for w != nil {
+		if c, ok := w.(CursorProvider); ok {
+			if cursor := c.CursorAt(r.pointer); cursor != interfaces.CursorDefault {
+				return cursor
+			}
+		}
+		parented, ok := w.(interface{ Parent() Widget })
+		if !ok {
+			break
+		}
+		w = parented.Parent()
+	}
+	return interfaces.CursorDefault
+}
+
+// trackPointer follows the mouse for choosing the cursor shape
+func (r *RootWidget) trackPointer(ev Event) {
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		r.pointer, r.pointerIn = e.Position, true
+	case interfaces.MouseButtonEvent:
+		r.pointer, r.pointerIn = e.Position, true
+	case interfaces.CursorLeaveEvent:
+		r.pointerIn = false
+	}
+}
+
+// CursorWidget shows a cursor shape while the mouse is over its child,
+// unless a widget inside the child asks for another
+type CursorWidget struct {
+	Base
+	child  Widget
+	cursor Cursor
+}
+
+// WithCursor creates a new widget showing a cursor shape over the child
+func WithCursor(child Widget, cursor Cursor) *CursorWidget {
+	c := &CursorWidget{child: child, cursor: cursor}
+	adopt(c, child)
+	return c
+}
+
+// SetCursor changes the cursor shown over the child
+func (c *CursorWidget) SetCursor(cursor Cursor) {
+	c.cursor = cursor
+}
+
+// CursorAt implements CursorProvider for CursorWidget
+func (c *CursorWidget) CursorAt(p Point) Cursor {
+	return c.cursor
+}
+
+// GetConstraints returns the child's constraints
+func (c *CursorWidget) GetConstraints() Constraints {
+	if c.child == nil {
+		return NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return c.child.GetConstraints()
+}
+
+// Measure returns the size the child measures
+func (c *CursorWidget) Measure(constraints Constraints) Size {
+	return measure(c.child, constraints)
+}
+
+// Layout implements the Widget interface for CursorWidget; the child is laid
+// out in the widget's box
+func (c *CursorWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !c.NeedsLayout(ctx, constraints) {
+		return c.CachedSize(), nil
+	}
+	if size, err = layoutInset(ctx, c.child, Insets{}, constraints); chk.E(err) {
+		return
+	}
+	c.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for CursorWidget
+func (c *CursorWidget) Paint(ctx *Context, box *Box) (err error) {
+	if c.child == nil {
+		return
+	}
+	return paintChild(ctx, c.child, box)
+}
+
+// HandleEvent implements the Widget interface for CursorWidget
+func (c *CursorWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if c.child == nil {
+		return false
+	}
+	return routeEvent(ctx, c.child, box, ev)
+}
@@ -0,0 +1,198 @@
+package widget
+
+import (
+	"math"
+	"time"
+
+	"lol.mleku.dev/chk"
+)
+
+// maxDamageRegions is the number of separate regions repainted in one frame
+// before they are merged into their bounding rect
+const maxDamageRegions = 8
+
+// Invalidate schedules a region of the canvas to be repainted on the next frame
+func (r *RootWidget) Invalidate(rect Rect) {
+	if rect.Empty() || r.fullDamage {
+		return
+	}
+	r.damage = append(r.damage, rect)
+}
+
+// InvalidateAll schedules the whole canvas to be repainted on the next frame
+func (r *RootWidget) InvalidateAll() {
+	r.fullDamage = true
+	r.damage = r.damage[:0]
+}
+
+// MarkNeedsPaint repaints the whole canvas, the root's painted region
+func (r *RootWidget) MarkNeedsPaint() {
+	r.InvalidateAll()
+}
+
+// addDamage implements damageSink for widgets marking themselves dirty
+func (r *RootWidget) addDamage(rect Rect) {
+	r.Invalidate(rect)
+}
+
+// Render lays out the tree if anything changed since the last frame and
+// repaints the damaged regions of the canvas described by box. Regions that
+// were not damaged are left untouched, so the canvas must preserve its
+// contents between frames. It reports whether anything was painted.
+func (r *RootWidget) Render(ctx *Context, box *Box) (painted bool, err error) {
+	r.beginPacing(ctx)
+	painted, err = r.render(ctx, box)
+	r.endPacing(ctx)
+	return
+}
+
+// render lays out and repaints the tree for Render
+func (r *RootWidget) render(ctx *Context, box *Box) (painted bool, err error) {
+	ctx = r.themed(ctx)
+	start := time.Now()
+	phase := traceRegion("layout", nil)
+	if _, err = r.Layout(ctx, NewConstraintsNoPos(0, 0, box.Size.Width, box.Size.Height)); chk.E(err) {
+		phase.End()
+		return
+	}
+	r.runTimers(ctx)
+	err = r.layoutPopups(ctx)
+	phase.End()
+	if chk.E(err) {
+		return
+	}
+	if t, ok := r.focused.(ticker); ok {
+		t.tick(ctx)
+	}
+	r.refreshSources()
+	start = r.paced(JankLayout, start)
+	canvas := box.Rect()
+	r.undecorate()
+	var regions []Rect
+	if r.fullDamage {
+		regions = []Rect{canvas}
+	} else {
+		regions = r.backdropDamage(mergeDamage(r.damage, canvas), canvas)
+	}
+	r.fullDamage = false
+	r.damage = r.damage[:0]
+	// Forget the regions of popups that closed
+	r.hits.DropLayers(len(r.popups) + 1)
+
+	phase = traceRegion("paint", nil)
+	defer phase.End()
+	list := ctx.DrawList
+	commands, vertices := len(list.Commands), len(list.Vertices)
+	r.beginProfile()
+	for _, region := range regions {
+		// Clear the region to the background before repainting it
+		list.PushClip(region.X, region.Y, region.Width, region.Height)
+		list.Clear(r.clearColor.or(themeOf(ctx).Background))
+		if b := themeOf(ctx).BackgroundBrush; b.IsSet() && !r.clearColor.set {
+			list.FillRoundRect(canvas.X, canvas.Y, canvas.Width, canvas.Height, 0, b)
+		}
+
+		// The widgets painted in the region register their regions again
+		r.hits.Repaint(region)
+		regionCtx := childContext(ctx, box)
+		regionCtx.Clip = region
+		err = r.Paint(regionCtx, box)
+		list.PopClip()
+		if chk.E(err) {
+			return
+		}
+		painted = true
+	}
+	if painted {
+		r.endProfile(list, len(regions), canvas, commands, vertices)
+	}
+	if r.trackState() {
+		// Widgets restored as they were first shown are drawn again as
+		// restored before the frame is presented
+		r.paced(JankPaint, start)
+		return r.render(ctx, box)
+	}
+	if r.decorate(ctx, canvas, len(list.Commands)-commands) {
+		painted = true
+	}
+	if painted {
+		r.syncAccess(canvas)
+	}
+	r.paced(JankPaint, start)
+	return
+}
+
+// mergeDamage clips damaged rects to the canvas and merges overlapping ones.
+// When there are too many regions they are replaced by their bounding rect.
+func mergeDamage(damage []Rect, canvas Rect) (regions []Rect) {
+	for _, d := range damage {
+		if d = pixelBounds(d).Intersect(canvas); d.Empty() {
+			continue
+		}
+		// Absorb every region the new rect overlaps, repeating since the
+		// grown rect may now overlap regions it missed before
+		for merged := true; merged; {
+			merged = false
+			for i := 0; i < len(regions); i++ {
+				if regions[i].Overlaps(d) {
+					d = d.Union(regions[i])
+					regions = append(regions[:i], regions[i+1:]...)
+					merged = true
+					i--
+				}
+			}
+		}
+		regions = append(regions, d)
+	}
+	if len(regions) > maxDamageRegions {
+		var bounds Rect
+		for _, region := range regions {
+			bounds = bounds.Union(region)
+		}
+		regions = []Rect{bounds}
+	}
+	return
+}
+
+// backdropSampler is implemented by widgets drawing what was painted behind
+// them, which must be repainted along with it
+type backdropSampler interface {
+	// backdropReach returns how far beyond its box the widget samples
+	backdropReach() float32
+}
+
+// backdropDamage grows the damaged regions to repaint every widget sampling
+// pixels behind it that lie in them, with all it samples, which would
+// otherwise keep showing what was behind before or sample its own output
+func (r *RootWidget) backdropDamage(regions []Rect, canvas Rect) []Rect {
+	for grown := true; grown; {
+		grown = false
+		for _, h := range r.hits.Regions() {
+			s, ok := h.Target.(backdropSampler)
+			if !ok || h.Rect.Empty() {
+				continue
+			}
+			reach := s.backdropReach()
+			area := Rect{X: h.Rect.X - reach, Y: h.Rect.Y - reach, Width: h.Rect.Width + 2*reach, Height: h.Rect.Height + 2*reach}
+			whole := pixelBounds(area).Intersect(canvas)
+			for _, region := range regions {
+				if region.Overlaps(whole) && !region.Covers(whole) {
+					regions = mergeDamage(append(regions, whole), canvas)
+					grown = true
+					break
+				}
+			}
+		}
+	}
+	return regions
+}
+
+// pixelBounds expands a rect outwards to whole pixels so scissoring does not
+// leave partially covered edge pixels stale
+func pixelBounds(r Rect) Rect {
+	x0 := float32(math.Floor(float64(r.X)))
+	y0 := float32(math.Floor(float64(r.Y)))
+	x1 := float32(math.Ceil(float64(r.X + r.Width)))
+	y1 := float32(math.Ceil(float64(r.Y + r.Height)))
+	return Rect{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}
+}
@@ -0,0 +1,75 @@
+package widget
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMergeDamage(t *testing.T) {
+	canvas := Rect{Width: 100, Height: 100}
+	many := make([]Rect, maxDamageRegions+1)
+	for i := range many {
+		many[i] = Rect{X: float32(10 * i), Y: float32(10 * i), Width: 5, Height: 5}
+	}
+	tests := []struct {
+		name   string
+		damage []Rect
+		want   []Rect
+	}{
+		{"none", nil, nil},
+		{"separate", []Rect{{X: 0, Y: 0, Width: 10, Height: 10}, {X: 50, Y: 50, Width: 10, Height: 10}},
+			[]Rect{{X: 0, Y: 0, Width: 10, Height: 10}, {X: 50, Y: 50, Width: 10, Height: 10}}},
+		{"overlapping", []Rect{{X: 0, Y: 0, Width: 10, Height: 10}, {X: 5, Y: 5, Width: 10, Height: 10}},
+			[]Rect{{X: 0, Y: 0, Width: 15, Height: 15}}},
+		{"bridged", []Rect{
+			{X: 0, Y: 0, Width: 10, Height: 10},
+			{X: 20, Y: 0, Width: 10, Height: 10},
+			{X: 5, Y: 0, Width: 20, Height: 5},
+		}, []Rect{{X: 0, Y: 0, Width: 30, Height: 10}}},
+		{"clipped to the canvas", []Rect{{X: -10, Y: 90, Width: 20, Height: 20}},
+			[]Rect{{X: 0, Y: 90, Width: 10, Height: 10}}},
+		{"outside the canvas", []Rect{{X: 200, Y: 0, Width: 10, Height: 10}}, nil},
+		{"whole pixels", []Rect{{X: 1.5, Y: 2.25, Width: 3, Height: 1}},
+			[]Rect{{X: 1, Y: 2, Width: 4, Height: 2}}},
+		{"too many", many, []Rect{{X: 0, Y: 0, Width: 85, Height: 85}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeDamage(tt.damage, canvas); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPixelBounds(t *testing.T) {
+	tests := []struct {
+		in, want Rect
+	}{
+		{Rect{X: 1, Y: 2, Width: 3, Height: 4}, Rect{X: 1, Y: 2, Width: 3, Height: 4}},
+		{Rect{X: 0.5, Y: 0.5, Width: 1, Height: 1}, Rect{X: 0, Y: 0, Width: 2, Height: 2}},
+		{Rect{X: -0.5, Y: 1.9, Width: 0.2, Height: 0.2}, Rect{X: -1, Y: 1, Width: 1, Height: 2}},
+	}
+	for _, tt := range tests {
+		if got := pixelBounds(tt.in); got != tt.want {
+			t.Errorf("%v: got %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	r := Root(nil)
+	r.fullDamage = false
+	a, b := Rect{Width: 10, Height: 10}, Rect{X: 20, Width: 10, Height: 10}
+	r.Invalidate(a)
+	r.Invalidate(Rect{})
+	r.Invalidate(b)
+	if want := []Rect{a, b}; !slices.Equal(r.damage, want) {
+		t.Errorf("damage %v, want %v", r.damage, want)
+	}
+	r.InvalidateAll()
+	r.Invalidate(a)
+	if !r.fullDamage || len(r.damage) != 0 {
+		t.Errorf("after InvalidateAll: full %v damage %v, want only full damage", r.fullDamage, r.damage)
+	}
+}
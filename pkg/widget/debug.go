@@ -0,0 +1,320 @@
+package widget
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/render"
+	"github.com/mleku/goo/pkg/text"
+)
+
+const (
+	// debugKey shows and hides the debug overlay
+	debugKey = interfaces.KeyF12
+	// debugTextSize is the size of the overlay's text
+	debugTextSize = 12
+	// debugPadding is the space around the overlay's panels and their text
+	debugPadding = 6
+	// debugWidth is the width of the statistics panel
+	debugWidth = 220
+	// debugGraphHeight is the height of the frame time graph, which spans
+	// frame times up to debugGraphRange
+	debugGraphHeight = 40
+	debugGraphRange  = 50 * time.Millisecond
+	// debugBudget is the time a frame has at 60 frames a second, marked on
+	// the graph
+	debugBudget = time.Second / 60
+	// debugBar is the width of each frame's bar in the graph
+	debugBar = 2
+)
+
+var (
+	// debugBackground is the color of the overlay's panels
+	debugBackground = [4]float32{0, 0, 0, 0.75}
+	// debugText is the color of the overlay's text
+	debugText = [4]float32{1, 1, 1, 1}
+	// debugFast and debugSlow color the frames within and over the budget
+	debugFast = [4]float32{0.3, 0.85, 0.4, 1}
+	debugSlow = [4]float32{0.95, 0.3, 0.3, 1}
+	// debugOutline outlines the widget under the cursor
+	debugOutline = [4]float32{1, 0, 1, 1}
+	// highlightOutline outlines the highlighted widget
+	highlightOutline = [4]float32{0.2, 0.6, 1, 1}
+)
+
+// debugOverlay is the state of a root's debug overlay
+type debugOverlay struct {
+	font  *text.Font
+	shown bool
+}
+
+// Debug enables the debug overlay, drawn with text in a font, and returns
+// the root for chaining. F12 shows and hides it. The overlay shows the frame
+// rate and a graph of frame times when the context has frame statistics,
+// how many widgets were on screen and how many draw calls the last frame
+// took, and outlines the widget under the cursor with its constraints and
+// the containers whose children overflow them. While the root is profiling
+// it also shows the costliest widgets and the overdraw heatmap, and while
+// it monitors the frame pacing the frames that were late.
+func (r *RootWidget) Debug(font *text.Font) *RootWidget {
+	r.debug = &debugOverlay{font: font}
+	return r
+}
+
+// ShowDebug shows or hides the debug overlay, if enabled
+func (r *RootWidget) ShowDebug(show bool) {
+	d := r.debug
+	if d == nil || d.shown == show {
+		return
+	}
+	d.shown = show
+}
+
+// DebugShown reports whether the debug overlay is showing
+func (r *RootWidget) DebugShown() bool {
+	return r.debug != nil && r.debug.shown
+}
+
+// debugEvent toggles the debug overlay with its key, reporting whether it did
+func (r *RootWidget) debugEvent(e interfaces.KeyEvent) bool {
+	if r.debug == nil || e.Key != debugKey || e.Action != interfaces.ActionPress {
+		return false
+	}
+	r.ShowDebug(!r.debug.shown)
+	return true
+}
+
+// Highlight outlines a widget of the tree above everything else, for tools
+// such as the inspector pointing out a widget, until called with another
+// widget or nil
+func (r *RootWidget) Highlight(w Widget) {
+	r.highlight = w
+}
+
+// Highlighted returns the highlighted widget, nil if none
+func (r *RootWidget) Highlighted() Widget {
+	return r.highlight
+}
+
+// reportOverflow records whether a widget's children overflowed it in its
+// last layout, for the debug overlay to outline
+func (r *RootWidget) reportOverflow(w Widget, overflowing bool) {
+	if !overflowing {
+		delete(r.overflowing, w)
+		return
+	}
+	if r.overflowing == nil {
+		r.overflowing = make(map[Widget]struct{})
+	}
+	r.overflowing[w] = struct{}{}
+}
+
+// undecorate repaints the regions the focus ring, debug overlay and
+// highlight were drawn over in the last frame, so they never leave stale
+// pixels behind
+func (r *RootWidget) undecorate() {
+	for _, rect := range r.decorated {
+		r.Invalidate(rect)
+	}
+	r.decorated = r.decorated[:0]
+}
+
+// decorate draws the focus ring, debug overlay and highlight over the
+// canvas, given the number of draw calls the frame took, reporting whether
+// anything was drawn
+func (r *RootWidget) decorate(ctx *Context, canvas Rect, commands int) (painted bool) {
+	if w := r.focused; r.focusVisible && w != nil && rootOf(w) == r {
+		if t, ok := w.(paintTracker); ok {
+			rect := t.lastPaintBox().Rect()
+			rect.X, rect.Y = rect.X-focusRingGap, rect.Y-focusRingGap
+			rect.Width, rect.Height = rect.Width+2*focusRingGap, rect.Height+2*focusRingGap
+			r.outline(ctx, rect, 2, themeOf(ctx).Primary)
+			painted = true
+		}
+	}
+	if w := r.highlight; w != nil && rootOf(w) == r {
+		if t, ok := w.(paintTracker); ok {
+			r.outline(ctx, t.lastPaintBox().Rect(), 2, highlightOutline)
+			painted = true
+		}
+	}
+	if r.DebugShown() {
+		for w := range r.overflowing {
+			if rootOf(w) != r {
+				// Taken out of the tree since it overflowed
+				delete(r.overflowing, w)
+				continue
+			}
+			if t, ok := w.(paintTracker); ok {
+				r.outline(ctx, t.lastPaintBox().Rect(), 2, debugSlow)
+			}
+		}
+		r.paintDebug(ctx, canvas, commands)
+		painted = true
+	}
+	return
+}
+
+// outline draws the outline of a rect over the canvas
+func (r *RootWidget) outline(ctx *Context, rect Rect, width float32, color [4]float32) {
+	ctx.DrawList.RoundRectStroke(rect.X, rect.Y, rect.Width, rect.Height, 0, width, color)
+	// Repaint only the outline's edges, which must not overlap or they
+	// would merge into the whole rect
+	x, y, w, h, e := rect.X, rect.Y, rect.Width, rect.Height, float32(math.Ceil(float64(width)))
+	r.decorated = append(r.decorated,
+		Rect{X: x - e, Y: y - e, Width: w + 2*e, Height: 2 * e},
+		Rect{X: x - e, Y: y + h - e, Width: w + 2*e, Height: 2 * e},
+		Rect{X: x - e, Y: y + e, Width: 2 * e, Height: max(h-2*e, 0)},
+		Rect{X: x + w - e, Y: y + e, Width: 2 * e, Height: max(h-2*e, 0)},
+	)
+}
+
+// paintDebug draws the debug overlay over the canvas, given the number of
+// draw calls the frame took
+func (r *RootWidget) paintDebug(ctx *Context, canvas Rect, commands int) {
+	d := r.debug
+	face := d.font.Face(debugTextSize)
+	list := ctx.DrawList
+	line := face.LineHeight()
+
+	var lines []string
+	if s := ctx.Stats; s != nil {
+		lines = append(lines,
+			fmt.Sprintf("%.0f fps  frame %s", s.FPS, debugMillis(s.FrameTime)),
+			fmt.Sprintf("p50 %s  p95 %s  p99 %s", debugMillis(s.P50), debugMillis(s.P95), debugMillis(s.P99)),
+		)
+	}
+	lines = append(lines, fmt.Sprintf("%d widgets  %d draw calls", len(r.hits.Regions()), commands))
+	if p := r.profile; p != nil {
+		// Under the panel, and drawn again over the whole canvas each frame
+		paintHeatmap(list, p.report.Overdraw)
+		r.decorated = append(r.decorated, canvas)
+		lines = append(lines, p.profileLines()...)
+	}
+	if p := r.pacing; p != nil {
+		lines = append(lines, p.jankLines()...)
+	}
+	height := 2*debugPadding + line*float32(len(lines))
+	if ctx.Stats != nil {
+		height += debugGraphHeight + debugPadding
+	}
+	// Wide enough for the longest line, such as a late frame's
+	width := float32(debugWidth)
+	for _, s := range lines {
+		width = max(width, face.Measure(s)+2*debugPadding)
+	}
+	panel := Rect{
+		X:      canvas.X + canvas.Width - width - debugPadding,
+		Y:      canvas.Y + debugPadding,
+		Width:  width,
+		Height: height,
+	}
+	list.RoundRect(panel.X, panel.Y, panel.Width, panel.Height, 4, debugBackground)
+	y := panel.Y + debugPadding
+	for _, s := range lines {
+		face.Draw(list, panel.X+debugPadding, y+face.Ascent(), s, debugText)
+		y += line
+	}
+	if s := ctx.Stats; s != nil {
+		debugGraph(list, Rect{
+			X:      panel.X + debugPadding,
+			Y:      y + debugPadding,
+			Width:  panel.Width - 2*debugPadding,
+			Height: debugGraphHeight,
+		}, s.Recent)
+	}
+	r.decorated = append(r.decorated, panel)
+
+	if r.pointerIn {
+		r.paintDebugHover(ctx, canvas, face)
+	}
+}
+
+// paintDebugHover outlines the widget under the cursor and labels it with
+// its type, size and constraints
+func (r *RootWidget) paintDebugHover(ctx *Context, canvas Rect, face *text.Face) {
+	target, ok := r.hits.HitTest(r.pointer)
+	if !ok {
+		return
+	}
+	var bounds Rect
+	for _, region := range r.hits.Regions() {
+		if region.Target == target && region.Rect.Contains(r.pointer) {
+			bounds = region.Rect
+			break
+		}
+	}
+	list := ctx.DrawList
+	r.outline(ctx, bounds, 1, debugOutline)
+
+	lines := []string{fmt.Sprintf("%s  %s", typeName(target), debugSize(bounds.Width, bounds.Height))}
+	if w, ok := target.(Widget); ok {
+		c := w.GetConstraints()
+		lines = append(lines, fmt.Sprintf("min %s  max %s",
+			debugSize(c.MinWidth, c.MinHeight), debugSize(c.MaxWidth, c.MaxHeight)))
+	}
+	var width float32
+	for _, s := range lines {
+		width = max(width, face.Measure(s))
+	}
+	label := Rect{
+		Width:  width + 2*debugPadding,
+		Height: face.LineHeight()*float32(len(lines)) + 2*debugPadding,
+	}
+	// Below the widget, or above it when there is no room
+	label.X = max(min(bounds.X, canvas.X+canvas.Width-label.Width), canvas.X)
+	label.Y = bounds.Y + bounds.Height + 2
+	if label.Y+label.Height > canvas.Y+canvas.Height {
+		label.Y = max(bounds.Y-label.Height-2, canvas.Y)
+	}
+	list.RoundRect(label.X, label.Y, label.Width, label.Height, 4, debugBackground)
+	y := label.Y + debugPadding
+	for _, s := range lines {
+		face.Draw(list, label.X+debugPadding, y+face.Ascent(), s, debugText)
+		y += face.LineHeight()
+	}
+	r.decorated = append(r.decorated, label)
+}
+
+// debugGraph draws the most recent frame times that fit a rect as bars,
+// those over the frame budget in red, with a line marking the budget
+func debugGraph(list *render.DrawList, rect Rect, times []time.Duration) {
+	n := min(len(times), int(rect.Width/debugBar))
+	bottom := rect.Y + rect.Height
+	for i, t := range times[len(times)-n:] {
+		h := rect.Height * min(float32(t)/float32(debugGraphRange), 1)
+		color := debugFast
+		if t > debugBudget {
+			color = debugSlow
+		}
+		list.Rect(rect.X+float32(i)*debugBar, bottom-h, debugBar-0.5, h, color)
+	}
+	budget := bottom - rect.Height*float32(debugBudget)/float32(debugGraphRange)
+	list.Rect(rect.X, budget, rect.Width, 1, [4]float32{1, 1, 1, 0.5})
+}
+
+// debugMillis formats a duration in milliseconds
+func debugMillis(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
+
+// typeName returns the name of a value's type without its package, such as
+// LabelWidget
+func typeName(v any) string {
+	name := fmt.Sprintf("%T", v)
+	return strings.TrimLeft(name[strings.LastIndex(name, ".")+1:], "*")
+}
+
+// debugSize formats a size, showing unbounded dimensions as inf
+func debugSize(width, height float32) string {
+	dim := func(v float32) string {
+		if v >= 1e9 {
+			return "inf"
+		}
+		return fmt.Sprintf("%.0f", v)
+	}
+	return dim(width) + "x" + dim(height)
+}
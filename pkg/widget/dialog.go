@@ -0,0 +1,220 @@
+package widget
+
+import (
+	"math"
+
+	"github.com/mleku/goo/pkg/text"
+	"lol.mleku.dev/chk"
+)
+
+const (
+	// dialogButtonWidth and dialogButtonHeight size the buttons of message dialogs
+	dialogButtonWidth  = 88
+	dialogButtonHeight = 30
+	// dialogGap separates the parts of message dialogs
+	dialogGap = 12
+	// dialogMinWidth is the narrowest a message dialog is made
+	dialogMinWidth = 280
+	// dialogElevation is how far dialogs are raised above the window, sizing their shadow
+	dialogElevation = 8
+)
+
+// DialogWidget is a modal surface shown centered over the window. While it
+// is open the rest of the window is dimmed and receives no input, and
+// keyboard focus stays inside the dialog. Escape closes it unless it was
+// made undismissable.
+type DialogWidget struct {
+	Base
+	content Widget
+	padding float32
+	popup   *Popup
+}
+
+// Dialog creates a new dialog showing the content inside a padded surface.
+// The dialog takes the content's minimum size unless a size is set.
+func Dialog(content Widget) *DialogWidget {
+	d := &DialogWidget{
+		content: content,
+		padding: 16,
+	}
+	d.popup = NewPopup(d).Center().Modal(true)
+	adopt(d, content)
+	return d
+}
+
+// Size sets the dialog's size, including the padding, and returns the dialog for chaining
+func (d *DialogWidget) Size(width, height float32) *DialogWidget {
+	d.popup.Size(width, height)
+	return d
+}
+
+// Padding sets the space between the dialog edge and its content and returns the dialog for chaining
+func (d *DialogWidget) Padding(padding float32) *DialogWidget {
+	d.padding = padding
+	d.MarkNeedsLayout()
+	return d
+}
+
+// Dismissable sets whether escape closes the dialog and returns the dialog for chaining
+func (d *DialogWidget) Dismissable(dismissable bool) *DialogWidget {
+	d.popup.Persistent(!dismissable)
+	return d
+}
+
+// OnClose sets the callback invoked when the dialog closes and returns the dialog for chaining
+func (d *DialogWidget) OnClose(fn func()) *DialogWidget {
+	d.popup.OnClose(fn)
+	return d
+}
+
+// Show opens the dialog over the root's window
+func (d *DialogWidget) Show(r *RootWidget) {
+	r.ShowPopup(d.popup)
+}
+
+// Close closes the dialog, restoring focus to the widget that had it before
+func (d *DialogWidget) Close() {
+	d.popup.Close()
+}
+
+// IsOpen reports whether the dialog is showing
+func (d *DialogWidget) IsOpen() bool {
+	return d.popup.IsOpen()
+}
+
+// GetConstraints returns the content's minimum size inside the padding
+func (d *DialogWidget) GetConstraints() Constraints {
+	var c Constraints
+	if d.content != nil {
+		c = d.content.GetConstraints()
+	}
+	return NewFlexConstraints(c.MinWidth+2*d.padding, c.MinHeight+2*d.padding, 1e9, 1e9)
+}
+
+// Measure returns the size the content measures inside the padding
+func (d *DialogWidget) Measure(constraints Constraints) Size {
+	return UniformInsets(d.padding).around(measure(d.content, UniformInsets(d.padding).shrink(constraints)))
+}
+
+// Layout implements the Widget interface for DialogWidget; the content is
+// laid out inside the padding
+func (d *DialogWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !d.NeedsLayout(ctx, constraints) {
+		return d.CachedSize(), nil
+	}
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	if d.content != nil {
+		inner := d.contentBox(&Box{Size: size})
+		if _, err = d.content.Layout(ctx, NewRigidConstraints(inner.Size.Width, inner.Size.Height)); chk.E(err) {
+			return
+		}
+	}
+	d.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for DialogWidget
+func (d *DialogWidget) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	paintElevated(ctx, box, dialogElevation, th.Radius.Large)
+	fillSkinned(ctx, box, th.Skins.Panel, th.Radius.Large, th.Border, th.Surface)
+	if d.content == nil {
+		return
+	}
+	return paintChild(ctx, d.content, d.contentBox(box))
+}
+
+// paintBounds implements overflowing, returning the box along with the
+// dialog's shadow
+func (d *DialogWidget) paintBounds(box *Box) Rect {
+	dy, blur := elevation(dialogElevation)
+	return shadowBounds(box, 0, dy, blur)
+}
+
+// HandleEvent implements the Widget interface for DialogWidget
+func (d *DialogWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if d.content == nil {
+		return false
+	}
+	return routeEvent(ctx, d.content, d.contentBox(box), ev)
+}
+
+// contentBox returns the content's box inside the padding
+func (d *DialogWidget) contentBox(box *Box) *Box {
+	var c Constraints
+	if d.content != nil {
+		c = d.content.GetConstraints()
+	}
+	return NewBox(
+		box.Position.X+d.padding,
+		box.Position.Y+d.padding,
+		max(box.Size.Width-2*d.padding, 0),
+		max(box.Size.Height-2*d.padding, 0),
+		c,
+	)
+}
+
+// Alert creates a dialog showing a title and message with an OK button.
+// The dialog closes when OK is pressed or on escape, then calls onClose,
+// which may be nil.
+func Alert(font *text.Font, title, message string, onClose func()) *DialogWidget {
+	var d *DialogWidget
+	d = messageDialog(font, title, message, dialogButton(font, "OK", func() { d.Close() }))
+	return d.OnClose(onClose)
+}
+
+// Confirm creates a dialog showing a title and message with Cancel and OK
+// buttons. The dialog closes when either is pressed or on escape, then calls
+// onResult, which may be nil, with whether OK was pressed.
+func Confirm(font *text.Font, title, message string, onResult func(ok bool)) *DialogWidget {
+	var d *DialogWidget
+	var ok bool
+	d = messageDialog(font, title, message,
+		dialogButton(font, "Cancel", func() { d.Close() }),
+		dialogButton(font, "OK", func() { ok = true; d.Close() }),
+	)
+	return d.OnClose(func() {
+		if onResult != nil {
+			onResult(ok)
+		}
+		ok = false
+	})
+}
+
+// messageDialog lays out a title, a message and a row of buttons aligned to
+// the end, sizing the dialog to fit them
+func messageDialog(font *text.Font, title, message string, buttons ...Widget) *DialogWidget {
+	titleLabel := Label(font, title).Size(16)
+	messageLabel := Label(font, message)
+	row := Row()
+	for i, b := range buttons {
+		if i > 0 {
+			row.Rigid(NewFixedSize(dialogGap/2, dialogButtonHeight, nil))
+		}
+		row.Rigid(b)
+	}
+	n := float32(len(buttons))
+	rowWidth := n*dialogButtonWidth + (n-1)*dialogGap/2
+
+	tc, mc := titleLabel.GetConstraints(), messageLabel.GetConstraints()
+	width := max(tc.MinWidth, mc.MinWidth, rowWidth, dialogMinWidth)
+	height := tc.MinHeight + dialogGap + mc.MinHeight + 2*dialogGap + dialogButtonHeight
+	content := Column().
+		Rigid(titleLabel).
+		Rigid(NewFixedSize(width, dialogGap, nil)).
+		Rigid(messageLabel).
+		Flex(NewDirectionWidget(NewFixedSize(rowWidth, dialogButtonHeight, row), GravitySouthEast), 1)
+
+	d := Dialog(content)
+	return d.Size(
+		float32(math.Ceil(float64(width+2*d.padding))),
+		float32(math.Ceil(float64(height+2*d.padding))),
+	)
+}
+
+// dialogButton creates a button of a message dialog
+func dialogButton(font *text.Font, label string, onClick func()) Widget {
+	return NewFixedSize(dialogButtonWidth, dialogButtonHeight,
+		Button(Label(font, label).Align(text.AlignCenter)).OnClick(onClick),
+	)
+}
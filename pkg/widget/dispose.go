@@ -0,0 +1,86 @@
+package widget
+
+// Disposer is implemented by widgets holding resources to release when the
+// window showing them closes, such as textures or subscriptions to state
+type Disposer interface {
+	// Dispose releases the widget's resources. Textures freed are uploaded
+	// again if drawn, but state is no longer followed.
+	Dispose()
+}
+
+// Dispose releases the resources of the widgets of the tree, calling
+// Dispose on those painted in the last frame, including the popups, and
+// their ancestors, children before their parents. Pass it to the window's
+// OnClose so it runs while the window's GL context is current.
+func (r *RootWidget) Dispose() {
+	seen := map[Widget]bool{}
+	var order []Widget
+	var visit func(w Widget)
+	visit = func(w Widget) {
+		if w == nil || seen[w] {
+			return
+		}
+		seen[w] = true
+		if p, ok := w.(interface{ Parent() Widget }); ok {
+			visit(p.Parent())
+		}
+		order = append(order, w)
+	}
+	for _, region := range r.hits.Regions() {
+		if w, ok := region.Target.(Widget); ok {
+			visit(w)
+		}
+	}
+	// Parents were visited before their children
+	for i := len(order) - 1; i >= 0; i-- {
+		if d, ok := order[i].(Disposer); ok && order[i] != Widget(r) {
+			d.Dispose()
+		}
+	}
+	if r.unlocalize != nil {
+		r.unlocalize()
+		r.unlocalize = nil
+	}
+}
+
+// Dispose implements Disposer, freeing the GPU copy of the image
+func (i *ImageWidget) Dispose() {
+	i.texture.Dispose()
+}
+
+// Dispose implements Disposer, freeing the GPU copy of the rasterized icon
+func (i *IconWidget) Dispose() {
+	if i.texture != nil {
+		i.texture.Dispose()
+	}
+}
+
+// Dispose implements Disposer, freeing the GPU copy of the cached rendering,
+// which is rendered again if drawn
+func (c *CacheLayerWidget) Dispose() {
+	c.layer.dispose()
+}
+
+// Dispose implements Disposer, freeing the GPU copy of the child's rendering
+func (e *ShaderEffectWidget) Dispose() {
+	e.layer.dispose()
+}
+
+// Dispose implements Disposer, freeing the GPU copy of the rendering,
+// which is rendered again if drawn
+func (v *GLViewportWidget) Dispose() {
+	v.texture.Dispose()
+	v.dirty = true
+}
+
+// Dispose implements Disposer, stopping following the sources
+func (o *ObserveWidget) Dispose() {
+	o.Close()
+}
+
+// Dispose implements Disposer, freeing the GPU copy of the image previewed
+func (b *FileBrowserWidget) Dispose() {
+	if b.preview.texture != nil {
+		b.preview.texture.Dispose()
+	}
+}
@@ -0,0 +1,891 @@
+package widget
+
+import (
+	"math"
+	"slices"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/text"
+	"lol.mleku.dev/chk"
+)
+
+const (
+	// dockGuide is the size of the guides shown while panels are dragged,
+	// dropped on to dock them, and dockGuideGap the space around them
+	dockGuide    = 28
+	dockGuideGap = 6
+	// dockEdgeShare is the share of the dock taken by panels docked along
+	// one of its edges
+	dockEdgeShare = 0.25
+	// dockFrame is the width of the frame around floating stacks, dragged
+	// to resize them, and dockCorner how far from a corner of the frame
+	// both of its edges are dragged
+	dockFrame  = 5
+	dockCorner = 12
+	// dockFloatWidth and dockFloatHeight are the size panels are torn off
+	// at, and dockMinWidth and dockMinHeight the smallest floats are made
+	dockFloatWidth  = 320
+	dockFloatHeight = 240
+	dockMinWidth    = 120
+	dockMinHeight   = 80
+	// dockPaneMin is the smallest size of a pane along a split of the dock
+	dockPaneMin = 40
+	// dockElevation is how far floating stacks are raised above the dock
+	dockElevation = 4
+	// dockGhostOffset is how far the title dragged with the cursor is drawn
+	// from it
+	dockGhostOffset = 12
+)
+
+// DockSide is where panels dock relative to a stack of panels, or to the
+// whole dock
+type DockSide int
+
+const (
+	// DockCenter adds the panels as tabs of the stack
+	DockCenter DockSide = iota
+	// DockLeft places the panels left of the stack
+	DockLeft
+	// DockRight places the panels right of the stack
+	DockRight
+	// DockTop places the panels above the stack
+	DockTop
+	// DockBottom places the panels below the stack
+	DockBottom
+)
+
+// dockPanel is a panel of a dock
+type dockPanel struct {
+	id, title string
+	content   Widget
+	closable  bool
+	// stack is the stack showing the panel, nil while it is closed
+	stack *dockNode
+}
+
+// dockNode is a split of the dock's space in two or, without children, a
+// stack of panels shown as tabs
+type dockNode struct {
+	parent, first, second *dockNode
+	vertical              bool
+	ratio                 float32
+	panels                []*dockPanel
+	selected              int
+	// view shows the stack, and float is set when it floats over the dock
+	view  *dockStackWidget
+	float *dockFloat
+}
+
+// isStack reports whether the node is a stack of panels rather than a split
+func (n *dockNode) isStack() bool {
+	return n.first == nil
+}
+
+// stacks appends the stacks of the tree below the node to list in order
+func (n *dockNode) stacks(list []*dockNode) []*dockNode {
+	switch {
+	case n == nil:
+	case n.isStack():
+		list = append(list, n)
+	default:
+		list = n.second.stacks(n.first.stacks(list))
+	}
+	return list
+}
+
+// dockFloat is a stack floating over the dock, torn off from it
+type dockFloat struct {
+	stack *dockNode
+	// rect is the float's place relative to the dock and its size
+	rect Rect
+}
+
+// dockEdges are the edges of a floating stack dragged to resize it
+type dockEdges struct {
+	left, top, right, bottom bool
+}
+
+// dockZone is where dragged panels dock when dropped
+type dockZone struct {
+	// stack is the stack the panels dock relative to, nil for the dock
+	stack *dockNode
+	side  DockSide
+	// guide is the absolute region of the guide dropped on to dock there,
+	// empty for the strip of a stack, and preview the region the panels
+	// would take
+	guide, preview Rect
+}
+
+// dockDrag is a panel dragged out of its stack, or a floating stack moved
+// whole
+type dockDrag struct {
+	panel *dockPanel
+	float *dockFloat
+	// grab is the cursor's offset from the moved float's top left corner
+	grab Point
+	at   Point
+	// over is the stack under the cursor, whose guides are shown, and zone
+	// where the panels dock when dropped, if over one
+	over   *dockNode
+	zone   dockZone
+	inZone bool
+}
+
+// DockWidget arranges panels like the workspace of an IDE. Panels stack as
+// tabs, and stacks share the dock's space along splitters that are dragged
+// to resize them. Dragging a tab out of its stack shows guides over the
+// stack under the cursor and along the edges of the dock, and dropping it
+// on one docks the panel beside or into that stack, or along that edge of
+// the whole dock, as previewed while over it. Dropped on the strip of tabs
+// of another stack it joins that stack. Dropped anywhere else the panel is
+// torn off into a floating stack, which is moved by dragging its strip of
+// tabs, resized by its frame, and docked again the same way. The
+// arrangement is saved as a DockLayout, to restore it as the user left it.
+type DockWidget struct {
+	Base
+	font *text.Font
+	size float32
+	// closable is the default for panels added from now on
+	closable bool
+	panels   []*dockPanel
+	root     *dockNode
+	// floats holds the floating stacks, the topmost last
+	floats []*dockFloat
+	// tree shows the docked stacks, rebuilt when they are rearranged
+	tree Widget
+	drag *dockDrag
+	// resizing is the float whose edges are dragged, resized from its rect
+	// when the cursor was pressed at resizeAt
+	resizing   *dockFloat
+	edges      dockEdges
+	resizeFrom Rect
+	resizeAt   Point
+
+	onChange func()
+	onClose  func(id string) bool
+	// key is the key the layout is remembered under
+	key string
+}
+
+// Dock creates a new dock without panels, with titles drawn in the given
+// font at 14 pixels
+func Dock(font *text.Font) *DockWidget {
+	return &DockWidget{font: font, size: 14}
+}
+
+// Size sets the pixel size of the titles and returns the dock for chaining
+func (d *DockWidget) Size(size float32) *DockWidget {
+	d.size = size
+	d.rebuild()
+	return d
+}
+
+// Closable sets whether panels added from now on have a close button and
+// returns the dock for chaining
+func (d *DockWidget) Closable(closable bool) *DockWidget {
+	d.closable = closable
+	return d
+}
+
+// OnChange sets the callback invoked when the user rearranges the panels,
+// selects one or moves a splitter, for saving the layout, and returns the
+// dock for chaining
+func (d *DockWidget) OnChange(fn func()) *DockWidget {
+	d.onChange = fn
+	return d
+}
+
+// OnClose sets the callback invoked with the id of a panel whose close
+// button was clicked, before it closes, and returns the dock for chaining.
+// Returning false keeps the panel open.
+func (d *DockWidget) OnClose(fn func(id string) bool) *DockWidget {
+	d.onClose = fn
+	return d
+}
+
+// Add adds a panel showing the content, known by its id, as the last tab of
+// the first docked stack, and returns the dock for chaining. Adding an id
+// again replaces the panel's title and content.
+func (d *DockWidget) Add(id, title string, content Widget) *DockWidget {
+	p := d.panel(id)
+	if p == nil {
+		p = &dockPanel{id: id}
+		d.panels = append(d.panels, p)
+	}
+	p.title, p.content, p.closable = title, content, d.closable
+	if p.stack != nil {
+		d.rebuild()
+		return d
+	}
+	d.place([]*dockPanel{p}, nil, d.firstStack(), DockCenter)
+	return d
+}
+
+// Move docks the panel with the id relative to the stack showing the
+// target panel, or along an edge of the dock when the target is empty.
+// Docking in the center adds it as a tab, which for floating stacks is the
+// only side.
+func (d *DockWidget) Move(id, target string, side DockSide) {
+	p := d.panel(id)
+	if p == nil {
+		return
+	}
+	var stack *dockNode
+	switch {
+	case target != "":
+		t := d.panel(target)
+		if t == nil || t.stack == nil {
+			return
+		}
+		stack = t.stack
+		if stack == p.stack && (side == DockCenter || stack.float != nil || len(stack.panels) == 1) {
+			return
+		}
+	case side == DockCenter:
+		stack = d.firstStack()
+	}
+	d.place([]*dockPanel{p}, p, stack, side)
+}
+
+// Float tears the panel with the id off into a floating stack at a place
+// relative to the dock
+func (d *DockWidget) Float(id string, x, y, width, height float32) {
+	if p := d.panel(id); p != nil {
+		d.float(p, Rect{X: x, Y: y, Width: width, Height: height})
+	}
+}
+
+// Close closes the panel with the id without invoking the close callback.
+// It keeps its content and is opened again by Show.
+func (d *DockWidget) Close(id string) {
+	if p := d.panel(id); p != nil && p.stack != nil {
+		d.detach(p)
+		d.rebuild()
+	}
+}
+
+// Show selects the panel with the id in its stack, raising it when it
+// floats, or opens it again as a tab of the first docked stack when closed
+func (d *DockWidget) Show(id string) {
+	p := d.panel(id)
+	switch {
+	case p == nil:
+	case p.stack == nil:
+		d.place([]*dockPanel{p}, p, d.firstStack(), DockCenter)
+	default:
+		n := p.stack
+		n.selected = slices.Index(n.panels, p)
+		if n.float != nil {
+			d.raise(n.float)
+		}
+		n.view.MarkNeedsLayout()
+	}
+}
+
+// IsOpen reports whether the panel with the id is shown in a stack
+func (d *DockWidget) IsOpen(id string) bool {
+	p := d.panel(id)
+	return p != nil && p.stack != nil
+}
+
+// GetConstraints returns flexible constraints; docks take the space offered
+func (d *DockWidget) GetConstraints() Constraints {
+	return NewFlexConstraints(0, 0, 1e9, 1e9)
+}
+
+// Measure returns the minimum size
+func (d *DockWidget) Measure(constraints Constraints) Size {
+	return minSize(d.GetConstraints())
+}
+
+// Layout implements the Widget interface for DockWidget; docks take all the
+// space offered, sharing it between the docked stacks, and keep floating
+// stacks within it
+func (d *DockWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !d.NeedsLayout(ctx, constraints) {
+		return d.CachedSize(), nil
+	}
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	if d.tree != nil {
+		if _, err = d.tree.Layout(ctx, NewRigidConstraints(size.Width, size.Height)); chk.E(err) {
+			return
+		}
+	}
+	for _, f := range d.floats {
+		f.rect = d.clampFloat(f.rect, size)
+		if _, err = f.stack.view.Layout(ctx, NewRigidConstraints(f.rect.Width-2*dockFrame, f.rect.Height-2*dockFrame)); chk.E(err) {
+			return
+		}
+	}
+	d.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for DockWidget, drawing the floating
+// stacks over the docked ones, and while panels are dragged, where they
+// would dock
+func (d *DockWidget) Paint(ctx *Context, box *Box) (err error) {
+	if d.tree != nil {
+		if err = paintChild(ctx, d.tree, box); chk.E(err) {
+			return
+		}
+	}
+	th := themeOf(ctx)
+	for _, f := range d.floats {
+		fb := d.floatBox(box, f)
+		paintElevated(ctx, fb, dockElevation, th.Radius.Medium)
+		fillBordered(ctx, fb, th.Radius.Medium, th.Border, th.Surface)
+		// The frame is the dock's, for resizing the float, and covers what
+		// lies below it
+		ctx.Hits.Register(d, fb.Rect())
+		if err = paintChild(ctx, f.stack.view, d.innerBox(fb, f)); chk.E(err) {
+			return
+		}
+	}
+	g := d.drag
+	if g == nil {
+		return
+	}
+	list := ctx.DrawList
+	for _, z := range d.zones(g.over) {
+		gb := NewBox(z.guide.X, z.guide.Y, z.guide.Width, z.guide.Height, Constraints{})
+		fill := th.Surface
+		if g.inZone && g.zone.guide == z.guide {
+			fill = th.SurfaceHover
+		}
+		paintElevated(ctx, gb, 2, th.Radius.Small)
+		fillBordered(ctx, gb, th.Radius.Small, th.Border, fill)
+		// Mark the side of the guide
+		inner := Rect{X: z.guide.X + 6, Y: z.guide.Y + 6, Width: z.guide.Width - 12, Height: z.guide.Height - 12}
+		if z.side != DockCenter {
+			inner = sideRect(inner, z.side, 0.5)
+		}
+		list.Rect(inner.X, inner.Y, inner.Width, inner.Height, th.Primary)
+	}
+	if g.inZone {
+		r := g.zone.preview
+		fill, edge := th.Primary, th.Primary
+		fill[3] = 0.25
+		list.Rect(r.X, r.Y, r.Width, r.Height, fill)
+		list.Rect(r.X, r.Y, r.Width, 2, edge)
+		list.Rect(r.X, r.Y+r.Height-2, r.Width, 2, edge)
+		list.Rect(r.X, r.Y, 2, r.Height, edge)
+		list.Rect(r.X+r.Width-2, r.Y, 2, r.Height, edge)
+	}
+	if g.panel != nil {
+		// The title of the panel follows the cursor
+		r := d.ghostRect(g)
+		gb := NewBox(r.X, r.Y, r.Width, r.Height, Constraints{})
+		paintElevated(ctx, gb, dockElevation, th.Radius.Small)
+		fillBordered(ctx, gb, th.Radius.Small, th.Primary, th.Surface)
+		face := d.font.Face(d.size)
+		face.Draw(list, r.X+tabsPadding, r.Y+(r.Height-face.LineHeight())/2+face.Ascent(), g.panel.title, th.Text)
+	}
+	return
+}
+
+// paintBounds implements overflowing, returning the box along with the
+// shadows of the floating stacks and the title dragged with the cursor
+func (d *DockWidget) paintBounds(box *Box) Rect {
+	r := box.Rect()
+	dy, blur := elevation(dockElevation)
+	for _, f := range d.floats {
+		r = r.Union(shadowBounds(d.floatBox(box, f), 0, dy, blur))
+	}
+	if g := d.drag; g != nil && g.panel != nil {
+		gr := d.ghostRect(g)
+		r = r.Union(shadowBounds(NewBox(gr.X, gr.Y, gr.Width, gr.Height, Constraints{}), 0, dy, blur))
+	}
+	return r
+}
+
+// HandleEvent implements the Widget interface for DockWidget. Floating
+// stacks get the events over them before the docked stacks below.
+func (d *DockWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if d.drag != nil || d.resizing != nil {
+		switch e := ev.(type) {
+		case interfaces.MouseMoveEvent:
+			if d.drag != nil {
+				d.dragTo(e.Position)
+			} else {
+				d.resizeTo(e.Position)
+			}
+			return true
+		case interfaces.MouseButtonEvent:
+			if e.Button == interfaces.MouseButtonLeft && e.Action == interfaces.ActionRelease {
+				if d.drag != nil {
+					d.drop()
+				} else {
+					d.resizing = nil
+				}
+				d.changed()
+				return true
+			}
+		}
+	}
+	if at, targeted := interfaces.Target(ev); targeted {
+		for i := len(d.floats) - 1; i >= 0; i-- {
+			f := d.floats[i]
+			fb := d.floatBox(box, f)
+			if !fb.Contains(at) {
+				continue
+			}
+			if e, ok := ev.(interfaces.MouseButtonEvent); ok && e.Action == interfaces.ActionPress {
+				d.raise(f)
+				if edges := d.edgesAt(fb, f, at); e.Button == interfaces.MouseButtonLeft && edges != (dockEdges{}) {
+					d.resizing, d.edges, d.resizeFrom, d.resizeAt = f, edges, f.rect, at
+					return true
+				}
+			}
+			// Floats keep the events over them from the stacks below
+			routeEvent(ctx, f.stack.view, d.innerBox(fb, f), ev)
+			return true
+		}
+		return d.tree != nil && routeEvent(ctx, d.tree, box, ev)
+	}
+	for _, f := range slices.Clone(d.floats) {
+		if routeEvent(ctx, f.stack.view, d.innerBox(d.floatBox(box, f), f), ev) {
+			handled = true
+		}
+	}
+	if d.tree != nil && routeEvent(ctx, d.tree, box, ev) {
+		handled = true
+	}
+	return
+}
+
+// CursorAt implements CursorProvider, showing a resize cursor over the
+// frames of floating stacks and while they are resized
+func (d *DockWidget) CursorAt(p Point) Cursor {
+	edges := d.edges
+	if d.resizing == nil {
+		edges = dockEdges{}
+		for i := len(d.floats) - 1; i >= 0; i-- {
+			f := d.floats[i]
+			if fb := d.floatBox(&d.paintBox, f); fb.Contains(p) {
+				edges = d.edgesAt(fb, f, p)
+				break
+			}
+		}
+	}
+	switch {
+	case edges.left || edges.right:
+		return interfaces.CursorResizeH
+	case edges.top || edges.bottom:
+		return interfaces.CursorResizeV
+	}
+	return interfaces.CursorDefault
+}
+
+// beginDrag starts dragging a panel out of its stack
+func (d *DockWidget) beginDrag(p *dockPanel, at Point) {
+	d.drag = &dockDrag{panel: p}
+	d.dragTo(at)
+}
+
+// beginMove starts moving a floating stack whole, held at a point
+func (d *DockWidget) beginMove(f *dockFloat, held, at Point) {
+	origin := d.paintBox.Position
+	d.drag = &dockDrag{float: f, grab: Point{X: held.X - origin.X - f.rect.X, Y: held.Y - origin.Y - f.rect.Y}}
+	d.dragTo(at)
+}
+
+// dragTo follows the cursor with the dragged panel or float, finding where
+// it would dock
+func (d *DockWidget) dragTo(p Point) {
+	g := d.drag
+	d.invalidate(d.paintBounds(&d.paintBox))
+	g.at = p
+	if f := g.float; f != nil {
+		origin := d.paintBox.Position
+		f.rect = d.clampFloat(Rect{X: p.X - origin.X - g.grab.X, Y: p.Y - origin.Y - g.grab.Y, Width: f.rect.Width, Height: f.rect.Height}, d.CachedSize())
+	}
+	g.over = d.dragTarget(p)
+	g.zone, g.inZone = d.zoneAt(p)
+	d.invalidate(d.paintBounds(&d.paintBox))
+}
+
+// drop docks the dragged panels where they were dropped, or tears a panel
+// dropped elsewhere off into a floating stack
+func (d *DockWidget) drop() {
+	g := d.drag
+	d.invalidate(d.paintBounds(&d.paintBox))
+	d.drag = nil
+	switch {
+	case g.inZone && g.float != nil:
+		n := g.float.stack
+		d.place(slices.Clone(n.panels), n.panels[n.selected], g.zone.stack, g.zone.side)
+	case g.inZone:
+		d.place([]*dockPanel{g.panel}, g.panel, g.zone.stack, g.zone.side)
+	case g.panel != nil:
+		origin := d.paintBox.Position
+		r := Rect{
+			X:      g.at.X - origin.X - dockFloatWidth/4,
+			Y:      g.at.Y - origin.Y - dockFrame - d.stripHeight()/2,
+			Width:  dockFloatWidth,
+			Height: dockFloatHeight,
+		}
+		d.float(g.panel, d.clampFloat(r, d.CachedSize()))
+	}
+}
+
+// resizeTo drags the held edges of a floating stack to the cursor
+func (d *DockWidget) resizeTo(p Point) {
+	r, from := d.resizeFrom, d.resizeFrom
+	dx, dy := p.X-d.resizeAt.X, p.Y-d.resizeAt.Y
+	if d.edges.left {
+		r.Width = max(from.Width-dx, dockMinWidth)
+		r.X = from.X + from.Width - r.Width
+	}
+	if d.edges.right {
+		r.Width = from.Width + dx
+	}
+	if d.edges.top {
+		r.Height = max(from.Height-dy, dockMinHeight)
+		r.Y = from.Y + from.Height - r.Height
+	}
+	if d.edges.bottom {
+		r.Height = from.Height + dy
+	}
+	d.invalidate(d.paintBounds(&d.paintBox))
+	d.resizing.rect = d.clampFloat(r, d.CachedSize())
+	d.MarkNeedsLayout()
+}
+
+// dragTarget returns the stack under a point, floating or docked, leaving
+// out a float being moved
+func (d *DockWidget) dragTarget(p Point) *dockNode {
+	for i := len(d.floats) - 1; i >= 0; i-- {
+		if f := d.floats[i]; f != d.drag.float && d.floatBox(&d.paintBox, f).Contains(p) {
+			return f.stack
+		}
+	}
+	for _, n := range d.root.stacks(nil) {
+		if n.view.paintBox.Contains(p) {
+			return n
+		}
+	}
+	return nil
+}
+
+// zones returns the guides shown while panels are dragged over a stack,
+// with where they would dock: along the edges of the dock, or in the middle
+// of an empty one, and beside or into the stack. Floating stacks only take
+// panels as tabs, and a stack cannot take a panel dragged out of it unless
+// others remain to place it beside.
+func (d *DockWidget) zones(over *dockNode) (zones []dockZone) {
+	box := d.paintBox.Rect()
+	guide := func(x, y float32) Rect {
+		return Rect{X: float32(math.Round(float64(x))), Y: float32(math.Round(float64(y))), Width: dockGuide, Height: dockGuide}
+	}
+	cx, cy := box.X+(box.Width-dockGuide)/2, box.Y+(box.Height-dockGuide)/2
+	if d.root == nil {
+		return []dockZone{{side: DockCenter, guide: guide(cx, cy), preview: box}}
+	}
+	for _, z := range []struct {
+		side DockSide
+		x, y float32
+	}{
+		{DockLeft, box.X + dockGuideGap, cy},
+		{DockRight, box.X + box.Width - dockGuideGap - dockGuide, cy},
+		{DockTop, cx, box.Y + dockGuideGap},
+		{DockBottom, cx, box.Y + box.Height - dockGuideGap - dockGuide},
+	} {
+		zones = append(zones, dockZone{side: z.side, guide: guide(z.x, z.y), preview: sideRect(box, z.side, dockEdgeShare)})
+	}
+	if over == nil {
+		return
+	}
+	var source *dockNode
+	if p := d.drag.panel; p != nil {
+		source = p.stack
+	}
+	r := over.view.paintBox.Rect()
+	if over.float != nil {
+		r = d.floatBox(&d.paintBox, over.float).Rect()
+	}
+	cx, cy = r.X+(r.Width-dockGuide)/2, r.Y+(r.Height-dockGuide)/2
+	if over != source {
+		zones = append(zones, dockZone{stack: over, side: DockCenter, guide: guide(cx, cy), preview: r})
+	}
+	if over.float != nil || (over == source && len(over.panels) == 1) {
+		return
+	}
+	step := float32(dockGuide + dockGuideGap)
+	for _, z := range []struct {
+		side DockSide
+		x, y float32
+	}{
+		{DockLeft, cx - step, cy},
+		{DockRight, cx + step, cy},
+		{DockTop, cx, cy - step},
+		{DockBottom, cx, cy + step},
+	} {
+		zones = append(zones, dockZone{stack: over, side: z.side, guide: guide(z.x, z.y), preview: sideRect(r, z.side, 0.5)})
+	}
+	return
+}
+
+// zoneAt returns where the dragged panels would dock when dropped at a
+// point: the guide under it, or the strip of tabs of another stack
+func (d *DockWidget) zoneAt(p Point) (zone dockZone, ok bool) {
+	over := d.drag.over
+	zones := d.zones(over)
+	for i := len(zones) - 1; i >= 0; i-- {
+		if zones[i].guide.Contains(p) {
+			return zones[i], true
+		}
+	}
+	if over == nil {
+		return
+	}
+	for _, z := range zones {
+		if z.stack == over && z.side == DockCenter && over.view.stripBox(&over.view.paintBox).Contains(p) {
+			z.guide = Rect{}
+			return z, true
+		}
+	}
+	return
+}
+
+// place docks panels relative to a stack, or along an edge of the dock
+// when it is nil, selecting the given panel, or keeping the selection when
+// it is nil
+func (d *DockWidget) place(panels []*dockPanel, selected *dockPanel, target *dockNode, side DockSide) {
+	for _, p := range panels {
+		d.detach(p)
+	}
+	if target != nil && target.float != nil {
+		side = DockCenter
+	}
+	stack := &dockNode{panels: panels}
+	switch {
+	case target == nil && d.root == nil:
+		d.root = stack
+	case target == nil:
+		d.splitAt(d.root, stack, side, dockEdgeShare)
+	case side == DockCenter:
+		target.panels = append(target.panels, panels...)
+		stack = target
+	default:
+		d.splitAt(target, stack, side, 0.5)
+	}
+	for _, p := range panels {
+		p.stack = stack
+	}
+	if selected != nil {
+		stack.selected = slices.Index(stack.panels, selected)
+	}
+	if stack.float != nil {
+		d.raise(stack.float)
+	}
+	d.rebuild()
+}
+
+// float tears a panel off into a floating stack at a place relative to the
+// dock, over the other floats
+func (d *DockWidget) float(p *dockPanel, rect Rect) {
+	d.detach(p)
+	f := &dockFloat{rect: rect}
+	f.stack = &dockNode{panels: []*dockPanel{p}, float: f}
+	p.stack = f.stack
+	d.floats = append(d.floats, f)
+	d.rebuild()
+}
+
+// splitAt splits the space of a node between it and a new stack placed at
+// a side of it, taking a share of the space
+func (d *DockWidget) splitAt(n, stack *dockNode, side DockSide, share float32) {
+	split := &dockNode{vertical: side == DockTop || side == DockBottom}
+	d.replace(n, split)
+	if side == DockLeft || side == DockTop {
+		split.first, split.second, split.ratio = stack, n, share
+	} else {
+		split.first, split.second, split.ratio = n, stack, 1-share
+	}
+	n.parent, stack.parent = split, split
+}
+
+// detach takes a panel out of its stack, removing the stack when it is left
+// empty, its sibling taking the place of their split
+func (d *DockWidget) detach(p *dockPanel) {
+	n := p.stack
+	if n == nil {
+		return
+	}
+	i := slices.Index(n.panels, p)
+	n.panels = slices.Delete(n.panels, i, i+1)
+	p.stack = nil
+	if i < n.selected || n.selected >= len(n.panels) {
+		n.selected = max(n.selected-1, 0)
+	}
+	switch {
+	case len(n.panels) > 0:
+	case n.float != nil:
+		d.floats = slices.DeleteFunc(d.floats, func(f *dockFloat) bool { return f == n.float })
+	case n.parent == nil:
+		d.root = nil
+	default:
+		sibling := n.parent.first
+		if sibling == n {
+			sibling = n.parent.second
+		}
+		d.replace(n.parent, sibling)
+	}
+}
+
+// replace puts a node in the place of another in the tree
+func (d *DockWidget) replace(old, with *dockNode) {
+	with.parent = old.parent
+	switch {
+	case old.parent == nil:
+		d.root = with
+	case old.parent.first == old:
+		old.parent.first = with
+	default:
+		old.parent.second = with
+	}
+}
+
+// rebuild builds the widgets showing the docked stacks again after they
+// were rearranged, and lays everything out again
+func (d *DockWidget) rebuild() {
+	d.tree = d.build(d.root)
+	adopt(d, d.tree)
+	for _, f := range d.floats {
+		adopt(d, d.view(f.stack))
+	}
+}
+
+// build returns the widget showing a node of the tree, splits of the dock
+// becoming split widgets that keep the node's ratio as they are dragged
+func (d *DockWidget) build(n *dockNode) Widget {
+	switch {
+	case n == nil:
+		return nil
+	case n.isStack():
+		return d.view(n)
+	}
+	s := Split(d.build(n.first), d.build(n.second)).Ratio(n.ratio).MinSizes(dockPaneMin, dockPaneMin)
+	if n.vertical {
+		s.Vertical()
+	}
+	s.OnChange(func(ratio, _ float32) {
+		n.ratio = ratio
+		d.changed()
+	})
+	return s
+}
+
+// view returns the widget showing a stack, to be laid out again
+func (d *DockWidget) view(n *dockNode) *dockStackWidget {
+	if n.view == nil {
+		n.view = &dockStackWidget{dock: d, node: n, hot: -1, held: -1, pressedClose: -1}
+	}
+	n.view.MarkNeedsLayout()
+	return n.view
+}
+
+// closePanel closes a panel whose close button was clicked, unless the
+// close callback keeps it open
+func (d *DockWidget) closePanel(p *dockPanel) {
+	if d.onClose != nil && !d.onClose(p.id) {
+		return
+	}
+	d.detach(p)
+	d.rebuild()
+	d.changed()
+}
+
+// raise moves a floating stack over the others
+func (d *DockWidget) raise(f *dockFloat) {
+	if i := slices.Index(d.floats, f); i >= 0 && i < len(d.floats)-1 {
+		d.floats = append(slices.Delete(d.floats, i, i+1), f)
+		d.MarkNeedsPaint()
+	}
+}
+
+// changed invokes the change callback
+func (d *DockWidget) changed() {
+	if d.onChange != nil {
+		d.onChange()
+	}
+}
+
+// panel returns the panel with the id, nil if there is none
+func (d *DockWidget) panel(id string) *dockPanel {
+	for _, p := range d.panels {
+		if p.id == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// firstStack returns the first docked stack, nil if none is docked
+func (d *DockWidget) firstStack() *dockNode {
+	if stacks := d.root.stacks(nil); len(stacks) > 0 {
+		return stacks[0]
+	}
+	return nil
+}
+
+// clampFloat limits the place and size of a floating stack to a dock of
+// the given size
+func (d *DockWidget) clampFloat(r Rect, size Size) Rect {
+	r.Width = max(min(r.Width, size.Width), min(dockMinWidth, size.Width))
+	r.Height = max(min(r.Height, size.Height), min(dockMinHeight, size.Height))
+	r.X = min(max(r.X, 0), size.Width-r.Width)
+	r.Y = min(max(r.Y, 0), size.Height-r.Height)
+	return r
+}
+
+// floatBox returns the absolute box of a floating stack with its frame
+func (d *DockWidget) floatBox(box *Box, f *dockFloat) *Box {
+	return NewBox(box.Position.X+f.rect.X, box.Position.Y+f.rect.Y, f.rect.Width, f.rect.Height, Constraints{})
+}
+
+// innerBox returns the absolute box of a floating stack within its frame
+func (d *DockWidget) innerBox(fb *Box, f *dockFloat) *Box {
+	return NewBox(fb.Position.X+dockFrame, fb.Position.Y+dockFrame,
+		max(fb.Size.Width-2*dockFrame, 0), max(fb.Size.Height-2*dockFrame, 0), f.stack.view.GetConstraints())
+}
+
+// edgesAt returns the edges of a floating stack's frame under a point,
+// both edges near its corners
+func (d *DockWidget) edgesAt(fb *Box, f *dockFloat, p Point) (edges dockEdges) {
+	if d.innerBox(fb, f).Contains(p) {
+		return
+	}
+	x, y := p.X-fb.Position.X, p.Y-fb.Position.Y
+	w, h := fb.Size.Width, fb.Size.Height
+	return dockEdges{left: x < dockCorner, top: y < dockCorner, right: x >= w-dockCorner, bottom: y >= h-dockCorner}
+}
+
+// ghostRect returns the absolute region of the title dragged with the cursor
+func (d *DockWidget) ghostRect(g *dockDrag) Rect {
+	w := float32(math.Ceil(float64(d.font.Face(d.size).Measure(g.panel.title)))) + 2*tabsPadding
+	return Rect{X: g.at.X + dockGhostOffset, Y: g.at.Y + dockGhostOffset, Width: w, Height: d.stripHeight()}
+}
+
+// stripHeight returns the height of the strips of titles of the stacks
+func (d *DockWidget) stripHeight() float32 {
+	return float32(math.Ceil(float64(d.font.Face(d.size).LineHeight()))) + tabsPadding
+}
+
+// sideRect returns the share of a region along one of its sides
+func sideRect(r Rect, side DockSide, share float32) Rect {
+	switch side {
+	case DockLeft:
+		r.Width *= share
+	case DockRight:
+		r.X += r.Width * (1 - share)
+		r.Width *= share
+	case DockTop:
+		r.Height *= share
+	case DockBottom:
+		r.Y += r.Height * (1 - share)
+		r.Height *= share
+	}
+	return r
+}
@@ -0,0 +1,168 @@
+package widget
+
+import "encoding/json"
+
+// DockLayout is the arrangement of a dock's panels, for restoring the dock
+// as the user left it. It encodes to JSON, naming panels by their ids.
+type DockLayout struct {
+	// Root is the tree of docked stacks, nil when no panel is docked
+	Root *DockNode `json:",omitempty"`
+	// Floating holds the floating stacks, the topmost last
+	Floating []DockFloat `json:",omitempty"`
+}
+
+// DockNode is a split of a dock's space in two or, without children, a
+// stack of panels
+type DockNode struct {
+	// Vertical places First above Second rather than beside it, and Ratio
+	// is First's share of the space
+	Vertical      bool      `json:",omitempty"`
+	Ratio         float32   `json:",omitempty"`
+	First, Second *DockNode `json:",omitempty"`
+	// Panels holds the ids of a stack's panels in the order of their tabs,
+	// Selected being the index of the one shown
+	Panels   []string `json:",omitempty"`
+	Selected int      `json:",omitempty"`
+}
+
+// DockFloat is a stack of panels floating over a dock
+type DockFloat struct {
+	Panels   []string
+	Selected int `json:",omitempty"`
+	// X, Y, Width and Height are the float's place relative to the dock and
+	// its size
+	X, Y, Width, Height float32
+}
+
+// SaveLayout returns the arrangement of the panels
+func (d *DockWidget) SaveLayout() (layout DockLayout) {
+	layout.Root = saveDockNode(d.root)
+	for _, f := range d.floats {
+		layout.Floating = append(layout.Floating, DockFloat{
+			Panels:   dockPanelIDs(f.stack.panels),
+			Selected: f.stack.selected,
+			X:        f.rect.X,
+			Y:        f.rect.Y,
+			Width:    f.rect.Width,
+			Height:   f.rect.Height,
+		})
+	}
+	return
+}
+
+// RestoreLayout arranges the panels as saved. Panels the layout does not
+// name are closed, and ids of panels that were not added are skipped.
+func (d *DockWidget) RestoreLayout(layout DockLayout) {
+	for _, p := range d.panels {
+		p.stack = nil
+	}
+	d.drag, d.resizing = nil, nil
+	d.root = d.restoreNode(layout.Root)
+	if d.root != nil {
+		d.root.parent = nil
+	}
+	d.floats = nil
+	for _, saved := range layout.Floating {
+		stack := d.restoreStack(saved.Panels, saved.Selected)
+		if stack == nil {
+			continue
+		}
+		f := &dockFloat{stack: stack, rect: Rect{X: saved.X, Y: saved.Y, Width: saved.Width, Height: saved.Height}}
+		stack.float = f
+		d.floats = append(d.floats, f)
+	}
+	d.rebuild()
+}
+
+// StateKey remembers the layout with the state of the user interface under
+// the key and returns the dock for chaining
+func (d *DockWidget) StateKey(key string) *DockWidget {
+	d.key = key
+	return d
+}
+
+// stateKey implements stateful
+func (d *DockWidget) stateKey() string {
+	return d.key
+}
+
+// saveState implements stateful, saving the layout
+func (d *DockWidget) saveState() any {
+	return d.SaveLayout()
+}
+
+// restoreState implements stateful, restoring the saved layout
+func (d *DockWidget) restoreState(data json.RawMessage) (err error) {
+	var layout DockLayout
+	if err = json.Unmarshal(data, &layout); err != nil {
+		return
+	}
+	d.RestoreLayout(layout)
+	return
+}
+
+// saveDockNode returns the saved form of a node of the tree
+func saveDockNode(n *dockNode) *DockNode {
+	switch {
+	case n == nil:
+		return nil
+	case n.isStack():
+		return &DockNode{Panels: dockPanelIDs(n.panels), Selected: n.selected}
+	}
+	return &DockNode{
+		Vertical: n.vertical,
+		Ratio:    n.ratio,
+		First:    saveDockNode(n.first),
+		Second:   saveDockNode(n.second),
+	}
+}
+
+// restoreNode builds a node of the tree from its saved form, nil when none
+// of its panels are known, a split missing a side giving way to the other
+func (d *DockWidget) restoreNode(saved *DockNode) *dockNode {
+	switch {
+	case saved == nil:
+		return nil
+	case saved.First == nil && saved.Second == nil:
+		return d.restoreStack(saved.Panels, saved.Selected)
+	}
+	first, second := d.restoreNode(saved.First), d.restoreNode(saved.Second)
+	switch {
+	case first == nil:
+		return second
+	case second == nil:
+		return first
+	}
+	n := &dockNode{first: first, second: second, vertical: saved.Vertical, ratio: min(max(saved.Ratio, 0), 1)}
+	first.parent, second.parent = n, n
+	return n
+}
+
+// restoreStack builds a stack of the known panels with the ids that are not
+// already placed, nil if there are none
+func (d *DockWidget) restoreStack(ids []string, selected int) *dockNode {
+	n := &dockNode{}
+	for i, id := range ids {
+		p := d.panel(id)
+		if p == nil || p.stack != nil {
+			continue
+		}
+		if i == selected {
+			n.selected = len(n.panels)
+		}
+		p.stack = n
+		n.panels = append(n.panels, p)
+	}
+	if len(n.panels) == 0 {
+		return nil
+	}
+	return n
+}
+
+// dockPanelIDs returns the ids of panels
+func dockPanelIDs(panels []*dockPanel) (ids []string) {
+	for _, p := range panels {
+		ids = append(ids, p.id)
+	}
+	return
+}
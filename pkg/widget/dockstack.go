@@ -0,0 +1,291 @@
+package widget
+
+import (
+	"math"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"lol.mleku.dev/chk"
+)
+
+// dockStackWidget shows a stack of a dock's panels as a strip of tabs above
+// the selected panel. Clicking a tab selects its panel and dragging it
+// hands the panel to the dock to drag out of the stack. Dragging the strip
+// of a floating stack, or the tab of its only panel, moves the float.
+type dockStackWidget struct {
+	Base
+	dock *DockWidget
+	node *dockNode
+	// hot is the tab under the cursor and hotClose set when the cursor is
+	// over its close button
+	hot      int
+	hotClose bool
+	// pressedClose is the tab whose close button is held down, -1 if none
+	pressedClose int
+	// held is the tab held down, -1 if none, and heldStrip set when the
+	// strip of a float is held beside the tabs, pressAt being where
+	held      int
+	heldStrip bool
+	pressAt   Point
+}
+
+// GetConstraints returns flexible constraints; stacks take the space given
+func (s *dockStackWidget) GetConstraints() Constraints {
+	return NewFlexConstraints(0, 0, 1e9, 1e9)
+}
+
+// Measure returns the minimum size
+func (s *dockStackWidget) Measure(constraints Constraints) Size {
+	return minSize(s.GetConstraints())
+}
+
+// Layout implements the Widget interface for dockStackWidget; stacks take
+// all the space offered and lay the selected panel out below the strip
+func (s *dockStackWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !s.NeedsLayout(ctx, constraints) {
+		return s.CachedSize(), nil
+	}
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	s.SetLayout(ctx, constraints, size)
+	if content := s.current(); content != nil {
+		if _, err = content.Layout(ctx, NewRigidConstraints(size.Width, max(size.Height-s.dock.stripHeight(), 0))); chk.E(err) {
+			return
+		}
+	}
+	return
+}
+
+// Paint implements the Widget interface for dockStackWidget
+func (s *dockStackWidget) Paint(ctx *Context, box *Box) (err error) {
+	if content := s.current(); content != nil {
+		if err = paintChild(ctx, content, s.contentBox(box)); chk.E(err) {
+			return
+		}
+	}
+	th := themeOf(ctx)
+	list := ctx.DrawList
+	x, y, w := box.Position.X, box.Position.Y, box.Size.Width
+	h := s.dock.stripHeight()
+	list.Rect(x, y, w, h, th.Surface)
+	list.Rect(x, y+h-1, w, 1, th.Border)
+
+	list.PushClip(x, y, w, h)
+	defer list.PopClip()
+	face := s.dock.font.Face(s.dock.size)
+	baseline := y + (h-face.LineHeight())/2 + face.Ascent()
+	for i, p := range s.node.panels {
+		tx, tw := s.tabSpan(i)
+		tx += x
+		color := th.TextMuted
+		switch {
+		case i == s.node.selected:
+			list.Rect(tx, y, tw, h-1, th.Background)
+			list.Rect(tx, y+h-tabsIndicator, tw, tabsIndicator, th.Primary)
+			color = th.Text
+		case i == s.hot:
+			list.Rect(tx, y, tw, h-1, th.SurfaceHover)
+		}
+		face.Draw(list, tx+tabsPadding, baseline, p.title, color)
+		if !p.closable {
+			continue
+		}
+		cx, cy := tx+tw-tabsPadding/2-tabsClose/2, y+h/2
+		if i == s.hot && s.hotClose {
+			list.Circle(cx, cy, tabsClose/2, th.SurfacePressed)
+		}
+		// Draw the cross as two strokes
+		d := float32(tabsClose) / 4
+		list.Line(cx-d, cy-d, cx+d, cy+d, 1.5, color)
+		list.Line(cx-d, cy+d, cx+d, cy-d, 1.5, color)
+	}
+	return
+}
+
+// Accessibility implements Accessible, with the title of the selected panel
+// as the value, which can be set to select another
+func (s *dockStackWidget) Accessibility() AccessNode {
+	node := AccessNode{Role: interfaces.RoleTabList, Actions: []AccessAction{interfaces.AccessSetValue}}
+	if n := s.node; len(n.panels) > 0 {
+		node.Value = n.panels[n.selected].title
+	}
+	return node
+}
+
+// AccessAction implements AccessActor, selecting the panel with the title
+// of the value
+func (s *dockStackWidget) AccessAction(ctx *Context, action AccessAction, value string) bool {
+	if action != interfaces.AccessSetValue {
+		return false
+	}
+	for i, p := range s.node.panels {
+		if p.title == value {
+			s.pick(i)
+			return true
+		}
+	}
+	return false
+}
+
+// HandleEvent implements the Widget interface for dockStackWidget
+func (s *dockStackWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		if s.held >= 0 || s.heldStrip {
+			if distance(e.Position, s.pressAt) >= dragThreshold {
+				s.startDrag(e.Position)
+			}
+			return true
+		}
+		i, onClose := s.tabAt(box, e.Position)
+		s.setHot(i, onClose)
+	case interfaces.CursorLeaveEvent:
+		s.setHot(-1, false)
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft {
+			break
+		}
+		if e.Action == interfaces.ActionPress && s.stripBox(box).Contains(e.Position) {
+			s.press(box, e.Position)
+			return true
+		}
+		if e.Action == interfaces.ActionRelease && (s.held >= 0 || s.heldStrip || s.pressedClose >= 0) {
+			s.release(box, e.Position)
+			return true
+		}
+	}
+	if content := s.current(); content != nil {
+		return routeEvent(ctx, content, s.contentBox(box), ev)
+	}
+	return false
+}
+
+// press selects the tab under the cursor and holds it for dragging, holds
+// down its close button, or holds the strip of a float for moving it
+func (s *dockStackWidget) press(box *Box, p Point) {
+	i, onClose := s.tabAt(box, p)
+	switch {
+	case onClose:
+		s.pressedClose = i
+	case i >= 0:
+		s.pick(i)
+		s.held, s.pressAt = i, p
+	case s.node.float != nil:
+		s.heldStrip, s.pressAt = true, p
+	}
+}
+
+// release closes the panel whose close button was pressed when released
+// over it, or lets go of a tab or strip that was not dragged
+func (s *dockStackWidget) release(box *Box, p Point) {
+	s.held, s.heldStrip = -1, false
+	i := s.pressedClose
+	s.pressedClose = -1
+	if i < 0 {
+		return
+	}
+	if j, onClose := s.tabAt(box, p); j == i && onClose {
+		s.dock.closePanel(s.node.panels[i])
+	}
+}
+
+// startDrag hands the held tab to the dock to drag out of the stack, or
+// the whole float when its strip or only tab is held
+func (s *dockStackWidget) startDrag(at Point) {
+	n := s.node
+	held, strip := s.held, s.heldStrip
+	s.held, s.heldStrip = -1, false
+	s.setHot(-1, false)
+	if n.float != nil && (strip || len(n.panels) == 1) {
+		s.dock.beginMove(n.float, s.pressAt, at)
+		return
+	}
+	s.dock.beginDrag(n.panels[held], at)
+}
+
+// pick selects a panel on behalf of the user, notifying the dock when the
+// selection changed
+func (s *dockStackWidget) pick(index int) {
+	n := s.node
+	if index < 0 || index >= len(n.panels) || index == n.selected {
+		return
+	}
+	n.selected = index
+	s.MarkNeedsLayout()
+	s.dock.changed()
+}
+
+// current returns the content of the selected panel, adopting it from the
+// stack it was shown in before
+func (s *dockStackWidget) current() Widget {
+	n := s.node
+	if len(n.panels) == 0 {
+		return nil
+	}
+	content := n.panels[n.selected].content
+	if content != nil {
+		if parented, ok := content.(interface{ Parent() Widget }); !ok || parented.Parent() != s {
+			content.SetParent(s)
+		}
+	}
+	return content
+}
+
+// tabSpan returns the offset of the tab at index from the left edge of the
+// strip and its width
+func (s *dockStackWidget) tabSpan(index int) (x, width float32) {
+	for i := 0; i <= index; i++ {
+		x += width
+		width = s.tabWidth(i)
+	}
+	return
+}
+
+// tabWidth returns the width of the tab at index
+func (s *dockStackWidget) tabWidth(index int) float32 {
+	p := s.node.panels[index]
+	w := float32(math.Ceil(float64(s.dock.font.Face(s.dock.size).Measure(p.title)))) + 2*tabsPadding
+	if p.closable {
+		w += tabsClose
+	}
+	return w
+}
+
+// tabAt returns the tab under a point, -1 if none, and whether the point is
+// over its close button
+func (s *dockStackWidget) tabAt(box *Box, p Point) (index int, onClose bool) {
+	if !s.stripBox(box).Contains(p) {
+		return -1, false
+	}
+	for i, panel := range s.node.panels {
+		x, w := s.tabSpan(i)
+		x += box.Position.X
+		if p.X < x || p.X >= x+w {
+			continue
+		}
+		right := x + w - tabsPadding/2
+		return i, panel.closable && p.X >= right-tabsClose && p.X < right
+	}
+	return -1, false
+}
+
+// stripBox returns the absolute box of the strip of titles
+func (s *dockStackWidget) stripBox(box *Box) *Box {
+	return NewBox(box.Position.X, box.Position.Y, box.Size.Width, s.dock.stripHeight(), s.GetConstraints())
+}
+
+// contentBox returns the absolute box of the selected panel
+func (s *dockStackWidget) contentBox(box *Box) *Box {
+	h := s.dock.stripHeight()
+	var c Constraints
+	if content := s.current(); content != nil {
+		c = content.GetConstraints()
+	}
+	return NewBox(box.Position.X, box.Position.Y+h, box.Size.Width, max(box.Size.Height-h, 0), c)
+}
+
+// setHot updates the tab under the cursor, repainting when it changes
+func (s *dockStackWidget) setHot(index int, onClose bool) {
+	if index != s.hot || onClose != s.hotClose {
+		s.hot, s.hotClose = index, onClose
+		s.MarkNeedsPaint()
+	}
+}
@@ -0,0 +1,549 @@
+package widget
+
+import (
+	"math"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"lol.mleku.dev/chk"
+)
+
+const (
+	// dragThreshold is how far the cursor moves with the button held before
+	// a press becomes a drag
+	dragThreshold = 4
+	// dragRejectedAlpha is the opacity of the drag image over places that do
+	// not accept the drop
+	dragRejectedAlpha = 0.5
+)
+
+// Drag is a drag and drop operation in progress. It carries a payload from
+// the widget it started on to the drop target it is released over, drawing
+// an image under the cursor on the way. Releasing the button anywhere that
+// does not accept the payload, or pressing escape, cancels it.
+type Drag struct {
+	// Payload is the data carried, which drop targets inspect to decide
+	// whether to accept it
+	Payload any
+	// Source is the widget the drag started on
+	Source Widget
+	root   *RootWidget
+	image  Widget
+	// offset is the position of the image's top left corner relative to the cursor
+	offset   Point
+	position Point
+	// imageBox is where the image was last drawn, for repainting it
+	imageBox Box
+	target   DropHandler
+	accepted bool
+	onEnd    func(dropped bool)
+}
+
+// DropHandler is implemented by widgets that take part in drag and drop as
+// drop targets. The topmost widget under the cursor, or the nearest of its
+// ancestors implementing DropHandler, is the target.
+type DropHandler interface {
+	// DragEnter is called when a drag moves over the target, returning
+	// whether it would accept the payload
+	DragEnter(d *Drag) (accept bool)
+	// DragOver is called as an accepted drag moves over the target
+	DragOver(d *Drag, p Point)
+	// DragLeave is called when a drag moves off the target or is cancelled
+	// while over it
+	DragLeave(d *Drag)
+	// Drop is called when an accepted drag is released over the target
+	Drop(d *Drag, p Point)
+}
+
+// StartDrag begins dragging a payload from a source widget with the cursor
+// at a point, drawing an image, which may be nil, under the cursor with its
+// top left corner at an offset from it. Any drag already in progress is
+// cancelled. It returns nil when the source is not in a root's tree.
+func StartDrag(source Widget, payload any, image Widget, at, offset Point) *Drag {
+	r := rootOf(source)
+	if r == nil {
+		return nil
+	}
+	if r.drag != nil {
+		r.drag.Cancel()
+	}
+	d := &Drag{
+		Payload:  payload,
+		Source:   source,
+		root:     r,
+		image:    image,
+		offset:   offset,
+		position: at,
+	}
+	if image != nil {
+		image.SetParent(r)
+	}
+	r.drag = d
+	r.dragTo(at)
+	return d
+}
+
+// OnEnd sets the callback invoked with whether the payload was dropped when
+// the drag ends and returns the drag for chaining
+func (d *Drag) OnEnd(fn func(dropped bool)) *Drag {
+	d.onEnd = fn
+	return d
+}
+
+// Position returns the cursor position
+func (d *Drag) Position() Point {
+	return d.position
+}
+
+// Accepted reports whether the target under the cursor accepts the payload
+func (d *Drag) Accepted() bool {
+	return d.accepted
+}
+
+// Target returns the drop target under the cursor, nil if there is none
+func (d *Drag) Target() DropHandler {
+	return d.target
+}
+
+// Cancel ends the drag without dropping the payload
+func (d *Drag) Cancel() {
+	d.end(false)
+}
+
+// end finishes the drag, dropping the payload on the target when dropped is set
+func (d *Drag) end(dropped bool) {
+	r := d.root
+	if r.drag != d {
+		return
+	}
+	r.drag = nil
+	r.Invalidate(d.imageBox.Rect())
+	if d.target != nil {
+		if dropped && d.accepted {
+			d.target.Drop(d, d.position)
+		} else {
+			d.target.DragLeave(d)
+			dropped = false
+		}
+	} else {
+		dropped = false
+	}
+	if d.onEnd != nil {
+		d.onEnd(dropped)
+	}
+}
+
+// Dragging returns the drag in progress, nil if there is none
+func (r *RootWidget) Dragging() *Drag {
+	return r.drag
+}
+
+// dragEvent moves the drag in progress with the cursor, dropping it on
+// release and cancelling it on escape. It reports whether the event is kept
+// from the tree.
+func (r *RootWidget) dragEvent(ev Event) (handled bool) {
+	d := r.drag
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		r.dragTo(e.Position)
+		return true
+	case interfaces.MouseButtonEvent:
+		if e.Button == interfaces.MouseButtonLeft && e.Action == interfaces.ActionRelease {
+			r.dragTo(e.Position)
+			d.end(true)
+		}
+		// The release is still delivered so the source sees the button go up
+		return false
+	case interfaces.KeyEvent:
+		if e.Key == interfaces.KeyEscape && e.Action == interfaces.ActionPress {
+			d.Cancel()
+			return true
+		}
+	case interfaces.CursorLeaveEvent:
+		r.dragTo(Point{X: -1, Y: -1})
+	}
+	return false
+}
+
+// dragTo moves the drag in progress to a point, telling the drop targets it
+// leaves and enters
+func (r *RootWidget) dragTo(p Point) {
+	d := r.drag
+	d.position = p
+	target := dropTargetAt(r, p)
+	if target != d.target {
+		if d.target != nil {
+			d.target.DragLeave(d)
+		}
+		d.target, d.accepted = target, false
+		if target != nil {
+			d.accepted = target.DragEnter(d)
+		}
+	}
+	if d.target != nil && d.accepted {
+		d.target.DragOver(d, p)
+	}
+	r.Invalidate(d.imageBox.Rect())
+	d.imageBox.Position = Point{X: p.X + d.offset.X, Y: p.Y + d.offset.Y}
+	if d.image != nil {
+		c := d.image.GetConstraints()
+		d.imageBox.Size = Size{Width: c.MinWidth, Height: c.MinHeight}
+	}
+	r.Invalidate(d.imageBox.Rect())
+}
+
+// dropTargetAt returns the drop target under a point, the topmost widget
+// there or the nearest of its ancestors implementing DropHandler
+func dropTargetAt(r *RootWidget, p Point) DropHandler {
+	var w Widget = r.HitTest(p)
+	for w != nil {
+		if h, ok := w.(DropHandler); ok {
+			return h
+		}
+		parented, ok := w.(interface{ Parent() Widget })
+		if !ok {
+			return nil
+		}
+		w = parented.Parent()
+	}
+	return nil
+}
+
+// paintDrag draws the image of the drag in progress above everything else,
+// faded over places that do not accept the drop
+func (r *RootWidget) paintDrag(ctx *Context) (err error) {
+	d := r.drag
+	if d == nil || d.image == nil {
+		return
+	}
+	box := &d.imageBox
+	if _, err = d.image.Layout(childContext(ctx, box), NewRigidConstraints(box.Size.Width, box.Size.Height)); chk.E(err) {
+		return
+	}
+	// The image is not a target itself, so it is kept out of hit testing
+	imageCtx := *ctx
+	imageCtx.Hits = nil
+	list := ctx.DrawList
+	if !d.accepted {
+		list.PushAlpha(dragRejectedAlpha)
+		defer list.PopAlpha()
+	}
+	return paintChild(&imageCtx, d.image, box)
+}
+
+// DraggableWidget lets its child be dragged, starting a drag carrying a
+// payload when the cursor moves a few pixels with the left button held on it
+type DraggableWidget struct {
+	Base
+	child   Widget
+	payload func() any
+	image   func() Widget
+	onEnd   func(dropped bool)
+	pressed bool
+	press   Point
+	// dragging is set from when the drag starts until it ends
+	dragging bool
+}
+
+// Draggable creates a new wrapper letting the child be dragged, carrying the
+// value returned by payload. The drag image is a shape the size of the child
+// until one is set.
+func Draggable(child Widget, payload func() any) *DraggableWidget {
+	d := &DraggableWidget{child: child, payload: payload}
+	adopt(d, child)
+	return d
+}
+
+// Image sets the function creating the widget drawn under the cursor while
+// dragging and returns the wrapper for chaining
+func (d *DraggableWidget) Image(fn func() Widget) *DraggableWidget {
+	d.image = fn
+	return d
+}
+
+// OnEnd sets the callback invoked with whether the payload was dropped when
+// a drag from the child ends and returns the wrapper for chaining
+func (d *DraggableWidget) OnEnd(fn func(dropped bool)) *DraggableWidget {
+	d.onEnd = fn
+	return d
+}
+
+// IsDragging reports whether the child is being dragged
+func (d *DraggableWidget) IsDragging() bool {
+	return d.dragging
+}
+
+// GetConstraints returns the child's constraints
+func (d *DraggableWidget) GetConstraints() Constraints {
+	if d.child == nil {
+		return NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return d.child.GetConstraints()
+}
+
+// Measure returns the size the child measures
+func (d *DraggableWidget) Measure(constraints Constraints) Size {
+	return measure(d.child, constraints)
+}
+
+// Layout implements the Widget interface for DraggableWidget; the child is
+// laid out in the widget's box
+func (d *DraggableWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !d.NeedsLayout(ctx, constraints) {
+		return d.CachedSize(), nil
+	}
+	if size, err = layoutInset(ctx, d.child, Insets{}, constraints); chk.E(err) {
+		return
+	}
+	d.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for DraggableWidget
+func (d *DraggableWidget) Paint(ctx *Context, box *Box) (err error) {
+	if d.child == nil {
+		return
+	}
+	return paintChild(ctx, d.child, box)
+}
+
+// HandleEvent implements the Widget interface for DraggableWidget. Presses
+// the child handles itself do not start a drag.
+func (d *DraggableWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if d.child != nil && routeEvent(ctx, d.child, box, ev) {
+		return true
+	}
+	switch e := ev.(type) {
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft {
+			break
+		}
+		switch e.Action {
+		case interfaces.ActionPress:
+			d.pressed, d.press = true, e.Position
+			return true
+		case interfaces.ActionRelease:
+			d.pressed = false
+		}
+	case interfaces.MouseMoveEvent:
+		if !d.pressed || distance(d.press, e.Position) < dragThreshold {
+			break
+		}
+		d.pressed = false
+		d.start(box, e.Position)
+		return true
+	}
+	return false
+}
+
+// start begins dragging with the cursor at a point, with the image held
+// where the button was pressed on the child
+func (d *DraggableWidget) start(box *Box, at Point) {
+	var payload any
+	if d.payload != nil {
+		payload = d.payload()
+	}
+	var image Widget
+	if d.image != nil {
+		image = d.image()
+	} else {
+		image = dragGhost(box.Size)
+	}
+	offset := Point{X: box.Position.X - d.press.X, Y: box.Position.Y - d.press.Y}
+	drag := StartDrag(d, payload, image, at, offset)
+	if drag == nil {
+		return
+	}
+	d.dragging = true
+	drag.OnEnd(func(dropped bool) {
+		d.dragging = false
+		if d.onEnd != nil {
+			d.onEnd(dropped)
+		}
+	})
+}
+
+// DropZoneWidget makes its child a drop target, outlining it while a drag
+// it accepts is over it. It can also take files dropped on it from other
+// applications.
+type DropZoneWidget struct {
+	Base
+	child  Widget
+	accept func(d *Drag) bool
+	onOver func(d *Drag, p Point)
+	onDrop func(d *Drag, p Point)
+	// onFiles receives files dropped on the zone from other applications
+	onFiles func(paths []string, p Point)
+	// over is set while an accepted drag is over the zone
+	over bool
+}
+
+// DropZone creates a new drop target around the child. It accepts every
+// payload until an accept function is set.
+func DropZone(child Widget) *DropZoneWidget {
+	z := &DropZoneWidget{child: child}
+	adopt(z, child)
+	return z
+}
+
+// Accept sets the function deciding whether a drag's payload can be dropped
+// and returns the drop zone for chaining
+func (z *DropZoneWidget) Accept(fn func(d *Drag) bool) *DropZoneWidget {
+	z.accept = fn
+	return z
+}
+
+// OnOver sets the callback invoked as an accepted drag moves over the zone
+// and returns the drop zone for chaining
+func (z *DropZoneWidget) OnOver(fn func(d *Drag, p Point)) *DropZoneWidget {
+	z.onOver = fn
+	return z
+}
+
+// OnDrop sets the callback invoked when an accepted drag is dropped on the
+// zone and returns the drop zone for chaining
+func (z *DropZoneWidget) OnDrop(fn func(d *Drag, p Point)) *DropZoneWidget {
+	z.onDrop = fn
+	return z
+}
+
+// OnFiles sets the callback invoked with the paths of files dropped on the
+// zone from other applications and returns the drop zone for chaining. The
+// zone is not outlined while files are dragged over it, as the window is not
+// told where they are until they are dropped.
+func (z *DropZoneWidget) OnFiles(fn func(paths []string, p Point)) *DropZoneWidget {
+	z.onFiles = fn
+	return z
+}
+
+// DragEnter implements DropHandler for DropZoneWidget
+func (z *DropZoneWidget) DragEnter(d *Drag) (accept bool) {
+	if z.accept != nil && !z.accept(d) {
+		return false
+	}
+	z.over = true
+	z.MarkNeedsPaint()
+	return true
+}
+
+// DragOver implements DropHandler for DropZoneWidget
+func (z *DropZoneWidget) DragOver(d *Drag, p Point) {
+	if z.onOver != nil {
+		z.onOver(d, p)
+	}
+}
+
+// DragLeave implements DropHandler for DropZoneWidget
+func (z *DropZoneWidget) DragLeave(d *Drag) {
+	if z.over {
+		z.over = false
+		z.MarkNeedsPaint()
+	}
+}
+
+// Drop implements DropHandler for DropZoneWidget
+func (z *DropZoneWidget) Drop(d *Drag, p Point) {
+	z.DragLeave(d)
+	if z.onDrop != nil {
+		z.onDrop(d, p)
+	}
+}
+
+// GetConstraints returns the child's constraints
+func (z *DropZoneWidget) GetConstraints() Constraints {
+	if z.child == nil {
+		return NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return z.child.GetConstraints()
+}
+
+// Measure returns the size the child measures
+func (z *DropZoneWidget) Measure(constraints Constraints) Size {
+	return measure(z.child, constraints)
+}
+
+// Layout implements the Widget interface for DropZoneWidget; the child is
+// laid out in the widget's box
+func (z *DropZoneWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !z.NeedsLayout(ctx, constraints) {
+		return z.CachedSize(), nil
+	}
+	if size, err = layoutInset(ctx, z.child, Insets{}, constraints); chk.E(err) {
+		return
+	}
+	z.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for DropZoneWidget
+func (z *DropZoneWidget) Paint(ctx *Context, box *Box) (err error) {
+	if z.child != nil {
+		if err = paintChild(ctx, z.child, box); chk.E(err) {
+			return
+		}
+	}
+	if z.over {
+		th := themeOf(ctx)
+		ctx.DrawList.RoundRectStroke(box.Position.X+1, box.Position.Y+1,
+			box.Size.Width-2, box.Size.Height-2, th.Radius.Small, 2, th.Primary)
+	}
+	return
+}
+
+// HandleEvent implements the Widget interface for DropZoneWidget. Files
+// dropped on the zone go to the child first.
+func (z *DropZoneWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if z.child != nil && routeEvent(ctx, z.child, box, ev) {
+		return true
+	}
+	if e, ok := ev.(interfaces.FileDropEvent); ok && z.onFiles != nil {
+		z.onFiles(e.Paths, e.Position)
+		return true
+	}
+	return false
+}
+
+// dragGhostWidget is the default drag image, a translucent shape the size
+// of what is being dragged
+type dragGhostWidget struct {
+	Base
+	size Size
+}
+
+// dragGhost creates a drag image of a size
+func dragGhost(size Size) *dragGhostWidget {
+	return &dragGhostWidget{size: size}
+}
+
+// GetConstraints returns the ghost's size
+func (g *dragGhostWidget) GetConstraints() Constraints {
+	return NewRigidConstraints(g.size.Width, g.size.Height)
+}
+
+// Measure returns the minimum size of the ghost
+func (g *dragGhostWidget) Measure(constraints Constraints) Size {
+	return minSize(g.GetConstraints())
+}
+
+// Layout implements the Widget interface for dragGhostWidget
+func (g *dragGhostWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	g.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for dragGhostWidget
+func (g *dragGhostWidget) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	fillBordered(ctx, box, th.Radius.Small, th.Primary, th.Selection)
+	return
+}
+
+// HandleEvent implements the Widget interface for dragGhostWidget; ghosts ignore input
+func (g *dragGhostWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	return false
+}
+
+// distance returns the distance between two points
+func distance(a, b Point) float32 {
+	return float32(math.Hypot(float64(b.X-a.X), float64(b.Y-a.Y)))
+}
@@ -0,0 +1,516 @@
+package widget
+
+import (
+	"math"
+	"slices"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/text"
+)
+
+const (
+	// dropdownPadding is the space around the text of a dropdown and its rows
+	dropdownPadding = 6
+	// dropdownArrow is the width of the arrow drawn at the end of a dropdown
+	dropdownArrow = 8
+	// dropdownScrollbar is the width of the scroll indicator of a long list
+	dropdownScrollbar = 4
+)
+
+// DropdownWidget shows the selected option of a list and opens the whole
+// list in a popup above the other widgets when clicked. While it has focus
+// the up and down keys change the selection, and enter or space opens the
+// list to pick from with the keys or the mouse.
+type DropdownWidget struct {
+	Base
+	font        *text.Font
+	size        float32
+	options     []string
+	selected    int
+	placeholder string
+	maxVisible  int
+	onSelect    func(index int, option string)
+	hovered     bool
+	// list is the content of the popup shown while open
+	list  *dropdownList
+	popup *Popup
+}
+
+// Dropdown creates a new dropdown choosing from the options, drawn in the
+// given font, with nothing selected. The text defaults to 14 pixels and up
+// to 8 options are shown before the list scrolls.
+func Dropdown(font *text.Font, options ...string) *DropdownWidget {
+	d := &DropdownWidget{
+		font:       font,
+		size:       14,
+		options:    options,
+		selected:   -1,
+		maxVisible: 8,
+	}
+	d.list = &dropdownList{owner: d}
+	d.popup = NewPopup(d.list).OnClose(d.closed)
+	return d
+}
+
+// Size sets the pixel size of the text and returns the dropdown for chaining
+func (d *DropdownWidget) Size(size float32) *DropdownWidget {
+	d.size = size
+	d.MarkNeedsLayout()
+	return d
+}
+
+// Placeholder sets the hint shown while nothing is selected and returns the dropdown for chaining
+func (d *DropdownWidget) Placeholder(s string) *DropdownWidget {
+	d.placeholder = s
+	d.MarkNeedsPaint()
+	return d
+}
+
+// MaxVisible sets how many options the list shows before it scrolls and
+// returns the dropdown for chaining
+func (d *DropdownWidget) MaxVisible(n int) *DropdownWidget {
+	d.maxVisible = max(n, 1)
+	return d
+}
+
+// OnSelect sets the callback invoked with the index and text of the option
+// the user picks and returns the dropdown for chaining
+func (d *DropdownWidget) OnSelect(fn func(index int, option string)) *DropdownWidget {
+	d.onSelect = fn
+	return d
+}
+
+// SetOptions replaces the options, keeping the selected index when it is
+// still in range and clearing the selection otherwise
+func (d *DropdownWidget) SetOptions(options ...string) {
+	d.close()
+	d.options = options
+	if d.selected >= len(options) {
+		d.selected = -1
+	}
+	d.MarkNeedsLayout()
+}
+
+// Options returns the options chosen from
+func (d *DropdownWidget) Options() []string {
+	return d.options
+}
+
+// Select selects an option by index, -1 for none, without invoking the
+// select callback
+func (d *DropdownWidget) Select(index int) {
+	if index < -1 || index >= len(d.options) || index == d.selected {
+		return
+	}
+	d.selected = index
+	d.MarkNeedsPaint()
+}
+
+// Selected returns the index of the selected option, -1 if none
+func (d *DropdownWidget) Selected() int {
+	return d.selected
+}
+
+// SelectedOption returns the text of the selected option, empty if none
+func (d *DropdownWidget) SelectedOption() string {
+	if d.selected < 0 || d.selected >= len(d.options) {
+		return ""
+	}
+	return d.options[d.selected]
+}
+
+// IsOpen reports whether the option list is showing
+func (d *DropdownWidget) IsOpen() bool {
+	return d.list.open
+}
+
+// GetConstraints returns a minimum size that fits the longest option on one line
+func (d *DropdownWidget) GetConstraints() Constraints {
+	face := d.font.Face(d.size)
+	width := face.Measure(d.placeholder)
+	for _, option := range d.options {
+		width = max(width, face.Measure(option))
+	}
+	width += 3*dropdownPadding + dropdownArrow
+	height := d.rowHeight()
+	return NewFlexConstraints(width, height, 1e9, height)
+}
+
+// Measure returns the minimum size of the dropdown, which fits its longest option
+func (d *DropdownWidget) Measure(constraints Constraints) Size {
+	return minSize(d.GetConstraints())
+}
+
+// Layout implements the Widget interface for DropdownWidget; dropdowns take
+// all the space offered. The list closes when the dropdown is resized.
+func (d *DropdownWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !d.NeedsLayout(ctx, constraints) {
+		return d.CachedSize(), nil
+	}
+	d.close()
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	d.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for DropdownWidget
+func (d *DropdownWidget) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	border, fill := th.Border, th.Surface
+	if hasFocus(d) || d.list.open {
+		border = th.Primary
+	}
+	if d.hovered {
+		fill = th.SurfaceHover
+	}
+	fillBordered(ctx, box, th.Radius.Small, border, fill)
+
+	list := ctx.DrawList
+	x, y, w, h := box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height
+
+	// Point the arrow down, or up when the list opened above
+	ax := x + w - dropdownPadding - dropdownArrow
+	ay := float32(math.Round(float64(y + h/2)))
+	half := float32(dropdownArrow / 2)
+	if d.list.open && d.list.above {
+		list.Polygon([][2]float32{{ax, ay + half/2}, {ax + dropdownArrow, ay + half/2}, {ax + half, ay - half/2}}, th.Text)
+	} else {
+		list.Polygon([][2]float32{{ax, ay - half/2}, {ax + dropdownArrow, ay - half/2}, {ax + half, ay + half/2}}, th.Text)
+	}
+
+	face := d.font.Face(d.size)
+	s, color := d.SelectedOption(), th.Text
+	if d.selected < 0 {
+		s, color = d.placeholder, th.TextMuted
+	}
+	list.PushClip(x, y, ax-x-dropdownPadding/2, h)
+	face.Draw(list, x+dropdownPadding, y+(h-face.LineHeight())/2+face.Ascent(), s, color)
+	list.PopClip()
+	return
+}
+
+// navigable lets gamepad navigation focus the dropdown, which A opens
+func (d *DropdownWidget) navigable() bool {
+	return true
+}
+
+// Accessibility implements Accessible, with the selected option as the value
+func (d *DropdownWidget) Accessibility() AccessNode {
+	node := AccessNode{
+		Role:    interfaces.RoleComboBox,
+		Name:    d.placeholder,
+		Value:   d.SelectedOption(),
+		State:   interfaces.StateExpandable,
+		Actions: []AccessAction{interfaces.AccessClick, interfaces.AccessExpand, interfaces.AccessCollapse, interfaces.AccessSetValue},
+	}
+	if d.list.open {
+		node.State |= interfaces.StateExpanded
+	}
+	return node
+}
+
+// AccessAction implements AccessActor, opening and closing the list or
+// selecting the option with the text of the value
+func (d *DropdownWidget) AccessAction(ctx *Context, action AccessAction, value string) bool {
+	switch action {
+	case interfaces.AccessClick:
+		if d.list.open {
+			d.close()
+		} else {
+			d.open()
+		}
+	case interfaces.AccessExpand:
+		d.open()
+	case interfaces.AccessCollapse:
+		d.close()
+	case interfaces.AccessSetValue:
+		i := slices.Index(d.options, value)
+		if i < 0 {
+			return false
+		}
+		d.pick(i)
+	default:
+		return false
+	}
+	return true
+}
+
+// HandleEvent implements the Widget interface for DropdownWidget
+func (d *DropdownWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		d.setHovered(box.Contains(e.Position))
+	case interfaces.CursorLeaveEvent:
+		d.setHovered(false)
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft || e.Action != interfaces.ActionPress {
+			return false
+		}
+		// Presses are only delivered when the cursor is inside the box. The
+		// root closes an open list before a press outside it reaches here,
+		// so a press always opens the list.
+		requestFocus(d)
+		d.open()
+		return true
+	case interfaces.KeyEvent:
+		if !hasFocus(d) || e.Action == interfaces.ActionRelease {
+			return false
+		}
+		return d.key(e)
+	}
+	return false
+}
+
+// key moves the selection or the list highlight
+func (d *DropdownWidget) key(e interfaces.KeyEvent) (handled bool) {
+	l := d.list
+	if !l.open {
+		if len(d.options) == 0 && (e.Key == interfaces.KeyUp || e.Key == interfaces.KeyDown) {
+			// An empty dropdown has nothing to step through
+			return false
+		}
+		switch e.Key {
+		case interfaces.KeyUp:
+			d.pick(max(d.selected-1, 0))
+		case interfaces.KeyDown:
+			d.pick(min(d.selected+1, len(d.options)-1))
+		case interfaces.KeyEnter, interfaces.KeySpace:
+			d.open()
+		default:
+			return false
+		}
+		return true
+	}
+	page := l.rows - 1
+	switch e.Key {
+	case interfaces.KeyUp:
+		l.highlight(max(l.hot-1, 0))
+	case interfaces.KeyDown:
+		l.highlight(min(l.hot+1, len(d.options)-1))
+	case interfaces.KeyPageUp:
+		l.highlight(max(l.hot-page, 0))
+	case interfaces.KeyPageDown:
+		l.highlight(min(l.hot+page, len(d.options)-1))
+	case interfaces.KeyHome:
+		l.highlight(0)
+	case interfaces.KeyEnd:
+		l.highlight(len(d.options) - 1)
+	case interfaces.KeyEnter, interfaces.KeySpace:
+		d.close()
+		if l.hot >= 0 {
+			d.pick(l.hot)
+		}
+	case interfaces.KeyEscape, interfaces.KeyTab:
+		d.close()
+	default:
+		return false
+	}
+	return true
+}
+
+// open shows the list below the dropdown, or above it when there is more
+// room there, with the selected option highlighted
+func (d *DropdownWidget) open() {
+	r := rootOf(d)
+	if r == nil || d.list.open || len(d.options) == 0 {
+		return
+	}
+	box := d.paintBox
+	rowHeight := d.rowHeight()
+	rows := min(len(d.options), d.maxVisible)
+	top := box.Position.Y
+	below := r.CachedSize().Height - top - box.Size.Height
+	l := d.list
+	l.above = below < float32(rows)*rowHeight+2 && top > below
+	room := below
+	if l.above {
+		room = top
+	}
+	// Shorten the list to whole rows that fit the window
+	l.rows = max(min(rows, int((room-2)/rowHeight)), 1)
+	height := float32(l.rows)*rowHeight + 2
+	placement := PlaceBelow
+	if l.above {
+		placement = PlaceAbove
+	}
+	l.open, l.first = true, 0
+	l.highlight(max(d.selected, 0))
+	r.ShowPopup(d.popup.Anchor(d, placement).Size(box.Size.Width, height))
+	d.MarkNeedsPaint()
+}
+
+// close hides the list
+func (d *DropdownWidget) close() {
+	d.popup.Close()
+}
+
+// closed updates the dropdown after its list closed
+func (d *DropdownWidget) closed() {
+	d.list.open = false
+	d.MarkNeedsPaint()
+}
+
+// pick selects an option on behalf of the user, invoking the select
+// callback when the selection changed
+func (d *DropdownWidget) pick(index int) {
+	if index < 0 || index >= len(d.options) || index == d.selected {
+		return
+	}
+	d.selected = index
+	d.MarkNeedsPaint()
+	if d.onSelect != nil {
+		d.onSelect(index, d.options[index])
+	}
+}
+
+// rowHeight returns the height of the dropdown and of each list row
+func (d *DropdownWidget) rowHeight() float32 {
+	return float32(math.Ceil(float64(d.font.Face(d.size).LineHeight()))) + 2*dropdownPadding
+}
+
+// setHovered updates the hover state, repainting when it changes
+func (d *DropdownWidget) setHovered(hovered bool) {
+	if hovered != d.hovered {
+		d.hovered = hovered
+		d.MarkNeedsPaint()
+	}
+}
+
+// dropdownList is the popup listing a dropdown's options. Rows highlight
+// under the cursor and releasing the mouse over one picks it, so the list
+// can be opened, dragged through and picked from in one press.
+type dropdownList struct {
+	Base
+	owner *DropdownWidget
+	open  bool
+	// above is set when the list opened above the dropdown
+	above bool
+	// rows is how many rows fit the list, hot is the highlighted row and
+	// first the row shown at the top
+	rows, hot, first int
+}
+
+// highlight highlights a row, scrolling to keep it visible
+func (l *dropdownList) highlight(index int) {
+	l.hot = index
+	if index < l.first {
+		l.first = index
+	}
+	if index >= l.first+l.rows {
+		l.first = index - l.rows + 1
+	}
+	l.MarkNeedsPaint()
+}
+
+// scroll moves the rows shown by a number of rows, clamped to the options
+func (l *dropdownList) scroll(rows int) {
+	last := max(len(l.owner.options)-l.rows, 0)
+	first := min(max(l.first+rows, 0), last)
+	if first != l.first {
+		l.first = first
+		l.MarkNeedsPaint()
+	}
+}
+
+// GetConstraints returns the list's constraints; the dropdown places the list itself
+func (l *dropdownList) GetConstraints() Constraints {
+	return NewFlexConstraints(0, 0, 1e9, 1e9)
+}
+
+// Measure returns the minimum size of the list
+func (l *dropdownList) Measure(constraints Constraints) Size {
+	return minSize(l.GetConstraints())
+}
+
+// Layout implements the Widget interface for dropdownList; lists take all the space offered
+func (l *dropdownList) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	l.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for dropdownList
+func (l *dropdownList) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	d := l.owner
+	fillSkinned(ctx, box, th.Skins.Panel, th.Radius.Small, th.Border, th.Surface)
+
+	list := ctx.DrawList
+	list.PushClip(box.Position.X+1, box.Position.Y+1, box.Size.Width-2, box.Size.Height-2)
+	defer list.PopClip()
+
+	face := d.font.Face(d.size)
+	rowHeight := d.rowHeight()
+	rows := l.rows
+	width := box.Size.Width - 2
+	overflow := len(d.options) > rows
+	if overflow {
+		width -= dropdownScrollbar
+	}
+	for i := l.first; i < min(l.first+rows, len(d.options)); i++ {
+		y := box.Position.Y + 1 + float32(i-l.first)*rowHeight
+		color := th.Text
+		switch {
+		case i == l.hot:
+			list.Rect(box.Position.X+1, y, width, rowHeight, th.Selection)
+		case i == d.selected:
+			color = th.Primary
+		}
+		face.Draw(list, box.Position.X+dropdownPadding, y+dropdownPadding+face.Ascent(), d.options[i], color)
+	}
+
+	if overflow {
+		track := box.Size.Height - 2
+		length, position := thumbSpan(track, float32(rows), float32(len(d.options)), float32(l.first))
+		list.Rect(box.Position.X+box.Size.Width-1-dropdownScrollbar, box.Position.Y+1+position, dropdownScrollbar, length, th.Thumb)
+	}
+	return
+}
+
+// HandleEvent implements the Widget interface for dropdownList
+func (l *dropdownList) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		if i, ok := l.rowAt(box, e.Position); ok && i != l.hot {
+			l.hot = i
+			l.MarkNeedsPaint()
+		}
+		return box.Contains(e.Position)
+	case interfaces.ScrollEvent:
+		// Scrolls are only delivered when the cursor is inside the box
+		l.scroll(-int(math.Round(float64(e.Offset.Y))))
+		if i, ok := l.rowAt(box, e.Position); ok {
+			l.hot = i
+		}
+		return true
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft {
+			return false
+		}
+		if e.Action == interfaces.ActionPress {
+			return true
+		}
+		// Releases are broadcast, so only pick when released over a row
+		i, ok := l.rowAt(box, e.Position)
+		if !ok {
+			return false
+		}
+		d := l.owner
+		d.close()
+		d.pick(i)
+		return true
+	}
+	return false
+}
+
+// rowAt returns the option under a point, reporting whether there is one
+func (l *dropdownList) rowAt(box *Box, p Point) (index int, ok bool) {
+	if !box.Contains(p) {
+		return 0, false
+	}
+	index = l.first + int((p.Y-box.Position.Y-1)/l.owner.rowHeight())
+	if index < 0 || index >= len(l.owner.options) {
+		return 0, false
+	}
+	return index, true
+}
@@ -0,0 +1,48 @@
+package widget_test
+
+import (
+	"testing"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/widget"
+)
+
+func TestDropdownKeys(t *testing.T) {
+	font := loadFont(t)
+	tests := []struct {
+		name    string
+		options []string
+		keys    []interfaces.Key
+		want    int
+		picks   int
+	}{
+		{"empty", nil, []interfaces.Key{interfaces.KeyDown, interfaces.KeyUp, interfaces.KeyEnter}, -1, 0},
+		{"down from nothing", []string{"a", "b"}, []interfaces.Key{interfaces.KeyDown}, 0, 1},
+		{"stops at the last", []string{"a", "b"}, []interfaces.Key{interfaces.KeyDown, interfaces.KeyDown, interfaces.KeyDown}, 1, 2},
+		{"up stops at the first", []string{"a", "b"}, []interfaces.Key{interfaces.KeyDown, interfaces.KeyUp}, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var picks int
+			d := widget.Dropdown(font, tt.options...).OnSelect(func(int, string) { picks++ })
+			h := newHarness(t, widget.Column().Rigid(widget.NewFixedSize(160, 30, d)), 180, 120)
+			h.root.SetFocus(d)
+			for _, k := range tt.keys {
+				h.key(k, 0)
+			}
+			if got := d.Selected(); got != tt.want {
+				t.Errorf("selected %d, want %d", got, tt.want)
+			}
+			if picks != tt.picks {
+				t.Errorf("%d picks, want %d", picks, tt.picks)
+			}
+			want := ""
+			if tt.want >= 0 {
+				want = tt.options[tt.want]
+			}
+			if got := d.SelectedOption(); got != want {
+				t.Errorf("selected option %q, want %q", got, want)
+			}
+		})
+	}
+}
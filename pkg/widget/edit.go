@@ -0,0 +1,361 @@
+package widget
+
+import (
+	"math"
+	"slices"
+	"time"
+	"unicode"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/render"
+	"github.com/mleku/goo/pkg/text"
+)
+
+// blinkInterval is how long the caret stays shown or hidden while blinking
+const blinkInterval = 530 * time.Millisecond
+
+// maxUndo is the number of edits kept for undo
+const maxUndo = 200
+
+// editBuffer holds editable text with a caret and selection, shared by the
+// text editing widgets. Positions are rune indices into the text.
+type editBuffer struct {
+	text  []rune
+	caret int
+	// anchor is the fixed end of the selection, equal to the caret when
+	// nothing is selected
+	anchor int
+}
+
+// String returns the buffer contents
+func (b *editBuffer) String() string {
+	return string(b.text)
+}
+
+// setText replaces the contents and places the caret at the end
+func (b *editBuffer) setText(s string) {
+	b.text = []rune(s)
+	b.caret = len(b.text)
+	b.anchor = b.caret
+}
+
+// selection returns the selected range in order
+func (b *editBuffer) selection() (start, end int) {
+	return min(b.caret, b.anchor), max(b.caret, b.anchor)
+}
+
+// hasSelection reports whether any text is selected
+func (b *editBuffer) hasSelection() bool {
+	return b.caret != b.anchor
+}
+
+// selectedText returns the selected text
+func (b *editBuffer) selectedText() string {
+	start, end := b.selection()
+	return string(b.text[start:end])
+}
+
+// moveTo places the caret, extending the selection from the anchor when
+// extend is set and collapsing it otherwise
+func (b *editBuffer) moveTo(pos int, extend bool) {
+	b.caret = min(max(pos, 0), len(b.text))
+	if !extend {
+		b.anchor = b.caret
+	}
+}
+
+// moveLeft moves the caret back a rune or word. Without extend a selection
+// collapses to its start instead.
+func (b *editBuffer) moveLeft(word, extend bool) {
+	switch {
+	case b.hasSelection() && !extend:
+		start, _ := b.selection()
+		b.moveTo(start, false)
+	case word:
+		b.moveTo(b.wordLeft(b.caret), extend)
+	default:
+		b.moveTo(b.caret-1, extend)
+	}
+}
+
+// moveRight moves the caret forward a rune or word. Without extend a
+// selection collapses to its end instead.
+func (b *editBuffer) moveRight(word, extend bool) {
+	switch {
+	case b.hasSelection() && !extend:
+		_, end := b.selection()
+		b.moveTo(end, false)
+	case word:
+		b.moveTo(b.wordRight(b.caret), extend)
+	default:
+		b.moveTo(b.caret+1, extend)
+	}
+}
+
+// moveVisual moves the caret a rune left or right on screen within a shaped
+// line of the text from start to end, so in right to left text left steps on
+// through the text. Word steps and collapsing a selection follow the text in
+// the direction of the line, as does stepping off either end of the line.
+func (b *editBuffer) moveVisual(l *text.Layout, start, end int, right, word, extend bool) {
+	forward := right != l.RTL
+	if word || b.hasSelection() && !extend {
+		if forward {
+			b.moveRight(word, extend)
+		} else {
+			b.moveLeft(word, extend)
+		}
+		return
+	}
+	pos := b.caret - start
+	next := l.Left(pos)
+	if right {
+		next = l.Right(pos)
+	}
+	switch {
+	case next != pos:
+		b.moveTo(start+next, extend)
+	case forward && b.caret == end:
+		b.moveRight(false, extend)
+	case !forward && b.caret == start:
+		b.moveLeft(false, extend)
+	}
+}
+
+// selectAll selects the whole text
+func (b *editBuffer) selectAll() {
+	b.anchor = 0
+	b.caret = len(b.text)
+}
+
+// insert replaces the selection with s and places the caret after it
+func (b *editBuffer) insert(s string) {
+	b.deleteSelection()
+	r := []rune(s)
+	b.text = append(b.text[:b.caret], append(r, b.text[b.caret:]...)...)
+	b.caret += len(r)
+	b.anchor = b.caret
+}
+
+// deleteSelection removes the selected text, reporting whether there was any
+func (b *editBuffer) deleteSelection() bool {
+	if !b.hasSelection() {
+		return false
+	}
+	start, end := b.selection()
+	b.text = append(b.text[:start], b.text[end:]...)
+	b.caret, b.anchor = start, start
+	return true
+}
+
+// deleteBackward removes the selection, or the rune or word before the caret
+func (b *editBuffer) deleteBackward(word bool) bool {
+	if b.deleteSelection() {
+		return true
+	}
+	if b.caret == 0 {
+		return false
+	}
+	start := b.caret - 1
+	if word {
+		start = b.wordLeft(b.caret)
+	}
+	b.anchor = start
+	return b.deleteSelection()
+}
+
+// deleteForward removes the selection, or the rune or word after the caret
+func (b *editBuffer) deleteForward(word bool) bool {
+	if b.deleteSelection() {
+		return true
+	}
+	if b.caret == len(b.text) {
+		return false
+	}
+	end := b.caret + 1
+	if word {
+		end = b.wordRight(b.caret)
+	}
+	b.anchor = end
+	return b.deleteSelection()
+}
+
+// wordLeft returns the start of the word before pos, skipping any spaces
+// and punctuation in between
+func (b *editBuffer) wordLeft(pos int) int {
+	for pos > 0 && !isWordRune(b.text[pos-1]) {
+		pos--
+	}
+	for pos > 0 && isWordRune(b.text[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+// wordRight returns the end of the word after pos, skipping any spaces and
+// punctuation in between
+func (b *editBuffer) wordRight(pos int) int {
+	for pos < len(b.text) && !isWordRune(b.text[pos]) {
+		pos++
+	}
+	for pos < len(b.text) && isWordRune(b.text[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// snapshot returns a copy of the buffer state for undo
+func (b *editBuffer) snapshot() editState {
+	return editState{text: slices.Clone(b.text), caret: b.caret, anchor: b.anchor}
+}
+
+// restore replaces the buffer state with a snapshot, taking ownership of it
+func (b *editBuffer) restore(s editState) {
+	b.text, b.caret, b.anchor = s.text, s.caret, s.anchor
+}
+
+// editState is a saved copy of an editBuffer
+type editState struct {
+	text          []rune
+	caret, anchor int
+}
+
+// editHistory records buffer states for undo and redo. Consecutive edits
+// pushed with merge set, such as typed characters, undo as one step.
+type editHistory struct {
+	undo, redo []editState
+	merging    bool
+}
+
+// push records the state from before an edit and discards the redo states
+func (h *editHistory) push(before editState, merge bool) {
+	if !merge || !h.merging {
+		h.undo = append(h.undo, before)
+		if len(h.undo) > maxUndo {
+			h.undo = slices.Delete(h.undo, 0, 1)
+		}
+	}
+	h.redo = h.redo[:0]
+	h.merging = merge
+}
+
+// breakMerge ends the current run of merged edits, such as when the caret moves
+func (h *editHistory) breakMerge() {
+	h.merging = false
+}
+
+// clear forgets all recorded states
+func (h *editHistory) clear() {
+	h.undo = h.undo[:0]
+	h.redo = h.redo[:0]
+	h.merging = false
+}
+
+// undoTo restores the state before the last edit, reporting whether there was one
+func (h *editHistory) undoTo(b *editBuffer) bool {
+	n := len(h.undo)
+	if n == 0 {
+		return false
+	}
+	h.redo = append(h.redo, b.snapshot())
+	b.restore(h.undo[n-1])
+	h.undo = h.undo[:n-1]
+	h.merging = false
+	return true
+}
+
+// redoTo reapplies the last undone edit, reporting whether there was one
+func (h *editHistory) redoTo(b *editBuffer) bool {
+	n := len(h.redo)
+	if n == 0 {
+		return false
+	}
+	h.undo = append(h.undo, b.snapshot())
+	b.restore(h.redo[n-1])
+	h.redo = h.redo[:n-1]
+	h.merging = false
+	return true
+}
+
+// caretBlink tracks the visibility of a blinking caret. The caret is shown
+// for blinkInterval after each restart, then alternates.
+type caretBlink struct {
+	start  time.Time
+	hidden bool
+}
+
+// restart shows the caret and begins a new blink cycle
+func (c *caretBlink) restart(now time.Time) {
+	c.start = now
+	c.hidden = false
+}
+
+// visible reports whether the caret is currently shown
+func (c *caretBlink) visible() bool {
+	return !c.hidden
+}
+
+// update advances the blink to the given time, reporting whether the caret
+// was shown or hidden
+func (c *caretBlink) update(now time.Time) (changed bool) {
+	hidden := now.Sub(c.start)/blinkInterval%2 == 1
+	if hidden == c.hidden {
+		return false
+	}
+	c.hidden = hidden
+	return true
+}
+
+// next returns when the caret is next shown or hidden
+func (c *caretBlink) next(now time.Time) time.Time {
+	return c.start.Add((now.Sub(c.start)/blinkInterval + 1) * blinkInterval)
+}
+
+// composition is the text an input method is composing, which the text
+// editing widgets show underlined at the caret until the input method
+// commits it as characters
+type composition struct {
+	text []rune
+	// caret is the input method's caret within the text
+	caret int
+}
+
+// update replaces the composition with the text of a preedit event
+func (c *composition) update(e interfaces.PreeditEvent) {
+	c.text = []rune(e.Text)
+	c.caret = min(max(e.Caret, 0), len(c.text))
+}
+
+// active reports whether text is being composed
+func (c *composition) active() bool {
+	return len(c.text) > 0
+}
+
+// reset ends the composition, such as when its text is committed or the
+// widget loses focus
+func (c *composition) reset() {
+	c.text, c.caret = nil, 0
+}
+
+// draw draws the composition underlined at x on a baseline, returning its
+// width and the x of the input method's caret from its start
+func (c *composition) draw(list *render.DrawList, face *text.Face, x, baseline float32, color [4]float32) (width, caret float32) {
+	s := string(c.text)
+	face.Draw(list, x, baseline, s, color)
+	width = face.Measure(s)
+	thickness := max(float32(math.Round(float64(face.Size()/12))), 1)
+	list.Rect(x, float32(math.Round(float64(baseline)))+thickness, width, thickness, color)
+	return width, face.Measure(string(c.text[:c.caret]))
+}
+
+// reportCaret tells the input method where the caret is, so its candidate
+// window opens beside the text being composed
+func reportCaret(ctx *Context, x, y, height float32) {
+	if ctx.InputMethod != nil {
+		ctx.InputMethod.SetCaret(Rect{X: x, Y: y, Width: 1, Height: height})
+	}
+}
+
+// isWordRune reports whether a rune is part of a word for word movement
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
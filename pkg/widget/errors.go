@@ -7,4 +7,6 @@ import (
 var (
 	// errInvalidDirection is returned when an invalid layout direction is specified
 	errInvalidDirection = errors.New("invalid direction")
+	// errAnchorAxis is returned when an edge is attached to an anchor on the other axis
+	errAnchorAxis = errors.New("anchor on the wrong axis")
 )
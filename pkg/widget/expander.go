@@ -0,0 +1,428 @@
+package widget
+
+import (
+	"math"
+	"time"
+
+	"github.com/mleku/goo/pkg/anim"
+	"github.com/mleku/goo/pkg/icons"
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/text"
+	"lol.mleku.dev/chk"
+)
+
+const (
+	// expanderPadding is the space around the title of an expander
+	expanderPadding = 6
+	// expanderDuration is how long an expander takes to open or close
+	expanderDuration = 200 * time.Millisecond
+)
+
+// ExpanderWidget is a header line with a title that shows or hides the
+// content below it when clicked, or when enter or space is pressed while it
+// has focus. The content slides open and closed, the expander growing and
+// shrinking with it.
+type ExpanderWidget struct {
+	Base
+	content  Widget
+	font     *text.Font
+	size     float32
+	title    string
+	expanded bool
+	hovered  bool
+	onToggle func(expanded bool)
+	// open runs from 0 when closed to 1 when open, and shown is its value in
+	// the last frame, which the expander is sized by
+	open  *anim.Tween[float32]
+	shown float32
+	// contentHeight is the height the content was laid out at
+	contentHeight float32
+	// group is the accordion the expander belongs to, nil if none
+	group *AccordionWidget
+}
+
+// Expander creates a new closed expander showing the title in the given
+// font at 14 pixels above the content
+func Expander(font *text.Font, title string, content Widget) *ExpanderWidget {
+	e := &ExpanderWidget{
+		content: content,
+		font:    font,
+		size:    14,
+		title:   title,
+		open:    anim.NewFloat(0),
+	}
+	adopt(e, content)
+	return e
+}
+
+// Size sets the pixel size of the title and returns the expander for chaining
+func (e *ExpanderWidget) Size(size float32) *ExpanderWidget {
+	e.size = size
+	e.MarkNeedsLayout()
+	return e
+}
+
+// Expanded sets whether the content starts shown and returns the expander
+// for chaining
+func (e *ExpanderWidget) Expanded(expanded bool) *ExpanderWidget {
+	e.expanded = expanded
+	e.open.Set(openness(expanded))
+	e.shown = openness(expanded)
+	e.closeOthers()
+	e.MarkNeedsLayout()
+	return e
+}
+
+// OnToggle sets the callback invoked with the new state when the user opens
+// or closes the expander, and returns the expander for chaining
+func (e *ExpanderWidget) OnToggle(fn func(expanded bool)) *ExpanderWidget {
+	e.onToggle = fn
+	return e
+}
+
+// SetExpanded opens or closes the expander, sliding the content in or out.
+// Opening an expander of an accordion closes the one open.
+func (e *ExpanderWidget) SetExpanded(expanded bool) {
+	if expanded == e.expanded {
+		return
+	}
+	e.expanded = expanded
+	e.open.To(openness(expanded), expanderDuration, anim.EaseOutCubic)
+	e.closeOthers()
+	e.MarkNeedsLayout()
+}
+
+// IsExpanded reports whether the content is shown, or being opened
+func (e *ExpanderWidget) IsExpanded() bool {
+	return e.expanded
+}
+
+// SetTitle replaces the title
+func (e *ExpanderWidget) SetTitle(title string) {
+	if title == e.title {
+		return
+	}
+	e.title = title
+	e.MarkNeedsLayout()
+}
+
+// Title returns the title
+func (e *ExpanderWidget) Title() string {
+	return e.title
+}
+
+// GetConstraints returns the height of the header and the part of the
+// content's minimum height shown
+func (e *ExpanderWidget) GetConstraints() Constraints {
+	header := e.headerHeight()
+	var content Constraints
+	if e.content != nil {
+		content = e.content.GetConstraints()
+	}
+	width := max(e.titleWidth(), content.MinWidth)
+	return NewFlexConstraints(width, header+e.shown*content.MinHeight, 1e9, 1e9)
+}
+
+// Measure returns the size of the header and the part of the content shown
+func (e *ExpanderWidget) Measure(constraints Constraints) Size {
+	content := measure(e.content, NewFlexConstraints(0, 0, constraints.MaxWidth, 1e9))
+	return Size{
+		Width:  min(max(e.titleWidth(), content.Width), constraints.MaxWidth),
+		Height: e.headerHeight() + float32(math.Ceil(float64(e.shown*content.Height))),
+	}
+}
+
+// Layout implements the Widget interface for ExpanderWidget; the content is
+// laid out at its full height below the header and cut off while opening
+// and closing
+func (e *ExpanderWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !e.NeedsLayout(ctx, constraints) {
+		return e.CachedSize(), nil
+	}
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	if e.content != nil && (e.expanded || e.shown > 0) {
+		e.contentHeight = measure(e.content, NewFlexConstraints(0, 0, size.Width, 1e9)).Height
+		if _, err = e.content.Layout(ctx, NewRigidConstraints(size.Width, e.contentHeight)); chk.E(err) {
+			return
+		}
+	}
+	e.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for ExpanderWidget
+func (e *ExpanderWidget) Paint(ctx *Context, box *Box) (err error) {
+	if shown := e.open.Value(ctx.Clock); shown != e.shown || e.open.Running() {
+		// Size the expander for the new frame of the slide. The tree is
+		// already laid out, so the relayout happens on the next frame.
+		e.shown = shown
+		e.MarkNeedsLayout()
+	}
+	e.paintHeader(ctx, box)
+	if e.content == nil || e.shown <= 0 {
+		return
+	}
+	header := e.headerHeight()
+	list := ctx.DrawList
+	list.PushClip(box.Position.X, box.Position.Y+header, box.Size.Width, max(box.Size.Height-header, 0))
+	defer list.PopClip()
+	return paintChild(ctx, e.content, e.contentBox(box))
+}
+
+// paintHeader draws the arrow and title
+func (e *ExpanderWidget) paintHeader(ctx *Context, box *Box) {
+	th := themeOf(ctx)
+	list := ctx.DrawList
+	face := e.font.Face(e.size)
+	header := e.headerHeight()
+	x, y, w := box.Position.X, box.Position.Y, box.Size.Width
+	if e.hovered {
+		list.RoundRect(x, y, w, header, th.Radius.Small, th.SurfaceHover)
+	}
+	if hasFocus(e) {
+		list.RoundRectStroke(x, y, w, header, th.Radius.Small, 1, th.Primary)
+	}
+	arrow := float32(math.Round(float64(face.LineHeight())))
+	ax := x + expanderPadding
+	tx := ax + arrow + expanderPadding
+	name := "chevron-right"
+	if ctx.RTL {
+		ax = x + w - expanderPadding - arrow
+		tx = ax - expanderPadding - face.Measure(e.title)
+		name = "chevron-left"
+	}
+	if e.expanded {
+		name = "chevron-down"
+	}
+	icons.Draw(list, name, ax, y+(header-arrow)/2, arrow, ctx.Scale, th.TextMuted)
+	baseline := y + (header-face.LineHeight())/2 + face.Ascent()
+	face.Draw(list, tx, baseline, e.title, th.Text)
+}
+
+// CursorAt implements CursorProvider, showing the hand over the header
+func (e *ExpanderWidget) CursorAt(p Point) Cursor {
+	if e.onHeader(&e.paintBox, p) {
+		return interfaces.CursorHand
+	}
+	return interfaces.CursorDefault
+}
+
+// navigable lets gamepad navigation reach the expander
+func (e *ExpanderWidget) navigable() bool {
+	return true
+}
+
+// Accessibility implements Accessible, naming the expander by its title
+func (e *ExpanderWidget) Accessibility() AccessNode {
+	node := AccessNode{
+		Role:    interfaces.RoleButton,
+		Name:    e.title,
+		State:   interfaces.StateExpandable,
+		Actions: []AccessAction{interfaces.AccessClick},
+	}
+	if e.expanded {
+		node.State |= interfaces.StateExpanded
+	}
+	return node
+}
+
+// AccessAction implements AccessActor, opening or closing the expander
+func (e *ExpanderWidget) AccessAction(ctx *Context, action AccessAction, value string) bool {
+	if action != interfaces.AccessClick {
+		return false
+	}
+	e.toggle()
+	return true
+}
+
+// HandleEvent implements the Widget interface for ExpanderWidget
+func (e *ExpanderWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch ev := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		e.setHovered(e.onHeader(box, ev.Position))
+	case interfaces.CursorLeaveEvent:
+		e.setHovered(false)
+	case interfaces.MouseButtonEvent:
+		if ev.Button == interfaces.MouseButtonLeft && ev.Action == interfaces.ActionPress && e.onHeader(box, ev.Position) {
+			requestFocus(e)
+			e.toggle()
+			return true
+		}
+	case interfaces.KeyEvent:
+		if hasFocus(e) && (ev.Key == interfaces.KeyEnter || ev.Key == interfaces.KeySpace) {
+			if ev.Action == interfaces.ActionPress {
+				e.toggle()
+			}
+			return true
+		}
+	case interfaces.CharEvent:
+		// Swallow the space typed along with the key press
+		if hasFocus(e) && ev.Char == ' ' {
+			return true
+		}
+	}
+	if e.content == nil || !e.expanded {
+		return false
+	}
+	return routeEvent(ctx, e.content, e.contentBox(box), ev)
+}
+
+// toggle opens or closes the expander for the user
+func (e *ExpanderWidget) toggle() {
+	e.SetExpanded(!e.expanded)
+	if e.onToggle != nil {
+		e.onToggle(e.expanded)
+	}
+	if e.group != nil && e.group.onChange != nil {
+		e.group.onChange(e.group.Opened())
+	}
+}
+
+// closeOthers closes the other expanders of the accordion once this one opens
+func (e *ExpanderWidget) closeOthers() {
+	if e.group != nil && e.expanded {
+		e.group.opened(e)
+	}
+}
+
+// contentBox returns the absolute box of the content below the header
+func (e *ExpanderWidget) contentBox(box *Box) *Box {
+	return NewBox(box.Position.X, box.Position.Y+e.headerHeight(), box.Size.Width, e.contentHeight, e.content.GetConstraints())
+}
+
+// onHeader reports whether a point lies on the header
+func (e *ExpanderWidget) onHeader(box *Box, p Point) bool {
+	return box.Contains(p) && p.Y < box.Position.Y+e.headerHeight()
+}
+
+// setHovered updates whether the cursor is over the header, repainting when
+// it changes
+func (e *ExpanderWidget) setHovered(hovered bool) {
+	if hovered != e.hovered {
+		e.hovered = hovered
+		e.MarkNeedsPaint()
+	}
+}
+
+// headerHeight returns the height of the header
+func (e *ExpanderWidget) headerHeight() float32 {
+	return float32(math.Ceil(float64(e.font.Face(e.size).LineHeight()))) + 2*expanderPadding
+}
+
+// titleWidth returns the width of the arrow and title
+func (e *ExpanderWidget) titleWidth() float32 {
+	face := e.font.Face(e.size)
+	return face.Measure(e.title) + float32(math.Round(float64(face.LineHeight()))) + 3*expanderPadding
+}
+
+// openness returns the value of an expander's open tween for a state
+func openness(expanded bool) float32 {
+	if expanded {
+		return 1
+	}
+	return 0
+}
+
+// AccordionWidget stacks expanders of which only one is open at a time;
+// opening one closes the one that was open
+type AccordionWidget struct {
+	Base
+	column    *Container
+	expanders []*ExpanderWidget
+	onChange  func(index int)
+}
+
+// Accordion creates a new accordion stacking the expanders. Only the first
+// of them set expanded stays open.
+func Accordion(expanders ...*ExpanderWidget) *AccordionWidget {
+	a := &AccordionWidget{column: Column()}
+	adopt(a, a.column)
+	for _, e := range expanders {
+		a.Add(e)
+	}
+	return a
+}
+
+// Add appends an expander and returns the accordion for chaining. It is
+// closed when another is open already.
+func (a *AccordionWidget) Add(e *ExpanderWidget) *AccordionWidget {
+	if e.expanded && a.Opened() >= 0 {
+		e.Expanded(false)
+	}
+	e.group = a
+	a.expanders = append(a.expanders, e)
+	a.column.Rigid(e)
+	return a
+}
+
+// OnChange sets the callback invoked with the index of the expander open,
+// -1 for none, when the user opens or closes one, and returns the accordion
+// for chaining
+func (a *AccordionWidget) OnChange(fn func(index int)) *AccordionWidget {
+	a.onChange = fn
+	return a
+}
+
+// Expanders returns the accordion's expanders
+func (a *AccordionWidget) Expanders() []*ExpanderWidget {
+	return a.expanders
+}
+
+// Opened returns the index of the expander open, -1 if none
+func (a *AccordionWidget) Opened() int {
+	for i, e := range a.expanders {
+		if e.expanded {
+			return i
+		}
+	}
+	return -1
+}
+
+// Open opens the expander at index, closing the one open, or closes them
+// all for an index out of range
+func (a *AccordionWidget) Open(index int) {
+	for i, e := range a.expanders {
+		e.SetExpanded(i == index)
+	}
+}
+
+// opened closes the other expanders once one opens
+func (a *AccordionWidget) opened(open *ExpanderWidget) {
+	for _, e := range a.expanders {
+		if e != open {
+			e.SetExpanded(false)
+		}
+	}
+}
+
+// GetConstraints returns the constraints of the stack
+func (a *AccordionWidget) GetConstraints() Constraints {
+	return a.column.GetConstraints()
+}
+
+// Measure returns the size the stack measures
+func (a *AccordionWidget) Measure(constraints Constraints) Size {
+	return a.column.Measure(constraints)
+}
+
+// Layout implements the Widget interface for AccordionWidget
+func (a *AccordionWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !a.NeedsLayout(ctx, constraints) {
+		return a.CachedSize(), nil
+	}
+	if size, err = layoutInset(ctx, a.column, Insets{}, constraints); chk.E(err) {
+		return
+	}
+	a.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for AccordionWidget
+func (a *AccordionWidget) Paint(ctx *Context, box *Box) (err error) {
+	return paintChild(ctx, a.column, box)
+}
+
+// HandleEvent implements the Widget interface for AccordionWidget
+func (a *AccordionWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	return routeEvent(ctx, a.column, box, ev)
+}
@@ -0,0 +1,1119 @@
+package widget
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/render"
+	"github.com/mleku/goo/pkg/text"
+	"lol.mleku.dev/chk"
+)
+
+const (
+	// filePadding is the space around the text of file browser rows
+	filePadding = 4
+	// fileTreeWidth is the width the directory tree starts at
+	fileTreeWidth = 200
+	// filePreviewText is the most of a text file read for its preview, and
+	// filePreviewImage the largest image file decoded
+	filePreviewText  = 8 << 10
+	filePreviewImage = 64 << 20
+)
+
+// FileSort is the column the files of a file browser are sorted by
+type FileSort int
+
+const (
+	SortByName FileSort = iota
+	SortBySize
+	SortByModified
+)
+
+// FileEntry describes a file or directory shown by a file browser
+type FileEntry struct {
+	Name, Path string
+	Dir        bool
+	Size       int64
+	Modified   time.Time
+}
+
+// FileBrowserWidget browses the file system without the system's dialogs,
+// drawn with the theme like the rest of the program. A bar of the
+// directories along the path leads back up, a tree beside the files shows
+// the directories from the home folder and the file system root, and a
+// preview shows the selected image or the start of the selected text file.
+// The files are listed with their size and modification time below headers
+// that sort by each column, directories first.
+//
+// Clicking a file selects it and double clicking it, or pressing enter,
+// opens it; directories open by showing their files. Backspace goes up a
+// directory. Use it in a dialog where the system has none, or everywhere
+// for a picker that looks the same on every platform.
+type FileBrowserWidget struct {
+	Base
+	font *text.Font
+	size float32
+	dir  string
+	// hidden shows files whose names start with a dot, and patterns limit
+	// the files shown to those matching any, all when empty
+	hidden   bool
+	patterns []string
+	sort     FileSort
+	// descending reverses the order of the files, directories staying first
+	descending bool
+	entries    []FileEntry
+	// readErr is why the directory could not be listed, nil if it was
+	readErr error
+	// selected and hot are the indexes of the selected entry and the entry
+	// under the cursor, -1 for none
+	selected, hot int
+	// lastClick and lastIndex are when and on which entry the last click
+	// landed, for telling double clicks
+	lastClick time.Time
+	lastIndex int
+
+	onSelect, onOpen, onNavigate func(path string)
+
+	crumbs  *fileCrumbs
+	tree    *TreeWidget
+	header  *fileHeader
+	list    *fileList
+	scroll  *ScrollWidget
+	preview *filePreview
+	body    Widget
+}
+
+// FileBrowser creates a new file browser showing a directory, drawn in the
+// given font at 14 pixels, with a preview of the selected file. The working
+// directory is shown when the directory cannot be.
+func FileBrowser(font *text.Font, dir string) *FileBrowserWidget {
+	b := &FileBrowserWidget{font: font, size: 14, selected: -1, hot: -1}
+	b.crumbs = &fileCrumbs{b: b, hot: -1}
+	b.header = &fileHeader{b: b}
+	b.list = &fileList{b: b}
+	b.preview = &filePreview{b: b}
+	b.scroll = Scroll(b.list).Axes(false, true)
+	b.tree = Tree(font).OnSelect(func(n *TreeNode) {
+		if path, ok := n.Data.(string); ok {
+			b.navigate(path)
+		}
+	}).Loader(b.subdirectories)
+	b.tree.SetRoots(b.roots()...)
+	b.build(true)
+	if err := b.SetDirectory(dir); err != nil {
+		wd, _ := os.Getwd()
+		chk.E(b.SetDirectory(wd))
+	}
+	return b
+}
+
+// Size sets the pixel size of the text and returns the browser for chaining
+func (b *FileBrowserWidget) Size(size float32) *FileBrowserWidget {
+	b.size = size
+	b.tree.Size(size)
+	b.relayout()
+	return b
+}
+
+// ShowHidden sets whether files and directories whose names start with a
+// dot are shown and returns the browser for chaining
+func (b *FileBrowserWidget) ShowHidden(hidden bool) *FileBrowserWidget {
+	b.hidden = hidden
+	b.tree.SetRoots(b.roots()...)
+	b.Refresh()
+	return b
+}
+
+// Filter limits the files shown to those whose names match any of the
+// patterns, such as "*.png", and returns the browser for chaining.
+// Directories are always shown; no patterns shows every file.
+func (b *FileBrowserWidget) Filter(patterns ...string) *FileBrowserWidget {
+	b.patterns = patterns
+	b.Refresh()
+	return b
+}
+
+// Preview sets whether the selected file is previewed beside the list and
+// returns the browser for chaining
+func (b *FileBrowserWidget) Preview(preview bool) *FileBrowserWidget {
+	b.build(preview)
+	return b
+}
+
+// SortBy sorts the files by a column, in descending order if set, and
+// returns the browser for chaining. Clicking a column's header sorts by it
+// and clicking again reverses the order.
+func (b *FileBrowserWidget) SortBy(column FileSort, descending bool) *FileBrowserWidget {
+	b.sort, b.descending = column, descending
+	b.sortEntries()
+	return b
+}
+
+// OnSelect sets the callback invoked with the path of the file or
+// directory the user selects and returns the browser for chaining
+func (b *FileBrowserWidget) OnSelect(fn func(path string)) *FileBrowserWidget {
+	b.onSelect = fn
+	return b
+}
+
+// OnOpen sets the callback invoked with the path of the file the user
+// opens by double clicking it or pressing enter, and returns the browser
+// for chaining
+func (b *FileBrowserWidget) OnOpen(fn func(path string)) *FileBrowserWidget {
+	b.onOpen = fn
+	return b
+}
+
+// OnNavigate sets the callback invoked with the directory the user moves to
+// and returns the browser for chaining
+func (b *FileBrowserWidget) OnNavigate(fn func(dir string)) *FileBrowserWidget {
+	b.onNavigate = fn
+	return b
+}
+
+// SetDirectory shows the files of a directory without invoking the navigate
+// callback, returning an error when it is not a directory
+func (b *FileBrowserWidget) SetDirectory(dir string) (err error) {
+	if dir, err = filepath.Abs(dir); chk.E(err) {
+		return
+	}
+	var info os.FileInfo
+	if info, err = os.Stat(dir); chk.E(err) {
+		return
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	b.dir = dir
+	b.selected, b.hot = -1, -1
+	b.preview.show(nil)
+	b.Refresh()
+	b.scroll.ScrollTo(0, 0)
+	b.selectTree()
+	return
+}
+
+// Directory returns the directory shown
+func (b *FileBrowserWidget) Directory() string {
+	return b.dir
+}
+
+// Entries returns the files and directories shown, in the order shown
+func (b *FileBrowserWidget) Entries() []FileEntry {
+	return b.entries
+}
+
+// Selected returns the path of the selected file or directory, empty when
+// none is selected
+func (b *FileBrowserWidget) Selected() string {
+	if b.selected < 0 {
+		return ""
+	}
+	return b.entries[b.selected].Path
+}
+
+// Select selects the file or directory with a name in the directory shown
+// without invoking the select callback, reporting whether there is one
+func (b *FileBrowserWidget) Select(name string) bool {
+	for i, e := range b.entries {
+		if e.Name == name {
+			b.selected = i
+			b.preview.show(&b.entries[i])
+			b.list.MarkNeedsPaint()
+			return true
+		}
+	}
+	return false
+}
+
+// Refresh lists the directory again, after files changed, keeping the
+// selection when its file is still there
+func (b *FileBrowserWidget) Refresh() {
+	selected := b.Selected()
+	b.entries, b.readErr = b.entries[:0], nil
+	var list []os.DirEntry
+	if list, b.readErr = os.ReadDir(b.dir); b.readErr == nil {
+		for _, d := range list {
+			name := d.Name()
+			if !b.hidden && strings.HasPrefix(name, ".") {
+				continue
+			}
+			info, err := d.Info()
+			if err != nil {
+				continue
+			}
+			// Links to directories open like directories
+			dir := info.IsDir()
+			if info.Mode()&os.ModeSymlink != 0 {
+				if target, err := os.Stat(filepath.Join(b.dir, name)); err == nil {
+					dir = target.IsDir()
+				}
+			}
+			if !dir && !b.matches(name) {
+				continue
+			}
+			b.entries = append(b.entries, FileEntry{
+				Name:     name,
+				Path:     filepath.Join(b.dir, name),
+				Dir:      dir,
+				Size:     info.Size(),
+				Modified: info.ModTime(),
+			})
+		}
+	}
+	b.selected, b.hot = -1, -1
+	b.sortEntries()
+	if selected != "" {
+		b.Select(filepath.Base(selected))
+	}
+	b.relayout()
+}
+
+// matches reports whether a file name matches the filter
+func (b *FileBrowserWidget) matches(name string) bool {
+	if len(b.patterns) == 0 {
+		return true
+	}
+	for _, p := range b.patterns {
+		if ok, _ := filepath.Match(strings.ToLower(p), strings.ToLower(name)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// sortEntries orders the entries by the sort column with directories first,
+// keeping the selection
+func (b *FileBrowserWidget) sortEntries() {
+	selected := b.Selected()
+	slices.SortStableFunc(b.entries, func(x, y FileEntry) int {
+		if x.Dir != y.Dir {
+			if x.Dir {
+				return -1
+			}
+			return 1
+		}
+		var c int
+		switch b.sort {
+		case SortBySize:
+			c = cmp.Compare(x.Size, y.Size)
+		case SortByModified:
+			c = x.Modified.Compare(y.Modified)
+		}
+		if c == 0 {
+			c = cmp.Or(cmp.Compare(strings.ToLower(x.Name), strings.ToLower(y.Name)), cmp.Compare(x.Name, y.Name))
+		}
+		if b.descending {
+			c = -c
+		}
+		return c
+	})
+	b.selected = slices.IndexFunc(b.entries, func(e FileEntry) bool { return e.Path == selected && selected != "" })
+	b.hot = -1
+	b.list.MarkNeedsPaint()
+	b.header.MarkNeedsPaint()
+}
+
+// navigate shows a directory on behalf of the user, invoking the navigate
+// callback
+func (b *FileBrowserWidget) navigate(dir string) {
+	if dir == b.dir {
+		return
+	}
+	if err := b.SetDirectory(dir); err != nil {
+		return
+	}
+	if b.onNavigate != nil {
+		b.onNavigate(b.dir)
+	}
+}
+
+// pick selects an entry on behalf of the user, scrolling it into view and
+// invoking the select callback when the selection changed
+func (b *FileBrowserWidget) pick(i int) {
+	if i < 0 || i >= len(b.entries) {
+		return
+	}
+	box := b.list.paintBox
+	rowHeight := b.rowHeight()
+	scrollIntoView(b.list, Rect{X: box.Position.X, Y: box.Position.Y + float32(i)*rowHeight, Width: 1, Height: rowHeight})
+	if i == b.selected {
+		return
+	}
+	b.selected = i
+	b.preview.show(&b.entries[i])
+	b.list.MarkNeedsPaint()
+	if b.onSelect != nil {
+		b.onSelect(b.entries[i].Path)
+	}
+}
+
+// activate opens an entry on behalf of the user: directories are shown and
+// files passed to the open callback
+func (b *FileBrowserWidget) activate(i int) {
+	if i < 0 || i >= len(b.entries) {
+		return
+	}
+	e := b.entries[i]
+	if e.Dir {
+		b.navigate(e.Path)
+		return
+	}
+	if b.onOpen != nil {
+		b.onOpen(e.Path)
+	}
+}
+
+// up shows the parent directory on behalf of the user, selecting the
+// directory it came from
+func (b *FileBrowserWidget) up() {
+	from := b.dir
+	parent := filepath.Dir(from)
+	if parent == from {
+		return
+	}
+	b.navigate(parent)
+	if b.Select(filepath.Base(from)) {
+		b.pick(b.selected)
+	}
+}
+
+// build assembles the parts of the browser, with or without the preview
+func (b *FileBrowserWidget) build(preview bool) {
+	files := Widget(Column().Rigid(b.header).Flex(b.scroll, 1))
+	if preview {
+		files = Split(files, b.preview).Ratio(0.65)
+	}
+	b.body = Column().
+		Rigid(b.crumbs).
+		Flex(Split(Scroll(b.tree).Axes(false, true), files).Position(fileTreeWidth), 1)
+	adopt(b, b.body)
+	b.MarkNeedsLayout()
+}
+
+// relayout lays out the parts drawing the entries again
+func (b *FileBrowserWidget) relayout() {
+	b.crumbs.MarkNeedsLayout()
+	b.header.MarkNeedsLayout()
+	b.list.MarkNeedsLayout()
+	b.preview.MarkNeedsLayout()
+}
+
+// roots returns the nodes at the top of the directory tree: the home folder
+// and the root of the file system
+func (b *FileBrowserWidget) roots() (roots []*TreeNode) {
+	if home, err := os.UserHomeDir(); err == nil {
+		roots = append(roots, &TreeNode{Label: filepath.Base(home), Data: home, HasChildren: true})
+	}
+	wd, _ := os.Getwd()
+	root := filepath.VolumeName(wd) + string(filepath.Separator)
+	return append(roots, &TreeNode{Label: root, Data: root, HasChildren: true})
+}
+
+// subdirectories loads the children of a node of the directory tree
+func (b *FileBrowserWidget) subdirectories(n *TreeNode) (children []*TreeNode) {
+	dir, _ := n.Data.(string)
+	list, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, d := range list {
+		name := d.Name()
+		if !d.IsDir() || (!b.hidden && strings.HasPrefix(name, ".")) {
+			continue
+		}
+		children = append(children, &TreeNode{Label: name, Data: filepath.Join(dir, name), HasChildren: true})
+	}
+	slices.SortFunc(children, func(x, y *TreeNode) int {
+		return cmp.Compare(strings.ToLower(x.Label), strings.ToLower(y.Label))
+	})
+	return
+}
+
+// selectTree selects the directory shown in the tree, expanding the nodes
+// leading to it from the root it lies deepest within
+func (b *FileBrowserWidget) selectTree() {
+	var from *TreeNode
+	var rel string
+	for _, root := range b.tree.Roots() {
+		path, _ := root.Data.(string)
+		r, err := filepath.Rel(path, b.dir)
+		if err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if from == nil || len(path) > len(from.Data.(string)) {
+			from, rel = root, r
+		}
+	}
+	if from == nil {
+		b.tree.Select(nil)
+		return
+	}
+	n := from
+	if rel != "." {
+		for _, part := range strings.Split(rel, string(filepath.Separator)) {
+			b.tree.Expand(n)
+			i := slices.IndexFunc(n.Children, func(c *TreeNode) bool { return c.Label == part })
+			if i < 0 {
+				break
+			}
+			n = n.Children[i]
+		}
+	}
+	b.tree.Select(n)
+}
+
+// face returns the face the browser draws text in
+func (b *FileBrowserWidget) face() *text.Face {
+	return b.font.Face(b.size)
+}
+
+// rowHeight returns the height of each row of the list, header and bar
+func (b *FileBrowserWidget) rowHeight() float32 {
+	return float32(math.Ceil(float64(b.face().LineHeight()))) + 2*filePadding
+}
+
+// columns returns where the size and modified columns start across a width,
+// the name column taking the rest from the left
+func (b *FileBrowserWidget) columns(x, width float32) (size, modified float32) {
+	face := b.face()
+	modified = x + width - face.Measure("0000-00-00 00:00") - 2*filePadding
+	size = modified - face.Measure("000.0 MB") - 2*filePadding
+	return
+}
+
+// GetConstraints returns the constraints of the parts
+func (b *FileBrowserWidget) GetConstraints() Constraints {
+	return b.body.GetConstraints()
+}
+
+// Measure returns the size the parts measure
+func (b *FileBrowserWidget) Measure(constraints Constraints) Size {
+	return measure(b.body, constraints)
+}
+
+// Layout implements the Widget interface for FileBrowserWidget; the parts
+// fill the browser's box
+func (b *FileBrowserWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !b.NeedsLayout(ctx, constraints) {
+		return b.CachedSize(), nil
+	}
+	if size, err = layoutInset(ctx, b.body, Insets{}, constraints); chk.E(err) {
+		return
+	}
+	b.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for FileBrowserWidget
+func (b *FileBrowserWidget) Paint(ctx *Context, box *Box) (err error) {
+	return paintChild(ctx, b.body, box)
+}
+
+// HandleEvent implements the Widget interface for FileBrowserWidget
+func (b *FileBrowserWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	return routeEvent(ctx, b.body, box, ev)
+}
+
+// fileCrumb is a directory along the path shown by the bar of a file
+// browser and where it is drawn
+type fileCrumb struct {
+	label, path string
+	x, width    float32
+}
+
+// fileCrumbs is the bar of a file browser holding a button going up a
+// directory and the directories along the path, each leading back to it
+type fileCrumbs struct {
+	Base
+	b *FileBrowserWidget
+	// hot is the index of the crumb under the cursor, -1 for none and
+	// crumbUp for the up button
+	hot int
+}
+
+// crumbUp stands for the up button of the bar among the crumbs
+const crumbUp = -2
+
+// crumbs returns the directories along the path that fit the bar, from
+// the root to the directory shown, placed after the up button. Those nearest
+// the root are left out when they do not all fit, elided by an ellipsis.
+func (c *fileCrumbs) crumbs(box *Box) (crumbs []fileCrumb, elided bool) {
+	face := c.b.face()
+	dir := c.b.dir
+	for {
+		parent := filepath.Dir(dir)
+		label := filepath.Base(dir)
+		if parent == dir {
+			label = dir
+		}
+		crumbs = append(crumbs, fileCrumb{label: label, path: dir, width: face.Measure(label) + 2*filePadding})
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	slices.Reverse(crumbs)
+	separator := face.Measure(" › ")
+	start := box.Position.X + c.b.rowHeight() + filePadding
+	avail := box.Size.Width - (start - box.Position.X)
+	total := float32(0)
+	for _, cr := range crumbs {
+		total += cr.width + separator
+	}
+	for len(crumbs) > 1 && total > avail {
+		total -= crumbs[0].width + separator
+		crumbs = crumbs[1:]
+		elided = true
+	}
+	x := start
+	if elided {
+		x += face.Measure("…") + separator
+	}
+	for i := range crumbs {
+		crumbs[i].x = x
+		x += crumbs[i].width + separator
+	}
+	return
+}
+
+// GetConstraints returns a row as tall as a line of text
+func (c *fileCrumbs) GetConstraints() Constraints {
+	h := c.b.rowHeight() + 2*filePadding
+	return NewFlexConstraints(0, h, 1e9, h)
+}
+
+// Measure returns the height of the bar
+func (c *fileCrumbs) Measure(constraints Constraints) Size {
+	return minSize(c.GetConstraints())
+}
+
+// Layout implements the Widget interface for fileCrumbs
+func (c *fileCrumbs) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: c.GetConstraints().MinHeight}
+	c.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for fileCrumbs
+func (c *fileCrumbs) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	list := ctx.DrawList
+	face := c.b.face()
+	rowHeight := c.b.rowHeight()
+	y := box.Position.Y + filePadding
+	baseline := y + filePadding + face.Ascent()
+	list.Rect(box.Position.X, box.Position.Y+box.Size.Height-1, box.Size.Width, 1, th.Border)
+	// The up button is an arrow, muted at the root
+	ux := box.Position.X + filePadding
+	if c.hot == crumbUp {
+		list.RoundRect(ux, y, rowHeight, rowHeight, th.Radius.Small, th.SurfaceHover)
+	}
+	arrow := th.Text
+	if filepath.Dir(c.b.dir) == c.b.dir {
+		arrow = th.TextMuted
+	}
+	cx, cy, half := ux+rowHeight/2, y+rowHeight/2, rowHeight/5
+	list.Polyline([][2]float32{{cx - half, cy + half/2}, {cx, cy - half/2}, {cx + half, cy + half/2}}, false, 1.5, arrow)
+	crumbs, elided := c.crumbs(box)
+	if elided {
+		face.Draw(list, crumbs[0].x-face.Measure("… › "), baseline, "… › ", th.TextMuted)
+	}
+	for i, cr := range crumbs {
+		if i == c.hot {
+			list.RoundRect(cr.x, y, cr.width, rowHeight, th.Radius.Small, th.SurfaceHover)
+		}
+		color := th.Text
+		if i < len(crumbs)-1 {
+			color = th.TextMuted
+			face.Draw(list, cr.x+cr.width, baseline, " › ", th.TextMuted)
+		}
+		face.Draw(list, cr.x+filePadding, baseline, cr.label, color)
+	}
+	return
+}
+
+// HandleEvent implements the Widget interface for fileCrumbs
+func (c *fileCrumbs) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		c.setHot(c.at(box, e.Position))
+	case interfaces.CursorLeaveEvent:
+		c.setHot(-1)
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft || e.Action != interfaces.ActionPress {
+			return false
+		}
+		switch i := c.at(box, e.Position); {
+		case i == crumbUp:
+			c.b.up()
+		case i >= 0:
+			crumbs, _ := c.crumbs(box)
+			c.b.navigate(crumbs[i].path)
+		}
+		return true
+	}
+	return false
+}
+
+// at returns the index of the crumb at a point, crumbUp for the up button
+// and -1 for none
+func (c *fileCrumbs) at(box *Box, p Point) int {
+	if !box.Contains(p) {
+		return -1
+	}
+	if p.X < box.Position.X+filePadding+c.b.rowHeight() {
+		return crumbUp
+	}
+	crumbs, _ := c.crumbs(box)
+	for i, cr := range crumbs {
+		if p.X >= cr.x && p.X < cr.x+cr.width {
+			return i
+		}
+	}
+	return -1
+}
+
+// setHot updates the crumb under the cursor, repainting when it changes
+func (c *fileCrumbs) setHot(i int) {
+	if i != c.hot {
+		c.hot = i
+		c.MarkNeedsPaint()
+	}
+}
+
+// fileHeader is the row of column titles above the files of a file browser,
+// which sort the files when clicked
+type fileHeader struct {
+	Base
+	b *FileBrowserWidget
+}
+
+// GetConstraints returns a row as tall as the rows of files
+func (h *fileHeader) GetConstraints() Constraints {
+	height := h.b.rowHeight()
+	return NewFlexConstraints(0, height, 1e9, height)
+}
+
+// Measure returns the height of the header
+func (h *fileHeader) Measure(constraints Constraints) Size {
+	return minSize(h.GetConstraints())
+}
+
+// Layout implements the Widget interface for fileHeader
+func (h *fileHeader) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: h.b.rowHeight()}
+	h.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for fileHeader
+func (h *fileHeader) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	list := ctx.DrawList
+	face := h.b.face()
+	x, y, w := box.Position.X, box.Position.Y, box.Size.Width
+	list.Rect(x, y, w, box.Size.Height, th.Surface)
+	list.Rect(x, y+box.Size.Height-1, w, 1, th.Border)
+	_, modified := h.b.columns(x, w)
+	baseline := y + filePadding + face.Ascent()
+	titles := []struct {
+		title string
+		x     float32
+		right bool
+		sort  FileSort
+	}{
+		{"Name", x + h.b.rowHeight(), false, SortByName},
+		{"Size", modified - filePadding, true, SortBySize},
+		{"Modified", modified + filePadding, false, SortByModified},
+	}
+	for _, t := range titles {
+		tx := t.x
+		if t.right {
+			tx -= face.Measure(t.title)
+		}
+		face.Draw(list, tx, baseline, t.title, th.TextMuted)
+		if t.sort != h.b.sort {
+			continue
+		}
+		// A triangle beside the sorting column points the way it sorts
+		ax := tx + face.Measure(t.title) + filePadding
+		if t.right {
+			ax = tx - filePadding - treeArrow
+		}
+		ay := y + box.Size.Height/2
+		half := float32(treeArrow / 2)
+		if h.b.descending {
+			list.Polygon([][2]float32{{ax, ay - half/2}, {ax + treeArrow, ay - half/2}, {ax + half, ay + half/2}}, th.TextMuted)
+		} else {
+			list.Polygon([][2]float32{{ax, ay + half/2}, {ax + treeArrow, ay + half/2}, {ax + half, ay - half/2}}, th.TextMuted)
+		}
+	}
+	return
+}
+
+// HandleEvent implements the Widget interface for fileHeader
+func (h *fileHeader) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	e, ok := ev.(interfaces.MouseButtonEvent)
+	if !ok || e.Button != interfaces.MouseButtonLeft || e.Action != interfaces.ActionPress || !box.Contains(e.Position) {
+		return false
+	}
+	size, modified := h.b.columns(box.Position.X, box.Size.Width)
+	column := SortByName
+	switch {
+	case e.Position.X >= modified:
+		column = SortByModified
+	case e.Position.X >= size:
+		column = SortBySize
+	}
+	descending := false
+	if column == h.b.sort {
+		descending = !h.b.descending
+	}
+	h.b.SortBy(column, descending)
+	return true
+}
+
+// fileList is the rows of files of a file browser
+type fileList struct {
+	Base
+	b *FileBrowserWidget
+}
+
+// GetConstraints returns a minimum height holding every row
+func (l *fileList) GetConstraints() Constraints {
+	rows := max(len(l.b.entries), 1)
+	return NewFlexConstraints(0, float32(rows)*l.b.rowHeight(), 1e9, 1e9)
+}
+
+// Measure returns the minimum size of the list
+func (l *fileList) Measure(constraints Constraints) Size {
+	return minSize(l.GetConstraints())
+}
+
+// Layout implements the Widget interface for fileList; lists take all the
+// space offered
+func (l *fileList) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !l.NeedsLayout(ctx, constraints) {
+		return l.CachedSize(), nil
+	}
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	l.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for fileList
+func (l *fileList) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	list := ctx.DrawList
+	b := l.b
+	face := b.face()
+	rowHeight := b.rowHeight()
+	x, w := box.Position.X, box.Size.Width
+	if b.readErr != nil || len(b.entries) == 0 {
+		message := "No files"
+		if b.readErr != nil {
+			message = "Cannot read this folder"
+			if os.IsPermission(b.readErr) {
+				message = "Permission denied"
+			}
+		}
+		face.Draw(list, x+rowHeight, box.Position.Y+filePadding+face.Ascent(), message, th.TextMuted)
+		return
+	}
+	size, modified := b.columns(x, w)
+	focused := hasFocus(l)
+	icon := rowHeight - 2*filePadding
+	for i, e := range b.entries {
+		y := box.Position.Y + float32(i)*rowHeight
+		if !ctx.Clip.Empty() && (y+rowHeight <= ctx.Clip.Y || y >= ctx.Clip.Y+ctx.Clip.Height) {
+			continue
+		}
+		switch i {
+		case b.selected:
+			list.Rect(x, y, w, rowHeight, th.Selection)
+			if focused {
+				list.RoundRectStroke(x, y, w, rowHeight, th.Radius.Small, 1, th.Primary)
+			}
+		case b.hot:
+			list.Rect(x, y, w, rowHeight, th.SurfaceHover)
+		}
+		paintFileIcon(list, x+filePadding, y+filePadding, icon, e.Dir, th.Primary, th.TextMuted)
+		baseline := y + filePadding + face.Ascent()
+		list.PushClip(x+rowHeight, y, size-x-rowHeight-filePadding, rowHeight)
+		face.Draw(list, x+rowHeight, baseline, e.Name, th.Text)
+		list.PopClip()
+		if !e.Dir {
+			s := formatFileSize(e.Size)
+			face.Draw(list, modified-filePadding-face.Measure(s), baseline, s, th.TextMuted)
+		}
+		face.Draw(list, modified+filePadding, baseline, e.Modified.Format("2006-01-02 15:04"), th.TextMuted)
+	}
+	return
+}
+
+// paintFileIcon draws a folder, or a page for a file, in a square
+func paintFileIcon(list *render.DrawList, x, y, size float32, dir bool, folder, page [4]float32) {
+	if dir {
+		tab := size * 0.4
+		list.RoundRect(x, y+size*0.15, tab, size*0.2, 1, folder)
+		list.RoundRect(x, y+size*0.25, size, size*0.65, 2, folder)
+		return
+	}
+	w := size * 0.75
+	list.RoundRectStroke(x+(size-w)/2, y, w, size, 1.5, 1, page)
+	for i := range 3 {
+		ly := y + size*(0.3+0.2*float32(i))
+		list.Rect(x+(size-w)/2+w*0.2, ly, w*0.6, 1, page)
+	}
+}
+
+// navigable lets gamepad navigation reach the list
+func (l *fileList) navigable() bool {
+	return true
+}
+
+// Accessibility implements Accessible, with the name of the selected entry
+// as the value
+func (l *fileList) Accessibility() AccessNode {
+	node := AccessNode{Role: interfaces.RoleList, Name: l.b.dir}
+	if l.b.selected >= 0 {
+		node.Value = l.b.entries[l.b.selected].Name
+	}
+	return node
+}
+
+// HandleEvent implements the Widget interface for fileList
+func (l *fileList) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	b := l.b
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		l.setHot(l.rowAt(box, e.Position))
+	case interfaces.CursorLeaveEvent:
+		l.setHot(-1)
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft || e.Action != interfaces.ActionPress {
+			return false
+		}
+		requestFocus(l)
+		i := l.rowAt(box, e.Position)
+		if i < 0 {
+			return true
+		}
+		now := frameTime(ctx)
+		double := i == b.lastIndex && now.Sub(b.lastClick) <= doubleTapDelay
+		b.lastClick, b.lastIndex = now, i
+		b.pick(i)
+		if double {
+			b.lastClick = time.Time{}
+			b.activate(i)
+		}
+		return true
+	case interfaces.KeyEvent:
+		if !hasFocus(l) || e.Action == interfaces.ActionRelease {
+			return false
+		}
+		last := len(b.entries) - 1
+		switch e.Key {
+		case interfaces.KeyUp:
+			b.pick(max(b.selected-1, 0))
+		case interfaces.KeyDown:
+			b.pick(min(b.selected+1, last))
+		case interfaces.KeyHome:
+			b.pick(0)
+		case interfaces.KeyEnd:
+			b.pick(last)
+		case interfaces.KeyEnter:
+			b.activate(b.selected)
+		case interfaces.KeyBackspace:
+			b.up()
+		default:
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// rowAt returns the index of the entry under a point, -1 if none
+func (l *fileList) rowAt(box *Box, p Point) int {
+	if !box.Contains(p) {
+		return -1
+	}
+	i := int((p.Y - box.Position.Y) / l.b.rowHeight())
+	if i < 0 || i >= len(l.b.entries) {
+		return -1
+	}
+	return i
+}
+
+// setHot updates the entry under the cursor, repainting when it changes
+func (l *fileList) setHot(i int) {
+	if i != l.b.hot {
+		l.b.hot = i
+		l.MarkNeedsPaint()
+	}
+}
+
+// filePreview shows the selected file of a file browser: images scaled to
+// fit, the start of text files and the size and time of anything else
+type filePreview struct {
+	Base
+	b     *FileBrowserWidget
+	entry *FileEntry
+	// texture is the image previewed and lines the text, nil for neither
+	texture *render.Texture
+	lines   []string
+}
+
+// show previews an entry, nil for none
+func (p *filePreview) show(e *FileEntry) {
+	if p.texture != nil {
+		p.texture.Dispose()
+	}
+	p.entry, p.texture, p.lines = nil, nil, nil
+	p.MarkNeedsPaint()
+	if e == nil {
+		return
+	}
+	// The entry is copied as the browser's entries are reused when it lists
+	// the directory again
+	entry := *e
+	p.entry = &entry
+	if e.Dir {
+		return
+	}
+	switch strings.ToLower(filepath.Ext(e.Name)) {
+	case ".png", ".jpg", ".jpeg":
+		if e.Size > filePreviewImage {
+			return
+		}
+		f, err := os.Open(e.Path)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+		if img, _, err := image.Decode(f); err == nil {
+			p.texture = render.TextureFromImage(img)
+		}
+		return
+	}
+	f, err := os.Open(e.Path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	data := make([]byte, filePreviewText)
+	n, _ := f.Read(data)
+	data = data[:n]
+	// Text is valid UTF-8 without NUL bytes, cut at the last whole line
+	// when the file is longer than what was read
+	if n == filePreviewText {
+		if i := bytes.LastIndexByte(data, '\n'); i > 0 {
+			data = data[:i]
+		}
+	}
+	if n == 0 || bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data) {
+		return
+	}
+	s := strings.ReplaceAll(strings.ReplaceAll(string(data), "\r\n", "\n"), "\t", "    ")
+	p.lines = strings.Split(s, "\n")
+}
+
+// GetConstraints returns flexible constraints
+func (p *filePreview) GetConstraints() Constraints {
+	return NewFlexConstraints(0, 0, 1e9, 1e9)
+}
+
+// Measure returns the minimum size of the preview
+func (p *filePreview) Measure(constraints Constraints) Size {
+	return minSize(p.GetConstraints())
+}
+
+// Layout implements the Widget interface for filePreview; previews take all
+// the space offered
+func (p *filePreview) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	p.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for filePreview
+func (p *filePreview) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	list := ctx.DrawList
+	x, y, w, h := box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height
+	list.Rect(x, y, w, h, th.Field)
+	list.Rect(x, y, 1, h, th.Border)
+	e := p.entry
+	if e == nil {
+		return
+	}
+	face := p.b.face()
+	pad := float32(2 * filePadding)
+	list.PushClip(x, y, w, h)
+	defer list.PopClip()
+	line := face.LineHeight()
+	face.Draw(list, x+pad, y+pad+face.Ascent(), e.Name, th.Text)
+	details := "Folder"
+	if !e.Dir {
+		details = formatFileSize(e.Size)
+	}
+	details += " · " + e.Modified.Format("2006-01-02 15:04")
+	face.Draw(list, x+pad, y+pad+line+face.Ascent(), details, th.TextMuted)
+	top := y + 2*pad + 2*line
+	switch {
+	case p.texture != nil:
+		// Fit the image below the details, never enlarging it
+		aw, ah := w-2*pad, y+h-pad-top
+		scale := min(aw/float32(p.texture.Width), ah/float32(p.texture.Height), 1)
+		if scale <= 0 {
+			return
+		}
+		iw, ih := float32(p.texture.Width)*scale, float32(p.texture.Height)*scale
+		list.Image(p.texture, x+pad+(aw-iw)/2, top, iw, ih, 0, 0, 1, 1, [4]float32{1, 1, 1, 1})
+	case p.lines != nil:
+		small := p.b.font.Face(p.b.size * 0.85)
+		step := small.LineHeight()
+		for i, l := range p.lines {
+			ly := top + float32(i)*step
+			if ly > y+h {
+				break
+			}
+			small.Draw(list, x+pad, ly+small.Ascent(), l, th.Text)
+		}
+	}
+	return
+}
+
+// HandleEvent implements the Widget interface for filePreview; previews
+// ignore input
+func (p *filePreview) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	return false
+}
+
+// formatFileSize writes a size in bytes in the largest unit below it, with
+// one decimal
+func formatFileSize(n int64) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+	v := float64(n)
+	for _, unit := range []string{"KB", "MB", "GB", "TB"} {
+		v /= 1024
+		if v < 1024 || unit == "TB" {
+			return fmt.Sprintf("%.1f %s", v, unit)
+		}
+	}
+	return ""
+}
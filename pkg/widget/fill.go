@@ -1,25 +1,84 @@
 package widget
 
 import (
-	"github.com/go-gl/gl/all-core/gl"
+	"time"
+
+	"github.com/mleku/goo/pkg/anim"
+	"github.com/mleku/goo/pkg/render"
 )
 
-// Filler is a widget that fills its box with a solid color
+// Filler is a widget that fills its box with a solid color or a gradient, as
+// a rectangle with optionally rounded corners or as the ellipse inside the
+// box, and optionally only as an outline
 type Filler struct {
-	color [4]float32
+	Base
+	color *anim.Tween[[4]float32]
+	// brush paints the fill instead of the color when set
+	brush   render.Brush
+	radius  float32
+	ellipse bool
+	// outline is the width of the outline drawn instead of filling, 0 to fill
+	outline float32
 }
 
 // Fill creates a new Fill widget that fills its container with the specified color.
 // The fill always fills to the edge of its box when calculated.
 func Fill(red, green, blue, alpha float32) *Filler {
 	return &Filler{
-		color: [4]float32{red, green, blue, alpha},
+		color: anim.NewColor([4]float32{red, green, blue, alpha}),
 	}
 }
 
-// SetColor updates the fill color
+// GradientFill creates a new Fill widget painted with a brush, usually a
+// linear or radial gradient, whose positions are relative to the fill's box
+func GradientFill(brush render.Brush) *Filler {
+	f := Fill(0, 0, 0, 0)
+	f.brush = brush
+	return f
+}
+
+// SetColor updates the fill color, replacing any brush
 func (f *Filler) SetColor(red, green, blue, alpha float32) {
-	f.color = [4]float32{red, green, blue, alpha}
+	f.color.Set([4]float32{red, green, blue, alpha})
+	f.brush = render.Brush{}
+	f.MarkNeedsPaint()
+}
+
+// SetBrush paints the fill with a brush instead of its color
+func (f *Filler) SetBrush(brush render.Brush) {
+	f.brush = brush
+	f.MarkNeedsPaint()
+}
+
+// AnimateColor fades the fill color to a new color over the duration,
+// replacing any brush
+func (f *Filler) AnimateColor(red, green, blue, alpha float32, duration time.Duration, ease anim.Easing) {
+	f.brush = render.Brush{}
+	f.color.To([4]float32{red, green, blue, alpha}, duration, ease)
+	f.MarkNeedsPaint()
+}
+
+// Radius rounds the corners of the fill to radius and returns the fill for chaining
+func (f *Filler) Radius(radius float32) *Filler {
+	f.radius = radius
+	f.MarkNeedsPaint()
+	return f
+}
+
+// Ellipse makes the fill the ellipse touching the edges of its box, a circle
+// in a square box, and returns the fill for chaining
+func (f *Filler) Ellipse() *Filler {
+	f.ellipse = true
+	f.MarkNeedsPaint()
+	return f
+}
+
+// Outline draws only an outline of the given width inside the edges of the
+// shape, 0 to fill it, and returns the fill for chaining
+func (f *Filler) Outline(width float32) *Filler {
+	f.outline = width
+	f.MarkNeedsPaint()
+	return f
 }
 
 // GetConstraints returns the size constraints for this Fill widget
@@ -28,38 +87,67 @@ func (f *Filler) GetConstraints() Constraints {
 	return NewFlexConstraints(0, 0, 1e9, 1e9)
 }
 
-// Render implements the Widget interface for Fill
-func (f *Filler) Render(ctx *Context, box *Box) (usedSize Size, err error) {
-	// Convert to OpenGL coordinates (bottom-left origin) from top-left origin
-	// Box Y is from top, convert to bottom-left
-	bottomY := float32(ctx.WindowHeight) - box.Position.Y - box.Size.Height
-
-	// Create vertices for the quad (exact pixel boundaries)
-	x1, y1 := box.Position.X, float32(ctx.WindowHeight)-box.Position.Y
-	x2, y2 := box.Position.X+box.Size.Width, float32(ctx.WindowHeight)-box.Position.Y
-	x3, y3 := box.Position.X+box.Size.Width, float32(ctx.WindowHeight)-box.Position.Y-box.Size.Height
-	x4, y4 := box.Position.X, float32(ctx.WindowHeight)-box.Position.Y-box.Size.Height
-
-	// Enable scissor test with proper boundaries to enforce clipping
-	// This prevents artifacts at the edges by ensuring rendering doesn't go beyond boundaries
-	gl.Scissor(
-		int32(box.Position.X),
-		int32(bottomY),
-		int32(box.Size.Width),
-		int32(box.Size.Height),
-	)
-
-	// Set the color
-	gl.Color4f(f.color[0], f.color[1], f.color[2], f.color[3])
-
-	// Draw using immediate mode
-	// The scissor test will clip these vertices to the exact boundaries
-	gl.Begin(gl.QUADS)
-	gl.Vertex2f(x1, y1)
-	gl.Vertex2f(x2, y2)
-	gl.Vertex2f(x3, y3)
-	gl.Vertex2f(x4, y4)
-	gl.End()
-
-	return box.Size, nil
+// Measure returns the minimum size of the fill
+func (f *Filler) Measure(constraints Constraints) Size {
+	return minSize(f.GetConstraints())
+}
+
+// Layout implements the Widget interface for Fill; fills take all the space offered
+func (f *Filler) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	f.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for Fill
+func (f *Filler) Paint(ctx *Context, box *Box) (err error) {
+	brush := f.brush
+	if !brush.IsSet() {
+		brush = render.Solid(f.color.Value(ctx.Clock))
+	}
+	list := ctx.DrawList
+	x, y, w, h := box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height
+	switch {
+	case f.ellipse && f.outline > 0:
+		list.StrokeEllipse(x+w/2, y+h/2, w/2, h/2, f.outline, brush)
+	case f.ellipse:
+		list.FillEllipse(x+w/2, y+h/2, w/2, h/2, brush)
+	case f.outline > 0:
+		list.StrokeRoundRect(x, y, w, h, f.radius, f.outline, brush)
+	default:
+		list.FillRoundRect(x, y, w, h, f.radius, brush)
+	}
+	if f.color.Running() {
+		f.MarkNeedsPaint()
+	}
+	return
+}
+
+// fillRect paints the box with a solid color
+func fillRect(ctx *Context, box *Box, color [4]float32) {
+	ctx.DrawList.Rect(box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height, color)
+}
+
+// fillBordered paints the box with a 1 pixel border around a filled inside,
+// with corners rounded to radius
+func fillBordered(ctx *Context, box *Box, radius float32, border, fill [4]float32) {
+	list := ctx.DrawList
+	x, y, w, h := box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height
+	list.RoundRect(x, y, w, h, radius, border)
+	list.RoundRect(x+1, y+1, w-2, h-2, max(radius-1, 0), fill)
+}
+
+// fillSkinned paints the box with a theme skin when set, otherwise like
+// fillBordered
+func fillSkinned(ctx *Context, box *Box, skin *render.NinePatch, radius float32, border, fill [4]float32) {
+	if skin != nil {
+		ctx.DrawList.NinePatch(skin, box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height, [4]float32{1, 1, 1, 1})
+		return
+	}
+	fillBordered(ctx, box, radius, border, fill)
+}
+
+// HandleEvent implements the Widget interface for Fill; fills ignore input
+func (f *Filler) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	return false
 }
@@ -0,0 +1,98 @@
+package widget
+
+// ticker is implemented by focused widgets whose appearance changes over
+// time, such as a blinking caret. The root calls tick once per frame, and
+// the widget asks the context's clock for a frame when it next changes.
+type ticker interface {
+	tick(ctx *Context)
+}
+
+// SetFocus gives keyboard focus to a widget in the tree, or removes focus
+// when nil. Both the old and new focus owners are repainted. While a modal
+// popup is open only widgets inside it or popups above it can take focus.
+func (r *RootWidget) SetFocus(w Widget) {
+	if w == r.focused {
+		return
+	}
+	if m := r.modal(); m >= 0 && w != nil && !r.aboveModal(w, m) {
+		return
+	}
+	if r.focused != nil {
+		r.focused.MarkNeedsPaint()
+	}
+	r.focused = w
+	if w != nil {
+		w.MarkNeedsPaint()
+	}
+}
+
+// aboveModal reports whether the widget is inside the modal popup at index
+// or a popup shown above it
+func (r *RootWidget) aboveModal(w Widget, index int) bool {
+	for _, p := range r.popups[index:] {
+		if within(w, p.content) {
+			return true
+		}
+	}
+	return false
+}
+
+// Focused returns the widget receiving keyboard input, nil if none
+func (r *RootWidget) Focused() Widget {
+	return r.focused
+}
+
+// focusEvent delivers an event to the focused widget in the box it was last
+// painted in
+func (r *RootWidget) focusEvent(ctx *Context, ev Event) (handled bool) {
+	t, ok := r.focused.(paintTracker)
+	if !ok {
+		return false
+	}
+	box := t.lastPaintBox()
+	return r.focused.HandleEvent(childContext(ctx, box), box, ev)
+}
+
+// rootOf returns the root of the tree containing the widget, nil when the
+// widget is not attached to a root
+func rootOf(w Widget) *RootWidget {
+	for w != nil {
+		if r, ok := w.(*RootWidget); ok {
+			return r
+		}
+		parented, ok := w.(interface{ Parent() Widget })
+		if !ok {
+			return nil
+		}
+		w = parented.Parent()
+	}
+	return nil
+}
+
+// within reports whether the widget is the ancestor or one of its descendants
+func within(w, ancestor Widget) bool {
+	for w != nil {
+		if w == ancestor {
+			return true
+		}
+		parented, ok := w.(interface{ Parent() Widget })
+		if !ok {
+			return false
+		}
+		w = parented.Parent()
+	}
+	return false
+}
+
+// requestFocus gives keyboard focus to the widget
+func requestFocus(w Widget) {
+	if r := rootOf(w); r != nil {
+		r.SetFocus(w)
+	}
+}
+
+// hasFocus reports whether the widget receives keyboard input
+func hasFocus(w Widget) bool {
+	r := rootOf(w)
+	return r != nil && r.focused == w
+}
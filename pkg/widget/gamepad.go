@@ -0,0 +1,260 @@
+package widget
+
+import (
+	"math"
+
+	"github.com/mleku/goo/pkg/interfaces"
+)
+
+// focusRingGap is the space between a widget focused by gamepad navigation
+// and the ring drawn around it
+const focusRingGap = 2
+
+// navigable is implemented by the widgets gamepad navigation moves focus
+// between, those that act when clicked
+type navigable interface {
+	// navigable reports whether the widget can take focus, false while it
+	// is disabled
+	navigable() bool
+}
+
+// arrowTaker is implemented by navigable widgets that use some arrow keys
+// themselves, which the d-pad sends them as keys rather than moving focus
+type arrowTaker interface {
+	takesArrow(key interfaces.Key) bool
+}
+
+// gamepadArrows are the arrow keys of the d-pad's directions
+var gamepadArrows = map[interfaces.GamepadButton]interfaces.Key{
+	interfaces.GamepadDpadUp:    interfaces.KeyUp,
+	interfaces.GamepadDpadRight: interfaces.KeyRight,
+	interfaces.GamepadDpadDown:  interfaces.KeyDown,
+	interfaces.GamepadDpadLeft:  interfaces.KeyLeft,
+}
+
+// OnGamepad sets the callback offered gamepad input before it navigates,
+// which returns whether it took the input, and returns the root for
+// chaining
+func (r *RootWidget) OnGamepad(fn func(e interfaces.GamepadEvent) bool) *RootWidget {
+	r.onGamepad = fn
+	return r
+}
+
+// gamepadEvent drives the interface with a gamepad. The d-pad moves focus
+// to the nearest navigable widget in its direction, drawing a ring around
+// it, A clicks the focused widget and B acts as escape. A widget that uses
+// arrow keys along a direction, such as a slider, is sent them instead.
+// While the top popup has nothing to navigate between, such as a menu or a
+// dropdown's list, the d-pad and A are sent to the focus owner as the arrow
+// and enter keys.
+func (r *RootWidget) gamepadEvent(ctx *Context, box *Box, e interfaces.GamepadEvent) bool {
+	if r.onGamepad != nil && r.onGamepad(e) {
+		return true
+	}
+	if e.Action == interfaces.ActionRelease {
+		return false
+	}
+	candidates := r.navigables()
+	switch e.Button {
+	case interfaces.GamepadA:
+		if len(candidates) == 0 {
+			return r.focusKey(ctx, interfaces.KeyEnter, e.Action)
+		}
+		if e.Action == interfaces.ActionPress {
+			r.activate(ctx, box, candidates)
+		}
+		return true
+	case interfaces.GamepadB:
+		if r.focusKey(ctx, interfaces.KeyEscape, e.Action) {
+			return true
+		}
+		return e.Action == interfaces.ActionPress && r.escapePopup()
+	}
+	key, ok := gamepadArrows[e.Button]
+	if !ok {
+		return false
+	}
+	if t, ok := r.focused.(arrowTaker); (ok && t.takesArrow(key)) || len(candidates) == 0 {
+		return r.focusKey(ctx, key, e.Action)
+	}
+	r.navigate(candidates, key)
+	return true
+}
+
+// focusKey sends a key to the focus owner
+func (r *RootWidget) focusKey(ctx *Context, key interfaces.Key, action interfaces.Action) bool {
+	return r.focusEvent(ctx, interfaces.KeyEvent{Key: key, Action: action})
+}
+
+// navigables returns the regions of the navigable widgets in the top layer
+// that input reaches, the window's content or the popup over it
+func (r *RootWidget) navigables() (candidates []interfaces.HitRegion) {
+	layer := 0
+	for i := len(r.popups) - 1; i >= 0; i-- {
+		if !r.popups[i].passThrough {
+			layer = i + 1
+			break
+		}
+	}
+	for _, region := range r.hits.Regions() {
+		if n, ok := region.Target.(navigable); ok && region.Layer == layer && n.navigable() {
+			candidates = append(candidates, region)
+		}
+	}
+	return
+}
+
+// focusRect returns the visible rect of the focus owner when it is one of
+// the candidates
+func (r *RootWidget) focusRect(candidates []interfaces.HitRegion) (rect Rect, ok bool) {
+	for _, c := range candidates {
+		if c.Target == r.focused && r.focused != nil {
+			return c.Rect, true
+		}
+	}
+	return
+}
+
+// navigate moves focus from the focus owner to the nearest candidate in the
+// direction of an arrow key, or to the first when nothing navigable has
+// focus. With nothing that way, the scroll view around the focus owner
+// scrolls that way to uncover more.
+func (r *RootWidget) navigate(candidates []interfaces.HitRegion, key interfaces.Key) {
+	from, ok := r.focusRect(candidates)
+	if !ok {
+		r.navigateTo(firstNavigable(candidates))
+		return
+	}
+	var best Widget
+	bestScore := float32(math.MaxFloat32)
+	for _, c := range candidates {
+		if c.Target == r.focused {
+			continue
+		}
+		if score, ok := navigationScore(from, c.Rect, key); ok && score < bestScore {
+			best, bestScore = c.Target.(Widget), score
+		}
+	}
+	if best == nil {
+		r.scrollToward(key)
+		return
+	}
+	r.navigateTo(best)
+}
+
+// navigateTo focuses a widget with the focus ring shown, scrolling it into
+// view
+func (r *RootWidget) navigateTo(w Widget) {
+	r.SetFocus(w)
+	if r.focused != w {
+		return
+	}
+	r.focusVisible = true
+	if t, ok := w.(paintTracker); ok {
+		scrollIntoView(w, t.lastPaintBox().Rect())
+	}
+}
+
+// activate clicks the middle of the visible part of the focus owner, or
+// focuses the first candidate when nothing navigable has focus. Widgets
+// taking arrow keys are not clicked, as a click moves a slider's thumb.
+func (r *RootWidget) activate(ctx *Context, box *Box, candidates []interfaces.HitRegion) {
+	rect, ok := r.focusRect(candidates)
+	if !ok {
+		r.navigateTo(firstNavigable(candidates))
+		return
+	}
+	w := r.focused
+	if _, ok := w.(arrowTaker); ok {
+		return
+	}
+	at := Point{X: rect.X + rect.Width/2, Y: rect.Y + rect.Height/2}
+	for _, action := range []interfaces.Action{interfaces.ActionPress, interfaces.ActionRelease} {
+		r.handleEvent(ctx, box, interfaces.MouseButtonEvent{
+			Position: at,
+			Button:   interfaces.MouseButtonLeft,
+			Action:   action,
+		})
+	}
+	// A click on a widget that does not take focus takes it away, so give it
+	// back for navigation to carry on from
+	if r.focused == nil && rootOf(w) == r {
+		r.SetFocus(w)
+	}
+	if r.focused == w {
+		r.focusVisible = true
+	}
+}
+
+// scrollToward scrolls the nearest scroll view around the focus owner a
+// step in the direction of an arrow key
+func (r *RootWidget) scrollToward(key interfaces.Key) {
+	var w Widget = r.focused
+	for w != nil {
+		parented, ok := w.(interface{ Parent() Widget })
+		if !ok {
+			return
+		}
+		w = parented.Parent()
+		s, ok := w.(*ScrollWidget)
+		if !ok {
+			continue
+		}
+		switch key {
+		case interfaces.KeyUp:
+			s.ScrollBy(0, -s.step)
+		case interfaces.KeyDown:
+			s.ScrollBy(0, s.step)
+		case interfaces.KeyLeft:
+			s.ScrollBy(-s.step, 0)
+		case interfaces.KeyRight:
+			s.ScrollBy(s.step, 0)
+		}
+		return
+	}
+}
+
+// firstNavigable returns the candidate nearest the top left, first in
+// reading order
+func firstNavigable(candidates []interfaces.HitRegion) Widget {
+	first := candidates[0]
+	for _, c := range candidates[1:] {
+		if c.Rect.Y < first.Rect.Y || (c.Rect.Y == first.Rect.Y && c.Rect.X < first.Rect.X) {
+			first = c
+		}
+	}
+	return first.Target.(Widget)
+}
+
+// navigationScore returns how far a rect lies from another in the direction
+// of an arrow key, lowest for the one to move to, and whether it lies that
+// way at all. The distance across the direction between the rects counts
+// double, so a widget in line is preferred to a nearer one off to the side.
+func navigationScore(from, to Rect, key interfaces.Key) (score float32, ok bool) {
+	fx, fy := from.X+from.Width/2, from.Y+from.Height/2
+	tx, ty := to.X+to.Width/2, to.Y+to.Height/2
+	var along, across, offset float32
+	switch key {
+	case interfaces.KeyRight, interfaces.KeyLeft:
+		if (key == interfaces.KeyRight && tx <= fx) || (key == interfaces.KeyLeft && tx >= fx) {
+			return
+		}
+		along = max(to.X-(from.X+from.Width), from.X-(to.X+to.Width), 0)
+		across = spanGap(from.Y, from.Y+from.Height, to.Y, to.Y+to.Height)
+		offset = abs32(ty - fy)
+	default:
+		if (key == interfaces.KeyDown && ty <= fy) || (key == interfaces.KeyUp && ty >= fy) {
+			return
+		}
+		along = max(to.Y-(from.Y+from.Height), from.Y-(to.Y+to.Height), 0)
+		across = spanGap(from.X, from.X+from.Width, to.X, to.X+to.Width)
+		offset = abs32(tx - fx)
+	}
+	// The offset of the centres only breaks ties between rects in line
+	return along + 2*across + offset/100, true
+}
+
+// spanGap returns the distance between two spans, zero where they overlap
+func spanGap(lo1, hi1, lo2, hi2 float32) float32 {
+	return max(lo2-hi1, lo1-hi2, 0)
+}
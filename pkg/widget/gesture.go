@@ -0,0 +1,433 @@
+package widget
+
+import (
+	"slices"
+	"time"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"lol.mleku.dev/chk"
+)
+
+const (
+	// touchSlop is how far a pointer moves while held before it is dragging
+	// rather than pressing
+	touchSlop = 8
+	// longPressDelay is how long a pointer is held still to long press
+	longPressDelay = 500 * time.Millisecond
+	// doubleTapDelay is the longest time between the taps of a double tap
+	doubleTapDelay = 300 * time.Millisecond
+	// doubleTapSlop is how far apart the taps of a double tap may land
+	doubleTapSlop = 24
+	// swipeVelocity is the speed in pixels a second a pan ends at to swipe
+	swipeVelocity = 800
+	// mousePointer is the pointer ID the left mouse button is recognised
+	// under, which no touch uses
+	mousePointer = -1
+)
+
+// GesturePhase is the stage of a continuous gesture
+type GesturePhase int
+
+const (
+	GestureStart GesturePhase = iota
+	GestureUpdate
+	GestureEnd
+)
+
+// SwipeDirection is the direction a swipe moved in
+type SwipeDirection int
+
+const (
+	SwipeLeft SwipeDirection = iota
+	SwipeRight
+	SwipeUp
+	SwipeDown
+)
+
+// Pan is a pointer dragged across a gesture detector
+type Pan struct {
+	Phase GesturePhase
+	// Position is where the pointer is, relative to the detector
+	Position Point
+	// Delta is how far the pointer moved since the last update
+	Delta Point
+	// Velocity is how fast the pointer was moving in pixels a second when
+	// the pan ended, zero before then and when it was cancelled
+	Velocity Point
+}
+
+// Pinch is two touches moved apart or together on a gesture detector
+type Pinch struct {
+	Phase GesturePhase
+	// Center is midway between the touches, relative to the detector
+	Center Point
+	// Scale is the distance between the touches over their distance when
+	// the pinch started
+	Scale float32
+}
+
+// gesturePointer is a touch, or the left mouse button, held on a detector
+type gesturePointer struct {
+	id              int
+	start, position Point
+}
+
+// GestureWidget recognises taps, double taps, long presses, pans, swipes
+// and pinches made on its child with touches or the left mouse button, and
+// calls the callbacks set for them. The mouse counts as one touch, so every
+// gesture but the pinch works without a touch screen. A double tap is also
+// reported as two taps, and a pan that ends fast enough as a swipe.
+//
+// The detector takes the presses and touches its child does not, so
+// widgets inside it that only know the mouse are not pressed by touch.
+type GestureWidget struct {
+	Base
+	child       Widget
+	onTap       func(at Point)
+	onDoubleTap func(at Point)
+	onLongPress func(at Point)
+	onPan       func(p Pan)
+	onSwipe     func(direction SwipeDirection, velocity Point)
+	onPinch     func(p Pinch)
+	// pointers are those held on the detector, in the order they landed
+	pointers []gesturePointer
+	tracker  velocityTracker
+	panning  bool
+	pinching bool
+	// spent is set once the held pointer has moved, long pressed or pinched
+	// so lifting it is not a tap
+	spent bool
+	// last is where the pan was last reported
+	last Point
+	// span is the distance between the touches when the pinch started
+	span      float32
+	longPress *timer
+	// lastTap is when and where the last tap that could start a double tap
+	// was made
+	lastTap   time.Time
+	lastTapAt Point
+}
+
+// Gestures creates a new gesture detector around the child
+func Gestures(child Widget) *GestureWidget {
+	g := &GestureWidget{child: child}
+	adopt(g, child)
+	return g
+}
+
+// OnTap sets the callback invoked where a pointer is pressed and lifted
+// without moving, and returns the detector for chaining
+func (g *GestureWidget) OnTap(fn func(at Point)) *GestureWidget {
+	g.onTap = fn
+	return g
+}
+
+// OnDoubleTap sets the callback invoked where a second tap follows a first
+// close by, and returns the detector for chaining
+func (g *GestureWidget) OnDoubleTap(fn func(at Point)) *GestureWidget {
+	g.onDoubleTap = fn
+	return g
+}
+
+// OnLongPress sets the callback invoked where a pointer is held still for
+// half a second, which is then not a tap, and returns the detector for
+// chaining
+func (g *GestureWidget) OnLongPress(fn func(at Point)) *GestureWidget {
+	g.onLongPress = fn
+	return g
+}
+
+// OnPan sets the callback invoked as a pointer is dragged across the
+// detector, and returns the detector for chaining
+func (g *GestureWidget) OnPan(fn func(p Pan)) *GestureWidget {
+	g.onPan = fn
+	return g
+}
+
+// OnSwipe sets the callback invoked when a pan ends moving quickly, with
+// the direction it moved in most, and returns the detector for chaining
+func (g *GestureWidget) OnSwipe(fn func(direction SwipeDirection, velocity Point)) *GestureWidget {
+	g.onSwipe = fn
+	return g
+}
+
+// OnPinch sets the callback invoked as two touches are moved apart or
+// together, and returns the detector for chaining. A pan in progress ends
+// when the second touch lands.
+func (g *GestureWidget) OnPinch(fn func(p Pinch)) *GestureWidget {
+	g.onPinch = fn
+	return g
+}
+
+// GetConstraints returns the child's constraints
+func (g *GestureWidget) GetConstraints() Constraints {
+	if g.child == nil {
+		return NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return g.child.GetConstraints()
+}
+
+// Measure returns the size the child measures
+func (g *GestureWidget) Measure(constraints Constraints) Size {
+	return measure(g.child, constraints)
+}
+
+// Layout implements the Widget interface for GestureWidget; the child is
+// laid out in the widget's box
+func (g *GestureWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !g.NeedsLayout(ctx, constraints) {
+		return g.CachedSize(), nil
+	}
+	if size, err = layoutInset(ctx, g.child, Insets{}, constraints); chk.E(err) {
+		return
+	}
+	g.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for GestureWidget
+func (g *GestureWidget) Paint(ctx *Context, box *Box) (err error) {
+	if g.child == nil {
+		return
+	}
+	return paintChild(ctx, g.child, box)
+}
+
+// HandleEvent implements the Widget interface for GestureWidget
+func (g *GestureWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if g.child != nil {
+		_, targeted := interfaces.Target(ev)
+		if routeEvent(ctx, g.child, box, ev) && targeted {
+			return true
+		}
+	}
+	switch e := ev.(type) {
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft {
+			break
+		}
+		switch e.Action {
+		case interfaces.ActionPress:
+			return g.down(ctx, box, mousePointer, e.Position)
+		case interfaces.ActionRelease:
+			return g.up(ctx, box, mousePointer, e.Position, false)
+		}
+	case interfaces.MouseMoveEvent:
+		return g.move(ctx, box, mousePointer, e.Position)
+	case interfaces.TouchEvent:
+		switch e.Phase {
+		case interfaces.TouchBegin:
+			return g.down(ctx, box, e.ID, e.Position)
+		case interfaces.TouchMove:
+			return g.move(ctx, box, e.ID, e.Position)
+		case interfaces.TouchEnd:
+			return g.up(ctx, box, e.ID, e.Position, false)
+		case interfaces.TouchCancel:
+			return g.up(ctx, box, e.ID, e.Position, true)
+		}
+	}
+	return false
+}
+
+// down starts tracking a pointer landing at a point, as the first of a
+// gesture or the second of a pinch
+func (g *GestureWidget) down(ctx *Context, box *Box, id int, at Point) bool {
+	if g.find(id) >= 0 {
+		return true
+	}
+	switch len(g.pointers) {
+	case 0:
+		if g.onTap == nil && g.onDoubleTap == nil && g.onLongPress == nil &&
+			g.onPan == nil && g.onSwipe == nil && g.onPinch == nil {
+			return false
+		}
+	case 1:
+		if g.onPinch == nil {
+			return false
+		}
+	default:
+		return false
+	}
+	g.pointers = append(g.pointers, gesturePointer{id: id, start: at, position: at})
+	if len(g.pointers) == 2 {
+		g.startPinch(box)
+		return true
+	}
+	now := frameTime(ctx)
+	g.spent = false
+	g.tracker.reset()
+	g.tracker.add(now, at)
+	if r := rootOf(g); r != nil && g.onLongPress != nil {
+		local := g.local(box, at)
+		g.longPress = r.schedule(now.Add(longPressDelay), func() {
+			g.longPress = nil
+			g.spent = true
+			g.onLongPress(local)
+		})
+	}
+	return true
+}
+
+// move follows a held pointer to a point, starting a pan once the first
+// moves far enough
+func (g *GestureWidget) move(ctx *Context, box *Box, id int, at Point) bool {
+	i := g.find(id)
+	if i < 0 {
+		return false
+	}
+	g.pointers[i].position = at
+	if g.pinching {
+		g.pinch(box, GestureUpdate)
+		return true
+	}
+	g.tracker.add(frameTime(ctx), at)
+	if !g.panning {
+		p := g.pointers[i]
+		if g.spent || distance(p.start, at) < touchSlop {
+			return true
+		}
+		g.cancelLongPress()
+		g.spent = true
+		if g.onPan == nil && g.onSwipe == nil {
+			return true
+		}
+		g.panning, g.last = true, p.start
+		g.pan(Pan{Phase: GestureStart, Position: g.local(box, p.start)})
+	}
+	g.pan(Pan{Phase: GestureUpdate, Position: g.local(box, at), Delta: g.delta(at)})
+	return true
+}
+
+// up stops tracking a pointer lifted at a point, or cancelled, ending the
+// gesture it was making
+func (g *GestureWidget) up(ctx *Context, box *Box, id int, at Point, cancelled bool) bool {
+	i := g.find(id)
+	if i < 0 {
+		return false
+	}
+	g.pointers[i].position = at
+	if g.pinching {
+		g.pinch(box, GestureEnd)
+		g.pinching = false
+		g.pointers = slices.Delete(g.pointers, i, i+1)
+		return true
+	}
+	g.pointers = slices.Delete(g.pointers, i, i+1)
+	g.cancelLongPress()
+	now := frameTime(ctx)
+	switch {
+	case g.panning:
+		g.panning = false
+		g.tracker.add(now, at)
+		var velocity Point
+		if !cancelled {
+			velocity = g.tracker.velocity(now)
+		}
+		g.pan(Pan{Phase: GestureEnd, Position: g.local(box, at), Delta: g.delta(at), Velocity: velocity})
+		if direction, ok := swipeDirection(velocity); ok && g.onSwipe != nil {
+			g.onSwipe(direction, velocity)
+		}
+	case !g.spent && !cancelled:
+		g.tap(now, g.local(box, at))
+	}
+	return true
+}
+
+// tap reports a tap at a point, and a double tap when it follows another
+func (g *GestureWidget) tap(now time.Time, at Point) {
+	if g.onTap != nil {
+		g.onTap(at)
+	}
+	if g.onDoubleTap == nil {
+		return
+	}
+	if !g.lastTap.IsZero() && now.Sub(g.lastTap) <= doubleTapDelay && distance(g.lastTapAt, at) <= doubleTapSlop {
+		// A third tap starts a new double tap rather than ending another
+		g.lastTap = time.Time{}
+		g.onDoubleTap(at)
+		return
+	}
+	g.lastTap, g.lastTapAt = now, at
+}
+
+// startPinch ends any pan and starts a pinch between the held touches
+func (g *GestureWidget) startPinch(box *Box) {
+	g.cancelLongPress()
+	if g.panning {
+		g.panning = false
+		at := g.pointers[0].position
+		g.pan(Pan{Phase: GestureEnd, Position: g.local(box, at), Delta: g.delta(at)})
+	}
+	g.pinching, g.spent = true, true
+	g.span = max(distance(g.pointers[0].position, g.pointers[1].position), 1)
+	g.pinch(box, GestureStart)
+}
+
+// pinch reports the pinch between the held touches
+func (g *GestureWidget) pinch(box *Box, phase GesturePhase) {
+	if g.onPinch == nil || len(g.pointers) < 2 {
+		return
+	}
+	a, b := g.pointers[0].position, g.pointers[1].position
+	g.onPinch(Pinch{
+		Phase:  phase,
+		Center: g.local(box, Point{X: (a.X + b.X) / 2, Y: (a.Y + b.Y) / 2}),
+		Scale:  distance(a, b) / g.span,
+	})
+}
+
+// pan reports a pan when it is watched
+func (g *GestureWidget) pan(p Pan) {
+	if g.onPan != nil {
+		g.onPan(p)
+	}
+}
+
+// delta returns how far the pan moved to a point since it was last reported
+func (g *GestureWidget) delta(at Point) (d Point) {
+	d = Point{X: at.X - g.last.X, Y: at.Y - g.last.Y}
+	g.last = at
+	return
+}
+
+// cancelLongPress stops a pending long press from being reported
+func (g *GestureWidget) cancelLongPress() {
+	if g.longPress == nil {
+		return
+	}
+	if r := rootOf(g); r != nil {
+		r.cancel(g.longPress)
+	}
+	g.longPress = nil
+}
+
+// find returns the index of a held pointer, -1 if it is not held
+func (g *GestureWidget) find(id int) int {
+	return slices.IndexFunc(g.pointers, func(p gesturePointer) bool { return p.id == id })
+}
+
+// local returns a point relative to the detector
+func (g *GestureWidget) local(box *Box, at Point) Point {
+	return Point{X: at.X - box.Position.X, Y: at.Y - box.Position.Y}
+}
+
+// swipeDirection returns the direction of a pan ending at a velocity, and
+// whether it was fast enough to swipe
+func swipeDirection(velocity Point) (direction SwipeDirection, ok bool) {
+	if abs32(velocity.X) >= abs32(velocity.Y) {
+		if abs32(velocity.X) < swipeVelocity {
+			return
+		}
+		if velocity.X < 0 {
+			return SwipeLeft, true
+		}
+		return SwipeRight, true
+	}
+	if abs32(velocity.Y) < swipeVelocity {
+		return
+	}
+	if velocity.Y < 0 {
+		return SwipeUp, true
+	}
+	return SwipeDown, true
+}
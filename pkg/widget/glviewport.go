@@ -0,0 +1,111 @@
+package widget
+
+import (
+	"math"
+
+	"github.com/mleku/goo/pkg/render"
+)
+
+// GLViewportWidget shows what user code renders with OpenGL into a
+// framebuffer the size of its box in pixels, with its own depth buffer, for
+// 3D model viewers and games laid out among widgets. The draw function is
+// called as the frame is drawn, with the window's GL context current, the
+// framebuffer bound, the viewport covering it and the buffers cleared; it
+// draws as it would into a window of width by height pixels. It is called
+// again when the box changes size, after Refresh, and on every frame while
+// animated; otherwise the last rendering is shown. Input reaches the
+// viewport through widgets wrapping it, such as Gestures. Without GL, as in
+// headless rendering, nothing is drawn.
+type GLViewportWidget struct {
+	Base
+	texture     *render.Texture
+	draw        func(width, height int)
+	constraints Constraints
+	animate     bool
+	// dirty is set when the texture no longer shows what draw renders
+	dirty bool
+}
+
+// GLViewport creates a new viewport showing what draw renders with GL.
+// If no constraints are provided, uses default flexible constraints (0, 0, 1e9, 1e9).
+func GLViewport(draw func(width, height int), constraints ...Constraints) *GLViewportWidget {
+	c := NewFlexConstraints(0, 0, 1e9, 1e9)
+	if len(constraints) > 0 {
+		c = constraints[0]
+	}
+	return &GLViewportWidget{
+		texture:     render.NewTexture(0, 0, render.FormatRGBA, nil),
+		draw:        draw,
+		constraints: c,
+		dirty:       true,
+	}
+}
+
+// Animate renders the viewport on every frame while set, for scenes that
+// move on their own, and returns the viewport for chaining
+func (v *GLViewportWidget) Animate(animate bool) *GLViewportWidget {
+	v.animate = animate
+	v.Refresh()
+	return v
+}
+
+// Refresh renders the viewport again on the next frame, for scenes that
+// changed. It must be called on the UI goroutine.
+func (v *GLViewportWidget) Refresh() {
+	v.dirty = true
+	v.MarkNeedsPaint()
+}
+
+// Texture returns the texture the viewport renders into
+func (v *GLViewportWidget) Texture() *render.Texture {
+	return v.texture
+}
+
+// GetConstraints returns the viewport's constraints
+func (v *GLViewportWidget) GetConstraints() Constraints {
+	return v.constraints
+}
+
+// Measure returns the minimum size of the viewport
+func (v *GLViewportWidget) Measure(constraints Constraints) Size {
+	return minSize(v.GetConstraints())
+}
+
+// Layout implements the Widget interface for GLViewportWidget; viewports
+// take all the space offered
+func (v *GLViewportWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	v.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for GLViewportWidget
+func (v *GLViewportWidget) Paint(ctx *Context, box *Box) (err error) {
+	if v.draw == nil || box.Size.Width <= 0 || box.Size.Height <= 0 {
+		return
+	}
+	scale := ctx.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+	t := v.texture
+	width := int(math.Ceil(float64(box.Size.Width * scale)))
+	height := int(math.Ceil(float64(box.Size.Height * scale)))
+	if v.dirty || v.animate || width != t.Width || height != t.Height {
+		v.dirty = false
+		t.Width, t.Height = width, height
+		ctx.DrawList.RenderGL(t, v.draw)
+	}
+	// GL's rows count from the bottom
+	ctx.DrawList.Image(t, box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height, 0, 1, 1, 0, [4]float32{1, 1, 1, 1})
+	if v.animate {
+		v.MarkNeedsPaint()
+	}
+	return
+}
+
+// HandleEvent implements the Widget interface for GLViewportWidget;
+// viewports leave input to the widgets wrapping them
+func (v *GLViewportWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	return false
+}
@@ -0,0 +1,148 @@
+package widget_test
+
+import (
+	"image"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mleku/goo/pkg/headless"
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/text"
+	"github.com/mleku/goo/pkg/widget"
+)
+
+// testFont is a TrueType font found on most Linux systems
+const testFont = "/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf"
+
+// loadFont loads the test font, skipping the test when it is missing
+func loadFont(t *testing.T) *text.Font {
+	t.Helper()
+	if _, err := os.Stat(testFont); err != nil {
+		t.Skip("test font not installed:", testFont)
+	}
+	font, err := text.Load(testFont)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return font
+}
+
+// harness drives a widget tree on a headless screen, a frame at a time
+type harness struct {
+	t      *testing.T
+	root   *widget.RootWidget
+	screen *headless.Screen
+	now    time.Time
+	// img is a copy of the last frame
+	img *image.RGBA
+}
+
+// newHarness shows a widget tree on a screen of a size and draws the first
+// frame
+func newHarness(t *testing.T, child widget.Widget, width, height int) *harness {
+	t.Helper()
+	root := widget.Root(child)
+	h := &harness{
+		t:      t,
+		root:   root,
+		screen: headless.New(root, width, height),
+		now:    time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}
+	h.frame()
+	return h
+}
+
+// frame draws the next frame, delivering the queued input, and returns a
+// copy of it
+func (h *harness) frame() *image.RGBA {
+	h.t.Helper()
+	h.now = h.now.Add(time.Second / 60)
+	img, err := h.screen.Frame(h.now)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	h.img = &image.RGBA{Pix: append([]uint8(nil), img.Pix...), Stride: img.Stride, Rect: img.Rect}
+	return h.img
+}
+
+// send delivers events with the next frame and returns it
+func (h *harness) send(events ...interfaces.Event) *image.RGBA {
+	h.t.Helper()
+	h.screen.Send(events...)
+	return h.frame()
+}
+
+// find returns the middle of where a widget was painted in the last frame,
+// failing the test when it cannot be hit
+func (h *harness) find(w widget.Widget) interfaces.Point {
+	h.t.Helper()
+	b := h.img.Bounds()
+	var found bool
+	var lo, hi interfaces.Point
+	for y := b.Min.Y; y < b.Max.Y; y += 2 {
+		for x := b.Min.X; x < b.Max.X; x += 2 {
+			p := interfaces.Point{X: float32(x) + 0.5, Y: float32(y) + 0.5}
+			if h.root.HitTest(p) != w {
+				continue
+			}
+			if !found {
+				lo, hi, found = p, p, true
+			}
+			lo.X, lo.Y = min(lo.X, p.X), min(lo.Y, p.Y)
+			hi.X, hi.Y = max(hi.X, p.X), max(hi.Y, p.Y)
+		}
+	}
+	if !found {
+		h.t.Fatalf("%T not painted", w)
+	}
+	return interfaces.Point{X: (lo.X + hi.X) / 2, Y: (lo.Y + hi.Y) / 2}
+}
+
+// move moves the pointer to a point
+func (h *harness) move(p interfaces.Point) *image.RGBA {
+	h.t.Helper()
+	return h.send(interfaces.MouseMoveEvent{Position: p})
+}
+
+// press presses the left mouse button at a point
+func (h *harness) press(p interfaces.Point) *image.RGBA {
+	h.t.Helper()
+	return h.send(interfaces.MouseMoveEvent{Position: p}, interfaces.MouseButtonEvent{
+		Position: p, Button: interfaces.MouseButtonLeft, Action: interfaces.ActionPress,
+	})
+}
+
+// release releases the left mouse button at a point
+func (h *harness) release(p interfaces.Point) *image.RGBA {
+	h.t.Helper()
+	return h.send(interfaces.MouseMoveEvent{Position: p}, interfaces.MouseButtonEvent{
+		Position: p, Button: interfaces.MouseButtonLeft, Action: interfaces.ActionRelease,
+	})
+}
+
+// click presses and releases the left mouse button at a point
+func (h *harness) click(p interfaces.Point) *image.RGBA {
+	h.t.Helper()
+	h.press(p)
+	return h.release(p)
+}
+
+// key presses and releases a key with modifiers
+func (h *harness) key(key interfaces.Key, mods interfaces.Modifier) *image.RGBA {
+	h.t.Helper()
+	return h.send(
+		interfaces.KeyEvent{Key: key, Action: interfaces.ActionPress, Mods: mods},
+		interfaces.KeyEvent{Key: key, Action: interfaces.ActionRelease, Mods: mods},
+	)
+}
+
+// typeText types the runes of a string
+func (h *harness) typeText(s string) *image.RGBA {
+	h.t.Helper()
+	var events []interfaces.Event
+	for _, r := range s {
+		events = append(events, interfaces.CharEvent{Char: r})
+	}
+	return h.send(events...)
+}
@@ -0,0 +1,177 @@
+package widget
+
+import (
+	"math"
+
+	"github.com/mleku/goo/pkg/icons"
+	"github.com/mleku/goo/pkg/render"
+	"github.com/mleku/goo/pkg/svg"
+	"lol.mleku.dev/chk"
+)
+
+// IconWidget draws a vector image at a size, rasterized for the display's
+// pixel density so it stays crisp. Shapes painted with currentColor take
+// the theme's text color; setting a color paints the whole icon in it. Icons
+// of the built-in set are drawn from a shared atlas in one color.
+type IconWidget struct {
+	Base
+	name  string
+	image *svg.Image
+	size  float32
+	color colorOverride
+	// texture holds the image rasterized at the pixel size and colors it
+	// was last drawn with
+	texture        *render.Texture
+	pixelW, pixelH int
+	drawnColor     [4]float32
+	drawnMono      bool
+}
+
+// Icon creates a new icon showing a vector image whose larger side is size
+// units long
+func Icon(img *svg.Image, size float32) *IconWidget {
+	return &IconWidget{image: img, size: size}
+}
+
+// IconNamed creates a new icon showing an icon of the built-in set, such as
+// "close" or "search", size units square in the theme's text color. See
+// icons.Names for the names.
+func IconNamed(name string, size float32) *IconWidget {
+	return &IconWidget{name: name, size: size}
+}
+
+// IconFile creates a new icon showing an SVG file. Files are parsed once and
+// shared by every icon showing them.
+func IconFile(path string, size float32) (i *IconWidget, err error) {
+	var img *svg.Image
+	if img, err = svg.Load(path); chk.E(err) {
+		return
+	}
+	return Icon(img, size), nil
+}
+
+// Size sets the length of the icon's larger side and returns the icon for chaining
+func (i *IconWidget) Size(size float32) *IconWidget {
+	i.size = size
+	i.MarkNeedsLayout()
+	return i
+}
+
+// Color paints the whole icon in a color, replacing its own colors and the
+// theme's, and returns the icon for chaining
+func (i *IconWidget) Color(red, green, blue, alpha float32) *IconWidget {
+	i.color = override([4]float32{red, green, blue, alpha})
+	i.MarkNeedsPaint()
+	return i
+}
+
+// SetImage replaces the displayed image
+func (i *IconWidget) SetImage(img *svg.Image) {
+	if img == i.image {
+		return
+	}
+	i.name, i.image = "", img
+	i.pixelW, i.pixelH = 0, 0
+	i.MarkNeedsLayout()
+}
+
+// SetName replaces the displayed image with an icon of the built-in set
+func (i *IconWidget) SetName(name string) {
+	if name == i.name {
+		return
+	}
+	i.name, i.image = name, nil
+	if i.texture != nil {
+		i.texture.Dispose()
+		i.texture = nil
+	}
+	i.MarkNeedsLayout()
+}
+
+// Name returns the name of the built-in icon shown, empty when showing an
+// image
+func (i *IconWidget) Name() string {
+	return i.name
+}
+
+// iconSize returns the width and height of the icon, keeping the image's
+// aspect ratio
+func (i *IconWidget) iconSize() (width, height float32) {
+	if i.image == nil || i.image.Width <= 0 || i.image.Height <= 0 {
+		return i.size, i.size
+	}
+	if i.image.Width >= i.image.Height {
+		return i.size, i.size * i.image.Height / i.image.Width
+	}
+	return i.size * i.image.Width / i.image.Height, i.size
+}
+
+// GetConstraints returns a minimum size that fits the icon
+func (i *IconWidget) GetConstraints() Constraints {
+	w, h := i.iconSize()
+	return NewFlexConstraints(w, h, 1e9, 1e9)
+}
+
+// Measure returns the minimum size of the icon
+func (i *IconWidget) Measure(constraints Constraints) Size {
+	return minSize(i.GetConstraints())
+}
+
+// Layout implements the Widget interface for IconWidget; icons take all the
+// space offered and are drawn centered within it
+func (i *IconWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	i.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for IconWidget
+func (i *IconWidget) Paint(ctx *Context, box *Box) (err error) {
+	if box.Size.Width <= 0 || box.Size.Height <= 0 {
+		return
+	}
+	w, h := i.iconSize()
+	// Shrink to fit boxes smaller than the icon
+	fit := min(1, box.Size.Width/w, box.Size.Height/h)
+	w, h = w*fit, h*fit
+	scale := ctx.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+	if i.name != "" {
+		x, y := box.Position.X+(box.Size.Width-w)/2, box.Position.Y+(box.Size.Height-h)/2
+		icons.Draw(ctx.DrawList, i.name, x, y, w, scale, i.color.or(themeOf(ctx).Text))
+		return
+	}
+	if i.image == nil {
+		return
+	}
+	pw := int(math.Round(float64(w * scale)))
+	ph := int(math.Round(float64(h * scale)))
+	if pw <= 0 || ph <= 0 {
+		return
+	}
+	mono := i.color.set
+	color := i.color.or(themeOf(ctx).Text)
+	if i.texture == nil || pw != i.pixelW || ph != i.pixelH || color != i.drawnColor || mono != i.drawnMono {
+		if i.texture != nil {
+			i.texture.Dispose()
+		}
+		if mono {
+			i.texture = render.TextureFromImage(i.image.RasterizeMono(pw, ph, color))
+		} else {
+			i.texture = render.TextureFromImage(i.image.Rasterize(pw, ph, color))
+		}
+		i.pixelW, i.pixelH, i.drawnColor, i.drawnMono = pw, ph, color, mono
+	}
+	// Snap to whole pixels so the rasterized image is not resampled
+	x := float32(math.Round(float64((box.Position.X+(box.Size.Width-w)/2)*scale))) / scale
+	y := float32(math.Round(float64((box.Position.Y+(box.Size.Height-h)/2)*scale))) / scale
+	ctx.DrawList.Image(i.texture, x, y, float32(pw)/scale, float32(ph)/scale, 0, 0, 1, 1, [4]float32{1, 1, 1, 1})
+	return
+}
+
+// HandleEvent implements the Widget interface for IconWidget; icons ignore input
+func (i *IconWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	return false
+}
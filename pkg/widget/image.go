@@ -0,0 +1,205 @@
+package widget
+
+import (
+	"image"
+	"math"
+
+	"github.com/mleku/goo/pkg/render"
+	"lol.mleku.dev/chk"
+)
+
+// ScaleMode specifies how an image is sized to its box
+type ScaleMode int
+
+const (
+	// ScaleFit shows the whole image as large as fits, keeping its aspect ratio
+	ScaleFit ScaleMode = iota
+	// ScaleFill covers the whole box keeping the aspect ratio, cropping the overflow
+	ScaleFill
+	// ScaleStretch covers the box exactly, distorting the aspect ratio
+	ScaleStretch
+	// ScaleCenter shows the image at its natural size centered in the box
+	ScaleCenter
+)
+
+// ImageWidget draws a bitmap. The pixels are uploaded to the GPU once when
+// first drawn and reused on later frames. Images of a texture source show
+// its latest frame, uploaded again whenever another is pushed.
+type ImageWidget struct {
+	Base
+	texture     *render.Texture
+	source      *render.TextureSource
+	constraints Constraints
+	mode        ScaleMode
+	tint        [4]float32
+}
+
+// Image creates a new image widget showing the image, scaled to fit its box.
+// If no constraints are provided, uses default flexible constraints (0, 0, 1e9, 1e9).
+func Image(img image.Image, constraints ...Constraints) *ImageWidget {
+	return ImageTexture(render.TextureFromImage(img), constraints...)
+}
+
+// ImageFile creates a new image widget showing a PNG or JPEG file. Files are
+// decoded once and their textures shared by every widget showing them.
+func ImageFile(path string, constraints ...Constraints) (i *ImageWidget, err error) {
+	var texture *render.Texture
+	if texture, err = render.LoadTexture(path); chk.E(err) {
+		return
+	}
+	return ImageTexture(texture, constraints...), nil
+}
+
+// ImageTexture creates a new image widget showing an existing texture.
+// If no constraints are provided, uses default flexible constraints (0, 0, 1e9, 1e9).
+func ImageTexture(texture *render.Texture, constraints ...Constraints) *ImageWidget {
+	var c Constraints
+	if len(constraints) > 0 {
+		c = constraints[0]
+	} else {
+		c = NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return &ImageWidget{
+		texture:     texture,
+		constraints: c,
+		mode:        ScaleFit,
+		tint:        [4]float32{1.0, 1.0, 1.0, 1.0},
+	}
+}
+
+// ImageSource creates a new image widget showing the frames pushed into a
+// texture source, for camera previews and streamed video. The root swaps in
+// the latest frame at the start of each frame it renders, so the source
+// should wake the window as frames arrive.
+// If no constraints are provided, uses default flexible constraints (0, 0, 1e9, 1e9).
+func ImageSource(source *render.TextureSource, constraints ...Constraints) *ImageWidget {
+	i := ImageTexture(source.Texture(), constraints...)
+	i.source = source
+	return i
+}
+
+// Scale sets how the image is sized to its box and returns the image for chaining
+func (i *ImageWidget) Scale(mode ScaleMode) *ImageWidget {
+	i.mode = mode
+	i.MarkNeedsPaint()
+	return i
+}
+
+// Filter sets how the image is sampled when scaled and returns the image for
+// chaining. The filter belongs to the texture, so it also applies to other
+// widgets showing the same file.
+func (i *ImageWidget) Filter(filter render.Filter) *ImageWidget {
+	i.texture.Filter = filter
+	i.MarkNeedsPaint()
+	return i
+}
+
+// Tint sets a color the image pixels are multiplied by and returns the image for chaining
+func (i *ImageWidget) Tint(red, green, blue, alpha float32) *ImageWidget {
+	i.tint = [4]float32{red, green, blue, alpha}
+	i.MarkNeedsPaint()
+	return i
+}
+
+// SetImage replaces the displayed image, and the source it showed
+func (i *ImageWidget) SetImage(img image.Image) {
+	i.texture.Dispose()
+	i.texture = render.TextureFromImage(img)
+	i.source = nil
+	i.MarkNeedsPaint()
+}
+
+// Texture returns the texture holding the image
+func (i *ImageWidget) Texture() *render.Texture {
+	return i.texture
+}
+
+// Source returns the texture source the image shows, nil if none
+func (i *ImageWidget) Source() *render.TextureSource {
+	return i.source
+}
+
+// refreshSource swaps in the latest frame pushed to the image's source,
+// repainting the image when there was one
+func (i *ImageWidget) refreshSource() {
+	if i.source != nil && i.source.Update() {
+		i.MarkNeedsPaint()
+	}
+}
+
+// sourced is implemented by widgets showing frames pushed into a texture
+// source from other goroutines
+type sourced interface {
+	refreshSource()
+}
+
+// refreshSources swaps in the frames pushed to the sources of the widgets
+// painted, on the UI goroutine before the frame is painted
+func (r *RootWidget) refreshSources() {
+	for _, region := range r.hits.Regions() {
+		if s, ok := region.Target.(sourced); ok {
+			s.refreshSource()
+		}
+	}
+}
+
+// GetConstraints returns the image's constraints
+func (i *ImageWidget) GetConstraints() Constraints {
+	return i.constraints
+}
+
+// Measure returns the minimum size of the image
+func (i *ImageWidget) Measure(constraints Constraints) Size {
+	return minSize(i.GetConstraints())
+}
+
+// Layout implements the Widget interface for ImageWidget; images take all the
+// space offered and scale within it when painted
+func (i *ImageWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	i.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for ImageWidget
+func (i *ImageWidget) Paint(ctx *Context, box *Box) (err error) {
+	if i.source != nil {
+		// Images painted for the first time have not been refreshed
+		i.source.Update()
+	}
+	t := i.texture
+	if t.Width == 0 || t.Height == 0 || box.Size.Width <= 0 || box.Size.Height <= 0 {
+		return
+	}
+	iw, ih := float32(t.Width), float32(t.Height)
+	bx, by, bw, bh := box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height
+	list := ctx.DrawList
+
+	switch i.mode {
+	case ScaleStretch:
+		list.Image(t, bx, by, bw, bh, 0, 0, 1, 1, i.tint)
+	case ScaleFit:
+		scale := min(bw/iw, bh/ih)
+		w, h := iw*scale, ih*scale
+		list.Image(t, bx+(bw-w)/2, by+(bh-h)/2, w, h, 0, 0, 1, 1, i.tint)
+	case ScaleFill:
+		// Crop the texture coordinates to the part of the image that covers the box
+		scale := max(bw/iw, bh/ih)
+		u := (1 - bw/scale/iw) / 2
+		v := (1 - bh/scale/ih) / 2
+		list.Image(t, bx, by, bw, bh, u, v, 1-u, 1-v, i.tint)
+	case ScaleCenter:
+		// Snap to whole pixels so the image is not resampled
+		x := float32(math.Round(float64(bx + (bw-iw)/2)))
+		y := float32(math.Round(float64(by + (bh-ih)/2)))
+		list.PushClip(bx, by, bw, bh)
+		list.Image(t, x, y, iw, ih, 0, 0, 1, 1, i.tint)
+		list.PopClip()
+	}
+	return
+}
+
+// HandleEvent implements the Widget interface for ImageWidget; images ignore input
+func (i *ImageWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	return false
+}
@@ -0,0 +1,415 @@
+package widget
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mleku/goo/pkg/text"
+	"lol.mleku.dev/chk"
+)
+
+const (
+	// inspectorPoll is how often the inspector looks for changes to the
+	// tree it inspects
+	inspectorPoll = 250 * time.Millisecond
+	// inspectorValueLength is the number of characters of a field's value shown
+	inspectorValueLength = 60
+	// inspectorTextSize is the size of the inspector's text
+	inspectorTextSize = 13
+)
+
+// inspectorLink is a widget of an inspected tree and its parent
+type inspectorLink struct {
+	widget, parent Widget
+}
+
+// InspectorWidget shows the hierarchy of the widgets painted by another
+// root as a tree, live, with the constraints, box and fields of the selected
+// widget below it. The widget under the cursor in the tree, or else the
+// selected one, is outlined in the inspected window. The inspector can sit
+// beside the inspected tree or in a window of its own.
+type InspectorWidget struct {
+	Base
+	font    *text.Font
+	target  *RootWidget
+	tree    *TreeWidget
+	details *inspectorDetails
+	child   Widget
+	// nodes maps the widgets of the inspected tree to their tree nodes
+	nodes map[Widget]*TreeNode
+	// links is the inspected hierarchy from the last refresh, in paint order
+	links []inspectorLink
+	// follow selects the widget under the cursor in the inspected window
+	follow bool
+	wake   func()
+	// poll repaints the inspector to read the inspected tree again
+	poll *timer
+}
+
+// Inspector creates a new inspector of the tree under a root
+func Inspector(font *text.Font, target *RootWidget) *InspectorWidget {
+	i := &InspectorWidget{
+		font:    font,
+		target:  target,
+		details: &inspectorDetails{font: font},
+		nodes:   make(map[Widget]*TreeNode),
+	}
+	i.tree = Tree(font).Size(inspectorTextSize).OnSelect(func(*TreeNode) { i.update() })
+	i.child = Split(Scroll(i.tree), Scroll(i.details)).Vertical().Ratio(0.6)
+	adopt(i, i.child)
+	return i
+}
+
+// Follow sets whether the inspector selects the widget under the cursor in
+// the inspected window as it moves, and returns the inspector for chaining
+func (i *InspectorWidget) Follow(follow bool) *InspectorWidget {
+	i.follow = follow
+	return i
+}
+
+// Wake sets the function that wakes the inspected window to draw the
+// outline of the widget pointed out, such as its Window.Wake when the
+// inspector is shown in another window, and returns the inspector for chaining
+func (i *InspectorWidget) Wake(fn func()) *InspectorWidget {
+	i.wake = fn
+	return i
+}
+
+// Selected returns the selected widget, nil if none
+func (i *InspectorWidget) Selected() Widget {
+	if n := i.tree.Selected(); n != nil {
+		w, _ := n.Data.(Widget)
+		return w
+	}
+	return nil
+}
+
+// Select selects a widget of the inspected tree, expanding the tree to show it
+func (i *InspectorWidget) Select(w Widget) {
+	n := i.nodes[w]
+	if n == i.tree.Selected() {
+		return
+	}
+	i.tree.Select(n)
+	i.update()
+}
+
+// Refresh reads the inspected hierarchy again, keeping the nodes that were
+// expanded and the selection
+func (i *InspectorWidget) Refresh() {
+	i.refresh()
+}
+
+// refresh reads the inspected hierarchy again, reporting whether it changed
+func (i *InspectorWidget) refresh() (changed bool) {
+	links := i.hierarchy()
+	if slicesEqual(links, i.links) {
+		i.update()
+		return
+	}
+	i.links = links
+	expanded := make(map[Widget]bool)
+	for w, n := range i.nodes {
+		expanded[w] = n.expanded
+	}
+	selected := i.Selected()
+	i.nodes = make(map[Widget]*TreeNode, len(links))
+	var roots []*TreeNode
+	for _, l := range links {
+		n := &TreeNode{Label: inspectorLabel(l.widget), Data: l.widget, expanded: expanded[l.widget]}
+		i.nodes[l.widget] = n
+		if p := i.nodes[l.parent]; p != nil {
+			p.Add(n)
+		} else {
+			roots = append(roots, n)
+		}
+	}
+	if len(expanded) == 0 && len(roots) > 0 {
+		// Open the top of the tree the first time
+		roots[0].expanded = true
+	}
+	i.tree.SetRoots(roots...)
+	if n := i.nodes[selected]; n != nil {
+		i.tree.Select(n)
+	}
+	i.update()
+	return true
+}
+
+// hierarchy returns the widgets painted by the inspected root and their
+// ancestors, each after its parent, leaving out the inspector itself
+func (i *InspectorWidget) hierarchy() (links []inspectorLink) {
+	seen := map[Widget]bool{}
+	var visit func(w Widget)
+	visit = func(w Widget) {
+		if w == nil || seen[w] {
+			return
+		}
+		seen[w] = true
+		var parent Widget
+		if p, ok := w.(interface{ Parent() Widget }); ok {
+			parent = p.Parent()
+		}
+		visit(parent)
+		links = append(links, inspectorLink{widget: w, parent: parent})
+	}
+	for _, region := range i.target.hits.Regions() {
+		w, ok := region.Target.(Widget)
+		if !ok || w == Widget(i) || i.contains(w) {
+			continue
+		}
+		visit(w)
+	}
+	return
+}
+
+// contains reports whether a widget is part of the inspector
+func (i *InspectorWidget) contains(w Widget) bool {
+	for w != nil {
+		if w == Widget(i) {
+			return true
+		}
+		p, ok := w.(interface{ Parent() Widget })
+		if !ok {
+			return false
+		}
+		w = p.Parent()
+	}
+	return false
+}
+
+// update shows the selected widget's details and outlines the widget
+// pointed out in the inspected window
+func (i *InspectorWidget) update() {
+	i.details.show(i.Selected())
+	var pointed Widget
+	if n := i.tree.hot; n != nil {
+		pointed, _ = n.Data.(Widget)
+	} else {
+		pointed = i.Selected()
+	}
+	if pointed != i.target.Highlighted() {
+		i.target.Highlight(pointed)
+		if i.wake != nil {
+			i.wake()
+		}
+	}
+}
+
+// GetConstraints returns the constraints of the tree and details
+func (i *InspectorWidget) GetConstraints() Constraints {
+	return i.child.GetConstraints()
+}
+
+// Measure returns the size the inspector's panes measure
+func (i *InspectorWidget) Measure(constraints Constraints) Size {
+	return i.child.Measure(constraints)
+}
+
+// Layout implements the Widget interface for InspectorWidget
+func (i *InspectorWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !i.NeedsLayout(ctx, constraints) {
+		return i.CachedSize(), nil
+	}
+	if size, err = layoutInset(ctx, i.child, Insets{}, constraints); chk.E(err) {
+		return
+	}
+	i.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for InspectorWidget. The inspected
+// tree is read again every time the inspector is painted, which it is at
+// intervals while it is on screen.
+func (i *InspectorWidget) Paint(ctx *Context, box *Box) (err error) {
+	defer func() {
+		// Changes found while painting are shown on the next frame
+		selected := i.Selected()
+		changed := i.refresh()
+		if i.follow && i.target.pointerIn {
+			if w := i.target.HitTest(i.target.pointer); w != nil && !i.contains(w) {
+				i.Select(w)
+			}
+		}
+		if changed || i.Selected() != selected {
+			ctx.Clock.Request()
+		}
+	}()
+	if r := rootOf(i); r != nil && i.poll == nil {
+		i.poll = r.schedule(frameTime(ctx).Add(inspectorPoll), func() {
+			i.poll = nil
+			i.MarkNeedsPaint()
+		})
+	}
+	return paintChild(ctx, i.child, box)
+}
+
+// HandleEvent implements the Widget interface for InspectorWidget
+func (i *InspectorWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	hot := i.tree.hot
+	handled = routeEvent(ctx, i.child, box, ev)
+	if i.tree.hot != hot {
+		i.update()
+	}
+	return
+}
+
+// inspectorLabel returns the label of a widget's node, its type and any text it shows
+func inspectorLabel(w Widget) string {
+	label := typeName(w)
+	if t, ok := w.(interface{ Text() string }); ok && t.Text() != "" {
+		label += " " + strconv.Quote(truncate(t.Text(), inspectorValueLength/2))
+	}
+	return label
+}
+
+// inspectorDetails lists the constraints, box and fields of a widget
+type inspectorDetails struct {
+	Base
+	font  *text.Font
+	lines []string
+}
+
+// show lists the details of a widget, nothing for nil
+func (d *inspectorDetails) show(w Widget) {
+	var lines []string
+	if w != nil {
+		c := w.GetConstraints()
+		lines = append(lines, typeName(w),
+			fmt.Sprintf("min %s  max %s", debugSize(c.MinWidth, c.MinHeight), debugSize(c.MaxWidth, c.MaxHeight)))
+		if t, ok := w.(paintTracker); ok {
+			b := t.lastPaintBox()
+			lines = append(lines, fmt.Sprintf("box %.0f,%.0f  %s", b.Position.X, b.Position.Y, debugSize(b.Size.Width, b.Size.Height)))
+		}
+		lines = append(lines, inspectFields(w)...)
+	}
+	if slicesEqual(lines, d.lines) {
+		return
+	}
+	d.lines = lines
+	d.MarkNeedsLayout()
+	d.MarkNeedsPaint()
+}
+
+// GetConstraints returns a minimum size that fits every line
+func (d *inspectorDetails) GetConstraints() Constraints {
+	face := d.font.Face(inspectorTextSize)
+	var width float32
+	for _, s := range d.lines {
+		width = max(width, face.Measure(s))
+	}
+	return NewFlexConstraints(width+2*treePadding, face.LineHeight()*float32(len(d.lines))+2*treePadding, 1e9, 1e9)
+}
+
+// Measure returns the minimum size of the details
+func (d *inspectorDetails) Measure(constraints Constraints) Size {
+	return minSize(d.GetConstraints())
+}
+
+// Layout implements the Widget interface for inspectorDetails
+func (d *inspectorDetails) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	d.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for inspectorDetails
+func (d *inspectorDetails) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	face := d.font.Face(inspectorTextSize)
+	y := box.Position.Y + treePadding
+	for n, s := range d.lines {
+		color := th.Text
+		if n > 0 {
+			color = th.TextMuted
+		}
+		face.Draw(ctx.DrawList, box.Position.X+treePadding, y+face.Ascent(), s, color)
+		y += face.LineHeight()
+	}
+	return
+}
+
+// HandleEvent implements the Widget interface for inspectorDetails; details ignore input
+func (d *inspectorDetails) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	return false
+}
+
+// inspectFields lists the fields of the struct a widget points to with their
+// values, leaving out the embedded Base
+func inspectFields(w Widget) (lines []string) {
+	v := reflect.ValueOf(w)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	t := v.Type()
+	for n := range t.NumField() {
+		f := t.Field(n)
+		if f.Anonymous && f.Type == reflect.TypeFor[Base]() {
+			continue
+		}
+		lines = append(lines, f.Name+": "+inspectValue(v.Field(n)))
+	}
+	return
+}
+
+// inspectValue formats the value of a field briefly
+func inspectValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return "nil"
+		}
+		if e := v.Elem(); e.Kind() == reflect.Struct || e.Kind() == reflect.Pointer {
+			// Name what is pointed to rather than following it
+			name := e.Type().String()
+			return name[strings.LastIndex(name, ".")+1:]
+		}
+		return inspectValue(v.Elem())
+	case reflect.Func:
+		if v.IsNil() {
+			return "nil"
+		}
+		return "func"
+	case reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			return "nil"
+		}
+		if v.Len() > 8 {
+			return fmt.Sprintf("%s len %d", v.Type(), v.Len())
+		}
+	case reflect.Chan:
+		return v.Type().String()
+	}
+	return truncate(fmt.Sprint(v), inspectorValueLength)
+}
+
+// truncate shortens a string to a number of characters, marking where it was cut
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
+
+// slicesEqual reports whether two slices hold the same elements
+func slicesEqual[T comparable](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for n := range a {
+		if a[n] != b[n] {
+			return false
+		}
+	}
+	return true
+}
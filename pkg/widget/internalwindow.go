@@ -0,0 +1,688 @@
+package widget
+
+import (
+	"math"
+	"time"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/text"
+	"lol.mleku.dev/chk"
+)
+
+const (
+	// internalWindowPadding is the sum of the space above and below the
+	// title, and the space before it
+	internalWindowPadding = 10
+	// internalWindowGrip is how far inside its edges a window is dragged to
+	// resize it, and internalWindowCorner how far from a corner both of its
+	// edges are dragged
+	internalWindowGrip   = 5
+	internalWindowCorner = 12
+	// internalWindowMinWidth and internalWindowMinHeight are the smallest a
+	// window is resized to
+	internalWindowMinWidth  = 120
+	internalWindowMinHeight = 80
+	// internalWindowMinimized is the width of minimized windows, lined up
+	// along the bottom of the canvas internalWindowGap apart
+	internalWindowMinimized = 160
+	internalWindowGap       = 4
+	// internalWindowElevation is how far windows are raised above the tree
+	internalWindowElevation = 6
+)
+
+// internalWindowButton is a button of the title bar of an internal window
+type internalWindowButton int
+
+const (
+	internalWindowNoButton internalWindowButton = iota
+	internalWindowMinimize
+	internalWindowMaximize
+	internalWindowRestore
+	internalWindowClose
+)
+
+// InternalWindowWidget is a window drawn inside the root's canvas, above the
+// tree, for tool palettes and documents without the platform's windows. It
+// has a title bar dragged to move it, with buttons that minimize, maximize
+// and close it, and edges dragged to resize it. Clicking a window raises it
+// over the others and double clicking its title bar maximizes or restores
+// it. Minimized windows shrink to their title bars, lined up along the
+// bottom of the canvas, and maximized windows fill the canvas. A window
+// opens in the middle of the canvas unless placed with At, and is kept
+// within the canvas.
+type InternalWindowWidget struct {
+	Base
+	font    *text.Font
+	size    float32
+	title   string
+	content Widget
+	// rect is the window's place and size when neither minimized nor
+	// maximized, placed once it has been given a place
+	rect   Rect
+	placed bool
+
+	closable, resizable, minimizable, maximizable bool
+	minimized, maximized                          bool
+	// slot is the place of a minimized window along the bottom of the canvas
+	slot  int
+	popup *Popup
+	// hot is the button under the cursor and pressed the one held down
+	hot, pressed internalWindowButton
+	// moving is set while the title bar is dragged and edges while the
+	// frame is, from grabRect when the cursor was pressed at grab
+	moving   bool
+	edges    dockEdges
+	grab     Point
+	grabRect Rect
+	// titlePressed is set while the title bar of a minimized window is held
+	// and lastClick is when the title bar was last clicked
+	titlePressed bool
+	lastClick    time.Time
+	onClose      func() bool
+}
+
+// InternalWindow creates a new internal window showing the content below a
+// title drawn in the given font at 14 pixels. The window is 320 by 240
+// pixels, can be moved, resized, minimized, maximized and closed, and is
+// not shown until Show.
+func InternalWindow(font *text.Font, title string, content Widget) *InternalWindowWidget {
+	w := &InternalWindowWidget{
+		font:        font,
+		size:        14,
+		title:       title,
+		content:     content,
+		rect:        Rect{Width: 320, Height: 240},
+		closable:    true,
+		resizable:   true,
+		minimizable: true,
+		maximizable: true,
+	}
+	adopt(w, content)
+	return w
+}
+
+// Size sets the size of the window with its title bar and returns the
+// window for chaining
+func (w *InternalWindowWidget) Size(width, height float32) *InternalWindowWidget {
+	w.rect.Width, w.rect.Height = max(width, internalWindowMinWidth), max(height, internalWindowMinHeight)
+	w.MarkNeedsLayout()
+	return w
+}
+
+// At places the window's top left corner at a point of the canvas and
+// returns the window for chaining
+func (w *InternalWindowWidget) At(x, y float32) *InternalWindowWidget {
+	w.rect.X, w.rect.Y, w.placed = x, y, true
+	w.MarkNeedsLayout()
+	return w
+}
+
+// TitleSize sets the pixel size of the title and returns the window for chaining
+func (w *InternalWindowWidget) TitleSize(size float32) *InternalWindowWidget {
+	w.size = size
+	w.MarkNeedsLayout()
+	return w
+}
+
+// Closable sets whether the window has a close button and returns the
+// window for chaining
+func (w *InternalWindowWidget) Closable(closable bool) *InternalWindowWidget {
+	w.closable = closable
+	w.MarkNeedsPaint()
+	return w
+}
+
+// Resizable sets whether the window's edges can be dragged to resize it and
+// returns the window for chaining
+func (w *InternalWindowWidget) Resizable(resizable bool) *InternalWindowWidget {
+	w.resizable = resizable
+	return w
+}
+
+// Minimizable sets whether the window has a minimize button and returns the
+// window for chaining
+func (w *InternalWindowWidget) Minimizable(minimizable bool) *InternalWindowWidget {
+	w.minimizable = minimizable
+	w.MarkNeedsPaint()
+	return w
+}
+
+// Maximizable sets whether the window has a maximize button and can be
+// maximized by double clicking its title bar, and returns the window for
+// chaining
+func (w *InternalWindowWidget) Maximizable(maximizable bool) *InternalWindowWidget {
+	w.maximizable = maximizable
+	w.MarkNeedsPaint()
+	return w
+}
+
+// OnClose sets the callback invoked when the close button is clicked,
+// before the window closes, and returns the window for chaining. Returning
+// false keeps the window open.
+func (w *InternalWindowWidget) OnClose(fn func() bool) *InternalWindowWidget {
+	w.onClose = fn
+	return w
+}
+
+// Title returns the title of the window
+func (w *InternalWindowWidget) Title() string {
+	return w.title
+}
+
+// SetTitle changes the title of the window
+func (w *InternalWindowWidget) SetTitle(title string) {
+	w.title = title
+	w.MarkNeedsPaint()
+}
+
+// Content returns the widget the window shows
+func (w *InternalWindowWidget) Content() Widget {
+	return w.content
+}
+
+// Show shows the window in the root's canvas over the other windows, or
+// raises it when already shown
+func (w *InternalWindowWidget) Show(r *RootWidget) {
+	if w.popup == nil {
+		w.popup = NewPopup(w).Persistent(true)
+		w.popup.placer = w.place
+	}
+	r.ShowPopup(w.popup)
+	w.repaintWindows()
+}
+
+// Raise moves a shown window over the other windows
+func (w *InternalWindowWidget) Raise() {
+	if w.popup != nil && w.popup.root != nil {
+		w.Show(w.popup.root)
+	}
+}
+
+// Close hides the window without invoking the close callback. It keeps its
+// place and can be shown again.
+func (w *InternalWindowWidget) Close() {
+	if w.popup == nil || w.popup.root == nil {
+		return
+	}
+	r := w.popup.root
+	w.popup.Close()
+	w.moving, w.edges, w.pressed, w.hot = false, dockEdges{}, internalWindowNoButton, internalWindowNoButton
+	for _, o := range internalWindows(r) {
+		o.MarkNeedsPaint()
+	}
+}
+
+// IsOpen reports whether the window is shown
+func (w *InternalWindowWidget) IsOpen() bool {
+	return w.popup != nil && w.popup.IsOpen()
+}
+
+// Minimize shrinks the window to its title bar along the bottom of the canvas
+func (w *InternalWindowWidget) Minimize() {
+	if w.minimized {
+		return
+	}
+	w.minimized, w.maximized = true, false
+	w.slot = 0
+	if w.popup != nil && w.popup.root != nil {
+		taken := make(map[int]bool)
+		for _, o := range internalWindows(w.popup.root) {
+			if o != w && o.minimized {
+				taken[o.slot] = true
+			}
+		}
+		for taken[w.slot] {
+			w.slot++
+		}
+	}
+	w.MarkNeedsLayout()
+}
+
+// Maximize makes the window fill the canvas
+func (w *InternalWindowWidget) Maximize() {
+	if w.maximized {
+		return
+	}
+	w.minimized, w.maximized = false, true
+	w.MarkNeedsLayout()
+}
+
+// Restore shows a minimized or maximized window at its place and size again
+func (w *InternalWindowWidget) Restore() {
+	if !w.minimized && !w.maximized {
+		return
+	}
+	w.minimized, w.maximized = false, false
+	w.MarkNeedsLayout()
+}
+
+// IsMinimized reports whether the window is minimized
+func (w *InternalWindowWidget) IsMinimized() bool {
+	return w.minimized
+}
+
+// IsMaximized reports whether the window is maximized
+func (w *InternalWindowWidget) IsMaximized() bool {
+	return w.maximized
+}
+
+// Bounds returns the window's place and size when neither minimized nor
+// maximized
+func (w *InternalWindowWidget) Bounds() Rect {
+	return w.rect
+}
+
+// GetConstraints returns the smallest size of a window, its title bar
+func (w *InternalWindowWidget) GetConstraints() Constraints {
+	return NewFlexConstraints(internalWindowMinWidth, w.titleHeight(), 1e9, 1e9)
+}
+
+// Measure returns the minimum size
+func (w *InternalWindowWidget) Measure(constraints Constraints) Size {
+	return minSize(w.GetConstraints())
+}
+
+// Layout implements the Widget interface for InternalWindowWidget; windows
+// take the box they are placed in and lay the content out below the title
+// bar unless minimized
+func (w *InternalWindowWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !w.NeedsLayout(ctx, constraints) {
+		return w.CachedSize(), nil
+	}
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	w.SetLayout(ctx, constraints, size)
+	if w.content != nil && !w.minimized {
+		inner := w.contentBox(NewBox(0, 0, size.Width, size.Height, constraints))
+		if _, err = w.content.Layout(ctx, NewRigidConstraints(inner.Size.Width, inner.Size.Height)); chk.E(err) {
+			return
+		}
+	}
+	return
+}
+
+// Paint implements the Widget interface for InternalWindowWidget
+func (w *InternalWindowWidget) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	list := ctx.DrawList
+	radius := th.Radius.Medium
+	paintElevated(ctx, box, internalWindowElevation, radius)
+	border := th.Border
+	if w.active() {
+		border = th.Primary
+	}
+	fillBordered(ctx, box, radius, border, th.Background)
+	x, y, width := box.Position.X, box.Position.Y, box.Size.Width
+	h := w.titleHeight()
+	list.RoundRect(x+1, y+1, width-2, h-1, max(radius-1, 0), th.Surface)
+	if !w.minimized {
+		// Square off the bottom of the title bar above the content
+		list.Rect(x+1, y+h/2, width-2, h/2, th.Surface)
+		list.Rect(x+1, y+h-1, width-2, 1, th.Border)
+	}
+	buttons := w.buttons()
+	color := th.TextMuted
+	if w.active() {
+		color = th.Text
+	}
+	face := w.font.Face(w.size)
+	list.PushClip(x, y, max(width-float32(len(buttons))*h-internalWindowPadding/2, 0), h)
+	face.Draw(list, x+internalWindowPadding, y+(h-face.LineHeight())/2+face.Ascent(), w.title, color)
+	list.PopClip()
+	for i, b := range buttons {
+		w.paintButton(ctx, b, x+width-float32(len(buttons)-i)*h, y, h, color)
+	}
+	if w.content != nil && !w.minimized {
+		if err = paintChild(ctx, w.content, w.contentBox(box)); chk.E(err) {
+			return
+		}
+	}
+	return
+}
+
+// paintButton draws a button of the title bar in the square at x, y
+func (w *InternalWindowWidget) paintButton(ctx *Context, b internalWindowButton, x, y, size float32, color [4]float32) {
+	th := themeOf(ctx)
+	list := ctx.DrawList
+	if b == w.hot {
+		bg := th.SurfaceHover
+		if b == internalWindowClose {
+			bg = th.Error
+		}
+		if b == w.pressed {
+			bg = th.SurfacePressed
+		}
+		list.RoundRect(x+3, y+3, size-6, size-6, th.Radius.Small, bg)
+	}
+	cx, cy := x+size/2, y+size/2
+	d := float32(math.Round(float64(size) / 6))
+	switch b {
+	case internalWindowMinimize:
+		list.Line(cx-d, cy+d, cx+d, cy+d, 1.5, color)
+	case internalWindowMaximize:
+		strokeSquare(ctx, cx-d, cy-d, 2*d, color)
+	case internalWindowRestore:
+		s := 2 * d * 3 / 4
+		strokeSquare(ctx, cx-d, cy-d+2*d-s, s, color)
+		list.Line(cx-d+2*d-s, cy-d, cx+d, cy-d, 1.5, color)
+		list.Line(cx+d, cy-d, cx+d, cy-d+s, 1.5, color)
+	case internalWindowClose:
+		list.Line(cx-d, cy-d, cx+d, cy+d, 1.5, color)
+		list.Line(cx-d, cy+d, cx+d, cy-d, 1.5, color)
+	}
+}
+
+// strokeSquare outlines a square with its top left corner at x, y
+func strokeSquare(ctx *Context, x, y, size float32, color [4]float32) {
+	list := ctx.DrawList
+	list.Line(x, y, x+size, y, 1.5, color)
+	list.Line(x+size, y, x+size, y+size, 1.5, color)
+	list.Line(x+size, y+size, x, y+size, 1.5, color)
+	list.Line(x, y+size, x, y, 1.5, color)
+}
+
+// paintBounds implements overflowing, returning the box along with the
+// shadow the window casts
+func (w *InternalWindowWidget) paintBounds(box *Box) Rect {
+	dy, blur := elevation(internalWindowElevation)
+	return shadowBounds(box, 0, dy, blur)
+}
+
+// HandleEvent implements the Widget interface for InternalWindowWidget
+func (w *InternalWindowWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		switch {
+		case w.moving:
+			w.moveTo(e.Position)
+			return true
+		case w.edges != (dockEdges{}):
+			w.resizeTo(e.Position)
+			return true
+		}
+		w.setHot(w.buttonAt(box, e.Position))
+	case interfaces.CursorLeaveEvent:
+		w.setHot(internalWindowNoButton)
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft {
+			break
+		}
+		if e.Action == interfaces.ActionPress && box.Contains(e.Position) {
+			w.Raise()
+			if w.press(ctx, box, e.Position) {
+				return true
+			}
+			break
+		}
+		if e.Action == interfaces.ActionRelease && (w.moving || w.edges != (dockEdges{}) || w.pressed != internalWindowNoButton || w.titlePressed) {
+			w.release(box, e.Position)
+			return true
+		}
+	}
+	if w.content != nil && !w.minimized {
+		return routeEvent(ctx, w.content, w.contentBox(box), ev)
+	}
+	at, targeted := interfaces.Target(ev)
+	return targeted && box.Contains(at)
+}
+
+// CursorAt implements CursorProvider, showing a resize cursor over the
+// edges of resizable windows and while they are resized
+func (w *InternalWindowWidget) CursorAt(p Point) Cursor {
+	edges := w.edges
+	if edges == (dockEdges{}) {
+		edges = w.edgesAt(&w.paintBox, p)
+	}
+	switch {
+	case edges.left || edges.right:
+		return interfaces.CursorResizeH
+	case edges.top || edges.bottom:
+		return interfaces.CursorResizeV
+	}
+	return interfaces.CursorDefault
+}
+
+// press holds down a button of the title bar, the frame for resizing or the
+// title bar for moving, reporting whether the press was taken. A double
+// click on the title bar maximizes or restores the window.
+func (w *InternalWindowWidget) press(ctx *Context, box *Box, p Point) bool {
+	if b := w.buttonAt(box, p); b != internalWindowNoButton {
+		w.pressed = b
+		w.MarkNeedsPaint()
+		return true
+	}
+	if edges := w.edgesAt(box, p); edges != (dockEdges{}) {
+		w.edges, w.grab, w.grabRect = edges, p, w.rect
+		return true
+	}
+	if !w.titleBox(box).Contains(p) {
+		return false
+	}
+	now := frameTime(ctx)
+	double := now.Sub(w.lastClick) <= doubleTapDelay
+	w.lastClick = now
+	switch {
+	case double:
+		w.lastClick = time.Time{}
+		switch {
+		case w.minimized || w.maximized:
+			w.Restore()
+		case w.maximizable:
+			w.Maximize()
+		}
+	case w.minimized:
+		w.titlePressed = true
+	case !w.maximized:
+		w.moving, w.grab, w.grabRect = true, p, Rect{X: box.Position.X, Y: box.Position.Y, Width: w.rect.Width, Height: w.rect.Height}
+	}
+	return true
+}
+
+// release clicks the button of the title bar released over, restores a
+// minimized window whose title bar was clicked, or ends moving or resizing
+func (w *InternalWindowWidget) release(box *Box, p Point) {
+	pressed, titlePressed := w.pressed, w.titlePressed
+	w.moving, w.edges, w.pressed, w.titlePressed = false, dockEdges{}, internalWindowNoButton, false
+	w.MarkNeedsPaint()
+	if titlePressed && w.titleBox(box).Contains(p) {
+		w.Restore()
+		return
+	}
+	if pressed == internalWindowNoButton || w.buttonAt(box, p) != pressed {
+		return
+	}
+	switch pressed {
+	case internalWindowMinimize:
+		w.Minimize()
+	case internalWindowMaximize:
+		w.Maximize()
+	case internalWindowRestore:
+		w.Restore()
+	case internalWindowClose:
+		if w.onClose == nil || w.onClose() {
+			w.Close()
+		}
+	}
+}
+
+// moveTo drags the window by its title bar to follow the cursor
+func (w *InternalWindowWidget) moveTo(p Point) {
+	w.rect.X = w.grabRect.X + p.X - w.grab.X
+	w.rect.Y = w.grabRect.Y + p.Y - w.grab.Y
+	w.placed = true
+	w.rect = w.clamp(w.rect, w.canvas())
+}
+
+// resizeTo drags the held edges of the window to the cursor
+func (w *InternalWindowWidget) resizeTo(p Point) {
+	r, from := w.grabRect, w.grabRect
+	dx, dy := p.X-w.grab.X, p.Y-w.grab.Y
+	if w.edges.left {
+		r.Width = max(from.Width-dx, internalWindowMinWidth)
+		r.X = from.X + from.Width - r.Width
+	}
+	if w.edges.right {
+		r.Width = max(from.Width+dx, internalWindowMinWidth)
+	}
+	if w.edges.top {
+		r.Height = max(from.Height-dy, internalWindowMinHeight)
+		r.Y = from.Y + from.Height - r.Height
+	}
+	if w.edges.bottom {
+		r.Height = max(from.Height+dy, internalWindowMinHeight)
+	}
+	w.placed = true
+	w.rect = w.clamp(r, w.canvas())
+	w.MarkNeedsLayout()
+}
+
+// place implements the placer of the window's popup, returning its box in
+// a canvas of the given size. A window never placed opens in the middle.
+func (w *InternalWindowWidget) place(canvas Size) Box {
+	var r Rect
+	switch {
+	case w.minimized:
+		h := w.titleHeight()
+		r = Rect{
+			X:      internalWindowGap + float32(w.slot)*(internalWindowMinimized+internalWindowGap),
+			Y:      canvas.Height - h - internalWindowGap,
+			Width:  internalWindowMinimized,
+			Height: h,
+		}
+	case w.maximized:
+		r = Rect{Width: canvas.Width, Height: canvas.Height}
+	default:
+		if !w.placed && canvas.Width > 0 {
+			w.rect.X = float32(math.Round(float64(canvas.Width-w.rect.Width) / 2))
+			w.rect.Y = float32(math.Round(float64(canvas.Height-w.rect.Height) / 2))
+			w.placed = true
+		}
+		r = w.clamp(w.rect, canvas)
+	}
+	return *NewBox(r.X, r.Y, r.Width, r.Height, w.GetConstraints())
+}
+
+// clamp keeps a window's place and size within a canvas where it fits
+func (w *InternalWindowWidget) clamp(r Rect, canvas Size) Rect {
+	r.Width = max(min(r.Width, canvas.Width), min(internalWindowMinWidth, canvas.Width))
+	r.Height = max(min(r.Height, canvas.Height), min(internalWindowMinHeight, canvas.Height))
+	r.X = max(min(r.X, canvas.Width-r.Width), 0)
+	r.Y = max(min(r.Y, canvas.Height-r.Height), 0)
+	return r
+}
+
+// canvas returns the size of the canvas the window is shown in
+func (w *InternalWindowWidget) canvas() Size {
+	if w.popup != nil && w.popup.root != nil {
+		return w.popup.root.CachedSize()
+	}
+	return Size{Width: 1e9, Height: 1e9}
+}
+
+// buttons returns the buttons of the title bar from left to right
+func (w *InternalWindowWidget) buttons() (buttons []internalWindowButton) {
+	switch {
+	case w.minimized:
+		buttons = append(buttons, internalWindowRestore)
+	case w.minimizable:
+		buttons = append(buttons, internalWindowMinimize)
+	}
+	switch {
+	case w.maximized:
+		buttons = append(buttons, internalWindowRestore)
+	case w.maximizable:
+		buttons = append(buttons, internalWindowMaximize)
+	}
+	if w.closable {
+		buttons = append(buttons, internalWindowClose)
+	}
+	return
+}
+
+// buttonAt returns the button of the title bar under a point, if any
+func (w *InternalWindowWidget) buttonAt(box *Box, p Point) internalWindowButton {
+	if !w.titleBox(box).Contains(p) {
+		return internalWindowNoButton
+	}
+	h := w.titleHeight()
+	buttons := w.buttons()
+	i := len(buttons) - 1 - int((box.Position.X+box.Size.Width-p.X)/h)
+	if i < 0 || i >= len(buttons) {
+		return internalWindowNoButton
+	}
+	return buttons[i]
+}
+
+// edgesAt returns the edges of a resizable window's frame under a point,
+// both edges near its corners
+func (w *InternalWindowWidget) edgesAt(box *Box, p Point) (edges dockEdges) {
+	if !w.resizable || w.minimized || w.maximized || !box.Contains(p) {
+		return
+	}
+	x, y := p.X-box.Position.X, p.Y-box.Position.Y
+	width, height := box.Size.Width, box.Size.Height
+	if x >= internalWindowGrip && y >= internalWindowGrip && x < width-internalWindowGrip && y < height-internalWindowGrip {
+		return
+	}
+	return dockEdges{
+		left:   x < internalWindowCorner,
+		top:    y < internalWindowCorner,
+		right:  x >= width-internalWindowCorner,
+		bottom: y >= height-internalWindowCorner,
+	}
+}
+
+// active reports whether the window is shown over every other window
+func (w *InternalWindowWidget) active() bool {
+	if w.popup == nil || w.popup.root == nil {
+		return false
+	}
+	windows := internalWindows(w.popup.root)
+	return len(windows) > 0 && windows[len(windows)-1] == w
+}
+
+// repaintWindows repaints the windows shown with this one, as the one
+// shown over the others is drawn active
+func (w *InternalWindowWidget) repaintWindows() {
+	for _, o := range internalWindows(w.popup.root) {
+		o.MarkNeedsPaint()
+	}
+}
+
+// internalWindows returns the internal windows a root shows, from bottom to top
+func internalWindows(r *RootWidget) (windows []*InternalWindowWidget) {
+	for _, p := range r.popups {
+		if w, ok := p.content.(*InternalWindowWidget); ok {
+			windows = append(windows, w)
+		}
+	}
+	return
+}
+
+// setHot updates the button under the cursor, repainting when it changes
+func (w *InternalWindowWidget) setHot(b internalWindowButton) {
+	if b != w.hot {
+		w.hot = b
+		w.MarkNeedsPaint()
+	}
+}
+
+// titleHeight returns the height of the title bar
+func (w *InternalWindowWidget) titleHeight() float32 {
+	return float32(math.Ceil(float64(w.font.Face(w.size).LineHeight()))) + internalWindowPadding
+}
+
+// titleBox returns the absolute box of the title bar
+func (w *InternalWindowWidget) titleBox(box *Box) *Box {
+	return NewBox(box.Position.X, box.Position.Y, box.Size.Width, w.titleHeight(), w.GetConstraints())
+}
+
+// contentBox returns the absolute box of the content, inside the border
+// below the title bar
+func (w *InternalWindowWidget) contentBox(box *Box) *Box {
+	h := w.titleHeight()
+	var c Constraints
+	if w.content != nil {
+		c = w.content.GetConstraints()
+	}
+	return NewBox(box.Position.X+1, box.Position.Y+h, max(box.Size.Width-2, 0), max(box.Size.Height-h-1, 0), c)
+}
@@ -0,0 +1,262 @@
+package widget
+
+import (
+	"unicode/utf8"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/state"
+	"github.com/mleku/goo/pkg/text"
+)
+
+// LabelWidget renders a single line of text
+type LabelWidget struct {
+	Base
+	font  *text.Font
+	size  float32
+	text  string
+	color colorOverride
+	align text.Alignment
+	// unbind stops following the value bound as the text
+	unbind     func()
+	selectable bool
+	onSelect   func(selected string)
+	// anchor and caret are the rune positions the selection runs between
+	anchor, caret int
+	dragging      bool
+}
+
+// Label creates a new label that renders the string with the given font.
+// The label defaults to 14 pixel text in the theme's text color aligned to
+// the start of its box.
+func Label(font *text.Font, s string) *LabelWidget {
+	return &LabelWidget{
+		font:  font,
+		size:  14,
+		text:  s,
+		align: text.AlignStart,
+	}
+}
+
+// Size sets the pixel size of the text and returns the label for chaining
+func (l *LabelWidget) Size(size float32) *LabelWidget {
+	l.size = size
+	l.MarkNeedsLayout()
+	return l
+}
+
+// Color sets the text color, replacing the theme's, and returns the label for chaining
+func (l *LabelWidget) Color(red, green, blue, alpha float32) *LabelWidget {
+	l.color = override([4]float32{red, green, blue, alpha})
+	l.MarkNeedsPaint()
+	return l
+}
+
+// Align sets the horizontal alignment within the box and returns the label for chaining
+func (l *LabelWidget) Align(align text.Alignment) *LabelWidget {
+	l.align = align
+	l.MarkNeedsPaint()
+	return l
+}
+
+// Selectable sets whether the text can be selected by dragging and copied,
+// such as for values and log output the user may want elsewhere, and
+// returns the label for chaining
+func (l *LabelWidget) Selectable(selectable bool) *LabelWidget {
+	l.selectable = selectable
+	l.anchor, l.caret = 0, 0
+	l.MarkNeedsPaint()
+	return l
+}
+
+// OnSelectionChange sets the callback invoked with the selected text when
+// the user changes the selection, and returns the label for chaining
+func (l *LabelWidget) OnSelectionChange(fn func(selected string)) *LabelWidget {
+	l.onSelect = fn
+	return l
+}
+
+// SetText replaces the displayed string and clears the selection
+func (l *LabelWidget) SetText(s string) {
+	if s == l.text {
+		return
+	}
+	l.text = s
+	l.anchor, l.caret = 0, 0
+	l.MarkNeedsLayout()
+}
+
+// Bind shows an observable value as the text, following its changes, and
+// returns the label for chaining. Bind a message of an i18n.Catalog to have
+// the label follow the locale. Binding again replaces the value followed and
+// nil stops following.
+func (l *LabelWidget) Bind(value state.Value[string]) *LabelWidget {
+	if l.unbind != nil {
+		l.unbind()
+		l.unbind = nil
+	}
+	if value != nil {
+		l.unbind = state.Watch(value, l.SetText)
+	}
+	return l
+}
+
+// Text returns the displayed string
+func (l *LabelWidget) Text() string {
+	return l.text
+}
+
+// SelectedText returns the selected text, empty when nothing is selected
+func (l *LabelWidget) SelectedText() string {
+	start, end := l.selection()
+	return string([]rune(l.text)[start:end])
+}
+
+// GetConstraints returns a minimum size that fits the text on one line
+func (l *LabelWidget) GetConstraints() Constraints {
+	face := l.font.Face(l.size)
+	return NewFlexConstraints(face.Measure(l.text), face.LineHeight(), 1e9, 1e9)
+}
+
+// Measure returns the minimum size of the label, which fits its text on one line
+func (l *LabelWidget) Measure(constraints Constraints) Size {
+	return minSize(l.GetConstraints())
+}
+
+// Layout implements the Widget interface for LabelWidget; labels take all the
+// space offered and align the text within it
+func (l *LabelWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	l.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for LabelWidget
+func (l *LabelWidget) Paint(ctx *Context, box *Box) (err error) {
+	face := l.font.Face(l.size)
+
+	// Clip text to the box
+	list := ctx.DrawList
+	list.PushClip(box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height)
+	defer list.PopClip()
+
+	// Align horizontally, from the right in a mirrored layout, and center
+	// the line vertically
+	align := l.align
+	if ctx.RTL {
+		align = align.Mirrored()
+	}
+	x := box.Position.X + align.Offset(face.Measure(l.text), box.Size.Width)
+	top := box.Position.Y + (box.Size.Height-face.LineHeight())/2
+	if start, end := l.selection(); start != end && hasFocus(l) {
+		for _, span := range face.Layout(l.text).Selection(start, end) {
+			list.Rect(x+span[0], top, span[1]-span[0], face.LineHeight(), themeOf(ctx).Selection)
+		}
+	}
+	baseline := top + face.Ascent()
+	face.Draw(list, x, baseline, l.text, l.color.or(themeOf(ctx).Text))
+	return
+}
+
+// Accessibility implements Accessible, naming the label by its text
+func (l *LabelWidget) Accessibility() AccessNode {
+	return AccessNode{Role: interfaces.RoleLabel, Name: l.text}
+}
+
+// CursorAt implements CursorProvider, showing the text cursor over
+// selectable labels
+func (l *LabelWidget) CursorAt(p Point) Cursor {
+	if l.selectable {
+		return interfaces.CursorIBeam
+	}
+	return interfaces.CursorDefault
+}
+
+// HandleEvent implements the Widget interface for LabelWidget; labels ignore
+// input unless selectable, when dragging selects, a shift-click extends the
+// selection and the copy shortcut copies it
+func (l *LabelWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if !l.selectable {
+		return false
+	}
+	switch e := ev.(type) {
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft {
+			return false
+		}
+		switch e.Action {
+		case interfaces.ActionPress:
+			// Presses are only delivered when the cursor is inside the box
+			requestFocus(l)
+			l.moveTo(l.indexAt(ctx, box, e.Position.X), e.Mods&interfaces.ModShift != 0)
+			l.dragging = true
+			return true
+		case interfaces.ActionRelease:
+			if !l.dragging {
+				return false
+			}
+			l.dragging = false
+			return true
+		}
+	case interfaces.MouseMoveEvent:
+		if !l.dragging {
+			return false
+		}
+		l.moveTo(l.indexAt(ctx, box, e.Position.X), true)
+		return true
+	case interfaces.KeyEvent:
+		if !hasFocus(l) || e.Action == interfaces.ActionRelease || e.Mods&(interfaces.ModControl|interfaces.ModSuper) == 0 {
+			return false
+		}
+		switch e.Key {
+		case interfaces.KeyA:
+			l.selectRange(0, utf8.RuneCountInString(l.text))
+			return true
+		case interfaces.KeyC:
+			if s := l.SelectedText(); s != "" && ctx.Clipboard != nil {
+				ctx.Clipboard.SetText(s)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// moveTo moves the caret to a rune position, extending the selection from
+// the anchor or collapsing it
+func (l *LabelWidget) moveTo(pos int, extend bool) {
+	anchor := pos
+	if extend {
+		anchor = l.anchor
+	}
+	l.selectRange(anchor, pos)
+}
+
+// selectRange sets the anchor and caret, notifying a change of the text
+// selected
+func (l *LabelWidget) selectRange(anchor, caret int) {
+	start, end := l.selection()
+	l.anchor, l.caret = anchor, caret
+	if s, e := l.selection(); s == start && e == end {
+		return
+	}
+	l.MarkNeedsPaint()
+	if l.onSelect != nil {
+		l.onSelect(l.SelectedText())
+	}
+}
+
+// selection returns the selected rune range in order
+func (l *LabelWidget) selection() (start, end int) {
+	n := utf8.RuneCountInString(l.text)
+	return min(l.anchor, l.caret, n), min(max(l.anchor, l.caret), n)
+}
+
+// indexAt returns the rune position closest to a window x coordinate
+func (l *LabelWidget) indexAt(ctx *Context, box *Box, x float32) int {
+	face := l.font.Face(l.size)
+	align := l.align
+	if ctx.RTL {
+		align = align.Mirrored()
+	}
+	return face.Index(l.text, x-box.Position.X-align.Offset(face.Measure(l.text), box.Size.Width))
+}
@@ -0,0 +1,60 @@
+package widget_test
+
+import (
+	"testing"
+
+	"github.com/mleku/goo/pkg/i18n"
+	"github.com/mleku/goo/pkg/widget"
+)
+
+// layoutCounter fills its constraints and counts the times it is laid out
+type layoutCounter struct {
+	widget.Base
+	layouts int
+}
+
+func (c *layoutCounter) Layout(ctx *widget.Context, constraints widget.Constraints) (size widget.Size, err error) {
+	if !c.NeedsLayout(ctx, constraints) {
+		return c.CachedSize(), nil
+	}
+	c.layouts++
+	size = widget.Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	c.SetLayout(ctx, constraints, size)
+	return
+}
+
+func (c *layoutCounter) Paint(*widget.Context, *widget.Box) error { return nil }
+
+func (c *layoutCounter) GetConstraints() widget.Constraints { return widget.Constraints{} }
+
+func (c *layoutCounter) Measure(constraints widget.Constraints) widget.Size {
+	return widget.Size{Width: constraints.MinWidth, Height: constraints.MinHeight}
+}
+
+func (c *layoutCounter) HandleEvent(*widget.Context, *widget.Box, widget.Event) bool { return false }
+
+func TestRelayoutPerRoot(t *testing.T) {
+	switched, other := &layoutCounter{}, &layoutCounter{}
+	a := newHarness(t, switched, 100, 100)
+	b := newHarness(t, other, 100, 100)
+	catalog := i18n.NewCatalog(i18n.English())
+	a.root.Localize(catalog)
+	a.frame()
+	b.frame()
+	if switched.layouts != 1 || other.layouts != 1 {
+		t.Fatalf("laid out %d and %d times before switching, want once each", switched.layouts, other.layouts)
+	}
+	catalog.SetLocale(i18n.Arabic())
+	a.frame()
+	b.frame()
+	if switched.layouts != 2 {
+		t.Errorf("the localized tree was laid out %d times, want 2", switched.layouts)
+	}
+	if other.layouts != 1 {
+		t.Errorf("switching the locale of one root laid out another's tree again")
+	}
+	a.frame()
+	if switched.layouts != 2 {
+		t.Errorf("laid out %d times on the frame after switching, want 2", switched.layouts)
+	}
+}
@@ -0,0 +1,251 @@
+package widget
+
+import (
+	"math"
+
+	"github.com/mleku/goo/pkg/browser"
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/text"
+	"lol.mleku.dev/chk"
+)
+
+// LinkWidget is a line of text in the theme's link color that opens a web
+// address when clicked, or when enter is pressed while it has focus. It is
+// underlined while the cursor is over it or it has focus, and drawn in the
+// visited color once followed.
+type LinkWidget struct {
+	Base
+	font     *text.Font
+	size     float32
+	label    string
+	url      string
+	visited  bool
+	disabled bool
+	hovered  bool
+	pressed  bool
+	onClick  func(url string)
+	// area is the text as last painted, which alone responds to the cursor
+	area Rect
+}
+
+// Link creates a new link showing the label in the given font that opens
+// the url in the system's browser. The label defaults to 14 pixel text.
+func Link(font *text.Font, label, url string) *LinkWidget {
+	return &LinkWidget{font: font, size: 14, label: label, url: url}
+}
+
+// Size sets the pixel size of the text and returns the link for chaining
+func (l *LinkWidget) Size(size float32) *LinkWidget {
+	l.size = size
+	l.MarkNeedsLayout()
+	return l
+}
+
+// OnClick sets the callback invoked with the url when the link is followed,
+// in place of opening it in the browser, and returns the link for chaining
+func (l *LinkWidget) OnClick(fn func(url string)) *LinkWidget {
+	l.onClick = fn
+	return l
+}
+
+// Visited sets whether the link is drawn as already followed and returns
+// the link for chaining. Following a link marks it visited.
+func (l *LinkWidget) Visited(visited bool) *LinkWidget {
+	l.visited = visited
+	l.MarkNeedsPaint()
+	return l
+}
+
+// Disabled sets whether the link ignores input, drawn as muted text, and
+// returns the link for chaining
+func (l *LinkWidget) Disabled(disabled bool) *LinkWidget {
+	l.disabled = disabled
+	if disabled {
+		l.pressed = false
+	}
+	l.MarkNeedsPaint()
+	return l
+}
+
+// SetText replaces the displayed label and the address it opens
+func (l *LinkWidget) SetText(label, url string) {
+	if label == l.label && url == l.url {
+		return
+	}
+	l.label, l.url = label, url
+	l.MarkNeedsLayout()
+}
+
+// Text returns the displayed label
+func (l *LinkWidget) Text() string {
+	return l.label
+}
+
+// URL returns the address the link opens
+func (l *LinkWidget) URL() string {
+	return l.url
+}
+
+// IsVisited reports whether the link has been followed
+func (l *LinkWidget) IsVisited() bool {
+	return l.visited
+}
+
+// GetConstraints returns a minimum size that fits the text on one line
+func (l *LinkWidget) GetConstraints() Constraints {
+	face := l.font.Face(l.size)
+	return NewFlexConstraints(face.Measure(l.label), face.LineHeight(), 1e9, 1e9)
+}
+
+// Measure returns the minimum size of the link, which fits its text on one line
+func (l *LinkWidget) Measure(constraints Constraints) Size {
+	return minSize(l.GetConstraints())
+}
+
+// Layout implements the Widget interface for LinkWidget; links take all the
+// space offered and draw at the start of it
+func (l *LinkWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	l.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for LinkWidget
+func (l *LinkWidget) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	face := l.font.Face(l.size)
+	list := ctx.DrawList
+	list.PushClip(box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height)
+	defer list.PopClip()
+
+	width := face.Measure(l.label)
+	align := text.AlignStart
+	if ctx.RTL {
+		align = align.Mirrored()
+	}
+	x := box.Position.X + align.Offset(width, box.Size.Width)
+	top := box.Position.Y + (box.Size.Height-face.LineHeight())/2
+	l.area = Rect{X: x, Y: top, Width: width, Height: face.LineHeight()}
+
+	focused := hasFocus(l)
+	if focused {
+		list.RoundRect(x-2, top, width+4, face.LineHeight(), th.Radius.Small, th.Selection)
+	}
+	color := th.Link
+	switch {
+	case l.disabled:
+		color = th.TextMuted
+	case l.pressed:
+		color = th.LinkActive
+	case l.visited:
+		color = th.LinkVisited
+	}
+	baseline := top + face.Ascent()
+	face.Draw(list, x, baseline, l.label, color)
+	if (l.hovered || focused) && !l.disabled {
+		// The underline is as thick as a twelfth of the text, at least a pixel
+		thickness := max(float32(math.Round(float64(face.Size()/12))), 1)
+		list.Rect(x, float32(math.Round(float64(baseline)))+thickness, width, thickness, color)
+	}
+	return
+}
+
+// CursorAt implements CursorProvider, showing the hand over the text
+func (l *LinkWidget) CursorAt(p Point) Cursor {
+	if !l.disabled && l.area.Contains(p) {
+		return interfaces.CursorHand
+	}
+	return interfaces.CursorDefault
+}
+
+// navigable lets gamepad navigation reach an enabled link
+func (l *LinkWidget) navigable() bool {
+	return !l.disabled
+}
+
+// Accessibility implements Accessible, naming the link by its text
+func (l *LinkWidget) Accessibility() AccessNode {
+	return AccessNode{
+		Role:    interfaces.RoleLink,
+		Name:    l.label,
+		Value:   l.url,
+		State:   disabledState(l.disabled),
+		Actions: []AccessAction{interfaces.AccessClick},
+	}
+}
+
+// AccessAction implements AccessActor, following the link
+func (l *LinkWidget) AccessAction(ctx *Context, action AccessAction, value string) bool {
+	if action != interfaces.AccessClick || l.disabled {
+		return false
+	}
+	l.follow()
+	return true
+}
+
+// HandleEvent implements the Widget interface for LinkWidget
+func (l *LinkWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if l.disabled {
+		return false
+	}
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		l.setHovered(l.area.Contains(e.Position))
+	case interfaces.CursorLeaveEvent:
+		l.setHovered(false)
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft {
+			return false
+		}
+		switch e.Action {
+		case interfaces.ActionPress:
+			if !l.area.Contains(e.Position) {
+				return false
+			}
+			requestFocus(l)
+			l.pressed = true
+			l.MarkNeedsPaint()
+			return true
+		case interfaces.ActionRelease:
+			// Releases are broadcast, so only follow when released over the text
+			if !l.pressed {
+				return false
+			}
+			l.pressed = false
+			l.MarkNeedsPaint()
+			if l.area.Contains(e.Position) {
+				l.follow()
+			}
+			return true
+		}
+	case interfaces.KeyEvent:
+		if !hasFocus(l) || e.Key != interfaces.KeyEnter {
+			return false
+		}
+		if e.Action == interfaces.ActionPress {
+			l.follow()
+		}
+		return true
+	}
+	return false
+}
+
+// follow marks the link visited and hands its address to the callback, or
+// opens it in the browser when there is none
+func (l *LinkWidget) follow() {
+	l.visited = true
+	l.MarkNeedsPaint()
+	if l.onClick != nil {
+		l.onClick(l.url)
+		return
+	}
+	chk.E(browser.Open(l.url))
+}
+
+// setHovered updates the hover state, repainting when it changes
+func (l *LinkWidget) setHovered(hovered bool) {
+	if hovered != l.hovered {
+		l.hovered = hovered
+		l.MarkNeedsPaint()
+	}
+}
@@ -0,0 +1,697 @@
+package widget
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/mleku/goo/pkg/icons"
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/render"
+	"github.com/mleku/goo/pkg/text"
+	"lol.mleku.dev/chk"
+)
+
+const (
+	// menuPadding is the space around the text of menu rows
+	menuPadding = 6
+	// menuSeparator is the height of a separator row
+	menuSeparator = 9
+	// menuArrow is the width of the arrow marking rows that open submenus
+	menuArrow = 4
+	// menuMinWidth is the narrowest a menu is drawn
+	menuMinWidth = 120
+	// menuShortcutGap separates a label from its shortcut
+	menuShortcutGap = 24
+	// menuElevation is how far menus are raised above the window, sizing their shadow
+	menuElevation = 3
+)
+
+// MenuItem is an entry of a menu. Items run OnSelect when chosen, open
+// Submenu when it is set, or are drawn as a dividing line when Separator is
+// set. Disabled items are drawn muted and cannot be chosen. An ampersand in
+// the label marks the following character as the item's mnemonic, drawn
+// underlined, and "&&" shows an ampersand. The shortcut is shown beside the
+// label, and menu bars choose the item when it is pressed. The item shows
+// Icon before its label, or the icon of the built-in set named IconName in
+// the text color.
+type MenuItem struct {
+	Label     string
+	Icon      *render.Texture
+	IconName  string
+	Shortcut  Shortcut
+	Disabled  bool
+	Separator bool
+	Submenu   *Menu
+	OnSelect  func()
+}
+
+// selectable reports whether the item can be highlighted and chosen
+func (it *MenuItem) selectable() bool {
+	return !it.Separator && !it.Disabled
+}
+
+// Menu is a list of items shown in a popup, such as a context menu. Rows
+// highlight under the cursor, hovering a row with a submenu opens it beside
+// the menu, and releasing the mouse over a row chooses it. While open the
+// menu has keyboard focus: the up and down keys move the highlight, right
+// opens a submenu, left closes it, enter or space chooses the highlighted
+// item, an item's mnemonic key chooses it and escape closes the menu.
+type Menu struct {
+	font  *text.Font
+	size  float32
+	items []MenuItem
+	panel *menuPanel
+}
+
+// NewMenu creates a new empty menu drawn in the given font at 14 pixels
+func NewMenu(font *text.Font) *Menu {
+	m := &Menu{font: font, size: 14}
+	m.panel = &menuPanel{menu: m, hot: -1}
+	m.panel.popup = NewPopup(m.panel).OnClose(m.panel.closed)
+	return m
+}
+
+// Size sets the pixel size of the text and returns the menu for chaining
+func (m *Menu) Size(size float32) *Menu {
+	m.size = size
+	m.panel.MarkNeedsPaint()
+	return m
+}
+
+// Item appends an item running onSelect when chosen and returns the menu for chaining
+func (m *Menu) Item(label string, onSelect func()) *Menu {
+	return m.Add(MenuItem{Label: label, OnSelect: onSelect})
+}
+
+// ItemShortcut appends an item running onSelect when chosen or when the
+// shortcut is pressed in a menu bar, and returns the menu for chaining
+func (m *Menu) ItemShortcut(label string, shortcut Shortcut, onSelect func()) *Menu {
+	return m.Add(MenuItem{Label: label, Shortcut: shortcut, OnSelect: onSelect})
+}
+
+// Separator appends a dividing line and returns the menu for chaining
+func (m *Menu) Separator() *Menu {
+	return m.Add(MenuItem{Separator: true})
+}
+
+// Submenu appends an item opening another menu and returns the menu for chaining
+func (m *Menu) Submenu(label string, sub *Menu) *Menu {
+	return m.Add(MenuItem{Label: label, Submenu: sub})
+}
+
+// Add appends items and returns the menu for chaining
+func (m *Menu) Add(items ...MenuItem) *Menu {
+	m.items = append(m.items, items...)
+	m.panel.MarkNeedsPaint()
+	return m
+}
+
+// Items returns the menu's items
+func (m *Menu) Items() []MenuItem {
+	return m.items
+}
+
+// SetDisabled disables or enables the item at index
+func (m *Menu) SetDisabled(index int, disabled bool) {
+	if index < 0 || index >= len(m.items) {
+		return
+	}
+	m.items[index].Disabled = disabled
+	if disabled && m.panel.hot == index {
+		m.panel.highlight(-1)
+	}
+	m.panel.MarkNeedsPaint()
+}
+
+// ShowAt opens the menu with its top left corner at a window position,
+// moved to fit inside the window, and gives it keyboard focus
+func (m *Menu) ShowAt(r *RootWidget, x, y float32) {
+	m.show(r, x, y, nil)
+}
+
+// show opens the menu at a window position for the menu bar, nil if it is
+// not opened from one
+func (m *Menu) show(r *RootWidget, x, y float32, bar *MenuBarWidget) {
+	p := m.panel
+	if p.popup.IsOpen() {
+		p.popup.Close()
+	}
+	p.parent, p.bar = nil, bar
+	p.restore = r.focused
+	p.hot = -1
+	r.ShowPopup(p.popup.At(x, y))
+	r.SetFocus(p)
+}
+
+// Close closes the menu and any submenu open from it
+func (m *Menu) Close() {
+	m.panel.popup.Close()
+}
+
+// IsOpen reports whether the menu is showing
+func (m *Menu) IsOpen() bool {
+	return m.panel.popup.IsOpen()
+}
+
+// find returns the enabled item, in the menu or its submenus, that a key
+// press is the shortcut of, nil if none
+func (m *Menu) find(e interfaces.KeyEvent) *MenuItem {
+	for i := range m.items {
+		it := &m.items[i]
+		switch {
+		case it.Disabled:
+		case it.Submenu != nil:
+			if found := it.Submenu.find(e); found != nil {
+				return found
+			}
+		case it.Shortcut.matches(e):
+			return it
+		}
+	}
+	return nil
+}
+
+// rowHeight returns the height of an item row
+func (m *Menu) rowHeight() float32 {
+	return float32(math.Ceil(float64(m.font.Face(m.size).LineHeight()))) + 2*menuPadding
+}
+
+// menuPanel is the popup content drawing a menu's items
+type menuPanel struct {
+	Base
+	menu  *Menu
+	popup *Popup
+	// parent is the panel this one opened from as a submenu, nil for the
+	// top of the menu and restore the widget that had focus before it opened
+	parent  *menuPanel
+	restore Widget
+	// bar is the menu bar the top of the menu opened from, nil if none
+	bar *MenuBarWidget
+	// hot is the highlighted row, -1 if none, and child the submenu open
+	// from it
+	hot   int
+	child *menuPanel
+}
+
+// columns returns the widths of the icon, shortcut and arrow columns, zero
+// when no item has an icon, a shortcut or a submenu
+func (p *menuPanel) columns() (icon, shortcut, arrow float32) {
+	face := p.menu.font.Face(p.menu.size)
+	for i := range p.menu.items {
+		it := &p.menu.items[i]
+		if it.Icon != nil || it.IconName != "" {
+			// Icons are as tall as the text, followed by a gap
+			icon = p.menu.rowHeight() - menuPadding
+		}
+		if it.Shortcut.Key != 0 {
+			shortcut = max(shortcut, face.Measure(it.Shortcut.String())+menuShortcutGap)
+		}
+		if it.Submenu != nil {
+			arrow = menuArrow + menuPadding
+		}
+	}
+	return
+}
+
+// GetConstraints returns the size that fits every item
+func (p *menuPanel) GetConstraints() Constraints {
+	m := p.menu
+	face := m.font.Face(m.size)
+	icon, shortcut, arrow := p.columns()
+	var width, height float32
+	for i := range m.items {
+		it := &m.items[i]
+		if it.Separator {
+			height += menuSeparator
+			continue
+		}
+		label, _, _ := mnemonic(it.Label)
+		width = max(width, face.Measure(label))
+		height += m.rowHeight()
+	}
+	width = max(float32(math.Ceil(float64(width+icon+shortcut+arrow)))+2*menuPadding+2, menuMinWidth)
+	height += 2
+	return NewFlexConstraints(width, height, width, height)
+}
+
+// Measure returns the minimum size of the menu
+func (p *menuPanel) Measure(constraints Constraints) Size {
+	return minSize(p.GetConstraints())
+}
+
+// Layout implements the Widget interface for menuPanel
+func (p *menuPanel) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	p.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for menuPanel
+func (p *menuPanel) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	m := p.menu
+	paintElevated(ctx, box, menuElevation, th.Radius.Small)
+	fillSkinned(ctx, box, th.Skins.Panel, th.Radius.Small, th.Border, th.Surface)
+
+	list := ctx.DrawList
+	face := m.font.Face(m.size)
+	rowHeight := m.rowHeight()
+	icon, _, arrow := p.columns()
+	x, width := box.Position.X+1, box.Size.Width-2
+	y := box.Position.Y + 1
+	for i := range m.items {
+		it := &m.items[i]
+		if it.Separator {
+			list.Rect(x+menuPadding, y+menuSeparator/2, width-2*menuPadding, 1, th.Border)
+			y += menuSeparator
+			continue
+		}
+		color := th.Text
+		if it.Disabled {
+			color = th.TextMuted
+		}
+		if i == p.hot {
+			list.Rect(x, y, width, rowHeight, th.Selection)
+		}
+		if it.Icon != nil {
+			tint := [4]float32{1, 1, 1, 1}
+			if it.Disabled {
+				tint[3] = 0.5
+			}
+			s := rowHeight - 2*menuPadding
+			list.Image(it.Icon, x+menuPadding, y+menuPadding, s, s, 0, 0, 1, 1, tint)
+		} else if it.IconName != "" {
+			s := rowHeight - 2*menuPadding
+			icons.Draw(list, it.IconName, x+menuPadding, y+menuPadding, s, ctx.Scale, color)
+		}
+		baseline := y + menuPadding + face.Ascent()
+		drawMnemonic(list, face, x+menuPadding+icon, baseline, it.Label, color)
+		if it.Shortcut.Key != 0 {
+			s := it.Shortcut.String()
+			face.Draw(list, x+width-menuPadding-arrow-face.Measure(s), baseline, s, th.TextMuted)
+		}
+		if it.Submenu != nil {
+			ax := x + width - menuPadding - menuArrow
+			ay := float32(math.Round(float64(y + rowHeight/2)))
+			list.Polygon([][2]float32{{ax, ay - menuArrow}, {ax + menuArrow, ay}, {ax, ay + menuArrow}}, color)
+		}
+		y += rowHeight
+	}
+	return
+}
+
+// paintBounds implements overflowing, returning the box along with the
+// menu's shadow
+func (p *menuPanel) paintBounds(box *Box) Rect {
+	dy, blur := elevation(menuElevation)
+	return shadowBounds(box, 0, dy, blur)
+}
+
+// HandleEvent implements the Widget interface for menuPanel
+func (p *menuPanel) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		if !box.Contains(e.Position) {
+			// Keep the row a submenu opened from highlighted while the
+			// cursor visits the submenu
+			if p.child == nil {
+				p.highlight(-1)
+			}
+			return false
+		}
+		i, _ := p.rowAt(box, e.Position)
+		if i != p.hot {
+			p.highlight(i)
+			p.openChild(false)
+		}
+		return true
+	case interfaces.ScrollEvent:
+		// Scrolls are only delivered when the cursor is inside the box
+		return true
+	case interfaces.MouseButtonEvent:
+		if e.Action == interfaces.ActionPress {
+			return true
+		}
+		// Releases are broadcast, so only choose when released over a row
+		i, ok := p.rowAt(box, e.Position)
+		if !ok {
+			return false
+		}
+		p.choose(i)
+		return true
+	case interfaces.KeyEvent:
+		if !hasFocus(p) || e.Action == interfaces.ActionRelease {
+			return false
+		}
+		return p.key(e)
+	}
+	return false
+}
+
+// key moves the highlight, opens and closes submenus, and chooses items
+func (p *menuPanel) key(e interfaces.KeyEvent) (handled bool) {
+	switch e.Key {
+	case interfaces.KeyUp:
+		p.step(-1)
+	case interfaces.KeyDown:
+		p.step(1)
+	case interfaces.KeyHome:
+		p.hot = -1
+		p.step(1)
+	case interfaces.KeyEnd:
+		p.hot = len(p.menu.items)
+		p.step(-1)
+	case interfaces.KeyRight:
+		if p.hot >= 0 && p.menu.items[p.hot].Submenu != nil {
+			p.openChild(true)
+			return true
+		}
+		// Move on to the next menu of the bar
+		top := p.top()
+		if top.bar == nil {
+			return false
+		}
+		top.bar.step(1)
+	case interfaces.KeyLeft:
+		if p.parent != nil {
+			p.popup.Close()
+			return true
+		}
+		if p.bar == nil {
+			return false
+		}
+		p.bar.step(-1)
+	case interfaces.KeyEnter, interfaces.KeySpace:
+		if p.hot < 0 {
+			return false
+		}
+		p.choose(p.hot)
+	default:
+		if e.Mods&(interfaces.ModControl|interfaces.ModAlt|interfaces.ModSuper) != 0 {
+			return false
+		}
+		return p.mnemonic(keyRune(e.Key))
+	}
+	return true
+}
+
+// mnemonic chooses the first selectable item whose mnemonic is the
+// character, reporting whether there is one
+func (p *menuPanel) mnemonic(r rune) bool {
+	if r == 0 {
+		return false
+	}
+	for i := range p.menu.items {
+		it := &p.menu.items[i]
+		if _, m, _ := mnemonic(it.Label); it.selectable() && unicode.ToUpper(m) == r {
+			p.choose(i)
+			return true
+		}
+	}
+	return false
+}
+
+// step moves the highlight to the next selectable row in a direction,
+// wrapping around the ends
+func (p *menuPanel) step(dir int) {
+	items := p.menu.items
+	n := len(items)
+	i := p.hot
+	for range n {
+		i = ((i+dir)%n + n) % n
+		if items[i].selectable() {
+			p.highlight(i)
+			return
+		}
+	}
+}
+
+// highlight highlights a row, -1 for none
+func (p *menuPanel) highlight(index int) {
+	if index == p.hot {
+		return
+	}
+	p.hot = index
+	p.MarkNeedsPaint()
+}
+
+// choose acts on the item at index: an item with a submenu opens it, any
+// other closes the whole menu and runs its callback
+func (p *menuPanel) choose(index int) {
+	it := &p.menu.items[index]
+	if !it.selectable() {
+		return
+	}
+	if it.Submenu != nil {
+		p.highlight(index)
+		p.openChild(true)
+		return
+	}
+	p.top().popup.Close()
+	if it.OnSelect != nil {
+		it.OnSelect()
+	}
+}
+
+// openChild closes any open submenu and opens the one of the highlighted
+// row, if it has one, beside it. With focus the submenu takes keyboard focus
+// with its first item highlighted.
+func (p *menuPanel) openChild(focus bool) {
+	if p.child != nil {
+		p.child.popup.Close()
+	}
+	r := rootOf(p)
+	if r == nil || p.hot < 0 || p.menu.items[p.hot].Submenu == nil {
+		return
+	}
+	child := p.menu.items[p.hot].Submenu.panel
+	if child.popup.IsOpen() {
+		child.popup.Close()
+	}
+	box := p.popup.Box()
+	c := child.GetConstraints()
+	// Line the submenu's first row up with the row it opened from, on the
+	// side with room for it
+	x := box.Position.X + box.Size.Width
+	if x+c.MinWidth > r.CachedSize().Width && box.Position.X >= c.MinWidth {
+		x = box.Position.X - c.MinWidth
+	}
+	child.parent, child.bar, child.restore, child.hot = p, nil, nil, -1
+	p.child = child
+	r.ShowPopup(child.popup.At(x, p.rowTop(p.hot)-1))
+	if focus {
+		child.step(1)
+		r.SetFocus(child)
+	}
+}
+
+// top returns the panel at the top of the menu the panel belongs to
+func (p *menuPanel) top() *menuPanel {
+	for p.parent != nil {
+		p = p.parent
+	}
+	return p
+}
+
+// holds reports whether the panel is the other or one it opened from
+func (p *menuPanel) holds(other *menuPanel) bool {
+	for ; other != nil; other = other.parent {
+		if other == p {
+			return true
+		}
+	}
+	return false
+}
+
+// closed cleans up after the panel's popup closed, returning keyboard focus
+// held by it or a submenu and closing its submenu
+func (p *menuPanel) closed() {
+	parent := p.parent
+	if parent != nil && parent.child == p {
+		parent.child = nil
+	}
+	p.hot = -1
+	if r := rootOf(p); r != nil {
+		if f, ok := r.focused.(*menuPanel); ok && p.holds(f) {
+			if parent != nil && parent.popup.IsOpen() {
+				r.SetFocus(parent)
+			} else {
+				r.SetFocus(p.restore)
+			}
+		}
+	}
+	p.restore = nil
+	if p.child != nil {
+		p.child.popup.Close()
+	}
+	if p.bar != nil {
+		p.bar.closed(p.menu)
+	}
+}
+
+// rowTop returns the absolute top of the row at index
+func (p *menuPanel) rowTop(index int) float32 {
+	y := p.popup.Box().Position.Y + 1
+	for i := range index {
+		if p.menu.items[i].Separator {
+			y += menuSeparator
+		} else {
+			y += p.menu.rowHeight()
+		}
+	}
+	return y
+}
+
+// rowAt returns the selectable row under a point, -1 and false if none
+func (p *menuPanel) rowAt(box *Box, at Point) (index int, ok bool) {
+	if !box.Contains(at) {
+		return -1, false
+	}
+	y := box.Position.Y + 1
+	rowHeight := p.menu.rowHeight()
+	for i := range p.menu.items {
+		it := &p.menu.items[i]
+		h := rowHeight
+		if it.Separator {
+			h = menuSeparator
+		}
+		if at.Y >= y && at.Y < y+h {
+			if !it.selectable() {
+				return -1, false
+			}
+			return i, true
+		}
+		y += h
+	}
+	return -1, false
+}
+
+// mnemonic returns a label without the ampersand marking its mnemonic, the
+// mnemonic character, 0 if none, and its byte offset in the result. "&&"
+// stands for an ampersand.
+func mnemonic(label string) (s string, m rune, offset int) {
+	if !strings.ContainsRune(label, '&') {
+		return label, 0, -1
+	}
+	var b strings.Builder
+	offset = -1
+	escaped := false
+	for _, r := range label {
+		switch {
+		case escaped:
+			escaped = false
+			if r != '&' && m == 0 {
+				m, offset = r, b.Len()
+			}
+		case r == '&':
+			escaped = true
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), m, offset
+}
+
+// drawMnemonic draws a label at a baseline with its mnemonic underlined
+func drawMnemonic(list *render.DrawList, face *text.Face, x, baseline float32, label string, color [4]float32) {
+	s, m, offset := mnemonic(label)
+	face.Draw(list, x, baseline, s, color)
+	if m == 0 {
+		return
+	}
+	ux := x + face.Measure(s[:offset])
+	list.Rect(float32(math.Round(float64(ux))), baseline+1, face.Measure(string(m)), 1, color)
+}
+
+// ContextMenuWidget opens a menu at the cursor when its child is clicked
+// with the right mouse button
+type ContextMenuWidget struct {
+	Base
+	child Widget
+	menu  *Menu
+	// pressed is set while a right button press on the child is held
+	pressed bool
+}
+
+// WithContextMenu wraps the child so right clicking it opens the menu. The
+// wrapper takes the child's constraints.
+func WithContextMenu(child Widget, menu *Menu) *ContextMenuWidget {
+	c := &ContextMenuWidget{child: child, menu: menu}
+	adopt(c, child)
+	return c
+}
+
+// Menu returns the menu opened by right clicks
+func (c *ContextMenuWidget) Menu() *Menu {
+	return c.menu
+}
+
+// SetMenu replaces the menu opened by right clicks, nil for none
+func (c *ContextMenuWidget) SetMenu(menu *Menu) {
+	if c.menu != nil && c.menu != menu {
+		c.menu.Close()
+	}
+	c.menu = menu
+}
+
+// GetConstraints returns the child's constraints
+func (c *ContextMenuWidget) GetConstraints() Constraints {
+	if c.child == nil {
+		return NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return c.child.GetConstraints()
+}
+
+// Measure returns the size the child measures
+func (c *ContextMenuWidget) Measure(constraints Constraints) Size {
+	return measure(c.child, constraints)
+}
+
+// Layout implements the Widget interface for ContextMenuWidget; the child
+// is laid out with the same constraints
+func (c *ContextMenuWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !c.NeedsLayout(ctx, constraints) {
+		return c.CachedSize(), nil
+	}
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	if c.child != nil {
+		if size, err = c.child.Layout(ctx, constraints); chk.E(err) {
+			return
+		}
+	}
+	c.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for ContextMenuWidget
+func (c *ContextMenuWidget) Paint(ctx *Context, box *Box) (err error) {
+	if c.child == nil {
+		return
+	}
+	return paintChild(ctx, c.child, box)
+}
+
+// HandleEvent implements the Widget interface for ContextMenuWidget. The
+// menu opens when the right button is released over the child after being
+// pressed on it; other events are passed on to the child.
+func (c *ContextMenuWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if e, ok := ev.(interfaces.MouseButtonEvent); ok && e.Button == interfaces.MouseButtonRight && c.menu != nil {
+		if e.Action == interfaces.ActionPress {
+			// Presses are only delivered when the cursor is inside the box
+			c.pressed = true
+			return true
+		}
+		if e.Action == interfaces.ActionRelease && c.pressed {
+			c.pressed = false
+			if r := rootOf(c); r != nil && box.Contains(e.Position) {
+				c.menu.ShowAt(r, e.Position.X, e.Position.Y)
+				return true
+			}
+		}
+	}
+	if c.child == nil {
+		return false
+	}
+	return routeEvent(ctx, c.child, box, ev)
+}
@@ -0,0 +1,247 @@
+package widget
+
+import (
+	"math"
+	"unicode"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/text"
+)
+
+// menuBarPadding is the space either side of a menu bar title
+const menuBarPadding = 10
+
+// MenuBarWidget is a row of menu titles along the top of a window. Clicking
+// a title opens its menu below it, and while a menu is open hovering another
+// title switches to its menu. Alt with the mnemonic of a title opens its
+// menu from the keyboard, where the left and right keys move between the
+// menus. The shortcuts of the items in every menu are active wherever the
+// focus is, as long as the focus owner has no use for the keys.
+type MenuBarWidget struct {
+	Base
+	font   *text.Font
+	size   float32
+	titles []string
+	menus  []*Menu
+	// hot is the title under the cursor and open the title whose menu is
+	// showing, -1 if none
+	hot, open int
+}
+
+// MenuBar creates a new empty menu bar with titles drawn in the given font at 14 pixels
+func MenuBar(font *text.Font) *MenuBarWidget {
+	return &MenuBarWidget{
+		font: font,
+		size: 14,
+		hot:  -1,
+		open: -1,
+	}
+}
+
+// Size sets the pixel size of the titles and returns the menu bar for chaining
+func (b *MenuBarWidget) Size(size float32) *MenuBarWidget {
+	b.size = size
+	b.MarkNeedsLayout()
+	return b
+}
+
+// Menu appends a title opening the menu and returns the menu bar for
+// chaining. An ampersand in the title marks its mnemonic as in menu items.
+func (b *MenuBarWidget) Menu(title string, menu *Menu) *MenuBarWidget {
+	b.titles = append(b.titles, title)
+	b.menus = append(b.menus, menu)
+	b.MarkNeedsLayout()
+	return b
+}
+
+// OpenMenu opens the menu of the title at index below it, closing any other
+func (b *MenuBarWidget) OpenMenu(index int) {
+	r := rootOf(b)
+	if r == nil || index < 0 || index >= len(b.menus) || index == b.open {
+		return
+	}
+	b.CloseMenu()
+	x, _ := b.titleSpan(index)
+	box := b.paintBox
+	b.menus[index].show(r, box.Position.X+x, box.Position.Y+box.Size.Height, b)
+	b.open = index
+	b.MarkNeedsPaint()
+}
+
+// CloseMenu closes the open menu
+func (b *MenuBarWidget) CloseMenu() {
+	if b.open >= 0 {
+		b.menus[b.open].Close()
+	}
+}
+
+// Opened returns the index of the title whose menu is open, -1 if none
+func (b *MenuBarWidget) Opened() int {
+	return b.open
+}
+
+// GetConstraints returns the height of a title and the width of all of them
+func (b *MenuBarWidget) GetConstraints() Constraints {
+	var width float32
+	for i := range b.titles {
+		_, w := b.titleSpan(i)
+		width += w
+	}
+	height := b.height()
+	return NewFlexConstraints(width, height, 1e9, height)
+}
+
+// Measure returns the minimum size of the menu bar
+func (b *MenuBarWidget) Measure(constraints Constraints) Size {
+	return minSize(b.GetConstraints())
+}
+
+// Layout implements the Widget interface for MenuBarWidget; menu bars take
+// the width offered. The open menu closes when the bar is resized.
+func (b *MenuBarWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !b.NeedsLayout(ctx, constraints) {
+		return b.CachedSize(), nil
+	}
+	if r := rootOf(b); r != nil {
+		r.addShortcuts(b)
+	}
+	b.CloseMenu()
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	b.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for MenuBarWidget
+func (b *MenuBarWidget) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	list := ctx.DrawList
+	x, y, w, h := box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height
+	list.Rect(x, y, w, h, th.Surface)
+	list.Rect(x, y+h-1, w, 1, th.Border)
+
+	face := b.font.Face(b.size)
+	baseline := y + (h-face.LineHeight())/2 + face.Ascent()
+	for i, title := range b.titles {
+		tx, tw := b.titleSpan(i)
+		switch {
+		case i == b.open:
+			list.Rect(x+tx, y, tw, h-1, th.Selection)
+		case i == b.hot:
+			list.Rect(x+tx, y, tw, h-1, th.SurfaceHover)
+		}
+		drawMnemonic(list, face, x+tx+menuBarPadding, baseline, title, th.Text)
+	}
+	return
+}
+
+// HandleEvent implements the Widget interface for MenuBarWidget
+func (b *MenuBarWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		i := b.titleAt(box, e.Position)
+		b.setHot(i)
+		if b.open >= 0 && i >= 0 {
+			b.OpenMenu(i)
+		}
+		return box.Contains(e.Position)
+	case interfaces.CursorLeaveEvent:
+		b.setHot(-1)
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft || e.Action != interfaces.ActionPress {
+			return false
+		}
+		// Presses are only delivered when the cursor is inside the box. The
+		// root closes an open menu before a press outside it reaches here,
+		// so a press on a title always opens its menu. Focus stays where the
+		// click took it from so the menu's items can act on it.
+		if r := rootOf(b); r != nil && r.focused == nil {
+			r.SetFocus(r.blurred)
+		}
+		b.OpenMenu(b.titleAt(box, e.Position))
+		return true
+	}
+	return false
+}
+
+// shortcut implements shortcutHandler, opening menus by the mnemonics of
+// their titles and choosing items by their shortcuts
+func (b *MenuBarWidget) shortcut(e interfaces.KeyEvent) (handled bool) {
+	if r := keyRune(e.Key); r != 0 && e.Mods&shortcutMods == interfaces.ModAlt {
+		for i, title := range b.titles {
+			if _, m, _ := mnemonic(title); unicode.ToUpper(m) == r {
+				b.OpenMenu(i)
+				b.menus[i].panel.step(1)
+				return true
+			}
+		}
+	}
+	for _, m := range b.menus {
+		if it := m.find(e); it != nil {
+			b.CloseMenu()
+			if it.OnSelect != nil {
+				it.OnSelect()
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// step opens the menu a number of titles along from the open one, wrapping
+// around the ends, with its first item highlighted
+func (b *MenuBarWidget) step(dir int) {
+	n := len(b.menus)
+	if b.open < 0 || n == 0 {
+		return
+	}
+	i := ((b.open+dir)%n + n) % n
+	b.OpenMenu(i)
+	b.menus[i].panel.step(1)
+}
+
+// closed updates the bar after one of its menus closed
+func (b *MenuBarWidget) closed(m *Menu) {
+	if b.open >= 0 && b.menus[b.open] == m {
+		b.open = -1
+		b.MarkNeedsPaint()
+	}
+}
+
+// titleSpan returns the offset from the bar's left edge and the width of the
+// title at index
+func (b *MenuBarWidget) titleSpan(index int) (x, width float32) {
+	face := b.font.Face(b.size)
+	for i := 0; i <= index; i++ {
+		s, _, _ := mnemonic(b.titles[i])
+		x += width
+		width = float32(math.Ceil(float64(face.Measure(s)))) + 2*menuBarPadding
+	}
+	return
+}
+
+// titleAt returns the title under a point, -1 if none
+func (b *MenuBarWidget) titleAt(box *Box, p Point) int {
+	if !box.Contains(p) {
+		return -1
+	}
+	for i := range b.titles {
+		x, w := b.titleSpan(i)
+		if p.X >= box.Position.X+x && p.X < box.Position.X+x+w {
+			return i
+		}
+	}
+	return -1
+}
+
+// height returns the height of the bar
+func (b *MenuBarWidget) height() float32 {
+	return float32(math.Ceil(float64(b.font.Face(b.size).LineHeight()))) + 2*menuPadding
+}
+
+// setHot updates the title under the cursor, repainting when it changes
+func (b *MenuBarWidget) setHot(index int) {
+	if index != b.hot {
+		b.hot = index
+		b.MarkNeedsPaint()
+	}
+}
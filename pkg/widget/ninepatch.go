@@ -0,0 +1,97 @@
+package widget
+
+import (
+	"github.com/mleku/goo/pkg/render"
+	"lol.mleku.dev/chk"
+)
+
+// NinePatchWidget draws a nine-patch image stretched over its box behind its
+// child, which is laid out inside the image's content padding, for skinned
+// panels and frames
+type NinePatchWidget struct {
+	Base
+	child Widget
+	patch *render.NinePatch
+	tint  [4]float32
+}
+
+// NinePatch creates a new nine-patch widget drawing the image behind the
+// child
+func NinePatch(patch *render.NinePatch, child Widget) *NinePatchWidget {
+	n := &NinePatchWidget{
+		child: child,
+		patch: patch,
+		tint:  [4]float32{1, 1, 1, 1},
+	}
+	adopt(n, child)
+	return n
+}
+
+// Tint sets a color the image pixels are multiplied by and returns the
+// nine-patch for chaining
+func (n *NinePatchWidget) Tint(red, green, blue, alpha float32) *NinePatchWidget {
+	n.tint = [4]float32{red, green, blue, alpha}
+	n.MarkNeedsPaint()
+	return n
+}
+
+// SetPatch replaces the image
+func (n *NinePatchWidget) SetPatch(patch *render.NinePatch) {
+	n.patch = patch
+	n.MarkNeedsLayout()
+}
+
+// GetConstraints returns the child's constraints enlarged by the padding
+func (n *NinePatchWidget) GetConstraints() Constraints {
+	if n.child == nil {
+		return n.insets().grow(NewFlexConstraints(0, 0, 1e9, 1e9))
+	}
+	return n.insets().grow(n.child.GetConstraints())
+}
+
+// Measure returns the size the child measures inside the padding, enlarged
+// by it
+func (n *NinePatchWidget) Measure(constraints Constraints) Size {
+	return n.insets().around(measure(n.child, n.insets().shrink(constraints)))
+}
+
+// Layout implements the Widget interface for NinePatchWidget; the child is
+// laid out inside the padding
+func (n *NinePatchWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !n.NeedsLayout(ctx, constraints) {
+		return n.CachedSize(), nil
+	}
+	if size, err = layoutInset(ctx, n.child, n.insets(), constraints); chk.E(err) {
+		return
+	}
+	n.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for NinePatchWidget
+func (n *NinePatchWidget) Paint(ctx *Context, box *Box) (err error) {
+	if n.patch != nil {
+		ctx.DrawList.NinePatch(n.patch, box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height, n.tint)
+	}
+	if n.child == nil {
+		return
+	}
+	return paintChild(ctx, n.child, n.insets().box(box, n.child.GetConstraints()))
+}
+
+// HandleEvent implements the Widget interface for NinePatchWidget
+func (n *NinePatchWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if n.child == nil {
+		return false
+	}
+	return routeEvent(ctx, n.child, n.insets().box(box, n.child.GetConstraints()), ev)
+}
+
+// insets returns the image's content padding
+func (n *NinePatchWidget) insets() Insets {
+	if n.patch == nil {
+		return Insets{}
+	}
+	p := n.patch.Padding
+	return Insets{Left: p[0], Top: p[1], Right: p[2], Bottom: p[3]}
+}
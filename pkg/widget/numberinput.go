@@ -0,0 +1,503 @@
+package widget
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mleku/goo/pkg/i18n"
+	"github.com/mleku/goo/pkg/icons"
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/text"
+	"lol.mleku.dev/chk"
+)
+
+const (
+	// numberSpinWidth is the width of the column of spin buttons
+	numberSpinWidth = 20
+	// numberRepeatDelay is how long a spin button is held before it repeats,
+	// and numberRepeatInterval how often it steps while repeating
+	numberRepeatDelay    = 400 * time.Millisecond
+	numberRepeatInterval = 60 * time.Millisecond
+	// numberPageSteps is how many steps page up and page down take
+	numberPageSteps = 10
+)
+
+// NumberInputWidget is a text input holding a number, with buttons at its
+// end stepping the number up and down. While it has focus the up and down
+// keys and the scroll wheel step it too, and page up and page down take ten
+// steps. Numbers are read and written with the separators of a locale.
+//
+// Text that is not a number, or not a whole one in integer mode, or that
+// lies outside the range is outlined in the theme's error color and leaves
+// the value unchanged. Pressing enter or moving focus away puts back the
+// last valid value, written out in full.
+type NumberInputWidget struct {
+	Base
+	input    *TextInputWidget
+	locale   *i18n.Locale
+	integer  bool
+	minimum  float64
+	maximum  float64
+	step     float64
+	decimals int
+	value    float64
+	valid    bool
+	onChange func(value float64)
+	// hot is the spin button under the cursor and pressed the one held, 1
+	// for up, -1 for down and 0 for none. A held button repeats when timer
+	// fires.
+	hot, pressed int
+	timer        *timer
+	// focused is whether the input had focus when last painted, to tell
+	// when it loses it
+	focused bool
+	// spin is the column of spin buttons as last painted
+	spin Rect
+}
+
+// NumberInput creates a new number input holding zero, without limits and
+// stepping by one, drawn in the given font at 14 pixels with the English
+// separators
+func NumberInput(font *text.Font) *NumberInputWidget {
+	n := &NumberInputWidget{
+		input:    TextInput(font),
+		locale:   i18n.English(),
+		minimum:  math.Inf(-1),
+		maximum:  math.Inf(1),
+		step:     1,
+		decimals: -1,
+		valid:    true,
+	}
+	n.input.OnChange(n.typed).OnSubmit(func(string) { n.commit() })
+	n.input.keys = n.key
+	n.input.SetText(n.format(0))
+	adopt(n, n.input)
+	return n
+}
+
+// Size sets the pixel size of the text and returns the input for chaining
+func (n *NumberInputWidget) Size(size float32) *NumberInputWidget {
+	n.input.Size(size)
+	n.MarkNeedsLayout()
+	return n
+}
+
+// Integer sets whether only whole numbers are accepted and returns the
+// input for chaining
+func (n *NumberInputWidget) Integer(integer bool) *NumberInputWidget {
+	n.integer = integer
+	n.SetValue(n.value)
+	return n
+}
+
+// Range limits the value to minimum through maximum and returns the input
+// for chaining. Infinite limits leave that end open.
+func (n *NumberInputWidget) Range(minimum, maximum float64) *NumberInputWidget {
+	n.minimum, n.maximum = min(minimum, maximum), max(minimum, maximum)
+	n.SetValue(n.value)
+	return n
+}
+
+// Step sets how much the buttons, keys and wheel change the value and
+// returns the input for chaining
+func (n *NumberInputWidget) Step(step float64) *NumberInputWidget {
+	if step > 0 {
+		n.step = step
+	}
+	return n
+}
+
+// Decimals sets how many decimals the value is written with, or as few as
+// needed when negative, and returns the input for chaining
+func (n *NumberInputWidget) Decimals(decimals int) *NumberInputWidget {
+	n.decimals = decimals
+	n.SetValue(n.value)
+	return n
+}
+
+// Locale sets the locale whose separators numbers are read and written
+// with, and returns the input for chaining
+func (n *NumberInputWidget) Locale(locale *i18n.Locale) *NumberInputWidget {
+	if locale != nil {
+		n.locale = locale
+		n.SetValue(n.value)
+	}
+	return n
+}
+
+// Placeholder sets the hint shown while the input is empty and unfocused
+// and returns the input for chaining
+func (n *NumberInputWidget) Placeholder(s string) *NumberInputWidget {
+	n.input.Placeholder(s)
+	return n
+}
+
+// OnChange sets the callback invoked with the new value when the user
+// changes it and returns the input for chaining
+func (n *NumberInputWidget) OnChange(fn func(value float64)) *NumberInputWidget {
+	n.onChange = fn
+	return n
+}
+
+// SetValue sets the value, limited to the range and rounded in integer
+// mode, and writes it out
+func (n *NumberInputWidget) SetValue(value float64) {
+	n.set(value, false)
+}
+
+// Value returns the value, the last valid one while the text is not
+func (n *NumberInputWidget) Value() float64 {
+	return n.value
+}
+
+// IsValid reports whether the text typed is an accepted number
+func (n *NumberInputWidget) IsValid() bool {
+	return n.valid
+}
+
+// Increment steps the value up by steps, or down for a negative count, as
+// the user would
+func (n *NumberInputWidget) Increment(steps int) {
+	// Rounding to the decimals of the value and step keeps repeated steps
+	// from gathering rounding errors
+	places := max(decimalPlaces(n.value), decimalPlaces(n.step))
+	n.set(roundTo(n.value+float64(steps)*n.step, places), true)
+}
+
+// GetConstraints returns the input's constraints widened by the spin buttons
+func (n *NumberInputWidget) GetConstraints() Constraints {
+	c := n.input.GetConstraints()
+	c.MinWidth += numberSpinWidth
+	return c
+}
+
+// Measure returns the minimum size of the input
+func (n *NumberInputWidget) Measure(constraints Constraints) Size {
+	return minSize(n.GetConstraints())
+}
+
+// Layout implements the Widget interface for NumberInputWidget; number
+// inputs take all the space offered, the spin buttons at its end
+func (n *NumberInputWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !n.NeedsLayout(ctx, constraints) {
+		return n.CachedSize(), nil
+	}
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	if _, err = n.input.Layout(ctx, NewRigidConstraints(max(size.Width-numberSpinWidth, 0), size.Height)); chk.E(err) {
+		return
+	}
+	n.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for NumberInputWidget
+func (n *NumberInputWidget) Paint(ctx *Context, box *Box) (err error) {
+	if focused := hasFocus(n.input); focused != n.focused {
+		n.focused = focused
+		if !focused {
+			n.commit()
+		}
+	}
+	th := themeOf(ctx)
+	x, y, w, h := box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height
+	in := n.inputBox(ctx, box)
+	if err = paintChild(ctx, n.input, in); chk.E(err) {
+		return
+	}
+	if !n.valid {
+		ctx.DrawList.RoundRectStroke(in.Position.X, y, in.Size.Width, h, th.Radius.Small, 1, th.Error)
+	}
+	sx := x + w - numberSpinWidth
+	if ctx.RTL {
+		sx = x
+	}
+
+	n.spin = Rect{X: sx, Y: y, Width: numberSpinWidth, Height: h}
+	fillBordered(ctx, NewBox(sx, y, numberSpinWidth, h, box.Constraints), th.Radius.Small, th.Border, th.Surface)
+	half := h / 2
+	list := ctx.DrawList
+	for _, dir := range []int{1, -1} {
+		by := y
+		name := "chevron-up"
+		if dir < 0 {
+			by, name = y+half, "chevron-down"
+		}
+		switch {
+		case dir == n.pressed:
+			list.RoundRect(sx+1, by+1, numberSpinWidth-2, half-2, th.Radius.Small, th.SurfacePressed)
+		case dir == n.hot:
+			list.RoundRect(sx+1, by+1, numberSpinWidth-2, half-2, th.Radius.Small, th.SurfaceHover)
+		}
+		color := th.Text
+		if !n.canStep(dir) {
+			color = th.TextMuted
+		}
+		size := min(half, numberSpinWidth) - 2
+		icons.Draw(list, name, sx+(numberSpinWidth-size)/2, by+(half-size)/2, size, ctx.Scale, color)
+	}
+	list.Rect(sx+1, float32(math.Round(float64(y+half))), numberSpinWidth-2, 1, th.Border)
+	return
+}
+
+// CursorAt implements CursorProvider, showing the text cursor over the text
+func (n *NumberInputWidget) CursorAt(p Point) Cursor {
+	if n.spin.Contains(p) {
+		return interfaces.CursorDefault
+	}
+	return interfaces.CursorIBeam
+}
+
+// Accessibility implements Accessible, describing the input as a slider
+// stepped up and down
+func (n *NumberInputWidget) Accessibility() AccessNode {
+	node := AccessNode{
+		Role:    interfaces.RoleSlider,
+		Name:    n.input.placeholder,
+		Value:   n.format(n.value),
+		Numeric: n.value,
+		Actions: []AccessAction{interfaces.AccessIncrement, interfaces.AccessDecrement, interfaces.AccessSetValue},
+	}
+	if !math.IsInf(n.minimum, 0) {
+		node.Min = n.minimum
+	}
+	if !math.IsInf(n.maximum, 0) {
+		node.Max = n.maximum
+	}
+	return node
+}
+
+// AccessAction implements AccessActor, stepping or setting the value
+func (n *NumberInputWidget) AccessAction(ctx *Context, action AccessAction, value string) bool {
+	switch action {
+	case interfaces.AccessIncrement:
+		n.Increment(1)
+	case interfaces.AccessDecrement:
+		n.Increment(-1)
+	case interfaces.AccessSetValue:
+		v, ok := n.parse(value)
+		if !ok {
+			return false
+		}
+		n.set(v, true)
+	default:
+		return false
+	}
+	return true
+}
+
+// HandleEvent implements the Widget interface for NumberInputWidget
+func (n *NumberInputWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		n.setHot(n.buttonAt(e.Position))
+	case interfaces.CursorLeaveEvent:
+		n.setHot(0)
+	case interfaces.ScrollEvent:
+		if hasFocus(n.input) && box.Contains(e.Position) && e.Offset.Y != 0 {
+			if e.Offset.Y > 0 {
+				n.Increment(1)
+			} else {
+				n.Increment(-1)
+			}
+			return true
+		}
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft {
+			break
+		}
+		switch e.Action {
+		case interfaces.ActionPress:
+			// Presses are only delivered when the cursor is inside the box
+			if dir := n.buttonAt(e.Position); dir != 0 {
+				requestFocus(n.input)
+				n.pressed = dir
+				n.Increment(dir)
+				n.repeat(frameTime(ctx).Add(numberRepeatDelay))
+				return true
+			}
+		case interfaces.ActionRelease:
+			// Releases are broadcast, so stop repeating wherever released
+			if n.pressed != 0 {
+				n.release()
+				return true
+			}
+		}
+	}
+	return routeEvent(ctx, n.input, n.inputBox(ctx, box), ev)
+}
+
+// inputBox returns the box of the text input, beside the spin buttons
+func (n *NumberInputWidget) inputBox(ctx *Context, box *Box) *Box {
+	x := box.Position.X
+	if ctx.RTL {
+		x += numberSpinWidth
+	}
+	return NewBox(x, box.Position.Y, max(box.Size.Width-numberSpinWidth, 0), box.Size.Height, n.input.GetConstraints())
+}
+
+// key steps the value for the arrow and page keys pressed in the input
+func (n *NumberInputWidget) key(e interfaces.KeyEvent) bool {
+	switch e.Key {
+	case interfaces.KeyUp:
+		n.Increment(1)
+	case interfaces.KeyDown:
+		n.Increment(-1)
+	case interfaces.KeyPageUp:
+		n.Increment(numberPageSteps)
+	case interfaces.KeyPageDown:
+		n.Increment(-numberPageSteps)
+	default:
+		return false
+	}
+	return true
+}
+
+// typed reads the text after each edit, taking it as the value when valid
+func (n *NumberInputWidget) typed(s string) {
+	v, ok := n.parse(s)
+	if ok != n.valid {
+		n.valid = ok
+		n.MarkNeedsPaint()
+	}
+	if ok && v != n.value {
+		n.value = v
+		n.MarkNeedsPaint()
+		if n.onChange != nil {
+			n.onChange(v)
+		}
+	}
+}
+
+// commit writes out the value in full, putting back the last valid value
+// over text that is not
+func (n *NumberInputWidget) commit() {
+	if s := n.format(n.value); s != n.input.Text() {
+		n.input.SetText(s)
+	}
+	if !n.valid {
+		n.valid = true
+		n.MarkNeedsPaint()
+	}
+}
+
+// set limits and rounds a value, shows it, and notifies the change callback
+// when the user changed it
+func (n *NumberInputWidget) set(value float64, user bool) {
+	value = n.round(value)
+	changed := value != n.value
+	n.value = value
+	n.commit()
+	if changed {
+		n.MarkNeedsPaint()
+		if user && n.onChange != nil {
+			n.onChange(value)
+		}
+	}
+}
+
+// round limits a value to the range, rounding it to a whole number in
+// integer mode or to the decimals written when they are set
+func (n *NumberInputWidget) round(value float64) float64 {
+	switch {
+	case math.IsNaN(value):
+		value = 0
+	case n.integer:
+		value = math.Round(value)
+	case n.decimals >= 0:
+		value = roundTo(value, n.decimals)
+	}
+	return min(max(value, n.minimum), n.maximum)
+}
+
+// parse reads typed text, reporting whether it is an accepted value
+func (n *NumberInputWidget) parse(s string) (v float64, ok bool) {
+	v, err := n.locale.ParseNumber(s)
+	switch {
+	case err != nil:
+		return 0, false
+	case n.integer && v != math.Trunc(v):
+		return 0, false
+	}
+	return v, v >= n.minimum && v <= n.maximum
+}
+
+// format writes a value with the locale's separators
+func (n *NumberInputWidget) format(v float64) string {
+	decimals := n.decimals
+	if n.integer {
+		decimals = 0
+	}
+	return n.locale.Number(v, decimals)
+}
+
+// canStep reports whether the value can step in a direction
+func (n *NumberInputWidget) canStep(dir int) bool {
+	if dir > 0 {
+		return n.value < n.maximum
+	}
+	return n.value > n.minimum
+}
+
+// buttonAt returns the spin button under a point, 1 for up, -1 for down and
+// 0 for none
+func (n *NumberInputWidget) buttonAt(p Point) int {
+	switch {
+	case !n.spin.Contains(p):
+		return 0
+	case p.Y < n.spin.Y+n.spin.Height/2:
+		return 1
+	}
+	return -1
+}
+
+// repeat steps the held button again at a time, and then every interval
+// while it is held over it
+func (n *NumberInputWidget) repeat(at time.Time) {
+	r := rootOf(n)
+	if r == nil {
+		return
+	}
+	n.timer = r.schedule(at, func() {
+		n.timer = nil
+		if n.pressed == 0 {
+			return
+		}
+		if n.pressed == n.hot {
+			n.Increment(n.pressed)
+		}
+		n.repeat(at.Add(numberRepeatInterval))
+	})
+}
+
+// release lets go of the held spin button
+func (n *NumberInputWidget) release() {
+	if r := rootOf(n); r != nil {
+		r.cancel(n.timer)
+	}
+	n.timer = nil
+	n.pressed = 0
+	n.MarkNeedsPaint()
+}
+
+// setHot updates the spin button under the cursor, repainting when it
+// changes
+func (n *NumberInputWidget) setHot(dir int) {
+	if dir != n.hot {
+		n.hot = dir
+		n.MarkNeedsPaint()
+	}
+}
+
+// decimalPlaces returns how many decimals a number is written with in full
+func decimalPlaces(v float64) int {
+	_, fraction, _ := strings.Cut(strconv.FormatFloat(v, 'f', -1, 64), ".")
+	return len(fraction)
+}
+
+// roundTo rounds a number to a count of decimals
+func roundTo(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
@@ -0,0 +1,101 @@
+package widget
+
+import (
+	"github.com/mleku/goo/pkg/state"
+	"lol.mleku.dev/chk"
+)
+
+// ObserveWidget shows a child built from observable state, building it again
+// when any of the state changes. Changes are collected until the next frame,
+// so several changes together build the child once. The rebuilt child
+// replaces the old one, losing any focus or scroll position inside it.
+type ObserveWidget struct {
+	Base
+	build   func() Widget
+	child   Widget
+	stale   bool
+	cancels []func()
+}
+
+// Observe creates a new widget showing the child returned by build, which
+// reads the sources, and rebuilding it after any of them changes
+func Observe(build func() Widget, sources ...state.Observable) *ObserveWidget {
+	o := &ObserveWidget{build: build, stale: true}
+	for _, source := range sources {
+		o.cancels = append(o.cancels, source.Subscribe(o.changed))
+	}
+	o.current()
+	return o
+}
+
+// Close stops following the sources, leaving the child as last built
+func (o *ObserveWidget) Close() {
+	for _, cancel := range o.cancels {
+		cancel()
+	}
+	o.cancels = nil
+}
+
+// Child returns the child as last built
+func (o *ObserveWidget) Child() Widget {
+	return o.current()
+}
+
+// GetConstraints returns the child's constraints
+func (o *ObserveWidget) GetConstraints() Constraints {
+	if child := o.current(); child != nil {
+		return child.GetConstraints()
+	}
+	return NewFlexConstraints(0, 0, 1e9, 1e9)
+}
+
+// Measure returns the size the current child measures
+func (o *ObserveWidget) Measure(constraints Constraints) Size {
+	return measure(o.current(), constraints)
+}
+
+// Layout implements the Widget interface for ObserveWidget; the child is laid
+// out in the widget's box
+func (o *ObserveWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	child := o.current()
+	if !o.NeedsLayout(ctx, constraints) {
+		return o.CachedSize(), nil
+	}
+	if size, err = layoutInset(ctx, child, Insets{}, constraints); chk.E(err) {
+		return
+	}
+	o.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for ObserveWidget
+func (o *ObserveWidget) Paint(ctx *Context, box *Box) (err error) {
+	if o.child == nil {
+		return
+	}
+	return paintChild(ctx, o.child, box)
+}
+
+// HandleEvent implements the Widget interface for ObserveWidget
+func (o *ObserveWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if o.child == nil {
+		return false
+	}
+	return routeEvent(ctx, o.child, box, ev)
+}
+
+// changed marks the child for rebuilding after a source changed
+func (o *ObserveWidget) changed() {
+	o.stale = true
+	o.MarkNeedsLayout()
+}
+
+// current returns the child, building it first if a source changed
+func (o *ObserveWidget) current() Widget {
+	if o.stale {
+		o.stale = false
+		o.child = o.build()
+		adopt(o, o.child)
+	}
+	return o.child
+}
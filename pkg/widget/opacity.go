@@ -0,0 +1,97 @@
+package widget
+
+import (
+	"time"
+
+	"github.com/mleku/goo/pkg/anim"
+	"lol.mleku.dev/chk"
+)
+
+// OpacityWidget draws its child partly transparent, for dimming disabled
+// parts of a window and fading content in and out. Fully transparent
+// children receive no pointer input.
+type OpacityWidget struct {
+	Base
+	child Widget
+	alpha *anim.Tween[float32]
+}
+
+// Opacity creates a new opacity widget drawing the child with an opacity
+// from 0, invisible, to 1, opaque
+func Opacity(child Widget, alpha float32) *OpacityWidget {
+	o := &OpacityWidget{
+		child: child,
+		alpha: anim.NewFloat(alpha),
+	}
+	adopt(o, child)
+	return o
+}
+
+// SetOpacity changes the opacity of the child at once
+func (o *OpacityWidget) SetOpacity(alpha float32) {
+	o.alpha.Set(alpha)
+	o.MarkNeedsPaint()
+}
+
+// FadeTo changes the opacity of the child over the duration and returns the
+// widget for chaining
+func (o *OpacityWidget) FadeTo(alpha float32, duration time.Duration, ease anim.Easing) *OpacityWidget {
+	o.alpha.To(alpha, duration, ease)
+	o.MarkNeedsPaint()
+	return o
+}
+
+// Opacity returns the opacity the child is drawn with, or is fading to
+func (o *OpacityWidget) Opacity() float32 {
+	return o.alpha.Target()
+}
+
+// GetConstraints returns the child's constraints
+func (o *OpacityWidget) GetConstraints() Constraints {
+	if o.child == nil {
+		return NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return o.child.GetConstraints()
+}
+
+// Measure returns the size the child measures
+func (o *OpacityWidget) Measure(constraints Constraints) Size {
+	return measure(o.child, constraints)
+}
+
+// Layout implements the Widget interface for OpacityWidget; the child is laid
+// out in the widget's box
+func (o *OpacityWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !o.NeedsLayout(ctx, constraints) {
+		return o.CachedSize(), nil
+	}
+	if size, err = layoutInset(ctx, o.child, Insets{}, constraints); chk.E(err) {
+		return
+	}
+	o.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for OpacityWidget
+func (o *OpacityWidget) Paint(ctx *Context, box *Box) (err error) {
+	alpha := o.alpha.Value(ctx.Clock)
+	if o.alpha.Running() {
+		o.MarkNeedsPaint()
+	}
+	if o.child == nil || alpha <= 0 {
+		return
+	}
+	list := ctx.DrawList
+	list.PushAlpha(alpha)
+	err = paintChild(ctx, o.child, box)
+	list.PopAlpha()
+	return
+}
+
+// HandleEvent implements the Widget interface for OpacityWidget
+func (o *OpacityWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if o.child == nil || o.alpha.Target() <= 0 {
+		return false
+	}
+	return routeEvent(ctx, o.child, box, ev)
+}
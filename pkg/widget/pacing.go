@@ -0,0 +1,287 @@
+package widget
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mleku/goo/pkg/interfaces"
+)
+
+const (
+	// pacingDeadline is the time a frame has when the display's refresh
+	// rate is unknown
+	pacingDeadline = time.Second / 60
+	// jankLogSize is the number of missed frames the jank log keeps
+	jankLogSize = 64
+	// jankShown is the number of missed frames the debug overlay lists
+	jankShown = 3
+)
+
+// JankPhase is a part of drawing a frame
+type JankPhase int
+
+const (
+	// JankEvents is handling the events that arrived since the last frame
+	JankEvents JankPhase = iota
+	// JankLayout is laying the widgets out, running their timers included
+	JankLayout
+	// JankPaint is painting the damaged regions and the decorations
+	JankPaint
+	// JankPresent is what the window did with the frame after the widgets
+	// painted it, such as rasterizing and presenting it
+	JankPresent
+)
+
+// String returns the phase's name
+func (p JankPhase) String() string {
+	switch p {
+	case JankEvents:
+		return "events"
+	case JankLayout:
+		return "layout"
+	case JankPaint:
+		return "paint"
+	case JankPresent:
+		return "present"
+	}
+	return "unknown"
+}
+
+// Jank is a frame that missed its deadline, showing late
+type Jank struct {
+	// At is the time of the frame
+	At time.Time
+	// Took is how long the frame took, and Deadline the time it had, one
+	// refresh of the display
+	Took, Deadline time.Duration
+	// Phase is the part of the frame that took longest, and Events, Layout,
+	// Paint and Present how long each took
+	Phase                          JankPhase
+	Events, Layout, Paint, Present time.Duration
+	// Widget is what the phase's time went to: the widget under the pointer
+	// or with the keyboard focus for the slowest event, or the widget
+	// slowest to paint itself. It is nil for layout and presenting, or when
+	// unknown.
+	Widget Widget
+	// Event is the slowest event when the phase is JankEvents
+	Event Event
+}
+
+// String describes the missed frame, such as "paint LabelWidget 24.1ms of
+// 16.7ms"
+func (j Jank) String() string {
+	what := j.Phase.String()
+	switch {
+	case j.Widget != nil:
+		what += " " + typeName(j.Widget)
+	case j.Event != nil:
+		what += " " + typeName(j.Event)
+	}
+	return fmt.Sprintf("%s %s of %s", what, debugMillis(j.Took), debugMillis(j.Deadline))
+}
+
+// pacingMonitor is the state of a root's frame pacing monitor
+type pacingMonitor struct {
+	// phases is how long each phase of the frame being drawn has taken so
+	// far, events counting those dispatched since the last frame
+	phases [JankPresent]time.Duration
+	// slowEvent is the slowest of those events, taking slowTime, and
+	// slowTarget the widget it went to
+	slowEvent  Event
+	slowTime   time.Duration
+	slowTarget Widget
+	// work is how long the widgets took with the last frame, at its time,
+	// and late whether it was logged for it, so the window taking too long
+	// with a frame the widgets were quick with is told apart
+	work time.Duration
+	at   time.Time
+	late bool
+	log  []Jank
+	// onJank is called with each missed frame as it is logged
+	onJank func(j Jank)
+}
+
+// MonitorPacing turns the frame pacing monitor on or off and returns the
+// root for chaining. While it is on, the root times the events, layout and
+// paint of each frame, and logs the frames that miss their deadline, one
+// refresh of the display, with the phase and widget that took the time.
+// The log is read with JankLog, and the debug overlay shows its latest
+// entries. Painting is timed for each widget while the monitor is on.
+func (r *RootWidget) MonitorPacing(on bool) *RootWidget {
+	switch {
+	case on && r.pacing == nil:
+		r.pacing = &pacingMonitor{}
+	case !on:
+		r.pacing = nil
+	}
+	return r
+}
+
+// OnJank sets a callback for each frame the pacing monitor logs as missing
+// its deadline and returns the root for chaining
+func (r *RootWidget) OnJank(fn func(j Jank)) *RootWidget {
+	if r.pacing == nil {
+		r.MonitorPacing(true)
+	}
+	r.pacing.onJank = fn
+	return r
+}
+
+// JankLog returns the latest frames that missed their deadline, oldest
+// first, empty when the pacing monitor is off
+func (r *RootWidget) JankLog() []Jank {
+	if r.pacing == nil {
+		return nil
+	}
+	return slices.Clone(r.pacing.log)
+}
+
+// ClearJankLog empties the jank log
+func (r *RootWidget) ClearJankLog() {
+	if r.pacing != nil {
+		r.pacing.log = nil
+	}
+}
+
+// paced adds the time since start to a phase of the frame being drawn,
+// returning the time now for timing the next phase
+func (r *RootWidget) paced(phase JankPhase, start time.Time) (now time.Time) {
+	if r.pacing == nil {
+		return
+	}
+	now = time.Now()
+	r.pacing.phases[phase] += now.Sub(start)
+	return
+}
+
+// pacedEvent handles an event, timing it when the pacing monitor is on
+func (r *RootWidget) pacedEvent(ctx *Context, box *Box, ev Event) {
+	p := r.pacing
+	if p == nil {
+		r.HandleEvent(ctx, box, ev)
+		return
+	}
+	start := time.Now()
+	r.HandleEvent(ctx, box, ev)
+	took := time.Since(start)
+	p.phases[JankEvents] += took
+	if took > p.slowTime {
+		p.slowEvent, p.slowTime = ev, took
+		switch ev.(type) {
+		case interfaces.KeyEvent, interfaces.CharEvent:
+			p.slowTarget = r.focused
+		default:
+			// Pointer events go to the widget under the pointer
+			p.slowTarget = nil
+			if at, targeted := interfaces.Target(ev); targeted {
+				p.slowTarget = r.HitTest(at)
+			} else if r.pointerIn {
+				p.slowTarget = r.HitTest(r.pointer)
+			}
+		}
+	}
+}
+
+// beginPacing logs the last frame as missed when the window took too long
+// with it after the widgets were done in time
+func (r *RootWidget) beginPacing(ctx *Context) {
+	p := r.pacing
+	if p == nil || ctx.Stats == nil || p.at.IsZero() {
+		return
+	}
+	took, deadline := ctx.Stats.FrameTime, pacingDeadlineOf(ctx)
+	if !p.late && took > deadline && took > p.work {
+		r.logJank(Jank{
+			At:       p.at,
+			Took:     took,
+			Deadline: deadline,
+			Phase:    JankPresent,
+			Present:  took - p.work,
+		})
+	}
+	p.at = time.Time{}
+}
+
+// endPacing logs the frame drawn as missed when the widgets took longer
+// than its deadline, and starts timing the next
+func (r *RootWidget) endPacing(ctx *Context) {
+	p := r.pacing
+	if p == nil {
+		return
+	}
+	var work time.Duration
+	phase := JankEvents
+	for i, t := range p.phases {
+		work += t
+		if t > p.phases[phase] {
+			phase = JankPhase(i)
+		}
+	}
+	p.work, p.at = work, frameTime(ctx)
+	deadline := pacingDeadlineOf(ctx)
+	if p.late = work > deadline; p.late {
+		j := Jank{
+			At:       p.at,
+			Took:     work,
+			Deadline: deadline,
+			Phase:    phase,
+			Events:   p.phases[JankEvents],
+			Layout:   p.phases[JankLayout],
+			Paint:    p.phases[JankPaint],
+		}
+		switch phase {
+		case JankEvents:
+			j.Widget, j.Event = p.slowTarget, p.slowEvent
+		case JankPaint:
+			j.Widget = r.slowestPaint()
+		}
+		r.logJank(j)
+	}
+	p.phases = [JankPresent]time.Duration{}
+	p.slowEvent, p.slowTime, p.slowTarget = nil, 0, nil
+}
+
+// logJank adds a missed frame to the jank log
+func (r *RootWidget) logJank(j Jank) {
+	p := r.pacing
+	if len(p.log) == jankLogSize {
+		p.log = slices.Delete(p.log, 0, 1)
+	}
+	p.log = append(p.log, j)
+	if p.onJank != nil {
+		p.onJank(j)
+	}
+}
+
+// slowestPaint returns the widget that took longest to paint itself in the
+// last frame, nil if none painted
+func (r *RootWidget) slowestPaint() (w Widget) {
+	var slowest time.Duration
+	for _, s := range r.paintCost.Samples() {
+		if s.Self > slowest {
+			if sw, ok := s.Target.(Widget); ok {
+				w, slowest = sw, s.Self
+			}
+		}
+	}
+	return
+}
+
+// pacingDeadlineOf returns the time a frame has, one refresh of the display
+func pacingDeadlineOf(ctx *Context) time.Duration {
+	if s := ctx.Stats; s != nil && s.Refresh > 0 {
+		return s.Refresh
+	}
+	return pacingDeadline
+}
+
+// jankLines returns the lines the debug overlay shows about the frames
+// missing their deadline: how many did and the latest of them
+func (p *pacingMonitor) jankLines() (lines []string) {
+	lines = append(lines, fmt.Sprintf("%d late frames", len(p.log)))
+	for i := len(p.log) - 1; i >= max(len(p.log)-jankShown, 0); i-- {
+		lines = append(lines, p.log[i].String())
+	}
+	return
+}
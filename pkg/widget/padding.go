@@ -0,0 +1,166 @@
+package widget
+
+import (
+	"lol.mleku.dev/chk"
+)
+
+// Insets are distances inwards from each edge of a box
+type Insets struct {
+	Top, Right, Bottom, Left float32
+}
+
+// UniformInsets returns insets of the same distance from every edge
+func UniformInsets(d float32) Insets {
+	return Insets{Top: d, Right: d, Bottom: d, Left: d}
+}
+
+// SymmetricInsets returns insets of vertical distance from the top and
+// bottom edges and horizontal distance from the left and right edges
+func SymmetricInsets(vertical, horizontal float32) Insets {
+	return Insets{Top: vertical, Right: horizontal, Bottom: vertical, Left: horizontal}
+}
+
+// Horizontal returns the sum of the left and right insets
+func (in Insets) Horizontal() float32 {
+	return in.Left + in.Right
+}
+
+// Vertical returns the sum of the top and bottom insets
+func (in Insets) Vertical() float32 {
+	return in.Top + in.Bottom
+}
+
+// grow returns constraints enlarged by the insets, for a widget around a
+// child with the given constraints. Unbounded maximums stay unbounded.
+func (in Insets) grow(c Constraints) Constraints {
+	maxWidth, maxHeight := c.MaxWidth, c.MaxHeight
+	if maxWidth < 1e9 {
+		maxWidth += in.Horizontal()
+	}
+	if maxHeight < 1e9 {
+		maxHeight += in.Vertical()
+	}
+	return NewFlexConstraints(c.MinWidth+in.Horizontal(), c.MinHeight+in.Vertical(), maxWidth, maxHeight)
+}
+
+// around returns a size enlarged by the insets, for a widget around a child
+// of the given size
+func (in Insets) around(s Size) Size {
+	return Size{Width: s.Width + in.Horizontal(), Height: s.Height + in.Vertical()}
+}
+
+// shrink returns constraints reduced by the insets, for the child of a
+// widget laid out with the given constraints
+func (in Insets) shrink(c Constraints) Constraints {
+	return NewFlexConstraints(
+		max(c.MinWidth-in.Horizontal(), 0),
+		max(c.MinHeight-in.Vertical(), 0),
+		max(c.MaxWidth-in.Horizontal(), 0),
+		max(c.MaxHeight-in.Vertical(), 0),
+	)
+}
+
+// box returns the part of the box inside the insets, holding a child with the
+// given constraints
+func (in Insets) box(box *Box, c Constraints) *Box {
+	return NewBox(
+		box.Position.X+in.Left,
+		box.Position.Y+in.Top,
+		max(box.Size.Width-in.Horizontal(), 0),
+		max(box.Size.Height-in.Vertical(), 0),
+		c,
+	)
+}
+
+// PaddingWidget leaves space between its edges and its child. It takes the
+// child's constraints enlarged by the space, so it never squeezes the child
+// below its minimum size.
+type PaddingWidget struct {
+	Base
+	child  Widget
+	insets Insets
+}
+
+// Padding creates a new padding widget leaving the insets around the child
+func Padding(child Widget, insets Insets) *PaddingWidget {
+	p := &PaddingWidget{child: child, insets: insets}
+	adopt(p, child)
+	return p
+}
+
+// Margin creates a new padding widget leaving the insets around the child.
+// It lays out exactly like Padding; the name reads better for space kept
+// outside a decorated widget, as in Margin(Border(Padding(child, ...), ...), ...).
+func Margin(child Widget, insets Insets) *PaddingWidget {
+	return Padding(child, insets)
+}
+
+// SetInsets replaces the space left around the child
+func (p *PaddingWidget) SetInsets(insets Insets) {
+	p.insets = insets
+	p.MarkNeedsLayout()
+}
+
+// Insets returns the space left around the child
+func (p *PaddingWidget) Insets() Insets {
+	return p.insets
+}
+
+// GetConstraints returns the child's constraints enlarged by the insets
+func (p *PaddingWidget) GetConstraints() Constraints {
+	if p.child == nil {
+		return p.insets.grow(NewFlexConstraints(0, 0, 1e9, 1e9))
+	}
+	return p.insets.grow(p.child.GetConstraints())
+}
+
+// Measure returns the size the child measures inside the insets, enlarged
+// by them
+func (p *PaddingWidget) Measure(constraints Constraints) Size {
+	return p.insets.around(measure(p.child, p.insets.shrink(constraints)))
+}
+
+// Layout implements the Widget interface for PaddingWidget; the child is
+// laid out in the space inside the insets
+func (p *PaddingWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !p.NeedsLayout(ctx, constraints) {
+		return p.CachedSize(), nil
+	}
+	if size, err = layoutInset(ctx, p.child, p.insets, constraints); chk.E(err) {
+		return
+	}
+	p.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for PaddingWidget
+func (p *PaddingWidget) Paint(ctx *Context, box *Box) (err error) {
+	if p.child == nil {
+		return
+	}
+	return paintChild(ctx, p.child, p.insets.box(box, p.child.GetConstraints()))
+}
+
+// HandleEvent implements the Widget interface for PaddingWidget
+func (p *PaddingWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if p.child == nil {
+		return false
+	}
+	return routeEvent(ctx, p.child, p.insets.box(box, p.child.GetConstraints()), ev)
+}
+
+// layoutInset lays out a child inside insets, returning the size of the
+// widget around it: the child's size enlarged by the insets, or all the space
+// offered without a child
+func layoutInset(ctx *Context, child Widget, insets Insets, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	if child == nil {
+		return
+	}
+	if size, err = child.Layout(ctx, insets.shrink(constraints)); chk.E(err) {
+		return
+	}
+	size.Width += insets.Horizontal()
+	size.Height += insets.Vertical()
+	return
+}
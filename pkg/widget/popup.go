@@ -0,0 +1,388 @@
+package widget
+
+import (
+	"math"
+	"slices"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"lol.mleku.dev/chk"
+)
+
+// Placement specifies where a popup is shown relative to its anchor widget
+type Placement int
+
+const (
+	// PlaceBelow shows the popup under the anchor, aligned to its left edge
+	PlaceBelow Placement = iota
+	// PlaceAbove shows the popup over the anchor, aligned to its left edge
+	PlaceAbove
+	// PlaceRight shows the popup beside the anchor's right edge, aligned to its top
+	PlaceRight
+	// PlaceLeft shows the popup beside the anchor's left edge, aligned to its top
+	PlaceLeft
+)
+
+// Popup is a transient surface such as a menu, tooltip or dialog that a
+// RootWidget draws above its tree. Popups are anchored to a widget, placed
+// at a point or centered, stack in the order they were shown, and close when
+// the user presses outside them or presses escape unless they are persistent.
+type Popup struct {
+	content Widget
+	// anchor is the widget the popup is placed against, nil to place it at offset
+	anchor    Widget
+	placement Placement
+	// offset is the window position without an anchor, or the distance from
+	// the placed position with one
+	offset   Point
+	centered bool
+	// size is the popup's size, the content's minimum size when zero
+	size        Size
+	persistent  bool
+	passThrough bool
+	modal       bool
+	onClose     func()
+	// root shows the popup in box while open
+	root *RootWidget
+	box  Box
+	// restore is the widget that had focus when a modal popup opened
+	restore Widget
+	// notice is set for popups such as toasts that stay up while the user
+	// works below them, so escape passes them by for the popups below
+	notice bool
+	// placer places popups that keep their own place, such as internal
+	// windows, in place of the anchor, offset and size
+	placer func(canvas Size) Box
+}
+
+// NewPopup creates a new popup showing the content at the top left of the window
+func NewPopup(content Widget) *Popup {
+	return &Popup{content: content}
+}
+
+// Anchor places the popup against a widget, on the side given by placement,
+// and returns the popup for chaining. The popup follows the widget when it
+// moves and flips to the opposite side when it does not fit the window.
+func (p *Popup) Anchor(w Widget, placement Placement) *Popup {
+	p.anchor, p.placement, p.centered = w, placement, false
+	return p
+}
+
+// Center places the popup in the middle of the window and returns the popup for chaining
+func (p *Popup) Center() *Popup {
+	p.anchor, p.centered = nil, true
+	return p
+}
+
+// At places the popup at a window position, or offsets it from its anchored
+// or centered position, and returns the popup for chaining
+func (p *Popup) At(x, y float32) *Popup {
+	p.offset = Point{X: x, Y: y}
+	return p
+}
+
+// Size sets the popup's size and returns the popup for chaining. Popups
+// without a size take the minimum size of their content.
+func (p *Popup) Size(width, height float32) *Popup {
+	p.size = Size{Width: width, Height: height}
+	return p
+}
+
+// Persistent sets whether the popup stays open when the user presses
+// outside it or presses escape, and returns the popup for chaining
+func (p *Popup) Persistent(persistent bool) *Popup {
+	p.persistent = persistent
+	return p
+}
+
+// PassThrough sets whether input passes through the popup to whatever lies
+// below it, as for tooltips, and returns the popup for chaining. Such popups
+// never receive input and are not closed by presses outside them.
+func (p *Popup) PassThrough(passThrough bool) *Popup {
+	p.passThrough = passThrough
+	return p
+}
+
+// Modal sets whether the popup blocks the rest of the window and returns the
+// popup for chaining. A modal popup dims everything below it with the
+// theme's backdrop, keeps input from reaching it, and keeps keyboard focus
+// inside itself until it closes. Presses outside it do not close it.
+func (p *Popup) Modal(modal bool) *Popup {
+	p.modal = modal
+	return p
+}
+
+// OnClose sets the callback invoked when the popup closes, whether by
+// Close or by a press outside it, and returns the popup for chaining
+func (p *Popup) OnClose(fn func()) *Popup {
+	p.onClose = fn
+	return p
+}
+
+// Content returns the widget the popup shows
+func (p *Popup) Content() Widget {
+	return p.content
+}
+
+// IsOpen reports whether the popup is showing
+func (p *Popup) IsOpen() bool {
+	return p.root != nil
+}
+
+// Box returns the absolute box the popup was last placed in
+func (p *Popup) Box() Box {
+	return p.box
+}
+
+// Close hides the popup and invokes its close callback. Closing a popup
+// that is not open does nothing.
+func (p *Popup) Close() {
+	r := p.root
+	if r == nil {
+		return
+	}
+	r.popups = slices.DeleteFunc(r.popups, func(o *Popup) bool { return o == p })
+	r.Invalidate(p.bounds(&p.box))
+	p.root = nil
+	if p.modal {
+		// The backdrop covered the whole window
+		r.InvalidateAll()
+		r.SetFocus(p.restore)
+		p.restore = nil
+	}
+	if p.onClose != nil {
+		p.onClose()
+	}
+}
+
+// place computes the popup's box within the canvas
+func (p *Popup) place(canvas Size) Box {
+	if p.placer != nil {
+		return p.placer(canvas)
+	}
+	size := p.size
+	if size == (Size{}) {
+		c := p.content.GetConstraints()
+		size = Size{Width: c.MinWidth, Height: c.MinHeight}
+	}
+	pos := p.offset
+	switch {
+	case p.anchor != nil:
+		pos = p.anchored(size, canvas)
+	case p.centered:
+		pos.X += float32(math.Round(float64(canvas.Width-size.Width) / 2))
+		pos.Y += float32(math.Round(float64(canvas.Height-size.Height) / 2))
+	}
+	// Keep the popup inside the window where it fits
+	pos.X = max(min(pos.X, canvas.Width-size.Width), 0)
+	pos.Y = max(min(pos.Y, canvas.Height-size.Height), 0)
+	return *NewBox(pos.X, pos.Y, size.Width, size.Height, p.content.GetConstraints())
+}
+
+// anchored returns the popup's position beside its anchor, flipped to the
+// opposite side when it overflows the window and there is more room there
+func (p *Popup) anchored(size, canvas Size) (pos Point) {
+	var a Box
+	if t, ok := p.anchor.(paintTracker); ok {
+		a = *t.lastPaintBox()
+	}
+	left, top := a.Position.X, a.Position.Y
+	right, bottom := left+a.Size.Width, top+a.Size.Height
+	switch p.placement {
+	case PlaceBelow, PlaceAbove:
+		below, above := canvas.Height-bottom, top
+		pos = Point{X: left, Y: bottom}
+		if p.placement == PlaceAbove && (above >= size.Height || above >= below) ||
+			p.placement == PlaceBelow && below < size.Height && above > below {
+			pos.Y = top - size.Height
+		}
+	case PlaceRight, PlaceLeft:
+		after, before := canvas.Width-right, left
+		pos = Point{X: right, Y: top}
+		if p.placement == PlaceLeft && (before >= size.Width || before >= after) ||
+			p.placement == PlaceRight && after < size.Width && before > after {
+			pos.X = left - size.Width
+		}
+	}
+	pos.X += p.offset.X
+	pos.Y += p.offset.Y
+	return
+}
+
+// RootOf returns the root of the tree containing the widget, nil when the
+// widget is not attached to a root. Popup content belongs to the root that
+// shows it.
+func RootOf(w Widget) *RootWidget {
+	return rootOf(w)
+}
+
+// ShowPopup shows a popup above the tree and all popups already open. A
+// popup that is already open is raised to the top.
+func (r *RootWidget) ShowPopup(p *Popup) {
+	if p.root != nil && p.root != r {
+		p.Close()
+	}
+	if p.root == r {
+		r.popups = slices.DeleteFunc(r.popups, func(o *Popup) bool { return o == p })
+	}
+	if p.modal && p.root == nil {
+		// Move focus into the popup, where its content may take it
+		p.restore = r.focused
+		r.SetFocus(nil)
+		r.InvalidateAll()
+	}
+	p.root = r
+	p.content.SetParent(r)
+	p.box = p.place(r.CachedSize())
+	r.popups = append(r.popups, p)
+	r.Invalidate(p.bounds(&p.box))
+}
+
+// bounds returns the region the popup paints in when placed in a box,
+// including any shadow its content casts outside it
+func (p *Popup) bounds(box *Box) Rect {
+	if o, ok := p.content.(overflowing); ok {
+		return o.paintBounds(box)
+	}
+	return box.Rect()
+}
+
+// modal returns the index of the topmost modal popup, -1 if none
+func (r *RootWidget) modal() int {
+	for i := len(r.popups) - 1; i >= 0; i-- {
+		if r.popups[i].modal {
+			return i
+		}
+	}
+	return -1
+}
+
+// escapePopup closes the topmost popup that input reaches, unless it is
+// persistent, reporting whether one closed
+func (r *RootWidget) escapePopup() bool {
+	for i := len(r.popups) - 1; i >= 0; i-- {
+		p := r.popups[i]
+		if p.passThrough || p.notice {
+			continue
+		}
+		if p.persistent {
+			return false
+		}
+		p.Close()
+		return true
+	}
+	return false
+}
+
+// Popups returns the open popups from bottom to top
+func (r *RootWidget) Popups() []*Popup {
+	return slices.Clone(r.popups)
+}
+
+// ClosePopups closes every open popup, top first
+func (r *RootWidget) ClosePopups() {
+	for len(r.popups) > 0 {
+		r.popups[len(r.popups)-1].Close()
+	}
+}
+
+// layoutPopups places the open popups, which follow their anchors, and lays
+// out their content to fill them
+func (r *RootWidget) layoutPopups(ctx *Context) (err error) {
+	canvas := r.CachedSize()
+	for _, p := range r.popups {
+		if box := p.place(canvas); box != p.box {
+			r.Invalidate(p.bounds(&p.box))
+			r.Invalidate(p.bounds(&box))
+			p.box = box
+		}
+		box := &p.box
+		if _, err = p.content.Layout(childContext(ctx, box), NewRigidConstraints(box.Size.Width, box.Size.Height)); chk.E(err) {
+			return
+		}
+	}
+	return
+}
+
+// paintPopups paints the open popups from bottom to top, dimming what lies
+// below each modal popup
+func (r *RootWidget) paintPopups(ctx *Context) (err error) {
+	canvas := r.CachedSize()
+	defer ctx.Hits.SetLayer(0)
+	for i, p := range r.popups {
+		ctx.Hits.SetLayer(i + 1)
+		if p.modal {
+			ctx.DrawList.Rect(0, 0, canvas.Width, canvas.Height, themeOf(ctx).Backdrop)
+			// The backdrop hides everything below from hit testing
+			ctx.Hits.Register(p, Rect{Width: canvas.Width, Height: canvas.Height})
+		}
+		if err = paintChild(ctx, p.content, &p.box); chk.E(err) {
+			return
+		}
+	}
+	return
+}
+
+// popupEvent gives the open popups first sight of an event. A press or
+// scroll goes only to the topmost popup under the cursor, closing the
+// popups above it. One outside every popup closes those that are not
+// persistent, and is kept from the tree if any closed. Other pointer events
+// are shared with the tree and keyboard input is left to the focus owner.
+// Nothing below the topmost modal popup receives input, and pass through
+// popups are skipped. done reports whether the event should not be
+// delivered to the tree.
+func (r *RootWidget) popupEvent(ctx *Context, ev Event) (handled, done bool) {
+	switch ev.(type) {
+	case interfaces.KeyEvent, interfaces.CharEvent, interfaces.PreeditEvent, interfaces.GamepadEvent:
+		return false, false
+	}
+	modal := r.modal()
+	at, targeted := interfaces.Target(ev)
+	if !targeted {
+		for i := len(r.popups) - 1; i >= max(modal, 0); i-- {
+			// Handling the event may close popups
+			if i >= len(r.popups) {
+				continue
+			}
+			p := r.popups[i]
+			if p.passThrough {
+				continue
+			}
+			if routeEvent(ctx, p.content, &p.box, ev) {
+				handled = true
+			}
+		}
+		return handled, modal >= 0
+	}
+	hit := -1
+	for i := len(r.popups) - 1; i >= max(modal, 0); i-- {
+		if p := r.popups[i]; !p.passThrough && p.box.Contains(at) {
+			hit = i
+			break
+		}
+	}
+	if hit < 0 {
+		dismissed := r.dismissAbove(modal)
+		return dismissed, dismissed || modal >= 0
+	}
+	p := r.popups[hit]
+	r.dismissAbove(hit)
+	if !p.IsOpen() {
+		return true, true
+	}
+	return routeEvent(ctx, p.content, &p.box, ev), true
+}
+
+// dismissAbove closes the popups that are neither persistent nor pass
+// through above the one at index, all of them for -1, reporting whether
+// any closed
+func (r *RootWidget) dismissAbove(index int) (dismissed bool) {
+	for i := len(r.popups) - 1; i > index; i-- {
+		// Closing a popup may close others from its callback
+		if i >= len(r.popups) || r.popups[i].persistent || r.popups[i].passThrough {
+			continue
+		}
+		r.popups[i].Close()
+		dismissed = true
+	}
+	return
+}
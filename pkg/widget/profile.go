@@ -0,0 +1,322 @@
+package widget
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/render"
+)
+
+const (
+	// heatCell is the size of the overdraw heatmap's cells
+	heatCell = 8
+	// profileTop is the number of costliest widgets the debug overlay lists
+	profileTop = 3
+)
+
+// heatColors tint the cells of the overdraw heatmap drawn over once, twice,
+// three times and four or more times
+var heatColors = [][4]float32{
+	{0.2, 0.4, 1, 0.3},
+	{0.2, 0.9, 0.3, 0.3},
+	{1, 0.4, 0.8, 0.35},
+	{1, 0.15, 0.15, 0.4},
+}
+
+// ProfileReport describes what painting the last frame cost while the root
+// was profiling
+type ProfileReport struct {
+	// Widgets holds what each widget painted cost, the costliest first
+	Widgets []WidgetProfile
+	// Regions is the number of damaged regions repainted, and Time how long
+	// painting them took
+	Regions int
+	Time    time.Duration
+	// Commands and Vertices count the draw commands and vertices the frame
+	// added, not counting the debug overlay
+	Commands, Vertices int
+	// Scissors is the number of times the clip rect changes between the
+	// frame's draw commands
+	Scissors int
+	// Overdraw is how many times each part of the window was drawn
+	Overdraw Heatmap
+}
+
+// WidgetProfile is what a widget cost to paint over a frame
+type WidgetProfile struct {
+	Widget Widget `json:"-"`
+	// Type is the name of the widget's type, such as LabelWidget
+	Type string
+	// Rect is the box it was last painted in
+	Rect Rect
+	// Paints is how many times it painted, once for each damaged region it
+	// lay in
+	Paints int
+	// Time is how long it took to paint including its descendants, and Self
+	// that time without them
+	Time, Self time.Duration
+	// Commands and Vertices are the draw commands and vertices it added
+	// itself
+	Commands, Vertices int
+}
+
+// Heatmap counts how many times each cell of a grid over the window was
+// drawn, the clear to the background included, so a cell drawn once more
+// than cleared is 2
+type Heatmap struct {
+	// Cell is the width and height of each cell, and Columns and Rows the
+	// size of the grid
+	Cell          float32
+	Columns, Rows int
+	// Layers holds the average times each cell's pixels were drawn, row by
+	// row from the top left
+	Layers []float32
+}
+
+// At returns the times the pixels of the cell holding a point were drawn
+func (h Heatmap) At(p Point) float32 {
+	if h.Cell <= 0 || p.X < 0 || p.Y < 0 {
+		return 0
+	}
+	col, row := int(p.X/h.Cell), int(p.Y/h.Cell)
+	if col >= h.Columns || row >= h.Rows {
+		return 0
+	}
+	return h.Layers[row*h.Columns+col]
+}
+
+// Mean returns the average times the cells that were drawn were drawn
+func (h Heatmap) Mean() float32 {
+	var sum float32
+	var n int
+	for _, l := range h.Layers {
+		if l > 0 {
+			sum += l
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float32(n)
+}
+
+// Max returns the most times a cell was drawn
+func (h Heatmap) Max() (layers float32) {
+	for _, l := range h.Layers {
+		layers = max(layers, l)
+	}
+	return
+}
+
+// cover adds weight times the part of each cell a rect covers, within the
+// grid
+func (h *Heatmap) cover(x0, y0, x1, y1, weight float32) {
+	c := h.Cell
+	col0, row0 := max(int(x0/c), 0), max(int(y0/c), 0)
+	col1 := min(int(math.Ceil(float64(x1/c))), h.Columns)
+	row1 := min(int(math.Ceil(float64(y1/c))), h.Rows)
+	for row := row0; row < row1; row++ {
+		top, bottom := max(y0, float32(row)*c), min(y1, float32(row+1)*c)
+		for col := col0; col < col1; col++ {
+			left, right := max(x0, float32(col)*c), min(x1, float32(col+1)*c)
+			h.Layers[row*h.Columns+col] += weight * (right - left) * (bottom - top) / (c * c)
+		}
+	}
+}
+
+// WriteTo writes the report as text, the frame's totals followed by a line
+// for each widget, the costliest first
+func (p ProfileReport) WriteTo(w io.Writer) (n int64, err error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d regions painted in %s\n", p.Regions, p.Time)
+	fmt.Fprintf(&b, "%d draw commands  %d vertices  %d scissor changes\n", p.Commands, p.Vertices, p.Scissors)
+	fmt.Fprintf(&b, "overdraw mean %.2fx  max %.2fx\n", p.Overdraw.Mean(), p.Overdraw.Max())
+	fmt.Fprintf(&b, "%-24s %10s %10s %6s %8s %8s  %s\n", "widget", "self", "total", "paints", "commands", "vertices", "box")
+	for _, wp := range p.Widgets {
+		fmt.Fprintf(&b, "%-24s %10s %10s %6d %8d %8d  %.0f,%.0f %s\n", wp.Type, wp.Self, wp.Time,
+			wp.Paints, wp.Commands, wp.Vertices, wp.Rect.X, wp.Rect.Y, debugSize(wp.Rect.Width, wp.Rect.Height))
+	}
+	written, err := io.WriteString(w, b.String())
+	return int64(written), err
+}
+
+// profiler is the state of a root's paint profiling
+type profiler struct {
+	start  time.Time
+	report ProfileReport
+}
+
+// Profile turns profiling on or off and returns the root for chaining.
+// While profiling, the root records how long each widget takes to paint and
+// the draw commands and vertices it adds, how often the frame changes the
+// clip rect, and how many times each part of the window is drawn over,
+// described by ProfileReport after each frame. The debug overlay lists the
+// costliest widgets and tints the window with the overdraw heatmap, which
+// repaints the whole window each frame while it shows.
+func (r *RootWidget) Profile(on bool) *RootWidget {
+	switch {
+	case on && r.profile == nil:
+		r.profile = &profiler{}
+	case !on:
+		r.profile = nil
+	}
+	r.InvalidateAll()
+	return r
+}
+
+// Profiling reports whether the root is profiling
+func (r *RootWidget) Profiling() bool {
+	return r.profile != nil
+}
+
+// ProfileReport returns what painting the last frame cost, empty when the
+// root is not profiling
+func (r *RootWidget) ProfileReport() ProfileReport {
+	if r.profile == nil {
+		return ProfileReport{}
+	}
+	return r.profile.report
+}
+
+// beginProfile starts profiling the paint of a frame's damaged regions
+func (r *RootWidget) beginProfile() {
+	if r.paintCosts() != nil {
+		r.paintCost.Reset()
+	}
+	if p := r.profile; p != nil {
+		p.start = time.Now()
+	}
+}
+
+// paintCosts returns the record of what each widget costs to paint, nil
+// unless profiling or monitoring the frame pacing
+func (r *RootWidget) paintCosts() *interfaces.PaintProfile {
+	if r.profile == nil && r.pacing == nil {
+		return nil
+	}
+	return &r.paintCost
+}
+
+// endProfile reports what painting regions of the canvas cost, the
+// commands and vertices from the given ones on in the list painted
+func (r *RootWidget) endProfile(list *render.DrawList, regions int, canvas Rect, commands, vertices int) {
+	p := r.profile
+	if p == nil {
+		return
+	}
+	report := ProfileReport{
+		Regions:  regions,
+		Time:     time.Since(p.start),
+		Commands: len(list.Commands) - commands,
+		Vertices: len(list.Vertices) - vertices,
+	}
+	for _, s := range r.paintCost.Samples() {
+		w, _ := s.Target.(Widget)
+		report.Widgets = append(report.Widgets, WidgetProfile{
+			Widget:   w,
+			Type:     typeName(s.Target),
+			Rect:     s.Rect,
+			Paints:   s.Paints,
+			Time:     s.Time,
+			Self:     s.Self,
+			Commands: s.Commands,
+			Vertices: s.Vertices,
+		})
+	}
+	slices.SortStableFunc(report.Widgets, func(a, b WidgetProfile) int {
+		return cmp.Compare(b.Self, a.Self)
+	})
+	cmds := list.Commands[commands:]
+	for i, c := range cmds {
+		if i == 0 || c.Clipped != cmds[i-1].Clipped || c.Clip != cmds[i-1].Clip {
+			report.Scissors++
+		}
+	}
+	report.Overdraw = overdraw(list, cmds, canvas)
+	p.report = report
+}
+
+// overdraw counts how many times the commands of a list draw over each
+// cell of the canvas. Triangles count by their area, spread evenly over
+// the part of their bounds within the clip.
+func overdraw(list *render.DrawList, commands []render.Command, canvas Rect) (h Heatmap) {
+	h.Cell = heatCell
+	h.Columns = int(math.Ceil(float64((canvas.X + canvas.Width) / heatCell)))
+	h.Rows = int(math.Ceil(float64((canvas.Y + canvas.Height) / heatCell)))
+	h.Layers = make([]float32, h.Columns*h.Rows)
+	for _, c := range commands {
+		clip := [4]float32{canvas.X, canvas.Y, canvas.Width, canvas.Height}
+		if c.Clipped {
+			clip = [4]float32{
+				max(clip[0], c.Clip[0]), max(clip[1], c.Clip[1]),
+				min(clip[0]+clip[2], c.Clip[0]+c.Clip[2]) - max(clip[0], c.Clip[0]),
+				min(clip[1]+clip[3], c.Clip[1]+c.Clip[3]) - max(clip[1], c.Clip[1]),
+			}
+		}
+		if clip[2] <= 0 || clip[3] <= 0 {
+			continue
+		}
+		if c.Clear {
+			h.cover(clip[0], clip[1], clip[0]+clip[2], clip[1]+clip[3], 1)
+			continue
+		}
+		vertices := list.Vertices[c.First : c.First+c.Count]
+		for i := 0; i+2 < len(vertices); i += 3 {
+			a, b, v := vertices[i], vertices[i+1], vertices[i+2]
+			area := float32(math.Abs(float64((b.X-a.X)*(v.Y-a.Y)-(v.X-a.X)*(b.Y-a.Y)))) / 2
+			x0, y0 := min(a.X, b.X, v.X), min(a.Y, b.Y, v.Y)
+			x1, y1 := max(a.X, b.X, v.X), max(a.Y, b.Y, v.Y)
+			bounds := (x1 - x0) * (y1 - y0)
+			x0, y0 = max(x0, clip[0]), max(y0, clip[1])
+			x1, y1 = min(x1, clip[0]+clip[2]), min(y1, clip[1]+clip[3])
+			if bounds <= 0 || x1 <= x0 || y1 <= y0 {
+				continue
+			}
+			h.cover(x0, y0, x1, y1, area/bounds)
+		}
+	}
+	return
+}
+
+// paintHeatmap tints the cells of the canvas drawn over with the color of
+// how many times they were, joining the cells of a row tinted alike
+func paintHeatmap(list *render.DrawList, h Heatmap) {
+	level := func(layers float32) int {
+		return min(int(layers+0.5)-2, len(heatColors)-1)
+	}
+	for row := 0; row < h.Rows; row++ {
+		y := float32(row) * h.Cell
+		for col := 0; col < h.Columns; {
+			l := level(h.Layers[row*h.Columns+col])
+			end := col + 1
+			for end < h.Columns && level(h.Layers[row*h.Columns+end]) == l {
+				end++
+			}
+			if l >= 0 {
+				list.Rect(float32(col)*h.Cell, y, float32(end-col)*h.Cell, h.Cell, heatColors[l])
+			}
+			col = end
+		}
+	}
+}
+
+// profileLines returns the lines the debug overlay shows about the last
+// frame's profile: its totals and the costliest widgets
+func (p *profiler) profileLines() (lines []string) {
+	rep := p.report
+	lines = append(lines,
+		fmt.Sprintf("paint %s  %d regions", debugMillis(rep.Time), rep.Regions),
+		fmt.Sprintf("%d scissor changes  overdraw %.1fx", rep.Scissors, rep.Overdraw.Mean()),
+	)
+	for _, wp := range rep.Widgets[:min(len(rep.Widgets), profileTop)] {
+		lines = append(lines, fmt.Sprintf("%s %s  %d cmds", wp.Type, debugMillis(wp.Self), wp.Commands))
+	}
+	return
+}
@@ -0,0 +1,311 @@
+package widget
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/mleku/goo/pkg/anim"
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/state"
+)
+
+const (
+	// progressThickness is the height of a progress bar
+	progressThickness = 6
+	// progressDuration is how long a progress bar's fill takes to reach a new value
+	progressDuration = 200 * time.Millisecond
+	// progressPeriod is how long the moving segment of an indeterminate
+	// progress bar takes to cross it
+	progressPeriod = 1500 * time.Millisecond
+	// progressSegment is the width of the moving segment as a proportion of the bar
+	progressSegment = 0.3
+	// spinnerSize is the default diameter of a spinner
+	spinnerSize = 24
+	// spinnerPeriod is how long a spinner takes to turn once
+	spinnerPeriod = time.Second
+	// spinnerArc is the proportion of the circle covered by a spinner's arc
+	spinnerArc = 0.75
+	// spinnerSegments is how many straight segments a spinner's arc is drawn with
+	spinnerSegments = 32
+)
+
+// ProgressBarWidget shows how far a task has got by filling a track from
+// the left. The fill glides to each new value. When the amount of work is
+// not known the bar is made indeterminate, and a segment slides across it
+// instead until a value is known.
+type ProgressBarWidget struct {
+	Base
+	value         float32
+	fill          *anim.Tween[float32]
+	indeterminate bool
+	// start is when the indeterminate animation began, zero until it is first drawn
+	start      time.Time
+	trackColor colorOverride
+	fillColor  colorOverride
+	// unbind stops following the value bound, nil when none is
+	unbind func()
+}
+
+// ProgressBar creates a new empty progress bar
+func ProgressBar() *ProgressBarWidget {
+	return &ProgressBarWidget{fill: anim.NewFloat(0)}
+}
+
+// Indeterminate sets whether the bar shows activity without a value and
+// returns the progress bar for chaining
+func (p *ProgressBarWidget) Indeterminate(indeterminate bool) *ProgressBarWidget {
+	p.indeterminate = indeterminate
+	p.start = time.Time{}
+	p.MarkNeedsPaint()
+	return p
+}
+
+// Colors sets the track and fill colors, replacing the theme's, and returns
+// the progress bar for chaining
+func (p *ProgressBarWidget) Colors(track, fill [4]float32) *ProgressBarWidget {
+	p.trackColor = override(track)
+	p.fillColor = override(fill)
+	p.MarkNeedsPaint()
+	return p
+}
+
+// SetValue sets how much of the task is done, from 0 to 1, and animates the
+// fill to it. Setting a value makes an indeterminate bar determinate.
+func (p *ProgressBarWidget) SetValue(value float32) {
+	p.value = min(max(value, 0), 1)
+	if p.indeterminate {
+		// Grow from empty rather than from wherever the fill last was
+		p.indeterminate = false
+		p.fill.Set(0)
+	}
+	p.fill.To(p.value, progressDuration, anim.EaseOutQuad)
+	p.MarkNeedsPaint()
+}
+
+// Bind shows an observable value as the progress, following its changes,
+// and returns the progress bar for chaining. Negative values make the bar
+// indeterminate. Binding again replaces the value followed and nil stops
+// following.
+func (p *ProgressBarWidget) Bind(value state.Value[float32]) *ProgressBarWidget {
+	if p.unbind != nil {
+		p.unbind()
+		p.unbind = nil
+	}
+	if value != nil {
+		p.unbind = state.Watch(value, func(v float32) {
+			if v < 0 {
+				p.Indeterminate(true)
+				return
+			}
+			p.SetValue(v)
+		})
+	}
+	return p
+}
+
+// Value returns how much of the task is done, from 0 to 1
+func (p *ProgressBarWidget) Value() float32 {
+	return p.value
+}
+
+// IsIndeterminate reports whether the bar shows activity without a value
+func (p *ProgressBarWidget) IsIndeterminate() bool {
+	return p.indeterminate
+}
+
+// GetConstraints returns the bar's thickness, stretching along it
+func (p *ProgressBarWidget) GetConstraints() Constraints {
+	return NewFlexConstraints(2*progressThickness, progressThickness, 1e9, progressThickness)
+}
+
+// Measure returns the minimum size of the progress bar
+func (p *ProgressBarWidget) Measure(constraints Constraints) Size {
+	return minSize(p.GetConstraints())
+}
+
+// Layout implements the Widget interface for ProgressBarWidget; progress bars
+// take all the space offered and center the track across it
+func (p *ProgressBarWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	p.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for ProgressBarWidget
+func (p *ProgressBarWidget) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	list := ctx.DrawList
+	h := min(box.Size.Height, progressThickness)
+	x, w := box.Position.X, box.Size.Width
+	y := float32(math.Round(float64(box.Position.Y + (box.Size.Height-h)/2)))
+	list.RoundRect(x, y, w, h, h/2, p.trackColor.or(th.Track))
+
+	fill := p.fillColor.or(th.Primary)
+	if !p.indeterminate {
+		value := p.fill.Value(ctx.Clock)
+		if p.fill.Running() {
+			p.MarkNeedsPaint()
+		}
+		if value > 0 {
+			list.RoundRect(x, y, max(w*value, h), h, h/2, fill)
+		}
+		return
+	}
+
+	// Slide a segment from beyond the start of the track to beyond its end,
+	// clipped to the track's rounded ends
+	now := frameTime(ctx)
+	if p.start.IsZero() {
+		p.start = now
+	}
+	phase := float32(now.Sub(p.start)%progressPeriod) / float32(progressPeriod)
+	segment := w * progressSegment
+	left := x - segment + (w+segment)*anim.EaseInOutQuad(phase)
+	list.PushRoundClip(x, y, w, h, h/2)
+	list.RoundRect(left, y, segment, h, h/2, fill)
+	list.PopClip()
+	ctx.Clock.Request()
+	p.MarkNeedsPaint()
+	return
+}
+
+// Accessibility implements Accessible, with the progress from 0 to 1 as the
+// numeric value unless it is indeterminate
+func (p *ProgressBarWidget) Accessibility() AccessNode {
+	if p.indeterminate {
+		return AccessNode{Role: interfaces.RoleProgressBar}
+	}
+	return AccessNode{
+		Role:    interfaces.RoleProgressBar,
+		Value:   strconv.Itoa(int(math.Round(float64(p.value)*100))) + "%",
+		Numeric: float64(p.value),
+		Max:     1,
+	}
+}
+
+// HandleEvent implements the Widget interface for ProgressBarWidget;
+// progress bars ignore input
+func (p *ProgressBarWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	return false
+}
+
+// SpinnerWidget shows that work is going on with an arc turning around a
+// circle for as long as it is drawn
+type SpinnerWidget struct {
+	Base
+	size float32
+	// start is when the spinner began turning, zero until it is first drawn
+	start      time.Time
+	trackColor colorOverride
+	arcColor   colorOverride
+	// hidden leaves the spinner undrawn while there is nothing to wait for,
+	// and unbind stops following the value bound, nil when none is
+	hidden bool
+	unbind func()
+}
+
+// Spinner creates a new spinner 24 pixels across
+func Spinner() *SpinnerWidget {
+	return &SpinnerWidget{size: spinnerSize}
+}
+
+// Active sets whether the spinner is shown turning, leaving its space empty
+// when not, and returns the spinner for chaining
+func (s *SpinnerWidget) Active(active bool) *SpinnerWidget {
+	if active == !s.hidden {
+		return s
+	}
+	s.hidden = !active
+	s.start = time.Time{}
+	s.MarkNeedsPaint()
+	return s
+}
+
+// BindActive shows the spinner while an observable value is true, such as
+// whether a task is running, following its changes, and returns the spinner
+// for chaining. Binding again replaces the value followed and nil stops
+// following.
+func (s *SpinnerWidget) BindActive(value state.Value[bool]) *SpinnerWidget {
+	if s.unbind != nil {
+		s.unbind()
+		s.unbind = nil
+	}
+	if value != nil {
+		s.unbind = state.Watch(value, func(active bool) { s.Active(active) })
+	}
+	return s
+}
+
+// Size sets the spinner's diameter and returns the spinner for chaining
+func (s *SpinnerWidget) Size(size float32) *SpinnerWidget {
+	s.size = size
+	s.MarkNeedsLayout()
+	return s
+}
+
+// Colors sets the colors of the circle and the arc turning around it,
+// replacing the theme's, and returns the spinner for chaining
+func (s *SpinnerWidget) Colors(track, arc [4]float32) *SpinnerWidget {
+	s.trackColor = override(track)
+	s.arcColor = override(arc)
+	s.MarkNeedsPaint()
+	return s
+}
+
+// GetConstraints returns the spinner's diameter as its minimum size
+func (s *SpinnerWidget) GetConstraints() Constraints {
+	return NewFlexConstraints(s.size, s.size, 1e9, 1e9)
+}
+
+// Measure returns the minimum size of the spinner
+func (s *SpinnerWidget) Measure(constraints Constraints) Size {
+	return minSize(s.GetConstraints())
+}
+
+// Layout implements the Widget interface for SpinnerWidget; spinners take all
+// the space offered and are drawn in the middle of it
+func (s *SpinnerWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	s.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for SpinnerWidget
+func (s *SpinnerWidget) Paint(ctx *Context, box *Box) (err error) {
+	if s.hidden {
+		return
+	}
+	th := themeOf(ctx)
+	list := ctx.DrawList
+	diameter := min(s.size, box.Size.Width, box.Size.Height)
+	width := max(diameter/8, 2)
+	radius := (diameter - width) / 2
+	cx := box.Position.X + box.Size.Width/2
+	cy := box.Position.Y + box.Size.Height/2
+	list.CircleStroke(cx, cy, radius, width, s.trackColor.or(th.Track))
+
+	now := frameTime(ctx)
+	if s.start.IsZero() {
+		s.start = now
+	}
+	turn := float64(now.Sub(s.start)%spinnerPeriod) / float64(spinnerPeriod)
+	points := make([][2]float32, spinnerSegments+1)
+	for i := range points {
+		a := 2 * math.Pi * (turn + spinnerArc*float64(i)/spinnerSegments)
+		points[i] = [2]float32{
+			cx + radius*float32(math.Cos(a)),
+			cy + radius*float32(math.Sin(a)),
+		}
+	}
+	list.Polyline(points, false, width, s.arcColor.or(th.Primary))
+	ctx.Clock.Request()
+	s.MarkNeedsPaint()
+	return
+}
+
+// HandleEvent implements the Widget interface for SpinnerWidget; spinners
+// ignore input
+func (s *SpinnerWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	return false
+}
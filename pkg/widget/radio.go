@@ -0,0 +1,242 @@
+package widget
+
+import (
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/sound"
+	"github.com/mleku/goo/pkg/text"
+)
+
+// RadioGroup is the shared selection of a set of radio buttons. Each button
+// stands for an int value and at most one value is selected at a time.
+type RadioGroup struct {
+	selected int
+	// value is where the selection is stored, the selected field unless bound
+	value    *int
+	onChange func(value int)
+	buttons  []*RadioButtonWidget
+}
+
+// NewRadioGroup creates a new radio group with the given value selected.
+// Pass a value no button stands for to start with none selected.
+func NewRadioGroup(selected int) *RadioGroup {
+	g := &RadioGroup{selected: selected}
+	g.value = &g.selected
+	return g
+}
+
+// Bind stores the selection in value instead of the group and returns the
+// group for chaining. Selecting writes through to value, and changes made to
+// value elsewhere show the next time the buttons are repainted.
+func (g *RadioGroup) Bind(value *int) *RadioGroup {
+	g.value = value
+	g.repaint()
+	return g
+}
+
+// OnChange sets the callback invoked with the newly selected value when the
+// user picks a different button and returns the group for chaining
+func (g *RadioGroup) OnChange(fn func(value int)) *RadioGroup {
+	g.onChange = fn
+	return g
+}
+
+// Select changes the selected value without invoking the change callback
+func (g *RadioGroup) Select(value int) {
+	if value == *g.value {
+		return
+	}
+	*g.value = value
+	g.repaint()
+}
+
+// Selected returns the selected value
+func (g *RadioGroup) Selected() int {
+	return *g.value
+}
+
+// pick selects a value on behalf of the user, invoking the change callback
+// when the selection changed
+func (g *RadioGroup) pick(value int) {
+	if value == *g.value {
+		return
+	}
+	*g.value = value
+	g.repaint()
+	if g.onChange != nil {
+		g.onChange(value)
+	}
+}
+
+// repaint schedules every button in the group to be repainted
+func (g *RadioGroup) repaint() {
+	for _, b := range g.buttons {
+		b.MarkNeedsPaint()
+	}
+}
+
+// RadioButtonWidget is a round button in a RadioGroup, followed by a text
+// label. Clicking it or pressing space while it has focus selects its value,
+// and the arrow keys move the selection to the previous or next button.
+type RadioButtonWidget struct {
+	Base
+	toggle toggle
+	group  *RadioGroup
+	value  int
+}
+
+// RadioButton creates a new radio button in the group standing for value,
+// with a label drawn in the given font. The label defaults to 14 pixel text
+// and may be empty. Buttons are ordered for arrow keys as they are created.
+func RadioButton(group *RadioGroup, value int, font *text.Font, label string) *RadioButtonWidget {
+	r := &RadioButtonWidget{
+		toggle: toggle{font: font, size: 14, label: label},
+		group:  group,
+		value:  value,
+	}
+	group.buttons = append(group.buttons, r)
+	return r
+}
+
+// Size sets the pixel size of the label text, which also scales the button,
+// and returns the button for chaining
+func (r *RadioButtonWidget) Size(size float32) *RadioButtonWidget {
+	r.toggle.size = size
+	r.MarkNeedsLayout()
+	return r
+}
+
+// Disabled sets whether the button ignores input and returns the button for chaining
+func (r *RadioButtonWidget) Disabled(disabled bool) *RadioButtonWidget {
+	r.toggle.disabled = disabled
+	if disabled {
+		r.toggle.pressed = false
+	}
+	r.MarkNeedsPaint()
+	return r
+}
+
+// Value returns the value the button stands for
+func (r *RadioButtonWidget) Value() int {
+	return r.value
+}
+
+// IsSelected reports whether the button's value is the group's selection
+func (r *RadioButtonWidget) IsSelected() bool {
+	return r.group.Selected() == r.value
+}
+
+// GetConstraints returns a minimum size that fits the button and label
+func (r *RadioButtonWidget) GetConstraints() Constraints {
+	return r.toggle.constraints()
+}
+
+// Measure returns the minimum size of the radio button
+func (r *RadioButtonWidget) Measure(constraints Constraints) Size {
+	return minSize(r.GetConstraints())
+}
+
+// Layout implements the Widget interface for RadioButtonWidget; radio
+// buttons take all the space offered and draw at the start of it
+func (r *RadioButtonWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	r.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for RadioButtonWidget
+func (r *RadioButtonWidget) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	selected := r.IsSelected()
+	b := r.toggle.paint(ctx, box, hasFocus(r))
+
+	border, fill, dot := th.Border, th.Field, th.Primary
+	switch {
+	case r.toggle.disabled:
+		border, fill, dot = th.Disabled, th.Disabled, th.TextMuted
+	case selected:
+		border = th.Primary
+	case r.toggle.pressed:
+		fill = th.SurfacePressed
+	case r.toggle.hovered:
+		fill = th.SurfaceHover
+	}
+	s := b.Size.Width
+	fillBordered(ctx, b, s/2, border, fill)
+	if !selected {
+		return
+	}
+
+	// Draw the dot centered in the ring
+	ctx.DrawList.Circle(b.Position.X+s/2, b.Position.Y+s/2, s/4, dot)
+	return
+}
+
+// navigable lets gamepad navigation reach the button unless it is disabled,
+// as it reaches each button of a group
+func (r *RadioButtonWidget) navigable() bool {
+	return !r.toggle.disabled
+}
+
+// Accessibility implements Accessible
+func (r *RadioButtonWidget) Accessibility() AccessNode {
+	node := AccessNode{
+		Role:    interfaces.RoleRadioButton,
+		Name:    r.toggle.label,
+		State:   interfaces.StateCheckable | disabledState(r.toggle.disabled),
+		Actions: []AccessAction{interfaces.AccessClick},
+	}
+	if r.IsSelected() {
+		node.State |= interfaces.StateChecked
+	}
+	return node
+}
+
+// AccessAction implements AccessActor, selecting the button on a click
+func (r *RadioButtonWidget) AccessAction(ctx *Context, action AccessAction, value string) bool {
+	if action != interfaces.AccessClick || r.toggle.disabled {
+		return false
+	}
+	r.group.pick(r.value)
+	return true
+}
+
+// HandleEvent implements the Widget interface for RadioButtonWidget
+func (r *RadioButtonWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if e, ok := ev.(interfaces.KeyEvent); ok && !r.toggle.disabled && hasFocus(r) && e.Action != interfaces.ActionRelease {
+		switch e.Key {
+		case interfaces.KeyUp, interfaces.KeyLeft:
+			r.step(-1)
+			return true
+		case interfaces.KeyDown, interfaces.KeyRight:
+			r.step(1)
+			return true
+		}
+	}
+	handled, activated := r.toggle.handle(r, box, ev)
+	if activated {
+		sound.Play(themeOf(ctx).Sounds.Click)
+		r.group.pick(r.value)
+	}
+	return
+}
+
+// step selects and focuses the next enabled button in the group in the
+// given direction, wrapping around at either end
+func (r *RadioButtonWidget) step(direction int) {
+	buttons := r.group.buttons
+	n := len(buttons)
+	var i int
+	for i = range buttons {
+		if buttons[i] == r {
+			break
+		}
+	}
+	for range n - 1 {
+		i = (i + direction + n) % n
+		if next := buttons[i]; !next.toggle.disabled {
+			requestFocus(next)
+			r.group.pick(next.value)
+			return
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package widget_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/mleku/goo/pkg/headless"
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/widget"
+)
+
+// radioGroup shows three radio buttons for the values 0 to 2 in a column,
+// the middle one disabled when asked
+func radioGroup(t *testing.T, group *widget.RadioGroup, disableMiddle bool) (*harness, []*widget.RadioButtonWidget) {
+	font := loadFont(t)
+	buttons := []*widget.RadioButtonWidget{
+		widget.RadioButton(group, 0, font, "zero"),
+		widget.RadioButton(group, 1, font, "one").Disabled(disableMiddle),
+		widget.RadioButton(group, 2, font, "two"),
+	}
+	column := widget.Column()
+	for _, b := range buttons {
+		column.Rigid(widget.NewFixedSize(160, 28, b))
+	}
+	return newHarness(t, column, 180, 100), buttons
+}
+
+func TestRadioGroupClick(t *testing.T) {
+	var picked []int
+	group := widget.NewRadioGroup(0).OnChange(func(v int) { picked = append(picked, v) })
+	h, buttons := radioGroup(t, group, false)
+	tests := []struct {
+		click int
+		want  int
+	}{
+		{2, 2},
+		{2, 2},
+		{1, 1},
+		{0, 0},
+	}
+	for _, tt := range tests {
+		h.click(h.find(buttons[tt.click]))
+		if got := group.Selected(); got != tt.want {
+			t.Errorf("clicked %d: selected %d, want %d", tt.click, got, tt.want)
+		}
+		for i, b := range buttons {
+			if b.IsSelected() != (i == tt.want) {
+				t.Errorf("clicked %d: button %d selected = %v", tt.click, i, b.IsSelected())
+			}
+		}
+	}
+	// Clicking the selected button again changes nothing
+	if want := []int{2, 1, 0}; !slices.Equal(picked, want) {
+		t.Errorf("changes %v, want %v", picked, want)
+	}
+}
+
+func TestRadioGroupKeys(t *testing.T) {
+	var picked []int
+	group := widget.NewRadioGroup(0).OnChange(func(v int) { picked = append(picked, v) })
+	h, buttons := radioGroup(t, group, true)
+	h.root.SetFocus(buttons[0])
+	tests := []struct {
+		key  interfaces.Key
+		want int
+	}{
+		// The disabled middle button is skipped, and stepping wraps around
+		{interfaces.KeyDown, 2},
+		{interfaces.KeyRight, 0},
+		{interfaces.KeyUp, 2},
+		{interfaces.KeyLeft, 0},
+	}
+	for _, tt := range tests {
+		h.key(tt.key, 0)
+		if got := group.Selected(); got != tt.want {
+			t.Errorf("after %v: selected %d, want %d", tt.key, got, tt.want)
+		}
+		if h.root.Focused() != buttons[tt.want] {
+			t.Errorf("after %v: focus did not follow the selection", tt.key)
+		}
+	}
+	h.click(h.find(buttons[1]))
+	if group.Selected() != 0 {
+		t.Error("clicking a disabled button selected it")
+	}
+	if want := []int{2, 0, 2, 0}; !slices.Equal(picked, want) {
+		t.Errorf("changes %v, want %v", picked, want)
+	}
+}
+
+func TestRadioGroupBind(t *testing.T) {
+	value := 1
+	var changes int
+	group := widget.NewRadioGroup(0).Bind(&value).OnChange(func(int) { changes++ })
+	h, buttons := radioGroup(t, group, false)
+	if !buttons[1].IsSelected() {
+		t.Error("the bound value is not selected")
+	}
+	h.click(h.find(buttons[2]))
+	if value != 2 {
+		t.Errorf("bound value %d, want 2", value)
+	}
+	before := h.img
+	group.Select(0)
+	if value != 0 || changes != 1 {
+		t.Errorf("Select: value %d after %d changes, want 0 after 1", value, changes)
+	}
+	if headless.Diff(before, h.frame(), 0) == 0 {
+		t.Error("selecting did not repaint the buttons")
+	}
+}
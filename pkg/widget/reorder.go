@@ -0,0 +1,257 @@
+package widget
+
+import (
+	"github.com/mleku/goo/pkg/interfaces"
+	"lol.mleku.dev/chk"
+)
+
+// reorderPayload is the payload of a drag moving an item of a reorderable list
+type reorderPayload struct {
+	list  *ReorderListWidget
+	index int
+}
+
+// ReorderListWidget stacks its items from the top down, each at its minimum
+// height and the full width, and lets them be put in a different order by
+// dragging. While an item is dragged a line shows where it will go.
+type ReorderListWidget struct {
+	Base
+	items     []Widget
+	onReorder func(from, to int)
+	// pressed is the item the left button was pressed on, -1 for none
+	pressed int
+	press   Point
+	// insert is the gap a dragged item would be dropped in, -1 while no
+	// item is dragged over the list
+	insert int
+}
+
+// ReorderList creates a new empty reorderable list
+func ReorderList() *ReorderListWidget {
+	return &ReorderListWidget{pressed: -1, insert: -1}
+}
+
+// Add appends an item and returns the list for chaining
+func (l *ReorderListWidget) Add(item Widget) *ReorderListWidget {
+	l.items = append(l.items, item)
+	adopt(l, item)
+	return l
+}
+
+// OnReorder sets the callback invoked with the old and new index of an item
+// the user dragged to a new place and returns the list for chaining. Move
+// the matching entry of the data the list shows with Reorder.
+func (l *ReorderListWidget) OnReorder(fn func(from, to int)) *ReorderListWidget {
+	l.onReorder = fn
+	return l
+}
+
+// Len returns the number of items
+func (l *ReorderListWidget) Len() int {
+	return len(l.items)
+}
+
+// Item returns the item at an index
+func (l *ReorderListWidget) Item(index int) Widget {
+	return l.items[index]
+}
+
+// Move moves the item at index from to index to, without invoking the reorder callback
+func (l *ReorderListWidget) Move(from, to int) {
+	n := len(l.items)
+	if from < 0 || from >= n || to < 0 || to >= n || from == to {
+		return
+	}
+	Reorder(l.items, from, to)
+	l.MarkNeedsLayout()
+}
+
+// Remove removes the item at an index
+func (l *ReorderListWidget) Remove(index int) {
+	if index < 0 || index >= len(l.items) {
+		return
+	}
+	l.items = append(l.items[:index], l.items[index+1:]...)
+	l.MarkNeedsLayout()
+}
+
+// GetConstraints returns the items' minimum heights stacked, at the width of
+// the widest
+func (l *ReorderListWidget) GetConstraints() Constraints {
+	var width, height float32
+	for _, item := range l.items {
+		c := item.GetConstraints()
+		width = max(width, c.MinWidth)
+		height += c.MinHeight
+	}
+	return NewFlexConstraints(width, height, 1e9, 1e9)
+}
+
+// Measure returns the minimum size of the list, which stacks its items at
+// their minimum heights
+func (l *ReorderListWidget) Measure(constraints Constraints) Size {
+	return minSize(l.GetConstraints())
+}
+
+// Layout implements the Widget interface for ReorderListWidget
+func (l *ReorderListWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !l.NeedsLayout(ctx, constraints) {
+		return l.CachedSize(), nil
+	}
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	for _, item := range l.items {
+		if _, err = item.Layout(ctx, NewRigidConstraints(size.Width, item.GetConstraints().MinHeight)); chk.E(err) {
+			return
+		}
+	}
+	l.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for ReorderListWidget
+func (l *ReorderListWidget) Paint(ctx *Context, box *Box) (err error) {
+	for i, item := range l.items {
+		if err = paintChild(ctx, item, l.itemBox(box, i)); chk.E(err) {
+			return
+		}
+	}
+	if l.insert >= 0 {
+		th := themeOf(ctx)
+		y := l.gapY(box, l.insert)
+		ctx.DrawList.Rect(box.Position.X, y-1, box.Size.Width, 2, th.Primary)
+	}
+	return
+}
+
+// HandleEvent implements the Widget interface for ReorderListWidget. Presses
+// an item handles itself do not start a drag.
+func (l *ReorderListWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	for i, item := range l.items {
+		if routeEvent(ctx, item, l.itemBox(box, i), ev) {
+			handled = true
+		}
+	}
+	if handled {
+		return
+	}
+	switch e := ev.(type) {
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft {
+			break
+		}
+		switch e.Action {
+		case interfaces.ActionPress:
+			if i := l.itemAt(box, e.Position.Y); i >= 0 {
+				l.pressed, l.press = i, e.Position
+				return true
+			}
+		case interfaces.ActionRelease:
+			l.pressed = -1
+		}
+	case interfaces.MouseMoveEvent:
+		if l.pressed < 0 || distance(l.press, e.Position) < dragThreshold {
+			break
+		}
+		i := l.pressed
+		l.pressed = -1
+		ib := l.itemBox(box, i)
+		offset := Point{X: ib.Position.X - l.press.X, Y: ib.Position.Y - l.press.Y}
+		StartDrag(l.items[i], &reorderPayload{list: l, index: i}, dragGhost(ib.Size), e.Position, offset)
+		return true
+	}
+	return
+}
+
+// DragEnter implements DropHandler for ReorderListWidget, accepting the
+// list's own items
+func (l *ReorderListWidget) DragEnter(d *Drag) (accept bool) {
+	p, ok := d.Payload.(*reorderPayload)
+	return ok && p.list == l
+}
+
+// DragOver implements DropHandler for ReorderListWidget, showing the gap
+// between the items nearest the cursor
+func (l *ReorderListWidget) DragOver(d *Drag, p Point) {
+	box := &l.paintBox
+	insert := len(l.items)
+	for i := range l.items {
+		ib := l.itemBox(box, i)
+		if p.Y < ib.Position.Y+ib.Size.Height/2 {
+			insert = i
+			break
+		}
+	}
+	if insert != l.insert {
+		l.insert = insert
+		l.MarkNeedsPaint()
+	}
+}
+
+// DragLeave implements DropHandler for ReorderListWidget
+func (l *ReorderListWidget) DragLeave(d *Drag) {
+	if l.insert >= 0 {
+		l.insert = -1
+		l.MarkNeedsPaint()
+	}
+}
+
+// Drop implements DropHandler for ReorderListWidget, moving the dragged item
+// into the gap
+func (l *ReorderListWidget) Drop(d *Drag, p Point) {
+	insert := l.insert
+	l.DragLeave(d)
+	from := d.Payload.(*reorderPayload).index
+	to := insert
+	if to > from {
+		// The gap is counted with the item still in place
+		to--
+	}
+	if insert < 0 || to == from {
+		return
+	}
+	l.Move(from, to)
+	if l.onReorder != nil {
+		l.onReorder(from, to)
+	}
+}
+
+// itemBox returns the absolute box of the item at an index
+func (l *ReorderListWidget) itemBox(box *Box, index int) *Box {
+	c := l.items[index].GetConstraints()
+	return NewBox(box.Position.X, l.gapY(box, index), box.Size.Width, c.MinHeight, c)
+}
+
+// itemAt returns the index of the item at a window y coordinate, -1 if none
+func (l *ReorderListWidget) itemAt(box *Box, y float32) int {
+	top := box.Position.Y
+	for i, item := range l.items {
+		bottom := top + item.GetConstraints().MinHeight
+		if y >= top && y < bottom {
+			return i
+		}
+		top = bottom
+	}
+	return -1
+}
+
+// gapY returns the window y coordinate of the gap before the item at an
+// index, or after the last item
+func (l *ReorderListWidget) gapY(box *Box, index int) float32 {
+	y := box.Position.Y
+	for _, item := range l.items[:index] {
+		y += item.GetConstraints().MinHeight
+	}
+	return y
+}
+
+// Reorder moves the element of a slice at index from to index to, shifting
+// those between along, for keeping data in step with a reordered list
+func Reorder[T any](items []T, from, to int) {
+	moved := items[from]
+	if from < to {
+		copy(items[from:to], items[from+1:to+1])
+	} else {
+		copy(items[to+1:from+1], items[to:from])
+	}
+	items[to] = moved
+}
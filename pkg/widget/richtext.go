@@ -0,0 +1,642 @@
+package widget
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/render"
+	"github.com/mleku/goo/pkg/text"
+)
+
+// objectRune stands for an inline image among the runes of rich text
+const objectRune = '\ufffc'
+
+// Span is a run of rich text drawn in one style. Fields left at their zero
+// value take the rich text's defaults.
+type Span struct {
+	Text string
+	// Font draws the text in place of the rich text's font, for other
+	// families and weights
+	Font *text.Font
+	// Size is the pixel size of the text, 0 for the rich text's size
+	Size float32
+	// Color is the color of the text. A color with zero alpha follows the
+	// theme, in its text color or its primary color for links.
+	Color [4]float32
+	// Bold draws the text in the rich text's bold font, or thickened when
+	// it has none
+	Bold bool
+	// Underline and Strikethrough draw lines under and through the text
+	Underline, Strikethrough bool
+	// Link makes the span clickable, passing the link to the rich text's
+	// link callback. Links are drawn underlined.
+	Link string
+	// Image is drawn inline in place of the text, ImageWidth by ImageHeight
+	// units with its bottom on the baseline
+	Image                   *render.Texture
+	ImageWidth, ImageHeight float32
+}
+
+// richGlyph is a rune of rich text with the span it belongs to
+type richGlyph struct {
+	span    int
+	r       rune
+	advance float32
+}
+
+// richLine is one displayed line of rich text, a range of its glyphs
+type richLine struct {
+	start, end int
+	// width is the width of the glyphs without the spaces the line broke at
+	width float32
+	// y is the top of the line, ascent the distance from it to the baseline
+	y, ascent, height float32
+}
+
+// RichTextWidget shows paragraphs of text mixing fonts, sizes, colors and
+// decorations, with inline images and clickable links. Lines wrap at word
+// boundaries to the width of the box and newlines start new paragraphs.
+// Text can be selected by dragging and copied. Like WrapWidget its height
+// depends on its width, so it reports the height of the lines it was last
+// laid out in.
+type RichTextWidget struct {
+	Base
+	font       *text.Font
+	boldFont   *text.Font
+	size       float32
+	color      colorOverride
+	align      text.Alignment
+	selectable bool
+	onLink     func(link string)
+	onSelect   func(selected string)
+	spans      []Span
+	// glyphs holds the runes of every span, rebuilt when glyphsValid is unset
+	glyphs      []richGlyph
+	glyphsValid bool
+	// lines holds the lines of the glyphs wrapped to linesWidth
+	lines      []richLine
+	linesWidth float32
+	linesValid bool
+	// height is the height of the lines from the last layout, and stale is
+	// set when it changed since the parent read it
+	height float32
+	stale  bool
+	// anchor and caret are the glyph positions the selection runs between
+	anchor, caret int
+	dragging      bool
+	// pressed is the span of the link the mouse was pressed on, -1 if none
+	pressed int
+}
+
+// RichText creates a new rich text widget showing the spans. The text
+// defaults to 14 pixels in the theme's text color, aligned to the start of
+// the box, and can be selected.
+func RichText(font *text.Font, spans ...Span) *RichTextWidget {
+	return &RichTextWidget{
+		font:       font,
+		size:       14,
+		align:      text.AlignStart,
+		selectable: true,
+		spans:      spans,
+		height:     -1,
+		pressed:    -1,
+	}
+}
+
+// Size sets the default pixel size of the text and returns the rich text for chaining
+func (r *RichTextWidget) Size(size float32) *RichTextWidget {
+	r.size = size
+	r.invalidate()
+	return r
+}
+
+// BoldFont sets the font bold spans are drawn in and returns the rich text for chaining
+func (r *RichTextWidget) BoldFont(font *text.Font) *RichTextWidget {
+	r.boldFont = font
+	r.invalidate()
+	return r
+}
+
+// Color sets the default text color, replacing the theme's, and returns the
+// rich text for chaining
+func (r *RichTextWidget) Color(red, green, blue, alpha float32) *RichTextWidget {
+	r.color = override([4]float32{red, green, blue, alpha})
+	r.MarkNeedsPaint()
+	return r
+}
+
+// Align sets the horizontal alignment of the lines and returns the rich text for chaining
+func (r *RichTextWidget) Align(align text.Alignment) *RichTextWidget {
+	r.align = align
+	r.MarkNeedsPaint()
+	return r
+}
+
+// Selectable sets whether the text can be selected and copied and returns
+// the rich text for chaining
+func (r *RichTextWidget) Selectable(selectable bool) *RichTextWidget {
+	r.selectable = selectable
+	r.anchor, r.caret = 0, 0
+	r.MarkNeedsPaint()
+	return r
+}
+
+// OnLink sets the callback invoked with the link of a link span when it is
+// clicked, and returns the rich text for chaining
+func (r *RichTextWidget) OnLink(fn func(link string)) *RichTextWidget {
+	r.onLink = fn
+	return r
+}
+
+// OnSelectionChange sets the callback invoked with the selected text when
+// the user changes the selection, and returns the rich text for chaining
+func (r *RichTextWidget) OnSelectionChange(fn func(selected string)) *RichTextWidget {
+	r.onSelect = fn
+	return r
+}
+
+// SetSpans replaces the displayed spans and clears the selection
+func (r *RichTextWidget) SetSpans(spans ...Span) {
+	r.spans = spans
+	r.anchor, r.caret, r.pressed = 0, 0, -1
+	r.invalidate()
+}
+
+// Spans returns the displayed spans
+func (r *RichTextWidget) Spans() []Span {
+	return r.spans
+}
+
+// Text returns the text of the spans without styling
+func (r *RichTextWidget) Text() string {
+	return r.textOf(0, len(r.ensureGlyphs()))
+}
+
+// SelectedText returns the selected text, empty when nothing is selected
+func (r *RichTextWidget) SelectedText() string {
+	start, end := r.selection()
+	return r.textOf(start, end)
+}
+
+// invalidate discards the glyphs and lines after the spans or their
+// defaults changed
+func (r *RichTextWidget) invalidate() {
+	r.glyphsValid = false
+	r.linesValid = false
+	r.MarkNeedsLayout()
+}
+
+// GetConstraints returns the rich text's constraints, at least as wide as
+// its widest word and as tall as its lines were when it was last laid out,
+// or as its unwrapped lines before that
+func (r *RichTextWidget) GetConstraints() Constraints {
+	glyphs := r.ensureGlyphs()
+	var widest, word float32
+	for _, g := range glyphs {
+		if unicode.IsSpace(g.r) {
+			word = 0
+			continue
+		}
+		word += g.advance
+		widest = max(widest, word)
+	}
+	height := r.height
+	if height < 0 {
+		height = r.Measure(NewFlexConstraints(0, 0, 1e9, 1e9)).Height
+	}
+	return NewFlexConstraints(float32(math.Ceil(float64(widest))), height, 1e9, 1e9)
+}
+
+// Measure returns the size of the lines the text wraps into within the
+// maximum width, as wide as the longest
+func (r *RichTextWidget) Measure(constraints Constraints) (size Size) {
+	lines := r.ensureLines(constraints.MaxWidth)
+	for _, l := range lines {
+		size.Width = max(size.Width, l.width)
+	}
+	if n := len(lines); n > 0 {
+		size.Height = lines[n-1].y + lines[n-1].height
+	}
+	size.Width = float32(math.Ceil(float64(size.Width)))
+	return
+}
+
+// Layout implements the Widget interface for RichTextWidget. The text is as
+// wide as it is allowed, or as its longest line when that is unbounded.
+func (r *RichTextWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !r.NeedsLayout(ctx, constraints) {
+		return r.CachedSize(), nil
+	}
+	lines := r.Measure(constraints)
+	size.Width = constraints.MaxWidth
+	if size.Width >= 1e9 {
+		size.Width = lines.Width
+	}
+	size.Height = max(lines.Height, constraints.MinHeight)
+	if lines.Height != r.height {
+		r.height = lines.Height
+		r.stale = true
+	}
+	r.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for RichTextWidget
+func (r *RichTextWidget) Paint(ctx *Context, box *Box) (err error) {
+	if r.stale {
+		// The parent read the height of the lines before they were laid out
+		// at this width
+		r.stale = false
+		r.MarkNeedsLayout()
+		ctx.Clock.Request()
+	}
+	th := themeOf(ctx)
+	list := ctx.DrawList
+	glyphs := r.ensureGlyphs()
+	lines := r.ensureLines(box.Size.Width)
+	start, end := r.selection()
+	selected := start != end && hasFocus(r)
+	for _, l := range lines {
+		x := box.Position.X + r.align.Offset(l.width, box.Size.Width)
+		y := box.Position.Y + l.y
+		if selected && start <= l.end && end > l.start {
+			x0, x1 := r.advance(l.start, max(start, l.start)), r.advance(l.start, min(end, l.end))
+			if end > l.end && l.end < len(glyphs) && glyphs[l.end].r == '\n' {
+				// Show the selected line break as a space
+				x1 += r.face(glyphs[l.end].span).Glyph(' ').Advance
+			}
+			list.Rect(x+x0, y, x1-x0, l.height, th.Selection)
+		}
+		baseline := y + l.ascent
+		for i := l.start; i < l.end; {
+			// Draw the runs of glyphs sharing a span together
+			j := i + 1
+			for j < l.end && glyphs[j].span == glyphs[i].span {
+				j++
+			}
+			width := r.advance(i, j)
+			r.paintRun(ctx, glyphs[i].span, glyphs[i:j], x, baseline, width)
+			x += width
+			i = j
+		}
+	}
+	return
+}
+
+// paintRun draws glyphs of one span with the pen at x on the baseline
+func (r *RichTextWidget) paintRun(ctx *Context, index int, glyphs []richGlyph, x, baseline, width float32) {
+	th := themeOf(ctx)
+	list := ctx.DrawList
+	s := &r.spans[index]
+	if s.Image != nil {
+		list.Image(s.Image, x, baseline-s.ImageHeight, s.ImageWidth, s.ImageHeight, 0, 0, 1, 1, [4]float32{1, 1, 1, 1})
+		return
+	}
+	color := s.Color
+	if color[3] == 0 {
+		color = r.color.or(th.Text)
+		if s.Link != "" {
+			color = th.Link
+		}
+	}
+	face := r.face(index)
+	var b strings.Builder
+	for _, g := range glyphs {
+		b.WriteRune(g.r)
+	}
+	run := b.String()
+	face.Draw(list, x, baseline, run, color)
+	if s.Bold && r.boldFont == nil && s.Font == nil {
+		face.Draw(list, x+1, baseline, run, color)
+	}
+	// Decorations are as thick as a twelfth of the text, at least a pixel
+	thickness := max(float32(math.Round(float64(face.Size()/12))), 1)
+	if s.Underline || s.Link != "" {
+		list.Rect(x, float32(math.Round(float64(baseline)))+thickness, width, thickness, color)
+	}
+	if s.Strikethrough {
+		y := float32(math.Round(float64(baseline - face.Ascent()*0.3)))
+		list.Rect(x, y, width, thickness, color)
+	}
+}
+
+// CursorAt implements CursorProvider, showing the hand over links and the
+// text cursor over selectable text
+func (r *RichTextWidget) CursorAt(p Point) Cursor {
+	box := &r.paintBox
+	if r.linkAt(box, p) >= 0 {
+		return interfaces.CursorHand
+	}
+	if r.selectable {
+		return interfaces.CursorIBeam
+	}
+	return interfaces.CursorDefault
+}
+
+// Accessibility implements Accessible, naming the text by its plain text
+func (r *RichTextWidget) Accessibility() AccessNode {
+	return AccessNode{Role: interfaces.RoleLabel, Name: r.Text()}
+}
+
+// HandleEvent implements the Widget interface for RichTextWidget
+func (r *RichTextWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft {
+			return false
+		}
+		switch e.Action {
+		case interfaces.ActionPress:
+			// Presses are only delivered when the cursor is inside the box
+			r.pressed = r.linkAt(box, e.Position)
+			if r.selectable {
+				requestFocus(r)
+				r.moveTo(r.indexAt(box, e.Position), e.Mods&interfaces.ModShift != 0)
+				r.dragging = true
+			}
+			return r.pressed >= 0 || r.selectable
+		case interfaces.ActionRelease:
+			pressed := r.pressed
+			r.pressed = -1
+			r.dragging = false
+			if pressed < 0 {
+				return false
+			}
+			// A drag that selected text does not follow the link
+			if start, end := r.selection(); start == end && r.linkAt(box, e.Position) == pressed && r.onLink != nil {
+				r.onLink(r.spans[pressed].Link)
+			}
+			return true
+		}
+	case interfaces.MouseMoveEvent:
+		if !r.dragging {
+			return false
+		}
+		r.moveTo(r.indexAt(box, e.Position), true)
+		return true
+	case interfaces.KeyEvent:
+		if !hasFocus(r) || e.Action == interfaces.ActionRelease || e.Mods&(interfaces.ModControl|interfaces.ModSuper) == 0 {
+			return false
+		}
+		switch e.Key {
+		case interfaces.KeyA:
+			r.selectRange(0, len(r.ensureGlyphs()))
+			return true
+		case interfaces.KeyC:
+			if s := r.SelectedText(); s != "" && ctx.Clipboard != nil {
+				ctx.Clipboard.SetText(s)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// moveTo moves the caret to a glyph position, extending the selection from
+// the anchor or collapsing it
+func (r *RichTextWidget) moveTo(pos int, extend bool) {
+	anchor := pos
+	if extend {
+		anchor = r.anchor
+	}
+	r.selectRange(anchor, pos)
+}
+
+// selectRange sets the anchor and caret, notifying a change of the text
+// selected
+func (r *RichTextWidget) selectRange(anchor, caret int) {
+	start, end := r.selection()
+	r.anchor, r.caret = anchor, caret
+	if s, e := r.selection(); s == start && e == end {
+		return
+	}
+	r.MarkNeedsPaint()
+	if r.onSelect != nil {
+		r.onSelect(r.SelectedText())
+	}
+}
+
+// selection returns the selected glyph range in order
+func (r *RichTextWidget) selection() (start, end int) {
+	n := len(r.ensureGlyphs())
+	start, end = min(r.anchor, r.caret, n), min(max(r.anchor, r.caret), n)
+	return
+}
+
+// textOf returns the runes of the glyphs from start to end, leaving out
+// images
+func (r *RichTextWidget) textOf(start, end int) string {
+	var b strings.Builder
+	for _, g := range r.ensureGlyphs()[start:end] {
+		if r.spans[g.span].Image == nil {
+			b.WriteRune(g.r)
+		}
+	}
+	return b.String()
+}
+
+// lineAt returns the index of the line at a point in window coordinates
+// and the x of the point from the start of the line
+func (r *RichTextWidget) lineAt(box *Box, p Point) (index int, x float32) {
+	lines := r.ensureLines(box.Size.Width)
+	y := p.Y - box.Position.Y
+	index = sort.Search(len(lines), func(i int) bool { return lines[i].y+lines[i].height > y })
+	index = min(index, len(lines)-1)
+	l := lines[index]
+	return index, p.X - box.Position.X - r.align.Offset(l.width, box.Size.Width)
+}
+
+// indexAt returns the glyph position closest to a point in window coordinates
+func (r *RichTextWidget) indexAt(box *Box, p Point) int {
+	i, x := r.lineAt(box, p)
+	l := r.lines[i]
+	var pen float32
+	for j := l.start; j < l.end; j++ {
+		advance := r.glyphs[j].advance
+		if x < pen+advance/2 {
+			return j
+		}
+		pen += advance
+	}
+	return l.end
+}
+
+// linkAt returns the span of the link under a point in window coordinates,
+// -1 if there is none
+func (r *RichTextWidget) linkAt(box *Box, p Point) int {
+	if !box.Contains(p) {
+		return -1
+	}
+	i, x := r.lineAt(box, p)
+	l := r.lines[i]
+	if p.Y-box.Position.Y >= l.y+l.height {
+		return -1
+	}
+	var pen float32
+	for j := l.start; j < l.end; j++ {
+		g := r.glyphs[j]
+		if x >= pen && x < pen+g.advance {
+			if r.spans[g.span].Link != "" {
+				return g.span
+			}
+			return -1
+		}
+		pen += g.advance
+	}
+	return -1
+}
+
+// advance returns the width of the glyphs from start to end
+func (r *RichTextWidget) advance(start, end int) (width float32) {
+	for _, g := range r.glyphs[start:end] {
+		width += g.advance
+	}
+	return
+}
+
+// face returns the face a span's text is drawn in
+func (r *RichTextWidget) face(index int) *text.Face {
+	s := &r.spans[index]
+	font := r.font
+	switch {
+	case s.Font != nil:
+		font = s.Font
+	case s.Bold && r.boldFont != nil:
+		font = r.boldFont
+	}
+	size := s.Size
+	if size <= 0 {
+		size = r.size
+	}
+	return font.Face(size)
+}
+
+// metrics returns the distances a span reaches above and below the baseline
+func (r *RichTextWidget) metrics(index int) (ascent, descent float32) {
+	if s := &r.spans[index]; s.Image != nil {
+		return s.ImageHeight, 0
+	}
+	face := r.face(index)
+	return face.Ascent(), face.LineHeight() - face.Ascent()
+}
+
+// ensureGlyphs splits the spans into glyphs if they changed since the last call
+func (r *RichTextWidget) ensureGlyphs() []richGlyph {
+	if r.glyphsValid {
+		return r.glyphs
+	}
+	r.glyphs = r.glyphs[:0]
+	for i := range r.spans {
+		s := &r.spans[i]
+		if s.Image != nil {
+			r.glyphs = append(r.glyphs, richGlyph{span: i, r: objectRune, advance: s.ImageWidth})
+			continue
+		}
+		face := r.face(i)
+		for _, c := range strings.ReplaceAll(s.Text, "\r\n", "\n") {
+			var advance float32
+			if c != '\n' {
+				advance = face.Glyph(c).Advance
+			}
+			r.glyphs = append(r.glyphs, richGlyph{span: i, r: c, advance: advance})
+		}
+	}
+	r.glyphsValid = true
+	r.linesValid = false
+	return r.glyphs
+}
+
+// ensureLines wraps the glyphs into lines no wider than width if they or
+// the width changed since the last call
+func (r *RichTextWidget) ensureLines(width float32) []richLine {
+	glyphs := r.ensureGlyphs()
+	if r.linesValid && width == r.linesWidth {
+		return r.lines
+	}
+	r.lines = r.lines[:0]
+	for start := 0; start <= len(glyphs); {
+		end := start
+		for end < len(glyphs) && glyphs[end].r != '\n' {
+			end++
+		}
+		r.wrapParagraph(start, end, width)
+		start = end + 1
+	}
+	r.linesWidth = width
+	r.linesValid = true
+	return r.lines
+}
+
+// wrapParagraph appends the lines of the paragraph from start to end,
+// breaking after spaces where possible and mid-word otherwise
+func (r *RichTextWidget) wrapParagraph(start, end int, width float32) {
+	glyphs := r.glyphs
+	for {
+		i, brk := start, -1
+		var used float32
+		for i < end {
+			advance := glyphs[i].advance
+			if used+advance > width && i > start {
+				break
+			}
+			used += advance
+			if unicode.IsSpace(glyphs[i].r) {
+				brk = i + 1
+			}
+			i++
+		}
+		if i < end {
+			if unicode.IsSpace(glyphs[i].r) {
+				// Spaces may hang past the edge so the next line starts with a word
+				for i < end && unicode.IsSpace(glyphs[i].r) {
+					i++
+				}
+			} else if brk > start {
+				i = brk
+			}
+		}
+		if i >= end {
+			r.addLine(start, end)
+			return
+		}
+		r.addLine(start, i)
+		start = i
+	}
+}
+
+// addLine appends the line of the glyphs from start to end below the
+// others, as tall as its tallest span
+func (r *RichTextWidget) addLine(start, end int) {
+	l := richLine{start: start, end: end}
+	if n := len(r.lines); n > 0 {
+		l.y = r.lines[n-1].y + r.lines[n-1].height
+	}
+	var descent float32
+	switch {
+	case start < end:
+		for i := start; i < end; i++ {
+			a, d := r.metrics(r.glyphs[i].span)
+			l.ascent, descent = max(l.ascent, a), max(descent, d)
+		}
+	case start < len(r.glyphs):
+		// An empty paragraph is as tall as the span of its line break
+		l.ascent, descent = r.metrics(r.glyphs[start].span)
+	default:
+		face := r.font.Face(r.size)
+		if n := len(r.glyphs); n > 0 {
+			face = r.face(r.glyphs[n-1].span)
+		}
+		l.ascent, descent = face.Ascent(), face.LineHeight()-face.Ascent()
+	}
+	l.height = l.ascent + descent
+	// Spaces the line broke at hang past its width
+	last := end
+	for last > start && unicode.IsSpace(r.glyphs[last-1].r) {
+		last--
+	}
+	l.width = r.advance(start, last)
+	r.lines = append(r.lines, l)
+}
@@ -0,0 +1,705 @@
+package widget
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/mleku/goo/pkg/anim"
+	"github.com/mleku/goo/pkg/interfaces"
+	"lol.mleku.dev/chk"
+)
+
+// scrollAxis identifies the scrollbar being dragged
+type scrollAxis int
+
+const (
+	scrollAxisNone scrollAxis = iota
+	scrollAxisHorizontal
+	scrollAxisVertical
+)
+
+// minThumbLength keeps scrollbar thumbs large enough to grab on long content
+const minThumbLength = 16
+
+// ScrollWidget shows a scrollable window onto a child that may be larger
+// than its box. The child's extent along each scrolling axis is the larger of
+// the viewport and the child's minimum constraint, so give the child minimum
+// constraints (or wrap it in a FixedSize) covering its full content.
+// Kinetic scroll widgets can also be dragged and flung, and move in time
+// with the frame clock until they come to rest. Content is always dragged
+// and flung by touch.
+type ScrollWidget struct {
+	Base
+	child       Widget
+	constraints Constraints
+	horizontal  bool
+	vertical    bool
+	barWidth    float32
+	step        float32
+	// offset is the position of the content shown at the viewport origin
+	offset Point
+	// content is the child's size and viewport the visible area from the last layout
+	content  Size
+	viewport Size
+	// showH and showV record which scrollbars are visible
+	showH, showV bool
+	// dragging is the scrollbar whose thumb is held, grab is where the drag
+	// started and grabOffset the scroll offset at that time
+	dragging   scrollAxis
+	grab       Point
+	grabOffset Point
+	// kinetic lets the content be dragged and flung, and overscroll lets it
+	// be pulled past its ends
+	kinetic, overscroll bool
+	// panning is set while the content is dragged, which starts at grab
+	// with the scroll offset at grabOffset
+	panning bool
+	tracker velocityTracker
+	// touch is the touch that may drag the content, from touchStart, while
+	// touching is set
+	touch      int
+	touching   bool
+	touchStart Point
+	// motion moves each axis after the content is released
+	motionX, motionY axisMotion
+	// animation moves the offset towards the target of AnimateTo, nil when
+	// not animating
+	animation *anim.Tween[Point]
+
+	// Scrollbar colors follow the theme unless set with Colors
+	trackColor  colorOverride
+	thumbColor  colorOverride
+	activeColor colorOverride
+	// key is the key the offset is remembered under
+	key string
+}
+
+// Scroll creates a new scroll widget showing the child. Both axes scroll and
+// scrollbars appear on an axis when the content overflows it.
+// If no constraints are provided, uses default flexible constraints (0, 0, 1e9, 1e9).
+func Scroll(child Widget, constraints ...Constraints) *ScrollWidget {
+	var c Constraints
+	if len(constraints) > 0 {
+		c = constraints[0]
+	} else {
+		c = NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	s := &ScrollWidget{
+		child:       child,
+		constraints: c,
+		horizontal:  true,
+		vertical:    true,
+		barWidth:    10,
+		step:        40,
+	}
+	adopt(s, child)
+	return s
+}
+
+// Axes sets which directions scroll and returns the scroll widget for chaining.
+// The child is fitted to the viewport along axes that do not scroll.
+func (s *ScrollWidget) Axes(horizontal, vertical bool) *ScrollWidget {
+	s.horizontal = horizontal
+	s.vertical = vertical
+	s.MarkNeedsLayout()
+	return s
+}
+
+// ScrollbarWidth sets the thickness of the scrollbars and returns the scroll widget for chaining
+func (s *ScrollWidget) ScrollbarWidth(width float32) *ScrollWidget {
+	s.barWidth = width
+	s.MarkNeedsLayout()
+	return s
+}
+
+// Step sets the distance scrolled by one mouse wheel notch and returns the scroll widget for chaining
+func (s *ScrollWidget) Step(step float32) *ScrollWidget {
+	s.step = step
+	return s
+}
+
+// Kinetic lets the content be dragged with the left button where the child
+// does not take the press, and keeps it moving when released while moving,
+// slowing down until it stops. Returns the scroll widget for chaining.
+func (s *ScrollWidget) Kinetic(kinetic bool) *ScrollWidget {
+	s.kinetic = kinetic
+	return s
+}
+
+// Overscroll lets dragged and flung content move past its ends, resisting
+// further the further it goes and springing back when let go, and returns
+// the scroll widget for chaining
+func (s *ScrollWidget) Overscroll(overscroll bool) *ScrollWidget {
+	s.overscroll = overscroll
+	return s
+}
+
+// Colors sets the scrollbar track, thumb and dragged thumb colors, replacing
+// the theme's, and returns the scroll widget for chaining
+func (s *ScrollWidget) Colors(track, thumb, active [4]float32) *ScrollWidget {
+	s.trackColor = override(track)
+	s.thumbColor = override(thumb)
+	s.activeColor = override(active)
+	s.MarkNeedsPaint()
+	return s
+}
+
+// StateKey remembers the scroll position with the state of the user
+// interface under the key and returns the scroll widget for chaining
+func (s *ScrollWidget) StateKey(key string) *ScrollWidget {
+	s.key = key
+	return s
+}
+
+// Offset returns the scroll position, the point of the content shown at the
+// top left of the viewport
+func (s *ScrollWidget) Offset() Point {
+	return s.offset
+}
+
+// ScrollTo scrolls so the given point of the content is at the top left of
+// the viewport, clamped to the scrollable range
+func (s *ScrollWidget) ScrollTo(x, y float32) {
+	s.setOffset(Point{X: x, Y: y})
+}
+
+// ScrollBy scrolls the content by the given distance, clamped to the
+// scrollable range
+func (s *ScrollWidget) ScrollBy(dx, dy float32) {
+	s.setOffset(Point{X: s.offset.X + dx, Y: s.offset.Y + dy})
+}
+
+// AnimateTo scrolls smoothly so the given point of the content reaches the
+// top left of the viewport over the duration using the easing, nil for
+// Linear, clamped to the scrollable range
+func (s *ScrollWidget) AnimateTo(x, y float32, duration time.Duration, ease anim.Easing) {
+	target := Point{X: x, Y: y}
+	if s.valid {
+		target = s.clamp(target)
+	}
+	s.stop()
+	s.animation = anim.NewTween(s.offset, lerpPoint)
+	s.animation.To(target, duration, ease)
+	s.MarkNeedsPaint()
+}
+
+// Moving reports whether the content is being dragged, coasting, springing
+// back or animating
+func (s *ScrollWidget) Moving() bool {
+	return s.panning || s.animation != nil || s.motionX.kind != motionNone || s.motionY.kind != motionNone
+}
+
+// GetConstraints returns the scroll widget's constraints
+func (s *ScrollWidget) GetConstraints() Constraints {
+	return s.constraints
+}
+
+// Measure returns the minimum size of the scroll view, which shows its
+// content in whatever space it is given
+func (s *ScrollWidget) Measure(constraints Constraints) Size {
+	return minSize(s.GetConstraints())
+}
+
+// Layout implements the Widget interface for ScrollWidget.
+// The scroll widget fills the space offered and lays the child out at its
+// full content size.
+func (s *ScrollWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !s.NeedsLayout(ctx, constraints) {
+		return s.CachedSize(), nil
+	}
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	var minimum Size
+	if s.child != nil {
+		childConstraints := s.child.GetConstraints()
+		minimum = Size{Width: childConstraints.MinWidth, Height: childConstraints.MinHeight}
+	}
+
+	// A scrollbar narrows the viewport, which may make the other axis overflow
+	s.viewport = size
+	s.showV = s.vertical && minimum.Height > s.viewport.Height
+	if s.showV {
+		s.viewport.Width -= s.barWidth
+	}
+	s.showH = s.horizontal && minimum.Width > s.viewport.Width
+	if s.showH {
+		s.viewport.Height -= s.barWidth
+		if !s.showV && s.vertical && minimum.Height > s.viewport.Height {
+			s.showV = true
+			s.viewport.Width -= s.barWidth
+		}
+	}
+	s.viewport.Width = max(s.viewport.Width, 0)
+	s.viewport.Height = max(s.viewport.Height, 0)
+
+	s.content = s.viewport
+	if s.horizontal {
+		s.content.Width = max(s.content.Width, minimum.Width)
+	}
+	if s.vertical {
+		s.content.Height = max(s.content.Height, minimum.Height)
+	}
+	if s.child != nil {
+		if _, err = s.child.Layout(ctx, NewRigidConstraints(s.content.Width, s.content.Height)); chk.E(err) {
+			return
+		}
+	}
+	s.SetLayout(ctx, constraints, size)
+
+	// The content or viewport may have shrunk past the current position,
+	// unless it is being pulled or sprung past an end
+	if !s.Moving() {
+		s.offset = s.clamp(s.offset)
+	}
+	return
+}
+
+// Paint implements the Widget interface for ScrollWidget
+func (s *ScrollWidget) Paint(ctx *Context, box *Box) (err error) {
+	s.advance(ctx)
+	view := s.viewportBox(box)
+	if s.child != nil {
+		// Restrict the child to the viewport, both for drawing and for
+		// skipping descendants outside the region being repainted
+		clip := view.Rect()
+		if !ctx.Clip.Empty() {
+			clip = clip.Intersect(ctx.Clip)
+		}
+		if !clip.Empty() {
+			clipped := *ctx
+			clipped.Clip = clip
+			list := ctx.DrawList
+			list.PushClip(clip.X, clip.Y, clip.Width, clip.Height)
+			ctx.Hits.PushClip(view.Rect())
+			err = paintChild(&clipped, s.child, s.contentBox(box))
+			ctx.Hits.PopClip()
+			list.PopClip()
+			if chk.E(err) {
+				return
+			}
+		}
+	}
+
+	// Draw the scrollbars over the strips left beside the viewport
+	th := themeOf(ctx)
+	trackColor := s.trackColor.or(th.Track)
+	for _, axis := range []scrollAxis{scrollAxisVertical, scrollAxisHorizontal} {
+		if (axis == scrollAxisVertical && !s.showV) || (axis == scrollAxisHorizontal && !s.showH) {
+			continue
+		}
+		track, thumb := s.bar(box, axis)
+		fillRect(ctx, track, trackColor)
+		thumbColor := s.thumbColor.or(th.Thumb)
+		if s.dragging == axis {
+			thumbColor = s.activeColor.or(th.Primary)
+		}
+		ctx.DrawList.RoundRect(
+			thumb.Position.X+1, thumb.Position.Y+1, thumb.Size.Width-2, thumb.Size.Height-2,
+			th.Radius.Small, thumbColor,
+		)
+	}
+	if s.showH && s.showV {
+		// Fill the corner where the scrollbars meet
+		corner := NewBox(
+			view.Position.X+view.Size.Width, view.Position.Y+view.Size.Height,
+			s.barWidth, s.barWidth, Constraints{},
+		)
+		fillRect(ctx, corner, trackColor)
+	}
+	return
+}
+
+// HandleEvent implements the Widget interface for ScrollWidget.
+// The child gets the first chance at events so nested scroll widgets scroll
+// before their ancestors.
+func (s *ScrollWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	view := s.viewportBox(box)
+	if s.child != nil {
+		// Targeted events over the scrollbars or scrolled out content do not
+		// reach the child
+		if at, targeted := interfaces.Target(ev); !targeted || view.Contains(at) {
+			if routeEvent(ctx, s.child, s.contentBox(box), ev) && targeted {
+				return true
+			}
+		}
+	}
+
+	switch e := ev.(type) {
+	case interfaces.ScrollEvent:
+		dx, dy := -e.Offset.X*s.step, -e.Offset.Y*s.step
+		if !s.vertical {
+			// Let a plain wheel scroll horizontal-only content
+			dx, dy = dx+dy, 0
+		}
+		before := s.offset
+		s.ScrollBy(dx, dy)
+		return s.offset != before
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft {
+			return false
+		}
+		switch e.Action {
+		case interfaces.ActionPress:
+			if s.press(box, e.Position) {
+				return true
+			}
+			if s.kinetic && !s.touching && view.Contains(e.Position) {
+				s.startPan(ctx, e.Position)
+				return true
+			}
+			return false
+		case interfaces.ActionRelease:
+			if s.panning && !s.touching {
+				s.release(ctx, e.Position)
+				return true
+			}
+			if s.dragging == scrollAxisNone {
+				return false
+			}
+			s.dragging = scrollAxisNone
+			s.MarkNeedsPaint()
+			return true
+		}
+	case interfaces.MouseMoveEvent:
+		if s.panning && !s.touching {
+			s.pan(ctx, e.Position)
+			return true
+		}
+		if s.dragging == scrollAxisNone {
+			return false
+		}
+		s.drag(box, e.Position)
+		return true
+	case interfaces.TouchEvent:
+		return s.touchEvent(ctx, view, e)
+	}
+	return false
+}
+
+// touchEvent drags the content with a touch once it moves far enough along
+// a scrolling axis not to be a press, claiming the touch from the widgets
+// under it, and catches moving content as soon as it is touched
+func (s *ScrollWidget) touchEvent(ctx *Context, view *Box, e interfaces.TouchEvent) bool {
+	if e.Phase == interfaces.TouchBegin {
+		if s.touching || !view.Contains(e.Position) {
+			return false
+		}
+		s.touch, s.touching, s.touchStart = e.ID, true, e.Position
+		if !s.Moving() {
+			// Left to the widgets under it until it moves
+			return false
+		}
+		s.startPan(ctx, e.Position)
+		return true
+	}
+	if !s.touching || e.ID != s.touch {
+		return false
+	}
+	r := rootOf(s)
+	switch e.Phase {
+	case interfaces.TouchMove:
+		if !s.panning {
+			dx, dy := abs32(e.Position.X-s.touchStart.X), abs32(e.Position.Y-s.touchStart.Y)
+			if !(s.horizontal && dx >= touchSlop) && !(s.vertical && dy >= touchSlop) {
+				return false
+			}
+			if r != nil {
+				// A nested scroll view took it first
+				if r.touchClaimed(e.ID) {
+					s.touching = false
+					return false
+				}
+				r.claimTouch(e.ID)
+			}
+			s.startPan(ctx, s.touchStart)
+		}
+		s.pan(ctx, e.Position)
+		return true
+	case interfaces.TouchEnd, interfaces.TouchCancel:
+		s.touching = false
+		if s.panning {
+			s.release(ctx, e.Position)
+			return true
+		}
+	}
+	return false
+}
+
+// press starts a thumb drag, or pages towards the cursor when the track
+// outside the thumb is clicked
+func (s *ScrollWidget) press(box *Box, at Point) bool {
+	for _, axis := range []scrollAxis{scrollAxisVertical, scrollAxisHorizontal} {
+		if (axis == scrollAxisVertical && !s.showV) || (axis == scrollAxisHorizontal && !s.showH) {
+			continue
+		}
+		track, thumb := s.bar(box, axis)
+		if !track.Contains(at) {
+			continue
+		}
+		if thumb.Contains(at) {
+			s.dragging = axis
+			s.grab = at
+			s.grabOffset = s.offset
+			s.MarkNeedsPaint()
+			return true
+		}
+		if axis == scrollAxisVertical {
+			if at.Y < thumb.Position.Y {
+				s.ScrollBy(0, -s.viewport.Height)
+			} else {
+				s.ScrollBy(0, s.viewport.Height)
+			}
+		} else {
+			if at.X < thumb.Position.X {
+				s.ScrollBy(-s.viewport.Width, 0)
+			} else {
+				s.ScrollBy(s.viewport.Width, 0)
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// drag moves the content so the held thumb follows the cursor
+func (s *ScrollWidget) drag(box *Box, at Point) {
+	track, thumb := s.bar(box, s.dragging)
+	if s.dragging == scrollAxisVertical {
+		travel := track.Size.Height - thumb.Size.Height
+		if travel <= 0 {
+			return
+		}
+		scale := (s.content.Height - s.viewport.Height) / travel
+		s.ScrollTo(s.offset.X, s.grabOffset.Y+(at.Y-s.grab.Y)*scale)
+		return
+	}
+	travel := track.Size.Width - thumb.Size.Width
+	if travel <= 0 {
+		return
+	}
+	scale := (s.content.Width - s.viewport.Width) / travel
+	s.ScrollTo(s.grabOffset.X+(at.X-s.grab.X)*scale, s.offset.Y)
+}
+
+// startPan starts dragging the content from a point, catching it if it
+// was moving
+func (s *ScrollWidget) startPan(ctx *Context, at Point) {
+	s.stop()
+	s.panning = true
+	s.grab = at
+	s.grabOffset = s.offset
+	s.tracker.reset()
+	s.tracker.add(frameTime(ctx), at)
+}
+
+// pan moves the dragged content with the pointer, pulling it past its ends
+// against increasing resistance when overscroll is allowed
+func (s *ScrollWidget) pan(ctx *Context, at Point) {
+	s.tracker.add(frameTime(ctx), at)
+	offset := s.offset
+	if s.horizontal {
+		offset.X = s.pulled(s.grabOffset.X-(at.X-s.grab.X), s.content.Width-s.viewport.Width, s.viewport.Width)
+	}
+	if s.vertical {
+		offset.Y = s.pulled(s.grabOffset.Y-(at.Y-s.grab.Y), s.content.Height-s.viewport.Height, s.viewport.Height)
+	}
+	if offset != s.offset {
+		s.offset = offset
+		s.MarkNeedsPaint()
+	}
+}
+
+// pulled returns where content dragged to an offset along an axis is shown,
+// given how far it scrolls and the viewport's extent
+func (s *ScrollWidget) pulled(offset, scrollable, extent float32) float32 {
+	end := min(max(offset, 0), max(scrollable, 0))
+	if !s.overscroll {
+		return end
+	}
+	return end + rubberBand(offset-end, extent)
+}
+
+// release lets go of the dragged content, flinging it at the pointer's
+// velocity or springing it back inside its ends
+func (s *ScrollWidget) release(ctx *Context, at Point) {
+	now := frameTime(ctx)
+	s.tracker.add(now, at)
+	s.panning = false
+	// The content moves against the pointer
+	v := s.tracker.velocity(now)
+	if s.horizontal {
+		s.releaseAxis(&s.motionX, now, s.offset.X, -v.X, s.content.Width-s.viewport.Width)
+	}
+	if s.vertical {
+		s.releaseAxis(&s.motionY, now, s.offset.Y, -v.Y, s.content.Height-s.viewport.Height)
+	}
+	s.MarkNeedsPaint()
+}
+
+// releaseAxis starts an axis moving on its own from an offset at a velocity
+func (s *ScrollWidget) releaseAxis(m *axisMotion, now time.Time, offset, velocity, scrollable float32) {
+	end := min(max(offset, 0), max(scrollable, 0))
+	switch {
+	case offset != end:
+		m.spring(now, end, offset-end, velocity)
+	case abs32(velocity) >= minFlingVelocity:
+		m.fling(now, offset, velocity)
+	}
+}
+
+// stop ends any drag, fling, spring or animation where the content is,
+// inside its ends
+func (s *ScrollWidget) stop() {
+	s.panning = false
+	s.motionX.kind, s.motionY.kind = motionNone, motionNone
+	s.animation = nil
+	if s.valid {
+		s.offset = s.clamp(s.offset)
+	}
+}
+
+// advance moves the content for the frame while it flings, springs back or
+// animates, asking for another frame until it comes to rest
+func (s *ScrollWidget) advance(ctx *Context) {
+	if s.panning || !s.Moving() {
+		return
+	}
+	now := frameTime(ctx)
+	offset := s.offset
+	if s.animation != nil {
+		offset = s.animation.Value(ctx.Clock)
+		if !s.animation.Running() {
+			s.animation = nil
+		}
+	}
+	if s.motionX.kind != motionNone {
+		offset.X = s.motionX.step(now, 0, max(s.content.Width-s.viewport.Width, 0), s.overscroll)
+	}
+	if s.motionY.kind != motionNone {
+		offset.Y = s.motionY.step(now, 0, max(s.content.Height-s.viewport.Height, 0), s.overscroll)
+	}
+	s.offset = offset
+	if s.Moving() {
+		ctx.Clock.Request()
+		s.MarkNeedsPaint()
+	}
+}
+
+// reveal scrolls the least distance that brings an absolute rect of the
+// content, as last painted, into the viewport
+func (s *ScrollWidget) reveal(rect Rect) {
+	// Convert to content coordinates
+	x := rect.X - s.paintBox.Position.X + s.offset.X
+	y := rect.Y - s.paintBox.Position.Y + s.offset.Y
+	offset := s.offset
+	if s.horizontal {
+		offset.X = min(max(offset.X, x+rect.Width-s.viewport.Width), x)
+	}
+	if s.vertical {
+		offset.Y = min(max(offset.Y, y+rect.Height-s.viewport.Height), y)
+	}
+	s.setOffset(offset)
+}
+
+// scrollIntoView scrolls the nearest scroll widget around a widget so an
+// absolute rect of the widget, as last painted, is visible
+func scrollIntoView(w Widget, rect Rect) {
+	for w != nil {
+		parented, ok := w.(interface{ Parent() Widget })
+		if !ok {
+			return
+		}
+		if w = parented.Parent(); w == nil {
+			return
+		}
+		if s, ok := w.(*ScrollWidget); ok {
+			s.reveal(rect)
+			return
+		}
+	}
+}
+
+// setOffset moves the content and repaints when the position changes,
+// stopping it if it was moving on its own. Offsets set before the first
+// layout are clamped once the content size is known.
+func (s *ScrollWidget) setOffset(offset Point) {
+	s.stop()
+	if s.valid {
+		offset = s.clamp(offset)
+	}
+	if offset == s.offset {
+		return
+	}
+	s.offset = offset
+	s.MarkNeedsPaint()
+}
+
+// stateKey implements stateful
+func (s *ScrollWidget) stateKey() string {
+	return s.key
+}
+
+// saveState implements stateful, saving the scroll position
+func (s *ScrollWidget) saveState() any {
+	return s.offset
+}
+
+// restoreState implements stateful, scrolling to the saved position
+func (s *ScrollWidget) restoreState(data json.RawMessage) (err error) {
+	var offset Point
+	if err = json.Unmarshal(data, &offset); err != nil {
+		return
+	}
+	s.ScrollTo(offset.X, offset.Y)
+	return
+}
+
+// clamp limits an offset to the range that keeps the viewport over the content
+func (s *ScrollWidget) clamp(offset Point) Point {
+	offset.X = min(max(offset.X, 0), max(s.content.Width-s.viewport.Width, 0))
+	offset.Y = min(max(offset.Y, 0), max(s.content.Height-s.viewport.Height, 0))
+	return offset
+}
+
+// viewportBox returns the absolute area the content is visible in
+func (s *ScrollWidget) viewportBox(box *Box) *Box {
+	return NewBox(box.Position.X, box.Position.Y, s.viewport.Width, s.viewport.Height, s.constraints)
+}
+
+// contentBox returns the child's absolute box, shifted by the scroll offset
+func (s *ScrollWidget) contentBox(box *Box) *Box {
+	return NewBox(
+		box.Position.X-s.offset.X,
+		box.Position.Y-s.offset.Y,
+		s.content.Width,
+		s.content.Height,
+		s.child.GetConstraints(),
+	)
+}
+
+// bar returns the absolute track and thumb boxes of a scrollbar
+func (s *ScrollWidget) bar(box *Box, axis scrollAxis) (track, thumb *Box) {
+	if axis == scrollAxisVertical {
+		track = NewBox(box.Position.X+s.viewport.Width, box.Position.Y, s.barWidth, s.viewport.Height, Constraints{})
+		length, position := thumbSpan(track.Size.Height, s.viewport.Height, s.content.Height, s.offset.Y)
+		thumb = NewBox(track.Position.X, track.Position.Y+position, s.barWidth, length, Constraints{})
+		return
+	}
+	track = NewBox(box.Position.X, box.Position.Y+s.viewport.Height, s.viewport.Width, s.barWidth, Constraints{})
+	length, position := thumbSpan(track.Size.Width, s.viewport.Width, s.content.Width, s.offset.X)
+	thumb = NewBox(track.Position.X+position, track.Position.Y, length, s.barWidth, Constraints{})
+	return
+}
+
+// thumbSpan returns the length and position along the track of a thumb
+// sized in proportion to the visible part of the content
+func thumbSpan(track, visible, content, offset float32) (length, position float32) {
+	if content <= 0 || track <= 0 {
+		return track, 0
+	}
+	length = min(max(track*visible/content, minThumbLength), track)
+	if scrollable := content - visible; scrollable > 0 {
+		// Content pulled past its ends leaves the thumb at the end
+		position = (track - length) * min(max(offset/scrollable, 0), 1)
+	}
+	return
+}
@@ -0,0 +1,169 @@
+package widget
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// velocityWindow is how far back the pointer's movement is sampled to
+	// estimate its velocity
+	velocityWindow = 100 * time.Millisecond
+	// flingTimeConstant is how quickly a flung scroll slows down, the time
+	// its velocity takes to fall to about a third
+	flingTimeConstant = 0.325
+	// minFlingVelocity is the speed in pixels a second below which a
+	// released scroll stops rather than coasting
+	minFlingVelocity = 50
+	// springStiffness sets how quickly content pulled past its ends
+	// returns, the rate of the critically damped spring pulling it back
+	springStiffness = 12
+	// rubberBandResistance is how strongly content resists being pulled
+	// past its ends, as a fraction of the viewport
+	rubberBandResistance = 0.55
+)
+
+// velocitySample is a position at a time
+type velocitySample struct {
+	at       time.Time
+	position Point
+}
+
+// velocityTracker estimates how fast a pointer is moving from its recent
+// positions
+type velocityTracker struct {
+	samples []velocitySample
+}
+
+// reset forgets the samples
+func (v *velocityTracker) reset() {
+	v.samples = v.samples[:0]
+}
+
+// add samples a position at a time, forgetting samples too old to count
+func (v *velocityTracker) add(at time.Time, position Point) {
+	n := len(v.samples)
+	if n > 0 && v.samples[n-1].at.Equal(at) {
+		// Events delivered in the same frame share its time
+		v.samples[n-1].position = position
+	} else {
+		v.samples = append(v.samples, velocitySample{at: at, position: position})
+	}
+	i := 0
+	for i < len(v.samples)-1 && at.Sub(v.samples[i].at) > velocityWindow {
+		i++
+	}
+	v.samples = v.samples[i:]
+}
+
+// velocity returns the velocity in pixels a second over the samples, zero
+// when the pointer has not moved for the length of the window
+func (v *velocityTracker) velocity(now time.Time) (velocity Point) {
+	n := len(v.samples)
+	if n < 2 || now.Sub(v.samples[n-1].at) > velocityWindow {
+		return
+	}
+	first, last := v.samples[0], v.samples[n-1]
+	dt := float32(last.at.Sub(first.at).Seconds())
+	if dt <= 0 {
+		return
+	}
+	return Point{
+		X: (last.position.X - first.position.X) / dt,
+		Y: (last.position.Y - first.position.Y) / dt,
+	}
+}
+
+// motionKind is how an axis of a scroll view moves on its own
+type motionKind int
+
+const (
+	motionNone motionKind = iota
+	// motionFling coasts, slowing down
+	motionFling
+	// motionSpring returns to an end of the range past which it was
+	// pulled or flung
+	motionSpring
+)
+
+// axisMotion moves the scroll position along one axis after the content is
+// released, as a function of the time since it started
+type axisMotion struct {
+	kind  motionKind
+	start time.Time
+	// from is where a fling started, or the end a spring returns to
+	from float32
+	// displacement is how far past the end a spring started
+	displacement float32
+	velocity     float32
+}
+
+// fling starts coasting from a position at a velocity
+func (m *axisMotion) fling(now time.Time, from, velocity float32) {
+	*m = axisMotion{kind: motionFling, start: now, from: from, velocity: velocity}
+}
+
+// spring starts returning to an end from a displacement past it, moving at
+// a velocity
+func (m *axisMotion) spring(now time.Time, end, displacement, velocity float32) {
+	*m = axisMotion{kind: motionSpring, start: now, from: end, displacement: displacement, velocity: velocity}
+}
+
+// step returns the position at a time within a range, which content pulled
+// past springs back into when overscroll is allowed and stops at when it
+// is not, and stops the motion once it comes to rest
+func (m *axisMotion) step(now time.Time, lo, hi float32, overscroll bool) (position float32) {
+	t := float32(now.Sub(m.start).Seconds())
+	switch m.kind {
+	case motionFling:
+		decay := float32(math.Exp(float64(-t / flingTimeConstant)))
+		position = m.from + m.velocity*flingTimeConstant*(1-decay)
+		velocity := m.velocity * decay
+		if position < lo || position > hi {
+			end := min(max(position, lo), hi)
+			if !overscroll {
+				m.kind = motionNone
+				return end
+			}
+			// Bounce off the end with the velocity the content hit it at
+			m.spring(now, end, position-end, velocity)
+			return position
+		}
+		if abs32(velocity) < minFlingVelocity {
+			m.kind = motionNone
+		}
+	case motionSpring:
+		// A critically damped spring, which returns without oscillating
+		decay := float32(math.Exp(float64(-springStiffness * t)))
+		b := m.velocity + springStiffness*m.displacement
+		displacement := (m.displacement + b*t) * decay
+		velocity := (b - springStiffness*(m.displacement+b*t)) * decay
+		position = m.from + displacement
+		if abs32(displacement) < 0.5 && abs32(velocity) < minFlingVelocity {
+			m.kind = motionNone
+			position = m.from
+		}
+	}
+	return
+}
+
+// rubberBand returns how far content pulled a distance past its end moves,
+// which approaches the extent of the viewport the further it is pulled
+func rubberBand(distance, extent float32) float32 {
+	if extent <= 0 {
+		return 0
+	}
+	sign := float32(1)
+	if distance < 0 {
+		sign, distance = -1, -distance
+	}
+	return sign * (1 - 1/(distance*rubberBandResistance/extent+1)) * extent
+}
+
+// abs32 returns the absolute value of a number
+func abs32(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
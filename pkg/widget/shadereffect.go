@@ -0,0 +1,172 @@
+package widget
+
+import (
+	"time"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/render"
+	"lol.mleku.dev/chk"
+)
+
+// ShaderEffectWidget renders its child into a texture and draws it through
+// a GLSL fragment shader, for effects such as blurs, grayscale, color
+// grading and transitions. The shader's source defines
+//
+//	vec4 effect(vec2 uv)
+//
+// returning the color at uv, from 0 to 1 across the box, usually computed
+// by sampling the child with texture(tex, uv). Colors are premultiplied by
+// alpha. Besides the uniforms set with Uniform, the source may declare
+//
+//	uniform float time;       // seconds since the effect was first drawn
+//	uniform vec2 resolution;  // the size of the box in pixels
+//	uniform vec2 mouse;       // the cursor in pixels from the box's top left, -1 outside
+//
+// The child is rendered again only when it changes, while the shader runs
+// whenever the effect is drawn. Effects that change with time are drawn on
+// every frame once animated. Without GL, or when the source does not
+// compile, the child is drawn unaffected.
+type ShaderEffectWidget struct {
+	Base
+	child    Widget
+	layer    childLayer
+	shader   *render.Shader
+	uniforms []render.Uniform
+	animate  bool
+	// start is when the effect was first drawn and mouse where the cursor
+	// was last seen over it, -1 when outside
+	start time.Time
+	mouse Point
+}
+
+// ShaderEffect creates a new effect drawing the child through a shader
+// with the source of its effect function
+func ShaderEffect(child Widget, source string) *ShaderEffectWidget {
+	e := &ShaderEffectWidget{
+		child:  child,
+		layer:  newChildLayer(),
+		shader: render.NewShader(source),
+		mouse:  Point{X: -1, Y: -1},
+	}
+	adopt(e, child)
+	return e
+}
+
+// Uniform sets a uniform of one to four floats the shader declares as a
+// float or a vec2, vec3 or vec4, and returns the effect for chaining
+func (e *ShaderEffectWidget) Uniform(name string, values ...float32) *ShaderEffectWidget {
+	for i := range e.uniforms {
+		if e.uniforms[i].Name == name {
+			e.uniforms[i].Value = values
+			e.MarkNeedsPaint()
+			return e
+		}
+	}
+	e.uniforms = append(e.uniforms, render.Uniform{Name: name, Value: values})
+	e.MarkNeedsPaint()
+	return e
+}
+
+// Animate draws the effect on every frame while set, for shaders that
+// change with time, and returns the effect for chaining
+func (e *ShaderEffectWidget) Animate(animate bool) *ShaderEffectWidget {
+	e.animate = animate
+	e.MarkNeedsPaint()
+	return e
+}
+
+// Shader returns the shader the child is drawn through
+func (e *ShaderEffectWidget) Shader() *render.Shader {
+	return e.shader
+}
+
+// GetConstraints returns the child's constraints
+func (e *ShaderEffectWidget) GetConstraints() Constraints {
+	if e.child == nil {
+		return NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return e.child.GetConstraints()
+}
+
+// Measure returns the size the child measures
+func (e *ShaderEffectWidget) Measure(constraints Constraints) Size {
+	return measure(e.child, constraints)
+}
+
+// Layout implements the Widget interface for ShaderEffectWidget; the child
+// is laid out in the widget's box
+func (e *ShaderEffectWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !e.NeedsLayout(ctx, constraints) {
+		return e.CachedSize(), nil
+	}
+	e.layer.dirty = true
+	if size, err = layoutInset(ctx, e.child, Insets{}, constraints); chk.E(err) {
+		return
+	}
+	e.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for ShaderEffectWidget
+func (e *ShaderEffectWidget) Paint(ctx *Context, box *Box) (err error) {
+	t, width, height, err := e.layer.draw(ctx, box, e.child)
+	if t == nil || chk.E(err) {
+		return
+	}
+	now := frameTime(ctx)
+	if e.start.IsZero() {
+		e.start = now
+	}
+	scale := e.layer.scale
+	mouse := e.mouse
+	if mouse.X >= 0 {
+		mouse = Point{X: mouse.X * scale, Y: mouse.Y * scale}
+	}
+	uniforms := append([]render.Uniform{
+		{Name: "time", Value: []float32{float32(now.Sub(e.start).Seconds())}},
+		{Name: "resolution", Value: []float32{float32(t.Width), float32(t.Height)}},
+		{Name: "mouse", Value: []float32{mouse.X, mouse.Y}},
+	}, e.uniforms...)
+	ctx.DrawList.Effect(e.shader, uniforms, t, box.Position.X, box.Position.Y, width, height)
+	if e.animate {
+		e.MarkNeedsPaint()
+	}
+	return
+}
+
+// cache implements layered
+func (e *ShaderEffectWidget) cache() *childLayer {
+	return &e.layer
+}
+
+// HandleEvent implements the Widget interface for ShaderEffectWidget,
+// following the cursor for the shader
+func (e *ShaderEffectWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch m := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		mouse := Point{X: -1, Y: -1}
+		if box.Contains(m.Position) {
+			mouse = Point{X: m.Position.X - box.Position.X, Y: m.Position.Y - box.Position.Y}
+		}
+		if mouse != e.mouse {
+			e.mouse = mouse
+			e.MarkNeedsPaint()
+		}
+	case interfaces.CursorLeaveEvent:
+		if e.mouse.X >= 0 {
+			e.mouse = Point{X: -1, Y: -1}
+			e.MarkNeedsPaint()
+		}
+	}
+	if e.child == nil {
+		return false
+	}
+	return routeEvent(ctx, e.child, box, ev)
+}
+
+// addDamage implements damageSink, rendering the child again before
+// passing the region on
+func (e *ShaderEffectWidget) addDamage(rect Rect) {
+	e.layer.dirty = true
+	e.invalidate(rect)
+}
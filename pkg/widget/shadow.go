@@ -0,0 +1,150 @@
+package widget
+
+import (
+	"lol.mleku.dev/chk"
+)
+
+// ShadowWidget draws a soft shadow under its child, offset from it and
+// blurred, so raised surfaces such as cards stand out from the background.
+// The shadow is painted outside the widget's box without taking space in the
+// layout; give the widget a margin at least as wide as the shadow so the
+// container around it repaints the whole shadow.
+type ShadowWidget struct {
+	Base
+	child  Widget
+	dx, dy float32
+	blur   float32
+	radius float32
+	color  colorOverride
+}
+
+// Shadow creates a new shadow under the child, 2 pixels down and blurred
+// over 8 pixels, in the theme's shadow color
+func Shadow(child Widget) *ShadowWidget {
+	s := &ShadowWidget{
+		child: child,
+		dy:    2,
+		blur:  8,
+	}
+	adopt(s, child)
+	return s
+}
+
+// Elevation creates a new shadow under the child for a surface raised by a
+// number of levels. Higher surfaces cast larger, softer shadows further down.
+func Elevation(child Widget, level float32) *ShadowWidget {
+	dy, blur := elevation(level)
+	return Shadow(child).Offset(0, dy).Blur(blur)
+}
+
+// Offset moves the shadow from under the child and returns the shadow for chaining
+func (s *ShadowWidget) Offset(dx, dy float32) *ShadowWidget {
+	s.MarkNeedsPaint()
+	s.dx, s.dy = dx, dy
+	s.MarkNeedsPaint()
+	return s
+}
+
+// Blur sets the distance over which the shadow's edges fade out and returns
+// the shadow for chaining
+func (s *ShadowWidget) Blur(blur float32) *ShadowWidget {
+	s.MarkNeedsPaint()
+	s.blur = max(blur, 0)
+	s.MarkNeedsPaint()
+	return s
+}
+
+// Radius rounds the corners of the shadow to match a rounded child and
+// returns the shadow for chaining
+func (s *ShadowWidget) Radius(radius float32) *ShadowWidget {
+	s.radius = radius
+	s.MarkNeedsPaint()
+	return s
+}
+
+// Color sets the color of the shadow and returns the shadow for chaining
+func (s *ShadowWidget) Color(red, green, blue, alpha float32) *ShadowWidget {
+	s.color = override([4]float32{red, green, blue, alpha})
+	s.MarkNeedsPaint()
+	return s
+}
+
+// MarkNeedsPaint schedules the widget to be repainted along with its shadow
+func (s *ShadowWidget) MarkNeedsPaint() {
+	s.invalidate(s.paintBounds(&s.paintBox))
+}
+
+// GetConstraints returns the child's constraints; the shadow takes no space
+func (s *ShadowWidget) GetConstraints() Constraints {
+	if s.child == nil {
+		return NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return s.child.GetConstraints()
+}
+
+// Measure returns the size the child measures
+func (s *ShadowWidget) Measure(constraints Constraints) Size {
+	return measure(s.child, constraints)
+}
+
+// Layout implements the Widget interface for ShadowWidget; the child is laid
+// out in the widget's box
+func (s *ShadowWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !s.NeedsLayout(ctx, constraints) {
+		return s.CachedSize(), nil
+	}
+	if size, err = layoutInset(ctx, s.child, Insets{}, constraints); chk.E(err) {
+		return
+	}
+	s.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for ShadowWidget
+func (s *ShadowWidget) Paint(ctx *Context, box *Box) (err error) {
+	ctx.DrawList.Shadow(box.Position.X+s.dx, box.Position.Y+s.dy, box.Size.Width, box.Size.Height,
+		s.radius, s.blur, s.color.or(themeOf(ctx).Shadow))
+	if s.child == nil {
+		return
+	}
+	return paintChild(ctx, s.child, box)
+}
+
+// HandleEvent implements the Widget interface for ShadowWidget
+func (s *ShadowWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if s.child == nil {
+		return false
+	}
+	return routeEvent(ctx, s.child, box, ev)
+}
+
+// paintBounds implements overflowing, returning the box along with the
+// shadow around it
+func (s *ShadowWidget) paintBounds(box *Box) Rect {
+	return shadowBounds(box, s.dx, s.dy, s.blur)
+}
+
+// elevation returns the downwards offset and the blur of the shadow cast by
+// a surface raised by a number of levels
+func elevation(level float32) (dy, blur float32) {
+	return level, 3 * level
+}
+
+// shadowBounds returns the region covered by a box and the shadow it casts
+// with an offset and blur
+func shadowBounds(box *Box, dx, dy, blur float32) Rect {
+	r := box.Rect()
+	return r.Union(Rect{
+		X:      r.X + dx - blur,
+		Y:      r.Y + dy - blur,
+		Width:  r.Width + 2*blur,
+		Height: r.Height + 2*blur,
+	})
+}
+
+// paintElevated paints the shadow a surface raised by a number of levels
+// casts, with corners rounded to radius
+func paintElevated(ctx *Context, box *Box, level, radius float32) {
+	dy, blur := elevation(level)
+	ctx.DrawList.Shadow(box.Position.X, box.Position.Y+dy, box.Size.Width, box.Size.Height, radius, blur, themeOf(ctx).Shadow)
+}
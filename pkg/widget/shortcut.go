@@ -0,0 +1,109 @@
+package widget
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mleku/goo/pkg/interfaces"
+)
+
+// shortcutMods are the modifiers that distinguish shortcuts; lock keys are ignored
+const shortcutMods = interfaces.ModShift | interfaces.ModControl | interfaces.ModAlt | interfaces.ModSuper
+
+// keyNames are the names shown for keys other than letters and digits
+var keyNames = map[interfaces.Key]string{
+	interfaces.KeySpace:     "Space",
+	interfaces.KeyEscape:    "Esc",
+	interfaces.KeyEnter:     "Enter",
+	interfaces.KeyTab:       "Tab",
+	interfaces.KeyBackspace: "Backspace",
+	interfaces.KeyInsert:    "Ins",
+	interfaces.KeyDelete:    "Del",
+	interfaces.KeyRight:     "Right",
+	interfaces.KeyLeft:      "Left",
+	interfaces.KeyDown:      "Down",
+	interfaces.KeyUp:        "Up",
+	interfaces.KeyPageUp:    "PgUp",
+	interfaces.KeyPageDown:  "PgDn",
+	interfaces.KeyHome:      "Home",
+	interfaces.KeyEnd:       "End",
+}
+
+// Shortcut is a key pressed with a set of modifiers, such as Ctrl+S, that
+// chooses a menu item from anywhere in the window
+type Shortcut struct {
+	Key  interfaces.Key
+	Mods interfaces.Modifier
+}
+
+// String returns the shortcut as shown in menus, such as "Ctrl+Shift+S"
+func (s Shortcut) String() string {
+	var b strings.Builder
+	for _, m := range []struct {
+		mod  interfaces.Modifier
+		name string
+	}{
+		{interfaces.ModControl, "Ctrl+"},
+		{interfaces.ModAlt, "Alt+"},
+		{interfaces.ModShift, "Shift+"},
+		{interfaces.ModSuper, "Super+"},
+	} {
+		if s.Mods&m.mod != 0 {
+			b.WriteString(m.name)
+		}
+	}
+	switch r := keyRune(s.Key); {
+	case r != 0:
+		b.WriteRune(r)
+	case s.Key >= interfaces.KeyF1 && s.Key <= interfaces.KeyF12:
+		b.WriteString("F" + strconv.Itoa(int(s.Key-interfaces.KeyF1+1)))
+	default:
+		b.WriteString(keyNames[s.Key])
+	}
+	return b.String()
+}
+
+// matches reports whether a key press triggers the shortcut
+func (s Shortcut) matches(e interfaces.KeyEvent) bool {
+	return s.Key != 0 && e.Action == interfaces.ActionPress &&
+		e.Key == s.Key && e.Mods&shortcutMods == s.Mods&shortcutMods
+}
+
+// keyRune returns the character on a letter or digit key, 0 for other keys.
+// Letter keys are numbered by their upper case character.
+func keyRune(k interfaces.Key) rune {
+	if k >= 'A' && k <= 'Z' || k >= '0' && k <= '9' {
+		return rune(k)
+	}
+	return 0
+}
+
+// shortcutHandler is implemented by widgets acting on key presses wherever
+// the focus is, such as menu bars
+type shortcutHandler interface {
+	Widget
+	shortcut(e interfaces.KeyEvent) (handled bool)
+}
+
+// addShortcuts offers the handler the key presses the focus owner leaves unhandled
+func (r *RootWidget) addShortcuts(h shortcutHandler) {
+	if !slices.Contains(r.shortcuts, h) {
+		r.shortcuts = append(r.shortcuts, h)
+	}
+}
+
+// shortcut offers a key press to the shortcut handlers still in the tree
+// until one handles it
+func (r *RootWidget) shortcut(e interfaces.KeyEvent) (handled bool) {
+	if e.Action != interfaces.ActionPress {
+		return false
+	}
+	r.shortcuts = slices.DeleteFunc(r.shortcuts, func(h shortcutHandler) bool { return rootOf(h) != r })
+	for _, h := range r.shortcuts {
+		if h.shortcut(e) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,399 @@
+package widget
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/text"
+)
+
+const (
+	// sliderThumb is the diameter of a slider's thumb
+	sliderThumb = 16
+	// sliderTrack is the thickness of a slider's track
+	sliderTrack = 4
+	// sliderPageSteps is how many steps page up and page down move a slider
+	sliderPageSteps = 10
+	// sliderTipSize is the text size of the value tooltip and sliderTipPad
+	// the space around the text
+	sliderTipSize = 14
+	sliderTipPad  = 4
+)
+
+// SliderWidget selects a number in a range by dragging a thumb along a
+// track. Clicking the track jumps the thumb to the cursor, and the arrow,
+// page, home and end keys adjust the value while the slider has focus.
+type SliderWidget struct {
+	Base
+	minimum, maximum float32
+	step             float32
+	value            float32
+	vertical         bool
+	onChange         func(value float32)
+	hovered          bool
+	// dragging is set while the thumb is held and grab is the distance from
+	// the cursor to the thumb center along the track when it was grabbed
+	dragging bool
+	grab     float32
+	// font draws the value tooltip while dragging, none when nil
+	font   *text.Font
+	format func(value float32) string
+}
+
+// Slider creates a new horizontal slider selecting a value from minimum to
+// maximum, starting at minimum. Values are continuous until a step is set.
+func Slider(minimum, maximum float32) *SliderWidget {
+	return &SliderWidget{
+		minimum: minimum,
+		maximum: maximum,
+		value:   minimum,
+		format:  formatSliderValue,
+	}
+}
+
+// Vertical sets whether the track runs from the bottom up instead of left to
+// right and returns the slider for chaining
+func (s *SliderWidget) Vertical(vertical bool) *SliderWidget {
+	s.vertical = vertical
+	s.MarkNeedsLayout()
+	return s
+}
+
+// Step sets the increment values snap to, zero for continuous values, and
+// returns the slider for chaining
+func (s *SliderWidget) Step(step float32) *SliderWidget {
+	s.step = max(step, 0)
+	s.value = s.snap(s.value)
+	s.MarkNeedsPaint()
+	return s
+}
+
+// OnChange sets the callback invoked with the new value when the user moves
+// the slider and returns the slider for chaining
+func (s *SliderWidget) OnChange(fn func(value float32)) *SliderWidget {
+	s.onChange = fn
+	return s
+}
+
+// Tooltip shows the value in a tooltip drawn with the given font while the
+// thumb is dragged and returns the slider for chaining. The format function
+// converts the value to text and may be nil to show the number as is.
+func (s *SliderWidget) Tooltip(font *text.Font, format func(value float32) string) *SliderWidget {
+	s.font = font
+	s.format = format
+	if format == nil {
+		s.format = formatSliderValue
+	}
+	return s
+}
+
+// SetValue moves the slider to a value, snapped to the step and clamped to
+// the range, without invoking the change callback
+func (s *SliderWidget) SetValue(value float32) {
+	s.move(s.snap(value))
+}
+
+// Value returns the selected value
+func (s *SliderWidget) Value() float32 {
+	return s.value
+}
+
+// GetConstraints returns a thickness that fits the thumb across the track
+func (s *SliderWidget) GetConstraints() Constraints {
+	if s.vertical {
+		return NewFlexConstraints(sliderThumb, 2*sliderThumb, sliderThumb, 1e9)
+	}
+	return NewFlexConstraints(2*sliderThumb, sliderThumb, 1e9, sliderThumb)
+}
+
+// Measure returns the minimum size of the slider
+func (s *SliderWidget) Measure(constraints Constraints) Size {
+	return minSize(s.GetConstraints())
+}
+
+// Layout implements the Widget interface for SliderWidget; sliders take all
+// the space offered and center the track across it
+func (s *SliderWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	s.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for SliderWidget
+func (s *SliderWidget) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	list := ctx.DrawList
+	thumb := s.thumbBox(box, s.value)
+	cx := thumb.Position.X + sliderThumb/2
+	cy := thumb.Position.Y + sliderThumb/2
+
+	// Fill the track up to the thumb in the accent color
+	if s.vertical {
+		x := float32(math.Round(float64(cx - sliderTrack/2)))
+		top, bottom := box.Position.Y+sliderThumb/2, box.Position.Y+box.Size.Height-sliderThumb/2
+		list.RoundRect(x, top, sliderTrack, bottom-top, sliderTrack/2, th.Track)
+		list.RoundRect(x, cy, sliderTrack, bottom-cy, sliderTrack/2, th.Primary)
+	} else {
+		y := float32(math.Round(float64(cy - sliderTrack/2)))
+		left, right := box.Position.X+sliderThumb/2, box.Position.X+box.Size.Width-sliderThumb/2
+		list.RoundRect(left, y, right-left, sliderTrack, sliderTrack/2, th.Track)
+		list.RoundRect(left, y, cx-left, sliderTrack, sliderTrack/2, th.Primary)
+	}
+
+	border, fill := th.Border, th.Surface
+	switch {
+	case s.dragging:
+		border, fill = th.Primary, th.SurfacePressed
+	case hasFocus(s):
+		border = th.Primary
+	}
+	if s.hovered && !s.dragging {
+		fill = th.SurfaceHover
+	}
+	fillBordered(ctx, thumb, sliderThumb/2, border, fill)
+
+	if s.dragging && s.font != nil {
+		s.paintTooltip(ctx, box)
+	}
+	return
+}
+
+// paintTooltip draws the value in a bubble beside the thumb, outside the slider's box
+func (s *SliderWidget) paintTooltip(ctx *Context, box *Box) {
+	th := themeOf(ctx)
+	face := s.font.Face(sliderTipSize)
+	r := s.tooltipRect(box, s.value)
+	tip := NewBox(r.X, r.Y, r.Width, r.Height, Constraints{})
+	fillBordered(ctx, tip, th.Radius.Small, th.Border, th.Surface)
+	face.Draw(ctx.DrawList, r.X+sliderTipPad, r.Y+sliderTipPad+face.Ascent(), s.format(s.value), th.Text)
+}
+
+// navigable lets gamepad navigation focus the slider, which the d-pad then
+// adjusts along its track
+func (s *SliderWidget) navigable() bool {
+	return true
+}
+
+// takesArrow reports whether an arrow key runs along the slider's track,
+// moving the thumb rather than focus
+func (s *SliderWidget) takesArrow(key interfaces.Key) bool {
+	if s.vertical {
+		return key == interfaces.KeyUp || key == interfaces.KeyDown
+	}
+	return key == interfaces.KeyLeft || key == interfaces.KeyRight
+}
+
+// Accessibility implements Accessible
+func (s *SliderWidget) Accessibility() AccessNode {
+	return AccessNode{
+		Role:    interfaces.RoleSlider,
+		Value:   formatSliderValue(s.value),
+		Numeric: float64(s.value),
+		Min:     float64(min(s.minimum, s.maximum)),
+		Max:     float64(max(s.minimum, s.maximum)),
+		Actions: []AccessAction{interfaces.AccessIncrement, interfaces.AccessDecrement, interfaces.AccessSetValue},
+	}
+}
+
+// AccessAction implements AccessActor, stepping the value as the arrow keys
+// do or setting it to a number
+func (s *SliderWidget) AccessAction(ctx *Context, action AccessAction, value string) bool {
+	step := s.step
+	if step == 0 {
+		step = (s.maximum - s.minimum) / 100
+	}
+	switch action {
+	case interfaces.AccessIncrement:
+		s.change(s.value + step)
+	case interfaces.AccessDecrement:
+		s.change(s.value - step)
+	case interfaces.AccessSetValue:
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 32)
+		if err != nil {
+			return false
+		}
+		s.change(float32(v))
+	default:
+		return false
+	}
+	return true
+}
+
+// HandleEvent implements the Widget interface for SliderWidget
+func (s *SliderWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		if s.dragging {
+			s.change(s.valueAt(box, s.along(box, e.Position)-s.grab))
+			return true
+		}
+		s.setHovered(s.thumbBox(box, s.value).Contains(e.Position))
+	case interfaces.CursorLeaveEvent:
+		s.setHovered(false)
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft {
+			return false
+		}
+		switch e.Action {
+		case interfaces.ActionPress:
+			// Presses are only delivered when the cursor is inside the box.
+			// Grabbing the thumb keeps it under the cursor where it was
+			// held, and clicking the track jumps it to the cursor.
+			requestFocus(s)
+			thumb := s.thumbBox(box, s.value)
+			s.grab = 0
+			if thumb.Contains(e.Position) {
+				s.grab = s.along(box, e.Position) - s.along(box, Point{X: thumb.Position.X + sliderThumb/2, Y: thumb.Position.Y + sliderThumb/2})
+			}
+			s.dragging = true
+			s.damage(s.value)
+			s.change(s.valueAt(box, s.along(box, e.Position)-s.grab))
+			return true
+		case interfaces.ActionRelease:
+			if !s.dragging {
+				return false
+			}
+			s.dragging = false
+			s.damage(s.value)
+			return true
+		}
+	case interfaces.KeyEvent:
+		if !hasFocus(s) || e.Action == interfaces.ActionRelease {
+			return false
+		}
+		step := s.step
+		if step == 0 {
+			step = (s.maximum - s.minimum) / 100
+		}
+		switch e.Key {
+		case interfaces.KeyRight, interfaces.KeyUp:
+			s.change(s.value + step)
+		case interfaces.KeyLeft, interfaces.KeyDown:
+			s.change(s.value - step)
+		case interfaces.KeyPageUp:
+			s.change(s.value + sliderPageSteps*step)
+		case interfaces.KeyPageDown:
+			s.change(s.value - sliderPageSteps*step)
+		case interfaces.KeyHome:
+			s.change(s.minimum)
+		case interfaces.KeyEnd:
+			s.change(s.maximum)
+		default:
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// change moves the slider on behalf of the user, invoking the change
+// callback when the value changed
+func (s *SliderWidget) change(value float32) {
+	if s.move(s.snap(value)) && s.onChange != nil {
+		s.onChange(s.value)
+	}
+}
+
+// move sets an already snapped value, repainting and reporting whether it changed
+func (s *SliderWidget) move(value float32) (changed bool) {
+	if value == s.value {
+		return false
+	}
+	s.damage(s.value)
+	s.value = value
+	s.damage(s.value)
+	return true
+}
+
+// damage repaints the slider along with its tooltip at a value. The tooltip
+// lies outside the box, so both are invalidated together so the region also
+// covers the slider that paints it.
+func (s *SliderWidget) damage(value float32) {
+	r := s.paintBox.Rect()
+	if s.font != nil {
+		r = r.Union(s.tooltipRect(&s.paintBox, value))
+	}
+	s.invalidate(r)
+}
+
+// snap rounds a value to the nearest step and clamps it to the range
+func (s *SliderWidget) snap(value float32) float32 {
+	lo, hi := min(s.minimum, s.maximum), max(s.minimum, s.maximum)
+	if s.step > 0 {
+		value = s.minimum + float32(math.Round(float64((value-s.minimum)/s.step)))*s.step
+	}
+	return min(max(value, lo), hi)
+}
+
+// fraction returns how far along the range a value lies, from 0 to 1
+func (s *SliderWidget) fraction(value float32) float32 {
+	if s.maximum == s.minimum {
+		return 0
+	}
+	return min(max((value-s.minimum)/(s.maximum-s.minimum), 0), 1)
+}
+
+// along returns the distance of a point from the start of the track in the
+// direction values increase
+func (s *SliderWidget) along(box *Box, p Point) float32 {
+	if s.vertical {
+		return box.Position.Y + box.Size.Height - sliderThumb/2 - p.Y
+	}
+	return p.X - box.Position.X - sliderThumb/2
+}
+
+// valueAt returns the value at a distance along the track
+func (s *SliderWidget) valueAt(box *Box, distance float32) float32 {
+	length := box.Size.Width - sliderThumb
+	if s.vertical {
+		length = box.Size.Height - sliderThumb
+	}
+	if length <= 0 {
+		return s.minimum
+	}
+	return s.minimum + distance/length*(s.maximum-s.minimum)
+}
+
+// thumbBox returns the box of the thumb at a value
+func (s *SliderWidget) thumbBox(box *Box, value float32) *Box {
+	f := s.fraction(value)
+	if s.vertical {
+		x := box.Position.X + (box.Size.Width-sliderThumb)/2
+		y := box.Position.Y + (1-f)*(box.Size.Height-sliderThumb)
+		return NewBox(x, y, sliderThumb, sliderThumb, Constraints{})
+	}
+	x := box.Position.X + f*(box.Size.Width-sliderThumb)
+	y := box.Position.Y + (box.Size.Height-sliderThumb)/2
+	return NewBox(x, y, sliderThumb, sliderThumb, Constraints{})
+}
+
+// tooltipRect returns where the tooltip for a value is drawn, above the
+// thumb of a horizontal slider and left of the thumb of a vertical one
+func (s *SliderWidget) tooltipRect(box *Box, value float32) Rect {
+	face := s.font.Face(sliderTipSize)
+	pad := float32(sliderTipPad)
+	w := float32(math.Ceil(float64(face.Measure(s.format(value))))) + 2*pad
+	h := float32(math.Ceil(float64(face.LineHeight()))) + 2*pad
+	thumb := s.thumbBox(box, value)
+	x, y := thumb.Position.X+(sliderThumb-w)/2, thumb.Position.Y-h-pad
+	if s.vertical {
+		x, y = thumb.Position.X-w-pad, thumb.Position.Y+(sliderThumb-h)/2
+	}
+	// Snap to whole pixels so the text is not blurred
+	return Rect{X: float32(math.Round(float64(x))), Y: float32(math.Round(float64(y))), Width: w, Height: h}
+}
+
+// setHovered updates whether the cursor is over the thumb, repainting when it changes
+func (s *SliderWidget) setHovered(hovered bool) {
+	if hovered != s.hovered {
+		s.hovered = hovered
+		s.MarkNeedsPaint()
+	}
+}
+
+// formatSliderValue is the default tooltip text, the value with at most two decimals
+func formatSliderValue(value float32) string {
+	return strconv.FormatFloat(math.Round(float64(value)*100)/100, 'f', -1, 32)
+}
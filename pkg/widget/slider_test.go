@@ -0,0 +1,77 @@
+package widget_test
+
+import (
+	"testing"
+
+	"github.com/mleku/goo/pkg/headless"
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/widget"
+)
+
+func TestSliderKeys(t *testing.T) {
+	tests := []struct {
+		key  interfaces.Key
+		want float32
+	}{
+		{interfaces.KeyRight, 60},
+		{interfaces.KeyUp, 70},
+		{interfaces.KeyLeft, 60},
+		{interfaces.KeyPageDown, 0},
+		{interfaces.KeyDown, 0},
+		{interfaces.KeyEnd, 100},
+		{interfaces.KeyRight, 100},
+		{interfaces.KeyHome, 0},
+	}
+	var changes []float32
+	s := widget.Slider(0, 100).Step(10).OnChange(func(v float32) { changes = append(changes, v) })
+	s.SetValue(50)
+	h := newHarness(t, widget.Column().Rigid(widget.NewFixedSize(200, 30, s)), 220, 40)
+	h.root.SetFocus(s)
+	for _, tt := range tests {
+		h.key(tt.key, 0)
+		if got := s.Value(); got != tt.want {
+			t.Errorf("after %v: value %g, want %g", tt.key, got, tt.want)
+		}
+	}
+	// Keys that leave the value as it was do not report a change
+	if want := 6; len(changes) != want {
+		t.Errorf("%d changes %v, want %d", len(changes), changes, want)
+	}
+}
+
+func TestSliderDrag(t *testing.T) {
+	var changes int
+	s := widget.Slider(0, 10).Step(1).OnChange(func(float32) { changes++ })
+	h := newHarness(t, widget.Column().Rigid(widget.NewFixedSize(200, 30, s)), 220, 40)
+	at := h.find(s)
+	left := interfaces.Point{X: 1, Y: at.Y}
+	right := interfaces.Point{X: 199, Y: at.Y}
+
+	// Clicking the track jumps the thumb to the pointer
+	h.press(left)
+	if got := s.Value(); got != 0 {
+		t.Errorf("pressed at the left end: value %g, want 0", got)
+	}
+	before := h.img
+	h.move(interfaces.Point{X: 100, Y: at.Y})
+	if got := s.Value(); got != 5 {
+		t.Errorf("dragged to the middle: value %g, want 5", got)
+	}
+	if headless.Diff(before, h.img, 0) == 0 {
+		t.Error("dragging did not move the thumb")
+	}
+	// The thumb follows the pointer past the end of the track
+	h.move(interfaces.Point{X: 400, Y: at.Y})
+	h.release(right)
+	if got := s.Value(); got != 10 {
+		t.Errorf("released at the right end: value %g, want 10", got)
+	}
+	after := changes
+	h.move(left)
+	if s.Value() != 10 || changes != after {
+		t.Error("moving after the release changed the value")
+	}
+	if h.root.Focused() != s {
+		t.Error("pressing did not focus the slider")
+	}
+}
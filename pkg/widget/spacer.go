@@ -0,0 +1,50 @@
+package widget
+
+// SpacerWidget is empty space. Added to a row or column as a flexible child
+// it takes a share of the space left over by the other children, pushing
+// those after it towards the end; added as a rigid child with a minimum size
+// it is a fixed gap.
+type SpacerWidget struct {
+	Base
+	constraints Constraints
+}
+
+// Spacer creates a new spacer.
+// If no constraints are provided, uses default flexible constraints (0, 0, 1e9, 1e9).
+func Spacer(constraints ...Constraints) *SpacerWidget {
+	var c Constraints
+	if len(constraints) > 0 {
+		c = constraints[0]
+	} else {
+		c = NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return &SpacerWidget{constraints: c}
+}
+
+// GetConstraints returns the spacer's constraints
+func (s *SpacerWidget) GetConstraints() Constraints {
+	return s.constraints
+}
+
+// Measure returns the minimum size of the spacer
+func (s *SpacerWidget) Measure(constraints Constraints) Size {
+	return minSize(s.GetConstraints())
+}
+
+// Layout implements the Widget interface for SpacerWidget; spacers take all
+// the space offered
+func (s *SpacerWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	s.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for SpacerWidget; spacers paint nothing
+func (s *SpacerWidget) Paint(ctx *Context, box *Box) (err error) {
+	return
+}
+
+// HandleEvent implements the Widget interface for SpacerWidget
+func (s *SpacerWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	return false
+}
@@ -0,0 +1,377 @@
+package widget
+
+import (
+	"encoding/json"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"lol.mleku.dev/chk"
+)
+
+// splitDivider is the thickness of the draggable divider between the panes
+// of a split
+const splitDivider = 6
+
+// SplitWidget shows two children side by side, or one above the other, with
+// a divider between them that can be dragged to share the space differently.
+// The divider's position is kept either as a proportion of the space, so the
+// panes grow together when the split is resized, or as the size in pixels of
+// the first pane, so only the second pane grows. Neither pane is made smaller
+// than its minimum size while there is room. The cursor changes to a resize
+// cursor over the divider.
+type SplitWidget struct {
+	Base
+	first, second Widget
+	// vertical stacks the panes instead of placing them side by side
+	vertical bool
+	// ratio is the first pane's share of the space, used unless fixed is
+	// set, when pixels is the first pane's size
+	ratio  float32
+	pixels float32
+	fixed  bool
+	// minFirst and minSecond are the smallest sizes of the panes along the
+	// split
+	minFirst, minSecond float32
+	// position is the first pane's size along the split from the last layout
+	position float32
+	// hovered is set while the cursor is over the divider and dragging
+	// while it is held, grab being the cursor's offset into the divider
+	hovered, dragging bool
+	grab              float32
+	onChange          func(ratio, position float32)
+	// key is the key the divider's position is remembered under
+	key string
+}
+
+// splitState is the remembered position of a split's divider
+type splitState struct {
+	// Ratio is the first pane's share of the space, or when Fixed,
+	// Position is its size in pixels
+	Ratio, Position float32
+	Fixed           bool `json:",omitempty"`
+}
+
+// Split creates a new split placing the children side by side, sharing the
+// space equally
+func Split(first, second Widget) *SplitWidget {
+	s := &SplitWidget{
+		first:  first,
+		second: second,
+		ratio:  0.5,
+	}
+	adopt(s, first)
+	adopt(s, second)
+	return s
+}
+
+// Vertical stacks the first child above the second and returns the split for chaining
+func (s *SplitWidget) Vertical() *SplitWidget {
+	s.vertical = true
+	s.MarkNeedsLayout()
+	return s
+}
+
+// Ratio places the divider so the first pane takes a proportion of the space,
+// kept as the split is resized, and returns the split for chaining
+func (s *SplitWidget) Ratio(ratio float32) *SplitWidget {
+	s.ratio = min(max(ratio, 0), 1)
+	s.fixed = false
+	s.MarkNeedsLayout()
+	return s
+}
+
+// Position places the divider so the first pane is a number of pixels wide,
+// or tall when vertical, kept as the split is resized, and returns the split
+// for chaining
+func (s *SplitWidget) Position(pixels float32) *SplitWidget {
+	s.pixels = max(pixels, 0)
+	s.fixed = true
+	s.MarkNeedsLayout()
+	return s
+}
+
+// MinSizes sets the smallest sizes of the first and second panes along the
+// split and returns the split for chaining
+func (s *SplitWidget) MinSizes(first, second float32) *SplitWidget {
+	s.minFirst, s.minSecond = first, second
+	s.MarkNeedsLayout()
+	return s
+}
+
+// OnChange sets the callback invoked with the first pane's share of the
+// space and its size in pixels when the user drags the divider, for saving
+// the split, and returns the split for chaining
+func (s *SplitWidget) OnChange(fn func(ratio, position float32)) *SplitWidget {
+	s.onChange = fn
+	return s
+}
+
+// StateKey remembers the position of the divider with the state of the
+// user interface under the key and returns the split for chaining
+func (s *SplitWidget) StateKey(key string) *SplitWidget {
+	s.key = key
+	return s
+}
+
+// SplitRatio returns the first pane's share of the space from the last layout
+func (s *SplitWidget) SplitRatio() float32 {
+	if avail := s.available(s.CachedSize()); avail > 0 {
+		return s.position / avail
+	}
+	return s.ratio
+}
+
+// SplitPosition returns the first pane's size in pixels from the last layout
+func (s *SplitWidget) SplitPosition() float32 {
+	return s.position
+}
+
+// GetConstraints returns the panes' minimum sizes along the split with the
+// divider between them, and the larger of their minimums across it
+func (s *SplitWidget) GetConstraints() Constraints {
+	var c1, c2 Constraints
+	if s.first != nil {
+		c1 = s.first.GetConstraints()
+	}
+	if s.second != nil {
+		c2 = s.second.GetConstraints()
+	}
+	if s.vertical {
+		return NewFlexConstraints(
+			max(c1.MinWidth, c2.MinWidth),
+			max(c1.MinHeight, s.minFirst)+max(c2.MinHeight, s.minSecond)+splitDivider,
+			1e9, 1e9)
+	}
+	return NewFlexConstraints(
+		max(c1.MinWidth, s.minFirst)+max(c2.MinWidth, s.minSecond)+splitDivider,
+		max(c1.MinHeight, c2.MinHeight),
+		1e9, 1e9)
+}
+
+// Measure returns the size the panes measure side by side, or stacked when
+// the split is vertical, with the divider between them
+func (s *SplitWidget) Measure(constraints Constraints) Size {
+	m1, m2 := measure(s.first, constraints), measure(s.second, constraints)
+	if s.vertical {
+		return atLeast(Size{
+			Width:  max(m1.Width, m2.Width),
+			Height: max(m1.Height, s.minFirst) + max(m2.Height, s.minSecond) + splitDivider,
+		}, s.GetConstraints())
+	}
+	return atLeast(Size{
+		Width:  max(m1.Width, s.minFirst) + max(m2.Width, s.minSecond) + splitDivider,
+		Height: max(m1.Height, m2.Height),
+	}, s.GetConstraints())
+}
+
+// Layout implements the Widget interface for SplitWidget; splits take all
+// the space offered and divide it between the panes
+func (s *SplitWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !s.NeedsLayout(ctx, constraints) {
+		return s.CachedSize(), nil
+	}
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	avail := s.available(size)
+	position := s.ratio * avail
+	if s.fixed {
+		position = s.pixels
+	}
+	s.position = s.clamp(position, avail)
+	b1, b2 := s.paneBoxes(NewBox(0, 0, size.Width, size.Height, constraints))
+	for _, pane := range []struct {
+		child Widget
+		box   *Box
+	}{{s.first, b1}, {s.second, b2}} {
+		if pane.child == nil {
+			continue
+		}
+		if _, err = pane.child.Layout(ctx, NewRigidConstraints(pane.box.Size.Width, pane.box.Size.Height)); chk.E(err) {
+			return
+		}
+	}
+	s.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for SplitWidget
+func (s *SplitWidget) Paint(ctx *Context, box *Box) (err error) {
+	b1, b2 := s.paneBoxes(box)
+	if s.first != nil {
+		if err = paintChild(ctx, s.first, b1); chk.E(err) {
+			return
+		}
+	}
+	if s.second != nil {
+		if err = paintChild(ctx, s.second, b2); chk.E(err) {
+			return
+		}
+	}
+	th := themeOf(ctx)
+	d := s.dividerBox(box)
+	color, thickness := th.Border, float32(1)
+	if s.hovered || s.dragging {
+		color, thickness = th.Primary, 2
+	}
+	// Draw a line along the middle of the divider
+	if s.vertical {
+		ctx.DrawList.Rect(d.Position.X, d.Position.Y+(d.Size.Height-thickness)/2, d.Size.Width, thickness, color)
+	} else {
+		ctx.DrawList.Rect(d.Position.X+(d.Size.Width-thickness)/2, d.Position.Y, thickness, d.Size.Height, color)
+	}
+	return
+}
+
+// HandleEvent implements the Widget interface for SplitWidget
+func (s *SplitWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	d := s.dividerBox(box)
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		if s.dragging {
+			s.drag(box, e.Position)
+			return true
+		}
+		s.setHovered(d.Contains(e.Position))
+	case interfaces.CursorLeaveEvent:
+		s.setHovered(false)
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft {
+			break
+		}
+		if e.Action == interfaces.ActionPress && d.Contains(e.Position) {
+			s.dragging = true
+			s.grab = s.along(e.Position) - s.along(d.Position)
+			s.MarkNeedsPaint()
+			return true
+		}
+		if e.Action == interfaces.ActionRelease && s.dragging {
+			s.dragging = false
+			s.setHovered(d.Contains(e.Position))
+			s.MarkNeedsPaint()
+			return true
+		}
+	}
+	b1, b2 := s.paneBoxes(box)
+	if s.first != nil && routeEvent(ctx, s.first, b1, ev) {
+		handled = true
+	}
+	if s.second != nil && routeEvent(ctx, s.second, b2, ev) {
+		handled = true
+	}
+	return
+}
+
+// CursorAt implements CursorProvider, showing a resize cursor over the
+// divider and while it is dragged
+func (s *SplitWidget) CursorAt(p Point) Cursor {
+	if !s.dragging && !s.dividerBox(&s.paintBox).Contains(p) {
+		return interfaces.CursorDefault
+	}
+	if s.vertical {
+		return interfaces.CursorResizeV
+	}
+	return interfaces.CursorResizeH
+}
+
+// stateKey implements stateful
+func (s *SplitWidget) stateKey() string {
+	return s.key
+}
+
+// saveState implements stateful, saving the position of the divider
+func (s *SplitWidget) saveState() any {
+	return splitState{Ratio: s.ratio, Position: s.pixels, Fixed: s.fixed}
+}
+
+// restoreState implements stateful, placing the divider where it was saved
+func (s *SplitWidget) restoreState(data json.RawMessage) (err error) {
+	var state splitState
+	if err = json.Unmarshal(data, &state); err != nil {
+		return
+	}
+	if state.Fixed {
+		s.Position(state.Position)
+	} else {
+		s.Ratio(state.Ratio)
+	}
+	return
+}
+
+// drag moves the divider with the cursor
+func (s *SplitWidget) drag(box *Box, p Point) {
+	avail := s.available(box.Size)
+	position := s.clamp(s.along(p)-s.along(box.Position)-s.grab, avail)
+	if position == s.position {
+		return
+	}
+	s.position = position
+	s.pixels = position
+	if avail > 0 {
+		s.ratio = position / avail
+	}
+	s.MarkNeedsLayout()
+	if s.onChange != nil {
+		s.onChange(s.ratio, s.pixels)
+	}
+}
+
+// clamp limits the first pane's size to leave both panes their minimum
+// sizes, favouring the first pane when there is not room for both
+func (s *SplitWidget) clamp(position, avail float32) float32 {
+	return max(min(position, avail-s.minSecond), min(s.minFirst, avail), 0)
+}
+
+// available returns the space along the split shared by the panes
+func (s *SplitWidget) available(size Size) float32 {
+	if s.vertical {
+		return max(size.Height-splitDivider, 0)
+	}
+	return max(size.Width-splitDivider, 0)
+}
+
+// along returns the coordinate of a point along the split
+func (s *SplitWidget) along(p Point) float32 {
+	if s.vertical {
+		return p.Y
+	}
+	return p.X
+}
+
+// paneBoxes returns the absolute boxes of the panes
+func (s *SplitWidget) paneBoxes(box *Box) (first, second *Box) {
+	x, y, w, h := box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height
+	var c1, c2 Constraints
+	if s.first != nil {
+		c1 = s.first.GetConstraints()
+	}
+	if s.second != nil {
+		c2 = s.second.GetConstraints()
+	}
+	p := s.position
+	if s.vertical {
+		return NewBox(x, y, w, p, c1), NewBox(x, y+p+splitDivider, w, max(h-p-splitDivider, 0), c2)
+	}
+	return NewBox(x, y, p, h, c1), NewBox(x+p+splitDivider, y, max(w-p-splitDivider, 0), h, c2)
+}
+
+// dividerBox returns the absolute box of the divider
+func (s *SplitWidget) dividerBox(box *Box) *Box {
+	if s.vertical {
+		return NewBox(box.Position.X, box.Position.Y+s.position, box.Size.Width, splitDivider, s.constraints())
+	}
+	return NewBox(box.Position.X+s.position, box.Position.Y, splitDivider, box.Size.Height, s.constraints())
+}
+
+// constraints returns the constraints of the divider
+func (s *SplitWidget) constraints() Constraints {
+	if s.vertical {
+		return NewFlexConstraints(0, splitDivider, 1e9, splitDivider)
+	}
+	return NewFlexConstraints(splitDivider, 0, splitDivider, 1e9)
+}
+
+// setHovered updates the hover state of the divider, repainting when it changes
+func (s *SplitWidget) setHovered(hovered bool) {
+	if hovered != s.hovered {
+		s.hovered = hovered
+		s.MarkNeedsPaint()
+	}
+}
@@ -0,0 +1,239 @@
+package widget
+
+import (
+	"slices"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"lol.mleku.dev/chk"
+)
+
+// stackPlacement is how a child of a stack is placed
+type stackPlacement int
+
+const (
+	// stackFill children fill the stack
+	stackFill stackPlacement = iota
+	// stackAnchored children sit against the edges or corner of a gravity
+	stackAnchored
+	// stackAbsolute children sit at a point
+	stackAbsolute
+)
+
+// stackChild is a child of a stack and how it is placed
+type stackChild struct {
+	widget    Widget
+	placement stackPlacement
+	gravity   Gravity
+	// offset is the margin from the anchored edges, or the position of an
+	// absolute child
+	offset Point
+	z      int
+}
+
+// StackWidget layers its children, each filling the stack, anchored to an
+// edge, corner or the centre, or placed at a point, and painted in order of
+// their z index so badges, floating buttons and pinned elements sit over
+// the content below them. Children with the same z index are painted in the
+// order they were added. Input reaches the topmost child under the cursor
+// first.
+type StackWidget struct {
+	Base
+	children    []*stackChild
+	constraints Constraints
+	// order is the children sorted by z index, bottom first
+	order []*stackChild
+	// boxes holds the boxes of the children in order from the last layout,
+	// relative to the stack
+	boxes []Box
+}
+
+// Stack creates a new stack with no children.
+// If no constraints are provided, uses default flexible constraints (0, 0, 1e9, 1e9).
+func Stack(constraints ...Constraints) *StackWidget {
+	var c Constraints
+	if len(constraints) > 0 {
+		c = constraints[0]
+	} else {
+		c = NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return &StackWidget{constraints: c}
+}
+
+// Child adds a child filling the stack and returns the stack for chaining
+func (s *StackWidget) Child(child Widget) *StackWidget {
+	return s.add(&stackChild{widget: child, placement: stackFill})
+}
+
+// Positioned adds a child at the size it measures against the edges of a
+// gravity, a margin inside them, and returns the stack for chaining. Along
+// an axis the gravity centres, the margin moves the child right or down.
+func (s *StackWidget) Positioned(child Widget, gravity Gravity, dx, dy float32) *StackWidget {
+	return s.add(&stackChild{widget: child, placement: stackAnchored, gravity: gravity, offset: Point{X: dx, Y: dy}})
+}
+
+// At adds a child at the size it measures with its top left corner at a point
+// in the stack, and returns the stack for chaining
+func (s *StackWidget) At(child Widget, x, y float32) *StackWidget {
+	return s.add(&stackChild{widget: child, placement: stackAbsolute, offset: Point{X: x, Y: y}})
+}
+
+// Z sets the z index of a child, which is painted over children of lower z
+// index, and returns the stack for chaining. Children start at zero.
+func (s *StackWidget) Z(child Widget, z int) *StackWidget {
+	for _, c := range s.children {
+		if c.widget == child && c.z != z {
+			c.z = z
+			s.sort()
+		}
+	}
+	return s
+}
+
+// Move moves a positioned or absolute child to a new margin or point
+func (s *StackWidget) Move(child Widget, x, y float32) {
+	for _, c := range s.children {
+		if c.widget == child {
+			c.offset = Point{X: x, Y: y}
+			s.MarkNeedsLayout()
+			s.MarkNeedsPaint()
+		}
+	}
+}
+
+// Remove removes a child
+func (s *StackWidget) Remove(child Widget) {
+	s.children = slices.DeleteFunc(s.children, func(c *stackChild) bool { return c.widget == child })
+	s.sort()
+}
+
+// add adds a child and returns the stack for chaining
+func (s *StackWidget) add(c *stackChild) *StackWidget {
+	s.children = append(s.children, c)
+	adopt(s, c.widget)
+	s.sort()
+	return s
+}
+
+// sort orders the children by z index, keeping the order they were added
+// among those of the same index
+func (s *StackWidget) sort() {
+	s.order = slices.Clone(s.children)
+	slices.SortStableFunc(s.order, func(a, b *stackChild) int { return a.z - b.z })
+	s.MarkNeedsLayout()
+	s.MarkNeedsPaint()
+}
+
+// GetConstraints returns the stack's constraints
+func (s *StackWidget) GetConstraints() Constraints {
+	return s.constraints
+}
+
+// Measure returns the size holding every child at the size it measures, with
+// the margins of positioned children and the points of absolute ones, at
+// least the stack's minimum
+func (s *StackWidget) Measure(constraints Constraints) (size Size) {
+	for _, c := range s.children {
+		m := c.widget.Measure(constraints)
+		if c.placement != stackFill {
+			m.Width += max(c.offset.X, 0)
+			m.Height += max(c.offset.Y, 0)
+		}
+		size.Width, size.Height = max(size.Width, m.Width), max(size.Height, m.Height)
+	}
+	return atLeast(size, s.constraints)
+}
+
+// Layout implements the Widget interface for StackWidget. The stack fills
+// the space it is given.
+func (s *StackWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !s.NeedsLayout(ctx, constraints) {
+		return s.CachedSize(), nil
+	}
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	s.boxes = s.boxes[:0]
+	for _, c := range s.order {
+		cc := c.widget.GetConstraints()
+		box := Box{Size: size, Constraints: cc}
+		if c.placement != stackFill {
+			m := c.widget.Measure(NewFlexConstraints(0, 0, size.Width, size.Height))
+			box.Size = Size{Width: min(m.Width, size.Width), Height: min(m.Height, size.Height)}
+		}
+		switch c.placement {
+		case stackAnchored:
+			box.Position = anchorIn(c.gravity, size, box.Size, c.offset)
+		case stackAbsolute:
+			box.Position = c.offset
+		}
+		if _, err = c.widget.Layout(ctx, NewRigidConstraints(box.Size.Width, box.Size.Height)); chk.E(err) {
+			return
+		}
+		s.boxes = append(s.boxes, box)
+	}
+	s.SetLayout(ctx, constraints, size)
+	return
+}
+
+// anchorIn returns the position of a box of a size against the edges of a
+// gravity in an area, a margin inside them
+func anchorIn(gravity Gravity, area, size Size, margin Point) (p Point) {
+	// The fraction of the free space before the box on each axis
+	var fx, fy float32 = 0.5, 0.5
+	switch gravity {
+	case GravityNorth, GravityNorthEast, GravityNorthWest:
+		fy = 0
+	case GravitySouth, GravitySouthEast, GravitySouthWest:
+		fy = 1
+	}
+	switch gravity {
+	case GravityWest, GravityNorthWest, GravitySouthWest:
+		fx = 0
+	case GravityEast, GravityNorthEast, GravitySouthEast:
+		fx = 1
+	}
+	p.X = (area.Width - size.Width) * fx
+	p.Y = (area.Height - size.Height) * fy
+	// Margins push away from the anchored edges and move centred boxes
+	if fx == 1 {
+		p.X -= margin.X
+	} else {
+		p.X += margin.X
+	}
+	if fy == 1 {
+		p.Y -= margin.Y
+	} else {
+		p.Y += margin.Y
+	}
+	return
+}
+
+// Paint implements the Widget interface for StackWidget.
+// Children are painted from the lowest z index to the highest.
+func (s *StackWidget) Paint(ctx *Context, box *Box) (err error) {
+	for i := range s.boxes {
+		if i >= len(s.order) {
+			break
+		}
+		if err = paintChild(ctx, s.order[i].widget, childBox(box, &s.boxes[i])); chk.E(err) {
+			return
+		}
+	}
+	return
+}
+
+// HandleEvent implements the Widget interface for StackWidget.
+// Children of higher z index paint over the rest so they receive events first.
+func (s *StackWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	_, targeted := interfaces.Target(ev)
+	for i := len(s.boxes) - 1; i >= 0; i-- {
+		if i >= len(s.order) {
+			continue
+		}
+		if routeEvent(ctx, s.order[i].widget, childBox(box, &s.boxes[i]), ev) {
+			handled = true
+			if targeted {
+				return
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,310 @@
+package widget
+
+import (
+	"math"
+	"time"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/text"
+	"lol.mleku.dev/chk"
+)
+
+const (
+	// statusPadding is the space around the contents of a status bar
+	statusPadding = 4
+	// statusGap separates the widgets of a section
+	statusGap = 12
+	// statusProgressWidth is the width of a status bar's progress bar
+	statusProgressWidth = 120
+)
+
+// StatusSection is a part of a status bar widgets are placed in
+type StatusSection int
+
+const (
+	// StatusLeft holds widgets from the start of the bar, and is covered by
+	// messages
+	StatusLeft StatusSection = iota
+	// StatusCenter holds widgets centered on the bar
+	StatusCenter
+	// StatusRight holds widgets up to the end of the bar, before the
+	// progress bar
+	StatusRight
+)
+
+// statusChild is a widget of a status bar and its box relative to the bar
+// from the last layout
+type statusChild struct {
+	widget  Widget
+	section StatusSection
+	box     Box
+}
+
+// StatusBarWidget is a strip along the bottom of a window showing the state
+// of the program in three sections, widgets such as labels placed at its
+// start, its center and its end. A message shown on it covers the start
+// section, for a while or until cleared, and a progress bar can be shown at
+// its end while work runs. Sections follow the reading direction, so the
+// start is on the right in a mirrored layout.
+type StatusBarWidget struct {
+	Base
+	font     *text.Font
+	size     float32
+	children []statusChild
+	message  string
+	// timeout is how long the message shows, scheduled when the bar next
+	// paints, and timer clears it
+	timeout time.Duration
+	timer   *timer
+	// progress is shown at the end of the bar while showProgress is set
+	progress     *ProgressBarWidget
+	showProgress bool
+	progressBox  Box
+}
+
+// StatusBar creates a new empty status bar with messages drawn in the given
+// font at 13 pixels
+func StatusBar(font *text.Font) *StatusBarWidget {
+	s := &StatusBarWidget{font: font, size: 13, progress: ProgressBar()}
+	adopt(s, s.progress)
+	return s
+}
+
+// Size sets the pixel size of messages, which sets the height of the bar,
+// and returns the status bar for chaining
+func (s *StatusBarWidget) Size(size float32) *StatusBarWidget {
+	s.size = size
+	s.MarkNeedsLayout()
+	return s
+}
+
+// Add appends widgets to a section and returns the status bar for chaining.
+// Each widget is as wide as it measures.
+func (s *StatusBarWidget) Add(section StatusSection, widgets ...Widget) *StatusBarWidget {
+	for _, w := range widgets {
+		s.children = append(s.children, statusChild{widget: w, section: section})
+		adopt(s, w)
+	}
+	return s
+}
+
+// Clear removes the widgets of a section
+func (s *StatusBarWidget) Clear(section StatusSection) {
+	kept := s.children[:0]
+	for _, c := range s.children {
+		if c.section != section {
+			kept = append(kept, c)
+		}
+	}
+	clear(s.children[len(kept):])
+	s.children = kept
+	s.MarkNeedsLayout()
+}
+
+// ShowMessage covers the start section with a message for a while, or
+// until cleared or replaced when the timeout is zero
+func (s *StatusBarWidget) ShowMessage(message string, timeout time.Duration) {
+	s.cancelTimer()
+	s.message, s.timeout = message, timeout
+	s.MarkNeedsPaint()
+}
+
+// ClearMessage removes the message, showing the start section again
+func (s *StatusBarWidget) ClearMessage() {
+	s.cancelTimer()
+	if s.message == "" {
+		return
+	}
+	s.message, s.timeout = "", 0
+	s.MarkNeedsPaint()
+}
+
+// Message returns the message showing, empty if none
+func (s *StatusBarWidget) Message() string {
+	return s.message
+}
+
+// Progress returns the progress bar shown at the end of the bar, to set or
+// bind its value, such as to the progress of a task
+func (s *StatusBarWidget) Progress() *ProgressBarWidget {
+	return s.progress
+}
+
+// ShowProgress sets whether the progress bar is shown and returns the
+// status bar for chaining
+func (s *StatusBarWidget) ShowProgress(show bool) *StatusBarWidget {
+	if show != s.showProgress {
+		s.showProgress = show
+		s.MarkNeedsLayout()
+	}
+	return s
+}
+
+// GetConstraints returns the height of a line of text in the padding
+func (s *StatusBarWidget) GetConstraints() Constraints {
+	height := s.height()
+	return NewFlexConstraints(0, height, 1e9, height)
+}
+
+// Measure returns the height of the bar and the width of its widgets
+func (s *StatusBarWidget) Measure(constraints Constraints) Size {
+	inner := s.height() - 2*statusPadding - 1
+	width := float32(2 * statusPadding)
+	for i, c := range s.children {
+		if i > 0 {
+			width += statusGap
+		}
+		width += measure(c.widget, NewFlexConstraints(0, 0, constraints.MaxWidth, inner)).Width
+	}
+	if s.showProgress {
+		width += statusGap + statusProgressWidth
+	}
+	return Size{Width: min(width, constraints.MaxWidth), Height: s.height()}
+}
+
+// Layout implements the Widget interface for StatusBarWidget; status bars
+// take the width offered, with each widget as wide as it measures and as
+// tall as a line of text
+func (s *StatusBarWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !s.NeedsLayout(ctx, constraints) {
+		return s.CachedSize(), nil
+	}
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	inner := s.height() - 2*statusPadding - 1
+	top := (size.Height - inner) / 2
+	var widths [3]float32
+	for i := range s.children {
+		c := &s.children[i]
+		w := measure(c.widget, NewFlexConstraints(0, 0, size.Width, inner)).Width
+		if _, err = c.widget.Layout(ctx, NewRigidConstraints(w, inner)); chk.E(err) {
+			return
+		}
+		if widths[c.section] > 0 {
+			widths[c.section] += statusGap
+		}
+		c.box = Box{Position: Point{X: widths[c.section], Y: top}, Size: Size{Width: w, Height: inner}}
+		widths[c.section] += w
+	}
+	end := size.Width - statusPadding
+	if s.showProgress {
+		if _, err = s.progress.Layout(ctx, NewRigidConstraints(statusProgressWidth, inner)); chk.E(err) {
+			return
+		}
+		end -= statusProgressWidth
+		s.progressBox = Box{Position: Point{X: end, Y: top}, Size: Size{Width: statusProgressWidth, Height: inner}}
+		end -= statusGap
+	}
+	// Sections are laid out from their offsets, which start each at zero
+	starts := [3]float32{statusPadding, (size.Width - widths[StatusCenter]) / 2, end - widths[StatusRight]}
+	for i := range s.children {
+		c := &s.children[i]
+		c.box.Position.X += float32(math.Round(float64(starts[c.section])))
+		if ctx.RTL {
+			c.box.Position.X = size.Width - c.box.Position.X - c.box.Size.Width
+		}
+	}
+	if ctx.RTL {
+		s.progressBox.Position.X = size.Width - s.progressBox.Position.X - s.progressBox.Size.Width
+	}
+	s.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for StatusBarWidget
+func (s *StatusBarWidget) Paint(ctx *Context, box *Box) (err error) {
+	if s.timeout > 0 {
+		// The message times out from the frame it is first shown in
+		if r := rootOf(s); r != nil {
+			s.timer = r.schedule(frameTime(ctx).Add(s.timeout), s.ClearMessage)
+		}
+		s.timeout = 0
+	}
+	th := themeOf(ctx)
+	list := ctx.DrawList
+	x, y, w, h := box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height
+	list.Rect(x, y, w, h, th.Surface)
+	list.Rect(x, y, w, 1, th.Border)
+	list.PushClip(x, y, w, h)
+	defer list.PopClip()
+
+	for i := range s.children {
+		c := &s.children[i]
+		if s.message != "" && c.section == StatusLeft {
+			continue
+		}
+		if err = paintChild(ctx, c.widget, childBox(box, &c.box)); chk.E(err) {
+			return
+		}
+	}
+	if s.message != "" {
+		face := s.font.Face(s.size)
+		width := face.Measure(s.message)
+		align := text.AlignStart
+		if ctx.RTL {
+			align = align.Mirrored()
+		}
+		mx := x + statusPadding + align.Offset(width, w-2*statusPadding)
+		baseline := y + 1 + (h-1-face.LineHeight())/2 + face.Ascent()
+		face.Draw(list, mx, baseline, s.message, th.Text)
+	}
+	if s.showProgress {
+		if err = paintChild(ctx, s.progress, childBox(box, &s.progressBox)); chk.E(err) {
+			return
+		}
+	}
+	return
+}
+
+// HandleEvent implements the Widget interface for StatusBarWidget, passing
+// events to the widgets of the sections
+func (s *StatusBarWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	_, targeted := interfaces.Target(ev)
+	for i := range s.children {
+		c := &s.children[i]
+		if s.message != "" && c.section == StatusLeft {
+			continue
+		}
+		if routeEvent(ctx, c.widget, childBox(box, &c.box), ev) {
+			handled = true
+			if targeted {
+				return
+			}
+		}
+	}
+	return
+}
+
+// cancelTimer stops the message from timing out
+func (s *StatusBarWidget) cancelTimer() {
+	if r := rootOf(s); r != nil {
+		r.cancel(s.timer)
+	}
+	s.timer = nil
+}
+
+// height returns the height of the bar, fitting a line of text and the
+// border along its top
+func (s *StatusBarWidget) height() float32 {
+	return float32(math.Ceil(float64(s.font.Face(s.size).LineHeight()))) + 2*statusPadding + 1
+}
+
+// SetStatusBar pins a status bar along the bottom of the window, below the
+// root's child, and returns the root for chaining. A nil bar removes it.
+func (r *RootWidget) SetStatusBar(bar *StatusBarWidget) *RootWidget {
+	if r.statusBar == nil {
+		r.body = r.child
+	}
+	r.statusBar = bar
+	r.child = r.body
+	if bar != nil {
+		r.child = Column().Flex(r.body, 1).Rigid(bar)
+	}
+	adopt(r, r.child)
+	return r
+}
+
+// StatusBar returns the status bar pinned along the bottom of the window,
+// nil if none
+func (r *RootWidget) StatusBar() *StatusBarWidget {
+	return r.statusBar
+}
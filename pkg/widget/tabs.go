@@ -0,0 +1,661 @@
+package widget
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/text"
+	"lol.mleku.dev/chk"
+)
+
+const (
+	// tabsPadding is the space either side of the title of a tab, and the
+	// sum of the space above and below it
+	tabsPadding = 10
+	// tabsClose is the size of the close button of closable tabs
+	tabsClose = 14
+	// tabsIndicator is the thickness of the line under the selected tab
+	tabsIndicator = 2
+	// tabsDragThreshold is how far the cursor moves with the button held on
+	// a tab before the tab is dragged
+	tabsDragThreshold = 4
+)
+
+// tab is a page of a tabs widget
+type tab struct {
+	title    string
+	closable bool
+	content  Widget
+	// build constructs the content the first time the tab is shown
+	build func() Widget
+}
+
+// TabsWidget shows a strip of tab titles above the content of the selected
+// tab. Clicking a title selects its tab, closable tabs have a button that
+// closes them, and dragging a title along the strip reorders the tabs. When
+// the titles do not fit the strip scrolls with the mouse wheel and follows
+// the selection. While the strip has focus the left and right keys select
+// the neighbouring tabs. Content added lazily is built the first time its
+// tab is selected.
+type TabsWidget struct {
+	Base
+	font     *text.Font
+	size     float32
+	tabs     []*tab
+	selected int
+	// closable is the default for tabs added from now on
+	closable bool
+	// scroll is how far the strip is scrolled from its start
+	scroll float32
+	// hot is the tab under the cursor and hotClose set when the cursor is
+	// over its close button
+	hot      int
+	hotClose bool
+	// pressedClose is the tab whose close button is held down, -1 if none
+	pressedClose int
+	// drag is the tab held down, -1 if none, with from its index at the
+	// press. grab is the cursor's distance from the tab's left edge and
+	// pressX and dragX where the cursor was pressed and is now. dragging is
+	// set once the cursor moved far enough to drag.
+	drag, from    int
+	grab          float32
+	pressX, dragX float32
+	dragging      bool
+
+	onSelect  func(index int)
+	onClose   func(index int) bool
+	onReorder func(from, to int)
+	// key is the key the open tabs are remembered under
+	key string
+}
+
+// tabsState is the remembered titles of the open tabs in order, and the
+// index of the selected tab
+type tabsState struct {
+	Tabs     []string
+	Selected int
+}
+
+// Tabs creates a new tabs widget without tabs, with titles drawn in the
+// given font at 14 pixels
+func Tabs(font *text.Font) *TabsWidget {
+	return &TabsWidget{
+		font:         font,
+		size:         14,
+		selected:     -1,
+		hot:          -1,
+		pressedClose: -1,
+		drag:         -1,
+	}
+}
+
+// Size sets the pixel size of the titles and returns the tabs for chaining
+func (t *TabsWidget) Size(size float32) *TabsWidget {
+	t.size = size
+	t.MarkNeedsLayout()
+	return t
+}
+
+// Closable sets whether tabs added from now on have a close button and
+// returns the tabs for chaining
+func (t *TabsWidget) Closable(closable bool) *TabsWidget {
+	t.closable = closable
+	return t
+}
+
+// Add appends a tab showing the content and returns the tabs for chaining.
+// The first tab added is selected.
+func (t *TabsWidget) Add(title string, content Widget) *TabsWidget {
+	return t.insert(&tab{title: title, closable: t.closable, content: content})
+}
+
+// AddLazy appends a tab whose content is built by the function the first
+// time the tab is selected, and returns the tabs for chaining
+func (t *TabsWidget) AddLazy(title string, build func() Widget) *TabsWidget {
+	return t.insert(&tab{title: title, closable: t.closable, build: build})
+}
+
+// OnSelect sets the callback invoked with the index of the tab the user
+// selects and returns the tabs for chaining
+func (t *TabsWidget) OnSelect(fn func(index int)) *TabsWidget {
+	t.onSelect = fn
+	return t
+}
+
+// OnClose sets the callback invoked with the index of a tab whose close
+// button was clicked, before it closes, and returns the tabs for chaining.
+// Returning false keeps the tab open.
+func (t *TabsWidget) OnClose(fn func(index int) bool) *TabsWidget {
+	t.onClose = fn
+	return t
+}
+
+// OnReorder sets the callback invoked when the user drags a tab from one
+// index to another and returns the tabs for chaining
+func (t *TabsWidget) OnReorder(fn func(from, to int)) *TabsWidget {
+	t.onReorder = fn
+	return t
+}
+
+// StateKey remembers the open tabs, their order and the selected tab with
+// the state of the user interface under the key, and returns the tabs for
+// chaining. Tabs are known by their titles.
+func (t *TabsWidget) StateKey(key string) *TabsWidget {
+	t.key = key
+	return t
+}
+
+// Len returns the number of tabs
+func (t *TabsWidget) Len() int {
+	return len(t.tabs)
+}
+
+// Title returns the title of the tab at index
+func (t *TabsWidget) Title(index int) string {
+	return t.tabs[index].title
+}
+
+// SetTitle changes the title of the tab at index
+func (t *TabsWidget) SetTitle(index int, title string) {
+	t.tabs[index].title = title
+	t.MarkNeedsLayout()
+}
+
+// Content returns the content of the tab at index, nil for lazy content
+// that was not built yet
+func (t *TabsWidget) Content(index int) Widget {
+	return t.tabs[index].content
+}
+
+// Select selects the tab at index without invoking the select callback
+func (t *TabsWidget) Select(index int) {
+	if index < 0 || index >= len(t.tabs) || index == t.selected {
+		return
+	}
+	t.selected = index
+	t.reveal(index)
+	t.MarkNeedsLayout()
+}
+
+// Selected returns the index of the selected tab, -1 if there are no tabs
+func (t *TabsWidget) Selected() int {
+	return t.selected
+}
+
+// Remove removes the tab at index. When it was selected its neighbour is
+// selected instead.
+func (t *TabsWidget) Remove(index int) {
+	if index < 0 || index >= len(t.tabs) {
+		return
+	}
+	t.tabs = append(t.tabs[:index], t.tabs[index+1:]...)
+	switch {
+	case t.selected > index:
+		t.selected--
+	case t.selected == index:
+		t.selected = min(index, len(t.tabs)-1)
+	}
+	t.hot, t.pressedClose, t.drag, t.dragging = -1, -1, -1, false
+	t.MarkNeedsLayout()
+}
+
+// Move moves the tab at index from to index to, keeping it selected if it was
+func (t *TabsWidget) Move(from, to int) {
+	n := len(t.tabs)
+	if from < 0 || from >= n || to < 0 || to >= n || from == to {
+		return
+	}
+	moved := t.tabs[from]
+	t.tabs = append(t.tabs[:from], t.tabs[from+1:]...)
+	t.tabs = append(t.tabs[:to], append([]*tab{moved}, t.tabs[to:]...)...)
+	switch {
+	case t.selected == from:
+		t.selected = to
+	case from < t.selected && t.selected <= to:
+		t.selected--
+	case to <= t.selected && t.selected < from:
+		t.selected++
+	}
+	t.MarkNeedsPaint()
+}
+
+// GetConstraints returns the strip's height above the selected content's
+// minimum size
+func (t *TabsWidget) GetConstraints() Constraints {
+	strip := t.stripHeight()
+	c := NewFlexConstraints(0, 0, 1e9, 1e9)
+	if content := t.current(); content != nil {
+		c = content.GetConstraints()
+	}
+	maxHeight := c.MaxHeight
+	if maxHeight < 1e9 {
+		maxHeight += strip
+	}
+	return NewFlexConstraints(c.MinWidth, c.MinHeight+strip, c.MaxWidth, maxHeight)
+}
+
+// Measure returns the size the current tab's content measures below the
+// strip of tabs, at least the widget's minimum
+func (t *TabsWidget) Measure(constraints Constraints) Size {
+	strip := Insets{Top: t.stripHeight()}
+	return atLeast(strip.around(measure(t.current(), strip.shrink(constraints))), t.GetConstraints())
+}
+
+// Layout implements the Widget interface for TabsWidget; tabs take all the
+// space offered and lay the selected content out below the strip
+func (t *TabsWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !t.NeedsLayout(ctx, constraints) {
+		return t.CachedSize(), nil
+	}
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	t.SetLayout(ctx, constraints, size)
+	t.scroll = t.clampScroll(t.scroll, size.Width)
+	if content := t.current(); content != nil {
+		if _, err = content.Layout(ctx, NewRigidConstraints(size.Width, max(size.Height-t.stripHeight(), 0))); chk.E(err) {
+			return
+		}
+	}
+	return
+}
+
+// Paint implements the Widget interface for TabsWidget
+func (t *TabsWidget) Paint(ctx *Context, box *Box) (err error) {
+	if content := t.current(); content != nil {
+		if err = paintChild(ctx, content, t.contentBox(box)); chk.E(err) {
+			return
+		}
+	}
+	th := themeOf(ctx)
+	list := ctx.DrawList
+	x, y, w := box.Position.X, box.Position.Y, box.Size.Width
+	h := t.stripHeight()
+	list.Rect(x, y, w, h, th.Surface)
+	list.Rect(x, y+h-1, w, 1, th.Border)
+
+	list.PushClip(x, y, w, h)
+	defer list.PopClip()
+	face := t.font.Face(t.size)
+	baseline := y + (h-face.LineHeight())/2 + face.Ascent()
+	paintTab := func(i int) {
+		tx, tw := t.tabSpan(i)
+		tx += x
+		if i == t.drag && t.dragging {
+			tx = x + t.dragX - t.grab
+		}
+		tb := t.tabs[i]
+		color := th.TextMuted
+		switch {
+		case i == t.selected:
+			list.Rect(tx, y, tw, h-1, th.Background)
+			list.Rect(tx, y+h-tabsIndicator, tw, tabsIndicator, th.Primary)
+			color = th.Text
+		case i == t.hot:
+			list.Rect(tx, y, tw, h-1, th.SurfaceHover)
+		}
+		face.Draw(list, tx+tabsPadding, baseline, tb.title, color)
+		if !tb.closable {
+			return
+		}
+		cx, cy := tx+tw-tabsPadding/2-tabsClose/2, y+h/2
+		if i == t.hot && t.hotClose {
+			list.Circle(cx, cy, tabsClose/2, th.SurfacePressed)
+		}
+		// Draw the cross as two strokes
+		d := float32(tabsClose) / 4
+		list.Line(cx-d, cy-d, cx+d, cy+d, 1.5, color)
+		list.Line(cx-d, cy+d, cx+d, cy-d, 1.5, color)
+	}
+	for i := range t.tabs {
+		if i != t.drag || !t.dragging {
+			paintTab(i)
+		}
+	}
+	// The dragged tab floats over the others
+	if t.drag >= 0 && t.dragging {
+		paintTab(t.drag)
+	}
+	return
+}
+
+// Accessibility implements Accessible, with the title of the selected tab
+// as the value, which can be set to select another
+func (t *TabsWidget) Accessibility() AccessNode {
+	node := AccessNode{Role: interfaces.RoleTabList, Actions: []AccessAction{interfaces.AccessSetValue}}
+	if t.selected >= 0 {
+		node.Value = t.tabs[t.selected].title
+	}
+	return node
+}
+
+// AccessAction implements AccessActor, selecting the tab with the title of
+// the value
+func (t *TabsWidget) AccessAction(ctx *Context, action AccessAction, value string) bool {
+	if action != interfaces.AccessSetValue {
+		return false
+	}
+	for i, tb := range t.tabs {
+		if tb.title == value {
+			t.pick(i)
+			return true
+		}
+	}
+	return false
+}
+
+// HandleEvent implements the Widget interface for TabsWidget
+func (t *TabsWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	strip := t.stripBox(box)
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		if t.drag >= 0 {
+			t.dragTo(box, e.Position.X)
+			return true
+		}
+		i, onClose := t.tabAt(box, e.Position)
+		t.setHot(i, onClose)
+	case interfaces.CursorLeaveEvent:
+		t.setHot(-1, false)
+	case interfaces.ScrollEvent:
+		if strip.Contains(e.Position) {
+			// Scroll the strip by either axis of the wheel
+			delta := e.Offset.X
+			if delta == 0 {
+				delta = e.Offset.Y
+			}
+			t.scrollTo(t.scroll - delta*t.stripHeight())
+			return true
+		}
+	case interfaces.MouseButtonEvent:
+		if e.Button == interfaces.MouseButtonLeft {
+			if e.Action == interfaces.ActionPress && strip.Contains(e.Position) {
+				requestFocus(t)
+				t.press(box, e.Position)
+				return true
+			}
+			if e.Action == interfaces.ActionRelease && (t.drag >= 0 || t.pressedClose >= 0) {
+				t.release(box, e.Position)
+				return true
+			}
+		}
+	case interfaces.KeyEvent:
+		if hasFocus(t) && e.Action != interfaces.ActionRelease {
+			switch e.Key {
+			case interfaces.KeyLeft:
+				t.pick(max(t.selected-1, 0))
+				return true
+			case interfaces.KeyRight:
+				t.pick(min(t.selected+1, len(t.tabs)-1))
+				return true
+			}
+		}
+	}
+	if content := t.current(); content != nil {
+		return routeEvent(ctx, content, t.contentBox(box), ev)
+	}
+	return false
+}
+
+// stateKey implements stateful
+func (t *TabsWidget) stateKey() string {
+	return t.key
+}
+
+// saveState implements stateful, saving the titles of the tabs and the
+// selection
+func (t *TabsWidget) saveState() any {
+	state := tabsState{Tabs: make([]string, len(t.tabs)), Selected: t.selected}
+	for i, tb := range t.tabs {
+		state.Tabs[i] = tb.title
+	}
+	return state
+}
+
+// restoreState implements stateful, putting the tabs with the saved titles
+// in the saved order, closing closable tabs that were closed, and selecting
+// the saved tab
+func (t *TabsWidget) restoreState(data json.RawMessage) (err error) {
+	var state tabsState
+	if err = json.Unmarshal(data, &state); err != nil {
+		return
+	}
+	placed := 0
+	for _, title := range state.Tabs {
+		for i := placed; i < len(t.tabs); i++ {
+			if t.tabs[i].title == title {
+				t.Move(i, placed)
+				placed++
+				break
+			}
+		}
+	}
+	for i := len(t.tabs) - 1; i >= placed; i-- {
+		if t.tabs[i].closable {
+			t.Remove(i)
+		}
+	}
+	t.Select(state.Selected)
+	t.MarkNeedsLayout()
+	return
+}
+
+// press selects the tab under the cursor and holds it for dragging, or
+// holds down its close button
+func (t *TabsWidget) press(box *Box, p Point) {
+	i, onClose := t.tabAt(box, p)
+	if i < 0 {
+		return
+	}
+	if onClose {
+		t.pressedClose = i
+		return
+	}
+	t.pick(i)
+	x, _ := t.tabSpan(i)
+	t.drag, t.from = i, i
+	t.grab = p.X - box.Position.X - x
+	t.pressX, t.dragX = p.X, p.X
+}
+
+// release drops a dragged tab, or closes the tab whose close button was
+// pressed when released over it
+func (t *TabsWidget) release(box *Box, p Point) {
+	if t.drag >= 0 {
+		moved := t.dragging && t.drag != t.from
+		from, to := t.from, t.drag
+		t.drag, t.dragging = -1, false
+		t.MarkNeedsPaint()
+		if moved && t.onReorder != nil {
+			t.onReorder(from, to)
+		}
+		return
+	}
+	i := t.pressedClose
+	t.pressedClose = -1
+	if j, onClose := t.tabAt(box, p); j != i || !onClose {
+		return
+	}
+	if t.onClose != nil && !t.onClose(i) {
+		return
+	}
+	selected := t.selected == i
+	t.Remove(i)
+	if selected && t.selected >= 0 && t.onSelect != nil {
+		t.onSelect(t.selected)
+	}
+}
+
+// dragTo moves the held tab with the cursor, swapping it with neighbours
+// once its middle passes theirs
+func (t *TabsWidget) dragTo(box *Box, x float32) {
+	if !t.dragging {
+		if float32(math.Abs(float64(x-t.pressX))) < tabsDragThreshold {
+			return
+		}
+		t.dragging = true
+	}
+	t.dragX = x
+	_, w := t.tabSpan(t.drag)
+	middle := x - box.Position.X - t.grab + w/2
+	for t.drag < len(t.tabs)-1 {
+		nx, nw := t.tabSpan(t.drag + 1)
+		if middle < nx+nw/2 {
+			break
+		}
+		t.Move(t.drag, t.drag+1)
+		t.drag++
+	}
+	for t.drag > 0 {
+		px, pw := t.tabSpan(t.drag - 1)
+		if middle > px+pw/2 {
+			break
+		}
+		t.Move(t.drag, t.drag-1)
+		t.drag--
+	}
+	t.MarkNeedsPaint()
+}
+
+// pick selects a tab on behalf of the user, invoking the select callback
+// when the selection changed
+func (t *TabsWidget) pick(index int) {
+	if index < 0 || index >= len(t.tabs) || index == t.selected {
+		return
+	}
+	t.Select(index)
+	if t.onSelect != nil {
+		t.onSelect(index)
+	}
+}
+
+// insert appends a tab, selecting it when it is the first
+func (t *TabsWidget) insert(tb *tab) *TabsWidget {
+	if tb.content != nil {
+		tb.content.SetParent(t)
+	}
+	t.tabs = append(t.tabs, tb)
+	if t.selected < 0 {
+		t.selected = 0
+	}
+	t.MarkNeedsLayout()
+	return t
+}
+
+// current returns the content of the selected tab, building it if needed
+func (t *TabsWidget) current() Widget {
+	if t.selected < 0 {
+		return nil
+	}
+	tb := t.tabs[t.selected]
+	if tb.content == nil && tb.build != nil {
+		tb.content = tb.build()
+		tb.build = nil
+		if tb.content != nil {
+			tb.content.SetParent(t)
+		}
+	}
+	return tb.content
+}
+
+// tabSpan returns the offset of the tab at index from the left edge of the
+// strip, as scrolled, and its width
+func (t *TabsWidget) tabSpan(index int) (x, width float32) {
+	x = -t.scroll
+	for i := 0; i <= index; i++ {
+		x += width
+		width = t.tabWidth(i)
+	}
+	return
+}
+
+// tabWidth returns the width of the tab at index
+func (t *TabsWidget) tabWidth(index int) float32 {
+	tb := t.tabs[index]
+	w := float32(math.Ceil(float64(t.font.Face(t.size).Measure(tb.title)))) + 2*tabsPadding
+	if tb.closable {
+		w += tabsClose
+	}
+	return w
+}
+
+// stripWidth returns the width of all the tabs together
+func (t *TabsWidget) stripWidth() (width float32) {
+	for i := range t.tabs {
+		width += t.tabWidth(i)
+	}
+	return
+}
+
+// tabAt returns the tab under a point, -1 if none, and whether the point is
+// over its close button
+func (t *TabsWidget) tabAt(box *Box, p Point) (index int, onClose bool) {
+	if !t.stripBox(box).Contains(p) {
+		return -1, false
+	}
+	for i, tb := range t.tabs {
+		x, w := t.tabSpan(i)
+		x += box.Position.X
+		if p.X < x || p.X >= x+w {
+			continue
+		}
+		right := x + w - tabsPadding/2
+		return i, tb.closable && p.X >= right-tabsClose && p.X < right
+	}
+	return -1, false
+}
+
+// reveal scrolls the strip so the tab at index is visible
+func (t *TabsWidget) reveal(index int) {
+	x, w := t.tabSpan(index)
+	visible := t.CachedSize().Width
+	switch {
+	case visible <= 0:
+		// Not laid out yet
+	case x < 0:
+		t.scrollTo(t.scroll + x)
+	case x+w > visible:
+		t.scrollTo(t.scroll + x + w - visible)
+	}
+}
+
+// scrollTo scrolls the strip, clamped so no space is left past the last tab
+func (t *TabsWidget) scrollTo(scroll float32) {
+	scroll = t.clampScroll(scroll, t.CachedSize().Width)
+	if scroll != t.scroll {
+		t.scroll = scroll
+		t.MarkNeedsPaint()
+	}
+}
+
+// clampScroll limits a scroll position to the range of a strip of the given width
+func (t *TabsWidget) clampScroll(scroll, width float32) float32 {
+	return min(max(scroll, 0), max(t.stripWidth()-width, 0))
+}
+
+// stripHeight returns the height of the strip of titles
+func (t *TabsWidget) stripHeight() float32 {
+	return float32(math.Ceil(float64(t.font.Face(t.size).LineHeight()))) + tabsPadding
+}
+
+// stripBox returns the absolute box of the strip of titles
+func (t *TabsWidget) stripBox(box *Box) *Box {
+	return NewBox(box.Position.X, box.Position.Y, box.Size.Width, t.stripHeight(), t.GetConstraints())
+}
+
+// contentBox returns the absolute box of the selected content
+func (t *TabsWidget) contentBox(box *Box) *Box {
+	h := t.stripHeight()
+	var c Constraints
+	if content := t.current(); content != nil {
+		c = content.GetConstraints()
+	}
+	return NewBox(box.Position.X, box.Position.Y+h, box.Size.Width, max(box.Size.Height-h, 0), c)
+}
+
+// setHot updates the tab under the cursor, repainting when it changes
+func (t *TabsWidget) setHot(index int, onClose bool) {
+	if index != t.hot || onClose != t.hotClose {
+		t.hot, t.hotClose = index, onClose
+		t.MarkNeedsPaint()
+	}
+}
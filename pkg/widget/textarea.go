@@ -0,0 +1,718 @@
+package widget
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/text"
+)
+
+// textLine is one displayed line of a text area, a range of the buffer
+type textLine struct {
+	start, end int
+	// soft is set when the line was broken by wrapping rather than a newline
+	soft bool
+	// number is the 1-based paragraph number on the first line of a
+	// paragraph and 0 on its wrapped continuation lines
+	number int
+}
+
+// TextAreaWidget is a multi-line text editor. Lines wrap at word boundaries
+// to the width of the box, the view scrolls to follow the caret and edits
+// can be undone and redone. Optional line numbers are drawn in a gutter.
+type TextAreaWidget struct {
+	Base
+	font        *text.Font
+	size        float32
+	padding     float32
+	wrap        bool
+	lineNumbers bool
+	buffer      editBuffer
+	history     editHistory
+	onChange    func(s string)
+	// lines holds the displayed lines, rebuilt when linesValid is unset
+	lines      []textLine
+	linesValid bool
+	// gutter is the width of the line number column
+	gutter float32
+	// scroll is how far the text is shifted up and scrollX how far left
+	scroll  float32
+	scrollX float32
+	// goalX is the column the caret returns to when moving between lines
+	goalX     float32
+	goalValid bool
+	// dragging is set while the mouse selects text
+	dragging bool
+	// blink tracks the caret blink cycle while focused
+	blink caretBlink
+	// compose is the text an input method is composing at the caret
+	compose composition
+
+	// Colors follow the theme unless set with Colors
+	backgroundColor colorOverride
+	textColor       colorOverride
+	selectionColor  colorOverride
+}
+
+// TextArea creates a new empty multi-line text editor using the given font.
+// The editor defaults to 14 pixel text with word wrap on and line numbers off.
+func TextArea(font *text.Font) *TextAreaWidget {
+	return &TextAreaWidget{
+		font:    font,
+		size:    14,
+		padding: 4,
+		wrap:    true,
+	}
+}
+
+// Size sets the pixel size of the text and returns the editor for chaining
+func (t *TextAreaWidget) Size(size float32) *TextAreaWidget {
+	t.size = size
+	t.linesValid = false
+	t.MarkNeedsLayout()
+	return t
+}
+
+// Padding sets the space between the editor edge and its text and returns the editor for chaining
+func (t *TextAreaWidget) Padding(padding float32) *TextAreaWidget {
+	t.padding = padding
+	t.linesValid = false
+	t.MarkNeedsLayout()
+	return t
+}
+
+// Wrap sets whether long lines wrap to the width of the editor and returns
+// the editor for chaining. Unwrapped lines scroll horizontally.
+func (t *TextAreaWidget) Wrap(wrap bool) *TextAreaWidget {
+	t.wrap = wrap
+	t.scrollX = 0
+	t.linesValid = false
+	t.MarkNeedsPaint()
+	return t
+}
+
+// LineNumbers sets whether paragraph numbers are shown in a gutter and returns the editor for chaining
+func (t *TextAreaWidget) LineNumbers(show bool) *TextAreaWidget {
+	t.lineNumbers = show
+	t.linesValid = false
+	t.MarkNeedsPaint()
+	return t
+}
+
+// OnChange sets the callback invoked with the new text after each edit and returns the editor for chaining
+func (t *TextAreaWidget) OnChange(fn func(s string)) *TextAreaWidget {
+	t.onChange = fn
+	return t
+}
+
+// Colors sets the background, text and selection colors, replacing the
+// theme's, and returns the editor for chaining
+func (t *TextAreaWidget) Colors(background, text, selection [4]float32) *TextAreaWidget {
+	t.backgroundColor = override(background)
+	t.textColor = override(text)
+	t.selectionColor = override(selection)
+	t.MarkNeedsPaint()
+	return t
+}
+
+// SetText replaces the text, moves the caret to the end and clears the undo history
+func (t *TextAreaWidget) SetText(s string) {
+	t.buffer.setText(strings.ReplaceAll(s, "\r\n", "\n"))
+	t.history.clear()
+	t.linesValid = false
+	t.scrollToCaret()
+	t.MarkNeedsPaint()
+}
+
+// Text returns the current text
+func (t *TextAreaWidget) Text() string {
+	return t.buffer.String()
+}
+
+// Undo reverts the last edit, reporting whether there was one
+func (t *TextAreaWidget) Undo() bool {
+	if !t.history.undoTo(&t.buffer) {
+		return false
+	}
+	t.edited(time.Now(), true)
+	return true
+}
+
+// Redo reapplies the last undone edit, reporting whether there was one
+func (t *TextAreaWidget) Redo() bool {
+	if !t.history.redoTo(&t.buffer) {
+		return false
+	}
+	t.edited(time.Now(), true)
+	return true
+}
+
+// GetConstraints returns a minimum height that fits one line of text inside the padding
+func (t *TextAreaWidget) GetConstraints() Constraints {
+	return NewFlexConstraints(0, t.font.Face(t.size).LineHeight()+2*t.padding, 1e9, 1e9)
+}
+
+// Measure returns the minimum size of the text area
+func (t *TextAreaWidget) Measure(constraints Constraints) Size {
+	return minSize(t.GetConstraints())
+}
+
+// Layout implements the Widget interface for TextAreaWidget; editors take all
+// the space offered
+func (t *TextAreaWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	if size.Width != t.CachedSize().Width {
+		t.linesValid = false
+	}
+	t.SetLayout(ctx, constraints, size)
+	t.scrollToCaret()
+	return
+}
+
+// Paint implements the Widget interface for TextAreaWidget
+func (t *TextAreaWidget) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	focused := hasFocus(t)
+	border := th.Border
+	if focused {
+		border = th.Primary
+	}
+	fillBordered(ctx, box, th.Radius.Small, border, t.backgroundColor.or(th.Field))
+	inner := NewBox(box.Position.X+1, box.Position.Y+1, box.Size.Width-2, box.Size.Height-2, box.Constraints)
+	textColor := t.textColor.or(th.Text)
+
+	face := t.font.Face(t.size)
+	lines := t.ensureLines()
+	lineHeight := face.LineHeight()
+	list := ctx.DrawList
+	list.PushClip(inner.Position.X, inner.Position.Y, inner.Size.Width, inner.Size.Height)
+	defer list.PopClip()
+
+	// Only the lines inside the view are drawn
+	top := box.Position.Y + t.padding - t.scroll
+	first := max(int(t.scroll/lineHeight), 0)
+	last := min(int((t.scroll+box.Size.Height)/lineHeight)+1, len(lines))
+
+	if t.gutter > 0 {
+		list.Rect(inner.Position.X, inner.Position.Y, t.gutter-1, inner.Size.Height, th.Track)
+		for i := first; i < last; i++ {
+			if lines[i].number == 0 {
+				continue
+			}
+			number := strconv.Itoa(lines[i].number)
+			x := box.Position.X + t.gutter - t.padding - face.Measure(number)
+			face.Draw(list, x, top+float32(i)*lineHeight+face.Ascent(), number, th.TextMuted)
+		}
+	}
+
+	// Text scrolls horizontally beneath the gutter
+	textLeft := box.Position.X + t.gutter
+	list.PushClip(textLeft, inner.Position.Y, box.Position.X+box.Size.Width-1-textLeft, inner.Size.Height)
+	defer list.PopClip()
+	x := textLeft + t.padding - t.scrollX
+
+	if !focused {
+		// A composition ends with focus
+		t.compose.reset()
+	}
+	caretLine := t.lineOf(t.buffer.caret)
+	var composeX float32
+	start, end := t.buffer.selection()
+	for i := first; i < last; i++ {
+		l := lines[i]
+		y := top + float32(i)*lineHeight
+		if focused && start != end && start <= l.end && end > l.start {
+			shaped := t.lineLayout(face, l)
+			spans := shaped.Selection(max(start, l.start)-l.start, min(end, l.end)-l.start)
+			if end > l.end && !l.soft {
+				// Show the selected line break as a space at the end of the
+				// line
+				space := face.Glyph(' ').Advance
+				if shaped.RTL {
+					spans = append(spans, [2]float32{-space, 0})
+				} else {
+					spans = append(spans, [2]float32{shaped.Width, shaped.Width + space})
+				}
+			}
+			for _, span := range spans {
+				list.Rect(x+span[0], y, span[1]-span[0], lineHeight, t.selectionColor.or(th.Selection))
+			}
+		}
+		if i == caretLine && t.compose.active() {
+			// Composed text is shown at the caret, pushing the text after
+			// it on
+			c := t.buffer.caret
+			before := face.Measure(string(t.buffer.text[l.start:c]))
+			face.Draw(list, x, y+face.Ascent(), string(t.buffer.text[l.start:c]), textColor)
+			width, at := t.compose.draw(list, face, x+before, y+face.Ascent(), textColor)
+			face.Draw(list, x+before+width, y+face.Ascent(), string(t.buffer.text[c:l.end]), textColor)
+			composeX = before + at
+			continue
+		}
+		face.Draw(list, x, y+face.Ascent(), string(t.buffer.text[l.start:l.end]), textColor)
+	}
+
+	if focused {
+		l := lines[caretLine]
+		caret := x + composeX
+		if !t.compose.active() {
+			caret = x + t.lineLayout(face, l).CaretX(t.buffer.caret-l.start)
+		}
+		caret = float32(math.Round(float64(caret)))
+		y := top + float32(caretLine)*lineHeight
+		reportCaret(ctx, caret, y, lineHeight)
+		if t.blink.visible() {
+			list.Rect(caret, y, 1, lineHeight, textColor)
+		}
+	}
+
+	// A thin thumb shows the position within long text
+	view := box.Size.Height - 2*t.padding
+	if content := float32(len(lines)) * lineHeight; content > view {
+		length, position := thumbSpan(view, view, content, t.scroll)
+		list.Rect(box.Position.X+box.Size.Width-5, box.Position.Y+t.padding+position, 3, length, th.Thumb)
+	}
+	return
+}
+
+// CursorAt implements CursorProvider, showing the text cursor over the text
+// and the normal arrow over the line numbers
+func (t *TextAreaWidget) CursorAt(p Point) Cursor {
+	if p.X < t.paintBox.Position.X+t.gutter {
+		return interfaces.CursorArrow
+	}
+	return interfaces.CursorIBeam
+}
+
+// navigable lets gamepad navigation reach the text area
+func (t *TextAreaWidget) navigable() bool {
+	return true
+}
+
+// Accessibility implements Accessible
+func (t *TextAreaWidget) Accessibility() AccessNode {
+	return AccessNode{
+		Role:    interfaces.RoleTextArea,
+		Value:   t.buffer.String(),
+		State:   interfaces.StateMultiline,
+		Actions: []AccessAction{interfaces.AccessSetValue},
+	}
+}
+
+// AccessAction implements AccessActor, replacing the text as an edit that
+// can be undone
+func (t *TextAreaWidget) AccessAction(ctx *Context, action AccessAction, value string) bool {
+	if action != interfaces.AccessSetValue {
+		return false
+	}
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	t.change(ctx, false, func() bool {
+		if value == t.buffer.String() {
+			return false
+		}
+		t.buffer.selectAll()
+		t.buffer.insert(value)
+		return true
+	})
+	return true
+}
+
+// HandleEvent implements the Widget interface for TextAreaWidget
+func (t *TextAreaWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft {
+			return false
+		}
+		switch e.Action {
+		case interfaces.ActionPress:
+			// Presses are only delivered when the cursor is inside the box
+			requestFocus(t)
+			t.buffer.moveTo(t.indexAt(box, e.Position), e.Mods&interfaces.ModShift != 0)
+			t.dragging = true
+			t.moved(ctx)
+			return true
+		case interfaces.ActionRelease:
+			if !t.dragging {
+				return false
+			}
+			t.dragging = false
+			return true
+		}
+	case interfaces.MouseMoveEvent:
+		if !t.dragging {
+			return false
+		}
+		if pos := t.indexAt(box, e.Position); pos != t.buffer.caret {
+			t.buffer.moveTo(pos, true)
+			t.moved(ctx)
+		}
+		return true
+	case interfaces.ScrollEvent:
+		before := t.scroll
+		t.scroll -= e.Offset.Y * 3 * t.font.Face(t.size).LineHeight()
+		t.clampScroll()
+		if t.scroll == before {
+			return false
+		}
+		t.MarkNeedsPaint()
+		return true
+	case interfaces.KeyEvent:
+		if !hasFocus(t) || e.Action == interfaces.ActionRelease {
+			return false
+		}
+		return t.key(ctx, e)
+	case interfaces.CharEvent:
+		if !hasFocus(t) {
+			return false
+		}
+		t.compose.reset()
+		t.change(ctx, true, func() bool {
+			t.buffer.insert(string(e.Char))
+			return true
+		})
+		return true
+	case interfaces.PreeditEvent:
+		if !hasFocus(t) {
+			return false
+		}
+		t.compose.update(e)
+		t.edited(frameTime(ctx), false)
+		return true
+	}
+	return false
+}
+
+// key applies an editing or movement key
+func (t *TextAreaWidget) key(ctx *Context, e interfaces.KeyEvent) (handled bool) {
+	b := &t.buffer
+	extend := e.Mods&interfaces.ModShift != 0
+	word := e.Mods&(interfaces.ModControl|interfaces.ModAlt) != 0
+	shortcut := e.Mods&(interfaces.ModControl|interfaces.ModSuper) != 0
+	switch e.Key {
+	case interfaces.KeyLeft, interfaces.KeyRight:
+		l := t.ensureLines()[t.lineOf(b.caret)]
+		b.moveVisual(t.lineLayout(t.font.Face(t.size), l), l.start, t.lineEnd(l), e.Key == interfaces.KeyRight, word, extend)
+	case interfaces.KeyUp:
+		t.moveLines(ctx, -1, extend)
+		return true
+	case interfaces.KeyDown:
+		t.moveLines(ctx, 1, extend)
+		return true
+	case interfaces.KeyPageUp:
+		t.moveLines(ctx, -t.pageLines(), extend)
+		return true
+	case interfaces.KeyPageDown:
+		t.moveLines(ctx, t.pageLines(), extend)
+		return true
+	case interfaces.KeyHome:
+		if shortcut {
+			b.moveTo(0, extend)
+		} else {
+			b.moveTo(t.ensureLines()[t.lineOf(b.caret)].start, extend)
+		}
+	case interfaces.KeyEnd:
+		if shortcut {
+			b.moveTo(len(b.text), extend)
+		} else {
+			b.moveTo(t.lineEnd(t.ensureLines()[t.lineOf(b.caret)]), extend)
+		}
+	case interfaces.KeyBackspace:
+		t.change(ctx, false, func() bool { return b.deleteBackward(word) })
+		return true
+	case interfaces.KeyDelete:
+		t.change(ctx, false, func() bool { return b.deleteForward(word) })
+		return true
+	case interfaces.KeyEnter:
+		t.change(ctx, false, func() bool {
+			b.insert("\n")
+			return true
+		})
+		return true
+	case interfaces.KeyA:
+		if !shortcut {
+			return false
+		}
+		b.selectAll()
+	case interfaces.KeyC:
+		if !shortcut {
+			return false
+		}
+		if b.hasSelection() && ctx.Clipboard != nil {
+			ctx.Clipboard.SetText(b.selectedText())
+		}
+		return true
+	case interfaces.KeyX:
+		if !shortcut {
+			return false
+		}
+		if b.hasSelection() && ctx.Clipboard != nil {
+			ctx.Clipboard.SetText(b.selectedText())
+			t.change(ctx, false, b.deleteSelection)
+		}
+		return true
+	case interfaces.KeyV:
+		if !shortcut {
+			return false
+		}
+		if ctx.Clipboard != nil {
+			paste := strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(ctx.Clipboard.Text())
+			t.change(ctx, false, func() bool {
+				if paste == "" && !b.hasSelection() {
+					return false
+				}
+				b.insert(paste)
+				return true
+			})
+		}
+		return true
+	case interfaces.KeyZ:
+		if !shortcut {
+			return false
+		}
+		if extend {
+			t.Redo()
+		} else {
+			t.Undo()
+		}
+		return true
+	case interfaces.KeyY:
+		if !shortcut {
+			return false
+		}
+		t.Redo()
+		return true
+	default:
+		return false
+	}
+	t.moved(ctx)
+	return true
+}
+
+// change applies an edit, recording the previous state for undo when it
+// changed the text. Merged edits undo together with the ones before them.
+func (t *TextAreaWidget) change(ctx *Context, merge bool, edit func() bool) {
+	before := t.buffer.snapshot()
+	if !edit() {
+		return
+	}
+	t.history.push(before, merge)
+	t.edited(frameTime(ctx), true)
+}
+
+// moved updates the view after the caret or selection moved without an edit
+func (t *TextAreaWidget) moved(ctx *Context) {
+	t.history.breakMerge()
+	t.goalValid = false
+	t.edited(frameTime(ctx), false)
+}
+
+// edited restarts the caret blink, scrolls the caret into view and repaints,
+// rewrapping and notifying the change callback when the text changed
+func (t *TextAreaWidget) edited(now time.Time, changed bool) {
+	if changed {
+		t.linesValid = false
+		t.goalValid = false
+	}
+	t.blink.restart(now)
+	t.scrollToCaret()
+	t.MarkNeedsPaint()
+	if changed && t.onChange != nil {
+		t.onChange(t.buffer.String())
+	}
+}
+
+// tick blinks the caret while the editor has focus
+func (t *TextAreaWidget) tick(ctx *Context) {
+	now := frameTime(ctx)
+	if t.blink.update(now) {
+		t.MarkNeedsPaint()
+	}
+	ctx.Clock.WakeAt(t.blink.next(now))
+}
+
+// moveLines moves the caret up or down by a number of displayed lines,
+// keeping it near the column it started from
+func (t *TextAreaWidget) moveLines(ctx *Context, delta int, extend bool) {
+	face := t.font.Face(t.size)
+	lines := t.ensureLines()
+	b := &t.buffer
+	i := t.lineOf(b.caret)
+	if !t.goalValid {
+		t.goalX = t.lineLayout(face, lines[i]).CaretX(b.caret - lines[i].start)
+		t.goalValid = true
+	}
+	switch j := i + delta; {
+	case j < 0:
+		b.moveTo(0, extend)
+	case j >= len(lines):
+		b.moveTo(len(b.text), extend)
+	default:
+		l := lines[j]
+		pos := l.start + face.Index(string(b.text[l.start:l.end]), t.goalX)
+		b.moveTo(min(pos, t.lineEnd(l)), extend)
+	}
+	// Keep the goal column for the next vertical move
+	t.history.breakMerge()
+	t.edited(frameTime(ctx), false)
+}
+
+// pageLines returns the number of lines that fit in the view
+func (t *TextAreaWidget) pageLines() int {
+	view := t.CachedSize().Height - 2*t.padding
+	return max(int(view/t.font.Face(t.size).LineHeight()), 1)
+}
+
+// lineEnd returns the last caret position shown on a line. A position at the
+// end of a wrapped line is drawn at the start of the next, so the caret
+// stops before the space the line broke at.
+func (t *TextAreaWidget) lineEnd(l textLine) int {
+	if l.soft && l.end > l.start {
+		return l.end - 1
+	}
+	return l.end
+}
+
+// lineLayout returns a displayed line shaped for drawing and caret placement
+func (t *TextAreaWidget) lineLayout(face *text.Face, l textLine) *text.Layout {
+	return face.Layout(string(t.buffer.text[l.start:l.end]))
+}
+
+// lineOf returns the index of the displayed line holding a caret position
+func (t *TextAreaWidget) lineOf(pos int) int {
+	lines := t.ensureLines()
+	i := sort.Search(len(lines), func(i int) bool { return lines[i].start > pos }) - 1
+	return max(i, 0)
+}
+
+// indexAt returns the caret position closest to a point in window coordinates
+func (t *TextAreaWidget) indexAt(box *Box, p Point) int {
+	face := t.font.Face(t.size)
+	lines := t.ensureLines()
+	i := int(math.Floor(float64((p.Y - box.Position.Y - t.padding + t.scroll) / face.LineHeight())))
+	l := lines[min(max(i, 0), len(lines)-1)]
+	x := p.X - box.Position.X - t.gutter - t.padding + t.scrollX
+	pos := l.start + face.Index(string(t.buffer.text[l.start:l.end]), x)
+	return min(pos, t.lineEnd(l))
+}
+
+// scrollToCaret scrolls the view so the caret line is visible and, when
+// lines do not wrap, the caret column too
+func (t *TextAreaWidget) scrollToCaret() {
+	face := t.font.Face(t.size)
+	lines := t.ensureLines()
+	lineHeight := face.LineHeight()
+	i := t.lineOf(t.buffer.caret)
+	y := float32(i) * lineHeight
+	view := t.CachedSize().Height - 2*t.padding
+	if y+lineHeight > t.scroll+view {
+		t.scroll = y + lineHeight - view
+	}
+	if y < t.scroll {
+		t.scroll = y
+	}
+	t.clampScroll()
+
+	if t.wrap {
+		t.scrollX = 0
+		return
+	}
+	visible := t.textWidth() - 1
+	caret := t.lineLayout(face, lines[i]).CaretX(t.buffer.caret - lines[i].start)
+	if caret-t.scrollX > visible {
+		t.scrollX = caret - visible
+	}
+	if caret < t.scrollX {
+		t.scrollX = caret
+	}
+	t.scrollX = max(t.scrollX, 0)
+}
+
+// clampScroll keeps the vertical scroll within the text
+func (t *TextAreaWidget) clampScroll() {
+	lineHeight := t.font.Face(t.size).LineHeight()
+	view := t.CachedSize().Height - 2*t.padding
+	content := float32(len(t.ensureLines())) * lineHeight
+	t.scroll = min(max(t.scroll, 0), max(content-view, 0))
+}
+
+// textWidth returns the width available for text beside the gutter
+func (t *TextAreaWidget) textWidth() float32 {
+	return t.CachedSize().Width - t.gutter - 2*t.padding
+}
+
+// ensureLines splits the text into displayed lines if it changed since the
+// last call, wrapping paragraphs to the text width
+func (t *TextAreaWidget) ensureLines() []textLine {
+	if t.linesValid {
+		return t.lines
+	}
+	face := t.font.Face(t.size)
+	text := t.buffer.text
+	t.gutter = 0
+	if t.lineNumbers {
+		paragraphs := strings.Count(string(text), "\n") + 1
+		t.gutter = face.Measure(strconv.Itoa(paragraphs)) + 2*t.padding
+	}
+	width := t.textWidth()
+	t.lines = t.lines[:0]
+	number := 1
+	for start := 0; start <= len(text); number++ {
+		end := start
+		for end < len(text) && text[end] != '\n' {
+			end++
+		}
+		t.wrapParagraph(face, start, end, width, number)
+		start = end + 1
+	}
+	t.linesValid = true
+	return t.lines
+}
+
+// wrapParagraph appends the displayed lines of the paragraph from start to
+// end, breaking after spaces where possible and mid-word otherwise
+func (t *TextAreaWidget) wrapParagraph(face *text.Face, start, end int, width float32, number int) {
+	text := t.buffer.text
+	if !t.wrap || width <= 0 {
+		t.lines = append(t.lines, textLine{start: start, end: end, number: number})
+		return
+	}
+	for {
+		i, brk := start, -1
+		var used float32
+		for i < end {
+			advance := face.Glyph(text[i]).Advance
+			if used+advance > width && i > start {
+				break
+			}
+			used += advance
+			if unicode.IsSpace(text[i]) {
+				brk = i + 1
+			}
+			i++
+		}
+		if i >= end {
+			t.lines = append(t.lines, textLine{start: start, end: end, number: number})
+			return
+		}
+		if unicode.IsSpace(text[i]) {
+			// Spaces may hang past the edge so the next line starts with a word
+			for i < end && unicode.IsSpace(text[i]) {
+				i++
+			}
+		} else if brk > start {
+			i = brk
+		}
+		if i >= end {
+			t.lines = append(t.lines, textLine{start: start, end: end, number: number})
+			return
+		}
+		t.lines = append(t.lines, textLine{start: start, end: i, soft: true, number: number})
+		start, number = i, 0
+	}
+}
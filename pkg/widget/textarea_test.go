@@ -0,0 +1,93 @@
+package widget_test
+
+import (
+	"testing"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/widget"
+)
+
+func TestTextAreaEditing(t *testing.T) {
+	font := loadFont(t)
+	ctrl := interfaces.ModControl
+	tests := []struct {
+		name string
+		// start is the text the area holds before the edit
+		start string
+		edit  func(h *harness)
+		want  string
+	}{
+		{"lines", "", func(h *harness) {
+			h.typeText("one")
+			h.key(interfaces.KeyEnter, 0)
+			h.typeText("two")
+		}, "one\ntwo"},
+		{"up a line", "one\ntwo", func(h *harness) {
+			h.key(interfaces.KeyUp, 0)
+			h.typeText("!")
+		}, "one!\ntwo"},
+		{"line home", "one\ntwo", func(h *harness) {
+			h.key(interfaces.KeyHome, 0)
+			h.typeText(">")
+		}, "one\n>two"},
+		{"document home", "one\ntwo", func(h *harness) {
+			h.key(interfaces.KeyHome, ctrl)
+			h.key(interfaces.KeyDelete, 0)
+		}, "ne\ntwo"},
+		{"backspace joins lines", "one\ntwo", func(h *harness) {
+			h.key(interfaces.KeyHome, 0)
+			h.key(interfaces.KeyBackspace, 0)
+		}, "onetwo"},
+		{"paste keeps lines", "", func(h *harness) {
+			h.screen.Clipboard().SetText("a\r\nb")
+			h.key(interfaces.KeyV, ctrl)
+		}, "a\nb"},
+		{"undo typing as one step", "x", func(h *harness) {
+			h.typeText("yz")
+			h.key(interfaces.KeyZ, ctrl)
+		}, "x"},
+		{"undo and redo", "", func(h *harness) {
+			h.typeText("ab")
+			h.key(interfaces.KeyEnter, 0)
+			h.key(interfaces.KeyZ, ctrl)
+			h.key(interfaces.KeyZ, ctrl)
+			h.key(interfaces.KeyZ, ctrl|interfaces.ModShift)
+		}, "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			area := widget.TextArea(font)
+			area.SetText(tt.start)
+			h := newHarness(t, widget.Column().Rigid(widget.NewFixedSize(240, 120, area)), 260, 140)
+			// Focusing with a click moves the caret, so put it back at the end
+			h.click(h.find(area))
+			h.key(interfaces.KeyEnd, ctrl)
+			tt.edit(h)
+			if got := area.Text(); got != tt.want {
+				t.Errorf("text %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextAreaUndoHistory(t *testing.T) {
+	font := loadFont(t)
+	area := widget.TextArea(font)
+	area.SetText("kept")
+	if area.Undo() {
+		t.Error("setting the text left something to undo")
+	}
+	h := newHarness(t, widget.Column().Rigid(widget.NewFixedSize(200, 80, area)), 220, 100)
+	h.click(h.find(area))
+	h.key(interfaces.KeyEnd, interfaces.ModControl)
+	h.typeText(" more")
+	if !area.Undo() || area.Text() != "kept" {
+		t.Errorf("undo left %q, want %q", area.Text(), "kept")
+	}
+	if !area.Redo() || area.Text() != "kept more" {
+		t.Errorf("redo left %q, want %q", area.Text(), "kept more")
+	}
+	if area.Redo() {
+		t.Error("redo past the latest edit")
+	}
+}
@@ -0,0 +1,392 @@
+package widget
+
+import (
+	"math"
+	"strings"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/text"
+)
+
+// TextInputWidget is an editable single line of text. It takes keyboard
+// focus when clicked and supports selection with the mouse and shift keys,
+// word movement with ctrl and clipboard cut, copy and paste.
+type TextInputWidget struct {
+	Base
+	font        *text.Font
+	size        float32
+	padding     float32
+	buffer      editBuffer
+	placeholder string
+	onChange    func(s string)
+	onSubmit    func(s string)
+	// keys sees the keys pressed before the input, for widgets built around
+	// an input, and reports whether it used the key
+	keys func(e interfaces.KeyEvent) bool
+	// frameless leaves out the background and border, for inputs drawn
+	// inside the field of another widget
+	frameless bool
+	// scroll is how far the text is shifted left to keep the caret visible
+	scroll float32
+	// dragging is set while the mouse selects text
+	dragging bool
+	// blink tracks the caret blink cycle while focused
+	blink caretBlink
+	// compose is the text an input method is composing at the caret
+	compose composition
+
+	// Colors follow the theme unless set with Colors
+	backgroundColor colorOverride
+	textColor       colorOverride
+	selectionColor  colorOverride
+}
+
+// TextInput creates a new empty single line text input using the given font.
+// The input defaults to 14 pixel text.
+func TextInput(font *text.Font) *TextInputWidget {
+	return &TextInputWidget{
+		font:    font,
+		size:    14,
+		padding: 4,
+	}
+}
+
+// Size sets the pixel size of the text and returns the input for chaining
+func (t *TextInputWidget) Size(size float32) *TextInputWidget {
+	t.size = size
+	t.MarkNeedsLayout()
+	return t
+}
+
+// Padding sets the space between the input edge and its text and returns the input for chaining
+func (t *TextInputWidget) Padding(padding float32) *TextInputWidget {
+	t.padding = padding
+	t.MarkNeedsLayout()
+	return t
+}
+
+// Placeholder sets the hint shown while the input is empty and unfocused and returns the input for chaining
+func (t *TextInputWidget) Placeholder(s string) *TextInputWidget {
+	t.placeholder = s
+	t.MarkNeedsPaint()
+	return t
+}
+
+// OnChange sets the callback invoked with the new text after each edit and returns the input for chaining
+func (t *TextInputWidget) OnChange(fn func(s string)) *TextInputWidget {
+	t.onChange = fn
+	return t
+}
+
+// OnSubmit sets the callback invoked when enter is pressed and returns the input for chaining
+func (t *TextInputWidget) OnSubmit(fn func(s string)) *TextInputWidget {
+	t.onSubmit = fn
+	return t
+}
+
+// Colors sets the background, text and selection colors, replacing the
+// theme's, and returns the input for chaining
+func (t *TextInputWidget) Colors(background, text, selection [4]float32) *TextInputWidget {
+	t.backgroundColor = override(background)
+	t.textColor = override(text)
+	t.selectionColor = override(selection)
+	t.MarkNeedsPaint()
+	return t
+}
+
+// SetText replaces the text and moves the caret to the end
+func (t *TextInputWidget) SetText(s string) {
+	t.buffer.setText(s)
+	t.scrollToCaret()
+	t.MarkNeedsPaint()
+}
+
+// Text returns the current text
+func (t *TextInputWidget) Text() string {
+	return t.buffer.String()
+}
+
+// GetConstraints returns a height that fits one line of text inside the padding
+func (t *TextInputWidget) GetConstraints() Constraints {
+	height := t.font.Face(t.size).LineHeight() + 2*t.padding
+	return NewFlexConstraints(0, height, 1e9, height)
+}
+
+// Measure returns the minimum size of the text input
+func (t *TextInputWidget) Measure(constraints Constraints) Size {
+	return minSize(t.GetConstraints())
+}
+
+// Layout implements the Widget interface for TextInputWidget; inputs take all
+// the space offered
+func (t *TextInputWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	t.SetLayout(ctx, constraints, size)
+	t.scrollToCaret()
+	return
+}
+
+// Paint implements the Widget interface for TextInputWidget
+func (t *TextInputWidget) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	focused := hasFocus(t)
+	border := th.Border
+	if focused {
+		border = th.Primary
+	}
+	if !t.frameless {
+		fillBordered(ctx, box, th.Radius.Small, border, t.backgroundColor.or(th.Field))
+	}
+	inner := NewBox(box.Position.X+1, box.Position.Y+1, box.Size.Width-2, box.Size.Height-2, box.Constraints)
+	textColor := t.textColor.or(th.Text)
+
+	list := ctx.DrawList
+	list.PushClip(inner.Position.X, inner.Position.Y, inner.Size.Width, inner.Size.Height)
+	defer list.PopClip()
+
+	face := t.font.Face(t.size)
+	x := box.Position.X + t.padding - t.scroll
+	top := box.Position.Y + (box.Size.Height-face.LineHeight())/2
+	s := t.buffer.String()
+
+	if focused && t.buffer.hasSelection() {
+		// Selected right to left text within left to right text, or the
+		// reverse, shows as separate spans
+		start, end := t.buffer.selection()
+		for _, span := range face.Layout(s).Selection(start, end) {
+			list.Rect(x+span[0], top, span[1]-span[0], face.LineHeight(), t.selectionColor.or(th.Selection))
+		}
+	}
+	if !focused {
+		// A composition ends with focus
+		t.compose.reset()
+	}
+	baseline := top + face.Ascent()
+	caretX := t.caretX(face)
+	switch {
+	case t.compose.active():
+		// Composed text is shown at the caret, pushing the text after it on
+		before := face.Measure(string(t.buffer.text[:t.buffer.caret]))
+		face.Draw(list, x, baseline, string(t.buffer.text[:t.buffer.caret]), textColor)
+		width, at := t.compose.draw(list, face, x+before, baseline, textColor)
+		face.Draw(list, x+before+width, baseline, string(t.buffer.text[t.buffer.caret:]), textColor)
+		caretX = before + at
+	case s == "" && !focused:
+		face.Draw(list, x, baseline, t.placeholder, th.TextMuted)
+	default:
+		face.Draw(list, x, baseline, s, textColor)
+	}
+	if focused {
+		caret := float32(math.Round(float64(x + caretX)))
+		reportCaret(ctx, caret, top, face.LineHeight())
+		if t.blink.visible() {
+			list.Rect(caret, top, 1, face.LineHeight(), textColor)
+		}
+	}
+	return
+}
+
+// CursorAt implements CursorProvider, showing the text cursor
+func (t *TextInputWidget) CursorAt(p Point) Cursor {
+	return interfaces.CursorIBeam
+}
+
+// navigable lets gamepad navigation reach the input
+func (t *TextInputWidget) navigable() bool {
+	return true
+}
+
+// Accessibility implements Accessible, naming the input by its placeholder
+func (t *TextInputWidget) Accessibility() AccessNode {
+	return AccessNode{
+		Role:    interfaces.RoleTextInput,
+		Name:    t.placeholder,
+		Value:   t.buffer.String(),
+		Actions: []AccessAction{interfaces.AccessSetValue},
+	}
+}
+
+// AccessAction implements AccessActor, replacing the text as typing over a
+// selection of all of it would
+func (t *TextInputWidget) AccessAction(ctx *Context, action AccessAction, value string) bool {
+	if action != interfaces.AccessSetValue {
+		return false
+	}
+	value = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(value)
+	changed := value != t.buffer.String()
+	t.buffer.selectAll()
+	t.buffer.insert(value)
+	t.edited(ctx, changed)
+	return true
+}
+
+// HandleEvent implements the Widget interface for TextInputWidget
+func (t *TextInputWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft {
+			return false
+		}
+		switch e.Action {
+		case interfaces.ActionPress:
+			// Presses are only delivered when the cursor is inside the box
+			requestFocus(t)
+			t.buffer.moveTo(t.indexAt(box, e.Position.X), e.Mods&interfaces.ModShift != 0)
+			t.dragging = true
+			t.edited(ctx, false)
+			return true
+		case interfaces.ActionRelease:
+			if !t.dragging {
+				return false
+			}
+			t.dragging = false
+			return true
+		}
+	case interfaces.MouseMoveEvent:
+		if !t.dragging {
+			return false
+		}
+		if pos := t.indexAt(box, e.Position.X); pos != t.buffer.caret {
+			t.buffer.moveTo(pos, true)
+			t.edited(ctx, false)
+		}
+		return true
+	case interfaces.KeyEvent:
+		if !hasFocus(t) || e.Action == interfaces.ActionRelease {
+			return false
+		}
+		if t.keys != nil && t.keys(e) {
+			return true
+		}
+		return t.key(ctx, e)
+	case interfaces.CharEvent:
+		if !hasFocus(t) {
+			return false
+		}
+		t.compose.reset()
+		t.buffer.insert(string(e.Char))
+		t.edited(ctx, true)
+		return true
+	case interfaces.PreeditEvent:
+		if !hasFocus(t) {
+			return false
+		}
+		t.compose.update(e)
+		t.edited(ctx, false)
+		return true
+	}
+	return false
+}
+
+// key applies an editing or movement key
+func (t *TextInputWidget) key(ctx *Context, e interfaces.KeyEvent) (handled bool) {
+	b := &t.buffer
+	extend := e.Mods&interfaces.ModShift != 0
+	word := e.Mods&(interfaces.ModControl|interfaces.ModAlt) != 0
+	shortcut := e.Mods&(interfaces.ModControl|interfaces.ModSuper) != 0
+	var changed bool
+	switch e.Key {
+	case interfaces.KeyLeft, interfaces.KeyRight:
+		b.moveVisual(t.font.Face(t.size).Layout(b.String()), 0, len(b.text), e.Key == interfaces.KeyRight, word, extend)
+	case interfaces.KeyHome:
+		b.moveTo(0, extend)
+	case interfaces.KeyEnd:
+		b.moveTo(len(b.text), extend)
+	case interfaces.KeyBackspace:
+		changed = b.deleteBackward(word)
+	case interfaces.KeyDelete:
+		changed = b.deleteForward(word)
+	case interfaces.KeyEnter:
+		if t.onSubmit != nil {
+			t.onSubmit(b.String())
+		}
+		return true
+	case interfaces.KeyA:
+		if !shortcut {
+			return false
+		}
+		b.selectAll()
+	case interfaces.KeyC:
+		if !shortcut {
+			return false
+		}
+		if b.hasSelection() && ctx.Clipboard != nil {
+			ctx.Clipboard.SetText(b.selectedText())
+		}
+		return true
+	case interfaces.KeyX:
+		if !shortcut {
+			return false
+		}
+		if b.hasSelection() && ctx.Clipboard != nil {
+			ctx.Clipboard.SetText(b.selectedText())
+			changed = b.deleteSelection()
+		}
+	case interfaces.KeyV:
+		if !shortcut {
+			return false
+		}
+		if ctx.Clipboard != nil {
+			// A single line input cannot hold line breaks
+			paste := strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(ctx.Clipboard.Text())
+			if paste != "" || b.hasSelection() {
+				b.insert(paste)
+				changed = true
+			}
+		}
+	default:
+		return false
+	}
+	t.edited(ctx, changed)
+	return true
+}
+
+// edited restarts the caret blink and repaints after the caret moved or the
+// text changed, notifying the change callback when it did
+func (t *TextInputWidget) edited(ctx *Context, changed bool) {
+	t.blink.restart(frameTime(ctx))
+	t.scrollToCaret()
+	t.MarkNeedsPaint()
+	if changed && t.onChange != nil {
+		t.onChange(t.buffer.String())
+	}
+}
+
+// tick blinks the caret while the input has focus
+func (t *TextInputWidget) tick(ctx *Context) {
+	now := frameTime(ctx)
+	if t.blink.update(now) {
+		t.MarkNeedsPaint()
+	}
+	ctx.Clock.WakeAt(t.blink.next(now))
+}
+
+// caretX returns the caret position from the left of the text
+func (t *TextInputWidget) caretX(face *text.Face) float32 {
+	return face.Layout(t.buffer.String()).CaretX(t.buffer.caret)
+}
+
+// indexAt returns the caret position closest to a window x coordinate
+func (t *TextInputWidget) indexAt(box *Box, x float32) int {
+	face := t.font.Face(t.size)
+	return face.Index(t.buffer.String(), x-box.Position.X-t.padding+t.scroll)
+}
+
+// scrollToCaret shifts the text so the caret lies within the padded area,
+// without leaving empty space after the end of the text
+func (t *TextInputWidget) scrollToCaret() {
+	face := t.font.Face(t.size)
+	visible := t.CachedSize().Width - 2*t.padding - 1
+	if visible <= 0 {
+		t.scroll = 0
+		return
+	}
+	caret := t.caretX(face)
+	if caret-t.scroll > visible {
+		t.scroll = caret - visible
+	}
+	if caret < t.scroll {
+		t.scroll = caret
+	}
+	t.scroll = max(min(t.scroll, face.Measure(t.buffer.String())-visible), 0)
+}
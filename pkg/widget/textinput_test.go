@@ -0,0 +1,124 @@
+package widget_test
+
+import (
+	"testing"
+
+	"github.com/mleku/goo/pkg/headless"
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/widget"
+)
+
+func TestTextInputEditing(t *testing.T) {
+	font := loadFont(t)
+	ctrl := interfaces.ModControl
+	tests := []struct {
+		name string
+		// edit types and presses keys in the focused input
+		edit func(h *harness)
+		want string
+	}{
+		{"typing", func(h *harness) { h.typeText("hello") }, "hello"},
+		{"backspace", func(h *harness) {
+			h.typeText("hello")
+			h.key(interfaces.KeyBackspace, 0)
+		}, "hell"},
+		{"word backspace", func(h *harness) {
+			h.typeText("hello world")
+			h.key(interfaces.KeyBackspace, ctrl)
+		}, "hello "},
+		{"home and delete", func(h *harness) {
+			h.typeText("xhello")
+			h.key(interfaces.KeyHome, 0)
+			h.key(interfaces.KeyDelete, 0)
+		}, "hello"},
+		{"insert after moving", func(h *harness) {
+			h.typeText("helo")
+			h.key(interfaces.KeyLeft, 0)
+			h.typeText("l")
+		}, "hello"},
+		{"replace the selection", func(h *harness) {
+			h.typeText("hello")
+			h.key(interfaces.KeyA, ctrl)
+			h.typeText("bye")
+		}, "bye"},
+		{"cut and paste", func(h *harness) {
+			h.typeText("abc")
+			h.key(interfaces.KeyLeft, interfaces.ModShift)
+			h.key(interfaces.KeyX, ctrl)
+			h.key(interfaces.KeyHome, 0)
+			h.key(interfaces.KeyV, ctrl)
+		}, "cab"},
+		{"paste joins lines", func(h *harness) {
+			h.screen.Clipboard().SetText("one\ntwo")
+			h.key(interfaces.KeyV, ctrl)
+		}, "one two"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var changed []string
+			input := widget.TextInput(font).OnChange(func(s string) { changed = append(changed, s) })
+			h := newHarness(t, widget.Column().Rigid(widget.NewFixedSize(240, 30, input)), 260, 60)
+			h.click(h.find(input))
+			tt.edit(h)
+			if got := input.Text(); got != tt.want {
+				t.Errorf("text %q, want %q", got, tt.want)
+			}
+			if len(changed) == 0 || changed[len(changed)-1] != tt.want {
+				t.Errorf("changes %q, want the last to be %q", changed, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextInputFocus(t *testing.T) {
+	font := loadFont(t)
+	var submitted string
+	first := widget.TextInput(font).OnSubmit(func(s string) { submitted = s })
+	second := widget.TextInput(font)
+	h := newHarness(t, widget.Column().
+		Rigid(widget.NewFixedSize(200, 30, first)).
+		Rigid(widget.NewFixedSize(200, 30, second)), 220, 80)
+
+	// Typing goes nowhere until an input is focused
+	h.typeText("lost")
+	if first.Text() != "" || second.Text() != "" {
+		t.Fatal("unfocused input took typing")
+	}
+	h.click(h.find(first))
+	if h.root.Focused() != first {
+		t.Fatal("clicking did not focus the input")
+	}
+	if caret := h.screen.InputCaret(); caret.Height <= 0 || caret.Y >= 30 {
+		t.Errorf("input method caret %v is not in the first input", caret)
+	}
+	h.typeText("one")
+	h.key(interfaces.KeyEnter, 0)
+	if submitted != "one" {
+		t.Errorf("submitted %q, want %q", submitted, "one")
+	}
+	h.click(h.find(second))
+	h.typeText("two")
+	if first.Text() != "one" || second.Text() != "two" {
+		t.Errorf("texts %q and %q, want %q and %q", first.Text(), second.Text(), "one", "two")
+	}
+	h.key(interfaces.KeyA, interfaces.ModControl)
+	h.key(interfaces.KeyC, interfaces.ModControl)
+	if got := h.screen.Clipboard().Text(); got != "two" {
+		t.Errorf("copied %q, want %q", got, "two")
+	}
+}
+
+func TestTextInputSetText(t *testing.T) {
+	font := loadFont(t)
+	var changes int
+	input := widget.TextInput(font).OnChange(func(string) { changes++ })
+	h := newHarness(t, widget.Column().Rigid(widget.NewFixedSize(200, 30, input)), 220, 40)
+	empty := h.img
+	input.SetText("shown")
+	if changed := h.frame(); headless.Diff(empty, changed, 0) == 0 {
+		t.Error("setting the text did not repaint the input")
+	}
+	if changes != 0 {
+		t.Error("setting the text called the change callback")
+	}
+}
@@ -0,0 +1,35 @@
+package widget
+
+import (
+	"github.com/mleku/goo/pkg/theme"
+)
+
+// defaultTheme is used when neither the context nor the root sets a theme
+var defaultTheme = theme.Dark()
+
+// themeOf returns the theme widgets in the context are drawn with
+func themeOf(ctx *Context) *theme.Theme {
+	if ctx.Theme != nil {
+		return ctx.Theme
+	}
+	return defaultTheme
+}
+
+// colorOverride is a widget color that follows the theme until set
+type colorOverride struct {
+	color [4]float32
+	set   bool
+}
+
+// override returns a color that replaces the theme's
+func override(color [4]float32) colorOverride {
+	return colorOverride{color: color, set: true}
+}
+
+// or returns the overriding color if set, otherwise the theme color
+func (c colorOverride) or(themed [4]float32) [4]float32 {
+	if c.set {
+		return c.color
+	}
+	return themed
+}
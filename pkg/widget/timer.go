@@ -0,0 +1,49 @@
+package widget
+
+import (
+	"slices"
+	"time"
+)
+
+// timer runs a callback once the frame clock reaches a time. Timers are kept
+// by the root, which runs those due at the start of each frame and asks the
+// clock to wake it for the next.
+type timer struct {
+	at time.Time
+	fn func()
+}
+
+// schedule runs fn at the first frame at or after the given time
+func (r *RootWidget) schedule(at time.Time, fn func()) *timer {
+	t := &timer{at: at, fn: fn}
+	r.timers = append(r.timers, t)
+	return t
+}
+
+// cancel stops a scheduled timer from running, ignoring nil and timers that already ran
+func (r *RootWidget) cancel(t *timer) {
+	if t == nil {
+		return
+	}
+	r.timers = slices.DeleteFunc(r.timers, func(o *timer) bool { return o == t })
+}
+
+// runTimers runs the timers that are due and wakes the clock for the next one
+func (r *RootWidget) runTimers(ctx *Context) {
+	now := frameTime(ctx)
+	var due []*timer
+	r.timers = slices.DeleteFunc(r.timers, func(t *timer) bool {
+		if t.at.After(now) {
+			return false
+		}
+		due = append(due, t)
+		return true
+	})
+	// Callbacks may schedule or cancel other timers
+	for _, t := range due {
+		t.fn()
+	}
+	for _, t := range r.timers {
+		ctx.Clock.WakeAt(t.at)
+	}
+}
@@ -0,0 +1,592 @@
+package widget
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/mleku/goo/pkg/anim"
+	"github.com/mleku/goo/pkg/icons"
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/sound"
+	"github.com/mleku/goo/pkg/text"
+	"github.com/mleku/goo/pkg/theme"
+)
+
+const (
+	// toastMinWidth and toastMaxWidth bound the width of a toast, which
+	// otherwise fits its message
+	toastMinWidth = 240
+	toastMaxWidth = 420
+	// toastPadding is the space around the contents of a toast, toastGap
+	// the space between its message and buttons, and toastAccent the width
+	// of the bar along its start colored by its level
+	toastPadding = 12
+	toastGap     = 10
+	toastAccent  = 4
+	// toastMargin is the space between the toasts and the edges of the
+	// window, and toastSpacing the space between stacked toasts
+	toastMargin  = 16
+	toastSpacing = 8
+	// toastElevation is how far toasts are raised above the window
+	toastElevation = 4
+	// toastSlide is how long a toast takes to slide in or out, and
+	// toastMove how long the others take to close up the stack
+	toastSlide = 250 * time.Millisecond
+	toastMove  = 200 * time.Millisecond
+)
+
+// ToastLevel is the kind of notice a toast gives, which colors the bar
+// along its start
+type ToastLevel int
+
+const (
+	// ToastInfo is a neutral notice, drawn in the theme's primary color
+	ToastInfo ToastLevel = iota
+	// ToastSuccess reports work done, drawn in the theme's success color
+	ToastSuccess
+	// ToastWarning reports trouble, drawn in the theme's warning color
+	ToastWarning
+	// ToastError reports a failure, drawn in the theme's error color
+	ToastError
+)
+
+// ToastCorner is the corner of the window toasts stack in
+type ToastCorner int
+
+const (
+	// ToastBottomRight stacks toasts upwards from the bottom right corner
+	ToastBottomRight ToastCorner = iota
+	// ToastBottomLeft stacks toasts upwards from the bottom left corner
+	ToastBottomLeft
+	// ToastTopRight stacks toasts downwards from the top right corner
+	ToastTopRight
+	// ToastTopLeft stacks toasts downwards from the top left corner
+	ToastTopLeft
+)
+
+// Toaster shows toasts, short notices that slide in at a corner of the
+// window above everything else and go away by themselves. Toasts stack
+// with the newest nearest the corner, and those beyond the most shown at
+// once wait their turn. A toaster shows toasts once set on a root.
+//
+//	toasts := widget.NewToaster(font)
+//	root.SetToaster(toasts)
+//	toasts.Show("Saved", widget.ToastSuccess, 3*time.Second).
+//		Action("Undo", undo)
+type Toaster struct {
+	font       *text.Font
+	size       float32
+	corner     ToastCorner
+	maxVisible int
+	root       *RootWidget
+	// shown are the toasts on the window, newest first, and queued those
+	// waiting for room, oldest first
+	shown, queued []*Toast
+}
+
+// NewToaster creates a new toaster drawing toasts in the given font at 14
+// pixels, stacked in the bottom right corner, up to 3 at once
+func NewToaster(font *text.Font) *Toaster {
+	return &Toaster{font: font, size: 14, corner: ToastBottomRight, maxVisible: 3}
+}
+
+// Size sets the pixel size of the text of toasts and returns the toaster
+// for chaining
+func (t *Toaster) Size(size float32) *Toaster {
+	t.size = size
+	t.changed()
+	return t
+}
+
+// Corner sets the corner toasts stack in and returns the toaster for chaining
+func (t *Toaster) Corner(corner ToastCorner) *Toaster {
+	t.corner = corner
+	t.changed()
+	return t
+}
+
+// MaxVisible sets how many toasts show at once and returns the toaster for
+// chaining
+func (t *Toaster) MaxVisible(n int) *Toaster {
+	t.maxVisible = max(n, 1)
+	t.fill()
+	return t
+}
+
+// Show queues a toast giving a message at a level, shown at once when
+// there is room, which dismisses itself the duration after it shows. A
+// duration of zero keeps it up until the user closes it or Dismiss is
+// called. It returns the toast, to add an action to.
+func (t *Toaster) Show(message string, level ToastLevel, duration time.Duration) *Toast {
+	toast := &Toast{
+		toaster:  t,
+		message:  message,
+		level:    level,
+		duration: duration,
+		slide:    anim.NewFloat(0),
+		offset:   anim.NewFloat(0),
+	}
+	toast.popup = NewPopup(toast).Persistent(true)
+	toast.popup.notice = true
+	t.queued = append(t.queued, toast)
+	t.fill()
+	return toast
+}
+
+// DismissAll dismisses the toasts shown and drops those waiting
+func (t *Toaster) DismissAll() {
+	t.queued = nil
+	for _, toast := range t.shown {
+		toast.Dismiss()
+	}
+}
+
+// Toasts returns the toasts on the window, newest first, including any
+// sliding out
+func (t *Toaster) Toasts() []*Toast {
+	return slices.Clone(t.shown)
+}
+
+// fill shows waiting toasts while there is room for them
+func (t *Toaster) fill() {
+	if t.root == nil {
+		return
+	}
+	for len(t.queued) > 0 && t.showing() < t.maxVisible {
+		toast := t.queued[0]
+		t.queued = t.queued[1:]
+		t.shown = slices.Insert(t.shown, 0, toast)
+		t.root.ShowPopup(toast.popup)
+		toast.top = t.offsetOf(toast)
+		toast.offset.Set(toast.top)
+		toast.place()
+	}
+	t.changed()
+}
+
+// showing returns how many toasts are shown and not sliding out
+func (t *Toaster) showing() (n int) {
+	for _, toast := range t.shown {
+		if !toast.dismissing {
+			n++
+		}
+	}
+	return
+}
+
+// remove takes a toast that slid out off the window, making room for one
+// waiting
+func (t *Toaster) remove(toast *Toast) {
+	toast.popup.Close()
+	t.shown = slices.DeleteFunc(t.shown, func(o *Toast) bool { return o == toast })
+	t.fill()
+}
+
+// changed repaints the toasts shown, which move to their places as they paint
+func (t *Toaster) changed() {
+	for _, toast := range t.shown {
+		toast.MarkNeedsPaint()
+	}
+}
+
+// offsetOf returns the distance along the stack of a toast from the edge
+// of the window its corner is on, past the margin and the newer toasts
+func (t *Toaster) offsetOf(toast *Toast) (offset float32) {
+	for _, o := range t.shown {
+		if o == toast {
+			break
+		}
+		offset += o.height() + toastSpacing
+	}
+	return
+}
+
+// SetToaster sets the toaster showing toasts above the root's tree and
+// returns the root for chaining. A nil toaster takes the toasts down.
+func (r *RootWidget) SetToaster(t *Toaster) *RootWidget {
+	if old := r.toaster; old != nil && old != t {
+		for _, toast := range old.shown {
+			r.cancel(toast.timer)
+			toast.popup.Close()
+		}
+		old.shown, old.root = nil, nil
+	}
+	r.toaster = t
+	if t != nil {
+		t.root = r
+		t.fill()
+	}
+	return r
+}
+
+// Toaster returns the toaster showing toasts above the root's tree, nil if
+// none
+func (r *RootWidget) Toaster() *Toaster {
+	return r.toaster
+}
+
+// toastPart is a button of a toast
+type toastPart int
+
+const (
+	toastNone toastPart = iota
+	toastAction
+	toastClose
+)
+
+// Toast is a notice shown by a Toaster, with a message, an optional action
+// button and a button closing it
+type Toast struct {
+	Base
+	toaster  *Toaster
+	message  string
+	level    ToastLevel
+	duration time.Duration
+	action   string
+	onAction func()
+	popup    *Popup
+	// slide runs from 0 off the edge of the window to 1 in place, and
+	// offset is the distance of the toast along the stack; shown and top
+	// are their values as last painted
+	slide, offset *anim.Tween[float32]
+	shown, top    float32
+	// started is set once the toast painted and its time began to run out
+	// on timer, dismissing once it began to slide out and leaving once it
+	// slid out and is removed on the next frame
+	started, dismissing, leaving bool
+	timer                        *timer
+	// hot is the button under the cursor and pressed the one held
+	hot, pressed toastPart
+}
+
+// Action adds a button with the label to the toast that invokes the
+// callback and dismisses the toast, and returns the toast for chaining
+func (t *Toast) Action(label string, fn func()) *Toast {
+	t.action, t.onAction = label, fn
+	t.place()
+	t.MarkNeedsPaint()
+	return t
+}
+
+// Dismiss slides the toast out, or drops it if it is still waiting
+func (t *Toast) Dismiss() {
+	tr := t.toaster
+	if i := slices.Index(tr.queued, t); i >= 0 {
+		tr.queued = slices.Delete(tr.queued, i, i+1)
+		return
+	}
+	if t.dismissing || !t.popup.IsOpen() {
+		return
+	}
+	t.dismissing = true
+	if r := rootOf(t); r != nil {
+		r.cancel(t.timer)
+	}
+	t.timer = nil
+	t.slide.To(0, toastSlide, anim.EaseInCubic)
+	tr.fill()
+}
+
+// IsShowing reports whether the toast is on the window and not sliding out
+func (t *Toast) IsShowing() bool {
+	return t.popup.IsOpen() && !t.dismissing
+}
+
+// Message returns the message of the toast
+func (t *Toast) Message() string {
+	return t.message
+}
+
+// Level returns the level of the toast
+func (t *Toast) Level() ToastLevel {
+	return t.level
+}
+
+// place sizes the toast's popup and puts it at its place in the stack
+func (t *Toast) place() {
+	tr := t.toaster
+	if tr.root == nil || !t.popup.IsOpen() {
+		return
+	}
+	canvas := tr.root.CachedSize()
+	width, height := t.width(canvas), t.height()
+	x := float32(toastMargin)
+	if tr.corner == ToastBottomRight || tr.corner == ToastTopRight {
+		x = canvas.Width - toastMargin - width
+	}
+	y := toastMargin + t.top
+	if tr.corner == ToastBottomRight || tr.corner == ToastBottomLeft {
+		y = canvas.Height - y - height
+	}
+	t.popup.At(float32(math.Round(float64(x))), float32(math.Round(float64(y)))).Size(width, height)
+}
+
+// width returns the width that fits the message and buttons, within the
+// bounds of a toast and the window
+func (t *Toast) width(canvas Size) float32 {
+	face := t.toaster.font.Face(t.toaster.size)
+	width := toastAccent + 2*toastPadding + face.Measure(t.message) + toastGap + t.iconSize()
+	if t.action != "" {
+		width += face.Measure(t.action) + 2*toastGap
+	}
+	width = max(min(width, toastMaxWidth), toastMinWidth)
+	return float32(math.Ceil(float64(max(min(width, canvas.Width-2*toastMargin), 0))))
+}
+
+// height returns the height of a toast, a line of text inside the padding
+func (t *Toast) height() float32 {
+	return float32(math.Ceil(float64(t.toaster.font.Face(t.toaster.size).LineHeight()))) + 2*toastPadding
+}
+
+// GetConstraints returns the toast's constraints; the toaster places the toast itself
+func (t *Toast) GetConstraints() Constraints {
+	return NewFlexConstraints(0, 0, 1e9, 1e9)
+}
+
+// Measure returns the minimum size of the toast
+func (t *Toast) Measure(constraints Constraints) Size {
+	return minSize(t.GetConstraints())
+}
+
+// Layout implements the Widget interface for Toast; toasts take all the space offered
+func (t *Toast) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	t.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for Toast, sliding it in from the
+// edge of the window beside it
+func (t *Toast) Paint(ctx *Context, box *Box) (err error) {
+	now := frameTime(ctx)
+	r := rootOf(t)
+	if !t.started {
+		// The toast's time runs from the frame it first shows in
+		t.started = true
+		t.slide.To(1, toastSlide, anim.EaseOutCubic)
+		sounds := themeOf(ctx).Sounds
+		if t.level == ToastError {
+			sound.Play(sounds.Error)
+		} else {
+			sound.Play(sounds.Notice)
+		}
+		if t.duration > 0 && r != nil {
+			t.timer = r.schedule(now.Add(t.duration), t.Dismiss)
+		}
+	}
+	// Close up the stack when toasts above leave
+	if target := t.toaster.offsetOf(t); target != t.offset.Target() {
+		t.offset.To(target, toastMove, anim.EaseOutCubic)
+	}
+	shown, top := t.slide.Value(ctx.Clock), t.offset.Value(ctx.Clock)
+	if shown != t.shown || top != t.top || t.slide.Running() || t.offset.Running() {
+		// Paint the next frame of the slide, the popup following the
+		// toast's place on the next frame as it is laid out
+		t.invalidate(t.paintBounds(box))
+		t.shown, t.top = shown, top
+		t.invalidate(t.paintBounds(box))
+	}
+	t.place()
+	if t.dismissing && !t.leaving && !t.slide.Running() && r != nil {
+		// Popups cannot close while they paint, so leave on the next frame
+		t.leaving = true
+		r.schedule(now, func() { t.toaster.remove(t) })
+	}
+
+	box = t.slid(box)
+	th := themeOf(ctx)
+	list := ctx.DrawList
+	x, y, w, h := box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height
+	paintElevated(ctx, box, toastElevation, th.Radius.Medium)
+	fillBordered(ctx, box, th.Radius.Medium, th.Border, th.Surface)
+	list.PushClip(x, y, w, h)
+	defer list.PopClip()
+	accent := x
+	if ctx.RTL {
+		accent = x + w - toastAccent
+	}
+	list.Rect(accent, y, toastAccent, h, t.color(th))
+
+	face := t.toaster.font.Face(t.toaster.size)
+	baseline := y + (h-face.LineHeight())/2 + face.Ascent()
+	closeRect := t.partRect(ctx.RTL, box, toastClose)
+	t.paintButton(ctx, closeRect, toastClose)
+	icons.Draw(list, "close", closeRect.X, closeRect.Y, closeRect.Width, ctx.Scale, th.TextMuted)
+	// The message takes the room left before the buttons
+	start, end := x+toastAccent+toastPadding, t.partRect(false, box, toastClose).X-toastGap
+	if t.action != "" {
+		a := t.partRect(ctx.RTL, box, toastAction)
+		t.paintButton(ctx, a, toastAction)
+		face.Draw(list, a.X+toastGap, baseline, t.action, th.Primary)
+		end = t.partRect(false, box, toastAction).X
+	}
+	width := face.Measure(t.message)
+	mx := start
+	if ctx.RTL {
+		start, end = 2*x+w-end, 2*x+w-start
+		mx = max(end-width, start)
+	}
+	list.PushClip(start, y, max(end-start, 0), h)
+	face.Draw(list, mx, baseline, t.message, th.Text)
+	list.PopClip()
+	return
+}
+
+// paintButton draws the background of a button while hovered or held
+func (t *Toast) paintButton(ctx *Context, rect Rect, part toastPart) {
+	th := themeOf(ctx)
+	switch {
+	case t.pressed == part:
+		ctx.DrawList.RoundRect(rect.X, rect.Y, rect.Width, rect.Height, th.Radius.Small, th.SurfacePressed)
+	case t.hot == part:
+		ctx.DrawList.RoundRect(rect.X, rect.Y, rect.Width, rect.Height, th.Radius.Small, th.SurfaceHover)
+	}
+}
+
+// color returns the color of the toast's level
+func (t *Toast) color(th *theme.Theme) [4]float32 {
+	switch t.level {
+	case ToastSuccess:
+		return th.Success
+	case ToastWarning:
+		return th.Warning
+	case ToastError:
+		return th.Error
+	}
+	return th.Primary
+}
+
+// slid returns the box the toast is drawn in as it slides, moved towards
+// the edge of the window beside it
+func (t *Toast) slid(box *Box) *Box {
+	if t.shown >= 1 {
+		return box
+	}
+	distance := (1 - t.shown) * (box.Size.Width + toastMargin)
+	if c := t.toaster.corner; c == ToastBottomLeft || c == ToastTopLeft {
+		distance = -distance
+	}
+	return NewBox(box.Position.X+distance, box.Position.Y, box.Size.Width, box.Size.Height, box.Constraints)
+}
+
+// paintBounds implements overflowing, returning the box where the toast is
+// drawn as it slides, along with its shadow
+func (t *Toast) paintBounds(box *Box) Rect {
+	dy, blur := elevation(toastElevation)
+	return shadowBounds(box, 0, dy, blur).Union(shadowBounds(t.slid(box), 0, dy, blur))
+}
+
+// partRect returns a button of the toast in window coordinates, the close
+// button at the end and the action before it
+func (t *Toast) partRect(rtl bool, box *Box, part toastPart) Rect {
+	icon := t.iconSize()
+	x, y, w, h := box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height
+	rect := Rect{X: x + w - toastPadding - icon, Y: y + (h-icon)/2, Width: icon, Height: icon}
+	if part == toastAction {
+		width := t.toaster.font.Face(t.toaster.size).Measure(t.action) + 2*toastGap
+		rect = Rect{X: rect.X - toastGap/2 - width, Y: y + toastPadding/2, Width: width, Height: h - toastPadding}
+	}
+	if rtl {
+		rect.X = 2*x + w - rect.X - rect.Width
+	}
+	return rect
+}
+
+// partAt returns the button under a point, toastNone if none
+func (t *Toast) partAt(rtl bool, box *Box, p Point) toastPart {
+	switch {
+	case t.partRect(rtl, box, toastClose).Contains(p):
+		return toastClose
+	case t.action != "" && t.partRect(rtl, box, toastAction).Contains(p):
+		return toastAction
+	}
+	return toastNone
+}
+
+// iconSize returns the side of the close button
+func (t *Toast) iconSize() float32 {
+	return float32(math.Round(float64(t.toaster.font.Face(t.toaster.size).LineHeight())))
+}
+
+// CursorAt implements CursorProvider, showing the hand over the buttons
+func (t *Toast) CursorAt(p Point) Cursor {
+	if t.hot != toastNone {
+		return interfaces.CursorHand
+	}
+	return interfaces.CursorDefault
+}
+
+// Accessibility implements Accessible, naming the toast by its message
+func (t *Toast) Accessibility() AccessNode {
+	node := AccessNode{Role: interfaces.RoleLabel, Name: t.message}
+	if t.action != "" {
+		node.Value = t.action
+		node.Actions = []AccessAction{interfaces.AccessClick}
+	}
+	return node
+}
+
+// AccessAction implements AccessActor, taking the toast's action
+func (t *Toast) AccessAction(ctx *Context, action AccessAction, value string) bool {
+	if action != interfaces.AccessClick || t.action == "" {
+		return false
+	}
+	t.press(toastAction)
+	return true
+}
+
+// HandleEvent implements the Widget interface for Toast
+func (t *Toast) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		part := toastNone
+		if box.Contains(e.Position) {
+			part = t.partAt(ctx.RTL, box, e.Position)
+		}
+		t.setHot(part)
+		return box.Contains(e.Position)
+	case interfaces.CursorLeaveEvent:
+		t.setHot(toastNone)
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft {
+			return false
+		}
+		switch e.Action {
+		case interfaces.ActionPress:
+			// Presses are only delivered when the cursor is inside the box
+			t.pressed = t.partAt(ctx.RTL, box, e.Position)
+			t.MarkNeedsPaint()
+			return true
+		case interfaces.ActionRelease:
+			// Releases are broadcast, so only act when released where pressed
+			if t.pressed == toastNone {
+				return false
+			}
+			part := t.pressed
+			t.pressed = toastNone
+			t.MarkNeedsPaint()
+			if box.Contains(e.Position) && t.partAt(ctx.RTL, box, e.Position) == part {
+				t.press(part)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// press takes the action of a button of the toast for the user
+func (t *Toast) press(part toastPart) {
+	if part == toastAction && t.onAction != nil {
+		t.onAction()
+	}
+	t.Dismiss()
+}
+
+// setHot updates the button under the cursor, repainting when it changes
+func (t *Toast) setHot(part toastPart) {
+	if part != t.hot {
+		t.hot = part
+		t.MarkNeedsPaint()
+	}
+}
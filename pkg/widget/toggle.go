@@ -0,0 +1,121 @@
+package widget
+
+import (
+	"math"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/text"
+)
+
+// toggleInset is the space left around a toggle indicator for its focus ring
+const toggleInset = 2
+
+// toggle holds the pointer state and label shared by checkboxes and radio
+// buttons, which draw an indicator followed by a line of text and activate
+// on a click or on space while focused
+type toggle struct {
+	font     *text.Font
+	size     float32
+	label    string
+	disabled bool
+	hovered  bool
+	pressed  bool
+}
+
+// metrics returns the indicator side and the gap between it and the label
+func (t *toggle) metrics() (indicator, gap float32) {
+	indicator = float32(math.Round(float64(t.size))) + 2
+	gap = float32(math.Round(float64(t.size / 2)))
+	return
+}
+
+// constraints returns a minimum size that fits the indicator and label on one line
+func (t *toggle) constraints() Constraints {
+	face := t.font.Face(t.size)
+	indicator, gap := t.metrics()
+	width := indicator + 2*toggleInset
+	if t.label != "" {
+		width += gap + face.Measure(t.label)
+	}
+	height := max(face.LineHeight(), indicator+2*toggleInset)
+	return NewFlexConstraints(width, height, 1e9, 1e9)
+}
+
+// paint draws the label and the focus ring, returning the box the caller
+// draws its indicator in
+func (t *toggle) paint(ctx *Context, box *Box, focused bool) (indicator *Box) {
+	th := themeOf(ctx)
+	face := t.font.Face(t.size)
+	side, gap := t.metrics()
+	x := box.Position.X + toggleInset
+	y := float32(math.Round(float64(box.Position.Y + (box.Size.Height-side)/2)))
+	indicator = NewBox(x, y, side, side, NewRigidConstraints(side, side))
+
+	list := ctx.DrawList
+	if focused {
+		list.RoundRect(x-toggleInset, y-toggleInset, side+2*toggleInset, side+2*toggleInset, side/2, th.Selection)
+	}
+	if t.label != "" {
+		color := th.Text
+		if t.disabled {
+			color = th.TextMuted
+		}
+		list.PushClip(box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height)
+		baseline := box.Position.Y + (box.Size.Height-face.LineHeight())/2 + face.Ascent()
+		face.Draw(list, x+side+gap, baseline, t.label, color)
+		list.PopClip()
+	}
+	return
+}
+
+// handle processes input for the control w, reporting whether the event was
+// handled and whether the control was activated by it
+func (t *toggle) handle(w Widget, box *Box, ev Event) (handled, activated bool) {
+	if t.disabled {
+		return false, false
+	}
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		t.setHovered(w, box.Contains(e.Position))
+	case interfaces.CursorLeaveEvent:
+		t.setHovered(w, false)
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft {
+			return false, false
+		}
+		switch e.Action {
+		case interfaces.ActionPress:
+			// Presses are only delivered when the cursor is inside the box
+			requestFocus(w)
+			t.pressed = true
+			w.MarkNeedsPaint()
+			return true, false
+		case interfaces.ActionRelease:
+			// Releases are broadcast, so only activate when released over the control
+			if !t.pressed {
+				return false, false
+			}
+			t.pressed = false
+			w.MarkNeedsPaint()
+			return true, box.Contains(e.Position)
+		}
+	case interfaces.KeyEvent:
+		if !hasFocus(w) || e.Key != interfaces.KeySpace {
+			return false, false
+		}
+		// Repeats are consumed so holding space does not flicker the state
+		return true, e.Action == interfaces.ActionPress
+	case interfaces.CharEvent:
+		// Swallow the space typed along with the key press
+		return hasFocus(w) && e.Char == ' ', false
+	}
+	return false, false
+}
+
+// setHovered updates the hover state, repainting w when it changes
+func (t *toggle) setHovered(w Widget, hovered bool) {
+	if hovered != t.hovered {
+		t.hovered = hovered
+		w.MarkNeedsPaint()
+	}
+}
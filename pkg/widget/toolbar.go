@@ -0,0 +1,569 @@
+package widget
+
+import (
+	"math"
+
+	"github.com/mleku/goo/pkg/icons"
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/render"
+	"github.com/mleku/goo/pkg/text"
+)
+
+const (
+	// toolbarPadding is the space around the icon or text of a tool
+	toolbarPadding = 6
+	// toolbarInset is the space between the edge of the bar and the
+	// background of a hovered or checked tool
+	toolbarInset = 2
+	// toolbarSeparator is the width of a separator
+	toolbarSeparator = 9
+	// toolMore is the slot of the button opening the overflow menu
+	toolMore = -2
+)
+
+// ToolItem is an entry of a toolbar. Items show Icon, or the icon of the
+// built-in set named IconName in the text color, or Label when they have
+// neither. Label names the item in its tooltip and in the overflow menu.
+// Items run OnSelect when clicked, or when Toggle is set flip Checked and
+// run OnChange with it. Disabled items are drawn muted and cannot be used,
+// and Separator items are drawn as a dividing line.
+type ToolItem struct {
+	Label     string
+	Icon      *render.Texture
+	IconName  string
+	Toggle    bool
+	Checked   bool
+	Disabled  bool
+	Separator bool
+	OnSelect  func()
+	OnChange  func(checked bool)
+}
+
+// usable reports whether the item can be focused and used
+func (it *ToolItem) usable() bool {
+	return !it.Separator && !it.Disabled
+}
+
+// ToolbarWidget is a row of tools along the top of a window or panel. Tools
+// that do not fit collapse, from the end, into a menu opened by a button at
+// the end of the bar. Hovering a tool shows its label as a tooltip. While
+// the toolbar has focus the left and right keys move between the tools and
+// enter or space uses the one marked.
+type ToolbarWidget struct {
+	Base
+	font     *text.Font
+	size     float32
+	iconSize float32
+	items    []ToolItem
+	// visible is the number of items shown on the bar, the rest are in the
+	// overflow menu
+	visible  int
+	overflow *Menu
+	// hot is the slot under the cursor, pressed the slot the mouse was
+	// pressed on and cursor the slot marked for the keyboard, -1 if none
+	hot, pressed, cursor int
+	// tip shows the label of the slot tipSlot once the cursor has rested on
+	// it, when timer fires
+	bubble  *tooltipBubble
+	tip     *Popup
+	timer   *timer
+	tipSlot int
+}
+
+// Toolbar creates a new empty toolbar with labels drawn in the given font at
+// 14 pixels and icons 18 pixels square
+func Toolbar(font *text.Font) *ToolbarWidget {
+	t := &ToolbarWidget{
+		font:     font,
+		size:     14,
+		iconSize: 18,
+		overflow: NewMenu(font),
+		hot:      -1,
+		pressed:  -1,
+		cursor:   -1,
+		tipSlot:  -1,
+		bubble:   &tooltipBubble{font: font, size: 13},
+	}
+	t.tip = NewPopup(t.bubble).PassThrough(true)
+	return t
+}
+
+// Size sets the pixel size of labels and returns the toolbar for chaining
+func (t *ToolbarWidget) Size(size float32) *ToolbarWidget {
+	t.size = size
+	t.overflow.Size(size)
+	t.MarkNeedsLayout()
+	return t
+}
+
+// IconSize sets the side of the icons and returns the toolbar for chaining
+func (t *ToolbarWidget) IconSize(size float32) *ToolbarWidget {
+	t.iconSize = size
+	t.MarkNeedsLayout()
+	return t
+}
+
+// Button appends a tool showing the built-in icon named, labelled for its
+// tooltip, that runs onSelect when clicked, and returns the toolbar for
+// chaining
+func (t *ToolbarWidget) Button(iconName, label string, onSelect func()) *ToolbarWidget {
+	return t.Add(ToolItem{Label: label, IconName: iconName, OnSelect: onSelect})
+}
+
+// Toggle appends a tool showing the built-in icon named, labelled for its
+// tooltip, that is checked and unchecked by clicks, running onChange with
+// the new state, and returns the toolbar for chaining
+func (t *ToolbarWidget) Toggle(iconName, label string, checked bool, onChange func(checked bool)) *ToolbarWidget {
+	return t.Add(ToolItem{Label: label, IconName: iconName, Toggle: true, Checked: checked, OnChange: onChange})
+}
+
+// Separator appends a dividing line and returns the toolbar for chaining
+func (t *ToolbarWidget) Separator() *ToolbarWidget {
+	return t.Add(ToolItem{Separator: true})
+}
+
+// Add appends items and returns the toolbar for chaining
+func (t *ToolbarWidget) Add(items ...ToolItem) *ToolbarWidget {
+	t.items = append(t.items, items...)
+	t.MarkNeedsLayout()
+	return t
+}
+
+// Items returns the toolbar's items
+func (t *ToolbarWidget) Items() []ToolItem {
+	return t.items
+}
+
+// SetDisabled disables or enables the item at index
+func (t *ToolbarWidget) SetDisabled(index int, disabled bool) {
+	if index < 0 || index >= len(t.items) {
+		return
+	}
+	t.items[index].Disabled = disabled
+	if disabled && t.pressed == index {
+		t.pressed = -1
+	}
+	t.MarkNeedsPaint()
+}
+
+// SetChecked checks or unchecks the toggle at index
+func (t *ToolbarWidget) SetChecked(index int, checked bool) {
+	if index < 0 || index >= len(t.items) {
+		return
+	}
+	t.items[index].Checked = checked
+	t.MarkNeedsPaint()
+}
+
+// IsChecked reports whether the toggle at index is checked
+func (t *ToolbarWidget) IsChecked(index int) bool {
+	return index >= 0 && index < len(t.items) && t.items[index].Checked
+}
+
+// Overflowing returns the number of items collapsed into the overflow menu
+func (t *ToolbarWidget) Overflowing() int {
+	return len(t.items) - t.visible
+}
+
+// GetConstraints returns the height of a tool and the width of the overflow
+// button, as every tool can collapse into its menu
+func (t *ToolbarWidget) GetConstraints() Constraints {
+	height := t.height()
+	return NewFlexConstraints(t.buttonWidth(), height, 1e9, height)
+}
+
+// Measure returns the size that shows every tool
+func (t *ToolbarWidget) Measure(constraints Constraints) Size {
+	var width float32
+	for i := range t.items {
+		width += t.itemWidth(i)
+	}
+	return Size{Width: max(min(width, constraints.MaxWidth), t.buttonWidth()), Height: t.height()}
+}
+
+// Layout implements the Widget interface for ToolbarWidget; toolbars take
+// the width offered, collapsing the tools that do not fit
+func (t *ToolbarWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !t.NeedsLayout(ctx, constraints) {
+		return t.CachedSize(), nil
+	}
+	t.overflow.Close()
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	t.visible = t.fit(size.Width)
+	if t.cursor >= t.visible {
+		t.cursor = -1
+	}
+	t.SetLayout(ctx, constraints, size)
+	return
+}
+
+// fit returns the number of items shown on a bar of a width, leaving room
+// for the overflow button when not all of them fit
+func (t *ToolbarWidget) fit(width float32) (n int) {
+	var total float32
+	for i := range t.items {
+		total += t.itemWidth(i)
+	}
+	if total <= width {
+		return len(t.items)
+	}
+	width -= t.buttonWidth()
+	var x float32
+	for i := range t.items {
+		if x += t.itemWidth(i); x > width {
+			break
+		}
+		n = i + 1
+	}
+	// A separator is not left at the end of the bar
+	for n > 0 && t.items[n-1].Separator {
+		n--
+	}
+	return
+}
+
+// Paint implements the Widget interface for ToolbarWidget
+func (t *ToolbarWidget) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	list := ctx.DrawList
+	x, y, w, h := box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height
+	list.Rect(x, y, w, h, th.Surface)
+	list.Rect(x, y+h-1, w, 1, th.Border)
+	list.PushClip(x, y, w, h)
+	defer list.PopClip()
+
+	face := t.font.Face(t.size)
+	focused := hasFocus(t)
+	for _, slot := range t.slots() {
+		sx, sw := t.slotSpan(box, slot)
+		if slot >= 0 && t.items[slot].Separator {
+			list.Rect(float32(math.Round(float64(sx+sw/2))), y+toolbarPadding, 1, h-2*toolbarPadding-1, th.Border)
+			continue
+		}
+		var it ToolItem
+		if slot >= 0 {
+			it = t.items[slot]
+		}
+		bx, by, bw, bh := sx+toolbarInset, y+toolbarInset, sw-2*toolbarInset, h-2*toolbarInset-1
+		switch {
+		case it.Disabled:
+		case slot == t.pressed:
+			list.RoundRect(bx, by, bw, bh, th.Radius.Small, th.SurfacePressed)
+		case it.Checked:
+			list.RoundRect(bx, by, bw, bh, th.Radius.Small, th.Selection)
+		case slot == t.hot:
+			list.RoundRect(bx, by, bw, bh, th.Radius.Small, th.SurfaceHover)
+		}
+		if focused && slot == t.cursor {
+			list.RoundRectStroke(bx, by, bw, bh, th.Radius.Small, 1, th.Primary)
+		}
+		color := th.Text
+		if it.Disabled {
+			color = th.TextMuted
+		}
+		iy := y + (h-1-t.iconSize)/2
+		switch {
+		case slot == toolMore:
+			icons.Draw(list, "more-horiz", sx+toolbarPadding, iy, t.iconSize, ctx.Scale, color)
+		case it.Icon != nil:
+			tint := [4]float32{1, 1, 1, 1}
+			if it.Disabled {
+				tint[3] = 0.5
+			}
+			list.Image(it.Icon, sx+toolbarPadding, iy, t.iconSize, t.iconSize, 0, 0, 1, 1, tint)
+		case it.IconName != "":
+			icons.Draw(list, it.IconName, sx+toolbarPadding, iy, t.iconSize, ctx.Scale, color)
+		default:
+			baseline := y + (h-1-face.LineHeight())/2 + face.Ascent()
+			face.Draw(list, sx+toolbarPadding, baseline, it.Label, color)
+		}
+	}
+	return
+}
+
+// navigable lets gamepad navigation reach the toolbar
+func (t *ToolbarWidget) navigable() bool {
+	return len(t.slots()) > 0
+}
+
+// takesArrow implements arrowTaker, using left and right to move between
+// the tools
+func (t *ToolbarWidget) takesArrow(key interfaces.Key) bool {
+	return key == interfaces.KeyLeft || key == interfaces.KeyRight
+}
+
+// Accessibility implements Accessible, describing the toolbar as a group
+// named by the tool marked for the keyboard
+func (t *ToolbarWidget) Accessibility() AccessNode {
+	node := AccessNode{Role: interfaces.RoleGroup, Name: "toolbar"}
+	switch {
+	case t.cursor == toolMore:
+		node.Value = "more"
+	case t.cursor >= 0:
+		node.Value = t.items[t.cursor].Label
+	}
+	return node
+}
+
+// HandleEvent implements the Widget interface for ToolbarWidget
+func (t *ToolbarWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		slot := t.slotAt(box, e.Position)
+		t.setHot(slot)
+		if slot != t.tipSlot {
+			t.hideTip()
+			if slot != -1 {
+				t.armTip(ctx, slot, e.Position)
+			}
+		}
+		return box.Contains(e.Position)
+	case interfaces.CursorLeaveEvent, interfaces.ScrollEvent:
+		t.setHot(-1)
+		t.hideTip()
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft {
+			return false
+		}
+		switch e.Action {
+		case interfaces.ActionPress:
+			// Presses are only delivered when the cursor is inside the box
+			t.hideTip()
+			slot := t.slotAt(box, e.Position)
+			if slot == -1 {
+				return true
+			}
+			requestFocus(t)
+			t.cursor, t.pressed = slot, slot
+			t.MarkNeedsPaint()
+			return true
+		case interfaces.ActionRelease:
+			// Releases are broadcast, so only use a tool released over
+			pressed := t.pressed
+			if pressed == -1 {
+				return false
+			}
+			t.pressed = -1
+			t.MarkNeedsPaint()
+			if t.slotAt(box, e.Position) == pressed {
+				t.use(pressed)
+			}
+			return true
+		}
+	case interfaces.KeyEvent:
+		if !hasFocus(t) || e.Action == interfaces.ActionRelease {
+			return false
+		}
+		switch e.Key {
+		case interfaces.KeyLeft:
+			t.step(-1)
+		case interfaces.KeyRight:
+			t.step(1)
+		case interfaces.KeyHome:
+			t.cursor = -1
+			t.step(1)
+		case interfaces.KeyEnd:
+			t.cursor = -1
+			t.step(-1)
+		case interfaces.KeyEnter, interfaces.KeySpace:
+			if e.Action == interfaces.ActionPress && t.cursor != -1 {
+				t.use(t.cursor)
+			}
+		default:
+			return false
+		}
+		return true
+	case interfaces.CharEvent:
+		// Swallow the space typed along with the key press
+		return hasFocus(t) && e.Char == ' '
+	}
+	return false
+}
+
+// use runs the tool in a slot, flipping a toggle, or opens the overflow menu
+func (t *ToolbarWidget) use(slot int) {
+	if slot == toolMore {
+		t.openOverflow()
+		return
+	}
+	it := &t.items[slot]
+	if !it.usable() {
+		return
+	}
+	if it.Toggle {
+		it.Checked = !it.Checked
+		t.MarkNeedsPaint()
+		if it.OnChange != nil {
+			it.OnChange(it.Checked)
+		}
+		return
+	}
+	if it.OnSelect != nil {
+		it.OnSelect()
+	}
+}
+
+// openOverflow opens the menu of the items that do not fit below the
+// overflow button. Checked toggles show a tick in place of their icon.
+func (t *ToolbarWidget) openOverflow() {
+	r := rootOf(t)
+	if r == nil || t.visible == len(t.items) {
+		return
+	}
+	m := t.overflow
+	m.items = m.items[:0]
+	for i := t.visible; i < len(t.items); i++ {
+		it := t.items[i]
+		if it.Separator && len(m.items) == 0 {
+			continue
+		}
+		item := MenuItem{Label: it.Label, Icon: it.Icon, IconName: it.IconName, Disabled: it.Disabled, Separator: it.Separator}
+		if it.Toggle && it.Checked {
+			item.Icon, item.IconName = nil, "check"
+		}
+		item.OnSelect = func() { t.use(i) }
+		m.Add(item)
+	}
+	box := &t.paintBox
+	x, _ := t.slotSpan(box, toolMore)
+	m.ShowAt(r, x, box.Position.Y+box.Size.Height)
+	t.MarkNeedsPaint()
+}
+
+// step moves the keyboard mark to the next usable slot in a direction,
+// wrapping around the ends
+func (t *ToolbarWidget) step(dir int) {
+	var usable []int
+	for _, slot := range t.slots() {
+		if slot == toolMore || t.items[slot].usable() {
+			usable = append(usable, slot)
+		}
+	}
+	n := len(usable)
+	if n == 0 {
+		return
+	}
+	at := -1
+	for i, slot := range usable {
+		if slot == t.cursor {
+			at = i
+		}
+	}
+	switch {
+	case at < 0 && dir > 0:
+		at = 0
+	case at < 0:
+		at = n - 1
+	default:
+		at = ((at+dir)%n + n) % n
+	}
+	t.cursor = usable[at]
+	t.MarkNeedsPaint()
+}
+
+// slots returns the slots shown on the bar in order, the overflow button
+// last when items do not fit
+func (t *ToolbarWidget) slots() (slots []int) {
+	for i := range t.visible {
+		slots = append(slots, i)
+	}
+	if t.visible < len(t.items) {
+		slots = append(slots, toolMore)
+	}
+	return
+}
+
+// slotSpan returns the left edge in window coordinates and the width of a
+// slot. The overflow button sits at the end of the bar.
+func (t *ToolbarWidget) slotSpan(box *Box, slot int) (x, width float32) {
+	if slot == toolMore {
+		width = t.buttonWidth()
+		return box.Position.X + box.Size.Width - width, width
+	}
+	x = box.Position.X
+	for i := range slot {
+		x += t.itemWidth(i)
+	}
+	return x, t.itemWidth(slot)
+}
+
+// slotAt returns the slot under a point, -1 if none
+func (t *ToolbarWidget) slotAt(box *Box, p Point) int {
+	if !box.Contains(p) {
+		return -1
+	}
+	for _, slot := range t.slots() {
+		x, w := t.slotSpan(box, slot)
+		if p.X >= x && p.X < x+w {
+			if slot >= 0 && t.items[slot].Separator {
+				return -1
+			}
+			return slot
+		}
+	}
+	return -1
+}
+
+// itemWidth returns the width of the item at index on the bar
+func (t *ToolbarWidget) itemWidth(index int) float32 {
+	it := &t.items[index]
+	switch {
+	case it.Separator:
+		return toolbarSeparator
+	case it.Icon == nil && it.IconName == "":
+		return float32(math.Ceil(float64(t.font.Face(t.size).Measure(it.Label)))) + 2*toolbarPadding
+	}
+	return t.buttonWidth()
+}
+
+// buttonWidth returns the width of a tool showing an icon
+func (t *ToolbarWidget) buttonWidth() float32 {
+	return t.iconSize + 2*toolbarPadding
+}
+
+// height returns the height of the bar, fitting an icon or a line of text
+// and the border along its bottom
+func (t *ToolbarWidget) height() float32 {
+	line := float32(math.Ceil(float64(t.font.Face(t.size).LineHeight())))
+	return max(t.iconSize, line) + 2*toolbarPadding + 1
+}
+
+// setHot updates the slot under the cursor, repainting when it changes
+func (t *ToolbarWidget) setHot(slot int) {
+	if slot != t.hot {
+		t.hot = slot
+		t.MarkNeedsPaint()
+	}
+}
+
+// armTip starts waiting to show the label of a slot below the cursor
+func (t *ToolbarWidget) armTip(ctx *Context, slot int, cursor Point) {
+	r := rootOf(t)
+	if r == nil {
+		return
+	}
+	label := "More"
+	if slot >= 0 {
+		label = t.items[slot].Label
+	}
+	if label == "" {
+		return
+	}
+	t.tipSlot = slot
+	t.timer = r.schedule(frameTime(ctx).Add(tooltipDelay), func() {
+		t.timer = nil
+		t.bubble.text = label
+		t.bubble.MarkNeedsPaint()
+		r.ShowPopup(t.tip.At(cursor.X, cursor.Y+tooltipCursorGap))
+	})
+}
+
+// hideTip closes the tooltip and stops waiting to show it
+func (t *ToolbarWidget) hideTip() {
+	if r := rootOf(t); r != nil {
+		r.cancel(t.timer)
+	}
+	t.timer = nil
+	t.tipSlot = -1
+	t.tip.Close()
+}
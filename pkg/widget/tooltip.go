@@ -0,0 +1,197 @@
+package widget
+
+import (
+	"math"
+	"time"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/text"
+	"lol.mleku.dev/chk"
+)
+
+const (
+	// tooltipDelay is how long the cursor rests on a widget before its tooltip shows
+	tooltipDelay = 500 * time.Millisecond
+	// tooltipPadding is the space around the text of a tooltip
+	tooltipPadding = 4
+	// tooltipCursorGap is how far below the cursor a tooltip is shown
+	tooltipCursorGap = 20
+)
+
+// TooltipWidget shows a line of text near the cursor once it has rested
+// over the child for a while. The tooltip hides again when the cursor moves,
+// leaves the child, or a button is pressed.
+type TooltipWidget struct {
+	Base
+	child  Widget
+	bubble *tooltipBubble
+	popup  *Popup
+	delay  time.Duration
+	// timer shows the tooltip when the cursor has rested at cursor for the delay
+	timer  *timer
+	cursor Point
+}
+
+// WithTooltip wraps the child so hovering over it shows the tip drawn in the
+// given font. The wrapper takes the child's constraints.
+func WithTooltip(child Widget, font *text.Font, tip string) *TooltipWidget {
+	t := &TooltipWidget{
+		child:  child,
+		bubble: &tooltipBubble{font: font, size: 13, text: tip},
+		delay:  tooltipDelay,
+	}
+	t.popup = NewPopup(t.bubble).PassThrough(true)
+	adopt(t, child)
+	return t
+}
+
+// Delay sets how long the cursor must rest before the tooltip shows and
+// returns the tooltip for chaining
+func (t *TooltipWidget) Delay(delay time.Duration) *TooltipWidget {
+	t.delay = delay
+	return t
+}
+
+// SetText replaces the tip, updating it if it is showing
+func (t *TooltipWidget) SetText(s string) {
+	t.bubble.text = s
+	t.bubble.MarkNeedsPaint()
+}
+
+// Text returns the tip
+func (t *TooltipWidget) Text() string {
+	return t.bubble.text
+}
+
+// IsShowing reports whether the tooltip is visible
+func (t *TooltipWidget) IsShowing() bool {
+	return t.popup.IsOpen()
+}
+
+// GetConstraints returns the child's constraints
+func (t *TooltipWidget) GetConstraints() Constraints {
+	if t.child == nil {
+		return NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return t.child.GetConstraints()
+}
+
+// Measure returns the size the child measures
+func (t *TooltipWidget) Measure(constraints Constraints) Size {
+	return measure(t.child, constraints)
+}
+
+// Layout implements the Widget interface for TooltipWidget; the child is
+// laid out with the same constraints
+func (t *TooltipWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !t.NeedsLayout(ctx, constraints) {
+		return t.CachedSize(), nil
+	}
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	if t.child != nil {
+		if size, err = t.child.Layout(ctx, constraints); chk.E(err) {
+			return
+		}
+	}
+	t.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for TooltipWidget
+func (t *TooltipWidget) Paint(ctx *Context, box *Box) (err error) {
+	if t.child == nil {
+		return
+	}
+	return paintChild(ctx, t.child, box)
+}
+
+// HandleEvent implements the Widget interface for TooltipWidget. Events
+// are passed on to the child after updating the tooltip.
+func (t *TooltipWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		t.hide()
+		if box.Contains(e.Position) {
+			t.arm(ctx, e.Position)
+		}
+	case interfaces.CursorLeaveEvent, interfaces.ScrollEvent:
+		t.hide()
+	case interfaces.MouseButtonEvent:
+		if e.Action == interfaces.ActionPress {
+			t.hide()
+		}
+	}
+	if t.child == nil {
+		return false
+	}
+	return routeEvent(ctx, t.child, box, ev)
+}
+
+// arm starts waiting to show the tooltip at the cursor
+func (t *TooltipWidget) arm(ctx *Context, cursor Point) {
+	r := rootOf(t)
+	if r == nil {
+		return
+	}
+	t.cursor = cursor
+	t.timer = r.schedule(frameTime(ctx).Add(t.delay), t.show)
+}
+
+// show opens the tooltip below the cursor
+func (t *TooltipWidget) show() {
+	t.timer = nil
+	if r := rootOf(t); r != nil {
+		r.ShowPopup(t.popup.At(t.cursor.X, t.cursor.Y+tooltipCursorGap))
+	}
+}
+
+// hide closes the tooltip and stops waiting to show it
+func (t *TooltipWidget) hide() {
+	if r := rootOf(t); r != nil {
+		r.cancel(t.timer)
+	}
+	t.timer = nil
+	t.popup.Close()
+}
+
+// tooltipBubble draws the text of a tooltip in a bordered box
+type tooltipBubble struct {
+	Base
+	font *text.Font
+	size float32
+	text string
+}
+
+// GetConstraints returns the size of the text inside the padding
+func (b *tooltipBubble) GetConstraints() Constraints {
+	face := b.font.Face(b.size)
+	width := float32(math.Ceil(float64(face.Measure(b.text)))) + 2*tooltipPadding
+	height := float32(math.Ceil(float64(face.LineHeight()))) + 2*tooltipPadding
+	return NewFlexConstraints(width, height, width, height)
+}
+
+// Measure returns the minimum size of the bubble
+func (b *tooltipBubble) Measure(constraints Constraints) Size {
+	return minSize(b.GetConstraints())
+}
+
+// Layout implements the Widget interface for tooltipBubble
+func (b *tooltipBubble) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	b.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for tooltipBubble
+func (b *tooltipBubble) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	fillSkinned(ctx, box, th.Skins.Tooltip, th.Radius.Small, th.Border, th.Surface)
+	face := b.font.Face(b.size)
+	face.Draw(ctx.DrawList, box.Position.X+tooltipPadding, box.Position.Y+tooltipPadding+face.Ascent(), b.text, th.Text)
+	return
+}
+
+// HandleEvent implements the Widget interface for tooltipBubble; tooltips ignore input
+func (b *tooltipBubble) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	return false
+}
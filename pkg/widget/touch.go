@@ -0,0 +1,81 @@
+package widget
+
+import (
+	"github.com/mleku/goo/pkg/interfaces"
+)
+
+// touchEvent delivers a touch to the tree. A touch beginning where no
+// widget takes it stands in for the mouse until it ends, pressing the left
+// button where it lands and releasing it where it lifts, so widgets that
+// only know the mouse work on a touch screen. Its touch events are still
+// delivered, so a widget watching them, such as a scroll view, can claim it
+// once it sees the touch is a gesture, cancelling the press.
+//
+// Touches the tree takes do not move focus, so content scrolled or pinched
+// by touch leaves the focus owner alone, while those standing in for the
+// mouse move it as a click does.
+func (r *RootWidget) touchEvent(ctx *Context, box *Box, e interfaces.TouchEvent) (handled bool) {
+	handled = r.handleEvent(ctx, box, e)
+	claimed := r.touchClaimed(e.ID)
+	if claimed && (e.Phase == interfaces.TouchEnd || e.Phase == interfaces.TouchCancel) {
+		r.claiming = false
+	}
+	if r.touchMouse && e.ID == r.mouseTouch {
+		if claimed {
+			r.cancelTouchMouse(ctx, box)
+			return true
+		}
+		switch e.Phase {
+		case interfaces.TouchMove:
+			return r.handleEvent(ctx, box, interfaces.MouseMoveEvent{Position: e.Position}) || handled
+		case interfaces.TouchEnd:
+			r.touchMouse = false
+			return r.handleEvent(ctx, box, interfaces.MouseButtonEvent{
+				Position: e.Position,
+				Button:   interfaces.MouseButtonLeft,
+				Action:   interfaces.ActionRelease,
+			}) || handled
+		case interfaces.TouchCancel:
+			r.cancelTouchMouse(ctx, box)
+			return true
+		}
+		return
+	}
+	if handled || e.Phase != interfaces.TouchBegin || r.touchMouse {
+		return
+	}
+	r.mouseTouch, r.touchMouse = e.ID, true
+	r.handleEvent(ctx, box, interfaces.MouseMoveEvent{Position: e.Position})
+	return r.handleEvent(ctx, box, interfaces.MouseButtonEvent{
+		Position: e.Position,
+		Button:   interfaces.MouseButtonLeft,
+		Action:   interfaces.ActionPress,
+	})
+}
+
+// claimTouch takes a touch for the widget handling it until it ends, so
+// widgets containing it leave the touch alone. A touch standing in for the
+// mouse releases the button away from every widget so the press does not
+// click.
+func (r *RootWidget) claimTouch(id int) {
+	r.claimed, r.claiming = id, true
+}
+
+// touchClaimed reports whether a widget took a touch
+func (r *RootWidget) touchClaimed(id int) bool {
+	return r.claiming && r.claimed == id
+}
+
+// cancelTouchMouse ends the touch standing in for the mouse by moving it
+// off the window before releasing the button
+func (r *RootWidget) cancelTouchMouse(ctx *Context, box *Box) {
+	r.touchMouse = false
+	away := Point{X: -1, Y: -1}
+	r.handleEvent(ctx, box, interfaces.MouseMoveEvent{Position: away})
+	r.handleEvent(ctx, box, interfaces.MouseButtonEvent{
+		Position: away,
+		Button:   interfaces.MouseButtonLeft,
+		Action:   interfaces.ActionRelease,
+	})
+	r.handleEvent(ctx, box, interfaces.CursorLeaveEvent{})
+}
@@ -0,0 +1,21 @@
+package widget
+
+import (
+	"context"
+	"runtime/trace"
+)
+
+// traceContext is what the regions of execution traces the widgets record
+// belong to. The window records each frame as a task, which the regions of
+// its goroutine fall within.
+var traceContext = context.Background()
+
+// traceRegion starts a region of the execution trace for a phase of the
+// frame, such as paint, done for a target, named for its type while a trace
+// is being recorded. Regions cost next to nothing while none is.
+func traceRegion(phase string, target any) *trace.Region {
+	if target == nil || !trace.IsEnabled() {
+		return trace.StartRegion(traceContext, phase)
+	}
+	return trace.StartRegion(traceContext, phase+" "+typeName(target))
+}
@@ -0,0 +1,161 @@
+package widget
+
+import (
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/render"
+	"lol.mleku.dev/chk"
+)
+
+// TransformWidget moves, scales or rotates its child, and everything inside
+// it, by a matrix applied about an origin in its box. The child is laid out
+// in the widget's box as if untransformed, and pointer input is mapped back
+// through the matrix so it reaches the child where the child is drawn.
+type TransformWidget struct {
+	Base
+	child  Widget
+	matrix render.Matrix
+	// originX and originY locate the origin as fractions of the box
+	originX, originY float32
+	// applied is the transform the child was last painted with, relative to
+	// the widget's parent
+	applied render.Matrix
+}
+
+// Transform creates a new transform widget applying the matrix to the child
+// about the center of its box
+func Transform(child Widget, m render.Matrix) *TransformWidget {
+	t := &TransformWidget{
+		child:   child,
+		matrix:  m,
+		originX: 0.5,
+		originY: 0.5,
+		applied: render.Identity(),
+	}
+	adopt(t, child)
+	return t
+}
+
+// Origin sets the point the matrix is applied about, as fractions of the
+// box from its top left corner, and returns the widget for chaining
+func (t *TransformWidget) Origin(x, y float32) *TransformWidget {
+	t.MarkNeedsPaint()
+	t.originX, t.originY = x, y
+	t.MarkNeedsPaint()
+	return t
+}
+
+// SetMatrix replaces the transform of the child
+func (t *TransformWidget) SetMatrix(m render.Matrix) {
+	t.MarkNeedsPaint()
+	t.matrix = m
+	t.MarkNeedsPaint()
+}
+
+// Matrix returns the transform of the child
+func (t *TransformWidget) Matrix() render.Matrix {
+	return t.matrix
+}
+
+// MarkNeedsPaint schedules the region the child was last drawn in to be repainted
+func (t *TransformWidget) MarkNeedsPaint() {
+	t.invalidate(t.paintBounds(&t.paintBox))
+}
+
+// GetConstraints returns the child's constraints
+func (t *TransformWidget) GetConstraints() Constraints {
+	if t.child == nil {
+		return NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return t.child.GetConstraints()
+}
+
+// Measure returns the size the child measures
+func (t *TransformWidget) Measure(constraints Constraints) Size {
+	return measure(t.child, constraints)
+}
+
+// Layout implements the Widget interface for TransformWidget; the child is
+// laid out in the widget's box
+func (t *TransformWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !t.NeedsLayout(ctx, constraints) {
+		return t.CachedSize(), nil
+	}
+	if size, err = layoutInset(ctx, t.child, Insets{}, constraints); chk.E(err) {
+		return
+	}
+	t.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for TransformWidget
+func (t *TransformWidget) Paint(ctx *Context, box *Box) (err error) {
+	t.applied = t.about(box)
+	inverse, ok := t.applied.Invert()
+	if t.child == nil || !ok {
+		// A collapsed child covers no area
+		return
+	}
+	// Skip descendants outside the part of the region that maps onto them
+	clipped := *ctx
+	if !ctx.Clip.Empty() {
+		clipped.Clip = transformRect(inverse, ctx.Clip)
+	}
+	list := ctx.DrawList
+	list.PushTransform(t.applied)
+	ctx.Hits.PushTransform(t.applied)
+	err = paintChild(&clipped, t.child, box)
+	ctx.Hits.PopTransform()
+	list.PopTransform()
+	return
+}
+
+// HandleEvent implements the Widget interface for TransformWidget
+func (t *TransformWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	inverse, ok := t.about(box).Invert()
+	if t.child == nil || !ok {
+		return false
+	}
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		e.Position = transformPoint(inverse, e.Position)
+		ev = e
+	case interfaces.MouseButtonEvent:
+		e.Position = transformPoint(inverse, e.Position)
+		ev = e
+	case interfaces.ScrollEvent:
+		e.Position = transformPoint(inverse, e.Position)
+		ev = e
+	}
+	return routeEvent(ctx, t.child, box, ev)
+}
+
+// addDamage implements damageSink, mapping regions the child's subtree
+// invalidates to where they are drawn before passing them on
+func (t *TransformWidget) addDamage(rect Rect) {
+	t.invalidate(transformRect(t.applied, rect))
+}
+
+// paintBounds implements overflowing, returning the region the transformed
+// child covers
+func (t *TransformWidget) paintBounds(box *Box) Rect {
+	return transformRect(t.about(box), box.Rect())
+}
+
+// about returns the matrix applied about the origin in the box
+func (t *TransformWidget) about(box *Box) render.Matrix {
+	x := box.Position.X + box.Size.Width*t.originX
+	y := box.Position.Y + box.Size.Height*t.originY
+	return render.Translate(-x, -y).Then(t.matrix).Then(render.Translate(x, y))
+}
+
+// transformPoint returns a point transformed by a matrix
+func transformPoint(m render.Matrix, p Point) Point {
+	x, y := m.Apply(p.X, p.Y)
+	return Point{X: x, Y: y}
+}
+
+// transformRect returns the bounding rect of a rect transformed by a matrix
+func transformRect(m render.Matrix, r Rect) Rect {
+	b := m.Bounds([4]float32{r.X, r.Y, r.Width, r.Height})
+	return Rect{X: b[0], Y: b[1], Width: b[2], Height: b[3]}
+}
@@ -0,0 +1,488 @@
+package widget
+
+import (
+	"math"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/render"
+	"github.com/mleku/goo/pkg/text"
+)
+
+const (
+	// treePadding is the space around the text of tree rows
+	treePadding = 4
+	// treeIndent is how far each level of a tree is indented, which is also
+	// the width of the column holding the disclosure triangles
+	treeIndent = 16
+	// treeArrow is the size of the disclosure triangles
+	treeArrow = 8
+)
+
+// TreeNode is a node of a tree widget. Nodes with children, or with
+// HasChildren set when the children are loaded on demand, can be expanded.
+type TreeNode struct {
+	Label string
+	// Icon is drawn before the label, as tall as the text, when set
+	Icon     *render.Texture
+	Children []*TreeNode
+	// HasChildren marks a node whose children are loaded by the tree's
+	// loader the first time it is expanded
+	HasChildren bool
+	// Data carries an application value for the node
+	Data     any
+	parent   *TreeNode
+	expanded bool
+	loaded   bool
+}
+
+// NewTreeNode creates a new collapsed tree node with the given children
+func NewTreeNode(label string, children ...*TreeNode) *TreeNode {
+	return (&TreeNode{Label: label}).Add(children...)
+}
+
+// Add appends children to the node and returns the node for chaining. Call
+// Refresh on the tree after changing nodes it shows.
+func (n *TreeNode) Add(children ...*TreeNode) *TreeNode {
+	for _, c := range children {
+		c.parent = n
+	}
+	n.Children = append(n.Children, children...)
+	return n
+}
+
+// Parent returns the node the node is a child of, nil for the roots
+func (n *TreeNode) Parent() *TreeNode {
+	return n.parent
+}
+
+// Expanded reports whether the node's children are showing
+func (n *TreeNode) Expanded() bool {
+	return n.expanded
+}
+
+// expandable reports whether the node has or may have children
+func (n *TreeNode) expandable() bool {
+	return len(n.Children) > 0 || (n.HasChildren && !n.loaded)
+}
+
+// treeRow is a visible node of a tree and its depth below the roots
+type treeRow struct {
+	node  *TreeNode
+	depth int
+}
+
+// TreeWidget shows hierarchical data as rows of nodes indented below their
+// parents, with triangles to expand and collapse the nodes with children.
+// Clicking a row selects it and clicking its triangle expands or collapses
+// it. While the tree has focus the up and down keys move the selection, the
+// right key expands the selected node or moves into it, the left key
+// collapses it or moves to its parent, and enter or space toggles it. Put
+// the tree in a scroll widget to show more rows than fit; the selection is
+// kept in view.
+type TreeWidget struct {
+	Base
+	font   *text.Font
+	size   float32
+	roots  []*TreeNode
+	loader func(node *TreeNode) []*TreeNode
+	// onSelect is invoked when the user selects a node
+	onSelect func(node *TreeNode)
+	selected *TreeNode
+	// hot is the node under the cursor, nil if none
+	hot *TreeNode
+	// rows are the visible nodes from top to bottom
+	rows []treeRow
+}
+
+// Tree creates a new tree showing the root nodes, drawn in the given font at
+// 14 pixels, with nothing selected
+func Tree(font *text.Font, roots ...*TreeNode) *TreeWidget {
+	t := &TreeWidget{
+		font: font,
+		size: 14,
+	}
+	t.SetRoots(roots...)
+	return t
+}
+
+// Size sets the pixel size of the text and returns the tree for chaining
+func (t *TreeWidget) Size(size float32) *TreeWidget {
+	t.size = size
+	t.MarkNeedsLayout()
+	return t
+}
+
+// Loader sets the callback returning the children of a node with
+// HasChildren set the first time it is expanded, and returns the tree for
+// chaining. Returning no children leaves the node without any.
+func (t *TreeWidget) Loader(fn func(node *TreeNode) []*TreeNode) *TreeWidget {
+	t.loader = fn
+	return t
+}
+
+// OnSelect sets the callback invoked with the node the user selects and
+// returns the tree for chaining
+func (t *TreeWidget) OnSelect(fn func(node *TreeNode)) *TreeWidget {
+	t.onSelect = fn
+	return t
+}
+
+// SetRoots replaces the nodes shown at the top level, clearing the selection
+func (t *TreeWidget) SetRoots(roots ...*TreeNode) {
+	for _, n := range roots {
+		n.parent = nil
+	}
+	t.roots = roots
+	t.selected, t.hot = nil, nil
+	t.Refresh()
+}
+
+// Roots returns the nodes shown at the top level
+func (t *TreeWidget) Roots() []*TreeNode {
+	return t.roots
+}
+
+// Refresh updates the rows after nodes were added, removed or changed
+func (t *TreeWidget) Refresh() {
+	t.rows = t.rows[:0]
+	var walk func(nodes []*TreeNode, depth int)
+	walk = func(nodes []*TreeNode, depth int) {
+		for _, n := range nodes {
+			t.rows = append(t.rows, treeRow{node: n, depth: depth})
+			if n.expanded {
+				walk(n.Children, depth+1)
+			}
+		}
+	}
+	walk(t.roots, 0)
+	if t.selected != nil && t.row(t.selected) < 0 {
+		t.selected = nil
+	}
+	t.MarkNeedsLayout()
+}
+
+// Expand shows the children of a node, loading them first if needed
+func (t *TreeWidget) Expand(n *TreeNode) {
+	if n.expanded {
+		return
+	}
+	if n.HasChildren && !n.loaded {
+		n.loaded = true
+		if t.loader != nil {
+			n.Add(t.loader(n)...)
+		}
+	}
+	if len(n.Children) == 0 {
+		return
+	}
+	n.expanded = true
+	t.Refresh()
+}
+
+// Collapse hides the children of a node. A selection inside it moves to
+// the node.
+func (t *TreeWidget) Collapse(n *TreeNode) {
+	if !n.expanded {
+		return
+	}
+	n.expanded = false
+	for p := t.selected; p != nil; p = p.parent {
+		if p.parent == n {
+			t.pick(n)
+			break
+		}
+	}
+	t.Refresh()
+}
+
+// Toggle expands a collapsed node and collapses an expanded one
+func (t *TreeWidget) Toggle(n *TreeNode) {
+	if n.expanded {
+		t.Collapse(n)
+	} else {
+		t.Expand(n)
+	}
+}
+
+// Select selects a node, nil for none, without invoking the select
+// callback. The node's ancestors are expanded so it is visible.
+func (t *TreeWidget) Select(n *TreeNode) {
+	if n != nil {
+		for p := n.parent; p != nil; p = p.parent {
+			t.Expand(p)
+		}
+	}
+	t.selected = n
+	t.MarkNeedsPaint()
+}
+
+// Selected returns the selected node, nil if none
+func (t *TreeWidget) Selected() *TreeNode {
+	return t.selected
+}
+
+// GetConstraints returns a minimum size that fits every visible row
+func (t *TreeWidget) GetConstraints() Constraints {
+	face := t.font.Face(t.size)
+	icon := t.iconSize()
+	var width float32
+	for _, r := range t.rows {
+		w := t.indent(r.depth) + face.Measure(r.node.Label) + treePadding
+		if r.node.Icon != nil {
+			w += icon + treePadding
+		}
+		width = max(width, w)
+	}
+	height := float32(len(t.rows)) * t.rowHeight()
+	return NewFlexConstraints(float32(math.Ceil(float64(width))), height, 1e9, 1e9)
+}
+
+// Measure returns the minimum size of the tree
+func (t *TreeWidget) Measure(constraints Constraints) Size {
+	return minSize(t.GetConstraints())
+}
+
+// Layout implements the Widget interface for TreeWidget; trees take all the
+// space offered
+func (t *TreeWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !t.NeedsLayout(ctx, constraints) {
+		return t.CachedSize(), nil
+	}
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	t.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for TreeWidget
+func (t *TreeWidget) Paint(ctx *Context, box *Box) (err error) {
+	th := themeOf(ctx)
+	list := ctx.DrawList
+	face := t.font.Face(t.size)
+	rowHeight := t.rowHeight()
+	icon := t.iconSize()
+	focused := hasFocus(t)
+	x, w := box.Position.X, box.Size.Width
+	for i, r := range t.rows {
+		y := box.Position.Y + float32(i)*rowHeight
+		if !ctx.Clip.Empty() && (y+rowHeight <= ctx.Clip.Y || y >= ctx.Clip.Y+ctx.Clip.Height) {
+			continue
+		}
+		n := r.node
+		switch {
+		case n == t.selected:
+			list.Rect(x, y, w, rowHeight, th.Selection)
+			if focused {
+				list.RoundRectStroke(x, y, w, rowHeight, th.Radius.Small, 1, th.Primary)
+			}
+		case n == t.hot:
+			list.Rect(x, y, w, rowHeight, th.SurfaceHover)
+		}
+		tx := x + t.indent(r.depth)
+		if n.expandable() {
+			// Point the triangle right when collapsed and down when expanded
+			ax := tx - treeIndent + (treeIndent-treeArrow)/2
+			ay := float32(math.Round(float64(y + rowHeight/2)))
+			half := float32(treeArrow / 2)
+			if n.expanded {
+				list.Polygon([][2]float32{{ax, ay - half/2}, {ax + treeArrow, ay - half/2}, {ax + half, ay + half/2}}, th.TextMuted)
+			} else {
+				list.Polygon([][2]float32{{ax + half/2, ay - half}, {ax + half*3/2, ay}, {ax + half/2, ay + half}}, th.TextMuted)
+			}
+		}
+		if n.Icon != nil {
+			list.Image(n.Icon, tx, y+treePadding, icon, icon, 0, 0, 1, 1, [4]float32{1, 1, 1, 1})
+			tx += icon + treePadding
+		}
+		face.Draw(list, tx, y+treePadding+face.Ascent(), n.Label, th.Text)
+	}
+	return
+}
+
+// Accessibility implements Accessible, with the label of the selected node
+// as the value
+func (t *TreeWidget) Accessibility() AccessNode {
+	node := AccessNode{Role: interfaces.RoleTree, Actions: []AccessAction{interfaces.AccessExpand, interfaces.AccessCollapse}}
+	if t.selected != nil {
+		node.Value = t.selected.Label
+		if t.selected.expandable() {
+			node.State |= interfaces.StateExpandable
+			if t.selected.expanded {
+				node.State |= interfaces.StateExpanded
+			}
+		}
+	}
+	return node
+}
+
+// AccessAction implements AccessActor, expanding or collapsing the selected
+// node
+func (t *TreeWidget) AccessAction(ctx *Context, action AccessAction, value string) bool {
+	if t.selected == nil || !t.selected.expandable() {
+		return false
+	}
+	switch action {
+	case interfaces.AccessExpand:
+		t.Expand(t.selected)
+	case interfaces.AccessCollapse:
+		t.Collapse(t.selected)
+	default:
+		return false
+	}
+	return true
+}
+
+// HandleEvent implements the Widget interface for TreeWidget
+func (t *TreeWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		var hot *TreeNode
+		if i := t.rowAt(box, e.Position); i >= 0 {
+			hot = t.rows[i].node
+		}
+		t.setHot(hot)
+	case interfaces.CursorLeaveEvent:
+		t.setHot(nil)
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonLeft || e.Action != interfaces.ActionPress {
+			return false
+		}
+		requestFocus(t)
+		i := t.rowAt(box, e.Position)
+		if i < 0 {
+			return true
+		}
+		r := t.rows[i]
+		// The triangle's column toggles the node, the rest of the row selects it
+		if left := box.Position.X + t.indent(r.depth); r.node.expandable() && e.Position.X >= left-treeIndent && e.Position.X < left {
+			t.Toggle(r.node)
+		} else {
+			t.pick(r.node)
+		}
+		return true
+	case interfaces.KeyEvent:
+		if !hasFocus(t) || e.Action == interfaces.ActionRelease {
+			return false
+		}
+		return t.key(e)
+	}
+	return false
+}
+
+// key moves the selection and expands and collapses nodes
+func (t *TreeWidget) key(e interfaces.KeyEvent) (handled bool) {
+	if len(t.rows) == 0 {
+		return false
+	}
+	i := t.row(t.selected)
+	n := t.selected
+	switch e.Key {
+	case interfaces.KeyUp:
+		t.pickRow(max(i-1, 0))
+	case interfaces.KeyDown:
+		t.pickRow(min(i+1, len(t.rows)-1))
+	case interfaces.KeyHome:
+		t.pickRow(0)
+	case interfaces.KeyEnd:
+		t.pickRow(len(t.rows) - 1)
+	case interfaces.KeyRight:
+		switch {
+		case n == nil:
+			t.pickRow(0)
+		case !n.expanded:
+			t.Expand(n)
+		case len(n.Children) > 0:
+			t.pick(n.Children[0])
+		}
+	case interfaces.KeyLeft:
+		switch {
+		case n == nil:
+			t.pickRow(0)
+		case n.expanded:
+			t.Collapse(n)
+		case n.parent != nil:
+			t.pick(n.parent)
+		}
+	case interfaces.KeyEnter, interfaces.KeySpace:
+		if n == nil {
+			return false
+		}
+		t.Toggle(n)
+	default:
+		return false
+	}
+	return true
+}
+
+// pick selects a node on behalf of the user, scrolling it into view and
+// invoking the select callback when the selection changed
+func (t *TreeWidget) pick(n *TreeNode) {
+	if i := t.row(n); i >= 0 {
+		box := t.paintBox
+		rowHeight := t.rowHeight()
+		scrollIntoView(t, Rect{X: box.Position.X, Y: box.Position.Y + float32(i)*rowHeight, Width: 1, Height: rowHeight})
+	}
+	if n == t.selected {
+		return
+	}
+	t.selected = n
+	t.MarkNeedsPaint()
+	if t.onSelect != nil {
+		t.onSelect(n)
+	}
+}
+
+// pickRow selects the node of a row on behalf of the user
+func (t *TreeWidget) pickRow(i int) {
+	if i >= 0 && i < len(t.rows) {
+		t.pick(t.rows[i].node)
+	}
+}
+
+// row returns the row showing a node, -1 if it is not visible
+func (t *TreeWidget) row(n *TreeNode) int {
+	if n == nil {
+		return -1
+	}
+	for i, r := range t.rows {
+		if r.node == n {
+			return i
+		}
+	}
+	return -1
+}
+
+// rowAt returns the row under a point, -1 if none
+func (t *TreeWidget) rowAt(box *Box, p Point) int {
+	if !box.Contains(p) {
+		return -1
+	}
+	i := int((p.Y - box.Position.Y) / t.rowHeight())
+	if i < 0 || i >= len(t.rows) {
+		return -1
+	}
+	return i
+}
+
+// indent returns the offset of the labels of rows at a depth from the left
+// edge, past the triangles of their level
+func (t *TreeWidget) indent(depth int) float32 {
+	return float32(depth+1) * treeIndent
+}
+
+// rowHeight returns the height of each row
+func (t *TreeWidget) rowHeight() float32 {
+	return float32(math.Ceil(float64(t.font.Face(t.size).LineHeight()))) + 2*treePadding
+}
+
+// iconSize returns the width and height of node icons
+func (t *TreeWidget) iconSize() float32 {
+	return t.rowHeight() - 2*treePadding
+}
+
+// setHot updates the node under the cursor, repainting when it changes
+func (t *TreeWidget) setHot(n *TreeNode) {
+	if n != t.hot {
+		t.hot = n
+		t.MarkNeedsPaint()
+	}
+}
@@ -0,0 +1,177 @@
+package widget
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/mleku/goo/pkg/theme"
+	"lol.mleku.dev/chk"
+)
+
+// stateful is implemented by widgets whose state is remembered with the
+// user interface once they are given a key with their StateKey method
+type stateful interface {
+	// stateKey returns the key the state is saved under, empty for none
+	stateKey() string
+	// saveState returns the state to save, which encodes to JSON
+	saveState() any
+	// restoreState restores the state saved before
+	restoreState(data json.RawMessage) error
+}
+
+// savedState is the state of a user interface as written to its file
+type savedState struct {
+	// Theme names the theme the user chose
+	Theme string `json:",omitempty"`
+	// State holds the state of keyed widgets and remembered values by key
+	State map[string]json.RawMessage `json:",omitempty"`
+}
+
+// remembered is a value kept with the state of the user interface
+type remembered struct {
+	save    func() any
+	restore func(data json.RawMessage) error
+}
+
+// uiState remembers the state of a user interface in a file
+type uiState struct {
+	path   string
+	themes []*theme.Theme
+	// saved holds the state read from the file by key until it is restored,
+	// kept for widgets that were not shown since
+	saved map[string]json.RawMessage
+	// widgets holds the keyed widgets shown so far and values the values
+	// remembered, by key
+	widgets map[string]stateful
+	values  map[string]remembered
+}
+
+// RememberState restores the state of the user interface saved in a file
+// by SaveState, and returns the root for chaining. The theme is chosen by
+// name from the given themes, the dark and light presets when none are
+// given. Widgets given a state key are restored when first shown, before
+// the frame they appear in is presented, and values kept with Remember as
+// they are added. A missing or unreadable file leaves everything as it was
+// configured.
+func (r *RootWidget) RememberState(path string, themes ...*theme.Theme) *RootWidget {
+	if len(themes) == 0 {
+		themes = []*theme.Theme{theme.Dark(), theme.Light()}
+	}
+	s := &uiState{
+		path:    path,
+		themes:  themes,
+		saved:   make(map[string]json.RawMessage),
+		widgets: make(map[string]stateful),
+		values:  make(map[string]remembered),
+	}
+	r.state = s
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return r
+	}
+	var saved savedState
+	if err = json.Unmarshal(data, &saved); chk.E(err) {
+		return r
+	}
+	if saved.State != nil {
+		s.saved = saved.State
+	}
+	for _, t := range themes {
+		if saved.Theme != "" && t.Name == saved.Theme {
+			r.SetTheme(t)
+			break
+		}
+	}
+	return r
+}
+
+// SaveState writes the state of the user interface to the file it is
+// remembered in: the name of the theme, the state of the keyed widgets and
+// the values kept with Remember, along with what was saved before for
+// widgets that were not shown since. Call it before the program exits.
+func (r *RootWidget) SaveState() (err error) {
+	s := r.state
+	if s == nil {
+		return
+	}
+	saved := savedState{State: make(map[string]json.RawMessage)}
+	if r.theme != nil {
+		saved.Theme = r.theme.Name
+	}
+	for key, data := range s.saved {
+		saved.State[key] = data
+	}
+	for key, w := range s.widgets {
+		if saved.State[key], err = json.Marshal(w.saveState()); chk.E(err) {
+			return
+		}
+	}
+	for key, v := range s.values {
+		if saved.State[key], err = json.Marshal(v.save()); chk.E(err) {
+			return
+		}
+	}
+	var data []byte
+	if data, err = json.MarshalIndent(saved, "", "\t"); chk.E(err) {
+		return
+	}
+	err = os.WriteFile(s.path, data, 0o644)
+	chk.E(err)
+	return
+}
+
+// Remember keeps a value with the state of the user interface under a key,
+// such as the geometry of the window, saving what get returns and passing
+// the value saved before to set at once. It does nothing unless the root
+// remembers its state.
+func Remember[T any](r *RootWidget, key string, get func() T, set func(T)) {
+	s := r.state
+	if s == nil {
+		return
+	}
+	v := remembered{
+		save: func() any { return get() },
+		restore: func(data json.RawMessage) (err error) {
+			var value T
+			if err = json.Unmarshal(data, &value); err != nil {
+				return
+			}
+			set(value)
+			return
+		},
+	}
+	s.values[key] = v
+	if data, ok := s.saved[key]; ok {
+		delete(s.saved, key)
+		chk.E(v.restore(data))
+	}
+}
+
+// trackState finds the keyed widgets painted, restoring those shown for
+// the first time from their saved state, and reports whether any was
+func (r *RootWidget) trackState() (restored bool) {
+	s := r.state
+	if s == nil {
+		return
+	}
+	for _, region := range r.hits.Regions() {
+		w, ok := region.Target.(stateful)
+		if !ok {
+			continue
+		}
+		key := w.stateKey()
+		if key == "" || s.widgets[key] == w {
+			continue
+		}
+		s.widgets[key] = w
+		data, ok := s.saved[key]
+		if !ok {
+			continue
+		}
+		delete(s.saved, key)
+		if !chk.E(w.restoreState(data)) {
+			restored = true
+		}
+	}
+	return
+}
@@ -1,8 +1,13 @@
 package widget
 
 import (
+	"time"
+
+	"github.com/mleku/goo/pkg/i18n"
 	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/theme"
 	"lol.mleku.dev/chk"
+	"lol.mleku.dev/log"
 )
 
 // Re-export types from interfaces package for convenience
@@ -13,6 +18,10 @@ type (
 	Box         = interfaces.Box
 	Context     = interfaces.Context
 	Widget      = interfaces.Widget
+	Event       = interfaces.Event
+	Rect        = interfaces.Rect
+	Cursor      = interfaces.Cursor
+	WindowArea  = interfaces.WindowArea
 )
 
 // NewConstraints creates constraints with min/max values and position
@@ -104,6 +113,14 @@ const (
 	DirectionColumn
 )
 
+// String returns the name of the direction
+func (d Direction) String() string {
+	if d == DirectionColumn {
+		return "column"
+	}
+	return "row"
+}
+
 // FlexType specifies whether a widget is rigid or flexible
 type FlexType int
 
@@ -137,11 +154,50 @@ func NewFlexChild(widget Widget, weight float32) FlexChild {
 	}
 }
 
+// Justify places the children of a row or column along its main axis when
+// they do not fill it
+type Justify int
+
+const (
+	JustifyStart Justify = iota
+	JustifyCenter
+	JustifyEnd
+	// JustifySpaceBetween spreads the children to fill the container, with the
+	// first and last at its ends
+	JustifySpaceBetween
+	// JustifySpaceAround spreads the children to fill the container, with half
+	// the space between them at its ends
+	JustifySpaceAround
+)
+
+// CrossAlign places the children of a row or column across its main axis
+type CrossAlign int
+
+const (
+	// CrossStretch sizes children to the full height of a row or width of a
+	// column
+	CrossStretch CrossAlign = iota
+	// CrossStart, CrossCenter and CrossEnd size children to the size they
+	// measure and place them at the top, middle or bottom of a row, or the left,
+	// middle or right of a column
+	CrossStart
+	CrossCenter
+	CrossEnd
+)
+
 // Container is a widget that lays out children in rows or columns
 type Container struct {
+	Base
 	Direction   Direction
 	Children    []FlexChild
 	constraints Constraints
+	justify     Justify
+	crossAlign  CrossAlign
+	// overflow is how far the children extended past the end in the last
+	// layout
+	overflow float32
+	// boxes holds the child boxes from the last layout, relative to the container
+	boxes []Box
 }
 
 // Row creates a new row container with default flexible constraints.
@@ -195,26 +251,49 @@ func NewContainer(direction Direction, constraints ...Constraints) *Container {
 // AddChild adds a child widget to the container and returns the container for chaining
 func (c *Container) AddChild(child FlexChild) *Container {
 	c.Children = append(c.Children, child)
+	adopt(c, child.Widget)
 	return c
 }
 
 // Flex adds a flexible child with the specified weight to the container
 func (c *Container) Flex(child Widget, weight float32) *Container {
-	c.Children = append(c.Children, FlexChild{
+	return c.AddChild(FlexChild{
 		Widget: child,
 		Type:   FlexTypeFlex,
 		Weight: weight,
 	})
-	return c
 }
 
 // Rigid adds a rigid child to the container
 func (c *Container) Rigid(child Widget) *Container {
-	c.Children = append(c.Children, FlexChild{
+	return c.AddChild(FlexChild{
 		Widget: child,
 		Type:   FlexTypeRigid,
 		Weight: 0,
 	})
+}
+
+// Spacer adds empty space taking a share of the space left over with the
+// specified weight, and returns the container for chaining
+func (c *Container) Spacer(weight float32) *Container {
+	return c.Flex(Spacer(), weight)
+}
+
+// Justify sets how children are placed along the main axis when they do not
+// fill the container, and returns the container for chaining. Space left
+// over goes to flexible children first, so justification only takes effect
+// when there are none or they reach their maximum size.
+func (c *Container) Justify(justify Justify) *Container {
+	c.justify = justify
+	c.MarkNeedsLayout()
+	return c
+}
+
+// CrossAlign sets how children are sized and placed across the main axis,
+// and returns the container for chaining. Children stretch by default.
+func (c *Container) CrossAlign(align CrossAlign) *Container {
+	c.crossAlign = align
+	c.MarkNeedsLayout()
 	return c
 }
 
@@ -223,235 +302,531 @@ func (c *Container) GetConstraints() Constraints {
 	return c.constraints
 }
 
-// Render implements the Widget interface for Container
-func (c *Container) Render(ctx *Context, box *Box) (usedSize Size, err error) {
-	if len(c.Children) == 0 {
-		return Size{}, nil
+// Measure returns the size the children measure placed along the container,
+// at least its minimum
+func (c *Container) Measure(constraints Constraints) (size Size) {
+	loose := NewFlexConstraints(0, 0, constraints.MaxWidth, constraints.MaxHeight)
+	for _, child := range c.Children {
+		m := child.Widget.Measure(loose)
+		if c.Direction == DirectionRow {
+			size.Width += m.Width
+			size.Height = max(size.Height, m.Height)
+		} else {
+			size.Width = max(size.Width, m.Width)
+			size.Height += m.Height
+		}
 	}
+	return atLeast(size, c.constraints)
+}
 
-	// Calculate layout based on direction
-	switch c.Direction {
-	case DirectionRow:
-		return c.renderRow(ctx, box)
-	case DirectionColumn:
-		return c.renderColumn(ctx, box)
-	default:
-		return Size{}, errInvalidDirection
+// Layout implements the Widget interface for Container
+func (c *Container) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !c.NeedsLayout(ctx, constraints) {
+		return c.CachedSize(), nil
 	}
+	c.boxes = c.boxes[:0]
+	if len(c.Children) > 0 {
+		// Calculate layout based on direction
+		switch c.Direction {
+		case DirectionRow:
+			size, err = c.layoutRow(ctx, constraints)
+		case DirectionColumn:
+			size, err = c.layoutColumn(ctx, constraints)
+		default:
+			err = errInvalidDirection
+		}
+		if err != nil {
+			return Size{}, err
+		}
+	}
+	c.SetLayout(ctx, constraints, size)
+	return
 }
 
-// renderRow lays out children horizontally
-func (c *Container) renderRow(ctx *Context, box *Box) (usedSize Size, err error) {
-	availableWidth := box.Size.Width
-	availableHeight := box.Size.Height
-
-	// First pass: calculate rigid sizes and total flex weight
-	var rigidWidth float32
-	var totalFlexWeight float32
-	var maxHeight float32
-
-	for _, child := range c.Children {
-		childConstraints := child.Widget.GetConstraints()
+// Paint implements the Widget interface for Container
+func (c *Container) Paint(ctx *Context, box *Box) (err error) {
+	if c.overflow > 0 {
+		ctx.DrawList.PushClip(box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height)
+		defer ctx.DrawList.PopClip()
+	}
+	for i := range c.boxes {
+		if i >= len(c.Children) {
+			break
+		}
+		if err = paintChild(ctx, c.Children[i].Widget, childBox(box, &c.boxes[i])); chk.E(err) {
+			return
+		}
+	}
+	return
+}
 
-		if child.Type == FlexTypeRigid {
-			rigidWidth += childConstraints.MinWidth
-			if childConstraints.MinHeight > maxHeight {
-				maxHeight = childConstraints.MinHeight
-			}
-		} else {
-			totalFlexWeight += child.Weight
-			if childConstraints.MinHeight > maxHeight {
-				maxHeight = childConstraints.MinHeight
+// HandleEvent implements the Widget interface for Container
+func (c *Container) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	_, targeted := interfaces.Target(ev)
+	for i := len(c.boxes) - 1; i >= 0; i-- {
+		if i >= len(c.Children) {
+			continue
+		}
+		if routeEvent(ctx, c.Children[i].Widget, childBox(box, &c.boxes[i]), ev) {
+			handled = true
+			if targeted {
+				return
 			}
 		}
 	}
+	return
+}
+
+// layoutRow lays out children horizontally
+func (c *Container) layoutRow(ctx *Context, constraints Constraints) (usedSize Size, err error) {
+	availableWidth := constraints.MaxWidth
+	availableHeight := constraints.MaxHeight
 
-	// Calculate remaining width for flex children
-	flexWidth := availableWidth - rigidWidth
-	if flexWidth < 0 {
-		flexWidth = 0
+	// First pass: measure children and divide the width between them
+	measured := make([]Size, len(c.Children))
+	for i, child := range c.Children {
+		measured[i] = child.Widget.Measure(NewFlexConstraints(0, 0, availableWidth, availableHeight))
 	}
+	mainSizes := c.flexSizes(measured, availableWidth)
 
-	// Second pass: render children
-	var currentX float32
+	// Second pass: lay out children
+	// boxes are the sizes the children are given, and sizes what they use
+	boxes := make([]Size, len(c.Children))
+	sizes := make([]Size, len(c.Children))
 	var actualUsedWidth float32
 	var actualMaxHeight float32
+	var totalWidth float32
 
-	for _, child := range c.Children {
-		childConstraints := child.Widget.GetConstraints()
-		var childWidth float32
-
-		if child.Type == FlexTypeRigid {
-			childWidth = childConstraints.MinWidth
-		} else {
-			if totalFlexWeight > 0 {
-				childWidth = (flexWidth * child.Weight) / totalFlexWeight
-				// Clamp to constraints
-				if childWidth < childConstraints.MinWidth {
-					childWidth = childConstraints.MinWidth
-				}
-				if childWidth > childConstraints.MaxWidth {
-					childWidth = childConstraints.MaxWidth
-				}
-			} else {
-				childWidth = childConstraints.MinWidth
-			}
+	for i, child := range c.Children {
+		childWidth := mainSizes[i]
+		childHeight := availableHeight
+		if c.crossAlign != CrossStretch {
+			childHeight = min(measured[i].Height, availableHeight)
 		}
 
-		// Create child box
-		childBox := &Box{
-			Position: Point{
-				X: box.Position.X + currentX,
-				Y: box.Position.Y,
-			},
-			Size: Size{
-				Width:  childWidth,
-				Height: availableHeight,
-			},
-			Constraints: childConstraints,
+		// Lay out child
+		boxes[i] = Size{Width: childWidth, Height: childHeight}
+		sizes[i], err = child.Widget.Layout(ctx, NewRigidConstraints(childWidth, childHeight))
+		if chk.E(err) {
+			return Size{}, err
 		}
 
-		// Create child context
-		childCtx := &Context{
-			WindowWidth:   ctx.WindowWidth,
-			WindowHeight:  ctx.WindowHeight,
-			ParentBox:     childBox,
-			AvailableSize: childBox.Size,
-		}
+		totalWidth += childWidth
+		actualUsedWidth += sizes[i].Width
 
-		// Render child
-		childUsedSize, err := child.Widget.Render(childCtx, childBox)
-		if chk.E(err) {
-			return Size{}, err
+		if sizes[i].Height > actualMaxHeight {
+			actualMaxHeight = sizes[i].Height
 		}
+	}
+	c.setOverflow(totalWidth, availableWidth)
 
-		currentX += childUsedSize.Width
-		actualUsedWidth += childUsedSize.Width
+	// Third pass: place children along the row and within its height
+	currentX, gap := c.spread(availableWidth-actualUsedWidth, len(c.Children))
+	if c.justify != JustifyStart && availableWidth < 1e9 {
+		actualUsedWidth = max(actualUsedWidth, availableWidth)
+	}
+	if c.crossAlign != CrossStretch && availableHeight < 1e9 {
+		actualMaxHeight = max(actualMaxHeight, availableHeight)
+	}
 
-		if childUsedSize.Height > actualMaxHeight {
-			actualMaxHeight = childUsedSize.Height
+	for i, child := range c.Children {
+		c.boxes = append(c.boxes, Box{
+			Position: Point{
+				X: currentX,
+				Y: c.crossOffset(availableHeight - boxes[i].Height),
+			},
+			Size:        boxes[i],
+			Constraints: child.Widget.GetConstraints(),
+		})
+		currentX += sizes[i].Width + gap
+	}
+	if ctx.RTL {
+		// Mirrored rows place their first child at the right, filling the
+		// width they are given like justified rows
+		if availableWidth < 1e9 {
+			actualUsedWidth = max(actualUsedWidth, availableWidth)
+		}
+		for i := range c.boxes {
+			b := &c.boxes[i]
+			b.Position.X = actualUsedWidth - b.Position.X - b.Size.Width
 		}
 	}
 
 	return Size{Width: actualUsedWidth, Height: actualMaxHeight}, nil
 }
 
-// renderColumn lays out children vertically
-func (c *Container) renderColumn(ctx *Context, box *Box) (usedSize Size, err error) {
-	availableWidth := box.Size.Width
-	availableHeight := box.Size.Height
+// layoutColumn lays out children vertically
+func (c *Container) layoutColumn(ctx *Context, constraints Constraints) (usedSize Size, err error) {
+	availableWidth := constraints.MaxWidth
+	availableHeight := constraints.MaxHeight
 
-	// First pass: calculate rigid sizes and total flex weight
-	var rigidHeight float32
-	var totalFlexWeight float32
-	var maxWidth float32
+	// First pass: measure children and divide the height between them
+	measured := make([]Size, len(c.Children))
+	for i, child := range c.Children {
+		measured[i] = child.Widget.Measure(NewFlexConstraints(0, 0, availableWidth, availableHeight))
+	}
+	mainSizes := c.flexSizes(measured, availableHeight)
 
-	for _, child := range c.Children {
-		childConstraints := child.Widget.GetConstraints()
+	// Second pass: lay out children
+	// boxes are the sizes the children are given, and sizes what they use
+	boxes := make([]Size, len(c.Children))
+	sizes := make([]Size, len(c.Children))
+	var actualUsedHeight float32
+	var actualMaxWidth float32
+	var totalHeight float32
 
-		if child.Type == FlexTypeRigid {
-			rigidHeight += childConstraints.MinHeight
-			if childConstraints.MinWidth > maxWidth {
-				maxWidth = childConstraints.MinWidth
-			}
-		} else {
-			totalFlexWeight += child.Weight
-			if childConstraints.MinWidth > maxWidth {
-				maxWidth = childConstraints.MinWidth
-			}
+	for i, child := range c.Children {
+		childHeight := mainSizes[i]
+		childWidth := availableWidth
+		if c.crossAlign != CrossStretch {
+			childWidth = min(measured[i].Width, availableWidth)
+		}
+
+		// Lay out child
+		boxes[i] = Size{Width: childWidth, Height: childHeight}
+		sizes[i], err = child.Widget.Layout(ctx, NewRigidConstraints(childWidth, childHeight))
+		if chk.E(err) {
+			return Size{}, err
+		}
+
+		totalHeight += childHeight
+		actualUsedHeight += sizes[i].Height
+
+		if sizes[i].Width > actualMaxWidth {
+			actualMaxWidth = sizes[i].Width
 		}
 	}
+	c.setOverflow(totalHeight, availableHeight)
 
-	// Calculate remaining height for flex children
-	flexHeight := availableHeight - rigidHeight
-	if flexHeight < 0 {
-		flexHeight = 0
+	// Third pass: place children down the column and within its width
+	currentY, gap := c.spread(availableHeight-actualUsedHeight, len(c.Children))
+	if c.justify != JustifyStart && availableHeight < 1e9 {
+		actualUsedHeight = max(actualUsedHeight, availableHeight)
+	}
+	if c.crossAlign != CrossStretch && availableWidth < 1e9 {
+		actualMaxWidth = max(actualMaxWidth, availableWidth)
 	}
 
-	// Second pass: render children
-	var currentY float32
-	var actualUsedHeight float32
-	var actualMaxWidth float32
+	for i, child := range c.Children {
+		c.boxes = append(c.boxes, Box{
+			Position: Point{
+				X: c.crossOffset(availableWidth - boxes[i].Width),
+				Y: currentY,
+			},
+			Size:        boxes[i],
+			Constraints: child.Widget.GetConstraints(),
+		})
+		currentY += sizes[i].Height + gap
+	}
 
-	for _, child := range c.Children {
-		childConstraints := child.Widget.GetConstraints()
-		var childHeight float32
+	return Size{Width: actualMaxWidth, Height: actualUsedHeight}, nil
+}
 
-		if child.Type == FlexTypeRigid {
-			childHeight = childConstraints.MinHeight
-		} else {
-			if totalFlexWeight > 0 {
-				childHeight = (flexHeight * child.Weight) / totalFlexWeight
-				// Clamp to constraints
-				if childHeight < childConstraints.MinHeight {
-					childHeight = childConstraints.MinHeight
-				}
-				if childHeight > childConstraints.MaxHeight {
-					childHeight = childConstraints.MaxHeight
-				}
-			} else {
-				childHeight = childConstraints.MinHeight
+// flexSizes returns the sizes of the children along the main axis given the
+// sizes they measure and the space available. Rigid children, and flexible
+// children without weight, take the size they measure, and flexible
+// children share the rest in proportion to their weights. A flexible child
+// held at its minimum takes more than its share, and one held at its
+// maximum less, so the others are given their shares again from what is
+// left, until none is held.
+func (c *Container) flexSizes(measured []Size, available float32) (sizes []float32) {
+	row := c.Direction == DirectionRow
+	sizes = make([]float32, len(c.Children))
+	free := available
+	var flexing []int
+	for i, child := range c.Children {
+		if child.Type == FlexTypeFlex && child.Weight > 0 {
+			flexing = append(flexing, i)
+			continue
+		}
+		sizes[i] = measured[i].Height
+		if row {
+			sizes[i] = measured[i].Width
+		}
+		free -= sizes[i]
+	}
+	for len(flexing) > 0 {
+		var weight float32
+		for _, i := range flexing {
+			weight += c.Children[i].Weight
+		}
+		share := max(free, 0) / weight
+		// violation is how far the held children were moved from their
+		// shares in total, more when they take more
+		var violation float32
+		for _, i := range flexing {
+			cc := c.Children[i].Widget.GetConstraints()
+			lo, hi := cc.MinHeight, cc.MaxHeight
+			if row {
+				lo, hi = cc.MinWidth, cc.MaxWidth
 			}
+			want := share * c.Children[i].Weight
+			sizes[i] = min(max(want, lo), hi)
+			violation += sizes[i] - want
 		}
-
-		// Create child box
-		childBox := &Box{
-			Position: Point{
-				X: box.Position.X,
-				Y: box.Position.Y + currentY,
-			},
-			Size: Size{
-				Width:  availableWidth,
-				Height: childHeight,
-			},
-			Constraints: childConstraints,
+		if violation == 0 {
+			break
 		}
-
-		// Create child context
-		childCtx := &Context{
-			WindowWidth:   ctx.WindowWidth,
-			WindowHeight:  ctx.WindowHeight,
-			ParentBox:     childBox,
-			AvailableSize: childBox.Size,
+		// Fix the children held the way the total moved, and share what is
+		// left between the rest
+		var rest []int
+		for _, i := range flexing {
+			want := share * c.Children[i].Weight
+			if (violation > 0 && sizes[i] > want) || (violation < 0 && sizes[i] < want) {
+				free -= sizes[i]
+			} else {
+				rest = append(rest, i)
+			}
 		}
+		flexing = rest
+	}
+	return
+}
 
-		// Render child
-		childUsedSize, err := child.Widget.Render(childCtx, childBox)
-		if chk.E(err) {
-			return Size{}, err
-		}
+// setOverflow records how far the children extend past the end of the
+// container, given their total size and the space available along the
+// main axis, and warns when the container starts to overflow
+func (c *Container) setOverflow(total, available float32) {
+	overflow := float32(0)
+	if available < 1e9 && total > available {
+		overflow = total - available
+	}
+	if overflow > 0 && c.overflow == 0 {
+		log.W.F("%s overflows its %.0f pixels by %.0f", c.Direction, available, overflow)
+	}
+	c.overflow = overflow
+	if r := rootOf(c); r != nil {
+		r.reportOverflow(c, overflow > 0)
+	}
+}
 
-		currentY += childUsedSize.Height
-		actualUsedHeight += childUsedSize.Height
+// Overflow returns how far the children extended past the end of the
+// container along its main axis in the last layout, when they could not
+// be made small enough to fit. Children are clipped to the container
+// while it overflows, and the debug overlay outlines it.
+func (c *Container) Overflow() float32 {
+	return c.overflow
+}
 
-		if childUsedSize.Width > actualMaxWidth {
-			actualMaxWidth = childUsedSize.Width
+// spread returns where the first child starts along the main axis and the
+// space added between children, given the space left over. Nothing is
+// spread along an unbounded axis.
+func (c *Container) spread(free float32, count int) (start, gap float32) {
+	if free <= 0 || free >= 1e8 || count == 0 {
+		return
+	}
+	switch c.justify {
+	case JustifyCenter:
+		start = free / 2
+	case JustifyEnd:
+		start = free
+	case JustifySpaceBetween:
+		if count > 1 {
+			gap = free / float32(count-1)
+		} else {
+			start = free / 2
 		}
+	case JustifySpaceAround:
+		gap = free / float32(count)
+		start = gap / 2
 	}
+	return
+}
 
-	return Size{Width: actualMaxWidth, Height: actualUsedHeight}, nil
+// crossOffset returns where a child starts across the main axis given the
+// space it leaves over
+func (c *Container) crossOffset(free float32) float32 {
+	free = max(free, 0)
+	switch c.crossAlign {
+	case CrossCenter:
+		return free / 2
+	case CrossEnd:
+		return free
+	}
+	return 0
 }
 
 // RootWidget manages the root layout that spans the entire canvas
 type RootWidget struct {
-	child      Widget
-	clearColor [4]float32
+	Base
+	child Widget
+	// statusBar is pinned below body, the child the root was given, while set
+	statusBar *StatusBarWidget
+	body      Widget
+	// toaster shows toasts above the tree while set
+	toaster *Toaster
+	// clearColor fills the canvas behind the tree, the theme background unless set
+	clearColor colorOverride
+	// theme is passed to the tree through the context when set
+	theme *theme.Theme
+	// rtl mirrors the tree's layout for a right to left language, and
+	// unlocalize stops following the catalog that sets it
+	rtl        bool
+	unlocalize func()
+	// epoch counts the times every cached layout in the tree was discarded
+	// at once, passed to the tree through the context
+	epoch uint64
+	// access follows the accessibility tree for assistive technology, nil
+	// until a bridge or name is set
+	access *accessTree
+	// childBox is the child's box from the last layout, relative to the canvas
+	childBox *Box
+	// damage holds the regions to repaint on the next frame
+	damage []Rect
+	// fullDamage forces the whole canvas to be repainted on the next frame
+	fullDamage bool
+	// focused is the widget receiving keyboard input and blurred the one the
+	// last click took focus from
+	focused, blurred Widget
+	// popups are drawn above the tree, from bottom to top
+	popups []*Popup
+	// timers are the callbacks waiting for a later frame
+	timers []*timer
+	// shortcuts are offered the key presses the focus owner leaves unhandled
+	shortcuts []shortcutHandler
+	// hits holds the interactive regions registered by the tree as it paints
+	hits interfaces.Hits
+	// drag is the drag and drop operation in progress, nil when there is none
+	drag *Drag
+	// onFileDrop receives the files dropped on the window that no widget took
+	onFileDrop func(paths []string, at Point)
+	// onGamepad is offered gamepad input before it navigates
+	onGamepad func(e interfaces.GamepadEvent) bool
+	// focusVisible is set while the focus owner was reached by gamepad
+	// navigation, which draws a ring around it
+	focusVisible bool
+	// pointer is the last known mouse position, pointerIn whether the mouse
+	// is over the window
+	pointer   Point
+	pointerIn bool
+	// windowDrag is the part of the window's frame a press landed in, for
+	// the window to move or resize itself, until taken by WindowDrag
+	windowDrag WindowArea
+	// debug is the debug overlay, nil unless enabled
+	debug *debugOverlay
+	// profile records what painting each frame costs, nil unless profiling
+	profile *profiler
+	// pacing watches for frames missing their deadline, nil unless enabled
+	pacing *pacingMonitor
+	// paintCost records what each widget costs to paint while profiling or
+	// monitoring the frame pacing
+	paintCost interfaces.PaintProfile
+	// highlight is the widget outlined above the tree, nil for none
+	highlight Widget
+	// decorated holds the regions the focus ring, debug overlay and
+	// highlight were drawn over in the last frame
+	decorated []Rect
+	// overflowing holds the containers whose children overflowed them in
+	// their last layout
+	overflowing map[Widget]struct{}
+	// mouseTouch is the touch standing in for the mouse while touchMouse is set
+	mouseTouch int
+	touchMouse bool
+	// claimed is the touch a widget took for a gesture while claiming is set
+	claimed  int
+	claiming bool
+	// state remembers the state of the user interface, nil unless enabled
+	state *uiState
 }
 
 // Root creates a new root widget with the given child
 func Root(child Widget) *RootWidget {
-	return &RootWidget{
-		child:      child,
-		clearColor: [4]float32{0.0, 0.0, 0.0, 1.0}, // Default black
+	r := &RootWidget{
+		child: child,
 	}
+	adopt(r, child)
+	return r
 }
 
 // SetClearColor sets the background clear color for the root widget and returns the root for chaining
 func (r *RootWidget) SetClearColor(red, green, blue, alpha float32) *RootWidget {
-	r.clearColor = [4]float32{red, green, blue, alpha}
+	r.clearColor = override([4]float32{red, green, blue, alpha})
+	r.InvalidateAll()
 	return r
 }
 
+// SetTheme sets the theme the tree is drawn with, repainting everything,
+// and returns the root for chaining. A nil theme uses the context's theme.
+func (r *RootWidget) SetTheme(t *theme.Theme) *RootWidget {
+	r.theme = t
+	r.InvalidateAll()
+	return r
+}
+
+// Theme returns the theme set on the root, nil if none
+func (r *RootWidget) Theme() *theme.Theme {
+	return r.theme
+}
+
+// SetRTL mirrors the layout of the tree for a right to left language, laying
+// everything out again, and returns the root for chaining
+func (r *RootWidget) SetRTL(rtl bool) *RootWidget {
+	if rtl == r.rtl {
+		return r
+	}
+	r.rtl = rtl
+	r.relayoutAll()
+	r.InvalidateAll()
+	return r
+}
+
+// RTL reports whether the layout is mirrored
+func (r *RootWidget) RTL() bool {
+	return r.rtl
+}
+
+// Localize follows the locale of a catalog, and returns the root for
+// chaining. When the locale switches the tree is laid out again, as its text
+// may have changed size, and mirrored for right to left languages. A nil
+// catalog stops following.
+func (r *RootWidget) Localize(c *i18n.Catalog) *RootWidget {
+	if r.unlocalize != nil {
+		r.unlocalize()
+		r.unlocalize = nil
+	}
+	if c == nil {
+		return r
+	}
+	r.SetRTL(c.Locale().RTL)
+	r.unlocalize = c.Subscribe(func() {
+		r.rtl = c.Locale().RTL
+		r.relayoutAll()
+		r.InvalidateAll()
+	})
+	return r
+}
+
+// relayoutAll discards the cached layout of every widget in the tree, for
+// changes that affect widgets throughout it without telling them
+func (r *RootWidget) relayoutAll() {
+	r.epoch++
+}
+
+// themed returns the context with the root's theme, hit registry, layout
+// epoch and profile applied
+func (r *RootWidget) themed(ctx *Context) *Context {
+	themed := *ctx
+	if r.theme != nil {
+		themed.Theme = r.theme
+	}
+	themed.RTL = themed.RTL || r.rtl
+	// A root inside another adds its epoch to the outer one's, so either
+	// discarding its layouts lays the inner tree out again
+	themed.LayoutEpoch += r.epoch
+	themed.Hits = &r.hits
+	themed.Profile = r.paintCosts()
+	return &themed
+}
+
+// HitTest returns the topmost widget painted under a point in the last
+// frame, nil when there is none or the point is covered by the backdrop of
+// a modal popup
+func (r *RootWidget) HitTest(p Point) Widget {
+	target, _ := r.hits.HitTest(p)
+	w, _ := target.(Widget)
+	return w
+}
+
 // GetConstraints returns unconstrained size (fills canvas)
 func (r *RootWidget) GetConstraints() Constraints {
 	return Constraints{
@@ -462,18 +837,30 @@ func (r *RootWidget) GetConstraints() Constraints {
 	}
 }
 
-// Render implements the Widget interface for RootWidget
-func (r *RootWidget) Render(ctx *Context, box *Box) (usedSize Size, err error) {
+// Measure returns the minimum size of the root
+func (r *RootWidget) Measure(constraints Constraints) Size {
+	return minSize(r.GetConstraints())
+}
+
+// Layout implements the Widget interface for RootWidget.
+// The constraints maximum is the canvas size.
+func (r *RootWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !r.NeedsLayout(ctx, constraints) {
+		return r.CachedSize(), nil
+	}
 	if r.child == nil {
-		return box.Size, nil
+		r.childBox = nil
+		size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+		r.SetLayout(ctx, constraints, size)
+		return
 	}
 
 	// Get child constraints to determine positioning
 	childConstraints := r.child.GetConstraints()
 
 	// Create a box that spans the entire canvas, but position child based on its constraints
-	canvasWidth := float32(ctx.WindowWidth)
-	canvasHeight := float32(ctx.WindowHeight)
+	canvasWidth := constraints.MaxWidth
+	canvasHeight := constraints.MaxHeight
 
 	// Use constraint coordinates if specified, otherwise fill canvas
 	childBox := &Box{
@@ -501,23 +888,125 @@ func (r *RootWidget) Render(ctx *Context, box *Box) (usedSize Size, err error) {
 	if childConstraints.MinHeight > childBox.Size.Height {
 		childBox.Size.Height = childConstraints.MinHeight
 	}
+	r.childBox = childBox
 
-	// Create context for child
-	childCtx := &Context{
-		WindowWidth:   ctx.WindowWidth,
-		WindowHeight:  ctx.WindowHeight,
-		ParentBox:     childBox,
-		AvailableSize: childBox.Size,
+	// Lay out child
+	if size, err = r.child.Layout(ctx, NewRigidConstraints(childBox.Size.Width, childBox.Size.Height)); chk.E(err) {
+		return
+	}
+	r.SetLayout(ctx, constraints, size)
+
+	// Anything may have moved so the whole canvas must be repainted
+	r.InvalidateAll()
+	return
+}
+
+// Paint implements the Widget interface for RootWidget
+func (r *RootWidget) Paint(ctx *Context, box *Box) (err error) {
+	if r.child != nil && r.childBox != nil {
+		if err = paintChild(ctx, r.child, childBox(box, r.childBox)); chk.E(err) {
+			return
+		}
+	}
+	if err = r.paintPopups(ctx); chk.E(err) {
+		return
+	}
+	return r.paintDrag(ctx)
+}
+
+// HandleEvent implements the Widget interface for RootWidget
+func (r *RootWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if e, ok := ev.(interfaces.TouchEvent); ok {
+		return r.touchEvent(ctx, box, e)
+	}
+	return r.handleEvent(ctx, box, ev)
+}
+
+// handleEvent delivers an event to the popups, the focus owner or the tree
+func (r *RootWidget) handleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.ExposeEvent:
+		r.InvalidateAll()
+		return true
+	case interfaces.AccessActionEvent:
+		return r.accessEvent(ctx, e)
+	}
+	r.trackPointer(ev)
+	if r.drag != nil && r.dragEvent(ev) {
+		return true
+	}
+	if len(r.popups) > 0 {
+		if handled, done := r.popupEvent(ctx, ev); done {
+			return handled
+		}
+	}
+	switch e := ev.(type) {
+	case interfaces.MouseButtonEvent:
+		if e.Action == interfaces.ActionPress && e.Button == interfaces.MouseButtonLeft {
+			// Clicking moves focus to whichever widget requests it, or
+			// nowhere. Other buttons leave focus alone so context menus can
+			// act on the focus owner.
+			r.blurred = r.focused
+			r.SetFocus(nil)
+			r.focusVisible = false
+		}
+	case interfaces.GamepadEvent:
+		return r.gamepadEvent(ctx, box, e)
+	case interfaces.KeyEvent:
+		if r.debugEvent(e) {
+			return true
+		}
+		// Keyboard input goes straight to the focus owner, so a widget that
+		// passes focus on while handling a key does not see the key again.
+		// Escape closes the top popup when the focus owner has no use for it.
+		// Other keys it leaves are offered as shortcuts.
+		if r.focusEvent(ctx, ev) {
+			return true
+		}
+		if e.Key == interfaces.KeyEscape && e.Action == interfaces.ActionPress {
+			return r.escapePopup()
+		}
+		return r.shortcut(e)
+	case interfaces.CharEvent, interfaces.PreeditEvent:
+		return r.focusEvent(ctx, ev)
+	}
+	if r.child != nil && r.childBox != nil && routeEvent(ctx, r.child, childBox(box, r.childBox), ev) {
+		return true
+	}
+	if e, ok := ev.(interfaces.FileDropEvent); ok && r.onFileDrop != nil {
+		r.onFileDrop(e.Paths, e.Position)
+		return true
 	}
+	return false
+}
 
-	// Render child
-	return r.child.Render(childCtx, childBox)
+// OnFileDrop sets the callback invoked with the paths of files dropped on the
+// window from other applications where no widget takes them, and returns the
+// root for chaining
+func (r *RootWidget) OnFileDrop(fn func(paths []string, at Point)) *RootWidget {
+	r.onFileDrop = fn
+	return r
+}
+
+// Dispatch delivers queued window events to the widget tree in order.
+// Events are hit tested against the boxes laid out in the previous frame.
+func (r *RootWidget) Dispatch(ctx *Context, box *Box, events []Event) {
+	ctx = r.themed(ctx)
+	defer traceRegion("dispatch", nil).End()
+	for _, ev := range events {
+		region := traceRegion("event", ev)
+		r.pacedEvent(ctx, box, ev)
+		region.End()
+	}
 }
 
 // OverlayWidget allows multiple widgets to be rendered on top of each other
 type OverlayWidget struct {
+	Base
 	children    []Widget
 	constraints Constraints
+	// boxes holds the child boxes from the last layout, relative to the overlay
+	boxes []Box
 }
 
 // Overlay creates a new overlay widget that renders children in sequence.
@@ -538,6 +1027,7 @@ func Overlay(constraints ...Constraints) *OverlayWidget {
 // Child adds a child widget to be rendered on top of previous children and returns the overlay for chaining
 func (o *OverlayWidget) Child(child Widget) *OverlayWidget {
 	o.children = append(o.children, child)
+	adopt(o, child)
 	return o
 }
 
@@ -546,24 +1036,38 @@ func (o *OverlayWidget) GetConstraints() Constraints {
 	return o.constraints
 }
 
-// Render implements the Widget interface for OverlayWidget
-func (o *OverlayWidget) Render(ctx *Context, box *Box) (usedSize Size, err error) {
+// Measure returns the size holding every child at the size it measures, at
+// least the overlay's minimum
+func (o *OverlayWidget) Measure(constraints Constraints) (size Size) {
+	for _, child := range o.children {
+		m := child.Measure(constraints)
+		size.Width, size.Height = max(size.Width, m.Width), max(size.Height, m.Height)
+	}
+	return atLeast(size, o.constraints)
+}
+
+// Layout implements the Widget interface for OverlayWidget
+func (o *OverlayWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !o.NeedsLayout(ctx, constraints) {
+		return o.CachedSize(), nil
+	}
 	var maxUsedSize Size
+	o.boxes = o.boxes[:0]
 
-	// Render all children in sequence (later children paint over earlier ones)
+	// Lay out all children over the same area
 	for _, child := range o.children {
 		// Get child constraints to determine positioning and sizing
 		childConstraints := child.GetConstraints()
 
 		// Create child box based on its constraints
-		childBox := &Box{
+		childBox := Box{
 			Position: Point{
-				X: box.Position.X + childConstraints.Left,
-				Y: box.Position.Y + childConstraints.Top,
+				X: childConstraints.Left,
+				Y: childConstraints.Top,
 			},
 			Size: Size{
-				Width:  box.Size.Width - childConstraints.Left,
-				Height: box.Size.Height - childConstraints.Top,
+				Width:  constraints.MaxWidth - childConstraints.Left,
+				Height: constraints.MaxHeight - childConstraints.Top,
 			},
 			Constraints: childConstraints,
 		}
@@ -596,15 +1100,9 @@ func (o *OverlayWidget) Render(ctx *Context, box *Box) (usedSize Size, err error
 			}
 		}
 
-		// Create child context
-		childCtx := &Context{
-			WindowWidth:   ctx.WindowWidth,
-			WindowHeight:  ctx.WindowHeight,
-			ParentBox:     childBox,
-			AvailableSize: childBox.Size,
-		}
+		o.boxes = append(o.boxes, childBox)
 
-		childUsedSize, err := child.Render(childCtx, childBox)
+		childUsedSize, err := child.Layout(ctx, NewRigidConstraints(childBox.Size.Width, childBox.Size.Height))
 		if chk.E(err) {
 			return Size{}, err
 		}
@@ -618,9 +1116,42 @@ func (o *OverlayWidget) Render(ctx *Context, box *Box) (usedSize Size, err error
 		}
 	}
 
+	o.SetLayout(ctx, constraints, maxUsedSize)
 	return maxUsedSize, nil
 }
 
+// Paint implements the Widget interface for OverlayWidget.
+// Children are painted in sequence so later children paint over earlier ones.
+func (o *OverlayWidget) Paint(ctx *Context, box *Box) (err error) {
+	for i := range o.boxes {
+		if i >= len(o.children) {
+			break
+		}
+		if err = paintChild(ctx, o.children[i], childBox(box, &o.boxes[i])); chk.E(err) {
+			return
+		}
+	}
+	return
+}
+
+// HandleEvent implements the Widget interface for OverlayWidget.
+// Later children paint over earlier ones so they receive events first.
+func (o *OverlayWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	_, targeted := interfaces.Target(ev)
+	for i := len(o.boxes) - 1; i >= 0; i-- {
+		if i >= len(o.children) {
+			continue
+		}
+		if routeEvent(ctx, o.children[i], childBox(box, &o.boxes[i]), ev) {
+			handled = true
+			if targeted {
+				return
+			}
+		}
+	}
+	return
+}
+
 // Gravity specifies how a widget should be positioned within its container
 type Gravity int
 
@@ -636,11 +1167,34 @@ const (
 	GravitySouthWest
 )
 
+// mirrored returns the gravity with east and west swapped, as in a layout
+// mirrored for a right to left language
+func (g Gravity) mirrored() Gravity {
+	switch g {
+	case GravityEast:
+		return GravityWest
+	case GravityWest:
+		return GravityEast
+	case GravityNorthEast:
+		return GravityNorthWest
+	case GravityNorthWest:
+		return GravityNorthEast
+	case GravitySouthEast:
+		return GravitySouthWest
+	case GravitySouthWest:
+		return GravitySouthEast
+	}
+	return g
+}
+
 // DirectionWidget positions a single child widget using gravity-based positioning
 type DirectionWidget struct {
+	Base
 	child       Widget
 	gravity     Gravity
 	constraints Constraints
+	// childBox is the child's box from the last layout, relative to the widget
+	childBox *Box
 }
 
 // NewDirectionWidget creates a new direction widget with the specified gravity.
@@ -652,11 +1206,13 @@ func NewDirectionWidget(child Widget, gravity Gravity, constraints ...Constraint
 	} else {
 		c = NewFlexConstraints(0, 0, 1e9, 1e9)
 	}
-	return &DirectionWidget{
+	d := &DirectionWidget{
 		child:       child,
 		gravity:     gravity,
 		constraints: c,
 	}
+	adopt(d, child)
+	return d
 }
 
 // Center creates a direction widget that centers its child.
@@ -670,8 +1226,14 @@ func (d *DirectionWidget) GetConstraints() Constraints {
 	return d.constraints
 }
 
+// Measure returns the size the child measures, at least the widget's minimum
+func (d *DirectionWidget) Measure(constraints Constraints) Size {
+	return atLeast(measure(d.child, constraints), d.constraints)
+}
+
 // FixedSize is a widget that constrains its child to a fixed size
 type FixedSize struct {
+	Base
 	width       float32
 	height      float32
 	child       Widget
@@ -680,12 +1242,14 @@ type FixedSize struct {
 
 // NewFixedSize creates a new FixedSize widget with the specified dimensions
 func NewFixedSize(width, height float32, child Widget) *FixedSize {
-	return &FixedSize{
+	f := &FixedSize{
 		width:       width,
 		height:      height,
 		child:       child,
 		constraints: NewRigidConstraints(width, height),
 	}
+	adopt(f, child)
+	return f
 }
 
 // GetConstraints returns the size constraints for this FixedSize widget
@@ -693,14 +1257,46 @@ func (f *FixedSize) GetConstraints() Constraints {
 	return f.constraints
 }
 
-// Render implements the Widget interface for FixedSize
-func (f *FixedSize) Render(ctx *Context, box *Box) (usedSize Size, err error) {
+// Measure returns the fixed size
+func (f *FixedSize) Measure(constraints Constraints) Size {
+	return Size{Width: f.width, Height: f.height}
+}
+
+// Layout implements the Widget interface for FixedSize
+func (f *FixedSize) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !f.NeedsLayout(ctx, constraints) {
+		return f.CachedSize(), nil
+	}
+	size = Size{Width: f.width, Height: f.height}
+	if f.child != nil {
+		// Lay out child with fixed size
+		if size, err = f.child.Layout(ctx, f.constraints); chk.E(err) {
+			return
+		}
+	}
+	f.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for FixedSize
+func (f *FixedSize) Paint(ctx *Context, box *Box) (err error) {
+	if f.child == nil {
+		return
+	}
+	return paintChild(ctx, f.child, f.childBox(box))
+}
+
+// HandleEvent implements the Widget interface for FixedSize
+func (f *FixedSize) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
 	if f.child == nil {
-		return box.Size, nil
+		return false
 	}
+	return routeEvent(ctx, f.child, f.childBox(box), ev)
+}
 
-	// Create child box with fixed size
-	childBox := &Box{
+// childBox returns the fixed size box of the child at the widget's position
+func (f *FixedSize) childBox(box *Box) *Box {
+	return &Box{
 		Position: box.Position,
 		Size: Size{
 			Width:  f.width,
@@ -708,35 +1304,30 @@ func (f *FixedSize) Render(ctx *Context, box *Box) (usedSize Size, err error) {
 		},
 		Constraints: f.child.GetConstraints(),
 	}
-
-	// Create child context
-	childCtx := &Context{
-		WindowWidth:    ctx.WindowWidth,
-		WindowHeight:   ctx.WindowHeight,
-		ParentBox:      childBox,
-		AvailableSize:  childBox.Size,
-		PaintedRegions: ctx.PaintedRegions,
-	}
-
-	// Render child
-	return f.child.Render(childCtx, childBox)
 }
 
-// Render implements the Widget interface for DirectionWidget
-func (d *DirectionWidget) Render(ctx *Context, box *Box) (usedSize Size, err error) {
+// Layout implements the Widget interface for DirectionWidget
+func (d *DirectionWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !d.NeedsLayout(ctx, constraints) {
+		return d.CachedSize(), nil
+	}
 	if d.child == nil {
-		return box.Size, nil
+		d.childBox = nil
+		size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+		d.SetLayout(ctx, constraints, size)
+		return
 	}
 
 	// Get child constraints
 	childConstraints := d.child.GetConstraints()
+	boxWidth, boxHeight := constraints.MaxWidth, constraints.MaxHeight
 
 	// Calculate child size (respecting rigid constraints)
 	var childWidth, childHeight float32
 	if childConstraints.MinWidth == childConstraints.MaxWidth {
 		childWidth = childConstraints.MinWidth
 	} else {
-		childWidth = box.Size.Width
+		childWidth = boxWidth
 		if childWidth > childConstraints.MaxWidth {
 			childWidth = childConstraints.MaxWidth
 		}
@@ -748,7 +1339,7 @@ func (d *DirectionWidget) Render(ctx *Context, box *Box) (usedSize Size, err err
 	if childConstraints.MinHeight == childConstraints.MaxHeight {
 		childHeight = childConstraints.MinHeight
 	} else {
-		childHeight = box.Size.Height
+		childHeight = boxHeight
 		if childHeight > childConstraints.MaxHeight {
 			childHeight = childConstraints.MaxHeight
 		}
@@ -757,40 +1348,44 @@ func (d *DirectionWidget) Render(ctx *Context, box *Box) (usedSize Size, err err
 		}
 	}
 
-	// Calculate position based on gravity
+	// Calculate position based on gravity, relative to the widget
 	var childX, childY float32
-	switch d.gravity {
+	gravity := d.gravity
+	if ctx.RTL {
+		gravity = gravity.mirrored()
+	}
+	switch gravity {
 	case GravityCenter:
-		childX = box.Position.X + (box.Size.Width-childWidth)/2
-		childY = box.Position.Y + (box.Size.Height-childHeight)/2
+		childX = (boxWidth - childWidth) / 2
+		childY = (boxHeight - childHeight) / 2
 	case GravityNorth:
-		childX = box.Position.X + (box.Size.Width-childWidth)/2
-		childY = box.Position.Y
+		childX = (boxWidth - childWidth) / 2
+		childY = 0
 	case GravitySouth:
-		childX = box.Position.X + (box.Size.Width-childWidth)/2
-		childY = box.Position.Y + box.Size.Height - childHeight
+		childX = (boxWidth - childWidth) / 2
+		childY = boxHeight - childHeight
 	case GravityEast:
-		childX = box.Position.X + box.Size.Width - childWidth
-		childY = box.Position.Y + (box.Size.Height-childHeight)/2
+		childX = boxWidth - childWidth
+		childY = (boxHeight - childHeight) / 2
 	case GravityWest:
-		childX = box.Position.X
-		childY = box.Position.Y + (box.Size.Height-childHeight)/2
+		childX = 0
+		childY = (boxHeight - childHeight) / 2
 	case GravityNorthEast:
-		childX = box.Position.X + box.Size.Width - childWidth
-		childY = box.Position.Y
+		childX = boxWidth - childWidth
+		childY = 0
 	case GravityNorthWest:
-		childX = box.Position.X
-		childY = box.Position.Y
+		childX = 0
+		childY = 0
 	case GravitySouthEast:
-		childX = box.Position.X + box.Size.Width - childWidth
-		childY = box.Position.Y + box.Size.Height - childHeight
+		childX = boxWidth - childWidth
+		childY = boxHeight - childHeight
 	case GravitySouthWest:
-		childX = box.Position.X
-		childY = box.Position.Y + box.Size.Height - childHeight
+		childX = 0
+		childY = boxHeight - childHeight
 	}
 
 	// Create child box
-	childBox := &Box{
+	d.childBox = &Box{
 		Position: Point{
 			X: childX,
 			Y: childY,
@@ -802,14 +1397,87 @@ func (d *DirectionWidget) Render(ctx *Context, box *Box) (usedSize Size, err err
 		Constraints: childConstraints,
 	}
 
-	// Create child context
-	childCtx := &Context{
+	// Lay out child
+	if size, err = d.child.Layout(ctx, NewRigidConstraints(childWidth, childHeight)); chk.E(err) {
+		return
+	}
+	d.SetLayout(ctx, constraints, size)
+	return
+}
+
+// Paint implements the Widget interface for DirectionWidget
+func (d *DirectionWidget) Paint(ctx *Context, box *Box) (err error) {
+	if d.child == nil || d.childBox == nil {
+		return
+	}
+	return paintChild(ctx, d.child, childBox(box, d.childBox))
+}
+
+// HandleEvent implements the Widget interface for DirectionWidget
+func (d *DirectionWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	if d.child == nil || d.childBox == nil {
+		return false
+	}
+	return routeEvent(ctx, d.child, childBox(box, d.childBox), ev)
+}
+
+// paintChild paints a child widget in its absolute box, skipping it when the
+// box, or the region it paints in for widgets painting outside their box,
+// lies outside the region being repainted
+func paintChild(ctx *Context, child Widget, box *Box) (err error) {
+	if t, ok := child.(paintTracker); ok {
+		t.setPaintBox(box)
+	}
+	bounds := box.Rect()
+	if o, ok := child.(overflowing); ok {
+		bounds = o.paintBounds(box)
+	}
+	if !ctx.Clip.Empty() && !ctx.Clip.Overlaps(bounds) {
+		return
+	}
+	// Register the child's box before it paints, so its descendants and any
+	// regions it registers itself lie over it
+	ctx.Hits.Register(child, box.Rect())
+	ctx.Profile.Begin(child, box.Rect(), ctx.DrawList)
+	region := traceRegion("paint", child)
+	err = child.Paint(childContext(ctx, box), box)
+	region.End()
+	ctx.Profile.End()
+	return
+}
+
+// routeEvent delivers an event to a child laid out in the given box.
+// Targeted events are only delivered when the box contains their position.
+func routeEvent(ctx *Context, child Widget, box *Box, ev Event) (handled bool) {
+	if at, targeted := interfaces.Target(ev); targeted && !box.Contains(at) {
+		return false
+	}
+	return child.HandleEvent(childContext(ctx, box), box, ev)
+}
+
+// childContext derives the context for a child laid out in the given box
+func childContext(ctx *Context, box *Box) *Context {
+	return &Context{
 		WindowWidth:   ctx.WindowWidth,
 		WindowHeight:  ctx.WindowHeight,
-		ParentBox:     childBox,
-		AvailableSize: childBox.Size,
+		ParentBox:     box,
+		AvailableSize: box.Size,
+		Clip:          ctx.Clip,
+		DrawList:      ctx.DrawList,
+		Clipboard:     ctx.Clipboard,
+		Clock:         ctx.Clock,
+		Theme:         ctx.Theme,
+		Hits:          ctx.Hits,
+		Stats:         ctx.Stats,
+		Profile:       ctx.Profile,
+		Scale:         ctx.Scale,
+		InputMethod:   ctx.InputMethod,
+		RTL:           ctx.RTL,
+		LayoutEpoch:   ctx.LayoutEpoch,
 	}
+}
 
-	// Render child
-	return d.child.Render(childCtx, childBox)
+// frameTime returns the time of the frame being handled
+func frameTime(ctx *Context) time.Time {
+	return ctx.Clock.Now()
 }
@@ -0,0 +1,289 @@
+package widget
+
+import (
+	"slices"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"lol.mleku.dev/chk"
+)
+
+// WrapAlignment places the children of a wrap widget along their line, or
+// within their line across it
+type WrapAlignment int
+
+const (
+	WrapStart WrapAlignment = iota
+	WrapCenter
+	WrapEnd
+	// WrapSpaceBetween spreads the children of a line to fill it, with the
+	// first and last at its ends
+	WrapSpaceBetween
+	// WrapSpaceAround spreads the children of a line to fill it, with half
+	// the space between them at its ends
+	WrapSpaceAround
+)
+
+// wrapLine is a line of a wrap widget's children
+type wrapLine struct {
+	// first and last index the line's children, last exclusive
+	first, last   int
+	width, height float32
+}
+
+// WrapWidget lays out its children left to right at the sizes they measure,
+// starting a new line below when the next child does not fit, as tags,
+// chips and toolbar buttons flow. Its height depends on its width, so it
+// reports the height of the lines it was last laid out in; when a new width
+// changes the height its parent is laid out again on the next frame.
+type WrapWidget struct {
+	Base
+	children    []Widget
+	constraints Constraints
+	// spacing is the space between children along a line, and lineSpacing
+	// between lines
+	spacing, lineSpacing float32
+	align, crossAlign    WrapAlignment
+	// boxes holds the child boxes from the last layout, relative to the widget
+	boxes []Box
+	// height is the height of the lines from the last layout, and stale is
+	// set when it changed since the parent read it
+	height float32
+	stale  bool
+}
+
+// Wrap creates a new wrap widget with no children.
+// If no constraints are provided, uses default flexible constraints (0, 0, 1e9, 1e9).
+func Wrap(constraints ...Constraints) *WrapWidget {
+	var c Constraints
+	if len(constraints) > 0 {
+		c = constraints[0]
+	} else {
+		c = NewFlexConstraints(0, 0, 1e9, 1e9)
+	}
+	return &WrapWidget{constraints: c, height: -1}
+}
+
+// Child adds a child after the others and returns the widget for chaining
+func (w *WrapWidget) Child(child Widget) *WrapWidget {
+	w.children = append(w.children, child)
+	adopt(w, child)
+	return w
+}
+
+// Insert adds a child before the one at index, after the others when index
+// is out of range, and returns the widget for chaining
+func (w *WrapWidget) Insert(index int, child Widget) *WrapWidget {
+	if index < 0 || index > len(w.children) {
+		index = len(w.children)
+	}
+	w.children = slices.Insert(w.children, index, child)
+	adopt(w, child)
+	return w
+}
+
+// Remove takes a child out of the widget
+func (w *WrapWidget) Remove(child Widget) {
+	if i := slices.Index(w.children, child); i >= 0 {
+		w.children = slices.Delete(w.children, i, i+1)
+		w.MarkNeedsLayout()
+	}
+}
+
+// Spacing sets the space between children along a line and between lines,
+// and returns the widget for chaining
+func (w *WrapWidget) Spacing(spacing, lineSpacing float32) *WrapWidget {
+	w.spacing, w.lineSpacing = spacing, lineSpacing
+	w.MarkNeedsLayout()
+	return w
+}
+
+// Align sets how the children of each line are placed along it and returns
+// the widget for chaining
+func (w *WrapWidget) Align(align WrapAlignment) *WrapWidget {
+	w.align = align
+	w.MarkNeedsLayout()
+	return w
+}
+
+// CrossAlign sets how children shorter than their line are placed within
+// it, at its top, centre or bottom, and returns the widget for chaining. The
+// spacing alignments place them at the top.
+func (w *WrapWidget) CrossAlign(align WrapAlignment) *WrapWidget {
+	w.crossAlign = align
+	w.MarkNeedsLayout()
+	return w
+}
+
+// GetConstraints returns the widget's constraints, at least as wide as the
+// widest child measures and as tall as its lines were when it was last laid out, or
+// as one line before that
+func (w *WrapWidget) GetConstraints() Constraints {
+	c := w.constraints
+	var height float32
+	for _, child := range w.children {
+		m := child.Measure(NewFlexConstraints(0, 0, 1e9, 1e9))
+		c.MinWidth = max(c.MinWidth, m.Width)
+		height = max(height, m.Height)
+	}
+	if w.height >= 0 {
+		height = w.height
+	}
+	c.MinHeight = max(c.MinHeight, height)
+	c.MaxWidth, c.MaxHeight = max(c.MaxWidth, c.MinWidth), max(c.MaxHeight, c.MinHeight)
+	return c
+}
+
+// Measure returns the size of the lines the children break into within the
+// maximum width, as wide as the longest
+func (w *WrapWidget) Measure(constraints Constraints) (size Size) {
+	_, lines := w.breakLines(constraints.MaxWidth)
+	for n, l := range lines {
+		if n > 0 {
+			size.Height += w.lineSpacing
+		}
+		size.Width = max(size.Width, l.width)
+		size.Height += l.height
+	}
+	return atLeast(size, w.constraints)
+}
+
+// breakLines returns the sizes the children measure, no wider than the
+// available width, and the lines they break into
+func (w *WrapWidget) breakLines(available float32) (sizes []Size, lines []wrapLine) {
+	sizes = make([]Size, len(w.children))
+	line := wrapLine{}
+	for i, child := range w.children {
+		s := child.Measure(NewFlexConstraints(0, 0, available, 1e9))
+		s.Width = min(s.Width, available)
+		sizes[i] = s
+		if i > line.first && line.width+w.spacing+s.Width > available {
+			lines = append(lines, line)
+			line = wrapLine{first: i}
+		}
+		if i > line.first {
+			line.width += w.spacing
+		}
+		line.width += s.Width
+		line.height = max(line.height, s.Height)
+		line.last = i + 1
+	}
+	if line.last > line.first {
+		lines = append(lines, line)
+	}
+	return
+}
+
+// Layout implements the Widget interface for WrapWidget. The widget is as
+// wide as it is allowed, or as its longest line when that is unbounded.
+func (w *WrapWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !w.NeedsLayout(ctx, constraints) {
+		return w.CachedSize(), nil
+	}
+	available := constraints.MaxWidth
+	bounded := available < 1e9
+	sizes, lines := w.breakLines(available)
+
+	for _, l := range lines {
+		size.Width = max(size.Width, l.width)
+	}
+	if bounded {
+		size.Width = available
+	}
+
+	// Place the children of each line
+	w.boxes = w.boxes[:0]
+	var y float32
+	for n, l := range lines {
+		if n > 0 {
+			y += w.lineSpacing
+		}
+		x, gap := w.spread(size.Width-l.width, l.last-l.first)
+		for i := l.first; i < l.last; i++ {
+			s := sizes[i]
+			var dy float32
+			switch w.crossAlign {
+			case WrapCenter:
+				dy = (l.height - s.Height) / 2
+			case WrapEnd:
+				dy = l.height - s.Height
+			}
+			w.boxes = append(w.boxes, Box{
+				Position:    Point{X: x, Y: y + dy},
+				Size:        s,
+				Constraints: w.children[i].GetConstraints(),
+			})
+			if _, err = w.children[i].Layout(ctx, NewRigidConstraints(s.Width, s.Height)); chk.E(err) {
+				return
+			}
+			x += s.Width + w.spacing + gap
+		}
+		y += l.height
+	}
+	size.Height = max(y, constraints.MinHeight)
+
+	if y != w.height {
+		w.height = y
+		w.stale = true
+	}
+	w.SetLayout(ctx, constraints, size)
+	return
+}
+
+// spread returns where the first child of a line starts and the space added
+// between its children, given the space left over on the line
+func (w *WrapWidget) spread(free float32, count int) (start, gap float32) {
+	free = max(free, 0)
+	switch w.align {
+	case WrapCenter:
+		start = free / 2
+	case WrapEnd:
+		start = free
+	case WrapSpaceBetween:
+		if count > 1 {
+			gap = free / float32(count-1)
+		} else {
+			start = free / 2
+		}
+	case WrapSpaceAround:
+		gap = free / float32(count)
+		start = gap / 2
+	}
+	return
+}
+
+// Paint implements the Widget interface for WrapWidget
+func (w *WrapWidget) Paint(ctx *Context, box *Box) (err error) {
+	if w.stale {
+		// The parent read the height of the lines before they were laid out
+		// at this width
+		w.stale = false
+		w.MarkNeedsLayout()
+		ctx.Clock.Request()
+	}
+	for i := range w.boxes {
+		if i >= len(w.children) {
+			break
+		}
+		if err = paintChild(ctx, w.children[i], childBox(box, &w.boxes[i])); chk.E(err) {
+			return
+		}
+	}
+	return
+}
+
+// HandleEvent implements the Widget interface for WrapWidget
+func (w *WrapWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	_, targeted := interfaces.Target(ev)
+	for i := len(w.boxes) - 1; i >= 0; i-- {
+		if i >= len(w.children) {
+			continue
+		}
+		if routeEvent(ctx, w.children[i], childBox(box, &w.boxes[i]), ev) {
+			handled = true
+			if targeted {
+				return
+			}
+		}
+	}
+	return
+}
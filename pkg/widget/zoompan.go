@@ -0,0 +1,412 @@
+package widget
+
+import (
+	"math"
+	"slices"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/render"
+	"lol.mleku.dev/chk"
+)
+
+// zoomPanWheel is how much one notch of the wheel zooms by
+const zoomPanWheel = 1.2
+
+// ZoomPanWidget shows its child scaled and moved within its box, for
+// diagrams, maps and image viewers. The wheel zooms about the cursor,
+// dragging with the middle button pans, and pinching with two touches zooms
+// about and pans with the point between them. The child is laid out at the
+// size it measures, filling the box along axes where it measures nothing,
+// and pointer input is mapped back to where the child is drawn.
+type ZoomPanWidget struct {
+	Base
+	child Widget
+	// content is the size set for the child, zero along axes where it is
+	// laid out at the size it measures
+	content Size
+	// zoom is the scale the child is drawn at and offset where its top left
+	// corner is drawn relative to the box
+	zoom     float32
+	offset   Point
+	min, max float32
+	// viewport is the size of the box and laidOut the size of the child as
+	// last laid out; fit is set while zooming to fit waits for a layout
+	viewport, laidOut Size
+	fit               bool
+	// panning is set while the middle button drags the view, grabbed at
+	// grab with the offset grabOffset
+	panning    bool
+	grab       Point
+	grabOffset Point
+	// touches are those held on the box, pinching being set from when two
+	// of them start to zoom until all are lifted. The first two zoom from
+	// pinchZoom and pinchOffset about their midpoint, span apart when the
+	// pinch started.
+	touches     []gesturePointer
+	pinching    bool
+	pinchZoom   float32
+	pinchOffset Point
+	pinchCenter Point
+	span        float32
+	// applied is the transform the child was last painted with
+	applied  render.Matrix
+	onChange func()
+}
+
+// ZoomPan creates a new zoomable and pannable view of the child at a zoom
+// of one, which zooms between a tenth and ten times
+func ZoomPan(child Widget) *ZoomPanWidget {
+	z := &ZoomPanWidget{
+		child:   child,
+		zoom:    1,
+		min:     0.1,
+		max:     10,
+		applied: render.Identity(),
+	}
+	adopt(z, child)
+	return z
+}
+
+// ContentSize sets the size the child is laid out at, in place of the size
+// it measures, and returns the view for chaining
+func (z *ZoomPanWidget) ContentSize(width, height float32) *ZoomPanWidget {
+	z.content = Size{Width: width, Height: height}
+	z.MarkNeedsLayout()
+	return z
+}
+
+// Limits sets the smallest and largest zoom and returns the view for chaining
+func (z *ZoomPanWidget) Limits(minimum, maximum float32) *ZoomPanWidget {
+	z.min, z.max = minimum, max(maximum, minimum)
+	z.setView(z.zoom, z.offset)
+	return z
+}
+
+// OnChange sets the callback invoked when the user zooms or pans, and
+// returns the view for chaining
+func (z *ZoomPanWidget) OnChange(fn func()) *ZoomPanWidget {
+	z.onChange = fn
+	return z
+}
+
+// Zoom returns the scale the child is drawn at
+func (z *ZoomPanWidget) Zoom() float32 {
+	return z.zoom
+}
+
+// Offset returns where the child's top left corner is drawn relative to the
+// top left corner of the view
+func (z *ZoomPanWidget) Offset() Point {
+	return z.offset
+}
+
+// SetView draws the child at a zoom, kept within the limits, with its top
+// left corner at an offset from the top left corner of the view
+func (z *ZoomPanWidget) SetView(zoom float32, offset Point) {
+	z.fit = false
+	z.setView(zoom, offset)
+}
+
+// ZoomAt changes the zoom keeping the point of the child under a point of
+// the view, relative to its top left corner, in place
+func (z *ZoomPanWidget) ZoomAt(zoom float32, at Point) {
+	z.fit = false
+	z.zoomAt(zoom, at)
+}
+
+// PanBy moves the child across the view
+func (z *ZoomPanWidget) PanBy(dx, dy float32) {
+	z.SetView(z.zoom, Point{X: z.offset.X + dx, Y: z.offset.Y + dy})
+}
+
+// ZoomToFit zooms to show the whole child in the middle of the view, once
+// the view is laid out when it is not yet
+func (z *ZoomPanWidget) ZoomToFit() {
+	if z.viewport.Width <= 0 || z.viewport.Height <= 0 || z.laidOut.Width <= 0 || z.laidOut.Height <= 0 {
+		z.fit = true
+		z.MarkNeedsLayout()
+		return
+	}
+	z.fit = false
+	zoom := z.clampZoom(min(z.viewport.Width/z.laidOut.Width, z.viewport.Height/z.laidOut.Height))
+	z.setView(zoom, Point{
+		X: (z.viewport.Width - z.laidOut.Width*zoom) / 2,
+		Y: (z.viewport.Height - z.laidOut.Height*zoom) / 2,
+	})
+}
+
+// ToContent returns the point of the child under a point of the view,
+// both relative to their top left corners
+func (z *ZoomPanWidget) ToContent(at Point) Point {
+	return Point{X: (at.X - z.offset.X) / z.zoom, Y: (at.Y - z.offset.Y) / z.zoom}
+}
+
+// GetConstraints returns flexible constraints; views take the space given
+func (z *ZoomPanWidget) GetConstraints() Constraints {
+	return NewFlexConstraints(0, 0, 1e9, 1e9)
+}
+
+// Measure returns the minimum size
+func (z *ZoomPanWidget) Measure(constraints Constraints) Size {
+	return minSize(z.GetConstraints())
+}
+
+// Layout implements the Widget interface for ZoomPanWidget; views take all
+// the space offered and lay the child out at its own size
+func (z *ZoomPanWidget) Layout(ctx *Context, constraints Constraints) (size Size, err error) {
+	if !z.NeedsLayout(ctx, constraints) {
+		return z.CachedSize(), nil
+	}
+	size = Size{Width: constraints.MaxWidth, Height: constraints.MaxHeight}
+	z.SetLayout(ctx, constraints, size)
+	z.viewport = size
+	if z.child == nil {
+		return
+	}
+	content := z.content
+	if content.Width <= 0 || content.Height <= 0 {
+		measured := atLeast(measure(z.child, NewFlexConstraints(0, 0, 1e9, 1e9)), z.child.GetConstraints())
+		if content.Width <= 0 {
+			content.Width = measured.Width
+		}
+		if content.Height <= 0 {
+			content.Height = measured.Height
+		}
+	}
+	if content.Width <= 0 {
+		content.Width = size.Width
+	}
+	if content.Height <= 0 {
+		content.Height = size.Height
+	}
+	z.laidOut = content
+	if _, err = z.child.Layout(ctx, NewRigidConstraints(content.Width, content.Height)); chk.E(err) {
+		return
+	}
+	if z.fit {
+		z.ZoomToFit()
+	}
+	return
+}
+
+// Paint implements the Widget interface for ZoomPanWidget
+func (z *ZoomPanWidget) Paint(ctx *Context, box *Box) (err error) {
+	z.applied = z.view(box)
+	inverse, ok := z.applied.Invert()
+	if z.child == nil || !ok {
+		return
+	}
+	clip := box.Rect()
+	if !ctx.Clip.Empty() {
+		clip = clip.Intersect(ctx.Clip)
+	}
+	if clip.Empty() {
+		return
+	}
+	// Skip descendants outside the part of the region that maps onto them
+	clipped := *ctx
+	clipped.Clip = transformRect(inverse, clip)
+	list := ctx.DrawList
+	list.PushClip(box.Position.X, box.Position.Y, box.Size.Width, box.Size.Height)
+	ctx.Hits.PushClip(box.Rect())
+	list.PushTransform(z.applied)
+	ctx.Hits.PushTransform(z.applied)
+	err = paintChild(&clipped, z.child, z.contentBox(box))
+	ctx.Hits.PopTransform()
+	list.PopTransform()
+	ctx.Hits.PopClip()
+	list.PopClip()
+	return
+}
+
+// HandleEvent implements the Widget interface for ZoomPanWidget
+func (z *ZoomPanWidget) HandleEvent(ctx *Context, box *Box, ev Event) (handled bool) {
+	switch e := ev.(type) {
+	case interfaces.ScrollEvent:
+		if e.Offset.Y == 0 || !box.Contains(e.Position) {
+			break
+		}
+		zoom := z.zoom * float32(math.Pow(zoomPanWheel, float64(e.Offset.Y)))
+		z.fit = false
+		z.zoomAt(zoom, z.local(box, e.Position))
+		return true
+	case interfaces.MouseButtonEvent:
+		if e.Button != interfaces.MouseButtonMiddle {
+			break
+		}
+		if e.Action == interfaces.ActionPress && box.Contains(e.Position) {
+			z.panning, z.grab, z.grabOffset = true, e.Position, z.offset
+			return true
+		}
+		if e.Action == interfaces.ActionRelease && z.panning {
+			z.panning = false
+			return true
+		}
+	case interfaces.MouseMoveEvent:
+		if z.panning {
+			z.fit = false
+			z.setView(z.zoom, Point{
+				X: z.grabOffset.X + e.Position.X - z.grab.X,
+				Y: z.grabOffset.Y + e.Position.Y - z.grab.Y,
+			})
+			return true
+		}
+	case interfaces.TouchEvent:
+		if z.touch(ctx, box, e) {
+			return true
+		}
+	}
+	return z.route(ctx, box, ev)
+}
+
+// touch follows the touches held on the view, pinching between the first
+// two, and reports whether the touch was taken by the pinch
+func (z *ZoomPanWidget) touch(ctx *Context, box *Box, e interfaces.TouchEvent) bool {
+	i := slices.IndexFunc(z.touches, func(p gesturePointer) bool { return p.id == e.ID })
+	switch e.Phase {
+	case interfaces.TouchBegin:
+		if i >= 0 || !box.Contains(e.Position) {
+			return i >= 0
+		}
+		z.touches = append(z.touches, gesturePointer{id: e.ID, start: e.Position, position: e.Position})
+		if len(z.touches) != 2 {
+			return z.pinching
+		}
+		// Take the first touch back from the child to pinch with it
+		if !z.pinching {
+			first := z.touches[0]
+			z.route(ctx, box, interfaces.TouchEvent{ID: first.id, Phase: interfaces.TouchCancel, Position: first.position})
+		}
+		z.pinch(box)
+		return true
+	case interfaces.TouchMove:
+		if i < 0 {
+			return false
+		}
+		z.touches[i].position = e.Position
+		// A touch left over from a pinch moves nothing on its own
+		if !z.pinching || i > 1 || len(z.touches) < 2 {
+			return z.pinching
+		}
+		a, b := z.touches[0].position, z.touches[1].position
+		zoom := z.clampZoom(z.pinchZoom * distance(a, b) / z.span)
+		// Keep the point of the child that was between the touches there
+		content := Point{
+			X: (z.pinchCenter.X - z.pinchOffset.X) / z.pinchZoom,
+			Y: (z.pinchCenter.Y - z.pinchOffset.Y) / z.pinchZoom,
+		}
+		center := z.local(box, midpoint(a, b))
+		z.setView(zoom, Point{X: center.X - content.X*zoom, Y: center.Y - content.Y*zoom})
+		return true
+	case interfaces.TouchEnd, interfaces.TouchCancel:
+		if i < 0 {
+			return false
+		}
+		z.touches = slices.Delete(z.touches, i, i+1)
+		pinching := z.pinching
+		switch {
+		case len(z.touches) == 0:
+			z.pinching = false
+		case pinching && i < 2 && len(z.touches) >= 2:
+			// Carry on with the touches now first, from the view as it is
+			z.pinch(box)
+		}
+		return pinching
+	}
+	return false
+}
+
+// pinch starts zooming about the midpoint of the first two touches from the
+// view as it is
+func (z *ZoomPanWidget) pinch(box *Box) {
+	a, b := z.touches[0].position, z.touches[1].position
+	z.pinching, z.fit = true, false
+	z.pinchZoom, z.pinchOffset = z.zoom, z.offset
+	z.pinchCenter = z.local(box, midpoint(a, b))
+	z.span = max(distance(a, b), 1)
+}
+
+// route passes an event to the child, with pointer positions mapped to
+// where the child is drawn
+func (z *ZoomPanWidget) route(ctx *Context, box *Box, ev Event) bool {
+	inverse, ok := z.view(box).Invert()
+	if z.child == nil || !ok {
+		return false
+	}
+	if at, targeted := interfaces.Target(ev); targeted && !box.Contains(at) {
+		return false
+	}
+	switch e := ev.(type) {
+	case interfaces.MouseMoveEvent:
+		e.Position = transformPoint(inverse, e.Position)
+		ev = e
+	case interfaces.MouseButtonEvent:
+		e.Position = transformPoint(inverse, e.Position)
+		ev = e
+	case interfaces.ScrollEvent:
+		e.Position = transformPoint(inverse, e.Position)
+		ev = e
+	case interfaces.TouchEvent:
+		e.Position = transformPoint(inverse, e.Position)
+		ev = e
+	}
+	return routeEvent(ctx, z.child, z.contentBox(box), ev)
+}
+
+// addDamage implements damageSink, mapping regions the child's subtree
+// invalidates to where they are drawn in the box before passing them on
+func (z *ZoomPanWidget) addDamage(rect Rect) {
+	if r := transformRect(z.applied, rect).Intersect(z.paintBox.Rect()); !r.Empty() {
+		z.invalidate(r)
+	}
+}
+
+// setView changes the zoom and offset, repainting and notifying when they
+// changed
+func (z *ZoomPanWidget) setView(zoom float32, offset Point) {
+	zoom = z.clampZoom(zoom)
+	if zoom == z.zoom && offset == z.offset {
+		return
+	}
+	z.zoom, z.offset = zoom, offset
+	z.MarkNeedsPaint()
+	if z.onChange != nil {
+		z.onChange()
+	}
+}
+
+// zoomAt changes the zoom keeping the point of the child under a point of
+// the view in place
+func (z *ZoomPanWidget) zoomAt(zoom float32, at Point) {
+	zoom = z.clampZoom(zoom)
+	content := z.ToContent(at)
+	z.setView(zoom, Point{X: at.X - content.X*zoom, Y: at.Y - content.Y*zoom})
+}
+
+// clampZoom keeps a zoom within the limits
+func (z *ZoomPanWidget) clampZoom(zoom float32) float32 {
+	return min(max(zoom, z.min), z.max)
+}
+
+// view returns the transform drawing the child laid out at the top left
+// corner of the box where it is shown
+func (z *ZoomPanWidget) view(box *Box) render.Matrix {
+	x, y := box.Position.X, box.Position.Y
+	return render.Translate(-x, -y).Then(render.Scale(z.zoom, z.zoom)).Then(render.Translate(x+z.offset.X, y+z.offset.Y))
+}
+
+// contentBox returns the box the child is laid out in, before the view's
+// transform moves it
+func (z *ZoomPanWidget) contentBox(box *Box) *Box {
+	return NewBox(box.Position.X, box.Position.Y, z.laidOut.Width, z.laidOut.Height, z.child.GetConstraints())
+}
+
+// local returns a point relative to the view
+func (z *ZoomPanWidget) local(box *Box, at Point) Point {
+	return Point{X: at.X - box.Position.X, Y: at.Y - box.Position.Y}
+}
+
+// midpoint returns the point halfway between two others
+func midpoint(a, b Point) Point {
+	return Point{X: (a.X + b.X) / 2, Y: (a.Y + b.Y) / 2}
+}
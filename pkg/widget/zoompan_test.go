@@ -0,0 +1,42 @@
+package widget_test
+
+import (
+	"testing"
+
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/widget"
+)
+
+func TestZoomPanPinch(t *testing.T) {
+	z := widget.ZoomPan(widget.Column()).ContentSize(300, 300).Limits(0.1, 10)
+	h := newHarness(t, z, 300, 300)
+	touch := func(id int, phase interfaces.TouchPhase, x float32) {
+		h.send(interfaces.TouchEvent{ID: id, Phase: phase, Position: interfaces.Point{X: x, Y: 150}})
+	}
+	start := z.Zoom()
+
+	// Spreading the touches from 100 to 150 apart zooms in by half
+	touch(1, interfaces.TouchBegin, 100)
+	touch(2, interfaces.TouchBegin, 200)
+	touch(2, interfaces.TouchMove, 250)
+	pinched := z.Zoom()
+	if want := start * 1.5; pinched < want-1e-3 || pinched > want+1e-3 {
+		t.Fatalf("pinched zoom %g, want %g", pinched, want)
+	}
+
+	// The touch left after lifting one moves nothing
+	touch(1, interfaces.TouchEnd, 100)
+	touch(2, interfaces.TouchMove, 280)
+	if got := z.Zoom(); got != pinched {
+		t.Errorf("zoom %g after moving the touch left over, want %g", got, pinched)
+	}
+
+	// Putting a finger back down pinches again from the view as it is
+	touch(3, interfaces.TouchBegin, 180)
+	touch(3, interfaces.TouchMove, 80)
+	if want := pinched * 2; z.Zoom() < want-1e-3 || z.Zoom() > want+1e-3 {
+		t.Errorf("zoom %g after pinching again, want %g", z.Zoom(), want)
+	}
+	touch(2, interfaces.TouchEnd, 280)
+	touch(3, interfaces.TouchEnd, 80)
+}
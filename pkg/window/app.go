@@ -0,0 +1,298 @@
+//go:build !js
+
+package window
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-gl/gl/all-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/mleku/goo/pkg/clipboard"
+	"lol.mleku.dev/chk"
+)
+
+// App runs the main loop of one or more windows, each with its own render
+// function. The windows' GL contexts share objects with a hidden context
+// that lives as long as the app, so textures such as font atlases and images
+// are uploaded once and drawn in every window. Windows may be opened before
+// Run or from a render function while it runs; the loop ends when the last
+// window closes.
+type App struct {
+	// share is the hidden window owning the context the windows share with
+	share   *glfw.Window
+	windows []*Window
+	running bool
+	// gamepads are read each pass of the loop, more often while one is
+	// connected, and their input goes to the window with keyboard focus
+	gamepads         gamepads
+	gamepadConnected bool
+	// clipboard is the system clipboard the windows share
+	clipboard *clipboard.Clipboard
+	// software is set when the system offers only OpenGL older than 3.3
+	// core, so every window is drawn by the software canvas
+	software bool
+	// backend is the display system required by the first window opened
+	backend Backend
+}
+
+// NewApp creates a new app with no windows
+func NewApp() *App {
+	return &App{}
+}
+
+// Open shows a window, drawn by the render function each frame until it is
+// closed by the user or by Stop. It must be called from the main goroutine.
+func (a *App) Open(w *Window, renderFunc RenderFunc) (err error) {
+	if w.app != nil {
+		return
+	}
+	if a.share == nil {
+		a.backend = w.backend
+	}
+	if err = a.init(); chk.E(err) {
+		return
+	}
+	// Leave the context of a window whose render function opened this one current
+	if current := glfw.GetCurrentContext(); current != nil {
+		defer current.MakeContextCurrent()
+	}
+	if a.software {
+		w.software = true
+	}
+	if err = w.open(a.share, renderFunc); chk.E(err) {
+		return
+	}
+	w.app = a
+	a.windows = append(a.windows, w)
+	return
+}
+
+// Clipboard returns the system clipboard the app's windows share, nil until
+// the first window is opened
+func (a *App) Clipboard() *clipboard.Clipboard {
+	return a.clipboard
+}
+
+// clipboardChanged checks the clipboard for changes made by other programs
+// when a window gains focus, drawing the windows again when it changed so
+// widgets following it update
+func (a *App) clipboardChanged() {
+	if !a.clipboard.Check() {
+		return
+	}
+	for _, w := range a.windows {
+		w.clock.Request()
+	}
+}
+
+// Windows returns the open windows in the order they were opened
+func (a *App) Windows() []*Window {
+	return slices.Clone(a.windows)
+}
+
+// Run draws the open windows until all of them have closed or Stop is
+// called, then releases the shared resources. An error returned by a render
+// function closes every window and is returned.
+func (a *App) Run() (err error) {
+	if err = a.init(); chk.E(err) {
+		return
+	}
+	defer a.terminate()
+	a.running = true
+	for a.running && len(a.windows) > 0 {
+		a.pollGamepads()
+		// Render functions may open windows, which are drawn from the next pass
+		for _, w := range slices.Clone(a.windows) {
+			if w.window.ShouldClose() || !w.running {
+				a.close(w)
+				continue
+			}
+			now := time.Now()
+			if !w.due(now) {
+				continue
+			}
+			if err = w.draw(now); chk.E(err) {
+				return
+			}
+		}
+		if len(a.windows) == 0 {
+			break
+		}
+		a.wait()
+	}
+	return
+}
+
+// Shutdown asks each window whether it may close, as though the user
+// closed them all, and when none refuses stops the app, reporting whether it
+// did. The windows then close in turn, each running its close callback with
+// its context current before its resources are freed, and the shared
+// context and GLFW are released as Run returns.
+func (a *App) Shutdown() (ok bool) {
+	for _, w := range a.windows {
+		if !w.mayClose() {
+			return false
+		}
+	}
+	a.Stop()
+	return true
+}
+
+// Stop closes every window without asking, ending the main loop
+func (a *App) Stop() {
+	a.running = false
+	postEmptyEvent()
+}
+
+// glfwLive is set while GLFW is initialized, guarding the calls other
+// goroutines make into it from it terminating
+var glfwLive struct {
+	sync.RWMutex
+	live bool
+}
+
+// setGLFWLive records whether GLFW is initialized, waiting for the calls
+// other goroutines are making into it
+func setGLFWLive(live bool) {
+	glfwLive.Lock()
+	glfwLive.live = live
+	glfwLive.Unlock()
+}
+
+// postEmptyEvent wakes the main loop from any goroutine, doing nothing
+// while GLFW is not initialized
+func postEmptyEvent() {
+	glfwLive.RLock()
+	defer glfwLive.RUnlock()
+	if glfwLive.live {
+		glfw.PostEmptyEvent()
+	}
+}
+
+// pollGamepads queues the gamepads' input for the window with keyboard
+// focus, dropping it while none of the app's windows has focus
+func (a *App) pollGamepads() {
+	events, connected := a.gamepads.poll(time.Now())
+	a.gamepadConnected = connected
+	if len(events) == 0 {
+		return
+	}
+	for _, w := range a.windows {
+		if w.window.GetAttrib(glfw.Focused) == glfw.True {
+			for _, ev := range events {
+				w.queue(ev)
+			}
+			return
+		}
+	}
+}
+
+// init initializes GLFW and creates the shared context the first time it
+// is called, once the display system required is found to be there
+func (a *App) init() (err error) {
+	if a.share != nil {
+		return
+	}
+	if err = checkBackend(a.backend); err != nil {
+		return
+	}
+	if err = glfw.Init(); chk.E(err) {
+		return
+	}
+	setGLFWLive(true)
+	contextHints()
+	glfw.WindowHint(glfw.Visible, glfw.False)
+	if a.share, err = glfw.CreateWindow(1, 1, "", nil, nil); err != nil {
+		// Virtual machines and remote sessions often lack OpenGL 3.3 but
+		// offer an older context, enough to show frames drawn on the CPU.
+		// Without any OpenGL no window can open.
+		softwareHints()
+		glfw.WindowHint(glfw.Visible, glfw.False)
+		if a.share, err = glfw.CreateWindow(1, 1, "", nil, nil); chk.E(err) {
+			setGLFWLive(false)
+			glfw.Terminate()
+			return
+		}
+		a.software = true
+	}
+	a.share.MakeContextCurrent()
+	if err = gl.Init(); chk.E(err) {
+		a.share.Destroy()
+		a.share = nil
+		setGLFWLive(false)
+		glfw.Terminate()
+		return
+	}
+	a.clipboard = clipboard.New(system{clipboard.Tools()})
+	return
+}
+
+// wait draws again straight away while any window is animating, otherwise
+// sleeps until input arrives or the earliest frame a window requested for
+// later. Windows with a frame rate limit are woken when it next lets them
+// draw, and a connected gamepad wakes the loop to be read.
+func (a *App) wait() {
+	var wake time.Time
+	var waking bool
+	at := func(t time.Time) {
+		if !waking || t.Before(wake) {
+			wake, waking = t, true
+		}
+	}
+	for _, w := range a.windows {
+		if w.pending() {
+			at(w.nextFrame())
+		} else if t, ok := w.clock.Wake(); ok {
+			at(later(t, w.nextFrame()))
+		}
+	}
+	if a.gamepadConnected {
+		at(time.Now().Add(gamepadPoll))
+	}
+	switch now := time.Now(); {
+	case !waking:
+		glfw.WaitEvents()
+	case !wake.After(now):
+		glfw.PollEvents()
+	default:
+		glfw.WaitEventsTimeout(wake.Sub(now).Seconds())
+	}
+}
+
+// later returns the later of two times
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// close closes a window and removes it from the app
+func (a *App) close(w *Window) {
+	w.close()
+	w.app = nil
+	a.windows = slices.DeleteFunc(a.windows, func(o *Window) bool { return o == w })
+}
+
+// terminate closes the remaining windows and the shared context
+func (a *App) terminate() {
+	for len(a.windows) > 0 {
+		a.close(a.windows[0])
+	}
+	a.running = false
+	a.share.Destroy()
+	a.share = nil
+	setGLFWLive(false)
+	glfw.Terminate()
+}
+
+// contextHints requests the OpenGL 3.3 core context the renderer draws with
+func contextHints() {
+	glfw.DefaultWindowHints()
+	glfw.WindowHint(glfw.ContextVersionMajor, 3)
+	glfw.WindowHint(glfw.ContextVersionMinor, 3)
+	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
+}
@@ -0,0 +1,245 @@
+package window
+
+import (
+	"slices"
+	"sync/atomic"
+	"syscall/js"
+	"time"
+
+	"github.com/mleku/goo/pkg/clipboard"
+	"lol.mleku.dev/chk"
+)
+
+// App runs the main loop of one or more windows, each with its own render
+// function. In the browser each window is a canvas element of the page,
+// and frames are drawn in the page's animation frames while a window has
+// something to show. Windows may be opened before Run or from a render
+// function while it runs; the loop ends when the last window closes.
+type App struct {
+	windows []*Window
+	running bool
+	// wake carries the animation frames the browser calls back with to the
+	// loop, which draws outside the callback so render functions may block
+	wake chan struct{}
+	// requested is set while the animation frame frameID is asked for, and
+	// timer is the timeout asking for one when a window is next due
+	requested atomic.Bool
+	frameID   js.Value
+	timer     js.Value
+	frame     js.Func
+	timeout   js.Func
+	// clipboard is the page's clipboard the windows share, and paste the
+	// listener keeping its text
+	clipboard *clipboard.Clipboard
+	paste     js.Func
+}
+
+// NewApp creates a new app with no windows
+func NewApp() *App {
+	return &App{}
+}
+
+// Open shows a window, drawn by the render function each frame until it is
+// closed by Stop
+func (a *App) Open(w *Window, renderFunc RenderFunc) (err error) {
+	if w.app != nil {
+		return
+	}
+	a.init()
+	if err = w.open(renderFunc); chk.E(err) {
+		return
+	}
+	w.app = a
+	a.windows = append(a.windows, w)
+	a.request()
+	return
+}
+
+// Clipboard returns the clipboard the app's windows share, nil until the
+// first window is opened
+func (a *App) Clipboard() *clipboard.Clipboard {
+	return a.clipboard
+}
+
+// clipboardChanged draws the windows again when the clipboard changed, so
+// widgets following it update
+func (a *App) clipboardChanged() {
+	if !a.clipboard.Check() {
+		return
+	}
+	for _, w := range a.windows {
+		w.clock.Request()
+	}
+	a.request()
+}
+
+// Windows returns the open windows in the order they were opened
+func (a *App) Windows() []*Window {
+	return slices.Clone(a.windows)
+}
+
+// Run draws the open windows until all of them have closed or Stop is
+// called. An error returned by a render function closes every window and is
+// returned. The page keeps running once it returns, so main usually ends
+// there.
+func (a *App) Run() (err error) {
+	a.init()
+	defer a.terminate()
+	a.running = true
+	a.request()
+	for a.running && len(a.windows) > 0 {
+		<-a.wake
+		// Render functions may open windows, which are drawn from the next pass
+		for _, w := range slices.Clone(a.windows) {
+			if !w.running {
+				a.close(w)
+				continue
+			}
+			now := time.Now()
+			if !w.due(now) {
+				continue
+			}
+			if err = w.draw(now); chk.E(err) {
+				return
+			}
+		}
+		if len(a.windows) == 0 {
+			break
+		}
+		a.wait()
+	}
+	return
+}
+
+// Shutdown asks each window whether it may close and when none refuses
+// stops the app, reporting whether it did
+func (a *App) Shutdown() (ok bool) {
+	for _, w := range a.windows {
+		if !w.mayClose() {
+			return false
+		}
+	}
+	a.Stop()
+	return true
+}
+
+// Stop closes every window without asking, ending the main loop
+func (a *App) Stop() {
+	a.running = false
+	a.signal()
+}
+
+// init creates what the windows share the first time it is called
+func (a *App) init() {
+	if a.wake != nil {
+		return
+	}
+	a.wake = make(chan struct{}, 1)
+	a.frame = js.FuncOf(func(this js.Value, args []js.Value) any {
+		a.requested.Store(false)
+		a.signal()
+		return nil
+	})
+	a.timeout = js.FuncOf(func(this js.Value, args []js.Value) any {
+		a.timer = js.Value{}
+		a.request()
+		return nil
+	})
+	page := &pageClipboard{}
+	a.clipboard = clipboard.New(page)
+	// Browsers hand the clipboard's text to pages only as the user pastes
+	a.paste = js.FuncOf(func(this js.Value, args []js.Value) any {
+		if data := args[0].Get("clipboardData"); data.Truthy() {
+			page.text = data.Call("getData", "text/plain").String()
+			a.clipboardChanged()
+		}
+		return nil
+	})
+	js.Global().Get("document").Call("addEventListener", "paste", a.paste)
+}
+
+// request asks the browser for an animation frame to draw in, unless one
+// is asked for already. It is safe to call from any goroutine.
+func (a *App) request() {
+	if a.wake == nil || !a.requested.CompareAndSwap(false, true) {
+		return
+	}
+	a.frameID = js.Global().Call("requestAnimationFrame", a.frame)
+}
+
+// signal wakes the loop without waiting for a frame
+func (a *App) signal() {
+	select {
+	case a.wake <- struct{}{}:
+	default:
+	}
+}
+
+// wait asks for the next animation frame straight away while any window is
+// animating, otherwise for one at the earliest time a window requested for
+// later, leaving input to ask for one when there is none. Windows with a
+// frame rate limit are woken when it next lets them draw.
+func (a *App) wait() {
+	var wake time.Time
+	var waking bool
+	at := func(t time.Time) {
+		if !waking || t.Before(wake) {
+			wake, waking = t, true
+		}
+	}
+	for _, w := range a.windows {
+		if w.pending() {
+			at(w.nextFrame())
+		} else if t, ok := w.clock.Wake(); ok {
+			at(later(t, w.nextFrame()))
+		}
+	}
+	if a.timer.Truthy() {
+		js.Global().Call("clearTimeout", a.timer)
+		a.timer = js.Value{}
+	}
+	switch now := time.Now(); {
+	case !waking:
+	case !wake.After(now):
+		a.request()
+	default:
+		a.timer = js.Global().Call("setTimeout", a.timeout, wake.Sub(now).Milliseconds())
+	}
+}
+
+// later returns the later of two times
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// close closes a window and removes it from the app
+func (a *App) close(w *Window) {
+	w.close()
+	w.app = nil
+	a.windows = slices.DeleteFunc(a.windows, func(o *Window) bool { return o == w })
+}
+
+// terminate closes the remaining windows and releases the callbacks the
+// windows shared
+func (a *App) terminate() {
+	for len(a.windows) > 0 {
+		a.close(a.windows[0])
+	}
+	a.running = false
+	if a.timer.Truthy() {
+		js.Global().Call("clearTimeout", a.timer)
+		a.timer = js.Value{}
+	}
+	if a.requested.Load() {
+		js.Global().Call("cancelAnimationFrame", a.frameID)
+	}
+	js.Global().Get("document").Call("removeEventListener", "paste", a.paste)
+	a.frame.Release()
+	a.timeout.Release()
+	a.paste.Release()
+	a.wake = nil
+	a.requested.Store(false)
+}
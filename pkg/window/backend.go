@@ -0,0 +1,153 @@
+package window
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"sync"
+
+	"github.com/mleku/goo/pkg/clipboard"
+	"lol.mleku.dev/log"
+)
+
+// Backend is the display system windows are shown through. It is chosen as
+// the program is built: on Linux GLFW serves X11 by default and Wayland with
+// the wayland build tag, and a program cannot switch between them as it
+// runs.
+type Backend int
+
+const (
+	// BackendAuto accepts the display system the program was built for
+	BackendAuto Backend = iota
+	BackendWayland
+	BackendX11
+	BackendWindows
+	BackendMacOS
+	// BackendWeb shows windows in canvas elements of a web page, in the
+	// js/wasm build
+	BackendWeb
+)
+
+// String returns the name of the display system
+func (b Backend) String() string {
+	switch b {
+	case BackendWayland:
+		return "Wayland"
+	case BackendX11:
+		return "X11"
+	case BackendWindows:
+		return "Windows"
+	case BackendMacOS:
+		return "macOS"
+	case BackendWeb:
+		return "web"
+	}
+	return "auto"
+}
+
+// ErrNoDisplay is returned when opening a window in a session without a
+// display of the system the program was built for, such as a build with
+// the wayland tag run under X11 alone
+var ErrNoDisplay = errors.New("window: no display for the display system the program was built for")
+
+// ErrWrongBackend is returned when opening a window that requires a display
+// system the program was not built for
+var ErrWrongBackend = errors.New("window: the program was built for another display system")
+
+// RequireBackend makes the first window the app opens fail with
+// ErrWrongBackend unless the program was built for the display system,
+// checked once as all the app's windows share it. The choice is made when
+// building, not here: build with the wayland tag for Wayland. The X11 build
+// runs under Wayland sessions too, through XWayland, while a Wayland build
+// needs a Wayland session. Platform tells which system a build uses.
+func RequireBackend(b Backend) Option {
+	return func(w *Window) { w.backend = b }
+}
+
+// Capabilities are what the display system lets windows do, which differs
+// most between Wayland and X11
+type Capabilities struct {
+	Backend Backend
+	// Position is whether windows learn and choose their place on the
+	// desktop, by SetPosition, remembered geometry and drags on title bars
+	// drawn by widgets. Wayland keeps windows' places from them, so the
+	// calls do nothing there.
+	Position bool
+	// NativeDecorations is whether decorated windows always get the
+	// desktop's own title bar and borders. Wayland compositors without
+	// server side decorations, such as GNOME's, leave GLFW to draw plain
+	// ones, which apps may replace with their own through Undecorated.
+	NativeDecorations bool
+	// MonitorScale is whether each monitor has its own content scale, so
+	// Frame.Scale changes as a window moves between them. X11 has one for
+	// the whole desktop.
+	MonitorScale bool
+	// ClipboardImages is whether the clipboard holds images, which needs
+	// the clipboard tools of the desktop installed on Linux
+	ClipboardImages bool
+}
+
+// platform is the capabilities of the display system the program runs
+// with, found once as they cannot change while it runs
+var platform = sync.OnceValue(func() (c Capabilities) {
+	c = Capabilities{
+		Backend:           builtBackend(),
+		Position:          true,
+		NativeDecorations: true,
+		MonitorScale:      true,
+		ClipboardImages:   clipboard.HasImageTools(),
+	}
+	switch c.Backend {
+	case BackendWayland:
+		c.Position = false
+		c.NativeDecorations = false
+	case BackendX11:
+		c.MonitorScale = false
+	case BackendWeb:
+		c.Position = false
+		c.NativeDecorations = false
+	}
+	return
+})
+
+// Platform returns the capabilities of the display system the program's
+// windows are shown through
+func Platform() Capabilities {
+	return platform()
+}
+
+// builtBackend returns the display system the program was built for
+func builtBackend() Backend {
+	switch runtime.GOOS {
+	case "windows":
+		return BackendWindows
+	case "darwin":
+		return BackendMacOS
+	case "js":
+		return BackendWeb
+	}
+	if waylandBuild {
+		return BackendWayland
+	}
+	return BackendX11
+}
+
+// checkBackend reports whether the program was built for the display
+// system required and the session has a display of it
+func checkBackend(required Backend) (err error) {
+	built := builtBackend()
+	if required != BackendAuto && required != built {
+		log.E.Ln("window: built for", built.String()+",", "not", required)
+		return ErrWrongBackend
+	}
+	wayland, x11 := os.Getenv("WAYLAND_DISPLAY") != "", os.Getenv("DISPLAY") != ""
+	switch {
+	case built == BackendWayland && !wayland && x11:
+		log.E.Ln("window: a build with the wayland tag needs a Wayland session; build without it for X11")
+		return ErrNoDisplay
+	case built == BackendX11 && !x11 && wayland:
+		log.E.Ln("window: the X11 build needs XWayland under Wayland, or a build with the wayland tag")
+		return ErrNoDisplay
+	}
+	return
+}
@@ -0,0 +1,6 @@
+//go:build !wayland
+
+package window
+
+// waylandBuild is set when GLFW is built for Wayland rather than X11
+const waylandBuild = false
@@ -0,0 +1,107 @@
+//go:build !js
+
+package window
+
+import (
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/mleku/goo/pkg/interfaces"
+)
+
+// SetAlwaysOnTop sets whether the window stays above the other windows of
+// the desktop
+func (w *Window) SetAlwaysOnTop(on bool) {
+	w.alwaysOnTop = on
+	if w.window != nil {
+		w.window.SetAttrib(glfw.Floating, glfwBool(on))
+	}
+}
+
+// SetDecorated sets whether the window has the system's title bar and
+// borders
+func (w *Window) SetDecorated(on bool) {
+	w.undecorated = !on
+	if w.window != nil {
+		w.window.SetAttrib(glfw.Decorated, glfwBool(on))
+	}
+}
+
+// Transparent reports whether the desktop shows through the window's
+// transparent content
+func (w *Window) Transparent() bool {
+	return w.window != nil && w.window.GetAttrib(glfw.TransparentFramebuffer) == glfw.True
+}
+
+// chromeHints requests the window's decoration, stacking and transparency
+func (w *Window) chromeHints() {
+	glfw.WindowHint(glfw.Decorated, glfwBool(!w.undecorated))
+	glfw.WindowHint(glfw.Floating, glfwBool(w.alwaysOnTop))
+	glfw.WindowHint(glfw.TransparentFramebuffer, glfwBool(w.transparent))
+}
+
+// windowDrag is a move or resize of the window by a drag on a part of its
+// frame drawn by its widgets
+type windowDrag struct {
+	area interfaces.WindowArea
+	// cursorX and cursorY are where the press was, on the screen, and x, y,
+	// width and height the window's place then
+	cursorX, cursorY    float64
+	x, y, width, height int
+}
+
+// beginDrag starts moving or resizing the window with the mouse, while the
+// left button that pressed on the area is held
+func (w *Window) beginDrag(area interfaces.WindowArea) {
+	if area == interfaces.AreaClient || !Platform().Position || w.window.GetMouseButton(glfw.MouseButtonLeft) != glfw.Press {
+		return
+	}
+	d := windowDrag{area: area}
+	d.x, d.y = w.window.GetPos()
+	d.width, d.height = w.window.GetSize()
+	d.cursorX, d.cursorY = float64(d.x)+w.mouseX, float64(d.y)+w.mouseY
+	w.drag = d
+}
+
+// dragTo moves or resizes the window for the cursor at a point in the
+// window. The cursor is followed on the screen, as the window moves under it.
+func (w *Window) dragTo(x, y float64) {
+	d := &w.drag
+	wx, wy := w.window.GetPos()
+	dx := int(float64(wx) + x - d.cursorX)
+	dy := int(float64(wy) + y - d.cursorY)
+	if d.area == interfaces.AreaCaption {
+		w.window.SetPos(d.x+dx, d.y+dy)
+		return
+	}
+	left, top, width, height := d.x, d.y, d.width, d.height
+	switch d.area {
+	case interfaces.AreaLeft, interfaces.AreaTopLeft, interfaces.AreaBottomLeft:
+		dx = min(dx, width-1)
+		left, width = left+dx, width-dx
+	case interfaces.AreaRight, interfaces.AreaTopRight, interfaces.AreaBottomRight:
+		width = max(width+dx, 1)
+	}
+	switch d.area {
+	case interfaces.AreaTop, interfaces.AreaTopLeft, interfaces.AreaTopRight:
+		dy = min(dy, height-1)
+		top, height = top+dy, height-dy
+	case interfaces.AreaBottom, interfaces.AreaBottomLeft, interfaces.AreaBottomRight:
+		height = max(height+dy, 1)
+	}
+	if left != wx || top != wy {
+		w.window.SetPos(left, top)
+	}
+	w.window.SetSize(width, height)
+}
+
+// dragging reports whether the window is being moved or resized
+func (w *Window) dragging() bool {
+	return w.drag.area != interfaces.AreaClient
+}
+
+// glfwBool converts a bool to a GLFW hint or attribute value
+func glfwBool(b bool) int {
+	if b {
+		return glfw.True
+	}
+	return glfw.False
+}
@@ -0,0 +1,39 @@
+//go:build !js
+
+package window
+
+import (
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/mleku/goo/pkg/clipboard"
+)
+
+// system is the system clipboard, whose text is read through GLFW and
+// images through the platform's command line tools
+type system struct {
+	images clipboard.System
+}
+
+// Text returns the clipboard contents as text
+func (s system) Text() string {
+	return glfw.GetClipboardString()
+}
+
+// SetText replaces the clipboard contents
+func (s system) SetText(text string) {
+	glfw.SetClipboardString(text)
+}
+
+// Image returns the image held encoded as PNG
+func (s system) Image() (data []byte, err error) {
+	return s.images.Image()
+}
+
+// SetImage replaces the clipboard contents with an image encoded as PNG
+func (s system) SetImage(data []byte) (err error) {
+	return s.images.SetImage(data)
+}
+
+// HasImage reports whether an image is held
+func (s system) HasImage() bool {
+	return s.images.HasImage()
+}
@@ -0,0 +1,43 @@
+package window
+
+import (
+	"syscall/js"
+
+	"github.com/mleku/goo/pkg/clipboard"
+)
+
+// pageClipboard is the clipboard as a page sees it. Browsers hand pages the
+// clipboard's text only as the user pastes, so the text read is that last
+// pasted or copied, and images are unsupported.
+type pageClipboard struct {
+	text string
+}
+
+// Text returns the text last pasted or copied
+func (p *pageClipboard) Text() string {
+	return p.text
+}
+
+// SetText replaces the clipboard contents, where the browser allows, as
+// while handling the user's input
+func (p *pageClipboard) SetText(text string) {
+	p.text = text
+	if c := js.Global().Get("navigator").Get("clipboard"); c.Truthy() {
+		c.Call("writeText", text)
+	}
+}
+
+// Image returns no image, as pages are not handed them
+func (p *pageClipboard) Image() (data []byte, err error) {
+	return nil, clipboard.ErrUnsupported
+}
+
+// SetImage returns ErrUnsupported
+func (p *pageClipboard) SetImage(data []byte) (err error) {
+	return clipboard.ErrUnsupported
+}
+
+// HasImage reports false
+func (p *pageClipboard) HasImage() bool {
+	return false
+}
@@ -0,0 +1,100 @@
+//go:build !js
+
+package window
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/mleku/goo/pkg/interfaces"
+)
+
+// notAllowedSize is the side of the not allowed cursor image, which GLFW has
+// no standard shape for
+const notAllowedSize = 16
+
+// cursors creates the cursor shapes the render function asks for and keeps
+// them until the window closes
+type cursors struct {
+	shapes  map[interfaces.Cursor]*glfw.Cursor
+	current interfaces.Cursor
+}
+
+// apply shows a cursor shape over the window if it is not showing already
+func (c *cursors) apply(window *glfw.Window, shape interfaces.Cursor) {
+	if shape == c.current {
+		return
+	}
+	c.current = shape
+	if shape == interfaces.CursorDefault {
+		window.SetCursor(nil)
+		return
+	}
+	cursor, ok := c.shapes[shape]
+	if !ok {
+		cursor = createCursor(shape)
+		if c.shapes == nil {
+			c.shapes = make(map[interfaces.Cursor]*glfw.Cursor)
+		}
+		c.shapes[shape] = cursor
+	}
+	window.SetCursor(cursor)
+}
+
+// destroy frees the cursors created
+func (c *cursors) destroy() {
+	for _, cursor := range c.shapes {
+		if cursor != nil {
+			cursor.Destroy()
+		}
+	}
+	c.shapes = nil
+	c.current = interfaces.CursorDefault
+}
+
+// createCursor creates a cursor shape, nil for the default cursor
+func createCursor(shape interfaces.Cursor) *glfw.Cursor {
+	switch shape {
+	case interfaces.CursorArrow:
+		return glfw.CreateStandardCursor(glfw.ArrowCursor)
+	case interfaces.CursorIBeam:
+		return glfw.CreateStandardCursor(glfw.IBeamCursor)
+	case interfaces.CursorHand:
+		return glfw.CreateStandardCursor(glfw.HandCursor)
+	case interfaces.CursorResizeH:
+		return glfw.CreateStandardCursor(glfw.HResizeCursor)
+	case interfaces.CursorResizeV:
+		return glfw.CreateStandardCursor(glfw.VResizeCursor)
+	case interfaces.CursorCrosshair:
+		return glfw.CreateStandardCursor(glfw.CrosshairCursor)
+	case interfaces.CursorNotAllowed:
+		return glfw.CreateCursor(notAllowedImage(), notAllowedSize/2, notAllowedSize/2)
+	}
+	return nil
+}
+
+// notAllowedImage draws a red ring crossed by a diagonal bar, outlined in
+// white so it shows on any background
+func notAllowedImage() image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, notAllowedSize, notAllowedSize))
+	red := color.NRGBA{R: 220, G: 40, B: 40, A: 255}
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	c := float64(notAllowedSize-1) / 2
+	for y := range notAllowedSize {
+		for x := range notAllowedSize {
+			dx, dy := float64(x)-c, float64(y)-c
+			r := math.Hypot(dx, dy)
+			// Distance from the bar running from top left to bottom right
+			bar := math.Abs(dx-dy) / math.Sqrt2
+			switch {
+			case r <= c && (r >= c-2.5 || bar <= 1.5):
+				img.SetNRGBA(x, y, red)
+			case r <= c+1 && (r >= c-3.5 || bar <= 2.5):
+				img.SetNRGBA(x, y, white)
+			}
+		}
+	}
+	return img
+}
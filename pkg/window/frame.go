@@ -0,0 +1,232 @@
+package window
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mleku/goo/pkg/anim"
+	"github.com/mleku/goo/pkg/clipboard"
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/render"
+	"lol.mleku.dev/chk"
+)
+
+// MaxFPS limits the number of frames drawn a second and returns the window
+// for chaining. Input arriving sooner after a frame than the limit allows is
+// delivered with the next. Zero removes the limit.
+func (w *Window) MaxFPS(fps float64) *Window {
+	w.interval = 0
+	if fps > 0 {
+		w.interval = time.Duration(float64(time.Second) / fps)
+	}
+	return w
+}
+
+// Stats returns how fast the window has been drawing
+func (w *Window) Stats() interfaces.FrameStats {
+	return w.stats.stats(time.Now())
+}
+
+// Frame describes the window state passed to the render function each frame
+type Frame struct {
+	// Window size (logical size in screen coordinates)
+	Width, Height int
+	// Mouse position in window coordinates
+	MouseX, MouseY float64
+	CursorInWindow bool
+	// Events holds the input received since the previous frame in the order
+	// it arrived
+	Events []interfaces.Event
+	// DrawList receives the frame's geometry, which the window submits to
+	// the GPU after the render function returns
+	DrawList *render.DrawList
+	// Clipboard accesses the system clipboard, shared by the app's windows
+	Clipboard *clipboard.Clipboard
+	// Clock is the frame clock, advanced at the start of each frame.
+	// Animations request further frames on it; otherwise the window waits
+	// for input before drawing again.
+	Clock *anim.Clock
+	// Cursor is the shape of the mouse cursor over the window, which the
+	// render function sets each frame, the system's normal cursor if not
+	Cursor interfaces.Cursor
+	// Stats describes how fast the window has been drawing, up to the
+	// previous frame
+	Stats interfaces.FrameStats
+	// Scale is the number of framebuffer pixels per unit of the window size,
+	// 2 on a typical high density display
+	Scale float32
+	// InputMethod is told where the caret of the focused text is
+	InputMethod interfaces.InputMethod
+	// WindowDrag is the part of the window's frame a press landed in this
+	// frame, which the render function sets from the root widget's
+	// WindowDrag. The window moves or resizes itself while the button is held.
+	WindowDrag interfaces.WindowArea
+}
+
+// RenderFunc paints a frame. The frame is only valid for the duration of the
+// call. Frames are drawn into an offscreen canvas that keeps its contents
+// between frames, so only changed regions need redrawing. An ExposeEvent is
+// queued whenever the canvas contents are lost.
+type RenderFunc func(frame *Frame) error
+
+// Run opens the window and runs the main loop until it closes. Use an App to
+// show more than one window.
+func (w *Window) Run(renderFunc RenderFunc) (err error) {
+	app := NewApp()
+	if err = app.Open(w, renderFunc); chk.E(err) {
+		return
+	}
+	return app.Run()
+}
+
+// due reports whether the frame rate limit lets the window draw at a time
+func (w *Window) due(now time.Time) bool {
+	return !now.Before(w.nextFrame())
+}
+
+// nextFrame returns the earliest time the frame rate limit lets the window draw
+func (w *Window) nextFrame() time.Time {
+	if w.interval == 0 || w.lastFrame.IsZero() {
+		return time.Time{}
+	}
+	return w.lastFrame.Add(w.interval)
+}
+
+// pending reports whether the window has input or calls to deliver or was
+// asked to draw again as soon as possible
+func (w *Window) pending() bool {
+	return len(w.events) > 0 || w.invoke.waiting() || w.clock.Active()
+}
+
+// OnCloseRequest sets the callback deciding whether the window closes when
+// the user asks, by its close button or the system's shortcut, and returns
+// the window for chaining. Returning false keeps it open, for asking whether
+// to save changes first and closing with Stop once answered, or for hiding
+// it to the system tray instead.
+func (w *Window) OnCloseRequest(fn func() bool) *Window {
+	w.onCloseRequest = fn
+	return w
+}
+
+// OnClose sets the callback invoked as the window closes, with its GL
+// context current before its resources are freed, and returns the window
+// for chaining. Pass the root widget's Dispose to release the tree's
+// resources.
+func (w *Window) OnClose(fn func()) *Window {
+	w.onClose = fn
+	return w
+}
+
+// RequestClose closes the window as though the user asked, if the close
+// request callback allows
+func (w *Window) RequestClose() {
+	if w.mayClose() {
+		w.Stop()
+	}
+}
+
+// mayClose asks the close request callback whether the window may close,
+// drawing a frame so anything the callback showed appears
+func (w *Window) mayClose() bool {
+	if w.onCloseRequest == nil {
+		return true
+	}
+	if w.onCloseRequest() {
+		return true
+	}
+	if w.clock != nil {
+		w.clock.Request()
+	}
+	return false
+}
+
+// runQueued runs the calls queued by RunOnUIThread, leaving those queued
+// while they run for the next frame
+func (w *Window) runQueued() {
+	for _, fn := range w.invoke.take() {
+		fn()
+	}
+}
+
+// uiCalls queues the calls other goroutines hand to a window, run at the
+// start of its next frame. It grows as needed so callers never block, and
+// drops the calls once the window has closed, as nothing would run them.
+type uiCalls struct {
+	mu     sync.Mutex
+	calls  []func()
+	closed bool
+}
+
+// push queues a call, reporting false when the window has closed
+func (q *uiCalls) push(fn func()) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return false
+	}
+	q.calls = append(q.calls, fn)
+	return true
+}
+
+// take returns the queued calls, leaving the queue empty
+func (q *uiCalls) take() (calls []func()) {
+	q.mu.Lock()
+	calls, q.calls = q.calls, nil
+	q.mu.Unlock()
+	return
+}
+
+// waiting reports whether calls are queued
+func (q *uiCalls) waiting() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.calls) > 0
+}
+
+// isClosed reports whether the window has closed
+func (q *uiCalls) isClosed() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.closed
+}
+
+// setClosed drops the queued calls and those made from now on while the
+// window is closed, and accepts calls again once it opens
+func (q *uiCalls) setClosed(closed bool) {
+	q.mu.Lock()
+	q.closed = closed
+	if closed {
+		q.calls = nil
+	}
+	q.mu.Unlock()
+}
+
+// Touch delivers a touch with the next frame, at a point in window
+// coordinates. The web build passes the page's touches on here. Desktop
+// windows read no touch screen themselves, as GLFW reports one only as the
+// mouse input it emulates, so touches and the pinches built on them reach
+// the widgets only when the program reads the device itself, such as
+// through XInput 2 or WM_POINTER, and feeds its touches here from the main
+// thread.
+func (w *Window) Touch(id int, phase interfaces.TouchPhase, x, y float64) {
+	w.queue(interfaces.TouchEvent{
+		ID:       id,
+		Position: interfaces.Point{X: float32(x), Y: float32(y)},
+		Phase:    phase,
+	})
+}
+
+// App returns the app showing the window, nil while it is not open
+func (w *Window) App() *App {
+	return w.app
+}
+
+// queue appends an event to be delivered with the next frame
+func (w *Window) queue(ev interfaces.Event) {
+	w.events = append(w.events, ev)
+}
+
+// mousePoint returns the current cursor position as a widget coordinate
+func (w *Window) mousePoint() interfaces.Point {
+	return interfaces.Point{X: float32(w.mouseX), Y: float32(w.mouseY)}
+}
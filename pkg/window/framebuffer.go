@@ -0,0 +1,63 @@
+//go:build !js
+
+package window
+
+import (
+	"github.com/go-gl/gl/all-core/gl"
+)
+
+// frameBuffer is an offscreen render target that keeps the rendered frame
+// between swaps, so widgets only need to repaint the regions that changed.
+// The default framebuffer contents are undefined after a swap.
+type frameBuffer struct {
+	fbo           uint32
+	color         uint32
+	width, height int
+}
+
+// resize (re)creates the render target at the given size. The previous
+// contents are lost.
+func (f *frameBuffer) resize(width, height int) {
+	f.delete()
+	f.width, f.height = width, height
+
+	gl.GenRenderbuffers(1, &f.color)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, f.color)
+	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.RGBA8, int32(width), int32(height))
+	gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
+
+	gl.GenFramebuffers(1, &f.fbo)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, f.fbo)
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.RENDERBUFFER, f.color)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+}
+
+// bind directs rendering into the offscreen target
+func (f *frameBuffer) bind() {
+	gl.BindFramebuffer(gl.FRAMEBUFFER, f.fbo)
+}
+
+// present copies the offscreen target to the window's back buffer
+func (f *frameBuffer) present() {
+	// The scissor test also applies to blits, the renderer leaves it disabled
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, f.fbo)
+	gl.BindFramebuffer(gl.DRAW_FRAMEBUFFER, 0)
+	gl.BlitFramebuffer(
+		0, 0, int32(f.width), int32(f.height),
+		0, 0, int32(f.width), int32(f.height),
+		gl.COLOR_BUFFER_BIT, gl.NEAREST,
+	)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+}
+
+// delete releases the GL objects
+func (f *frameBuffer) delete() {
+	if f.fbo != 0 {
+		gl.DeleteFramebuffers(1, &f.fbo)
+		f.fbo = 0
+	}
+	if f.color != 0 {
+		gl.DeleteRenderbuffers(1, &f.color)
+		f.color = 0
+	}
+}
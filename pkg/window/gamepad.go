@@ -0,0 +1,98 @@
+//go:build !js
+
+package window
+
+import (
+	"time"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/mleku/goo/pkg/interfaces"
+)
+
+const (
+	// gamepadPoll is how often gamepads are read while one is connected, as
+	// GLFW has no callbacks for their buttons
+	gamepadPoll = 16 * time.Millisecond
+	// stickThreshold is how far the left stick is pushed to count as the d-pad
+	stickThreshold = 0.5
+	// gamepadRepeatDelay is how long a d-pad direction is held before it
+	// repeats, and gamepadRepeatInterval the time between repeats
+	gamepadRepeatDelay    = 400 * time.Millisecond
+	gamepadRepeatInterval = 100 * time.Millisecond
+)
+
+// gamepads reads the connected gamepads and reports their buttons changing
+// as events
+type gamepads struct {
+	pads map[glfw.Joystick]*gamepad
+}
+
+// gamepad is the state of a gamepad when it was last read
+type gamepad struct {
+	held [glfw.ButtonLast + 1]bool
+	// repeat is when each held d-pad direction next repeats
+	repeat [glfw.ButtonLast + 1]time.Time
+}
+
+// poll reads every connected gamepad at a time, returning events for the
+// buttons that were pressed, released or repeated since the last poll, and
+// whether any gamepad is connected
+func (g *gamepads) poll(now time.Time) (events []interfaces.Event, connected bool) {
+	for j := glfw.Joystick1; j <= glfw.JoystickLast; j++ {
+		if !j.IsGamepad() {
+			delete(g.pads, j)
+			continue
+		}
+		connected = true
+		state := j.GetGamepadState()
+		if state == nil {
+			continue
+		}
+		if g.pads == nil {
+			g.pads = make(map[glfw.Joystick]*gamepad)
+		}
+		pad := g.pads[j]
+		if pad == nil {
+			pad = &gamepad{}
+			g.pads[j] = pad
+		}
+		var down [glfw.ButtonLast + 1]bool
+		for i, action := range state.Buttons {
+			down[i] = action == glfw.Press
+		}
+		// The left stick steers as the d-pad does
+		x, y := state.Axes[glfw.AxisLeftX], state.Axes[glfw.AxisLeftY]
+		down[glfw.ButtonDpadLeft] = down[glfw.ButtonDpadLeft] || x < -stickThreshold
+		down[glfw.ButtonDpadRight] = down[glfw.ButtonDpadRight] || x > stickThreshold
+		down[glfw.ButtonDpadUp] = down[glfw.ButtonDpadUp] || y < -stickThreshold
+		down[glfw.ButtonDpadDown] = down[glfw.ButtonDpadDown] || y > stickThreshold
+		for i := range down {
+			ev := interfaces.GamepadEvent{Gamepad: int(j - glfw.Joystick1), Button: interfaces.GamepadButton(i)}
+			switch {
+			case down[i] && !pad.held[i]:
+				ev.Action = interfaces.ActionPress
+				pad.repeat[i] = now.Add(gamepadRepeatDelay)
+			case down[i] && directional(ev.Button) && !now.Before(pad.repeat[i]):
+				ev.Action = interfaces.ActionRepeat
+				pad.repeat[i] = now.Add(gamepadRepeatInterval)
+			case !down[i] && pad.held[i]:
+				ev.Action = interfaces.ActionRelease
+			default:
+				continue
+			}
+			events = append(events, ev)
+		}
+		pad.held = down
+	}
+	return
+}
+
+// directional reports whether a gamepad button is a d-pad direction
+func directional(button interfaces.GamepadButton) bool {
+	switch button {
+	case interfaces.GamepadDpadUp, interfaces.GamepadDpadRight,
+		interfaces.GamepadDpadDown, interfaces.GamepadDpadLeft:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,293 @@
+package window
+
+import (
+	"syscall/js"
+	"unicode/utf8"
+
+	"github.com/mleku/goo/pkg/interfaces"
+)
+
+// listener is an event handler the window added to the page, or the
+// callback of an observer when observer is set
+type listener struct {
+	target   js.Value
+	event    string
+	fn       js.Func
+	observer js.Value
+}
+
+// on adds a handler for an event of a target of the page, which draws a
+// frame once it ran. Handlers keep the page from acting on the events
+// themselves unless they return true.
+func (w *Window) on(target js.Value, event string, handle func(e js.Value) (pass bool)) {
+	fn := js.FuncOf(func(this js.Value, args []js.Value) any {
+		e := args[0]
+		if !handle(e) && e.Get("cancelable").Bool() {
+			e.Call("preventDefault")
+		}
+		w.Wake()
+		return nil
+	})
+	target.Call("addEventListener", event, fn, map[string]any{"passive": false})
+	w.listeners = append(w.listeners, listener{target: target, event: event, fn: fn})
+}
+
+// listen queues the page's input for the canvas for dispatch on the next
+// frame. Pointer events stand in for the mouse and touches alike, and are
+// captured while a button is held, so a drag leaving the canvas is followed
+// until it ends.
+func (w *Window) listen() {
+	c := w.canvas
+	w.on(c, "pointermove", func(e js.Value) bool {
+		if e.Get("pointerType").String() == "touch" {
+			w.touch(e, interfaces.TouchMove)
+			return false
+		}
+		w.mouseX, w.mouseY = e.Get("offsetX").Float(), e.Get("offsetY").Float()
+		w.queue(interfaces.MouseMoveEvent{Position: w.mousePoint()})
+		// A button pressed or released while another is held arrives as a move
+		if button := e.Get("button").Int(); button >= 0 && button < len(buttonBits) {
+			action := interfaces.ActionRelease
+			if e.Get("buttons").Int()&buttonBits[button] != 0 {
+				action = interfaces.ActionPress
+			}
+			w.mouseButton(e, action)
+		}
+		return false
+	})
+	w.on(c, "pointerdown", func(e js.Value) bool {
+		c.Call("focus")
+		c.Call("setPointerCapture", e.Get("pointerId"))
+		if e.Get("pointerType").String() == "touch" {
+			w.touch(e, interfaces.TouchBegin)
+			return false
+		}
+		w.mouseX, w.mouseY = e.Get("offsetX").Float(), e.Get("offsetY").Float()
+		w.mouseButton(e, interfaces.ActionPress)
+		return false
+	})
+	w.on(c, "pointerup", func(e js.Value) bool {
+		if e.Get("pointerType").String() == "touch" {
+			w.touch(e, interfaces.TouchEnd)
+			return false
+		}
+		w.mouseX, w.mouseY = e.Get("offsetX").Float(), e.Get("offsetY").Float()
+		w.mouseButton(e, interfaces.ActionRelease)
+		return false
+	})
+	w.on(c, "pointercancel", func(e js.Value) bool {
+		if e.Get("pointerType").String() == "touch" {
+			w.touch(e, interfaces.TouchCancel)
+		}
+		return false
+	})
+	w.on(c, "pointerenter", func(e js.Value) bool {
+		if e.Get("pointerType").String() != "touch" {
+			w.cursorInWindow = true
+			w.queue(interfaces.CursorEnterEvent{})
+		}
+		return true
+	})
+	w.on(c, "pointerleave", func(e js.Value) bool {
+		if e.Get("pointerType").String() != "touch" {
+			w.cursorInWindow = false
+			w.queue(interfaces.CursorLeaveEvent{})
+		}
+		return true
+	})
+	w.on(c, "wheel", func(e js.Value) bool {
+		// GLFW counts a notch of the wheel as one, which browsers give as
+		// about a hundred pixels or three lines
+		scale := 0.01
+		switch e.Get("deltaMode").Int() {
+		case 1:
+			scale = 1.0 / 3
+		case 2:
+			scale = 1
+		}
+		w.queue(interfaces.ScrollEvent{
+			Position: w.mousePoint(),
+			Offset: interfaces.Point{
+				X: float32(-e.Get("deltaX").Float() * scale),
+				Y: float32(-e.Get("deltaY").Float() * scale),
+			},
+		})
+		return false
+	})
+	w.on(c, "contextmenu", func(e js.Value) bool { return false })
+	w.on(c, "keydown", func(e js.Value) bool {
+		action := interfaces.ActionPress
+		if e.Get("repeat").Bool() {
+			action = interfaces.ActionRepeat
+		}
+		mods := w.key(e, action)
+		key := e.Get("key").String()
+		if r, size := utf8.DecodeRuneInString(key); size == len(key) && r != utf8.RuneError &&
+			(mods&(interfaces.ModControl|interfaces.ModSuper) == 0 || mods&interfaces.ModAlt != 0) {
+			w.queue(interfaces.CharEvent{Char: r})
+		}
+		// The paste shortcut goes on to the page, which hands over the
+		// clipboard's text with the paste event that follows
+		return mods&(interfaces.ModControl|interfaces.ModSuper) != 0 && e.Get("code").String() == "KeyV"
+	})
+	w.on(c, "keyup", func(e js.Value) bool {
+		w.key(e, interfaces.ActionRelease)
+		return false
+	})
+	observer := js.Global().Get("ResizeObserver")
+	if observer.Truthy() {
+		// Laying out the canvas at another size draws it again
+		fn := js.FuncOf(func(this js.Value, args []js.Value) any {
+			w.Wake()
+			return nil
+		})
+		l := listener{fn: fn, observer: observer.New(fn)}
+		l.observer.Call("observe", c)
+		w.listeners = append(w.listeners, l)
+	}
+}
+
+// buttonBits are the bits of the buttons held in pointer events for each
+// button, in the order browsers number them
+var buttonBits = []int{1, 4, 2, 8, 16}
+
+// mouseButton queues a press or release of the mouse button of a pointer
+// event
+func (w *Window) mouseButton(e js.Value, action interfaces.Action) {
+	var button interfaces.MouseButton
+	switch e.Get("button").Int() {
+	case 0:
+		button = interfaces.MouseButtonLeft
+	case 1:
+		button = interfaces.MouseButtonMiddle
+	case 2:
+		button = interfaces.MouseButtonRight
+	default:
+		return
+	}
+	w.queue(interfaces.MouseButtonEvent{
+		Position: w.mousePoint(),
+		Button:   button,
+		Action:   action,
+		Mods:     convertMods(e),
+	})
+}
+
+// touch queues a touch from a pointer event of a finger
+func (w *Window) touch(e js.Value, phase interfaces.TouchPhase) {
+	w.Touch(e.Get("pointerId").Int(), phase, e.Get("offsetX").Float(), e.Get("offsetY").Float())
+}
+
+// key queues a key event from a keyboard event, returning its modifiers
+func (w *Window) key(e js.Value, action interfaces.Action) (mods interfaces.Modifier) {
+	mods = convertMods(e)
+	w.queue(interfaces.KeyEvent{
+		Key:      convertKey(e.Get("code").String()),
+		Scancode: e.Get("keyCode").Int(),
+		Action:   action,
+		Mods:     mods,
+	})
+	return
+}
+
+// convertMods maps the modifier keys held in a DOM event to widget event
+// modifiers
+func convertMods(e js.Value) (m interfaces.Modifier) {
+	if e.Get("shiftKey").Bool() {
+		m |= interfaces.ModShift
+	}
+	if e.Get("ctrlKey").Bool() {
+		m |= interfaces.ModControl
+	}
+	if e.Get("altKey").Bool() {
+		m |= interfaces.ModAlt
+	}
+	if e.Get("metaKey").Bool() {
+		m |= interfaces.ModSuper
+	}
+	if e.Get("getModifierState").Truthy() {
+		if e.Call("getModifierState", "CapsLock").Bool() {
+			m |= interfaces.ModCapsLock
+		}
+		if e.Call("getModifierState", "NumLock").Bool() {
+			m |= interfaces.ModNumLock
+		}
+	}
+	return
+}
+
+// keyCodes are the keys, numbered as GLFW numbers them, of the DOM's names
+// for the keys that are not letters, digits or function keys
+var keyCodes = map[string]interfaces.Key{
+	"Space": interfaces.KeySpace, "Quote": 39, "Comma": 44, "Minus": 45,
+	"Period": 46, "Slash": 47, "Semicolon": 59, "Equal": 61,
+	"BracketLeft": 91, "Backslash": 92, "BracketRight": 93, "Backquote": 96,
+	"Escape": interfaces.KeyEscape, "Enter": interfaces.KeyEnter,
+	"Tab": interfaces.KeyTab, "Backspace": interfaces.KeyBackspace,
+	"Insert": interfaces.KeyInsert, "Delete": interfaces.KeyDelete,
+	"ArrowRight": interfaces.KeyRight, "ArrowLeft": interfaces.KeyLeft,
+	"ArrowDown": interfaces.KeyDown, "ArrowUp": interfaces.KeyUp,
+	"PageUp": interfaces.KeyPageUp, "PageDown": interfaces.KeyPageDown,
+	"Home": interfaces.KeyHome, "End": interfaces.KeyEnd,
+	"CapsLock": 280, "ScrollLock": 281, "NumLock": 282, "PrintScreen": 283,
+	"Pause": 284, "NumpadDecimal": 330, "NumpadDivide": 331,
+	"NumpadMultiply": 332, "NumpadSubtract": 333, "NumpadAdd": 334,
+	"NumpadEnter": 335, "NumpadEqual": 336, "ShiftLeft": 340,
+	"ControlLeft": 341, "AltLeft": 342, "MetaLeft": 343, "ShiftRight": 344,
+	"ControlRight": 345, "AltRight": 346, "MetaRight": 347, "ContextMenu": 348,
+}
+
+// convertKey maps the DOM's name for a key, which like GLFW's numbers names
+// its place on a US keyboard, to the widget event key
+func convertKey(code string) interfaces.Key {
+	switch n := len(code); {
+	case n == 4 && code[:3] == "Key":
+		return interfaces.Key(code[3])
+	case n == 6 && code[:5] == "Digit":
+		return interfaces.Key(code[5])
+	case n == 7 && code[:6] == "Numpad" && code[6] >= '0' && code[6] <= '9':
+		return interfaces.Key(320 + int(code[6]-'0'))
+	case n >= 2 && n <= 3 && code[0] == 'F':
+		var f int
+		for _, d := range code[1:] {
+			if d < '0' || d > '9' {
+				return interfaces.KeyUnknown
+			}
+			f = f*10 + int(d-'0')
+		}
+		if f >= 1 && f <= 25 {
+			return interfaces.KeyF1 + interfaces.Key(f-1)
+		}
+	}
+	if key, ok := keyCodes[code]; ok {
+		return key
+	}
+	return interfaces.KeyUnknown
+}
+
+// setCursor shows a cursor shape over the canvas if it is not showing
+// already
+func (w *Window) setCursor(shape interfaces.Cursor) {
+	if shape == w.cursor {
+		return
+	}
+	w.cursor = shape
+	name := ""
+	switch shape {
+	case interfaces.CursorArrow:
+		name = "default"
+	case interfaces.CursorIBeam:
+		name = "text"
+	case interfaces.CursorHand:
+		name = "pointer"
+	case interfaces.CursorResizeH:
+		name = "ew-resize"
+	case interfaces.CursorResizeV:
+		name = "ns-resize"
+	case interfaces.CursorCrosshair:
+		name = "crosshair"
+	case interfaces.CursorNotAllowed:
+		name = "not-allowed"
+	}
+	w.canvas.Get("style").Set("cursor", name)
+}
@@ -0,0 +1,53 @@
+package window
+
+import (
+	"github.com/mleku/goo/pkg/interfaces"
+)
+
+// inputMethod passes the caret of the focused text on to the window's
+// callback, for the program's input method code to place the candidate
+// window at
+type inputMethod struct {
+	window *Window
+}
+
+// SetCaret records the caret, calling the callback when it moved
+func (m inputMethod) SetCaret(caret interfaces.Rect) {
+	w := m.window
+	if caret == w.inputCaret {
+		return
+	}
+	w.inputCaret = caret
+	if w.onInputCaret != nil {
+		w.onInputCaret(caret)
+	}
+}
+
+// Preedit delivers the text an input method is composing with the next
+// frame, with the rune index of the input method's caret within it, for the
+// focused text widget to show at its caret. An empty text ends the
+// composition. Nothing in this package calls it: GLFW passes on only the
+// text an input method commits, as characters, and the input method draws
+// the composition in its own window. Inline composition needs the program
+// to follow the composition itself, such as through XIM or IBus preedit
+// callbacks, IMM32 composition messages or an NSTextInputClient, and feed
+// it here from the main thread.
+func (w *Window) Preedit(text string, caret int) {
+	w.queue(interfaces.PreeditEvent{Text: text, Caret: caret})
+}
+
+// OnInputCaret sets the callback invoked with the caret of the focused text
+// in window coordinates each time it moves, and returns the window for
+// chaining. The window does not move the input method's candidate window
+// itself, as GLFW has no call for it, so the callback is where a program
+// talking to the input method places it beside the caret.
+func (w *Window) OnInputCaret(fn func(caret interfaces.Rect)) *Window {
+	w.onInputCaret = fn
+	return w
+}
+
+// InputCaret returns the caret of the focused text in window coordinates, as
+// last reported by the widgets
+func (w *Window) InputCaret() interfaces.Rect {
+	return w.inputCaret
+}
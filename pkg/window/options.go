@@ -0,0 +1,46 @@
+package window
+
+// Option configures a window created by New
+type Option func(w *Window)
+
+// Undecorated opens the window without the system's title bar and borders,
+// for windows drawing their own. Widgets marking the title bar and edges
+// let the user move and resize it through Frame.WindowDrag, except under
+// Wayland, which does not let windows place themselves.
+func Undecorated() Option {
+	return func(w *Window) { w.undecorated = true }
+}
+
+// AlwaysOnTop keeps the window above the other windows of the desktop
+func AlwaysOnTop() Option {
+	return func(w *Window) { w.alwaysOnTop = true }
+}
+
+// Transparent lets the desktop show through where the window's content is
+// transparent, for overlays and windows with rounded corners. The render
+// function clears the canvas with a transparent color, such as with the
+// root widget's SetClearColor. Systems without a compositor show black.
+func Transparent() Option {
+	return func(w *Window) { w.transparent = true }
+}
+
+// Software draws the window with the software canvas, rasterizing each
+// frame on the CPU and copying the pixels to the window, instead of with
+// the GL renderer. It suits virtual machines and remote X sessions whose GL
+// is too old for the renderer, or slow, and serves as a reference when the
+// GL renderer draws a frame wrongly. It still needs an OpenGL context, of
+// any version with glDrawPixels, to copy the pixels through; a system with
+// no OpenGL at all cannot open windows, and the headless package draws
+// frames without one. Shader effects and GL viewports are not drawn. Apps
+// fall back to it by themselves on systems with only legacy OpenGL, older
+// than 3.3 core.
+func Software() Option {
+	return func(w *Window) {
+		w.software = true
+	}
+}
+
+// IsSoftware reports whether the window is drawn with the software canvas
+func (w *Window) IsSoftware() bool {
+	return w.software
+}
@@ -0,0 +1,58 @@
+package window
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/pprof"
+
+	"lol.mleku.dev/chk"
+	"lol.mleku.dev/log"
+)
+
+// ServePprof serves the program's profiles over HTTP at an address such as
+// localhost:6060, under /debug/pprof/ as net/http/pprof serves them, until
+// called again with an empty address or the window closes. An execution
+// trace fetched from /debug/pprof/trace shows each frame as a task, with
+// regions for the layout, paint and event dispatch of the widgets, beside
+// the garbage collector's and the other goroutines' activity, so a frame
+// that took too long can be told from what held it up.
+func (w *Window) ServePprof(addr string) (err error) {
+	w.stopPprof()
+	if addr == "" {
+		return
+	}
+	var l net.Listener
+	if l, err = net.Listen("tcp", addr); chk.E(err) {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	w.pprof = &http.Server{Handler: mux}
+	w.pprofAddr = l.Addr().String()
+	go func(s *http.Server) {
+		if err := s.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+			log.E.Ln("window: pprof server:", err)
+		}
+	}(w.pprof)
+	return
+}
+
+// PprofAddr returns the address the profiles are served at, such as the
+// port chosen for an address ending in :0, empty when they are not served
+func (w *Window) PprofAddr() string {
+	return w.pprofAddr
+}
+
+// stopPprof stops serving the profiles
+func (w *Window) stopPprof() {
+	if w.pprof == nil {
+		return
+	}
+	chk.E(w.pprof.Close())
+	w.pprof, w.pprofAddr = nil, ""
+}
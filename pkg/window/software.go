@@ -0,0 +1,31 @@
+//go:build !js
+
+package window
+
+import (
+	"github.com/go-gl/gl/all-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// softwareHints requests whatever context the system offers, legacy ones
+// included, as the software canvas only copies pixels with it. There is no
+// path to the screen without OpenGL.
+func softwareHints() {
+	glfw.DefaultWindowHints()
+}
+
+// presentCanvas copies the software canvas to the window's back buffer
+// with glDrawPixels, of the compatibility profile
+func (w *Window) presentCanvas() {
+	img := w.canvas.Image()
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	if width == 0 || height == 0 {
+		return
+	}
+	// The canvas rows count from the top and the window's from the bottom
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	gl.WindowPos2i(0, int32(height))
+	gl.PixelZoom(1, -1)
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 4)
+	gl.DrawPixels(int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+}
@@ -0,0 +1,379 @@
+//go:build !js
+
+package window
+
+import (
+	"encoding/json"
+	"image"
+	"os"
+	"time"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"lol.mleku.dev/chk"
+)
+
+// State is how a window is shown
+type State int
+
+const (
+	StateNormal State = iota
+	StateMaximized
+	StateMinimized
+	StateFullscreen
+)
+
+// Monitor is a display connected to the computer
+type Monitor struct {
+	Name string
+	// Bounds is the monitor's place on the desktop in screen coordinates,
+	// and WorkArea the part of it not covered by task bars and docks
+	Bounds, WorkArea image.Rectangle
+	// DPI is the number of pixels an inch from the physical size the monitor
+	// reports, zero when it reports none
+	DPI float32
+	// Scale is the content scale the system asks for, 2 on a typical high
+	// density display
+	Scale       float32
+	RefreshRate int
+	Primary     bool
+	monitor     *glfw.Monitor
+}
+
+// Monitors returns the monitors connected, the primary one first
+func (a *App) Monitors() (monitors []Monitor, err error) {
+	if err = a.init(); chk.E(err) {
+		return
+	}
+	return connectedMonitors(), nil
+}
+
+// connectedMonitors describes the monitors connected, the primary one first
+func connectedMonitors() (monitors []Monitor) {
+	primary := glfw.GetPrimaryMonitor()
+	for _, m := range glfw.GetMonitors() {
+		monitor := describe(m)
+		monitor.Primary = m == primary
+		if monitor.Primary {
+			monitors = append([]Monitor{monitor}, monitors...)
+			continue
+		}
+		monitors = append(monitors, monitor)
+	}
+	return
+}
+
+// describe reads the details of a GLFW monitor
+func describe(m *glfw.Monitor) (monitor Monitor) {
+	monitor = Monitor{Name: m.GetName(), monitor: m}
+	x, y := m.GetPos()
+	if mode := m.GetVideoMode(); mode != nil {
+		monitor.Bounds = image.Rect(x, y, x+mode.Width, y+mode.Height)
+		monitor.RefreshRate = mode.RefreshRate
+		if mm, _ := m.GetPhysicalSize(); mm > 0 {
+			monitor.DPI = float32(mode.Width) / (float32(mm) / 25.4)
+		}
+	}
+	wx, wy, ww, wh := m.GetWorkarea()
+	monitor.WorkArea = image.Rect(wx, wy, wx+ww, wy+wh)
+	monitor.Scale, _ = m.GetContentScale()
+	return
+}
+
+// trackRefresh notes the refresh rate of the monitor showing most of the
+// window, which sets the deadline its frames have
+func (w *Window) trackRefresh() {
+	w.stats.refresh = 0
+	if m, ok := w.Monitor(); ok && m.RefreshRate > 0 {
+		w.stats.refresh = time.Second / time.Duration(m.RefreshRate)
+	}
+}
+
+// Monitor returns the monitor showing most of the window, false while it is
+// not open
+func (w *Window) Monitor() (monitor Monitor, ok bool) {
+	if w.window == nil {
+		return
+	}
+	if m := w.window.GetMonitor(); m != nil {
+		return describe(m), true
+	}
+	bounds := w.bounds()
+	area := -1
+	for _, m := range connectedMonitors() {
+		overlap := bounds.Intersect(m.Bounds)
+		if a := overlap.Dx() * overlap.Dy(); a > area {
+			monitor, area, ok = m, a, true
+		}
+	}
+	return
+}
+
+// State returns how the window is shown
+func (w *Window) State() State {
+	switch {
+	case w.window == nil:
+		return w.start
+	case w.window.GetMonitor() != nil:
+		return StateFullscreen
+	case w.window.GetAttrib(glfw.Iconified) == glfw.True:
+		return StateMinimized
+	case w.window.GetAttrib(glfw.Maximized) == glfw.True:
+		return StateMaximized
+	}
+	return StateNormal
+}
+
+// Maximize makes the window fill the work area of its monitor, or opens it
+// so when called before it opens
+func (w *Window) Maximize() {
+	if w.window == nil {
+		w.start = StateMaximized
+		return
+	}
+	if w.State() == StateFullscreen {
+		w.Restore()
+	}
+	w.window.Maximize()
+}
+
+// Minimize hides the window in the task bar or dock until restored
+func (w *Window) Minimize() {
+	if w.window == nil {
+		w.start = StateMinimized
+		return
+	}
+	w.window.Iconify()
+}
+
+// Restore returns a maximized, minimized or fullscreen window to its normal
+// place and size
+func (w *Window) Restore() {
+	if w.window == nil {
+		w.start = StateNormal
+		return
+	}
+	if w.window.GetMonitor() != nil {
+		n := w.normal
+		w.window.SetMonitor(nil, n.Min.X, n.Min.Y, n.Dx(), n.Dy(), 0)
+		return
+	}
+	w.window.Restore()
+}
+
+// Fullscreen makes the window cover a monitor in its current video mode, or
+// opens it so when called before it opens. A nil monitor is the one showing
+// most of the window, the primary monitor before it opens.
+func (w *Window) Fullscreen(monitor *Monitor) {
+	if w.window == nil {
+		w.start = StateFullscreen
+		if monitor != nil {
+			w.startMonitor = monitor.Name
+		}
+		return
+	}
+	if monitor == nil {
+		m, ok := w.Monitor()
+		if !ok {
+			return
+		}
+		monitor = &m
+	}
+	if w.State() == StateMaximized {
+		w.window.Restore()
+	}
+	mode := monitor.monitor.GetVideoMode()
+	w.window.SetMonitor(monitor.monitor, 0, 0, mode.Width, mode.Height, mode.RefreshRate)
+}
+
+// Position returns the place of the window's content on the desktop, in
+// screen coordinates
+func (w *Window) Position() (x, y int) {
+	if w.window == nil {
+		return w.x, w.y
+	}
+	return w.window.GetPos()
+}
+
+// SetPosition moves the window's content to a place on the desktop, or
+// opens it there when called before it opens. Wayland does not let windows
+// place themselves, as Platform reports.
+func (w *Window) SetPosition(x, y int) {
+	if w.window == nil {
+		w.x, w.y, w.placed = x, y, true
+		return
+	}
+	if Platform().Position {
+		w.window.SetPos(x, y)
+	}
+}
+
+// Size returns the size of the window's content in screen coordinates
+func (w *Window) Size() (width, height int) {
+	if w.window == nil {
+		return w.width, w.height
+	}
+	return w.window.GetSize()
+}
+
+// SetSize changes the size of the window's content, or opens it at that
+// size when called before it opens
+func (w *Window) SetSize(width, height int) {
+	if w.window == nil {
+		w.width, w.height = width, height
+		w.canvasWidth, w.canvasHeight = width, height
+		return
+	}
+	w.window.SetSize(width, height)
+}
+
+// Geometry is a window's place, size and state, for opening it next time
+// as the user left it
+type Geometry struct {
+	// X, Y, Width and Height are the window's place and size when neither
+	// maximized, minimized nor fullscreen
+	X, Y, Width, Height int
+	Maximized           bool
+	Fullscreen          bool
+	// Monitor names the monitor a fullscreen window covers
+	Monitor string `json:",omitempty"`
+}
+
+// Geometry returns the window's place, size and state. A minimized window
+// is opened again in its normal state.
+func (w *Window) Geometry() (g Geometry) {
+	n := w.bounds()
+	if w.window != nil && w.State() != StateNormal {
+		n = w.normal
+	}
+	g = Geometry{X: n.Min.X, Y: n.Min.Y, Width: n.Dx(), Height: n.Dy()}
+	switch w.State() {
+	case StateMaximized:
+		g.Maximized = true
+	case StateFullscreen:
+		g.Fullscreen = true
+		if m, ok := w.Monitor(); ok {
+			g.Monitor = m.Name
+		} else {
+			g.Monitor = w.startMonitor
+		}
+	}
+	return
+}
+
+// SetGeometry places, sizes and shows the window as the geometry says, or
+// opens it so when called before it opens. The place is ignored when it
+// would leave the window off every monitor, as when one was unplugged.
+func (w *Window) SetGeometry(g Geometry) {
+	if w.window == nil {
+		w.geometry = &g
+		return
+	}
+	w.Restore()
+	if g.Width > 0 && g.Height > 0 {
+		w.window.SetSize(g.Width, g.Height)
+	}
+	if Platform().Position && onScreen(image.Rect(g.X, g.Y, g.X+g.Width, g.Y+g.Height)) {
+		w.window.SetPos(g.X, g.Y)
+	}
+	w.normal = w.bounds()
+	switch {
+	case g.Fullscreen:
+		var monitor *Monitor
+		for _, m := range connectedMonitors() {
+			if m.Name == g.Monitor {
+				monitor = &m
+				break
+			}
+		}
+		w.Fullscreen(monitor)
+	case g.Maximized:
+		w.window.Maximize()
+	}
+}
+
+// RememberGeometry opens the window with the geometry saved in a file and
+// saves it there when the window closes, so it opens next time as the user
+// left it, and returns the window for chaining. A missing or unreadable file
+// leaves the window as it was configured.
+func (w *Window) RememberGeometry(path string) *Window {
+	w.geometryPath = path
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return w
+	}
+	var g Geometry
+	if err = json.Unmarshal(data, &g); chk.E(err) {
+		return w
+	}
+	w.SetGeometry(g)
+	return w
+}
+
+// saveGeometry writes the window's geometry to the file it is remembered
+// in, if any
+func (w *Window) saveGeometry() {
+	if w.geometryPath == "" {
+		return
+	}
+	data, err := json.MarshalIndent(w.Geometry(), "", "\t")
+	if chk.E(err) {
+		return
+	}
+	chk.E(os.WriteFile(w.geometryPath, data, 0o644))
+}
+
+// placeOnOpen places and shows a window that has just been created as it
+// was asked to be before it opened
+func (w *Window) placeOnOpen() {
+	if w.placed && Platform().Position && onScreen(image.Rect(w.x, w.y, w.x+w.width, w.y+w.height)) {
+		w.window.SetPos(w.x, w.y)
+	}
+	w.normal = w.bounds()
+	if g := w.geometry; g != nil {
+		w.geometry = nil
+		w.SetGeometry(*g)
+	}
+	w.window.Show()
+	switch w.start {
+	case StateMaximized:
+		w.window.Maximize()
+	case StateMinimized:
+		w.window.Iconify()
+	case StateFullscreen:
+		var monitor *Monitor
+		for _, m := range connectedMonitors() {
+			if m.Name == w.startMonitor || (w.startMonitor == "" && m.Primary) {
+				monitor = &m
+				break
+			}
+		}
+		w.Fullscreen(monitor)
+	}
+	w.start = StateNormal
+}
+
+// trackNormal records the window's place and size while it is shown
+// normally, for restoring and remembering it
+func (w *Window) trackNormal() {
+	if w.State() == StateNormal {
+		w.normal = w.bounds()
+	}
+}
+
+// bounds returns the window's content on the desktop
+func (w *Window) bounds() image.Rectangle {
+	x, y := w.Position()
+	width, height := w.Size()
+	return image.Rect(x, y, x+width, y+height)
+}
+
+// onScreen reports whether part of a rectangle on the desktop lies on a
+// monitor
+func onScreen(r image.Rectangle) bool {
+	for _, m := range connectedMonitors() {
+		if r.Overlaps(m.WorkArea) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package window
+
+import (
+	"slices"
+	"time"
+
+	"github.com/mleku/goo/pkg/interfaces"
+)
+
+// statsFrames is how many frames the frame statistics are kept for
+const statsFrames = 240
+
+// frameStats records when recent frames started and how long they took in
+// rings of statsFrames entries
+type frameStats struct {
+	starts []time.Time
+	times  []time.Duration
+	// next is the index the next frame is recorded at once the rings are full
+	next int
+	// refresh is the time between refreshes of the window's display, zero
+	// when unknown
+	refresh time.Duration
+}
+
+// record adds a frame that started at a time and took a duration to draw
+func (s *frameStats) record(start time.Time, took time.Duration) {
+	if len(s.times) < statsFrames {
+		s.starts = append(s.starts, start)
+		s.times = append(s.times, took)
+		return
+	}
+	s.starts[s.next], s.times[s.next] = start, took
+	s.next = (s.next + 1) % statsFrames
+}
+
+// stats summarizes the recorded frames as of a time
+func (s *frameStats) stats(now time.Time) (st interfaces.FrameStats) {
+	st.Refresh = s.refresh
+	n := len(s.times)
+	if n == 0 {
+		return
+	}
+	st.Recent = make([]time.Duration, 0, n)
+	st.Recent = append(st.Recent, s.times[s.next:]...)
+	st.Recent = append(st.Recent, s.times[:s.next]...)
+	st.FrameTime = st.Recent[n-1]
+	for _, start := range s.starts {
+		if now.Sub(start) < time.Second {
+			st.FPS++
+		}
+	}
+	sorted := slices.Clone(st.Recent)
+	slices.Sort(sorted)
+	st.P50 = percentile(sorted, 50)
+	st.P95 = percentile(sorted, 95)
+	st.P99 = percentile(sorted, 99)
+	return
+}
+
+// percentile returns the nearest rank percentile of sorted durations
+func percentile(sorted []time.Duration, p int) time.Duration {
+	i := (len(sorted)*p + 99) / 100
+	return sorted[max(i-1, 0)]
+}
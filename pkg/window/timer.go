@@ -0,0 +1,86 @@
+package window
+
+import (
+	"slices"
+	"time"
+)
+
+// Timer is a callback a window runs at a later frame, once or repeatedly
+type Timer struct {
+	w  *Window
+	at time.Time
+	// interval is the time between runs of a repeating timer, zero for one
+	// that runs once
+	interval time.Duration
+	fn       func()
+}
+
+// After runs a function on the main goroutine at the start of the first
+// frame at least a duration from now, waking the window to draw it. It is
+// for work tied to the window without a goroutine of its own, such as
+// closing a notification after a while, and must be called from the main
+// goroutine; other goroutines use RunOnUIThread.
+func (w *Window) After(d time.Duration, fn func()) *Timer {
+	return w.schedule(&Timer{w: w, at: time.Now().Add(d), fn: fn})
+}
+
+// Every runs a function on the main goroutine at the start of a frame each
+// interval until stopped, waking the window to draw it, such as for polling
+// or saving automatically. Runs the window missed while busy are skipped
+// rather than made up. Like After it must be called from the main goroutine.
+func (w *Window) Every(interval time.Duration, fn func()) *Timer {
+	interval = max(interval, time.Millisecond)
+	return w.schedule(&Timer{w: w, at: time.Now().Add(interval), interval: interval, fn: fn})
+}
+
+// Stop stops the timer from running again, reporting whether it was still
+// scheduled
+func (t *Timer) Stop() (stopped bool) {
+	n := len(t.w.timers)
+	t.w.timers = slices.DeleteFunc(t.w.timers, func(o *Timer) bool { return o == t })
+	return len(t.w.timers) < n
+}
+
+// Reset schedules the timer to run a duration from now, again if it already
+// ran, and repeating at its interval afterwards if it repeats
+func (t *Timer) Reset(d time.Duration) {
+	t.Stop()
+	t.at = time.Now().Add(d)
+	t.w.schedule(t)
+}
+
+// schedule adds a timer and asks the clock to wake the window for it
+func (w *Window) schedule(t *Timer) *Timer {
+	w.timers = append(w.timers, t)
+	if w.clock != nil {
+		w.clock.WakeAt(t.at)
+	}
+	return t
+}
+
+// runTimers runs the timers due by a frame's time, rescheduling those that
+// repeat, and asks the clock to wake the window for the next
+func (w *Window) runTimers(now time.Time) {
+	var due []*Timer
+	w.timers = slices.DeleteFunc(w.timers, func(t *Timer) bool {
+		if t.at.After(now) {
+			return false
+		}
+		due = append(due, t)
+		return true
+	})
+	// Callbacks may schedule or stop other timers, or their own
+	for _, t := range due {
+		if t.interval > 0 {
+			t.at = t.at.Add(t.interval)
+			if !t.at.After(now) {
+				t.at = now.Add(t.interval)
+			}
+			w.timers = append(w.timers, t)
+		}
+		t.fn()
+	}
+	for _, t := range w.timers {
+		w.clock.WakeAt(t.at)
+	}
+}
@@ -0,0 +1,158 @@
+package window
+
+import (
+	"errors"
+	"image"
+	"syscall/js"
+
+	"lol.mleku.dev/log"
+)
+
+// ErrNoWebGL is returned when opening a window in a browser that cannot
+// draw WebGL into its canvas
+var ErrNoWebGL = errors.New("window: WebGL is not available")
+
+// The WebGL enums the presenter uses
+const (
+	glTexture2D        = 0x0DE1
+	glRGBA             = 0x1908
+	glUnsignedByte     = 0x1401
+	glFloat            = 0x1406
+	glTriangles        = 0x0004
+	glArrayBuffer      = 0x8892
+	glStaticDraw       = 0x88E4
+	glVertexShader     = 0x8B31
+	glFragmentShader   = 0x8B30
+	glCompileStatus    = 0x8B81
+	glLinkStatus       = 0x8B82
+	glTextureMinFilter = 0x2801
+	glTextureMagFilter = 0x2800
+	glTextureWrapS     = 0x2802
+	glTextureWrapT     = 0x2803
+	glNearest          = 0x2600
+	glClampToEdge      = 0x812F
+)
+
+// presentVertexShader covers the canvas with the texture, whose rows count
+// from the top
+const presentVertexShader = `
+attribute vec2 position;
+varying vec2 uv;
+void main() {
+	uv = vec2(position.x + 1.0, 1.0 - position.y) * 0.5;
+	gl_Position = vec4(position, 0.0, 1.0);
+}
+`
+
+// presentFragmentShader copies the texture's pixels
+const presentFragmentShader = `
+precision mediump float;
+varying vec2 uv;
+uniform sampler2D tex;
+void main() {
+	gl_FragColor = texture2D(tex, uv);
+}
+`
+
+// presenter shows the pixels of the software canvas in a canvas element
+// with WebGL, uploading them as a texture drawn over the whole element
+type presenter struct {
+	gl      js.Value
+	program js.Value
+	buffer  js.Value
+	texture js.Value
+	// width and height are the texture's size, and pixels the typed array
+	// its pixels are copied through
+	width, height int
+	pixels        js.Value
+}
+
+// newPresenter creates the WebGL context of a canvas element and what it
+// draws the canvas's pixels with
+func newPresenter(canvas js.Value) (p *presenter, err error) {
+	gl := canvas.Call("getContext", "webgl", map[string]any{
+		"alpha":     false,
+		"depth":     false,
+		"stencil":   false,
+		"antialias": false,
+	})
+	if !gl.Truthy() {
+		return nil, ErrNoWebGL
+	}
+	p = &presenter{gl: gl}
+	if p.program, err = p.link(); err != nil {
+		return nil, err
+	}
+	p.buffer = gl.Call("createBuffer")
+	gl.Call("bindBuffer", glArrayBuffer, p.buffer)
+	quad := js.Global().Get("Float32Array").New(js.ValueOf([]any{-1, -1, 1, -1, -1, 1, -1, 1, 1, -1, 1, 1}))
+	gl.Call("bufferData", glArrayBuffer, quad, glStaticDraw)
+	position := gl.Call("getAttribLocation", p.program, "position")
+	gl.Call("enableVertexAttribArray", position)
+	gl.Call("vertexAttribPointer", position, 2, glFloat, false, 0, 0)
+	p.texture = gl.Call("createTexture")
+	gl.Call("bindTexture", glTexture2D, p.texture)
+	// The canvas is as many pixels as the element covers, so no filtering
+	// is needed, and WebGL draws no textures of other sizes repeated
+	gl.Call("texParameteri", glTexture2D, glTextureMinFilter, glNearest)
+	gl.Call("texParameteri", glTexture2D, glTextureMagFilter, glNearest)
+	gl.Call("texParameteri", glTexture2D, glTextureWrapS, glClampToEdge)
+	gl.Call("texParameteri", glTexture2D, glTextureWrapT, glClampToEdge)
+	gl.Call("useProgram", p.program)
+	gl.Call("uniform1i", gl.Call("getUniformLocation", p.program, "tex"), 0)
+	return
+}
+
+// link compiles and links the program drawing the texture
+func (p *presenter) link() (program js.Value, err error) {
+	gl := p.gl
+	program = gl.Call("createProgram")
+	for _, s := range []struct {
+		kind   int
+		source string
+	}{{glVertexShader, presentVertexShader}, {glFragmentShader, presentFragmentShader}} {
+		shader := gl.Call("createShader", s.kind)
+		gl.Call("shaderSource", shader, s.source)
+		gl.Call("compileShader", shader)
+		if !gl.Call("getShaderParameter", shader, glCompileStatus).Bool() {
+			log.E.Ln("shader compile:", gl.Call("getShaderInfoLog", shader).String())
+			return js.Value{}, ErrNoWebGL
+		}
+		gl.Call("attachShader", program, shader)
+		gl.Call("deleteShader", shader)
+	}
+	gl.Call("linkProgram", program)
+	if !gl.Call("getProgramParameter", program, glLinkStatus).Bool() {
+		log.E.Ln("program link:", gl.Call("getProgramInfoLog", program).String())
+		return js.Value{}, ErrNoWebGL
+	}
+	return
+}
+
+// present uploads an image to the texture and draws it over the canvas
+func (p *presenter) present(img *image.RGBA) {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	if width == 0 || height == 0 {
+		return
+	}
+	gl := p.gl
+	if p.pixels.IsUndefined() || p.pixels.Get("length").Int() != len(img.Pix) {
+		p.pixels = js.Global().Get("Uint8Array").New(len(img.Pix))
+	}
+	js.CopyBytesToJS(p.pixels, img.Pix)
+	if width != p.width || height != p.height {
+		gl.Call("texImage2D", glTexture2D, 0, glRGBA, width, height, 0, glRGBA, glUnsignedByte, p.pixels)
+		p.width, p.height = width, height
+	} else {
+		gl.Call("texSubImage2D", glTexture2D, 0, 0, 0, width, height, glRGBA, glUnsignedByte, p.pixels)
+	}
+	gl.Call("viewport", 0, 0, width, height)
+	gl.Call("drawArrays", glTriangles, 0, 6)
+}
+
+// delete frees the WebGL objects
+func (p *presenter) delete() {
+	p.gl.Call("deleteTexture", p.texture)
+	p.gl.Call("deleteBuffer", p.buffer)
+	p.gl.Call("deleteProgram", p.program)
+}
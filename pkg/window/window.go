@@ -1,15 +1,25 @@
+//go:build !js
+
 package window
 
 import (
+	"context"
+	"image"
+	"net/http"
 	"runtime"
+	"runtime/trace"
+	"time"
 
 	"github.com/go-gl/gl/all-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/mleku/goo/pkg/anim"
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/render"
 	"lol.mleku.dev/chk"
-	"lol.mleku.dev/log"
 )
 
-// Window manages the OpenGL window and application lifecycle
+// Window manages an OpenGL window and the frames drawn in it, run on its own
+// or alongside other windows by an App
 type Window struct {
 	width            int
 	height           int
@@ -24,14 +34,76 @@ type Window struct {
 	mouseX           float64
 	mouseY           float64
 	cursorInWindow   bool
+	// events queued by the GLFW callbacks since the last frame
+	events []interfaces.Event
+	// frame holds the rendered canvas between frames
+	frame frameBuffer
+	// renderer submits the draw list painted each frame
+	renderer *render.Renderer
+	drawList *render.DrawList
+	// software is set for windows drawn by canvas on the CPU, with neither
+	// renderer nor frame
+	software bool
+	canvas   *render.Canvas
+	// backend is the display system required by RequireBackend
+	backend Backend
+	// clock paces animations and decides when the next frame is drawn
+	clock *anim.Clock
+	// cursors shows the cursor shape the render function asks for
+	cursors cursors
+	// app runs the window's main loop while it is open, calling renderFunc
+	// each frame
+	app        *App
+	renderFunc RenderFunc
+	// vsync is whether swaps wait for the vertical blank, applied to the
+	// context when vsyncSet is set and vsyncApplied is not
+	vsync, vsyncSet, vsyncApplied bool
+	// interval is the shortest time between frames, zero for no limit
+	interval time.Duration
+	// lastFrame is when the last frame started
+	lastFrame time.Time
+	stats     frameStats
+	// pprof serves the program's profiles at pprofAddr, nil unless asked to
+	pprof     *http.Server
+	pprofAddr string
+	// inputCaret is the caret of the focused text, passed to onInputCaret
+	// when it moves
+	inputCaret   interfaces.Rect
+	onInputCaret func(caret interfaces.Rect)
+	// undecorated, alwaysOnTop and transparent are the window's chrome,
+	// set by the options it was created with
+	undecorated, alwaysOnTop, transparent bool
+	// drag is the move or resize of the window in progress, its area
+	// AreaClient when there is none
+	drag windowDrag
+	// x and y place the window when it opens if placed is set, and start,
+	// startMonitor and geometry are how it is shown then
+	x, y         int
+	placed       bool
+	start        State
+	startMonitor string
+	geometry     *Geometry
+	// normal is the window's place and size when last shown normally, and
+	// geometryPath the file it is remembered in, empty for none
+	normal       image.Rectangle
+	geometryPath string
+	// onCloseRequest decides whether the window closes when the user asks,
+	// and onClose releases what the render function holds as it closes
+	onCloseRequest func() bool
+	onClose        func()
+	// invoke queues the calls other goroutines hand to the window, run at
+	// the start of the next frame
+	invoke uiCalls
+	// timers are the callbacks scheduled by After and Every
+	timers []*Timer
 }
 
 func init() {
 	runtime.LockOSThread()
 }
 
-// New creates a new window with the given configuration
-func New(width, height int, title string) (w *Window, err error) {
+// New creates a new window with the given size, title and options
+func New(width, height int, title string, options ...Option) (w *Window, err error) {
 	w = &Window{
 		width:            width,
 		height:           height,
@@ -41,117 +113,360 @@ func New(width, height int, title string) (w *Window, err error) {
 		resizeThreshold:  8,
 		skipResizeFrames: true,
 	}
+	for _, option := range options {
+		option(w)
+	}
 	return
 }
 
-// Run starts the window and runs the application main loop
-func (w *Window) Run(renderFunc func(windowWidth, windowHeight int, mouseX, mouseY float64, cursorInWindow bool) error) (err error) {
-	if err = glfw.Init(); chk.E(err) {
-		return
-	}
-	defer glfw.Terminate()
+// VSync sets whether buffer swaps wait for the display's vertical blank,
+// which limits the frame rate to the display's refresh rate and stops
+// tearing, and returns the window for chaining. The driver's default is kept
+// unless it is set. Each window waits for its own swaps, so windows drawn
+// together all with vsync share the refresh rate between them.
+func (w *Window) VSync(on bool) *Window {
+	w.vsync, w.vsyncSet, w.vsyncApplied = on, true, false
+	return w
+}
 
-	glfw.WindowHint(glfw.ContextVersionMajor, 2)
-	glfw.WindowHint(glfw.ContextVersionMinor, 1)
-	// Don't set OpenGLProfile - use compatibility profile for immediate mode
+// open creates the GLFW window with a context sharing objects with share,
+// and the resources it draws with
+func (w *Window) open(share *glfw.Window, renderFunc RenderFunc) (err error) {
+	contextHints()
+	if w.software {
+		// The canvas keeps its textures in memory, leaving nothing to share
+		softwareHints()
+		share = nil
+	}
 	glfw.WindowHint(glfw.Resizable, glfw.True)
+	w.chromeHints()
+	// The window is shown once placed as asked
+	glfw.WindowHint(glfw.Visible, glfw.False)
 
-	w.window, err = glfw.CreateWindow(w.width, w.height, w.title, nil, nil)
+	w.window, err = glfw.CreateWindow(w.width, w.height, w.title, nil, share)
 	if chk.E(err) {
 		return
 	}
-	defer w.window.Destroy()
 
 	w.window.MakeContextCurrent()
 
-	if err = gl.Init(); chk.E(err) {
-		return
-	}
-
-	// Set the viewport
-	gl.Viewport(0, 0, int32(w.width), int32(w.height))
-
-	// Enable scissor test for clipping
-	gl.Enable(gl.SCISSOR_TEST)
-
-	// Initialize canvas dimensions
+	// Initialize canvas dimensions and set the viewport to match
 	w.canvasWidth, w.canvasHeight = w.window.GetFramebufferSize()
+	gl.Viewport(0, 0, int32(w.canvasWidth), int32(w.canvasHeight))
+
+	w.drawList = render.NewDrawList()
+	w.clock = anim.NewClock()
+	w.queue(interfaces.ExposeEvent{})
+	if w.software {
+		// The canvas persists between frames as the offscreen frame does
+		w.canvas = render.NewCanvas(w.canvasWidth, w.canvasHeight)
+	} else {
+		// Create the batched renderer widgets paint through. Vertex arrays
+		// are not shared between contexts so each window has its own.
+		if w.renderer, err = render.NewRenderer(); chk.E(err) {
+			w.window.Destroy()
+			w.window = nil
+			return
+		}
+		// Render into an offscreen canvas that persists between frames
+		w.frame.resize(w.canvasWidth, w.canvasHeight)
+	}
 
-	// Set mouse cursor position callback
+	// Queue input events for dispatch on the next frame
 	w.window.SetCursorPosCallback(func(window *glfw.Window, xpos, ypos float64) {
+		// The widgets see nothing of a drag moving or resizing the window
+		if w.dragging() {
+			w.dragTo(xpos, ypos)
+			return
+		}
 		w.mouseX = xpos
 		w.mouseY = ypos
-		log.D.Ln("Cursor position:", xpos, ypos)
+		w.queue(interfaces.MouseMoveEvent{Position: w.mousePoint()})
 	})
 
-	// Set keyboard callback
 	w.window.SetKeyCallback(func(window *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
-		log.D.Ln("Key event: key=", key, "scancode=", scancode, "action=", action, "mods=", mods)
+		w.queue(interfaces.KeyEvent{
+			Key:      interfaces.Key(key),
+			Scancode: scancode,
+			Action:   convertAction(action),
+			Mods:     convertMods(mods),
+		})
 	})
 
-	// Set mouse button callback
 	w.window.SetMouseButtonCallback(func(window *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
-		log.D.Ln("Mouse button: button=", button, "action=", action, "mods=", mods)
+		if w.dragging() && button == glfw.MouseButtonLeft && action == glfw.Release {
+			w.drag = windowDrag{}
+		}
+		w.queue(interfaces.MouseButtonEvent{
+			Position: w.mousePoint(),
+			Button:   interfaces.MouseButton(button),
+			Action:   convertAction(action),
+			Mods:     convertMods(mods),
+		})
 	})
 
-	// Set scroll callback
 	w.window.SetScrollCallback(func(window *glfw.Window, xoffset, yoffset float64) {
-		log.D.Ln("Scroll: xoffset=", xoffset, "yoffset=", yoffset)
+		w.queue(interfaces.ScrollEvent{
+			Position: w.mousePoint(),
+			Offset:   interfaces.Point{X: float32(xoffset), Y: float32(yoffset)},
+		})
 	})
 
-	// Set character input callback
 	w.window.SetCharCallback(func(window *glfw.Window, char rune) {
-		log.D.Ln("Character input:", string(char))
+		w.queue(interfaces.CharEvent{Char: char})
+	})
+
+	w.window.SetDropCallback(func(window *glfw.Window, names []string) {
+		// The cursor is not tracked while the files are dragged over the
+		// window, so read where they were dropped
+		w.mouseX, w.mouseY = window.GetCursorPos()
+		w.queue(interfaces.FileDropEvent{Position: w.mousePoint(), Paths: names})
+	})
+
+	w.window.SetFocusCallback(func(window *glfw.Window, focused bool) {
+		// Other programs may have changed the clipboard while the window
+		// was in the background
+		if focused && w.app != nil {
+			w.app.clipboardChanged()
+		}
 	})
 
-	// Set cursor enter/leave callback
 	w.window.SetCursorEnterCallback(func(window *glfw.Window, entered bool) {
 		w.cursorInWindow = entered
 		if entered {
-			log.D.Ln("Cursor entered window")
+			w.queue(interfaces.CursorEnterEvent{})
 		} else {
-			log.D.Ln("Cursor left window")
+			w.queue(interfaces.CursorLeaveEvent{})
 		}
 	})
 
-	w.running = true
-	for !w.window.ShouldClose() && w.running {
-		// Get window size (logical size in screen coordinates)
-		windowWidth, windowHeight := w.window.GetSize()
+	w.window.SetCloseCallback(func(window *glfw.Window) {
+		if !w.mayClose() {
+			window.SetShouldClose(false)
+		}
+	})
 
-		// Get framebuffer/canvas size (actual rendering surface)
-		canvasWidth, canvasHeight := w.window.GetFramebufferSize()
+	w.window.SetPosCallback(func(window *glfw.Window, x, y int) {
+		w.trackNormal()
+		w.trackRefresh()
+	})
 
-		// Increment frame counter
-		w.frameCount++
+	w.window.SetSizeCallback(func(window *glfw.Window, width, height int) {
+		w.trackNormal()
+	})
 
-		// Update viewport if canvas size changed
-		if canvasWidth != w.canvasWidth || canvasHeight != w.canvasHeight {
-			gl.Viewport(0, 0, int32(canvasWidth), int32(canvasHeight))
-			w.canvasWidth = canvasWidth
-			w.canvasHeight = canvasHeight
-		}
+	w.placeOnOpen()
+	w.trackRefresh()
+	w.renderFunc = renderFunc
+	w.running = true
+	w.invoke.setClosed(false)
+	return
+}
 
-		// Render with window dimensions and mouse position
-		if err = renderFunc(windowWidth, windowHeight, w.mouseX, w.mouseY, w.cursorInWindow); chk.E(err) {
-			return
+// draw renders a frame of the window with its context current
+func (w *Window) draw(now time.Time) (err error) {
+	ctx, task := trace.NewTask(context.Background(), "frame")
+	defer task.End()
+	w.window.MakeContextCurrent()
+	if w.vsyncSet && !w.vsyncApplied {
+		// The swap interval belongs to the current context
+		interval := 0
+		if w.vsync {
+			interval = 1
 		}
+		glfw.SwapInterval(interval)
+		w.vsyncApplied = true
+	}
+	w.lastFrame = now
 
-		w.window.SwapBuffers()
+	// Get window size (logical size in screen coordinates)
+	windowWidth, windowHeight := w.window.GetSize()
+
+	// Get framebuffer/canvas size (actual rendering surface)
+	canvasWidth, canvasHeight := w.window.GetFramebufferSize()
+
+	// Increment frame counter
+	w.frameCount++
+
+	// Update viewport and offscreen canvas if canvas size changed
+	if canvasWidth != w.canvasWidth || canvasHeight != w.canvasHeight {
+		gl.Viewport(0, 0, int32(canvasWidth), int32(canvasHeight))
+		w.canvasWidth = canvasWidth
+		w.canvasHeight = canvasHeight
+		if w.software {
+			w.canvas.Resize(canvasWidth, canvasHeight)
+		} else {
+			w.frame.resize(canvasWidth, canvasHeight)
+		}
+		w.queue(interfaces.ExposeEvent{})
+	}
 
-		glfw.PollEvents()
+	// Calls from other goroutines and timers change what the frame shows
+	w.clock.Advance(now)
+	w.runQueued()
+	w.runTimers(now)
 
+	// Render with window dimensions, mouse position and queued events
+	frame := &Frame{
+		Width:          windowWidth,
+		Height:         windowHeight,
+		MouseX:         w.mouseX,
+		MouseY:         w.mouseY,
+		CursorInWindow: w.cursorInWindow,
+		Events:         w.events,
+		DrawList:       w.drawList,
+		Clipboard:      w.app.clipboard,
+		Clock:          w.clock,
+		Stats:          w.stats.stats(now),
+		Scale:          1,
+		InputMethod:    inputMethod{w},
+	}
+	if windowWidth > 0 {
+		frame.Scale = float32(canvasWidth) / float32(windowWidth)
+	}
+	// The frame keeps its events, so the next frame's queue in a new slice
+	w.events = nil
+	region := trace.StartRegion(ctx, "render")
+	err = w.renderFunc(frame)
+	region.End()
+	if chk.E(err) {
+		return
 	}
+	w.cursors.apply(w.window, frame.Cursor)
+	w.beginDrag(frame.WindowDrag)
+	region = trace.StartRegion(ctx, "present")
+	if w.software {
+		w.canvas.Flush(w.drawList, windowWidth, windowHeight)
+		w.presentCanvas()
+	} else {
+		w.frame.bind()
+		w.renderer.Flush(w.drawList, windowWidth, windowHeight)
+		w.frame.present()
+	}
+	region.End()
+	w.stats.record(now, time.Since(now))
 
+	trace.WithRegion(ctx, "swap", w.window.SwapBuffers)
 	return
 }
 
-// Stop stops the main loop
+// close frees the window's resources and destroys the GLFW window
+func (w *Window) close() {
+	w.saveGeometry()
+	w.window.MakeContextCurrent()
+	if w.onClose != nil {
+		w.onClose()
+	}
+	w.cursors.destroy()
+	w.stopPprof()
+	if w.software {
+		w.canvas = nil
+	} else {
+		w.frame.delete()
+		w.renderer.Delete()
+	}
+	w.window.Destroy()
+	w.window = nil
+	w.running = false
+	// Calls handed over from now on have nothing to run them
+	w.invoke.setClosed(true)
+	// A new context starts with the driver's swap interval
+	w.vsyncApplied = false
+}
+
+// Stop closes the window without asking the close request callback, ending
+// the main loop if it is the last one open
 func (w *Window) Stop() {
 	w.running = false
+	postEmptyEvent()
+}
+
+// Hide hides the window while leaving it open, as programs with an icon in
+// the system tray do to keep running in the background. The app keeps
+// running while every window is hidden.
+func (w *Window) Hide() {
+	if w.window != nil {
+		w.window.Hide()
+	}
+}
+
+// Show shows a hidden or minimized window again and gives it focus
+func (w *Window) Show() {
+	if w.window == nil {
+		return
+	}
+	if w.window.GetAttrib(glfw.Iconified) == glfw.True {
+		w.window.Restore()
+	}
+	w.window.Show()
+	w.window.Focus()
+	w.clock.Request()
 }
 
-// GetWindow returns the underlying GLFW window
+// Visible reports whether the window is open and shown
+func (w *Window) Visible() bool {
+	return w.window != nil && w.window.GetAttrib(glfw.Visible) == glfw.True
+}
+
+// RunOnUIThread runs a function on the main goroutine at the start of the
+// window's next frame, before the render function, and wakes the window to
+// draw it. It is safe to call from any goroutine, so work done in the
+// background, such as network requests, can update widgets and state with
+// the result. Calls run in the order they were made, and never block the
+// caller. Calls made once the window has closed are dropped, as nothing is
+// left to run them. It suits the Post option of the dialogs and notify
+// packages.
+func (w *Window) RunOnUIThread(fn func()) {
+	if w.invoke.push(fn) {
+		postEmptyEvent()
+	}
+}
+
+// Wake draws a new frame while the window is waiting for input. It is safe to
+// call from any goroutine, for example after changing state the render
+// function shows, and does nothing once the window has closed.
+func (w *Window) Wake() {
+	if !w.invoke.isClosed() {
+		postEmptyEvent()
+	}
+}
+
+// GetWindow returns the underlying GLFW window, nil while it is not open
 func (w *Window) GetWindow() *glfw.Window {
 	return w.window
 }
+
+// convertAction maps a GLFW action to the widget event action
+func convertAction(action glfw.Action) interfaces.Action {
+	switch action {
+	case glfw.Press:
+		return interfaces.ActionPress
+	case glfw.Repeat:
+		return interfaces.ActionRepeat
+	default:
+		return interfaces.ActionRelease
+	}
+}
+
+// convertMods maps GLFW modifier bits to widget event modifiers
+func convertMods(mods glfw.ModifierKey) (m interfaces.Modifier) {
+	if mods&glfw.ModShift != 0 {
+		m |= interfaces.ModShift
+	}
+	if mods&glfw.ModControl != 0 {
+		m |= interfaces.ModControl
+	}
+	if mods&glfw.ModAlt != 0 {
+		m |= interfaces.ModAlt
+	}
+	if mods&glfw.ModSuper != 0 {
+		m |= interfaces.ModSuper
+	}
+	if mods&glfw.ModCapsLock != 0 {
+		m |= interfaces.ModCapsLock
+	}
+	if mods&glfw.ModNumLock != 0 {
+		m |= interfaces.ModNumLock
+	}
+	return
+}
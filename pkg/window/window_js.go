@@ -0,0 +1,310 @@
+package window
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"runtime/trace"
+	"strconv"
+	"syscall/js"
+	"time"
+
+	"github.com/mleku/goo/pkg/anim"
+	"github.com/mleku/goo/pkg/interfaces"
+	"github.com/mleku/goo/pkg/render"
+	"lol.mleku.dev/chk"
+)
+
+// Window manages a canvas element of the web page and the frames drawn in
+// it, run on its own or alongside other windows by an App. Frames are
+// rasterized by the software canvas and shown through WebGL, so shader
+// effects and GL viewports are not drawn, and the page's input arrives as
+// the same events the desktop windows deliver.
+type Window struct {
+	width, height int
+	title         string
+	// element is the id of the canvas element shown in, and created whether
+	// the window made it, to be removed as it closes
+	element string
+	canvas  js.Value
+	created bool
+	running bool
+	// canvasWidth and canvasHeight are the canvas's size in pixels, which
+	// the page lays out in CSS pixels
+	canvasWidth    int
+	canvasHeight   int
+	mouseX         float64
+	mouseY         float64
+	cursorInWindow bool
+	// events queued by the page's listeners since the last frame
+	events []interfaces.Event
+	// image is rasterized into each frame and presented by webGL
+	image    *render.Canvas
+	webGL    *presenter
+	drawList *render.DrawList
+	// clock paces animations and decides when the next frame is drawn
+	clock *anim.Clock
+	// cursor is the cursor shape shown over the canvas
+	cursor interfaces.Cursor
+	// app runs the window's main loop while it is open, calling renderFunc
+	// each frame
+	app        *App
+	renderFunc RenderFunc
+	// interval is the shortest time between frames, zero for no limit
+	interval time.Duration
+	// lastFrame is when the last frame started
+	lastFrame time.Time
+	stats     frameStats
+	// pprof serves the program's profiles at pprofAddr, nil unless asked to
+	pprof     *http.Server
+	pprofAddr string
+	// inputCaret is the caret of the focused text, passed to onInputCaret
+	// when it moves
+	inputCaret   interfaces.Rect
+	onInputCaret func(caret interfaces.Rect)
+	// undecorated, alwaysOnTop, transparent, software and backend are set
+	// by options meaningful on the desktop, and kept so the same options
+	// build for the page
+	undecorated, alwaysOnTop, transparent, software bool
+	backend                                         Backend
+	// onCloseRequest decides whether the window closes when asked, and
+	// onClose releases what the render function holds as it closes
+	onCloseRequest func() bool
+	onClose        func()
+	// invoke queues the calls other goroutines hand to the window, run at
+	// the start of the next frame
+	invoke uiCalls
+	// timers are the callbacks scheduled by After and Every
+	timers []*Timer
+	// listeners are the page's event handlers, removed as the window closes
+	listeners []listener
+}
+
+// New creates a new window with the given size in CSS pixels, title and
+// options. The window creates a canvas element of that size at the end of
+// the page unless CanvasElement names one the page laid out; the title
+// becomes the page's.
+func New(width, height int, title string, options ...Option) (w *Window, err error) {
+	w = &Window{
+		width:        width,
+		height:       height,
+		title:        title,
+		canvasWidth:  width,
+		canvasHeight: height,
+		software:     true,
+	}
+	for _, option := range options {
+		option(w)
+	}
+	return
+}
+
+// CanvasElement shows the window in the canvas element of the page with an
+// id, sized as the page lays it out, rather than in one created for it
+func CanvasElement(id string) Option {
+	return func(w *Window) { w.element = id }
+}
+
+// VSync returns the window for chaining; browsers always draw in step with
+// the display
+func (w *Window) VSync(on bool) *Window {
+	return w
+}
+
+// open binds the canvas element and the resources the window draws with
+func (w *Window) open(renderFunc RenderFunc) (err error) {
+	document := js.Global().Get("document")
+	if w.element != "" {
+		w.canvas = document.Call("getElementById", w.element)
+	}
+	if !w.canvas.Truthy() {
+		w.canvas = document.Call("createElement", "canvas")
+		style := w.canvas.Get("style")
+		style.Set("width", strconv.Itoa(w.width)+"px")
+		style.Set("height", strconv.Itoa(w.height)+"px")
+		style.Set("display", "block")
+		document.Get("body").Call("appendChild", w.canvas)
+		w.created = true
+	}
+	if w.title != "" {
+		document.Set("title", w.title)
+	}
+	// Take the keyboard, and touches from the page's own scrolling and zooming
+	w.canvas.Set("tabIndex", 0)
+	w.canvas.Get("style").Set("touchAction", "none")
+	w.canvas.Get("style").Set("outline", "none")
+	if w.webGL, err = newPresenter(w.canvas); chk.E(err) {
+		w.release()
+		return
+	}
+	w.canvasWidth, w.canvasHeight = w.pixelSize()
+	w.canvas.Set("width", w.canvasWidth)
+	w.canvas.Set("height", w.canvasHeight)
+	w.image = render.NewCanvas(w.canvasWidth, w.canvasHeight)
+	w.drawList = render.NewDrawList()
+	w.clock = anim.NewClock()
+	w.queue(interfaces.ExposeEvent{})
+	w.listen()
+	w.renderFunc = renderFunc
+	w.running = true
+	w.invoke.setClosed(false)
+	return
+}
+
+// size returns the canvas's size in CSS pixels as the page lays it out
+func (w *Window) size() (width, height int) {
+	return w.canvas.Get("clientWidth").Int(), w.canvas.Get("clientHeight").Int()
+}
+
+// pixelSize returns the number of device pixels the canvas covers
+func (w *Window) pixelSize() (width, height int) {
+	ratio := js.Global().Get("devicePixelRatio").Float()
+	if ratio <= 0 {
+		ratio = 1
+	}
+	cw, ch := w.size()
+	return int(math.Round(float64(cw) * ratio)), int(math.Round(float64(ch) * ratio))
+}
+
+// draw renders a frame of the window
+func (w *Window) draw(now time.Time) (err error) {
+	ctx, task := trace.NewTask(context.Background(), "frame")
+	defer task.End()
+	w.lastFrame = now
+	windowWidth, windowHeight := w.size()
+	canvasWidth, canvasHeight := w.pixelSize()
+	if canvasWidth != w.canvasWidth || canvasHeight != w.canvasHeight {
+		w.canvasWidth, w.canvasHeight = canvasWidth, canvasHeight
+		w.canvas.Set("width", canvasWidth)
+		w.canvas.Set("height", canvasHeight)
+		w.image.Resize(canvasWidth, canvasHeight)
+		w.queue(interfaces.ExposeEvent{})
+	}
+
+	// Calls from other goroutines and timers change what the frame shows
+	w.clock.Advance(now)
+	w.runQueued()
+	w.runTimers(now)
+
+	frame := &Frame{
+		Width:          windowWidth,
+		Height:         windowHeight,
+		MouseX:         w.mouseX,
+		MouseY:         w.mouseY,
+		CursorInWindow: w.cursorInWindow,
+		Events:         w.events,
+		DrawList:       w.drawList,
+		Clipboard:      w.app.clipboard,
+		Clock:          w.clock,
+		Stats:          w.stats.stats(now),
+		Scale:          1,
+		InputMethod:    inputMethod{w},
+	}
+	if windowWidth > 0 {
+		frame.Scale = float32(canvasWidth) / float32(windowWidth)
+	}
+	// The frame keeps its events, so the next frame's queue in a new slice
+	w.events = nil
+	region := trace.StartRegion(ctx, "render")
+	err = w.renderFunc(frame)
+	region.End()
+	if chk.E(err) {
+		return
+	}
+	w.setCursor(frame.Cursor)
+	region = trace.StartRegion(ctx, "present")
+	w.image.Flush(w.drawList, windowWidth, windowHeight)
+	w.webGL.present(w.image.Image())
+	region.End()
+	w.stats.record(now, time.Since(now))
+	return
+}
+
+// close frees the window's resources and lets go of its canvas element
+func (w *Window) close() {
+	if w.onClose != nil {
+		w.onClose()
+	}
+	w.stopPprof()
+	w.webGL.delete()
+	w.release()
+	w.image = nil
+	w.running = false
+	// Calls handed over from now on have nothing to run them
+	w.invoke.setClosed(true)
+}
+
+// release removes the window's listeners, and its canvas element if it
+// created it
+func (w *Window) release() {
+	for _, l := range w.listeners {
+		if l.observer.Truthy() {
+			l.observer.Call("disconnect")
+		} else {
+			l.target.Call("removeEventListener", l.event, l.fn)
+		}
+		l.fn.Release()
+	}
+	w.listeners = nil
+	if w.created {
+		w.canvas.Call("remove")
+		w.created = false
+	}
+	w.canvas = js.Value{}
+}
+
+// Stop closes the window without asking the close request callback, ending
+// the main loop if it is the last one open
+func (w *Window) Stop() {
+	w.running = false
+	if w.app != nil {
+		w.app.signal()
+	}
+}
+
+// Hide hides the window's canvas while leaving it open
+func (w *Window) Hide() {
+	if w.canvas.Truthy() {
+		w.canvas.Get("style").Set("display", "none")
+	}
+}
+
+// Show shows a hidden window's canvas again and gives it focus
+func (w *Window) Show() {
+	if !w.canvas.Truthy() {
+		return
+	}
+	w.canvas.Get("style").Set("display", "block")
+	w.canvas.Call("focus")
+	w.clock.Request()
+	w.Wake()
+}
+
+// Visible reports whether the window is open and shown
+func (w *Window) Visible() bool {
+	return w.canvas.Truthy() && w.canvas.Get("style").Get("display").String() != "none"
+}
+
+// RunOnUIThread runs a function on the main goroutine at the start of the
+// window's next frame, before the render function, and wakes the window to
+// draw it. It is safe to call from any goroutine, and never blocks the
+// caller. Calls made once the window has closed are dropped.
+func (w *Window) RunOnUIThread(fn func()) {
+	if w.invoke.push(fn) {
+		w.Wake()
+	}
+}
+
+// Wake draws a new frame while the window is waiting for input. It is safe to
+// call from any goroutine.
+func (w *Window) Wake() {
+	if w.app != nil {
+		w.app.request()
+	}
+}
+
+// Element returns the canvas element the window is shown in, undefined
+// while it is not open
+func (w *Window) Element() js.Value {
+	return w.canvas
+}